@@ -0,0 +1,123 @@
+// Package appdata gives typed access to the AppData of mediasoup entities, so
+// applications do not assert its type everywhere:
+//
+//	type PeerData struct{ PeerId string }
+//
+//	producer, _ := transport.Produce(mediasoup.ProducerOptions{
+//		...
+//		AppData: &PeerData{PeerId: "alice"},
+//	})
+//	peerData, ok := appdata.Get[*PeerData](producer)
+//
+//	// or, with a typed view of the entity:
+//	typed := appdata.Producer[*PeerData]{producer}
+//	typed.AppData().PeerId
+//
+// It is a separate module since it requires Go 1.18, while the mediasoup-go
+// module does not.
+package appdata
+
+import "github.com/jiyeyuran/mediasoup-go"
+
+/**
+ * Entity with AppData: *mediasoup.Worker, *mediasoup.Router,
+ * mediasoup.ITransport, *mediasoup.Producer, *mediasoup.Consumer,
+ * *mediasoup.DataProducer, *mediasoup.DataConsumer or mediasoup.IRtpObserver.
+ */
+type Holder interface {
+	AppData() interface{}
+}
+
+/**
+ * Get the AppData of the entity as T. ok is false if it is not a T (e.g. nil
+ * or the default mediasoup.H{} of an entity created without AppData).
+ */
+func Get[T any](entity Holder) (value T, ok bool) {
+	value, ok = entity.AppData().(T)
+	return
+}
+
+/**
+ * Get the AppData of the entity as T, or the zero T if it is not a T.
+ */
+func GetOrZero[T any](entity Holder) T {
+	value, _ := Get[T](entity)
+	return value
+}
+
+/**
+ * Worker with AppData of type T.
+ */
+type Worker[T any] struct {
+	*mediasoup.Worker
+}
+
+func (w Worker[T]) AppData() T {
+	return GetOrZero[T](w.Worker)
+}
+
+/**
+ * Router with AppData of type T.
+ */
+type Router[T any] struct {
+	*mediasoup.Router
+}
+
+func (r Router[T]) AppData() T {
+	return GetOrZero[T](r.Router)
+}
+
+/**
+ * Transport with AppData of type T.
+ */
+type Transport[T any] struct {
+	mediasoup.ITransport
+}
+
+func (t Transport[T]) AppData() T {
+	return GetOrZero[T](t.ITransport)
+}
+
+/**
+ * Producer with AppData of type T.
+ */
+type Producer[T any] struct {
+	*mediasoup.Producer
+}
+
+func (p Producer[T]) AppData() T {
+	return GetOrZero[T](p.Producer)
+}
+
+/**
+ * Consumer with AppData of type T.
+ */
+type Consumer[T any] struct {
+	*mediasoup.Consumer
+}
+
+func (c Consumer[T]) AppData() T {
+	return GetOrZero[T](c.Consumer)
+}
+
+/**
+ * DataProducer with AppData of type T.
+ */
+type DataProducer[T any] struct {
+	*mediasoup.DataProducer
+}
+
+func (p DataProducer[T]) AppData() T {
+	return GetOrZero[T](p.DataProducer)
+}
+
+/**
+ * DataConsumer with AppData of type T.
+ */
+type DataConsumer[T any] struct {
+	*mediasoup.DataConsumer
+}
+
+func (c DataConsumer[T]) AppData() T {
+	return GetOrZero[T](c.DataConsumer)
+}
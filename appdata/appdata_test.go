@@ -0,0 +1,42 @@
+package appdata
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type peerData struct {
+	PeerId string
+}
+
+type holder struct {
+	appData interface{}
+}
+
+func (h holder) AppData() interface{} {
+	return h.appData
+}
+
+func TestGet(t *testing.T) {
+	data, ok := Get[*peerData](holder{&peerData{PeerId: "alice"}})
+	assert.True(t, ok)
+	assert.Equal(t, "alice", data.PeerId)
+
+	_, ok = Get[*peerData](holder{mediasoup.H{}})
+	assert.False(t, ok)
+
+	_, ok = Get[peerData](holder{nil})
+	assert.False(t, ok)
+
+	assert.Equal(t, "alice", GetOrZero[peerData](holder{peerData{PeerId: "alice"}}).PeerId)
+	assert.Nil(t, GetOrZero[*peerData](holder{"alice"}))
+}
+
+func TestTypedEntity(t *testing.T) {
+	worker := Worker[*peerData]{&mediasoup.Worker{}}
+
+	assert.Nil(t, worker.AppData())
+	assert.False(t, worker.Closed())
+}
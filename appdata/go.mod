@@ -0,0 +1,26 @@
+module github.com/jiyeyuran/mediasoup-go/appdata
+
+// Go 1.18 for the generics of the typed AppData accessors, the root module
+// still supporting Go 1.15.
+go 1.18
+
+require github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+require (
+	github.com/gobwas/glob v0.2.3 // indirect
+	github.com/imdario/mergo v0.3.11 // indirect
+	github.com/jiyeyuran/go-eventemitter v1.3.0 // indirect
+	github.com/rs/zerolog v1.20.0 // indirect
+	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/stretchr/testify v1.8.4
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../
@@ -59,7 +59,7 @@ type AudioLevelObserver struct {
 func newAudioLevelObserver(params rtpObserverParams) *AudioLevelObserver {
 	o := &AudioLevelObserver{
 		IRtpObserver: newRtpObserver(params),
-		logger:       NewLogger("AudioLevelObserver"),
+		logger:       params.loggerContext.newLogger("AudioLevelObserver"),
 	}
 
 	o.handleWorkerNotifications(params)
@@ -14,7 +14,7 @@ var (
 			ClockRate: 48000,
 			Channels:  2,
 			Parameters: RtpCodecSpecificParameters{
-				Useinbandfec: 1,
+				{Key: "useinbandfec", Value: 1},
 			},
 		},
 	}
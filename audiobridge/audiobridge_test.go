@@ -0,0 +1,111 @@
+package audiobridge
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBridge_InvalidOptions(t *testing.T) {
+	_, err := NewBridge(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestG711Decoder(t *testing.T) {
+	pcmu, err := NewG711Decoder(&mediasoup.RtpCodecParameters{MimeType: "audio/PCMU", ClockRate: 8000})
+	assert.NoError(t, err)
+
+	pcm := make([]int16, 4)
+	samples, err := pcmu.Decode([]byte{0xff, 0x7f, 0x00, 0x80}, pcm)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, samples)
+	assert.Equal(t, []int16{0, 0, -32124, 32124}, pcm)
+
+	pcma, err := NewG711Decoder(&mediasoup.RtpCodecParameters{MimeType: "audio/PCMA", ClockRate: 8000})
+	assert.NoError(t, err)
+
+	samples, err = pcma.Decode([]byte{0xd5, 0x55, 0xaa, 0x2a}, pcm)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, samples)
+	assert.Equal(t, []int16{8, -8, 32256, -32256}, pcm)
+
+	samples, err = pcma.Decode(nil, pcm)
+	assert.NoError(t, err)
+	assert.Zero(t, samples)
+
+	_, err = NewG711Decoder(&mediasoup.RtpCodecParameters{MimeType: "audio/opus"})
+	assert.IsType(t, mediasoup.UnsupportedError{}, err)
+}
+
+// fakeDecoder decodes every payload into its length, concealing with -1.
+type fakeDecoder struct{}
+
+func (fakeDecoder) Decode(payload []byte, pcm []int16) (int, error) {
+	if payload == nil {
+		pcm[0] = -1
+	} else {
+		pcm[0] = int16(len(payload))
+	}
+	return 1, nil
+}
+
+func (fakeDecoder) SampleRate() int { return 8000 }
+
+func (fakeDecoder) Channels() int { return 1 }
+
+func rtpPacket(seq uint16, timestamp uint32, payload []byte) []byte {
+	packet := make([]byte, 12, 12+len(payload))
+	packet[0] = 0x80
+	binary.BigEndian.PutUint16(packet[2:], seq)
+	binary.BigEndian.PutUint32(packet[4:], timestamp)
+
+	return append(packet, payload...)
+}
+
+func TestBridge_HandleRtp(t *testing.T) {
+	bridge := &Bridge{
+		logger:    mediasoup.NewLogger("AudioBridge"),
+		decoder:   fakeDecoder{},
+		clockRate: 8000,
+		frames:    make(chan Frame, 10),
+	}
+
+	bridge.handleRtp(rtpPacket(65535, 1000, []byte{1}))
+	// Two packets lost, wrapping the sequence number.
+	bridge.handleRtp(rtpPacket(2, 1480, []byte{1, 2}))
+	// Late packet.
+	bridge.handleRtp(rtpPacket(1, 1320, []byte{1, 2, 3}))
+	close(bridge.frames)
+
+	var frames []Frame
+
+	for frame := range bridge.frames {
+		frames = append(frames, frame)
+	}
+
+	assert.Equal(t, []Frame{
+		{Samples: []int16{1}, SampleRate: 8000, Channels: 1},
+		{Samples: []int16{-1}, SampleRate: 8000, Channels: 1, Timestamp: 20 * time.Millisecond, Concealed: true},
+		{Samples: []int16{-1}, SampleRate: 8000, Channels: 1, Timestamp: 40 * time.Millisecond, Concealed: true},
+		{Samples: []int16{2}, SampleRate: 8000, Channels: 1, Timestamp: 60 * time.Millisecond},
+	}, frames)
+}
+
+func TestBridge_Read(t *testing.T) {
+	bridge := &Bridge{frames: make(chan Frame, 2)}
+	bridge.frames <- Frame{Samples: []int16{1, -2}}
+	close(bridge.frames)
+
+	data, err := ioutil.ReadAll(bridge)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x00, 0xfe, 0xff}, data)
+}
+
+func TestFrame_Duration(t *testing.T) {
+	frame := Frame{Samples: make([]int16, 640), SampleRate: 16000, Channels: 2}
+	assert.Equal(t, 20*time.Millisecond, frame.Duration())
+}
@@ -0,0 +1,370 @@
+// Package audiobridge decodes an audio Producer into PCM, for speech
+// recognition, voice activity detection and other processing:
+//
+//	bridge, err := audiobridge.NewBridge(audiobridge.Options{
+//		Router:     router,
+//		Producer:   micProducer,
+//		NewDecoder: opus.DecoderFactory(16000, 1),
+//	})
+//	for frame := range bridge.Frames() {
+//		transcriber.Write(frame.Samples)
+//	}
+//
+// The Producer is consumed through a DirectTransport. PCMU and PCMA are
+// decoded in Go; other codecs, Opus in particular, need a decoder given with
+// the NewDecoder option, such as the one of the
+// github.com/jiyeyuran/mediasoup-go/audiobridge/opus module.
+package audiobridge
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Longest frame decoded, 120 ms at 48 kHz.
+const maxFrameSamples = 5760
+
+// Number of lost packets concealed at most, longer losses being skipped.
+const maxConcealedPackets = 5
+
+type Options struct {
+	/**
+	 * Router of the Producer.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Audio Producer to decode.
+	 */
+	Producer *mediasoup.Producer
+
+	/**
+	 * Create the decoder of the codec of the Producer. Default
+	 * NewG711Decoder.
+	 */
+	NewDecoder NewDecoderFunc
+
+	/**
+	 * Number of frames buffered, the new frames being dropped when the buffer
+	 * is full. Default 50.
+	 */
+	BufferFrames int
+
+	/**
+	 * Logger of the bridge. Default mediasoup.NewLogger("AudioBridge").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Frame is a decoded audio frame.
+ */
+type Frame struct {
+	/**
+	 * Interleaved 16-bit samples.
+	 */
+	Samples []int16
+
+	SampleRate int
+	Channels   int
+
+	/**
+	 * Time of the frame since the first one, given by the RTP timestamps.
+	 */
+	Timestamp time.Duration
+
+	/**
+	 * Whether the frame conceals a lost packet.
+	 */
+	Concealed bool
+}
+
+/**
+ * Duration of the frame.
+ */
+func (f Frame) Duration() time.Duration {
+	if f.SampleRate == 0 || f.Channels == 0 {
+		return 0
+	}
+	return time.Duration(len(f.Samples)/f.Channels) * time.Second / time.Duration(f.SampleRate)
+}
+
+/**
+ * Bridge decodes an audio Producer. Its frames are read either from Frames()
+ * or, as little-endian 16-bit PCM, with Read().
+ * @emits close
+ */
+type Bridge struct {
+	mediasoup.IEventEmitter
+	logger    mediasoup.Logger
+	transport *mediasoup.DirectTransport
+	consumer  *mediasoup.Consumer
+	decoder   Decoder
+	clockRate int
+	frames    chan Frame
+	locker    sync.Mutex
+	// Last received RTP packet.
+	started       bool
+	lastSeq       uint16
+	lastTimestamp uint32
+	extTimestamp  int64
+	dropped       int
+	closed        bool
+	// Remainder of the frame partially read by Read().
+	readBuf []byte
+}
+
+/**
+ * Start decoding the given Producer.
+ */
+func NewBridge(options Options) (bridge *Bridge, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Producer == nil {
+		return nil, mediasoup.NewTypeError("missing Producer")
+	}
+	if options.Producer.Kind() != mediasoup.MediaKind_Audio {
+		return nil, mediasoup.NewTypeError("Producer is not audio")
+	}
+	if options.NewDecoder == nil {
+		options.NewDecoder = NewG711Decoder
+	}
+	if options.BufferFrames <= 0 {
+		options.BufferFrames = 50
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("AudioBridge")
+	}
+
+	bridge = &Bridge{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		frames:        make(chan Frame, options.BufferFrames),
+	}
+
+	if bridge.transport, err = options.Router.CreateDirectTransport(); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			bridge.transport.Close()
+			bridge = nil
+		}
+	}()
+
+	bridge.consumer, err = bridge.transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      options.Producer.Id(),
+		RtpCapabilities: options.Router.RtpCapabilities(),
+	})
+	if err != nil {
+		return
+	}
+
+	codec := bridge.consumer.RtpParameters().Codecs[0]
+	bridge.clockRate = codec.ClockRate
+
+	if bridge.decoder, err = options.NewDecoder(codec); err != nil {
+		return
+	}
+
+	bridge.consumer.On("rtp", bridge.handleRtp)
+	bridge.consumer.On("producerclose", func() { bridge.Close() })
+
+	return
+}
+
+/**
+ * Sample rate of the decoded frames.
+ */
+func (b *Bridge) SampleRate() int {
+	return b.decoder.SampleRate()
+}
+
+/**
+ * Number of channels of the decoded frames.
+ */
+func (b *Bridge) Channels() int {
+	return b.decoder.Channels()
+}
+
+/**
+ * Decoded frames, the channel being closed with the bridge.
+ */
+func (b *Bridge) Frames() <-chan Frame {
+	return b.frames
+}
+
+/**
+ * Number of frames dropped so far because the buffer was full.
+ */
+func (b *Bridge) Dropped() int {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	return b.dropped
+}
+
+/**
+ * Read the decoded PCM, as little-endian 16-bit interleaved samples. io.EOF
+ * is returned once the bridge is closed and its frames read.
+ */
+func (b *Bridge) Read(p []byte) (n int, err error) {
+	if len(b.readBuf) == 0 {
+		frame, ok := <-b.frames
+		if !ok {
+			return 0, io.EOF
+		}
+		b.readBuf = make([]byte, 2*len(frame.Samples))
+
+		for i, sample := range frame.Samples {
+			binary.LittleEndian.PutUint16(b.readBuf[2*i:], uint16(sample))
+		}
+	}
+
+	n = copy(p, b.readBuf)
+	b.readBuf = b.readBuf[n:]
+
+	return
+}
+
+func (b *Bridge) handleRtp(packet []byte) {
+	seq, timestamp, payload, ok := parseRtpHeader(packet)
+	if !ok {
+		b.logger.Warn("invalid RTP packet")
+		return
+	}
+
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	lost, elapsed := 0, int64(0)
+
+	if b.started {
+		diff := int16(seq - b.lastSeq)
+
+		// Late or duplicated packet.
+		if diff <= 0 {
+			return
+		}
+		lost = int(diff) - 1
+		elapsed = int64(int32(timestamp - b.lastTimestamp))
+	}
+	b.started = true
+	b.lastSeq = seq
+	b.lastTimestamp = timestamp
+
+	if lost > 0 && lost <= maxConcealedPackets {
+		for i := 1; i <= lost; i++ {
+			b.decode(nil, b.extTimestamp+elapsed*int64(i)/int64(lost+1))
+		}
+	}
+	b.extTimestamp += elapsed
+	b.decode(payload, b.extTimestamp)
+}
+
+func (b *Bridge) decode(payload []byte, extTimestamp int64) {
+	channels := b.decoder.Channels()
+	pcm := make([]int16, maxFrameSamples*channels)
+
+	samples, err := b.decoder.Decode(payload, pcm)
+	if err != nil {
+		b.logger.Warn("decoding failed: %v", err)
+		return
+	}
+	if samples == 0 {
+		return
+	}
+
+	frame := Frame{
+		Samples:    pcm[:samples*channels],
+		SampleRate: b.decoder.SampleRate(),
+		Channels:   channels,
+		Timestamp:  rtpDuration(extTimestamp, b.clockRate),
+		Concealed:  payload == nil,
+	}
+
+	select {
+	case b.frames <- frame:
+	default:
+		b.dropped++
+	}
+}
+
+/**
+ * Whether the bridge is closed.
+ */
+func (b *Bridge) Closed() bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	return b.closed
+}
+
+/**
+ * Stop decoding and close the frames channel, its buffered frames remaining
+ * readable. It is called when the Producer is closed.
+ */
+func (b *Bridge) Close() error {
+	b.locker.Lock()
+
+	if b.closed {
+		b.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	b.logger.Debug("Close()")
+
+	b.closed = true
+	close(b.frames)
+
+	b.locker.Unlock()
+
+	b.transport.Close()
+	b.SafeEmit("close")
+
+	return nil
+}
+
+// rtpDuration converts RTP clock units into a duration.
+func rtpDuration(units int64, clockRate int) time.Duration {
+	rate := int64(clockRate)
+
+	return time.Duration(units/rate)*time.Second + time.Duration(units%rate)*time.Second/time.Duration(rate)
+}
+
+// parseRtpHeader returns the sequence number, timestamp and payload of the
+// packet.
+func parseRtpHeader(packet []byte) (seq uint16, timestamp uint32, payload []byte, ok bool) {
+	if len(packet) < 12 || packet[0]>>6 != 2 {
+		return
+	}
+
+	offset := 12 + 4*int(packet[0]&0x0f)
+
+	if packet[0]&0x10 != 0 {
+		if len(packet) < offset+4 {
+			return
+		}
+		offset += 4 + 4*int(binary.BigEndian.Uint16(packet[offset+2:]))
+	}
+
+	end := len(packet)
+
+	if packet[0]&0x20 != 0 && end > offset {
+		end -= int(packet[end-1])
+	}
+	if offset > end {
+		return
+	}
+
+	return binary.BigEndian.Uint16(packet[2:]), binary.BigEndian.Uint32(packet[4:]), packet[offset:end], true
+}
@@ -0,0 +1,103 @@
+package audiobridge
+
+import (
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Decoder decodes the RTP payloads of an audio codec into PCM.
+ */
+type Decoder interface {
+	/**
+	 * Decode the payload into pcm (interleaved 16-bit samples), returning the
+	 * number of samples per channel. A nil payload asks for the concealment
+	 * of a lost packet, decoders not supporting it returning 0.
+	 */
+	Decode(payload []byte, pcm []int16) (samples int, err error)
+
+	/**
+	 * Sample rate of the decoded PCM.
+	 */
+	SampleRate() int
+
+	/**
+	 * Number of channels of the decoded PCM.
+	 */
+	Channels() int
+}
+
+/**
+ * NewDecoderFunc creates the decoder of the given codec.
+ */
+type NewDecoderFunc func(codec *mediasoup.RtpCodecParameters) (Decoder, error)
+
+/**
+ * NewG711Decoder creates the decoder of PCMU and PCMA, the codecs decoded
+ * without a NewDecoder option.
+ */
+func NewG711Decoder(codec *mediasoup.RtpCodecParameters) (Decoder, error) {
+	switch strings.ToLower(codec.MimeType) {
+	case "audio/pcmu":
+		return g711Decoder{decode: ulawToLinear}, nil
+	case "audio/pcma":
+		return g711Decoder{decode: alawToLinear}, nil
+	default:
+		return nil, mediasoup.NewUnsupportedError("no decoder of %s", codec.MimeType)
+	}
+}
+
+type g711Decoder struct {
+	decode func(b byte) int16
+}
+
+func (d g711Decoder) Decode(payload []byte, pcm []int16) (samples int, err error) {
+	for samples < len(payload) && samples < len(pcm) {
+		pcm[samples] = d.decode(payload[samples])
+		samples++
+	}
+	return
+}
+
+func (d g711Decoder) SampleRate() int {
+	return 8000
+}
+
+func (d g711Decoder) Channels() int {
+	return 1
+}
+
+// ulawToLinear decodes a G.711 mu-law sample.
+func ulawToLinear(b byte) int16 {
+	b = ^b
+	t := (int16(b&0x0f) << 3) + 0x84
+	t <<= (b & 0x70) >> 4
+
+	if b&0x80 != 0 {
+		return 0x84 - t
+	}
+	return t - 0x84
+}
+
+// alawToLinear decodes a G.711 A-law sample.
+func alawToLinear(b byte) int16 {
+	b ^= 0x55
+	t := int16(b&0x0f) << 4
+	seg := (b & 0x70) >> 4
+
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+
+	if b&0x80 != 0 {
+		return t
+	}
+	return -t
+}
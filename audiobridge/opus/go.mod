@@ -0,0 +1,12 @@
+module github.com/jiyeyuran/mediasoup-go/audiobridge/opus
+
+go 1.15
+
+require (
+	github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+	gopkg.in/hraban/opus.v2 v2.0.0-20220302220929-eeacdbcb92d0
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../../
@@ -0,0 +1,57 @@
+// Package opus provides the Opus decoder of the audiobridge package, using
+// libopus through cgo. It is a separate module so that the mediasoup-go module
+// does not require cgo nor libopus.
+package opus
+
+import (
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/audiobridge"
+	opus "gopkg.in/hraban/opus.v2"
+)
+
+/**
+ * DecoderFactory returns the audiobridge.NewDecoderFunc decoding Opus at the
+ * given sample rate (8000, 12000, 16000, 24000 or 48000) and number of
+ * channels (1 or 2), e.g. 16000 and 1 for speech recognition.
+ */
+func DecoderFactory(sampleRate, channels int) audiobridge.NewDecoderFunc {
+	return func(codec *mediasoup.RtpCodecParameters) (audiobridge.Decoder, error) {
+		if strings.ToLower(codec.MimeType) != "audio/opus" {
+			return audiobridge.NewG711Decoder(codec)
+		}
+
+		decoder, err := opus.NewDecoder(sampleRate, channels)
+		if err != nil {
+			return nil, err
+		}
+
+		return &opusDecoder{decoder: decoder, sampleRate: sampleRate, channels: channels}, nil
+	}
+}
+
+type opusDecoder struct {
+	decoder    *opus.Decoder
+	sampleRate int
+	channels   int
+}
+
+func (d *opusDecoder) Decode(payload []byte, pcm []int16) (samples int, err error) {
+	if payload == nil {
+		// Conceal 20 ms, the usual packet duration.
+		samples = d.sampleRate / 50
+		err = d.decoder.DecodePLC(pcm[:samples*d.channels])
+		return
+	}
+
+	return d.decoder.Decode(payload, pcm)
+}
+
+func (d *opusDecoder) SampleRate() int {
+	return d.sampleRate
+}
+
+func (d *opusDecoder) Channels() int {
+	return d.channels
+}
@@ -0,0 +1,109 @@
+package bandwidth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var layers = []int{200000, 700000, 2500000}
+
+func TestNewPolicy_InvalidOptions(t *testing.T) {
+	_, err := NewPolicy(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewPolicy(Options{Budget: 1000000, LayerBitrates: []int{700000, 200000}})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	policy, err := NewPolicy(Options{Budget: 1000000})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultLayerBitrates, policy.options.LayerBitrates)
+	assert.IsType(t, mediasoup.TypeError{}, policy.SetBudget(0))
+	assert.NoError(t, policy.Close())
+	assert.Equal(t, mediasoup.ErrAlreadyClosed, policy.Close())
+	assert.IsType(t, mediasoup.InvalidStateError{}, policy.Rebalance())
+}
+
+func TestEqualShare(t *testing.T) {
+	candidates := []Candidate{
+		{ConsumerId: "a", LayerBitrates: layers},
+		{ConsumerId: "b", LayerBitrates: layers},
+		{ConsumerId: "c", LayerBitrates: layers[:1]},
+	}
+
+	// "c" cannot use more than 200 kbps, the rest being shared by the others.
+	assert.Equal(t, []int{1400000, 1400000, 200000}, EqualShare.Allocate(3000000, candidates))
+	assert.Equal(t, []int{2500000, 2500000, 200000}, EqualShare.Allocate(10000000, candidates))
+	assert.Equal(t, []int{100000, 100000, 100000}, EqualShare.Allocate(300000, candidates))
+	assert.Empty(t, EqualShare.Allocate(300000, nil))
+}
+
+func TestSpeakerFirst(t *testing.T) {
+	candidates := []Candidate{
+		{ConsumerId: "a", Speaker: true, LayerBitrates: layers},
+		{ConsumerId: "b", LayerBitrates: layers},
+		{ConsumerId: "c", LayerBitrates: layers},
+	}
+	strategy := SpeakerFirst(0.6)
+
+	assert.Equal(t, []int{1800000, 600000, 600000}, strategy.Allocate(3000000, candidates))
+
+	// What the speaker cannot use goes to the others.
+	assert.Equal(t, []int{2500000, 1750000, 1750000}, strategy.Allocate(6000000, candidates))
+
+	// Without the others the speaker gets the whole budget.
+	assert.Equal(t, []int{2500000}, strategy.Allocate(3000000, candidates[:1]))
+}
+
+func TestSpatialLayer(t *testing.T) {
+	assert.EqualValues(t, 0, spatialLayer(100000, layers))
+	assert.EqualValues(t, 0, spatialLayer(699999, layers))
+	assert.EqualValues(t, 1, spatialLayer(700000, layers))
+	assert.EqualValues(t, 2, spatialLayer(3000000, layers))
+}
+
+func TestPriority(t *testing.T) {
+	assert.EqualValues(t, 255, priority(2500000, 2500000))
+	assert.EqualValues(t, 128, priority(1250000, 2500000))
+	assert.EqualValues(t, 1, priority(0, 2500000))
+	assert.EqualValues(t, 1, priority(0, 0))
+}
+
+func TestNewManager_InvalidOptions(t *testing.T) {
+	_, err := NewManager(nil, ManagerOptions{Headroom: 1.5})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewManager(nil, ManagerOptions{LayerBitrates: []int{0}})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestNextBudget(t *testing.T) {
+	now := time.Now()
+	managed := &managedTransport{budget: 1000000}
+
+	// No estimation yet.
+	assert.Equal(t, 1000000, nextBudget(managed, now, 5*time.Second))
+
+	// Decreases are applied at once.
+	managed.estimate = 600000
+	assert.Equal(t, 600000, nextBudget(managed, now, 5*time.Second))
+	managed.budget = 600000
+
+	// Increases once they lasted the delay.
+	managed.estimate = 2000000
+	assert.Equal(t, 600000, nextBudget(managed, now, 5*time.Second))
+	assert.Equal(t, 600000, nextBudget(managed, now.Add(4*time.Second), 5*time.Second))
+	assert.Equal(t, 2000000, nextBudget(managed, now.Add(5*time.Second), 5*time.Second))
+	managed.budget = 2000000
+
+	// A drop restarts the delay.
+	managed.estimate = 3000000
+	assert.Equal(t, 2000000, nextBudget(managed, now.Add(6*time.Second), 5*time.Second))
+	managed.estimate = 1500000
+	assert.Equal(t, 1500000, nextBudget(managed, now.Add(7*time.Second), 5*time.Second))
+	managed.budget = 1500000
+	managed.estimate = 3000000
+	assert.Equal(t, 1500000, nextBudget(managed, now.Add(11*time.Second), 5*time.Second))
+}
@@ -0,0 +1,363 @@
+package bandwidth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type ManagerOptions struct {
+	/**
+	 * Strategy dividing the budget of every transport. Default EqualShare.
+	 */
+	Strategy Strategy
+
+	/**
+	 * Expected bitrates of the spatial layers, from the lowest. Default the
+	 * ones of the "camera" encoding preset.
+	 */
+	LayerBitrates []int
+
+	/**
+	 * Budget of a transport until its first bandwidth estimation. Default
+	 * 1 Mbps.
+	 */
+	InitialBudget int
+
+	/**
+	 * Fraction of the estimated bandwidth used as budget. Default 0.9.
+	 */
+	Headroom float64
+
+	/**
+	 * How long the estimation must stay above the budget before the budget is
+	 * raised, decreases being applied at once. Default 5 seconds.
+	 */
+	UpgradeDelay time.Duration
+
+	/**
+	 * Consumers whose score is below it are not given higher layers than the
+	 * one they currently receive. Default 7.
+	 */
+	MinScore uint16
+
+	/**
+	 * Interval between two computations of the layers. Default 1 second.
+	 */
+	Interval time.Duration
+
+	/**
+	 * Hook of the application, called for every Consumer when computing the
+	 * layers, which may change its candidate (e.g. mark it as speaker or drop
+	 * its highest layers). Optional.
+	 */
+	Candidate func(consumer *mediasoup.Consumer, candidate *Candidate)
+
+	/**
+	 * Logger of the manager. Default mediasoup.NewLogger("LayerManager").
+	 */
+	Logger mediasoup.Logger
+}
+
+type managedTransport struct {
+	policy *Policy
+	// Budget applied to the policy.
+	budget int
+	// Last estimation, with the headroom applied, 0 if none yet.
+	estimate int
+	// Since when the estimation is above the budget.
+	upSince time.Time
+}
+
+/**
+ * Manager adapts the downlink of every WebRtcTransport of a Router: the
+ * budget of each transport follows its bandwidth estimation ("bwe" trace
+ * events), and is divided between its video Consumers by a Policy, Consumers
+ * with a low score keeping their current layer. The layers of all the
+ * transports are computed together periodically, and budget increases are
+ * delayed, so that the layers do not oscillate.
+ *
+ * Enabling the trace events of the transports of the Router replaces the
+ * types enabled by the Manager.
+ */
+type Manager struct {
+	logger     mediasoup.Logger
+	options    ManagerOptions
+	locker     sync.Mutex
+	transports map[string]*managedTransport
+	speakers   []string
+	closed     bool
+	closeCh    chan struct{}
+}
+
+/**
+ * Create a Manager for the given Router. It is closed with the Router.
+ */
+func NewManager(router *mediasoup.Router, options ManagerOptions) (*Manager, error) {
+	if options.Headroom < 0 || options.Headroom > 1 {
+		return nil, mediasoup.NewTypeError("Headroom must be in [0, 1]")
+	}
+	if options.InitialBudget <= 0 {
+		options.InitialBudget = 1000000
+	}
+	if options.Headroom == 0 {
+		options.Headroom = 0.9
+	}
+	if options.UpgradeDelay <= 0 {
+		options.UpgradeDelay = 5 * time.Second
+	}
+	if options.MinScore == 0 {
+		options.MinScore = 7
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("LayerManager")
+	}
+
+	// Validate the options shared with the policies.
+	if _, err := NewPolicy(options.policyOptions(nil)); err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		logger:     options.Logger,
+		options:    options,
+		transports: make(map[string]*managedTransport),
+		closeCh:    make(chan struct{}),
+	}
+
+	router.Observer().On("newtransport", m.addTransport)
+	router.Observer().Once("close", func() { m.Close() })
+
+	for _, transport := range router.Transports() {
+		m.addTransport(transport)
+	}
+
+	go m.run()
+
+	return m, nil
+}
+
+func (o ManagerOptions) policyOptions(candidate func(*mediasoup.Consumer, *Candidate)) Options {
+	return Options{
+		Budget:        o.InitialBudget,
+		Strategy:      o.Strategy,
+		LayerBitrates: o.LayerBitrates,
+		Candidate:     candidate,
+		Logger:        o.Logger,
+	}
+}
+
+func (m *Manager) addTransport(transport mediasoup.ITransport) {
+	if _, ok := transport.(*mediasoup.WebRtcTransport); !ok {
+		return
+	}
+
+	policy, _ := NewPolicy(m.options.policyOptions(m.candidate))
+	managed := &managedTransport{policy: policy, budget: m.options.InitialBudget}
+
+	m.locker.Lock()
+
+	if m.closed {
+		m.locker.Unlock()
+		return
+	}
+	m.transports[transport.Id()] = managed
+	policy.SetSpeakers(m.speakers...)
+
+	m.locker.Unlock()
+
+	transport.Observer().On("trace", func(trace mediasoup.TransportTraceEventData) {
+		if trace.Type != mediasoup.TransportTraceEventType_Bwe {
+			return
+		}
+		info, _ := trace.Info.(map[string]interface{})
+		bitrate, _ := info["availableBitrate"].(float64)
+
+		m.locker.Lock()
+		managed.estimate = int(bitrate * m.options.Headroom)
+		m.locker.Unlock()
+	})
+	transport.Observer().Once("close", func() {
+		m.locker.Lock()
+		delete(m.transports, transport.Id())
+		m.locker.Unlock()
+
+		policy.Close()
+	})
+
+	if err := transport.EnableTraceEvent(mediasoup.TransportTraceEventType_Bwe); err != nil {
+		m.logger.Warn("enabling trace events of transport %s failed: %s", transport.Id(), err)
+	}
+
+	policy.AddTransport(transport)
+}
+
+// candidate keeps the Consumers with a low score on their current layer, then
+// calls the hook of the application.
+func (m *Manager) candidate(consumer *mediasoup.Consumer, candidate *Candidate) {
+	if consumer.Score().Score < m.options.MinScore {
+		if layers := consumer.CurrentLayers(); layers != nil && int(layers.SpatialLayer)+1 < len(candidate.LayerBitrates) {
+			candidate.LayerBitrates = candidate.LayerBitrates[:layers.SpatialLayer+1]
+		}
+	}
+	if m.options.Candidate != nil {
+		m.options.Candidate(consumer, candidate)
+	}
+}
+
+func (m *Manager) run() {
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.update(now)
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+// update computes the budgets of the transports, then their layers.
+func (m *Manager) update(now time.Time) {
+	m.locker.Lock()
+
+	type update struct {
+		policy *Policy
+		budget int
+	}
+	updates := make([]update, 0, len(m.transports))
+
+	for _, managed := range m.transports {
+		managed.budget = nextBudget(managed, now, m.options.UpgradeDelay)
+		updates = append(updates, update{managed.policy, managed.budget})
+	}
+
+	m.locker.Unlock()
+
+	for _, update := range updates {
+		// Rebalancing as well when the budget did not change, the scores of
+		// the Consumers may have.
+		if err := update.policy.SetBudget(update.budget); err != nil && !update.policy.Closed() {
+			m.logger.Warn("update() | rebalancing failed: %v", err)
+		}
+	}
+}
+
+// nextBudget returns the budget of the transport, following decreases of the
+// estimation at once and increases once they lasted the given delay.
+func nextBudget(managed *managedTransport, now time.Time, delay time.Duration) int {
+	switch {
+	case managed.estimate <= 0 || managed.estimate == managed.budget:
+		managed.upSince = time.Time{}
+		return managed.budget
+
+	case managed.estimate < managed.budget:
+		managed.upSince = time.Time{}
+		return managed.estimate
+
+	case managed.upSince.IsZero():
+		managed.upSince = now
+		return managed.budget
+
+	case now.Sub(managed.upSince) >= delay:
+		managed.upSince = time.Time{}
+		return managed.estimate
+
+	default:
+		return managed.budget
+	}
+}
+
+/**
+ * Set the Producers of the active speakers, given priority by the
+ * SpeakerFirst strategy.
+ */
+func (m *Manager) SetSpeakers(producerIds ...string) {
+	m.locker.Lock()
+
+	m.speakers = producerIds
+	policies := m.policies()
+
+	m.locker.Unlock()
+
+	for _, policy := range policies {
+		policy.SetSpeakers(producerIds...)
+	}
+}
+
+/**
+ * Current budget of the transport, 0 if it is not managed.
+ */
+func (m *Manager) Budget(transportId string) int {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	if managed := m.transports[transportId]; managed != nil {
+		return managed.budget
+	}
+	return 0
+}
+
+/**
+ * Current allocations of the Consumers of every transport.
+ */
+func (m *Manager) Allocations() (allocations []Allocation) {
+	m.locker.Lock()
+	policies := m.policies()
+	m.locker.Unlock()
+
+	for _, policy := range policies {
+		allocations = append(allocations, policy.Allocations()...)
+	}
+	return
+}
+
+func (m *Manager) policies() []*Policy {
+	policies := make([]*Policy, 0, len(m.transports))
+
+	for _, managed := range m.transports {
+		policies = append(policies, managed.policy)
+	}
+	return policies
+}
+
+/**
+ * Whether the Manager is closed.
+ */
+func (m *Manager) Closed() bool {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	return m.closed
+}
+
+/**
+ * Stop adapting the layers.
+ */
+func (m *Manager) Close() error {
+	m.locker.Lock()
+
+	if m.closed {
+		m.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	m.closed = true
+	close(m.closeCh)
+
+	policies := m.policies()
+	m.transports = make(map[string]*managedTransport)
+
+	m.locker.Unlock()
+
+	for _, policy := range policies {
+		policy.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,394 @@
+// Package bandwidth enforces a total downlink budget over a set of video
+// Consumers, e.g. the Consumers of a transport or of every transport of a
+// room, by adjusting their priorities and preferred layers as they come and
+// go:
+//
+//	policy, err := bandwidth.NewPolicy(bandwidth.Options{
+//		Budget:   3000000,
+//		Strategy: bandwidth.SpeakerFirst(0.6),
+//	})
+//	policy.AddTransport(recvTransport)
+//	...
+//	policy.SetSpeakers(activeSpeakerProducerId)
+//
+// The budget is divided by a pluggable Strategy, every Consumer then receiving
+// the highest spatial layer fitting its share and a priority proportional to
+// it. A Manager does it for every transport of a Router, the budgets following
+// the bandwidth estimations of the transports.
+package bandwidth
+
+import (
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Bitrates of the layers of the "camera" encoding preset.
+var defaultLayerBitrates = []int{200000, 700000, 2500000}
+
+type Options struct {
+	/**
+	 * Total downlink bitrate (in bps) of the video Consumers.
+	 */
+	Budget int
+
+	/**
+	 * Strategy dividing the budget. Default EqualShare.
+	 */
+	Strategy Strategy
+
+	/**
+	 * Expected bitrates of the spatial layers, from the lowest. Default the
+	 * ones of the "camera" encoding preset (200, 700 and 2500 kbps).
+	 */
+	LayerBitrates []int
+
+	/**
+	 * Function called for every Consumer when rebalancing, which may change its
+	 * candidate (e.g. mark it as speaker or drop its highest layers). Optional.
+	 */
+	Candidate func(consumer *mediasoup.Consumer, candidate *Candidate)
+
+	/**
+	 * Logger of the policy. Default mediasoup.NewLogger("BandwidthPolicy").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Allocation is the share of the budget given to a Consumer.
+ */
+type Allocation struct {
+	ConsumerId   string
+	Bitrate      int
+	SpatialLayer uint8
+	Priority     uint32
+}
+
+type managedConsumer struct {
+	consumer *mediasoup.Consumer
+	// Layers and priority last applied, nil and 0 if none.
+	layers   *mediasoup.ConsumerLayers
+	priority uint32
+}
+
+/**
+ * Policy divides a budget between video Consumers.
+ */
+type Policy struct {
+	logger          mediasoup.Logger
+	options         Options
+	locker          sync.Mutex
+	rebalanceLocker sync.Mutex
+	consumers       []*managedConsumer
+	speakers        map[string]bool
+	allocations     []Allocation
+	closed          bool
+}
+
+/**
+ * Create a policy.
+ */
+func NewPolicy(options Options) (*Policy, error) {
+	if options.Budget <= 0 {
+		return nil, mediasoup.NewTypeError("invalid Budget")
+	}
+	for i, bitrate := range options.LayerBitrates {
+		if bitrate <= 0 || i > 0 && bitrate <= options.LayerBitrates[i-1] {
+			return nil, mediasoup.NewTypeError("LayerBitrates must be positive and increasing")
+		}
+	}
+	if options.Strategy == nil {
+		options.Strategy = EqualShare
+	}
+	if len(options.LayerBitrates) == 0 {
+		options.LayerBitrates = defaultLayerBitrates
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("BandwidthPolicy")
+	}
+
+	return &Policy{
+		logger:   options.Logger,
+		options:  options,
+		speakers: make(map[string]bool),
+	}, nil
+}
+
+/**
+ * Manage the video Consumers of the transport, the current ones and the ones
+ * created later.
+ */
+func (p *Policy) AddTransport(transport mediasoup.ITransport) {
+	transport.Observer().On("newconsumer", func(consumer *mediasoup.Consumer) {
+		p.AddConsumer(consumer)
+	})
+
+	for _, consumer := range transport.Consumers() {
+		p.AddConsumer(consumer)
+	}
+}
+
+/**
+ * Manage a Consumer, rebalancing the budget. Audio and pipe Consumers are
+ * ignored. The Consumer is removed, and the budget rebalanced, once it is
+ * closed.
+ */
+func (p *Policy) AddConsumer(consumer *mediasoup.Consumer) {
+	if consumer.Kind() != mediasoup.MediaKind_Video || consumer.Type() == mediasoup.ConsumerType_Pipe || consumer.Closed() {
+		return
+	}
+
+	p.locker.Lock()
+
+	if p.closed || p.indexOf(consumer.Id()) >= 0 {
+		p.locker.Unlock()
+		return
+	}
+	p.consumers = append(p.consumers, &managedConsumer{consumer: consumer})
+
+	p.locker.Unlock()
+
+	consumer.Observer().On("close", func() {
+		p.locker.Lock()
+
+		if i := p.indexOf(consumer.Id()); i >= 0 {
+			p.consumers = append(p.consumers[:i], p.consumers[i+1:]...)
+		}
+		closed := p.closed
+
+		p.locker.Unlock()
+
+		if !closed {
+			p.Rebalance()
+		}
+	})
+
+	p.Rebalance()
+}
+
+func (p *Policy) indexOf(consumerId string) int {
+	for i, managed := range p.consumers {
+		if managed.consumer.Id() == consumerId {
+			return i
+		}
+	}
+	return -1
+}
+
+/**
+ * Set the Producers of the active speakers, rebalancing the budget.
+ */
+func (p *Policy) SetSpeakers(producerIds ...string) error {
+	p.locker.Lock()
+
+	p.speakers = make(map[string]bool, len(producerIds))
+
+	for _, producerId := range producerIds {
+		p.speakers[producerId] = true
+	}
+
+	p.locker.Unlock()
+
+	return p.Rebalance()
+}
+
+/**
+ * Set the budget (in bps), rebalancing it.
+ */
+func (p *Policy) SetBudget(budget int) error {
+	if budget <= 0 {
+		return mediasoup.NewTypeError("invalid budget")
+	}
+
+	p.locker.Lock()
+	p.options.Budget = budget
+	p.locker.Unlock()
+
+	return p.Rebalance()
+}
+
+/**
+ * Current allocations of the Consumers.
+ */
+func (p *Policy) Allocations() []Allocation {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return append([]Allocation(nil), p.allocations...)
+}
+
+/**
+ * Divide the budget again, applying the layers and priorities which changed.
+ * It is called automatically when the Consumers, the speakers or the budget
+ * change. The first error of the Consumers is returned.
+ */
+func (p *Policy) Rebalance() (err error) {
+	p.rebalanceLocker.Lock()
+	defer p.rebalanceLocker.Unlock()
+
+	p.locker.Lock()
+
+	if p.closed {
+		p.locker.Unlock()
+		return mediasoup.NewInvalidStateError("Policy closed")
+	}
+
+	consumers := append([]*managedConsumer(nil), p.consumers...)
+	speakers, budget := p.speakers, p.options.Budget
+
+	p.locker.Unlock()
+
+	candidates := make([]Candidate, len(consumers))
+
+	for i, managed := range consumers {
+		candidates[i] = Candidate{
+			ConsumerId:    managed.consumer.Id(),
+			Speaker:       speakers[managed.consumer.ProducerId()],
+			LayerBitrates: p.layerBitrates(managed.consumer),
+		}
+		if p.options.Candidate != nil {
+			p.options.Candidate(managed.consumer, &candidates[i])
+		}
+	}
+	bitrates := p.options.Strategy.Allocate(budget, candidates)
+
+	if len(bitrates) != len(candidates) {
+		return mediasoup.NewTypeError("Strategy returned %d allocations for %d consumers", len(bitrates), len(candidates))
+	}
+
+	maxBitrate := 0
+
+	for _, bitrate := range bitrates {
+		if bitrate > maxBitrate {
+			maxBitrate = bitrate
+		}
+	}
+
+	allocations := make([]Allocation, len(consumers))
+
+	for i, managed := range consumers {
+		allocation := Allocation{
+			ConsumerId:   candidates[i].ConsumerId,
+			Bitrate:      bitrates[i],
+			SpatialLayer: spatialLayer(bitrates[i], candidates[i].LayerBitrates),
+			Priority:     priority(bitrates[i], maxBitrate),
+		}
+		allocations[i] = allocation
+
+		if applyErr := p.apply(managed, allocation); applyErr != nil && !managed.consumer.Closed() {
+			p.logger.Warn("Rebalance() | applying allocation failed [consumerId:%s]: %v", allocation.ConsumerId, applyErr)
+
+			if err == nil {
+				err = applyErr
+			}
+		}
+	}
+
+	p.locker.Lock()
+	p.allocations = allocations
+	p.locker.Unlock()
+
+	return
+}
+
+// layerBitrates returns the bitrates of the spatial layers the Consumer can
+// receive, a single one for simple Consumers.
+func (p *Policy) layerBitrates(consumer *mediasoup.Consumer) []int {
+	bitrates := p.options.LayerBitrates
+
+	switch consumer.Type() {
+	case mediasoup.ConsumerType_Simulcast, mediasoup.ConsumerType_Svc:
+		if spatialLayers := int(scalabilityMode(consumer).SpatialLayers); spatialLayers < len(bitrates) {
+			return bitrates[:spatialLayers]
+		}
+		return bitrates
+	default:
+		return bitrates[len(bitrates)-1:]
+	}
+}
+
+func (p *Policy) apply(managed *managedConsumer, allocation Allocation) (err error) {
+	consumer := managed.consumer
+
+	if consumer.Type() == mediasoup.ConsumerType_Simulcast || consumer.Type() == mediasoup.ConsumerType_Svc {
+		temporalLayers := scalabilityMode(consumer).TemporalLayers
+		layers := mediasoup.ConsumerLayers{
+			SpatialLayer:  allocation.SpatialLayer,
+			TemporalLayer: temporalLayers - 1,
+		}
+
+		if managed.layers == nil || *managed.layers != layers {
+			if err = consumer.SetPreferredLayers(layers); err != nil {
+				return
+			}
+			managed.layers = &layers
+		}
+	}
+
+	if managed.priority != allocation.Priority {
+		if err = consumer.SetPriority(allocation.Priority); err != nil {
+			return
+		}
+		managed.priority = allocation.Priority
+	}
+
+	return
+}
+
+/**
+ * Whether the policy is closed.
+ */
+func (p *Policy) Closed() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.closed
+}
+
+/**
+ * Stop managing the Consumers, leaving their layers and priorities as they
+ * are.
+ */
+func (p *Policy) Close() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.closed {
+		return mediasoup.ErrAlreadyClosed
+	}
+	p.closed = true
+	p.consumers = nil
+
+	return nil
+}
+
+func scalabilityMode(consumer *mediasoup.Consumer) mediasoup.ScalabilityMode {
+	var mode string
+
+	if encodings := consumer.RtpParameters().Encodings; len(encodings) > 0 {
+		mode = encodings[0].ScalabilityMode
+	}
+
+	return mediasoup.ParseScalabilityMode(mode)
+}
+
+// spatialLayer returns the highest layer whose bitrate fits the allocation,
+// the lowest one if none.
+func spatialLayer(bitrate int, layerBitrates []int) (layer uint8) {
+	for i, layerBitrate := range layerBitrates {
+		if layerBitrate <= bitrate {
+			layer = uint8(i)
+		}
+	}
+	return
+}
+
+// priority maps the allocation to [1, 255], the highest allocation getting
+// 255.
+func priority(bitrate, maxBitrate int) uint32 {
+	if maxBitrate <= 0 {
+		return 1
+	}
+	return uint32(1 + 254*bitrate/maxBitrate)
+}
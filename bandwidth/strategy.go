@@ -0,0 +1,125 @@
+package bandwidth
+
+/**
+ * Candidate is a video Consumer sharing the budget.
+ */
+type Candidate struct {
+	ConsumerId string
+
+	/**
+	 * Whether its Producer is an active speaker (see Policy.SetSpeakers()).
+	 */
+	Speaker bool
+
+	/**
+	 * Bitrates of the spatial layers it can receive, from the lowest.
+	 */
+	LayerBitrates []int
+}
+
+/**
+ * Strategy divides the budget (in bps) between the candidates, returning the
+ * bitrate of every candidate in the same order.
+ */
+type Strategy interface {
+	Allocate(budget int, candidates []Candidate) []int
+}
+
+/**
+ * StrategyFunc is a function implementing Strategy.
+ */
+type StrategyFunc func(budget int, candidates []Candidate) []int
+
+func (f StrategyFunc) Allocate(budget int, candidates []Candidate) []int {
+	return f(budget, candidates)
+}
+
+/**
+ * EqualShare gives the same bitrate to every candidate, the bitrate a
+ * candidate cannot use (above its highest layer) being shared by the others.
+ */
+var EqualShare Strategy = StrategyFunc(func(budget int, candidates []Candidate) []int {
+	indexes := make([]int, len(candidates))
+
+	for i := range candidates {
+		indexes[i] = i
+	}
+
+	allocations := make([]int, len(candidates))
+	share(budget, candidates, indexes, allocations)
+
+	return allocations
+})
+
+/**
+ * SpeakerFirst gives up to the given ratio of the budget (e.g. 0.7) to the
+ * speakers, equally shared, and the rest to the others. The speakers get the
+ * whole budget when there are no others, and the others get what the speakers
+ * cannot use.
+ */
+func SpeakerFirst(speakerRatio float64) Strategy {
+	return StrategyFunc(func(budget int, candidates []Candidate) []int {
+		var speakers, others []int
+
+		for i, candidate := range candidates {
+			if candidate.Speaker {
+				speakers = append(speakers, i)
+			} else {
+				others = append(others, i)
+			}
+		}
+
+		speakersBudget := budget
+
+		if len(others) > 0 {
+			speakersBudget = int(float64(budget) * speakerRatio)
+		}
+
+		allocations := make([]int, len(candidates))
+		left := share(speakersBudget, candidates, speakers, allocations)
+		share(budget-speakersBudget+left, candidates, others, allocations)
+
+		return allocations
+	})
+}
+
+// share divides the budget equally between the given candidates without
+// exceeding their highest layer, returning the unused budget.
+func share(budget int, candidates []Candidate, indexes []int, allocations []int) (left int) {
+	pending := append([]int(nil), indexes...)
+	left = budget
+
+	for len(pending) > 0 && left > 0 {
+		part := left / len(pending)
+		var next []int
+
+		for _, i := range pending {
+			max := maxBitrate(candidates[i])
+			// Candidates which would be capped take their max first.
+			if max-allocations[i] <= part {
+				left -= max - allocations[i]
+				allocations[i] = max
+			} else {
+				next = append(next, i)
+			}
+		}
+
+		if len(next) == len(pending) {
+			for _, i := range next {
+				allocations[i] += part
+				left -= part
+			}
+			break
+		}
+		pending = next
+	}
+
+	return
+}
+
+func maxBitrate(candidate Candidate) int {
+	if len(candidate.LayerBitrates) == 0 {
+		return 0
+	}
+	return candidate.LayerBitrates[len(candidate.LayerBitrates)-1]
+}
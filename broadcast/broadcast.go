@@ -0,0 +1,423 @@
+// Package broadcast fans a Producer out to many Consumers across Workers. A
+// Router can only use one CPU core, so a Producer consumed by thousands of
+// endpoints (e.g. a webinar speaker) must be piped to Routers of other Workers:
+//
+//	broadcaster, err := broadcast.NewBroadcaster(broadcast.Options{
+//		Router:   router,
+//		Producer: producer,
+//		Workers:  workers,
+//	})
+//	// For every viewer:
+//	viewerRouter, err := broadcaster.Router()
+//	transport, err := viewerRouter.CreateWebRtcTransport(...)
+//	consumer, err := broadcaster.Consume(transport, mediasoup.ConsumerOptions{
+//		RtpCapabilities: viewerCaps,
+//	})
+//
+// Router() returns the least loaded Router having less than
+// MaxConsumersPerRouter Consumers, creating a replica Router with the Producer
+// piped into it on another Worker when they are all full.
+package broadcast
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Router of the Producer.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Producer to broadcast.
+	 */
+	Producer *mediasoup.Producer
+
+	/**
+	 * Workers on which the replica Routers are created, one per Worker at
+	 * most. The least loaded one (by number of Routers) is used first.
+	 */
+	Workers []*mediasoup.Worker
+
+	/**
+	 * Media codecs of the replica Routers. Default the ones of Router.
+	 */
+	MediaCodecs []*mediasoup.RtpCodecCapability
+
+	/**
+	 * Number of Consumers of a Router above which a replica is created.
+	 * Default 500.
+	 */
+	MaxConsumersPerRouter int
+
+	/**
+	 * IP of the PipeTransports between the Routers. Default "127.0.0.1".
+	 */
+	ListenIp mediasoup.TransportListenIp
+
+	/**
+	 * Logger of the broadcaster. Default mediasoup.NewLogger("Broadcaster").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Load of a Router of the broadcast.
+ */
+type ReplicaInfo struct {
+	Router *mediasoup.Router
+
+	/**
+	 * Id of the Producer to consume in the Router: the broadcast Producer in
+	 * the origin Router, the pipe Producer in the replicas.
+	 */
+	ProducerId string
+
+	/**
+	 * Number of Consumers created with Consume().
+	 */
+	Consumers int
+}
+
+type replica struct {
+	router    *mediasoup.Router
+	producer  *mediasoup.Producer
+	worker    *mediasoup.Worker
+	consumers int
+}
+
+/**
+ * Broadcaster
+ * @emits replica - (router *mediasoup.Router)
+ * @emits replicaclose - (router *mediasoup.Router)
+ * @emits close
+ */
+type Broadcaster struct {
+	mediasoup.IEventEmitter
+	logger   mediasoup.Logger
+	options  Options
+	locker   sync.Mutex
+	replicas []*replica
+	closed   bool
+}
+
+/**
+ * Start broadcasting the given Producer. The broadcaster is closed with the
+ * Producer or its Router.
+ */
+func NewBroadcaster(options Options) (broadcaster *Broadcaster, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Producer == nil {
+		return nil, mediasoup.NewTypeError("missing Producer")
+	}
+	if len(options.MediaCodecs) == 0 {
+		options.MediaCodecs = mediaCodecs(options.Router.RtpCapabilities())
+	}
+	if options.MaxConsumersPerRouter <= 0 {
+		options.MaxConsumersPerRouter = 500
+	}
+	if len(options.ListenIp.Ip) == 0 {
+		options.ListenIp.Ip = "127.0.0.1"
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("Broadcaster")
+	}
+
+	broadcaster = &Broadcaster{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		options:       options,
+		replicas: []*replica{
+			{router: options.Router, producer: options.Producer},
+		},
+	}
+
+	options.Producer.Observer().On("close", func() {
+		go broadcaster.Close()
+	})
+	options.Router.Observer().On("close", func() {
+		go broadcaster.Close()
+	})
+
+	return
+}
+
+/**
+ * Router in which the next Consumer must be created, creating a replica if
+ * every Router has MaxConsumersPerRouter Consumers. When no Worker is left,
+ * the least loaded Router is returned anyway.
+ */
+func (b *Broadcaster) Router() (router *mediasoup.Router, err error) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	if b.closed {
+		return nil, mediasoup.NewInvalidStateError("Broadcaster closed")
+	}
+
+	if index := leastLoaded(b.replicaLoads(), b.options.MaxConsumersPerRouter); index >= 0 {
+		return b.replicas[index].router, nil
+	}
+
+	worker := b.nextWorker()
+
+	if worker == nil {
+		b.logger.Warn("Router() | every Router is full and no Worker is left")
+
+		return b.replicas[leastLoaded(b.replicaLoads(), 0)].router, nil
+	}
+
+	r, err := b.createReplica(worker)
+	if err != nil {
+		return
+	}
+
+	return r.router, nil
+}
+
+/**
+ * Consume the broadcast Producer with a transport of one of the Routers of
+ * the broadcast, usually the one returned by Router(). ProducerId of the
+ * options is ignored.
+ */
+func (b *Broadcaster) Consume(transport mediasoup.ITransport, options mediasoup.ConsumerOptions, opts ...mediasoup.ConsumerOption) (consumer *mediasoup.Consumer, err error) {
+	b.locker.Lock()
+
+	var r *replica
+
+	for _, candidate := range b.replicas {
+		for _, t := range candidate.router.Transports() {
+			if t.Id() == transport.Id() {
+				r = candidate
+				break
+			}
+		}
+		if r != nil {
+			break
+		}
+	}
+
+	b.locker.Unlock()
+
+	if r == nil {
+		return nil, mediasoup.NewTypeError("transport not in a Router of the broadcast")
+	}
+
+	options.ProducerId = r.producer.Id()
+
+	if consumer, err = transport.Consume(options, opts...); err != nil {
+		return
+	}
+
+	b.locker.Lock()
+	r.consumers++
+	b.locker.Unlock()
+
+	consumer.Observer().On("close", func() {
+		b.locker.Lock()
+		r.consumers--
+		b.locker.Unlock()
+	})
+
+	return
+}
+
+/**
+ * Routers of the broadcast, the origin first.
+ */
+func (b *Broadcaster) Replicas() []ReplicaInfo {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	infos := make([]ReplicaInfo, 0, len(b.replicas))
+
+	for _, r := range b.replicas {
+		infos = append(infos, ReplicaInfo{
+			Router:     r.router,
+			ProducerId: r.producer.Id(),
+			Consumers:  r.consumers,
+		})
+	}
+
+	return infos
+}
+
+/**
+ * Whether the broadcaster is closed.
+ */
+func (b *Broadcaster) Closed() bool {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	return b.closed
+}
+
+/**
+ * Close the replica Routers, and so their Consumers.
+ */
+func (b *Broadcaster) Close() error {
+	b.locker.Lock()
+
+	if b.closed {
+		b.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	b.logger.Debug("Close()")
+
+	b.closed = true
+	replicas := b.replicas[1:]
+	b.replicas = b.replicas[:1]
+
+	b.locker.Unlock()
+
+	for _, r := range replicas {
+		r.router.Close()
+	}
+
+	b.SafeEmit("close")
+
+	return nil
+}
+
+// replicaLoads returns the number of Consumers of every replica, the locker
+// being held.
+func (b *Broadcaster) replicaLoads() []int {
+	loads := make([]int, len(b.replicas))
+
+	for i, r := range b.replicas {
+		loads[i] = r.consumers
+	}
+
+	return loads
+}
+
+// nextWorker returns the least loaded Worker without replica, the locker
+// being held.
+func (b *Broadcaster) nextWorker() *mediasoup.Worker {
+	var (
+		workers []*mediasoup.Worker
+		loads   []int
+	)
+
+	for _, worker := range b.options.Workers {
+		if worker.Closed() || b.hasReplica(worker) {
+			continue
+		}
+		workers = append(workers, worker)
+		loads = append(loads, len(worker.Routers()))
+	}
+
+	if index := leastLoaded(loads, 0); index >= 0 {
+		return workers[index]
+	}
+
+	return nil
+}
+
+func (b *Broadcaster) hasReplica(worker *mediasoup.Worker) bool {
+	for _, r := range b.replicas {
+		if r.worker == worker {
+			return true
+		}
+	}
+
+	// The origin Router may belong to the Worker.
+	for _, router := range worker.Routers() {
+		if router == b.options.Router {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createReplica pipes the Producer into a new Router of the Worker, the
+// locker being held.
+func (b *Broadcaster) createReplica(worker *mediasoup.Worker) (r *replica, err error) {
+	b.logger.Debug("createReplica() [pid:%d]", worker.Pid())
+
+	router, err := worker.CreateRouter(mediasoup.RouterOptions{
+		MediaCodecs: b.options.MediaCodecs,
+	})
+	if err != nil {
+		return
+	}
+
+	result, err := b.options.Router.PipeToRouter(mediasoup.PipeToRouterOptions{
+		ProducerId: b.options.Producer.Id(),
+		Router:     router,
+		ListenIp:   b.options.ListenIp,
+	})
+	if err != nil {
+		router.Close()
+		return
+	}
+
+	r = &replica{
+		router:   router,
+		producer: result.PipeProducer,
+		worker:   worker,
+	}
+	b.replicas = append(b.replicas, r)
+
+	router.Observer().On("close", func() {
+		b.removeReplica(r)
+	})
+
+	b.SafeEmit("replica", router)
+
+	return
+}
+
+func (b *Broadcaster) removeReplica(r *replica) {
+	b.logger.Debug("replica closed [routerId:%s]", r.router.Id())
+
+	b.locker.Lock()
+
+	for i, candidate := range b.replicas {
+		if candidate == r {
+			b.replicas = append(b.replicas[:i:i], b.replicas[i+1:]...)
+			break
+		}
+	}
+
+	b.locker.Unlock()
+
+	b.SafeEmit("replicaclose", r.router)
+}
+
+// leastLoaded returns the index of the lowest load below max (no limit if 0),
+// the first one if several, or -1.
+func leastLoaded(loads []int, max int) int {
+	index := -1
+
+	for i, load := range loads {
+		if max > 0 && load >= max {
+			continue
+		}
+		if index < 0 || load < loads[index] {
+			index = i
+		}
+	}
+
+	return index
+}
+
+// mediaCodecs returns the media codecs creating a Router with the given
+// capabilities, RTX codecs being added by the Router.
+func mediaCodecs(caps mediasoup.RtpCapabilities) (codecs []*mediasoup.RtpCodecCapability) {
+	for _, codec := range caps.Codecs {
+		if strings.HasSuffix(strings.ToLower(codec.MimeType), "/rtx") {
+			continue
+		}
+		clone := *codec
+		codecs = append(codecs, &clone)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeastLoaded(t *testing.T) {
+	assert.Equal(t, -1, leastLoaded(nil, 0))
+	assert.Equal(t, 0, leastLoaded([]int{3, 3, 5}, 0))
+	assert.Equal(t, 1, leastLoaded([]int{3, 2, 5}, 0))
+	assert.Equal(t, 2, leastLoaded([]int{10, 10, 9}, 10))
+	assert.Equal(t, -1, leastLoaded([]int{10, 12}, 10))
+	assert.Equal(t, 0, leastLoaded([]int{10, 12}, 0))
+}
+
+func TestMediaCodecs(t *testing.T) {
+	caps := mediasoup.RtpCapabilities{
+		Codecs: []*mediasoup.RtpCodecCapability{
+			{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+			{Kind: mediasoup.MediaKind_Video, MimeType: "video/VP8", ClockRate: 90000},
+			{Kind: mediasoup.MediaKind_Video, MimeType: "video/rtx", ClockRate: 90000},
+		},
+	}
+
+	codecs := mediaCodecs(caps)
+	require.Len(t, codecs, 2)
+	assert.Equal(t, "audio/opus", codecs[0].MimeType)
+	assert.Equal(t, "video/VP8", codecs[1].MimeType)
+
+	codecs[0].MimeType = "audio/foo"
+	assert.Equal(t, "audio/opus", caps.Codecs[0].MimeType)
+}
+
+func TestNewBroadcaster_InvalidOptions(t *testing.T) {
+	_, err := NewBroadcaster(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewBroadcaster(Options{Router: &mediasoup.Router{}})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
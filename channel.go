@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,8 +9,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/jiyeyuran/mediasoup-go/netstring"
 )
 
 const (
@@ -31,7 +30,7 @@ func (r workerResponse) Unmarshal(v interface{}) error {
 	if len(r.data) == 0 {
 		return nil
 	}
-	return json.Unmarshal([]byte(r.data), v)
+	return unmarshalJSON(r.data, v)
 }
 
 func (r workerResponse) Data() []byte {
@@ -42,10 +41,44 @@ func (r workerResponse) Err() error {
 	return r.err
 }
 
+// channelMessage is a response, a notification or a log line received from
+// the worker.
+type channelMessage struct {
+	// response
+	Id       int64  `json:"id,omitempty"`
+	Accepted bool   `json:"accepted,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// notification
+	TargetId string `json:"targetId,omitempty"`
+	Event    string `json:"event,omitempty"`
+	// common data
+	Data json.RawMessage `json:"data,omitempty"`
+	// log line
+	LogLevel WorkerLogLevel `json:"-"`
+	Log      []byte         `json:"-"`
+	// worker stdout, printed as is
+	Dump []byte `json:"-"`
+}
+
+/**
+ * Information of a request sent to the worker, once it is finished.
+ */
+type ChannelRequestInfo struct {
+	// Request method (e.g. "router.createWebRtcTransport").
+	Method string
+	// Time elapsed until the response was received or the request failed.
+	Duration time.Duration
+	// Error of the request, if any.
+	Error error
+}
+
 type sentInfo struct {
-	id     int64
-	method string
-	respCh chan workerResponse
+	id       int64
+	method   string
+	internal interface{}
+	start    time.Time
+	respCh   chan workerResponse
 }
 
 type Channel struct {
@@ -60,24 +93,50 @@ type Channel struct {
 	sentsLen       int64
 	closeCh        chan struct{}
 	startCh        chan struct{}
+	codec          channelCodec
+	decoder        channelFrameDecoder
+	counters       channelCounters
+	writer         *channelWriter
+	// Handler of the worker log lines, set before Start().
+	logHandler func(WorkerLog)
+	// Recorder of the forensics of the Worker, set before Start().
+	recorder *forensicsRecorder
+	// Timeout and retries of the requests by method, set before Start().
+	policies map[string]RequestPolicy
+	// Reader, processor and writer goroutines.
+	goroutines sync.WaitGroup
+	// Set while the processor goroutine runs the handlers of a message.
+	dispatching int32
+	// Error of the requests pending when the worker process died.
+	channelExit
 }
 
-func newChannel(producerSocket, consumerSocket net.Conn, pid int) *Channel {
-	logger := NewLogger("Channel")
+func newChannel(producerSocket, consumerSocket net.Conn, pid int, codec channelCodec, loggerContext loggerContext) *Channel {
+	logger := loggerContext.newLogger("Channel")
 
 	logger.Debug("constructor()")
 
 	channel := &Channel{
-		IEventEmitter:  NewEventEmitter(),
+		IEventEmitter:  newAsyncEventEmitter(),
 		logger:         logger,
 		producerSocket: producerSocket,
 		consumerSocket: consumerSocket,
 		pid:            pid,
 		closeCh:        make(chan struct{}),
+		channelExit:    newChannelExit(),
 		startCh:        make(chan struct{}),
+		codec:          codec,
+		decoder:        codec.newFrameDecoder(),
 	}
 
-	go channel.runReadLoop()
+	channel.writer = newChannelWriter(producerSocket, channel.closeCh, &channel.counters)
+
+	channel.goroutines.Add(2)
+	goWithWorkerLabels(pid, "channel-reader", channel.runReadLoop)
+	goWithWorkerLabels(pid, "channel-writer", func() {
+		defer channel.goroutines.Done()
+		channel.writer.run()
+	})
 
 	return channel
 }
@@ -102,7 +161,66 @@ func (c *Channel) Closed() bool {
 	return atomic.LoadInt32(&c.closed) > 0
 }
 
+// wait waits at most timeout for the goroutines of the closed Channel to
+// return, reporting whether they did.
+func (c *Channel) wait(timeout time.Duration) bool {
+	return waitTimeout(&c.goroutines, timeout)
+}
+
+// isDispatching reports whether the processor goroutine is running the
+// handlers of a message, which may be the caller.
+func (c *Channel) isDispatching() bool {
+	return atomic.LoadInt32(&c.dispatching) > 0
+}
+
+/**
+ * State of the Channel.
+ */
+func (c *Channel) Stats() ChannelStats {
+	return c.counters.stats(atomic.LoadInt64(&c.sentsLen), len(c.decoder.Result()))
+}
+
 func (c *Channel) Request(method string, internal interface{}, data ...interface{}) (rsp workerResponse) {
+	return c.RequestContext(context.Background(), method, internal, data...)
+}
+
+/**
+ * Send a request to the worker, waiting for its response until the context is
+ * done. The worker still processes a request whose context is done. A request
+ * whose response is not received in time fails with ErrRequestTimeout, being
+ * sent again first as many times as the RequestPolicy of its method allows.
+ */
+func (c *Channel) RequestContext(ctx context.Context, method string, internal interface{}, data ...interface{}) (rsp workerResponse) {
+	policy := requestPolicy(c.policies, method)
+
+	for retries := policy.Retries; ; retries-- {
+		rsp = c.request(ctx, method, internal, policy.Timeout, data...)
+
+		if retries <= 0 || !errors.Is(rsp.err, ErrRequestTimeout) || !isIdempotentMethod(method) {
+			return
+		}
+
+		c.logger.Warn("request timed out, retrying [method:%s, retries:%d]", method, retries)
+
+		timer := time.NewTimer(policy.RetryDelay)
+
+		select {
+		case <-timer.C:
+		case <-c.closeCh:
+			timer.Stop()
+			rsp.err = c.closedError()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			rsp.err = ctx.Err()
+			return
+		}
+	}
+}
+
+// request sends a request once, waiting for its response at most timeout if
+// set, else the default request timeout.
+func (c *Channel) request(ctx context.Context, method string, internal interface{}, timeout time.Duration, data ...interface{}) (rsp workerResponse) {
 	if c.Closed() {
 		rsp.err = NewInvalidStateError("PayloadChannel closed")
 		return
@@ -117,10 +235,28 @@ func (c *Channel) Request(method string, internal interface{}, data ...interface
 
 	c.logger.Debug("request() [method:%s, id:%d]", method, id)
 
+	start := time.Now()
+
+	defer func() {
+		message := ForensicsMessage{Type: "response", Id: id, Method: method, Duration: time.Since(start)}
+		if rsp.err != nil {
+			message.Error = rsp.err.Error()
+		}
+		c.recorder.recordMessage(message, rsp.data)
+
+		c.Emit("@request", ChannelRequestInfo{
+			Method:   method,
+			Duration: time.Since(start),
+			Error:    rsp.err,
+		})
+	}()
+
 	sent := sentInfo{
-		id:     id,
-		method: method,
-		respCh: make(chan workerResponse),
+		id:       id,
+		method:   method,
+		internal: internal,
+		start:    start,
+		respCh:   make(chan workerResponse, 1),
 	}
 	c.sents.Store(id, sent)
 
@@ -131,47 +267,75 @@ func (c *Channel) Request(method string, internal interface{}, data ...interface
 		atomic.AddInt64(&c.sentsLen, -1)
 	}()
 
-	req := H{
-		"id":       id,
-		"method":   method,
-		"internal": internal,
-	}
+	var reqData interface{}
 	if len(data) > 0 {
-		req["data"] = data[0]
+		reqData = data[0]
+	}
+	rawData, err := c.codec.encodeRequest(id, method, internal, reqData)
+	if err != nil {
+		rsp.err = err
+		return
 	}
-	rawData, _ := json.Marshal(req)
 
-	ns := netstring.Encode(rawData)
+	ns := c.codec.frame(rawData)
 
 	if len(ns) > NS_MESSAGE_MAX_LEN {
 		rsp.err = errors.New("Channel request too big")
 		return
 	}
 
-	if _, rsp.err = c.producerSocket.Write(ns); rsp.err != nil {
+	if rsp.err = ctx.Err(); rsp.err != nil {
 		return
 	}
 
-	timeout := 1000 * (15 + (0.1 * float64(size)))
-	timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+	if rsp.err = c.writer.write(ns); rsp.err != nil {
+		return
+	}
+
+	c.recorder.recordMessage(ForensicsMessage{Type: "request", Id: id, Method: method}, rawData)
+
+	if timeout <= 0 {
+		timeout = requestTimeout(size)
+	}
+	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
 	case rsp = <-sent.respCh:
 		return
 	case <-timer.C:
-		rsp.err = errors.New("Channel request timeout")
+		rsp.err = fmt.Errorf("%w [method:%s, id:%d]", ErrRequestTimeout, method, id)
 	case <-c.closeCh:
-		rsp.err = NewInvalidStateError("Channel closed")
+		rsp.err = c.closedError()
+	case <-ctx.Done():
+		rsp.err = ctx.Err()
+	}
+
+	return
+}
+
+// requestCreation sends the request creating an entity, closing it in the
+// worker with closeMethod if the context is done first, as the worker may have
+// created it anyway.
+func (c *Channel) requestCreation(ctx context.Context, method, closeMethod string, internal internalData, data ...interface{}) (rsp workerResponse) {
+	rsp = c.RequestContext(ctx, method, internal, data...)
+
+	if rsp.err != nil && ctx.Err() != nil {
+		go c.Request(closeMethod, internal)
 	}
 
 	return
 }
 
 func (c *Channel) runReadLoop() {
-	decoder := netstring.NewDecoder()
+	defer c.goroutines.Done()
+
+	decoder := c.decoder
+
+	c.goroutines.Add(1)
+	goWithWorkerLabels(c.pid, "channel-processor", func() {
+		defer c.goroutines.Done()
 
-	go func() {
 		select {
 		// wait start signal
 		case <-c.startCh:
@@ -182,12 +346,16 @@ func (c *Channel) runReadLoop() {
 		for {
 			select {
 			case nsPayload := <-decoder.Result():
+				start := time.Now()
+				atomic.StoreInt32(&c.dispatching, 1)
 				c.processNSPayload(nsPayload)
+				atomic.StoreInt32(&c.dispatching, 0)
+				c.counters.dispatchedSince(start)
 			case <-c.closeCh:
 				return
 			}
 		}
-	}()
+	})
 
 	buf := make([]byte, NS_PAYLOAD_MAX_LEN)
 
@@ -195,6 +363,10 @@ func (c *Channel) runReadLoop() {
 		n, err := c.consumerSocket.Read(buf)
 		if err != nil {
 			c.logger.Error("Channel error: %s", err)
+			// Not closed by Close(), the worker process is likely dead.
+			if !c.Closed() {
+				atomic.StoreInt32(&c.lost, 1)
+			}
 			break
 		}
 		data := buf[:n]
@@ -204,6 +376,7 @@ func (c *Channel) runReadLoop() {
 		if decoder.Length() > NS_PAYLOAD_MAX_LEN {
 			c.logger.Error("receiving buffer is full, discarding all data into it")
 			decoder.Reset()
+			c.counters.drop()
 		}
 	}
 
@@ -211,41 +384,38 @@ func (c *Channel) runReadLoop() {
 }
 
 func (c *Channel) processNSPayload(nsPayload []byte) {
-	switch nsPayload[0] {
-	case '{':
-		c.processMessage(nsPayload)
-	case 'D':
-		c.logger.Debug("[pid:%d] %s", c.pid, nsPayload[1:])
-	case 'W':
-		c.logger.Warn("[pid:%d] %s", c.pid, nsPayload[1:])
-	case 'E':
-		c.logger.Error("[pid:%d] %s", c.pid, nsPayload[1:])
-	case 'X':
-		fmt.Printf("%s\n", nsPayload[1:])
+	msg, err := c.codec.decodeMessage(nsPayload)
+	if err != nil {
+		c.logger.Warn("[pid:%d] %s: %s", c.pid, err, nsPayload)
+		return
+	}
+
+	switch {
+	case len(msg.LogLevel) > 0:
+		c.handleLog(msg.LogLevel, msg.Log)
+	case msg.Dump != nil:
+		fmt.Printf("%s\n", msg.Dump)
 	default:
-		c.logger.Warn("[pid:%d] unexpected data: %s", nsPayload[1:])
+		c.processMessage(msg)
 	}
 }
 
-func (c *Channel) processMessage(nsPayload []byte) {
-	var msg struct {
-		// response
-		Id       int64  `json:"id,omitempty"`
-		Accepted bool   `json:"accepted,omitempty"`
-		Error    string `json:"error,omitempty"`
-		Reason   string `json:"reason,omitempty"`
-		// notification
-		TargetId string `json:"targetId,omitempty"`
-		Event    string `json:"event,omitempty"`
-		// common data
-		Data json.RawMessage `json:"data,omitempty"`
+func (c *Channel) handleLog(level WorkerLogLevel, line []byte) {
+	log := parseWorkerLog(c.pid, level, string(line))
+	log.writeTo(c.logger)
+	c.recorder.recordLog("channel", log)
+
+	if c.logHandler != nil {
+		c.logHandler(log)
 	}
-	json.Unmarshal(nsPayload, &msg)
+}
 
+func (c *Channel) processMessage(msg channelMessage) {
 	if msg.Id > 0 {
 		value, ok := c.sents.Load(msg.Id)
 		if !ok {
 			c.logger.Error("received response does not match any sent request [id:%d]", msg.Id)
+			c.counters.drop()
 			return
 		}
 		sent := value.(sentInfo)
@@ -257,15 +427,12 @@ func (c *Channel) processMessage(nsPayload []byte) {
 		} else if len(msg.Error) > 0 {
 			c.logger.Warn("request failed [method:%s, id:%d]: %s", sent.method, sent.id, msg.Reason)
 
-			if msg.Error == "TypeError" {
-				sent.respCh <- workerResponse{err: NewTypeError(msg.Reason)}
-			} else {
-				sent.respCh <- workerResponse{err: errors.New(msg.Reason)}
-			}
+			sent.respCh <- workerResponse{err: newWorkerError(msg.Error, msg.Reason, sent.method, sent.internal)}
 		} else {
-			c.logger.Error("received response is not accepted nor rejected [method:%s, id:%s]", sent.method, sent.id)
+			c.logger.Error("received response is not accepted nor rejected [method:%s, id:%d]", sent.method, sent.id)
 		}
 	} else if len(msg.TargetId) > 0 && len(msg.Event) > 0 {
+		c.recorder.recordMessage(ForensicsMessage{Type: "notification", Method: msg.Event, TargetId: msg.TargetId}, msg.Data)
 		c.SafeEmit(msg.TargetId, msg.Event, msg.Data)
 	} else {
 		c.logger.Error("received message is not a response nor a notification")
@@ -0,0 +1,89 @@
+package mediasoup
+
+import "context"
+
+/**
+ * Response of a request sent by Channel.RequestAsync(), received once Done()
+ * is closed.
+ */
+type PendingResponse struct {
+	method string
+	done   chan struct{}
+	rsp    workerResponse
+}
+
+/**
+ * Send a request to the worker without waiting for its response, e.g. to send
+ * many requests concurrently and wait for all of them with WaitResponses().
+ */
+func (c *Channel) RequestAsync(method string, internal interface{}, data ...interface{}) *PendingResponse {
+	return c.RequestAsyncContext(context.Background(), method, internal, data...)
+}
+
+/**
+ * Like RequestAsync(), the response being waited for until the context is
+ * done.
+ */
+func (c *Channel) RequestAsyncContext(ctx context.Context, method string, internal interface{}, data ...interface{}) *PendingResponse {
+	pending := &PendingResponse{
+		method: method,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(pending.done)
+
+		pending.rsp = c.RequestContext(ctx, method, internal, data...)
+	}()
+
+	return pending
+}
+
+/**
+ * Method of the request.
+ */
+func (p *PendingResponse) Method() string {
+	return p.method
+}
+
+/**
+ * Closed once the response is received or the request failed.
+ */
+func (p *PendingResponse) Done() <-chan struct{} {
+	return p.done
+}
+
+/**
+ * Wait for the response.
+ */
+func (p *PendingResponse) Wait() workerResponse {
+	<-p.done
+	return p.rsp
+}
+
+/**
+ * Wait for the response, returning its error.
+ */
+func (p *PendingResponse) Err() error {
+	return p.Wait().Err()
+}
+
+/**
+ * Wait for the response, unmarshaling its data into v.
+ */
+func (p *PendingResponse) Unmarshal(v interface{}) error {
+	return p.Wait().Unmarshal(v)
+}
+
+/**
+ * Wait for all the responses, returning the error of the first failed one
+ * (in the order of the responses).
+ */
+func WaitResponses(responses ...*PendingResponse) (err error) {
+	for _, response := range responses {
+		if e := response.Err(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return
+}
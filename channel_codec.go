@@ -0,0 +1,110 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jiyeyuran/mediasoup-go/netstring"
+)
+
+// channelFrameDecoder splits the bytes received from the worker process into
+// messages.
+type channelFrameDecoder interface {
+	Feed(data []byte)
+	Result() chan []byte
+	Length() int
+	Reset()
+}
+
+// channelCodec is the format of the messages exchanged with the worker process
+// over the Channel and the PayloadChannel, the one of a WorkerProtocol.
+type channelCodec interface {
+	// protocol returns the WorkerProtocol of the codec.
+	protocol() WorkerProtocol
+
+	// newFrameDecoder returns a decoder of the received messages.
+	newFrameDecoder() channelFrameDecoder
+
+	// frame returns the bytes sending the given message or payload.
+	frame(message []byte) []byte
+
+	// encodeRequest returns the message of a request.
+	encodeRequest(id int64, method string, internal, data interface{}) ([]byte, error)
+
+	// encodeNotification returns the message of a notification.
+	encodeNotification(event string, internal, data interface{}) ([]byte, error)
+
+	// decodeMessage decodes a response, a notification or a log line.
+	decodeMessage(message []byte) (msg channelMessage, err error)
+}
+
+// newChannelCodec returns the codec of the given protocol, UnsupportedError
+// if this library does not speak it.
+func newChannelCodec(protocol WorkerProtocol) (channelCodec, error) {
+	switch protocol {
+	case WorkerProtocol_JSON:
+		return jsonChannelCodec{}, nil
+	default:
+		return nil, NewUnsupportedError("%s channel protocol not implemented", protocol)
+	}
+}
+
+// jsonChannelCodec is the codec of WorkerProtocol_JSON: JSON messages and log
+// lines in netstrings.
+type jsonChannelCodec struct{}
+
+func (jsonChannelCodec) protocol() WorkerProtocol {
+	return WorkerProtocol_JSON
+}
+
+func (jsonChannelCodec) newFrameDecoder() channelFrameDecoder {
+	return netstring.NewDecoder()
+}
+
+func (jsonChannelCodec) frame(message []byte) []byte {
+	return netstring.Encode(message)
+}
+
+func (jsonChannelCodec) encodeRequest(id int64, method string, internal, data interface{}) ([]byte, error) {
+	req := H{
+		"id":       id,
+		"method":   method,
+		"internal": internal,
+	}
+	if data != nil {
+		req["data"] = data
+	}
+	return json.Marshal(req)
+}
+
+func (jsonChannelCodec) encodeNotification(event string, internal, data interface{}) ([]byte, error) {
+	return json.Marshal(H{
+		"event":    event,
+		"internal": internal,
+		"data":     data,
+	})
+}
+
+func (jsonChannelCodec) decodeMessage(message []byte) (msg channelMessage, err error) {
+	if len(message) == 0 {
+		err = errors.New("unexpected empty data")
+		return
+	}
+
+	switch message[0] {
+	case '{':
+		err = unmarshalJSON(message, &msg)
+	case 'D':
+		msg.LogLevel, msg.Log = WorkerLogLevel_Debug, message[1:]
+	case 'W':
+		msg.LogLevel, msg.Log = WorkerLogLevel_Warn, message[1:]
+	case 'E':
+		msg.LogLevel, msg.Log = WorkerLogLevel_Error, message[1:]
+	case 'X':
+		msg.Dump = message[1:]
+	default:
+		err = errors.New("unexpected data")
+	}
+
+	return
+}
@@ -0,0 +1,60 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewChannelCodec(t *testing.T) {
+	codec, err := newChannelCodec(WorkerProtocol_JSON)
+	require.NoError(t, err)
+	assert.Equal(t, WorkerProtocol_JSON, codec.protocol())
+
+	_, err = newChannelCodec(WorkerProtocol_Flatbuffers)
+	assert.IsType(t, UnsupportedError{}, err)
+}
+
+func TestJsonChannelCodec(t *testing.T) {
+	codec := jsonChannelCodec{}
+
+	message, err := codec.encodeRequest(1, "router.close", H{"routerId": "r"}, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"method":"router.close","internal":{"routerId":"r"}}`, string(message))
+
+	message, err = codec.encodeNotification("dataConsumer.send", H{"dataConsumerId": "d"}, H{"ppid": 51})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"event":"dataConsumer.send","internal":{"dataConsumerId":"d"},"data":{"ppid":51}}`, string(message))
+
+	assert.Equal(t, "5:hello,", string(codec.frame([]byte("hello"))))
+
+	decoder := codec.newFrameDecoder()
+	decoder.Feed(codec.frame([]byte(`{"id":2,"accepted":true,"data":1}`)))
+	msg, err := codec.decodeMessage(<-decoder.Result())
+	require.NoError(t, err)
+	assert.Equal(t, channelMessage{Id: 2, Accepted: true, Data: json.RawMessage("1")}, msg)
+
+	msg, err = codec.decodeMessage([]byte("Wsomething happened"))
+	require.NoError(t, err)
+	assert.Equal(t, WorkerLogLevel_Warn, msg.LogLevel)
+	assert.Equal(t, "something happened", string(msg.Log))
+
+	msg, err = codec.decodeMessage([]byte("Xdump"))
+	require.NoError(t, err)
+	assert.Equal(t, "dump", string(msg.Dump))
+
+	_, err = codec.decodeMessage(nil)
+	assert.Error(t, err)
+	_, err = codec.decodeMessage([]byte("?"))
+	assert.Error(t, err)
+}
+
+func TestWorker_Protocol(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	assert.Equal(t, WorkerProtocol_JSON, mock.Protocol())
+}
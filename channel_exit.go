@@ -0,0 +1,61 @@
+package mediasoup
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Maximum time a request pending when the socket with the worker process was
+// lost waits for its exit status, to fail with ErrWorkerDied.
+const channelExitTimeout = time.Second
+
+// channelExit fails the requests of a Channel or PayloadChannel pending when
+// the worker process died with the error of its death, wrapping
+// ErrWorkerDied, instead of a generic "Channel closed".
+type channelExit struct {
+	// Set when the socket with the worker process was lost.
+	lost    int32
+	exitCh  chan struct{}
+	exitErr error
+	once    sync.Once
+}
+
+func newChannelExit() channelExit {
+	return channelExit{exitCh: make(chan struct{})}
+}
+
+// workerExited gives the error of the death of the worker process, nil if it
+// was closed.
+func (e *channelExit) workerExited(err error) {
+	e.once.Do(func() {
+		e.exitErr = err
+		close(e.exitCh)
+	})
+}
+
+// closedError returns the error of the requests failed as the Channel is
+// closed, waiting for the exit status of the worker process if the socket
+// with it was lost.
+func (e *channelExit) closedError() error {
+	select {
+	case <-e.exitCh:
+	default:
+		if atomic.LoadInt32(&e.lost) == 0 {
+			return NewInvalidStateError("Channel closed")
+		}
+		timer := time.NewTimer(channelExitTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-e.exitCh:
+		case <-timer.C:
+			return ErrWorkerDied
+		}
+	}
+
+	if e.exitErr != nil {
+		return e.exitErr
+	}
+	return NewInvalidStateError("Channel closed")
+}
@@ -0,0 +1,60 @@
+package mediasoup
+
+import (
+	"strings"
+	"time"
+)
+
+/**
+ * Timeout and retries of the Channel requests with a method, see
+ * WithRequestPolicy().
+ */
+type RequestPolicy struct {
+	/**
+	 * Time to wait for the response, instead of Config.RequestTimeout
+	 * increased by the pending requests. Default the latter.
+	 */
+	Timeout time.Duration
+
+	/**
+	 * Number of times a request which timed out is sent again. Only idempotent
+	 * methods ("*.dump", "*.getStats") are retried.
+	 */
+	Retries int
+
+	/**
+	 * Time to wait before sending a request again.
+	 */
+	RetryDelay time.Duration
+}
+
+// validate checks the policy given for the method (or pattern) key.
+func (p RequestPolicy) validate(key string) error {
+	if p.Timeout < 0 || p.Retries < 0 || p.RetryDelay < 0 {
+		return NewTypeError("negative request policy of %q", key)
+	}
+	if p.Retries > 0 && !isIdempotentMethod(key) {
+		return NewTypeError("retried requests of %q are not idempotent", key)
+	}
+	return nil
+}
+
+// isIdempotentMethod reports whether requests with the method (or pattern,
+// e.g. "*.dump") can be sent again without side effects.
+func isIdempotentMethod(method string) bool {
+	return strings.HasSuffix(method, ".dump") || strings.HasSuffix(method, ".getStats")
+}
+
+// requestPolicy returns the policy of the method: the one given for it, else
+// for "*.<action>", else for "*".
+func requestPolicy(policies map[string]RequestPolicy, method string) RequestPolicy {
+	if policy, ok := policies[method]; ok {
+		return policy
+	}
+	if i := strings.IndexByte(method, '.'); i >= 0 {
+		if policy, ok := policies["*"+method[i:]]; ok {
+			return policy
+		}
+	}
+	return policies["*"]
+}
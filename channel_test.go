@@ -0,0 +1,158 @@
+package mediasoup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go/netstring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChannel returns a Channel with a fake worker answering the requests
+// with the "accept" method only.
+func newTestChannel(t *testing.T) *Channel {
+	producerSocket, workerReader := net.Pipe()
+	consumerSocket, workerWriter := net.Pipe()
+
+	channel := newChannel(producerSocket, consumerSocket, 0, jsonChannelCodec{}, loggerContext{})
+	channel.Start()
+
+	t.Cleanup(func() {
+		channel.Close()
+		workerReader.Close()
+		workerWriter.Close()
+	})
+
+	go func() {
+		decoder := netstring.NewDecoder()
+		buf := make([]byte, 4096)
+
+		for {
+			n, err := workerReader.Read(buf)
+			if err != nil {
+				return
+			}
+			decoder.Feed(buf[:n])
+
+			for len(decoder.Result()) > 0 {
+				var req struct {
+					Id     int64
+					Method string
+				}
+				json.Unmarshal(<-decoder.Result(), &req)
+
+				if req.Method == "accept" {
+					data, _ := json.Marshal(H{"id": req.Id, "accepted": true, "data": H{"value": 1}})
+					workerWriter.Write(netstring.Encode(data))
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+func TestChannelRequestContext(t *testing.T) {
+	channel := newTestChannel(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, channel.RequestContext(ctx, "accept", nil).Err())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, channel.RequestContext(ctx, "ignore", nil).Err())
+
+	var data struct{ Value int }
+
+	require.NoError(t, channel.RequestContext(context.Background(), "accept", nil).Unmarshal(&data))
+	assert.Equal(t, 1, data.Value)
+	assert.EqualValues(t, 0, channel.Stats().PendingRequests)
+}
+
+func TestChannelRequestPolicy(t *testing.T) {
+	channel := newTestChannel(t)
+	channel.policies = map[string]RequestPolicy{
+		"*.dump": {Timeout: 20 * time.Millisecond, Retries: 2},
+		"*":      {Timeout: 20 * time.Millisecond},
+	}
+
+	var requests int32
+	channel.On("@request", func(ChannelRequestInfo) { atomic.AddInt32(&requests, 1) })
+
+	err := channel.Request("ignore.dump", nil).Err()
+	assert.True(t, errors.Is(err, ErrRequestTimeout))
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+
+	start := time.Now()
+	err = channel.Request("ignore.close", nil).Err()
+	assert.True(t, errors.Is(err, ErrRequestTimeout))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+
+	assert.NoError(t, channel.Request("accept", nil).Err())
+}
+
+func TestRequestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RequestPolicy{Retries: 1}.validate("*.getStats"))
+	assert.NoError(t, RequestPolicy{Timeout: time.Second}.validate("*"))
+	assert.IsType(t, TypeError{}, RequestPolicy{Retries: 1}.validate("*"))
+	assert.IsType(t, TypeError{}, RequestPolicy{Timeout: -1}.validate("router.dump"))
+}
+
+func TestChannelRequestAsync(t *testing.T) {
+	channel := newTestChannel(t)
+	channel.policies = map[string]RequestPolicy{"ignore": {Timeout: 50 * time.Millisecond}}
+
+	var responses []*PendingResponse
+
+	for i := 0; i < 50; i++ {
+		responses = append(responses, channel.RequestAsync("accept", nil))
+	}
+	require.NoError(t, WaitResponses(responses...))
+
+	for _, response := range responses {
+		var data struct{ Value int }
+
+		assert.Equal(t, "accept", response.Method())
+		require.NoError(t, response.Unmarshal(&data))
+		assert.Equal(t, 1, data.Value)
+	}
+
+	ignored := channel.RequestAsync("ignore", nil)
+
+	select {
+	case <-ignored.Done():
+		t.Fatal("done before timeout")
+	default:
+	}
+
+	err := WaitResponses(channel.RequestAsync("accept", nil), ignored)
+	assert.True(t, errors.Is(err, ErrRequestTimeout))
+	assert.EqualValues(t, 0, channel.Stats().PendingRequests)
+}
+
+func TestChannelWriteBatching(t *testing.T) {
+	channel := newTestChannel(t)
+
+	var responses []*PendingResponse
+
+	for i := 0; i < 100; i++ {
+		responses = append(responses, channel.RequestAsync("accept", nil))
+	}
+	require.NoError(t, WaitResponses(responses...))
+
+	stats := channel.Stats()
+	assert.EqualValues(t, 100, stats.WrittenMessages)
+	assert.Less(t, stats.Writes, stats.WrittenMessages)
+}
@@ -0,0 +1,91 @@
+package mediasoup
+
+import (
+	"net"
+)
+
+// Maximum number of messages written to the socket at once.
+const channelWriteBatchLen = 256
+
+// channelWrite is a message (or a message and its payload) to write, with the
+// channel receiving the error of the write.
+type channelWrite struct {
+	data  [][]byte
+	errCh chan error
+}
+
+// channelWriter writes the messages of the concurrent requests and
+// notifications of a Channel or a PayloadChannel from a single goroutine,
+// batching the ones queued meanwhile into a single (vectored) socket write.
+type channelWriter struct {
+	conn     net.Conn
+	queue    chan channelWrite
+	closeCh  <-chan struct{}
+	counters *channelCounters
+}
+
+func newChannelWriter(conn net.Conn, closeCh <-chan struct{}, counters *channelCounters) *channelWriter {
+	return &channelWriter{
+		conn:     conn,
+		queue:    make(chan channelWrite, channelWriteBatchLen),
+		closeCh:  closeCh,
+		counters: counters,
+	}
+}
+
+// write writes the netstrings of a message atomically, waiting for the write
+// of its batch.
+func (w *channelWriter) write(data ...[]byte) error {
+	write := channelWrite{data: data, errCh: make(chan error, 1)}
+
+	select {
+	case w.queue <- write:
+	case <-w.closeCh:
+		return NewInvalidStateError("Channel closed")
+	}
+
+	select {
+	case err := <-write.errCh:
+		return err
+	case <-w.closeCh:
+		return NewInvalidStateError("Channel closed")
+	}
+}
+
+// run writes the queued messages until the channel is closed.
+func (w *channelWriter) run() {
+	batch := make([]channelWrite, 0, channelWriteBatchLen)
+
+	for {
+		select {
+		case write := <-w.queue:
+			batch = append(batch[:0], write)
+		case <-w.closeCh:
+			return
+		}
+
+	collect:
+		for len(batch) < channelWriteBatchLen {
+			select {
+			case write := <-w.queue:
+				batch = append(batch, write)
+			default:
+				break collect
+			}
+		}
+
+		buffers := make(net.Buffers, 0, len(batch))
+
+		for _, write := range batch {
+			buffers = append(buffers, write.data...)
+		}
+
+		_, err := buffers.WriteTo(w.conn)
+
+		w.counters.wrote(len(batch))
+
+		for _, write := range batch {
+			write.errCh <- err
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package client implements the client side of the mediasoup negotiation, in
+// the same way mediasoup-client does, so that Go processes (recorder bots, AI
+// participants, etc.) can act as mediasoup endpoints against local or remote
+// mediasoup servers. It only generates and validates parameters; sending and
+// receiving media is left to the RTP stack of the application.
+package client
+
+import (
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/ortc"
+)
+
+type DeviceLoadOptions struct {
+	/**
+	 * The Router RTP capabilities, as given by router.RtpCapabilities().
+	 */
+	RouterRtpCapabilities mediasoup.RtpCapabilities
+
+	/**
+	 * RTP capabilities of the local RTP stack. If unset, the Router RTP
+	 * capabilities are used, meaning the application can handle every codec
+	 * and header extension enabled in the Router.
+	 */
+	LocalRtpCapabilities *mediasoup.RtpCapabilities
+
+	/**
+	 * SCTP capabilities of the local SCTP stack. Default 1024 OS and MIS.
+	 */
+	SctpCapabilities *mediasoup.SctpCapabilities
+}
+
+/**
+ * Device represents an endpoint that connects to a mediasoup Router to send
+ * and/or receive media.
+ */
+type Device struct {
+	logger mediasoup.Logger
+	locker sync.Mutex
+	// Loaded flag.
+	loaded bool
+	// Extended RTP capabilities.
+	extendedRtpCapabilities ortc.ExtendedRtpCapabilities
+	// Local RTP capabilities for receiving media.
+	recvRtpCapabilities mediasoup.RtpCapabilities
+	// Whether we can produce audio/video based on computed extended RTP
+	// capabilities.
+	canProduceByKind map[mediasoup.MediaKind]bool
+	// Local SCTP capabilities.
+	sctpCapabilities mediasoup.SctpCapabilities
+}
+
+func NewDevice() *Device {
+	logger := mediasoup.NewLogger("Device")
+	logger.Debug("constructor()")
+
+	return &Device{
+		logger: logger,
+		canProduceByKind: map[mediasoup.MediaKind]bool{
+			mediasoup.MediaKind_Audio: false,
+			mediasoup.MediaKind_Video: false,
+		},
+	}
+}
+
+/**
+ * Whether the Device is loaded.
+ */
+func (d *Device) Loaded() bool {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	return d.loaded
+}
+
+/**
+ * Initialize the Device with the RTP capabilities of the Router.
+ */
+func (d *Device) Load(options DeviceLoadOptions) (err error) {
+	d.logger.Debug("load()")
+
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	if d.loaded {
+		return mediasoup.NewInvalidStateError("already loaded")
+	}
+
+	routerRtpCapabilities := options.RouterRtpCapabilities
+
+	// This may throw.
+	if err = mediasoup.ValidateRtpCapabilities(&routerRtpCapabilities); err != nil {
+		return
+	}
+
+	localRtpCapabilities := routerRtpCapabilities
+
+	if options.LocalRtpCapabilities != nil {
+		localRtpCapabilities = *options.LocalRtpCapabilities
+
+		if err = mediasoup.ValidateRtpCapabilities(&localRtpCapabilities); err != nil {
+			return
+		}
+	}
+
+	sctpCapabilities := mediasoup.SctpCapabilities{
+		NumStreams: mediasoup.NumSctpStreams{OS: 1024, MIS: 1024},
+	}
+	if options.SctpCapabilities != nil {
+		sctpCapabilities = *options.SctpCapabilities
+	}
+
+	d.extendedRtpCapabilities = ortc.GetExtendedRtpCapabilities(localRtpCapabilities, routerRtpCapabilities)
+	d.recvRtpCapabilities = ortc.GetRecvRtpCapabilities(d.extendedRtpCapabilities)
+
+	// This may throw.
+	if err = mediasoup.ValidateRtpCapabilities(&d.recvRtpCapabilities); err != nil {
+		return
+	}
+
+	d.canProduceByKind[mediasoup.MediaKind_Audio] = ortc.CanSend(mediasoup.MediaKind_Audio, d.extendedRtpCapabilities)
+	d.canProduceByKind[mediasoup.MediaKind_Video] = ortc.CanSend(mediasoup.MediaKind_Video, d.extendedRtpCapabilities)
+	d.sctpCapabilities = sctpCapabilities
+	d.loaded = true
+
+	return
+}
+
+/**
+ * RTP capabilities of the Device for receiving media, to be given to the
+ * server when consuming.
+ */
+func (d *Device) RtpCapabilities() (caps mediasoup.RtpCapabilities, err error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	if !d.loaded {
+		err = mediasoup.NewInvalidStateError("not loaded")
+		return
+	}
+
+	return d.recvRtpCapabilities, nil
+}
+
+/**
+ * SCTP capabilities of the Device.
+ */
+func (d *Device) SctpCapabilities() (caps mediasoup.SctpCapabilities, err error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	if !d.loaded {
+		err = mediasoup.NewInvalidStateError("not loaded")
+		return
+	}
+
+	return d.sctpCapabilities, nil
+}
+
+/**
+ * Whether we can produce audio/video.
+ */
+func (d *Device) CanProduce(kind mediasoup.MediaKind) (ok bool, err error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	if !d.loaded {
+		err = mediasoup.NewInvalidStateError("not loaded")
+		return
+	}
+	if kind != mediasoup.MediaKind_Audio && kind != mediasoup.MediaKind_Video {
+		err = mediasoup.NewTypeError(`invalid kind "%s"`, kind)
+		return
+	}
+
+	return d.canProduceByKind[kind], nil
+}
+
+/**
+ * Create a Transport for sending media.
+ */
+func (d *Device) CreateSendTransport(options TransportOptions) (*SendTransport, error) {
+	d.logger.Debug("createSendTransport()")
+
+	transport, err := d.createTransport(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendTransport{Transport: transport}, nil
+}
+
+/**
+ * Create a Transport for receiving media.
+ */
+func (d *Device) CreateRecvTransport(options TransportOptions) (*RecvTransport, error) {
+	d.logger.Debug("createRecvTransport()")
+
+	transport, err := d.createTransport(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecvTransport{Transport: transport}, nil
+}
+
+func (d *Device) createTransport(options TransportOptions) (transport *Transport, err error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	if !d.loaded {
+		err = mediasoup.NewInvalidStateError("not loaded")
+		return
+	}
+	if len(options.Id) == 0 {
+		err = mediasoup.NewTypeError("missing id")
+		return
+	}
+	if len(options.DtlsParameters.Fingerprints) == 0 {
+		err = mediasoup.NewTypeError("missing dtlsParameters")
+		return
+	}
+
+	transport = &Transport{
+		options:                 options,
+		extendedRtpCapabilities: d.extendedRtpCapabilities,
+		canProduceByKind:        map[mediasoup.MediaKind]bool{},
+	}
+
+	for kind, ok := range d.canProduceByKind {
+		transport.canProduceByKind[kind] = ok
+	}
+
+	return
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routerRtpCapabilities = mediasoup.RtpCapabilities{
+	Codecs: []*mediasoup.RtpCodecCapability{
+		{
+			Kind:                 "audio",
+			MimeType:             "audio/opus",
+			PreferredPayloadType: 100,
+			ClockRate:            48000,
+			Channels:             2,
+			RtcpFeedback:         []mediasoup.RtcpFeedback{{Type: "transport-cc"}},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/VP8",
+			PreferredPayloadType: 101,
+			ClockRate:            90000,
+			RtcpFeedback: []mediasoup.RtcpFeedback{
+				{Type: "nack"},
+				{Type: "nack", Parameter: "pli"},
+				{Type: "transport-cc"},
+			},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/rtx",
+			PreferredPayloadType: 102,
+			ClockRate:            90000,
+			Parameters:           mediasoup.RtpCodecSpecificParameters{{Key: "apt", Value: 101}},
+		},
+	},
+	HeaderExtensions: []*mediasoup.RtpHeaderExtension{
+		{
+			Kind:        "video",
+			Uri:         "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01",
+			PreferredId: 5,
+		},
+	},
+}
+
+var transportOptions = TransportOptions{
+	Id: "transport-id",
+	DtlsParameters: mediasoup.DtlsParameters{
+		Role: mediasoup.DtlsRole_Auto,
+		Fingerprints: []mediasoup.DtlsFingerprint{
+			{Algorithm: "sha-256", Value: "82:5A:68:3D:36:C3:0A:DE:AF:E7:32:43:D2:88:83:57:AC:2D:65:E5:80:C4:B6:FB:AF:1A:A0:21:9F:6D:0C:AD"},
+		},
+	},
+}
+
+func TestDevice_Load(t *testing.T) {
+	device := NewDevice()
+
+	assert.False(t, device.Loaded())
+	_, err := device.RtpCapabilities()
+	assert.Error(t, err)
+
+	require.NoError(t, device.Load(DeviceLoadOptions{RouterRtpCapabilities: routerRtpCapabilities}))
+	assert.True(t, device.Loaded())
+	assert.Error(t, device.Load(DeviceLoadOptions{RouterRtpCapabilities: routerRtpCapabilities}))
+
+	caps, err := device.RtpCapabilities()
+	require.NoError(t, err)
+	assert.Len(t, caps.Codecs, 3)
+	assert.Len(t, caps.HeaderExtensions, 1)
+
+	ok, err := device.CanProduce(mediasoup.MediaKind_Audio)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = device.CanProduce("foo")
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	sctpCaps, err := device.SctpCapabilities()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1024, sctpCaps.NumStreams.OS)
+}
+
+func TestDevice_LocalRtpCapabilities(t *testing.T) {
+	device := NewDevice()
+
+	err := device.Load(DeviceLoadOptions{
+		RouterRtpCapabilities: routerRtpCapabilities,
+		LocalRtpCapabilities: &mediasoup.RtpCapabilities{
+			Codecs: routerRtpCapabilities.Codecs[:1],
+		},
+	})
+	require.NoError(t, err)
+
+	ok, err := device.CanProduce(mediasoup.MediaKind_Video)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	transport, err := device.CreateSendTransport(transportOptions)
+	require.NoError(t, err)
+
+	_, err = transport.Produce(ProduceOptions{Kind: mediasoup.MediaKind_Video})
+	assert.IsType(t, mediasoup.UnsupportedError{}, err)
+}
+
+func TestSendTransport_Produce(t *testing.T) {
+	device := NewDevice()
+	require.NoError(t, device.Load(DeviceLoadOptions{RouterRtpCapabilities: routerRtpCapabilities}))
+
+	_, err := device.CreateSendTransport(TransportOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	transport, err := device.CreateSendTransport(transportOptions)
+	require.NoError(t, err)
+	assert.Equal(t, "transport-id", transport.Id())
+
+	params, err := transport.Produce(ProduceOptions{Kind: mediasoup.MediaKind_Video})
+	require.NoError(t, err)
+
+	assert.Equal(t, "0", params.Mid)
+	require.Len(t, params.Codecs, 2)
+	assert.Equal(t, "video/VP8", params.Codecs[0].MimeType)
+	assert.Equal(t, "video/rtx", params.Codecs[1].MimeType)
+	require.Len(t, params.Encodings, 1)
+	assert.NotZero(t, params.Encodings[0].Ssrc)
+	require.NotNil(t, params.Encodings[0].Rtx)
+	assert.NotEmpty(t, params.Rtcp.Cname)
+	require.Len(t, params.HeaderExtensions, 1)
+	assert.Equal(t, 5, params.HeaderExtensions[0].Id)
+	assert.NoError(t, mediasoup.ValidateRtpParameters(&params))
+
+	audioParams, err := transport.Produce(ProduceOptions{
+		Kind:      mediasoup.MediaKind_Audio,
+		Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 1234}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", audioParams.Mid)
+	assert.Equal(t, params.Rtcp.Cname, audioParams.Rtcp.Cname)
+	assert.Equal(t, []mediasoup.RtpEncodingParameters{{Ssrc: 1234}}, audioParams.Encodings)
+}
+
+func TestRecvTransport_Consume(t *testing.T) {
+	device := NewDevice()
+	require.NoError(t, device.Load(DeviceLoadOptions{RouterRtpCapabilities: routerRtpCapabilities}))
+
+	transport, err := device.CreateRecvTransport(transportOptions)
+	require.NoError(t, err)
+
+	_, err = transport.Consume(mediasoup.RtpParameters{
+		Codecs: []*mediasoup.RtpCodecParameters{
+			{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 1111}},
+	})
+	assert.NoError(t, err)
+
+	_, err = transport.Consume(mediasoup.RtpParameters{
+		Codecs: []*mediasoup.RtpCodecParameters{
+			{MimeType: "audio/PCMU", PayloadType: 0, ClockRate: 8000},
+		},
+	})
+	assert.IsType(t, mediasoup.UnsupportedError{}, err)
+}
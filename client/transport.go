@@ -0,0 +1,184 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/ortc"
+)
+
+/**
+ * TransportOptions are the parameters of the server side WebRtcTransport, as
+ * returned by router.CreateWebRtcTransport() and sent to the client.
+ */
+type TransportOptions struct {
+	Id             string                    `json:"id"`
+	IceParameters  mediasoup.IceParameters   `json:"iceParameters"`
+	IceCandidates  []mediasoup.IceCandidate  `json:"iceCandidates"`
+	DtlsParameters mediasoup.DtlsParameters  `json:"dtlsParameters"`
+	SctpParameters *mediasoup.SctpParameters `json:"sctpParameters,omitempty"`
+	AppData        interface{}               `json:"appData,omitempty"`
+}
+
+type ProduceOptions struct {
+	/**
+	 * Media kind ("audio" or "video").
+	 */
+	Kind mediasoup.MediaKind
+
+	/**
+	 * Encodings to send. If unset, a single encoding is used. SSRCs are
+	 * generated for encodings without ssrc and rid.
+	 */
+	Encodings []mediasoup.RtpEncodingParameters
+
+	/**
+	 * The codec to use, taken from device.RtpCapabilities(). If unset, the first
+	 * codec of the given kind is used.
+	 */
+	Codec *mediasoup.RtpCodecCapability
+
+	/**
+	 * RTCP CNAME. If unset, the CNAME of the Transport is used.
+	 */
+	Cname string
+}
+
+/**
+ * Transport holds the parameters of a server side WebRtcTransport together
+ * with the negotiated RTP capabilities.
+ */
+type Transport struct {
+	options                 TransportOptions
+	extendedRtpCapabilities ortc.ExtendedRtpCapabilities
+	canProduceByKind        map[mediasoup.MediaKind]bool
+	locker                  sync.Mutex
+	// Next MID for media sections.
+	nextMid int
+	// RTCP CNAME used by Producers.
+	cname string
+}
+
+// Transport id
+func (t *Transport) Id() string {
+	return t.options.Id
+}
+
+// ICE parameters of the server side transport.
+func (t *Transport) IceParameters() mediasoup.IceParameters {
+	return t.options.IceParameters
+}
+
+// ICE candidates of the server side transport.
+func (t *Transport) IceCandidates() []mediasoup.IceCandidate {
+	return t.options.IceCandidates
+}
+
+// DTLS parameters of the server side transport.
+func (t *Transport) DtlsParameters() mediasoup.DtlsParameters {
+	return t.options.DtlsParameters
+}
+
+// SCTP parameters of the server side transport.
+func (t *Transport) SctpParameters() *mediasoup.SctpParameters {
+	return t.options.SctpParameters
+}
+
+// App custom data.
+func (t *Transport) AppData() interface{} {
+	return t.options.AppData
+}
+
+/**
+ * SendTransport generates the RTP parameters of the media sent to mediasoup.
+ */
+type SendTransport struct {
+	*Transport
+}
+
+/**
+ * Generate the RTP parameters of a new Producer, to be given to the server
+ * side transport.Produce(). The returned parameters are also the ones the
+ * local RTP stack must use to send the media.
+ */
+func (t *SendTransport) Produce(options ProduceOptions) (rtpParameters mediasoup.RtpParameters, err error) {
+	if !t.canProduceByKind[options.Kind] {
+		err = mediasoup.NewUnsupportedError("cannot produce %s", options.Kind)
+		return
+	}
+
+	rtpParameters = ortc.GetSendingRtpParameters(options.Kind, t.extendedRtpCapabilities)
+
+	if rtpParameters.Codecs, err = ortc.ReduceCodecs(rtpParameters.Codecs, options.Codec); err != nil {
+		return
+	}
+
+	encodings := options.Encodings
+
+	if len(encodings) == 0 {
+		encodings = []mediasoup.RtpEncodingParameters{{}}
+	}
+
+	hasRtx := len(rtpParameters.Codecs) > 1
+
+	for _, encoding := range encodings {
+		if encoding.Ssrc == 0 && len(encoding.Rid) == 0 {
+			encoding.Ssrc = rand.Uint32()
+
+			if hasRtx {
+				encoding.Rtx = &mediasoup.RtpEncodingRtx{Ssrc: rand.Uint32()}
+			}
+		}
+		rtpParameters.Encodings = append(rtpParameters.Encodings, encoding)
+	}
+
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	if len(t.cname) == 0 {
+		t.cname = fmt.Sprintf("%08x", rand.Uint32())
+	}
+
+	rtpParameters.Mid = fmt.Sprintf("%d", t.nextMid)
+	t.nextMid++
+
+	rtpParameters.Rtcp.Cname = options.Cname
+
+	if len(rtpParameters.Rtcp.Cname) == 0 {
+		rtpParameters.Rtcp.Cname = t.cname
+	}
+	rtpParameters.Rtcp.ReducedSize = mediasoup.Bool(true)
+	rtpParameters.Rtcp.Mux = mediasoup.Bool(true)
+
+	return
+}
+
+/**
+ * RecvTransport checks the media received from mediasoup.
+ */
+type RecvTransport struct {
+	*Transport
+}
+
+/**
+ * Check the RTP parameters of a Consumer created in the server side and
+ * return them, so the local RTP stack can receive its media.
+ */
+func (t *RecvTransport) Consume(rtpParameters mediasoup.RtpParameters) (mediasoup.RtpParameters, error) {
+	// This may throw.
+	if err := mediasoup.ValidateRtpParameters(&rtpParameters); err != nil {
+		return rtpParameters, err
+	}
+
+	ok, err := ortc.CanReceive(rtpParameters, t.extendedRtpCapabilities)
+	if err != nil {
+		return rtpParameters, err
+	}
+	if !ok {
+		return rtpParameters, mediasoup.NewUnsupportedError("cannot consume this Producer")
+	}
+
+	return rtpParameters, nil
+}
@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * ErrProducerNotFound is returned by Backend.Lookup() when no node published
+ * the Producer.
+ */
+var ErrProducerNotFound = errors.New("cluster: producer not found")
+
+/**
+ * ErrNodeNotFound is returned by Backend.Request() when the node is unknown
+ * or does not answer.
+ */
+var ErrNodeNotFound = errors.New("cluster: node not found")
+
+/**
+ * Location of a Producer published by a node.
+ */
+type ProducerRecord struct {
+	ProducerId string              `json:"producerId"`
+	NodeId     string              `json:"nodeId"`
+	RouterId   string              `json:"routerId"`
+	Kind       mediasoup.MediaKind `json:"kind"`
+}
+
+/**
+ * Backend is the coordination service shared by the nodes (e.g. Redis, NATS):
+ * a registry of the published Producers, and a request/response transport
+ * between the nodes. It must be safe for concurrent use.
+ */
+type Backend interface {
+	/**
+	 * Publish the location of a Producer.
+	 */
+	Register(ctx context.Context, record ProducerRecord) error
+
+	/**
+	 * Remove the location of a Producer.
+	 */
+	Unregister(ctx context.Context, producerId string) error
+
+	/**
+	 * Get the location of a Producer, ErrProducerNotFound if none.
+	 */
+	Lookup(ctx context.Context, producerId string) (ProducerRecord, error)
+
+	/**
+	 * Send a request to the given node, returning its response.
+	 */
+	Request(ctx context.Context, nodeId string, request []byte) ([]byte, error)
+
+	/**
+	 * Handle the requests sent to the given node until the returned Closer is
+	 * closed. The handler may be called concurrently.
+	 */
+	Serve(nodeId string, handler func(request []byte) []byte) (io.Closer, error)
+}
+
+/**
+ * MemoryBackend is a Backend for the nodes of a single process, e.g. tests or
+ * Workers running different mediasoup-worker builds.
+ */
+type MemoryBackend struct {
+	locker   sync.RWMutex
+	records  map[string]ProducerRecord
+	handlers map[string]func([]byte) []byte
+}
+
+/**
+ * Create an empty MemoryBackend.
+ */
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		records:  make(map[string]ProducerRecord),
+		handlers: make(map[string]func([]byte) []byte),
+	}
+}
+
+func (b *MemoryBackend) Register(ctx context.Context, record ProducerRecord) error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	b.records[record.ProducerId] = record
+
+	return nil
+}
+
+func (b *MemoryBackend) Unregister(ctx context.Context, producerId string) error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	delete(b.records, producerId)
+
+	return nil
+}
+
+func (b *MemoryBackend) Lookup(ctx context.Context, producerId string) (ProducerRecord, error) {
+	b.locker.RLock()
+	defer b.locker.RUnlock()
+
+	record, ok := b.records[producerId]
+	if !ok {
+		return record, ErrProducerNotFound
+	}
+
+	return record, nil
+}
+
+func (b *MemoryBackend) Request(ctx context.Context, nodeId string, request []byte) ([]byte, error) {
+	b.locker.RLock()
+	handler := b.handlers[nodeId]
+	b.locker.RUnlock()
+
+	if handler == nil {
+		return nil, ErrNodeNotFound
+	}
+
+	responseCh := make(chan []byte, 1)
+
+	go func() {
+		responseCh <- handler(request)
+	}()
+
+	select {
+	case response := <-responseCh:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *MemoryBackend) Serve(nodeId string, handler func(request []byte) []byte) (io.Closer, error) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	if _, ok := b.handlers[nodeId]; ok {
+		return nil, mediasoup.NewTypeError("node %q already served", nodeId)
+	}
+	b.handlers[nodeId] = handler
+
+	return closerFunc(func() error {
+		b.locker.Lock()
+		defer b.locker.Unlock()
+
+		delete(b.handlers, nodeId)
+
+		return nil
+	}), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend_Registry(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+
+	_, err := backend.Lookup(ctx, "p1")
+	assert.Equal(t, ErrProducerNotFound, err)
+
+	record := ProducerRecord{ProducerId: "p1", NodeId: "n1", RouterId: "r1", Kind: mediasoup.MediaKind_Audio}
+	require.NoError(t, backend.Register(ctx, record))
+
+	found, err := backend.Lookup(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, record, found)
+
+	require.NoError(t, backend.Unregister(ctx, "p1"))
+
+	_, err = backend.Lookup(ctx, "p1")
+	assert.Equal(t, ErrProducerNotFound, err)
+}
+
+func TestMemoryBackend_Request(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+
+	_, err := backend.Request(ctx, "n1", []byte("ping"))
+	assert.Equal(t, ErrNodeNotFound, err)
+
+	closer, err := backend.Serve("n1", func(request []byte) []byte {
+		if string(request) == "slow" {
+			time.Sleep(time.Second)
+		}
+		return append([]byte("re:"), request...)
+	})
+	require.NoError(t, err)
+
+	_, err = backend.Serve("n1", func(request []byte) []byte { return nil })
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	response, err := backend.Request(ctx, "n1", []byte("ping"))
+	require.NoError(t, err)
+	assert.Equal(t, "re:ping", string(response))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = backend.Request(timeoutCtx, "n1", []byte("slow"))
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	require.NoError(t, closer.Close())
+
+	_, err = backend.Request(ctx, "n1", []byte("ping"))
+	assert.Equal(t, ErrNodeNotFound, err)
+}
+
+func TestNewNode(t *testing.T) {
+	_, err := NewNode(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	backend := NewMemoryBackend()
+
+	node, err := NewNode(Options{Backend: backend, NodeId: "n1"})
+	require.NoError(t, err)
+	assert.Equal(t, "n1", node.Id())
+
+	_, err = NewNode(Options{Backend: backend, NodeId: "n1"})
+	assert.Error(t, err)
+
+	require.NoError(t, node.Close())
+	assert.True(t, node.Closed())
+	assert.Equal(t, mediasoup.ErrAlreadyClosed, node.Close())
+}
+
+func TestNode_HandleRequest(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	node, err := NewNode(Options{Backend: backend, NodeId: "n1"})
+	require.NoError(t, err)
+	defer node.Close()
+
+	other, err := NewNode(Options{Backend: backend, NodeId: "n2"})
+	require.NoError(t, err)
+	defer other.Close()
+
+	ctx := context.Background()
+
+	err = other.request(ctx, "n1", methodPipe, "pipe1", pipeRequest{ProducerId: "p1", NodeId: "n2"}, &pipeResponse{})
+	assert.EqualError(t, err, ErrProducerNotFound.Error())
+
+	err = other.request(ctx, "n1", "foo", "pipe1", nil, nil)
+	assert.Error(t, err)
+
+	// Notifications of unknown pipes are ignored.
+	assert.NoError(t, other.request(ctx, "n1", methodClose, "pipe1", nil, nil))
+
+	var resp response
+	require.NoError(t, json.Unmarshal(node.handleRequest([]byte("{")), &resp))
+	assert.NotEmpty(t, resp.Error)
+}
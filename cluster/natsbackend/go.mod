@@ -0,0 +1,13 @@
+module github.com/jiyeyuran/mediasoup-go/cluster/natsbackend
+
+go 1.15
+
+require (
+	github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+	github.com/nats-io/nats.go v1.11.0
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871 // indirect
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../../
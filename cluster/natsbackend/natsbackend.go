@@ -0,0 +1,158 @@
+// Package natsbackend implements cluster.Backend with NATS: every node
+// answers the lookups of the Producers it published, and the requests between
+// the nodes use NATS request/reply. It needs a NATS server supporting the "no
+// responders" status (2.2+).
+//
+//	conn, err := nats.Connect("nats://nats:4222")
+//	backend := natsbackend.New(natsbackend.Options{Conn: conn})
+//	node, err := cluster.NewNode(cluster.Options{Backend: backend, ...})
+//
+// The Producer and node ids are used in subjects, so they must not contain
+// dots, spaces or wildcards. It is a separate module so that the mediasoup-go
+// module does not depend on the NATS client.
+package natsbackend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go/cluster"
+	"github.com/nats-io/nats.go"
+)
+
+type Options struct {
+	/**
+	 * NATS connection.
+	 */
+	Conn *nats.Conn
+
+	/**
+	 * Prefix of the subjects. Default "mediasoup".
+	 */
+	Prefix string
+}
+
+/**
+ * Backend is a cluster.Backend using NATS.
+ */
+type Backend struct {
+	conn   *nats.Conn
+	prefix string
+	locker sync.Mutex
+	// Subscriptions answering the lookups, by Producer id.
+	producers map[string]*nats.Subscription
+}
+
+/**
+ * Create a Backend with the given connection.
+ */
+func New(options Options) *Backend {
+	if len(options.Prefix) == 0 {
+		options.Prefix = "mediasoup"
+	}
+
+	return &Backend{
+		conn:      options.Conn,
+		prefix:    options.Prefix,
+		producers: make(map[string]*nats.Subscription),
+	}
+}
+
+func (b *Backend) subject(kind, id string) string {
+	return b.prefix + "." + kind + "." + id
+}
+
+func (b *Backend) Register(ctx context.Context, record cluster.ProducerRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sub, err := b.conn.Subscribe(b.subject("producer", record.ProducerId), func(msg *nats.Msg) {
+		msg.Respond(data)
+	})
+	if err != nil {
+		return err
+	}
+
+	b.locker.Lock()
+	previous := b.producers[record.ProducerId]
+	b.producers[record.ProducerId] = sub
+	b.locker.Unlock()
+
+	if previous != nil {
+		previous.Unsubscribe()
+	}
+
+	// Make sure the server knows the subscription once registered.
+	return b.conn.FlushWithContext(ctx)
+}
+
+func (b *Backend) Unregister(ctx context.Context, producerId string) error {
+	b.locker.Lock()
+	sub := b.producers[producerId]
+	delete(b.producers, producerId)
+	b.locker.Unlock()
+
+	if sub == nil {
+		return nil
+	}
+
+	return sub.Unsubscribe()
+}
+
+func (b *Backend) Lookup(ctx context.Context, producerId string) (record cluster.ProducerRecord, err error) {
+	msg, err := b.conn.RequestWithContext(ctx, b.subject("producer", producerId), nil)
+	if errors.Is(err, nats.ErrNoResponders) {
+		err = cluster.ErrProducerNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(msg.Data, &record)
+
+	return
+}
+
+func (b *Backend) Request(ctx context.Context, nodeId string, request []byte) ([]byte, error) {
+	msg, err := b.conn.RequestWithContext(ctx, b.subject("node", nodeId), request)
+	if errors.Is(err, nats.ErrNoResponders) {
+		return nil, cluster.ErrNodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return msg.Data, nil
+}
+
+func (b *Backend) Serve(nodeId string, handler func(request []byte) []byte) (io.Closer, error) {
+	sub, err := b.conn.Subscribe(b.subject("node", nodeId), func(msg *nats.Msg) {
+		// The handler may create transports: do not block the other requests.
+		go func() {
+			msg.Respond(handler(msg.Data))
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.conn.Flush(); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	return subscriptionCloser{sub}, nil
+}
+
+type subscriptionCloser struct {
+	sub *nats.Subscription
+}
+
+func (c subscriptionCloser) Close() error {
+	return c.sub.Unsubscribe()
+}
@@ -0,0 +1,626 @@
+// Package cluster pipes Producers between mediasoup hosts. Every host runs a
+// Node publishing its Producers in a shared Backend (see the redisbackend and
+// natsbackend modules); a Node asked for a Producer living on another host
+// sets up the PipeTransport pair with it:
+//
+//	node, err := cluster.NewNode(cluster.Options{
+//		Backend:  backend,
+//		ListenIp: mediasoup.TransportListenIp{Ip: "0.0.0.0", AnnouncedIp: privateIp},
+//	})
+//	// Host A:
+//	err = node.Publish(ctx, router, producer)
+//	// Host B, before consuming the Producer in its router:
+//	_, err = node.Pipe(ctx, router, producerId)
+//	consumer, err := transport.Consume(mediasoup.ConsumerOptions{ProducerId: producerId, ...})
+//
+// The pipe Producer has the id of the original one, is paused and resumed
+// with it, and is closed when it is closed or when either node closes.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Id of the node, unique in the cluster. Default a random one.
+	 */
+	NodeId string
+
+	/**
+	 * Backend shared by the nodes.
+	 */
+	Backend Backend
+
+	/**
+	 * IP of the PipeTransports, with AnnouncedIp being reachable by the other
+	 * nodes. Default "127.0.0.1".
+	 */
+	ListenIp mediasoup.TransportListenIp
+
+	/**
+	 * Enable RTX and NACK on the PipeTransports.
+	 */
+	EnableRtx bool
+
+	/**
+	 * Enable SRTP on the PipeTransports.
+	 */
+	EnableSrtp bool
+
+	/**
+	 * Timeout of the notifications sent to the other nodes (close, pause,
+	 * resume). Default 5 seconds.
+	 */
+	RequestTimeout time.Duration
+
+	/**
+	 * Logger of the node. Default mediasoup.NewLogger("ClusterNode").
+	 */
+	Logger mediasoup.Logger
+}
+
+// Methods of the requests between nodes.
+const (
+	methodPipe   = "pipe"
+	methodClose  = "close"
+	methodPause  = "pause"
+	methodResume = "resume"
+)
+
+type request struct {
+	Method string          `json:"method"`
+	PipeId string          `json:"pipeId"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+type response struct {
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+type pipeRequest struct {
+	ProducerId     string                    `json:"producerId"`
+	NodeId         string                    `json:"nodeId"`
+	Ip             string                    `json:"ip"`
+	Port           uint16                    `json:"port"`
+	SrtpParameters *mediasoup.SrtpParameters `json:"srtpParameters,omitempty"`
+}
+
+type pipeResponse struct {
+	Ip             string                    `json:"ip"`
+	Port           uint16                    `json:"port"`
+	SrtpParameters *mediasoup.SrtpParameters `json:"srtpParameters,omitempty"`
+	Kind           mediasoup.MediaKind       `json:"kind"`
+	RtpParameters  mediasoup.RtpParameters   `json:"rtpParameters"`
+	Paused         bool                      `json:"paused"`
+}
+
+// pipe is one side of a PipeTransport pair between two nodes, identified by
+// the id of the transport of the consuming node.
+type pipe struct {
+	id        string
+	nodeId    string
+	transport *mediasoup.PipeTransport
+	// Pipe Producer in the consuming node.
+	producer *mediasoup.Producer
+	// Set when the remote node closed the pipe.
+	remoteClosed bool
+}
+
+type published struct {
+	router   *mediasoup.Router
+	producer *mediasoup.Producer
+}
+
+/**
+ * Node is a mediasoup host of the cluster.
+ */
+type Node struct {
+	logger    mediasoup.Logger
+	options   Options
+	server    io.Closer
+	locker    sync.Mutex
+	published map[string]published
+	pipes     map[string]*pipe
+	// Pipe Producers by Router id and Producer id.
+	pipeProducers map[[2]string]*mediasoup.Producer
+	// Serializes Pipe(), to create a single pipe for a Router and a Producer.
+	pipeLocker sync.Mutex
+	closed     bool
+}
+
+/**
+ * Create a node and start handling the requests of the other nodes.
+ */
+func NewNode(options Options) (node *Node, err error) {
+	if options.Backend == nil {
+		return nil, mediasoup.NewTypeError("missing Backend")
+	}
+	if len(options.NodeId) == 0 {
+		options.NodeId = mediasoup.DefaultIdGenerator("node")
+	}
+	if len(options.ListenIp.Ip) == 0 {
+		options.ListenIp.Ip = "127.0.0.1"
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = 5 * time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("ClusterNode")
+	}
+
+	node = &Node{
+		logger:        options.Logger,
+		options:       options,
+		published:     make(map[string]published),
+		pipes:         make(map[string]*pipe),
+		pipeProducers: make(map[[2]string]*mediasoup.Producer),
+	}
+
+	if node.server, err = options.Backend.Serve(options.NodeId, node.handleRequest); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+/**
+ * Id of the node.
+ */
+func (n *Node) Id() string {
+	return n.options.NodeId
+}
+
+/**
+ * Make the Producer available to the other nodes, until it is closed.
+ */
+func (n *Node) Publish(ctx context.Context, router *mediasoup.Router, producer *mediasoup.Producer) (err error) {
+	n.logger.Debug("Publish() [producerId:%s]", producer.Id())
+
+	n.locker.Lock()
+	if n.closed {
+		n.locker.Unlock()
+		return mediasoup.NewInvalidStateError("Node closed")
+	}
+	n.published[producer.Id()] = published{router: router, producer: producer}
+	n.locker.Unlock()
+
+	producer.Observer().On("close", func() {
+		n.unpublish(producer.Id())
+	})
+
+	err = n.options.Backend.Register(ctx, ProducerRecord{
+		ProducerId: producer.Id(),
+		NodeId:     n.Id(),
+		RouterId:   router.Id(),
+		Kind:       producer.Kind(),
+	})
+	if err != nil {
+		n.locker.Lock()
+		delete(n.published, producer.Id())
+		n.locker.Unlock()
+	}
+
+	return
+}
+
+func (n *Node) unpublish(producerId string) {
+	n.locker.Lock()
+	_, ok := n.published[producerId]
+	delete(n.published, producerId)
+	n.locker.Unlock()
+
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.options.RequestTimeout)
+	defer cancel()
+
+	if err := n.options.Backend.Unregister(ctx, producerId); err != nil {
+		n.logger.Warn("unpublish() | unregistering failed [producerId:%s]: %v", producerId, err)
+	}
+}
+
+/**
+ * Make the Producer with the given id consumable in the given Router, piping
+ * it from the node which published it if needed. It returns the Producer in
+ * the Router.
+ */
+func (n *Node) Pipe(ctx context.Context, router *mediasoup.Router, producerId string) (producer *mediasoup.Producer, err error) {
+	n.logger.Debug("Pipe() [routerId:%s, producerId:%s]", router.Id(), producerId)
+
+	n.pipeLocker.Lock()
+	defer n.pipeLocker.Unlock()
+
+	n.locker.Lock()
+
+	if n.closed {
+		n.locker.Unlock()
+		return nil, mediasoup.NewInvalidStateError("Node closed")
+	}
+
+	local, isLocal := n.published[producerId]
+	key := [2]string{router.Id(), producerId}
+	pipeProducer := n.pipeProducers[key]
+
+	n.locker.Unlock()
+
+	if isLocal {
+		if local.router == router {
+			return local.producer, nil
+		}
+		for _, p := range router.Producers() {
+			if p.Id() == producerId {
+				return p, nil
+			}
+		}
+		result, err := local.router.PipeToRouter(mediasoup.PipeToRouterOptions{
+			ProducerId: producerId,
+			Router:     router,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.PipeProducer, nil
+	}
+
+	if pipeProducer != nil {
+		return pipeProducer, nil
+	}
+
+	record, err := n.options.Backend.Lookup(ctx, producerId)
+	if err != nil {
+		return
+	}
+	if record.NodeId == n.Id() {
+		return nil, ErrProducerNotFound
+	}
+
+	return n.pipeFrom(ctx, router, record)
+}
+
+// pipeFrom creates the PipeTransport pair with the node of the record.
+func (n *Node) pipeFrom(ctx context.Context, router *mediasoup.Router, record ProducerRecord) (producer *mediasoup.Producer, err error) {
+	transport, err := router.CreatePipeTransport(mediasoup.PipeTransportOptions{
+		ListenIp:   n.options.ListenIp,
+		EnableRtx:  n.options.EnableRtx,
+		EnableSrtp: n.options.EnableSrtp,
+	})
+	if err != nil {
+		return
+	}
+
+	p := &pipe{
+		id:        transport.Id(),
+		nodeId:    record.NodeId,
+		transport: transport,
+	}
+
+	defer func() {
+		if err != nil {
+			transport.Close()
+		}
+	}()
+
+	var resp pipeResponse
+
+	err = n.request(ctx, record.NodeId, methodPipe, p.id, pipeRequest{
+		ProducerId:     record.ProducerId,
+		NodeId:         n.Id(),
+		Ip:             transport.Tuple().LocalIp,
+		Port:           transport.Tuple().LocalPort,
+		SrtpParameters: transport.SrtpParameters(),
+	}, &resp)
+	if err != nil {
+		return
+	}
+
+	// From now on, the remote node closes its side with ours.
+	n.addPipe(p)
+
+	err = transport.Connect(mediasoup.TransportConnectOptions{
+		Ip:             resp.Ip,
+		Port:           resp.Port,
+		SrtpParameters: resp.SrtpParameters,
+	})
+	if err != nil {
+		return
+	}
+
+	producer, err = transport.Produce(mediasoup.ProducerOptions{
+		Id:            record.ProducerId,
+		Kind:          resp.Kind,
+		RtpParameters: resp.RtpParameters,
+		Paused:        resp.Paused,
+	})
+	if err != nil {
+		return
+	}
+
+	key := [2]string{router.Id(), record.ProducerId}
+
+	n.locker.Lock()
+	p.producer = producer
+	n.pipeProducers[key] = producer
+	n.locker.Unlock()
+
+	producer.Observer().On("close", func() {
+		n.locker.Lock()
+		delete(n.pipeProducers, key)
+		n.locker.Unlock()
+
+		transport.Close()
+	})
+
+	return
+}
+
+// addPipe registers the pipe, which is removed and closed on the remote node
+// when its transport is closed.
+func (n *Node) addPipe(p *pipe) {
+	n.locker.Lock()
+	n.pipes[p.id] = p
+	n.locker.Unlock()
+
+	p.transport.Observer().On("close", func() {
+		n.locker.Lock()
+		delete(n.pipes, p.id)
+		remoteClosed := p.remoteClosed
+		n.locker.Unlock()
+
+		if !remoteClosed {
+			go n.notify(p.nodeId, methodClose, p.id)
+		}
+	})
+}
+
+// request sends a request to the node and decodes the response data into
+// result, if not nil.
+func (n *Node) request(ctx context.Context, nodeId, method, pipeId string, data interface{}, result interface{}) (err error) {
+	req := request{Method: method, PipeId: pipeId}
+
+	if data != nil {
+		if req.Data, err = json.Marshal(data); err != nil {
+			return
+		}
+	}
+
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+
+	respData, err := n.options.Backend.Request(ctx, nodeId, reqData)
+	if err != nil {
+		return
+	}
+
+	var resp response
+
+	if err = json.Unmarshal(respData, &resp); err != nil {
+		return
+	}
+	if len(resp.Error) > 0 {
+		return errors.New(resp.Error)
+	}
+	if result != nil {
+		err = json.Unmarshal(resp.Data, result)
+	}
+
+	return
+}
+
+// notify sends a notification of the pipe to the node, logging failures.
+func (n *Node) notify(nodeId, method, pipeId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.options.RequestTimeout)
+	defer cancel()
+
+	if err := n.request(ctx, nodeId, method, pipeId, nil, nil); err != nil {
+		n.logger.Warn("notify() | %s failed [nodeId:%s, pipeId:%s]: %v", method, nodeId, pipeId, err)
+	}
+}
+
+// handleRequest handles a request of another node.
+func (n *Node) handleRequest(data []byte) []byte {
+	var (
+		req    request
+		result interface{}
+		err    error
+	)
+
+	if err = json.Unmarshal(data, &req); err == nil {
+		n.logger.Debug("handleRequest() [method:%s, pipeId:%s]", req.Method, req.PipeId)
+
+		switch req.Method {
+		case methodPipe:
+			result, err = n.handlePipe(req)
+
+		case methodClose, methodPause, methodResume:
+			err = n.handlePipeNotification(req)
+
+		default:
+			err = mediasoup.NewTypeError("unknown method %q", req.Method)
+		}
+	}
+
+	var resp response
+
+	if err == nil && result != nil {
+		resp.Data, err = json.Marshal(result)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	respData, _ := json.Marshal(resp)
+
+	return respData
+}
+
+// handlePipe creates the producing side of a PipeTransport pair.
+func (n *Node) handlePipe(req request) (resp *pipeResponse, err error) {
+	var pipeReq pipeRequest
+
+	if err = json.Unmarshal(req.Data, &pipeReq); err != nil {
+		return
+	}
+
+	n.locker.Lock()
+	local, ok := n.published[pipeReq.ProducerId]
+	closed := n.closed
+	n.locker.Unlock()
+
+	if closed {
+		return nil, mediasoup.NewInvalidStateError("Node closed")
+	}
+	if !ok {
+		return nil, ErrProducerNotFound
+	}
+
+	transport, err := local.router.CreatePipeTransport(mediasoup.PipeTransportOptions{
+		ListenIp:   n.options.ListenIp,
+		EnableRtx:  n.options.EnableRtx,
+		EnableSrtp: n.options.EnableSrtp,
+	})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			transport.Close()
+		}
+	}()
+
+	err = transport.Connect(mediasoup.TransportConnectOptions{
+		Ip:             pipeReq.Ip,
+		Port:           pipeReq.Port,
+		SrtpParameters: pipeReq.SrtpParameters,
+	})
+	if err != nil {
+		return
+	}
+
+	consumer, err := transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId: pipeReq.ProducerId,
+	})
+	if err != nil {
+		return
+	}
+
+	p := &pipe{
+		id:        req.PipeId,
+		nodeId:    pipeReq.NodeId,
+		transport: transport,
+	}
+	n.addPipe(p)
+
+	consumer.On("producerpause", func() {
+		go n.notify(p.nodeId, methodPause, p.id)
+	})
+	consumer.On("producerresume", func() {
+		go n.notify(p.nodeId, methodResume, p.id)
+	})
+	consumer.Observer().On("close", func() {
+		transport.Close()
+	})
+
+	return &pipeResponse{
+		Ip:             transport.Tuple().LocalIp,
+		Port:           transport.Tuple().LocalPort,
+		SrtpParameters: transport.SrtpParameters(),
+		Kind:           consumer.Kind(),
+		RtpParameters:  consumer.RtpParameters(),
+		Paused:         consumer.ProducerPaused(),
+	}, nil
+}
+
+// handlePipeNotification handles the close, pause and resume notifications.
+func (n *Node) handlePipeNotification(req request) (err error) {
+	n.locker.Lock()
+	p := n.pipes[req.PipeId]
+
+	if p != nil && req.Method == methodClose {
+		p.remoteClosed = true
+	}
+	n.locker.Unlock()
+
+	if p == nil {
+		return nil
+	}
+
+	switch req.Method {
+	case methodClose:
+		return p.transport.Close()
+
+	case methodPause:
+		if p.producer != nil {
+			return p.producer.Pause()
+		}
+
+	case methodResume:
+		if p.producer != nil {
+			return p.producer.Resume()
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Whether the node is closed.
+ */
+func (n *Node) Closed() bool {
+	n.locker.Lock()
+	defer n.locker.Unlock()
+
+	return n.closed
+}
+
+/**
+ * Stop handling requests, unregister the published Producers and close the
+ * pipes.
+ */
+func (n *Node) Close() error {
+	n.locker.Lock()
+
+	if n.closed {
+		n.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	n.logger.Debug("Close()")
+
+	n.closed = true
+
+	var producerIds []string
+
+	for producerId := range n.published {
+		producerIds = append(producerIds, producerId)
+	}
+
+	var pipes []*pipe
+
+	for _, p := range n.pipes {
+		pipes = append(pipes, p)
+	}
+
+	n.locker.Unlock()
+
+	for _, producerId := range producerIds {
+		n.unpublish(producerId)
+	}
+	for _, p := range pipes {
+		p.transport.Close()
+	}
+
+	return n.server.Close()
+}
@@ -0,0 +1,12 @@
+module github.com/jiyeyuran/mediasoup-go/cluster/redisbackend
+
+go 1.15
+
+require (
+	github.com/go-redis/redis/v8 v8.11.5
+	github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../../
@@ -0,0 +1,176 @@
+// Package redisbackend implements cluster.Backend with Redis: the Producers
+// are registered as keys, and the requests between the nodes are sent with
+// pub/sub.
+//
+//	backend := redisbackend.New(redisbackend.Options{
+//		Client: redis.NewClient(&redis.Options{Addr: "redis:6379"}),
+//	})
+//	node, err := cluster.NewNode(cluster.Options{Backend: backend, ...})
+//
+// It is a separate module so that the mediasoup-go module does not depend on
+// the Redis client.
+package redisbackend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/cluster"
+)
+
+type Options struct {
+	/**
+	 * Redis client.
+	 */
+	Client redis.UniversalClient
+
+	/**
+	 * Prefix of the keys and channels. Default "mediasoup".
+	 */
+	Prefix string
+
+	/**
+	 * Logger of the backend. Default mediasoup.NewLogger("RedisBackend").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Backend is a cluster.Backend using Redis.
+ */
+type Backend struct {
+	client redis.UniversalClient
+	prefix string
+	logger mediasoup.Logger
+}
+
+// envelope is a request sent to a node channel.
+type envelope struct {
+	Reply string `json:"reply"`
+	Data  []byte `json:"data"`
+}
+
+/**
+ * Create a Backend with the given client.
+ */
+func New(options Options) *Backend {
+	if len(options.Prefix) == 0 {
+		options.Prefix = "mediasoup"
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("RedisBackend")
+	}
+
+	return &Backend{
+		client: options.Client,
+		prefix: options.Prefix,
+		logger: options.Logger,
+	}
+}
+
+func (b *Backend) key(kind, id string) string {
+	return b.prefix + ":" + kind + ":" + id
+}
+
+func (b *Backend) Register(ctx context.Context, record cluster.ProducerRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return b.client.Set(ctx, b.key("producer", record.ProducerId), data, 0).Err()
+}
+
+func (b *Backend) Unregister(ctx context.Context, producerId string) error {
+	return b.client.Del(ctx, b.key("producer", producerId)).Err()
+}
+
+func (b *Backend) Lookup(ctx context.Context, producerId string) (record cluster.ProducerRecord, err error) {
+	data, err := b.client.Get(ctx, b.key("producer", producerId)).Bytes()
+	if err == redis.Nil {
+		err = cluster.ErrProducerNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &record)
+
+	return
+}
+
+func (b *Backend) Request(ctx context.Context, nodeId string, request []byte) (response []byte, err error) {
+	replyId := make([]byte, 16)
+
+	if _, err = rand.Read(replyId); err != nil {
+		return
+	}
+
+	reply := b.key("reply", hex.EncodeToString(replyId))
+
+	// Subscribe to the reply channel before sending the request.
+	pubsub := b.client.Subscribe(ctx, reply)
+	defer pubsub.Close()
+
+	if _, err = pubsub.Receive(ctx); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(envelope{Reply: reply, Data: request})
+	if err != nil {
+		return
+	}
+
+	receivers, err := b.client.Publish(ctx, b.key("node", nodeId), data).Result()
+	if err != nil {
+		return
+	}
+	if receivers == 0 {
+		return nil, cluster.ErrNodeNotFound
+	}
+
+	select {
+	case msg, ok := <-pubsub.Channel():
+		if !ok {
+			return nil, redis.ErrClosed
+		}
+		return []byte(msg.Payload), nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *Backend) Serve(nodeId string, handler func(request []byte) []byte) (io.Closer, error) {
+	ctx := context.Background()
+	pubsub := b.client.Subscribe(ctx, b.key("node", nodeId))
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var req envelope
+
+			if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil {
+				b.logger.Warn("Serve() | invalid request: %v", err)
+				continue
+			}
+
+			go func() {
+				if err := b.client.Publish(ctx, req.Reply, handler(req.Data)).Err(); err != nil {
+					b.logger.Warn("Serve() | sending response failed: %v", err)
+				}
+			}()
+		}
+	}()
+
+	return pubsub, nil
+}
@@ -0,0 +1,168 @@
+package mediasoup
+
+import (
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+/**
+ * Global defaults of the library. Change them with SetConfig(), preferably
+ * before creating any worker.
+ */
+type Config struct {
+	/**
+	 * Time to wait for the response of a worker request, increased by 100ms for
+	 * every pending request. Default 15 seconds.
+	 */
+	RequestTimeout time.Duration
+
+	/**
+	 * Number of events buffered by an entity for its listeners before SafeEmit
+	 * blocks. Default 128.
+	 */
+	EmitterQueueSize int
+
+	/**
+	 * Number of listeners of an event above which a warning is logged.
+	 * Default 10.
+	 */
+	EmitterMaxListeners int
+
+	/**
+	 * How the entities dispatch their events to their listeners. Default
+	 * EmitterDispatchMode_Async.
+	 */
+	EmitterDispatchMode EmitterDispatchMode
+
+	/**
+	 * Interval between two polls of the StatsCollectors created without
+	 * Interval. Default 10 seconds.
+	 */
+	StatsInterval time.Duration
+
+	/**
+	 * Function creating the loggers of the default NewLogger, including the
+	 * ones of the entities already created. Default (nil) the console logger.
+	 * It must not call the default NewLogger.
+	 */
+	NewLogger func(scope string) Logger
+
+	/**
+	 * Id generator of the default DefaultIdGenerator, i.e. of the workers
+	 * created without WithIdGenerator(). Default (nil) UUIDs v4.
+	 */
+	IdGenerator IdGenerator
+}
+
+/**
+ * How events are dispatched to the listeners.
+ */
+type EmitterDispatchMode string
+
+const (
+	/**
+	 * Events are queued (see Config.EmitterQueueSize) and the listeners called
+	 * by the goroutine of the emitter.
+	 */
+	EmitterDispatchMode_Async EmitterDispatchMode = "async"
+
+	/**
+	 * Listeners are called by the goroutine emitting the event, e.g. the one
+	 * dispatching the worker notifications of the entity, in order. The
+	 * Channels themselves always dispatch asynchronously, so that the
+	 * listeners can send requests to the worker.
+	 */
+	EmitterDispatchMode_Sync EmitterDispatchMode = "sync"
+)
+
+var (
+	configLocker sync.RWMutex
+	config       = DefaultConfig()
+)
+
+/**
+ * Config with the default values.
+ */
+func DefaultConfig() Config {
+	return Config{
+		RequestTimeout:      15 * time.Second,
+		EmitterQueueSize:    128,
+		EmitterMaxListeners: 10,
+		EmitterDispatchMode: EmitterDispatchMode_Async,
+		StatsInterval:       10 * time.Second,
+	}
+}
+
+/**
+ * Current configuration.
+ */
+func GetConfig() Config {
+	configLocker.RLock()
+	defer configLocker.RUnlock()
+
+	return config
+}
+
+/**
+ * Change the configuration. Zero values are replaced with the default ones,
+ * e.g. SetConfig(Config{RequestTimeout: 5 * time.Second}) only changes the
+ * request timeout (and resets the others).
+ */
+func SetConfig(newConfig Config) {
+	defaults := DefaultConfig()
+
+	if newConfig.RequestTimeout <= 0 {
+		newConfig.RequestTimeout = defaults.RequestTimeout
+	}
+	if newConfig.EmitterQueueSize <= 0 {
+		newConfig.EmitterQueueSize = defaults.EmitterQueueSize
+	}
+	if newConfig.EmitterMaxListeners <= 0 {
+		newConfig.EmitterMaxListeners = defaults.EmitterMaxListeners
+	}
+	if newConfig.EmitterDispatchMode != EmitterDispatchMode_Sync {
+		newConfig.EmitterDispatchMode = defaults.EmitterDispatchMode
+	}
+	if newConfig.StatsInterval <= 0 {
+		newConfig.StatsInterval = defaults.StatsInterval
+	}
+
+	configLocker.Lock()
+	defer configLocker.Unlock()
+
+	config = newConfig
+}
+
+// getConfig returns the current configuration.
+func getConfig() Config {
+	configLocker.RLock()
+	defer configLocker.RUnlock()
+
+	return config
+}
+
+// newConfiguredLogger is the default NewLogger, creating the loggers with the
+// function of the configuration if any.
+func newConfiguredLogger(scope string) Logger {
+	if newLogger := getConfig().NewLogger; newLogger != nil {
+		return newLogger(scope)
+	}
+	return newDefaultLogger(scope)
+}
+
+// generateConfiguredId is the default DefaultIdGenerator, generating the ids
+// with the generator of the configuration if any.
+func generateConfiguredId(kind string) string {
+	if idGenerator := getConfig().IdGenerator; idGenerator != nil {
+		return idGenerator(kind)
+	}
+	return uuid.NewV4().String()
+}
+
+// requestTimeout returns the timeout of a request sent while the given number
+// of requests are pending.
+func requestTimeout(pendingRequests int64) time.Duration {
+	return getConfig().RequestTimeout + time.Duration(pendingRequests)*100*time.Millisecond
+}
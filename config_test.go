@@ -0,0 +1,96 @@
+package mediasoup
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	SetConfig(Config{
+		RequestTimeout: 5 * time.Second,
+		IdGenerator:    func(kind string) string { return kind },
+	})
+
+	config := GetConfig()
+	assert.Equal(t, 5*time.Second, config.RequestTimeout)
+	assert.Equal(t, DefaultConfig().StatsInterval, config.StatsInterval)
+	assert.Equal(t, DefaultConfig().EmitterQueueSize, config.EmitterQueueSize)
+	assert.Equal(t, EmitterDispatchMode_Async, config.EmitterDispatchMode)
+	assert.Nil(t, config.NewLogger)
+	assert.Equal(t, "router", IdGenerator(nil).newId("router"))
+
+	assert.Equal(t, 5*time.Second+300*time.Millisecond, requestTimeout(3))
+
+	SetConfig(DefaultConfig())
+	assert.NotEqual(t, "router", IdGenerator(nil).newId("router"))
+}
+
+func TestConfig_NewLogger(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	var scopes []string
+
+	SetConfig(Config{
+		NewLogger: func(scope string) Logger {
+			scopes = append(scopes, scope)
+			return newDefaultLogger(scope)
+		},
+	})
+	NewLogger("Test")
+
+	assert.Equal(t, []string{"Test"}, scopes)
+}
+
+func TestConfig_Concurrent(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			SetConfig(Config{
+				NewLogger:   newDefaultLogger,
+				IdGenerator: func(kind string) string { return kind },
+			})
+		}()
+		go func() {
+			defer wg.Done()
+
+			NewLogger("Test")
+			IdGenerator(nil).newId("router")
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestConfig_SyncEmitter(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+
+	SetConfig(Config{EmitterDispatchMode: EmitterDispatchMode_Sync})
+
+	emitter := NewEventEmitter()
+	called := false
+
+	emitter.On("event", func() {
+		called = true
+	})
+	emitter.On("panic", func() {
+		panic("listener failure")
+	})
+	emitter.SafeEmit("event")
+
+	assert.True(t, called)
+	assert.NotPanics(t, func() {
+		emitter.SafeEmit("panic").Wait()
+	})
+}
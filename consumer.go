@@ -1,7 +1,7 @@
 package mediasoup
 
 import (
-	"encoding/json"
+	"context"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -52,6 +52,23 @@ type ConsumerOptions struct {
 	 */
 	Pipe bool
 
+	/**
+	 * Codec policy for this Consumer. It overrides the one set in the
+	 * Transport, if any.
+	 */
+	CodecPolicy CodecPolicy `json:"-"`
+
+	/**
+	 * Trace events to enable as soon as the Consumer is created.
+	 */
+	TraceEventTypes []ConsumerTraceEventType `json:"-"`
+
+	/**
+	 * Base logger for the Consumer (e.g. with the id of the consuming peer).
+	 * Default the one of the Transport.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -156,6 +173,7 @@ type consumerParams struct {
 	producerPaused  bool
 	score           ConsumerScore
 	preferredLayers *ConsumerLayers
+	loggerContext   loggerContext
 }
 
 type consumerData struct {
@@ -194,10 +212,14 @@ type Consumer struct {
 	preferredLayers *ConsumerLayers
 	currentLayers   *ConsumerLayers // Current video layers (just for video with simulcast or SVC).
 	observer        IEventEmitter
+	// Guards paused, producerPaused, priority, score, preferredLayers and
+	// currentLayers. The notification handlers never wait for locker, which is
+	// held during the pause and resume requests.
+	dataLocker sync.RWMutex
 }
 
 func newConsumer(params consumerParams) *Consumer {
-	logger := NewLogger("Consumer")
+	logger := params.loggerContext.newLogger("Consumer")
 
 	logger.Debug("constructor()")
 
@@ -226,7 +248,7 @@ func newConsumer(params consumerParams) *Consumer {
 		priority:        1,
 		score:           params.score,
 		preferredLayers: params.preferredLayers,
-		observer:        NewEventEmitter(),
+		observer:        params.loggerContext.newObserver("consumer", params.internal.ConsumerId),
 	}
 
 	consumer.handleWorkerNotifications()
@@ -271,31 +293,49 @@ func (consumer *Consumer) Type() ConsumerType {
 
 // Whether the Consumer is paused.
 func (consumer *Consumer) Paused() bool {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.paused
 }
 
 // Whether the associate Producer is paused.
 func (consumer *Consumer) ProducerPaused() bool {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.producerPaused
 }
 
 // Current priority.
 func (consumer *Consumer) Priority() uint32 {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.priority
 }
 
 // Consumer score with consumer and consumer keys.
 func (consumer *Consumer) Score() ConsumerScore {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.score
 }
 
 // Preferred video layers.
 func (consumer *Consumer) PreferredLayers() *ConsumerLayers {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.preferredLayers
 }
 
 // Current video layers.
 func (consumer *Consumer) CurrentLayers() *ConsumerLayers {
+	consumer.dataLocker.RLock()
+	defer consumer.dataLocker.RUnlock()
+
 	return consumer.currentLayers
 }
 
@@ -320,25 +360,28 @@ func (consumer *Consumer) Observer() IEventEmitter {
 
 // Close the Consumer.
 func (consumer *Consumer) Close() (err error) {
-	if atomic.CompareAndSwapUint32(&consumer.closed, 0, 1) {
-		consumer.logger.Debug("close()")
-
-		// Remove notification subscriptions.
-		consumer.channel.RemoveAllListeners(consumer.internal.ConsumerId)
-		consumer.payloadChannel.RemoveAllListeners(consumer.internal.ConsumerId)
+	if !atomic.CompareAndSwapUint32(&consumer.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		response := consumer.channel.Request("consumer.close", consumer.internal)
-		if err = response.Err(); err != nil {
-			consumer.logger.Error("consumer close error: %s", err)
-		}
+	consumer.logger.Debug("close()")
 
-		consumer.Emit("@close")
-		consumer.RemoveAllListeners()
+	// Remove notification subscriptions.
+	consumer.channel.RemoveAllListeners(consumer.internal.ConsumerId)
+	consumer.payloadChannel.RemoveAllListeners(consumer.internal.ConsumerId)
 
-		// Emit observer event.
-		consumer.observer.SafeEmit("close")
-		consumer.observer.RemoveAllListeners()
+	response := consumer.channel.Request("consumer.close", consumer.internal)
+	if err = response.Err(); err != nil {
+		consumer.logger.Error("consumer close error: %s", err)
 	}
+
+	consumer.Emit("@close")
+	consumer.RemoveAllListeners()
+
+	// Emit observer event.
+	consumer.observer.SafeEmit("close")
+	consumer.observer.RemoveAllListeners()
+
 	return
 }
 
@@ -362,9 +405,16 @@ func (consumer *Consumer) transportClosed() {
 
 // Dump Consumer.
 func (consumer *Consumer) Dump() (dump *ConsumerDump, err error) {
+	return consumer.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (consumer *Consumer) DumpContext(ctx context.Context) (dump *ConsumerDump, err error) {
 	consumer.logger.Debug("dump()")
 
-	resp := consumer.channel.Request("consumer.dump", consumer.internal)
+	resp := consumer.channel.RequestContext(ctx, "consumer.dump", consumer.internal)
 	err = resp.Unmarshal(&dump)
 
 	return
@@ -372,9 +422,16 @@ func (consumer *Consumer) Dump() (dump *ConsumerDump, err error) {
 
 // Get Consumer stats.
 func (consumer *Consumer) GetStats() (stats []*ConsumerStat, err error) {
+	return consumer.GetStatsContext(context.Background())
+}
+
+/**
+ * GetStatsContext is GetStats with a context cancelling the worker request.
+ */
+func (consumer *Consumer) GetStatsContext(ctx context.Context) (stats []*ConsumerStat, err error) {
 	consumer.logger.Debug("getStats()")
 
-	resp := consumer.channel.Request("consumer.getStats", consumer.internal)
+	resp := consumer.channel.RequestContext(ctx, "consumer.getStats", consumer.internal)
 	err = resp.Unmarshal(&stats)
 
 	return
@@ -382,20 +439,29 @@ func (consumer *Consumer) GetStats() (stats []*ConsumerStat, err error) {
 
 // Pause the Consumer.
 func (consumer *Consumer) Pause() (err error) {
+	return consumer.PauseContext(context.Background())
+}
+
+/**
+ * PauseContext is Pause with a context cancelling the worker request.
+ */
+func (consumer *Consumer) PauseContext(ctx context.Context) (err error) {
 	consumer.locker.Lock()
 	defer consumer.locker.Unlock()
 
 	consumer.logger.Debug("pause()")
 
-	wasPaused := consumer.paused || consumer.producerPaused
-
-	response := consumer.channel.Request("consumer.pause", consumer.internal)
+	response := consumer.channel.RequestContext(ctx, "consumer.pause", consumer.internal)
 
 	if err = response.Err(); err != nil {
 		return
 	}
 
+	// Checked and set at once, the Producer being paused or resumed meanwhile.
+	consumer.dataLocker.Lock()
+	wasPaused := consumer.paused || consumer.producerPaused
 	consumer.paused = true
+	consumer.dataLocker.Unlock()
 
 	// Emit observer event.
 	if !wasPaused {
@@ -407,23 +473,33 @@ func (consumer *Consumer) Pause() (err error) {
 
 // Resume the Consumer.
 func (consumer *Consumer) Resume() (err error) {
+	return consumer.ResumeContext(context.Background())
+}
+
+/**
+ * ResumeContext is Resume with a context cancelling the worker request.
+ */
+func (consumer *Consumer) ResumeContext(ctx context.Context) (err error) {
 	consumer.locker.Lock()
 	defer consumer.locker.Unlock()
 
 	consumer.logger.Debug("resume()")
 
-	wasPaused := consumer.paused || consumer.producerPaused
-
-	response := consumer.channel.Request("consumer.resume", consumer.internal)
+	response := consumer.channel.RequestContext(ctx, "consumer.resume", consumer.internal)
 
 	if err = response.Err(); err != nil {
 		return
 	}
 
+	// Checked and set at once, the Producer being paused or resumed meanwhile.
+	consumer.dataLocker.Lock()
+	wasPaused := consumer.paused || consumer.producerPaused
 	consumer.paused = false
+	producerPaused := consumer.producerPaused
+	consumer.dataLocker.Unlock()
 
 	// Emit observer event.
-	if wasPaused && !consumer.producerPaused {
+	if wasPaused && !producerPaused {
 		consumer.observer.SafeEmit("resume")
 	}
 
@@ -432,19 +508,41 @@ func (consumer *Consumer) Resume() (err error) {
 
 // Set preferred video layers.
 func (consumer *Consumer) SetPreferredLayers(layers ConsumerLayers) (err error) {
+	return consumer.SetPreferredLayersContext(context.Background(), layers)
+}
+
+/**
+ * SetPreferredLayersContext is SetPreferredLayers with a context cancelling the worker request.
+ */
+func (consumer *Consumer) SetPreferredLayersContext(ctx context.Context, layers ConsumerLayers) (err error) {
 	consumer.logger.Debug("setPreferredLayers()")
 
-	response := consumer.channel.Request("consumer.setPreferredLayers", consumer.internal, layers)
-	err = response.Unmarshal(&consumer.preferredLayers)
+	response := consumer.channel.RequestContext(ctx, "consumer.setPreferredLayers", consumer.internal, layers)
+
+	var preferredLayers *ConsumerLayers
+	if err = response.Unmarshal(&preferredLayers); err != nil {
+		return
+	}
+
+	consumer.dataLocker.Lock()
+	consumer.preferredLayers = preferredLayers
+	consumer.dataLocker.Unlock()
 
 	return
 }
 
 // Set priority.
 func (consumer *Consumer) SetPriority(priority uint32) (err error) {
+	return consumer.SetPriorityContext(context.Background(), priority)
+}
+
+/**
+ * SetPriorityContext is SetPriority with a context cancelling the worker request.
+ */
+func (consumer *Consumer) SetPriorityContext(ctx context.Context, priority uint32) (err error) {
 	consumer.logger.Debug("setPriority()")
 
-	response := consumer.channel.Request("consumer.setPriority", consumer.internal, H{"priority": priority})
+	response := consumer.channel.RequestContext(ctx, "consumer.setPriority", consumer.internal, H{"priority": priority})
 
 	var result struct {
 		Priority uint32
@@ -453,23 +551,39 @@ func (consumer *Consumer) SetPriority(priority uint32) (err error) {
 		return
 	}
 
+	consumer.dataLocker.Lock()
 	consumer.priority = result.Priority
+	consumer.dataLocker.Unlock()
 
 	return
 }
 
 // Unset priority.
 func (consumer *Consumer) UnsetPriority() (err error) {
+	return consumer.UnsetPriorityContext(context.Background())
+}
+
+/**
+ * UnsetPriorityContext is UnsetPriority with a context cancelling the worker request.
+ */
+func (consumer *Consumer) UnsetPriorityContext(ctx context.Context) (err error) {
 	consumer.logger.Debug("unsetPriority()")
 
-	return consumer.SetPriority(1)
+	return consumer.SetPriorityContext(ctx, 1)
 }
 
 // Request a key frame to the Producer.
 func (consumer *Consumer) RequestKeyFrame() error {
+	return consumer.RequestKeyFrameContext(context.Background())
+}
+
+/**
+ * RequestKeyFrameContext is RequestKeyFrame with a context cancelling the worker request.
+ */
+func (consumer *Consumer) RequestKeyFrameContext(ctx context.Context) error {
 	consumer.logger.Debug("requestKeyFrame()")
 
-	response := consumer.channel.Request("consumer.requestKeyFrame", consumer.internal)
+	response := consumer.channel.RequestContext(ctx, "consumer.requestKeyFrame", consumer.internal)
 
 	return response.Err()
 }
@@ -478,13 +592,20 @@ func (consumer *Consumer) RequestKeyFrame() error {
  * Enable 'trace' event.
  */
 func (consumer *Consumer) EnableTraceEvent(types ...ConsumerTraceEventType) error {
+	return consumer.EnableTraceEventContext(context.Background(), types...)
+}
+
+/**
+ * EnableTraceEventContext is EnableTraceEvent with a context cancelling the worker request.
+ */
+func (consumer *Consumer) EnableTraceEventContext(ctx context.Context, types ...ConsumerTraceEventType) error {
 	consumer.logger.Debug("enableTraceEvent()")
 
 	if types == nil {
 		types = []ConsumerTraceEventType{}
 	}
 
-	response := consumer.channel.Request("consumer.enableTraceEvent", consumer.internal, H{"types": types})
+	response := consumer.channel.RequestContext(ctx, "consumer.enableTraceEvent", consumer.internal, H{"types": types})
 
 	return response.Err()
 }
@@ -507,10 +628,10 @@ func (consumer *Consumer) handleWorkerNotifications() {
 			}
 
 		case "producerpause":
-			consumer.locker.Lock()
-			defer consumer.locker.Unlock()
+			consumer.dataLocker.Lock()
 
 			if consumer.producerPaused {
+				consumer.dataLocker.Unlock()
 				break
 			}
 
@@ -518,6 +639,8 @@ func (consumer *Consumer) handleWorkerNotifications() {
 
 			consumer.producerPaused = true
 
+			consumer.dataLocker.Unlock()
+
 			consumer.SafeEmit("producerpause")
 
 			// Emit observer event.
@@ -526,30 +649,35 @@ func (consumer *Consumer) handleWorkerNotifications() {
 			}
 
 		case "producerresume":
-			consumer.locker.Lock()
-			defer consumer.locker.Unlock()
+			consumer.dataLocker.Lock()
 
 			if !consumer.producerPaused {
+				consumer.dataLocker.Unlock()
 				break
 			}
 
 			wasPaused := consumer.paused || consumer.producerPaused
+			paused := consumer.paused
 
 			consumer.producerPaused = false
 
+			consumer.dataLocker.Unlock()
+
 			consumer.SafeEmit("producerresume")
 
 			// Emit observer event.
-			if wasPaused && !consumer.paused {
+			if wasPaused && !paused {
 				consumer.observer.SafeEmit("resume")
 			}
 
 		case "score":
 			var score ConsumerScore
 
-			json.Unmarshal(data, &score)
+			unmarshalJSON(data, &score)
 
+			consumer.dataLocker.Lock()
 			consumer.score = score
+			consumer.dataLocker.Unlock()
 
 			consumer.SafeEmit("score", score)
 
@@ -559,9 +687,11 @@ func (consumer *Consumer) handleWorkerNotifications() {
 		case "layerschange":
 			var layers ConsumerLayers
 
-			json.Unmarshal(data, &layers)
+			unmarshalJSON(data, &layers)
 
+			consumer.dataLocker.Lock()
 			consumer.currentLayers = &layers
+			consumer.dataLocker.Unlock()
 
 			consumer.SafeEmit("layerschange", layers)
 
@@ -571,7 +701,7 @@ func (consumer *Consumer) handleWorkerNotifications() {
 		case "trace":
 			var trace ConsumerTraceEventData
 
-			json.Unmarshal(data, &trace)
+			unmarshalJSON(data, &trace)
 
 			consumer.SafeEmit("trace", trace)
 
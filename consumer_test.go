@@ -3,6 +3,7 @@ package mediasoup
 import (
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/jiyeyuran/mediasoup-go/h264"
@@ -38,11 +39,9 @@ func (suite *ConsumerTestingSuite) SetupTest() {
 			MimeType:  "video/H264",
 			ClockRate: 90000,
 			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					LevelAsymmetryAllowed: 1,
-					PacketizationMode:     1,
-					ProfileLevelId:        "4d0032",
-				},
+				{Key: "level-asymmetry-allowed", Value: 1},
+				{Key: "packetization-mode", Value: 1},
+				{Key: "profile-level-id", Value: "4d0032"},
 			},
 		},
 	}
@@ -62,11 +61,9 @@ func (suite *ConsumerTestingSuite) SetupTest() {
 				PreferredPayloadType: 101,
 				ClockRate:            90000,
 				Parameters: RtpCodecSpecificParameters{
-					RtpParameter: h264.RtpParameter{
-						LevelAsymmetryAllowed: 1,
-						PacketizationMode:     1,
-						ProfileLevelId:        "4d0032",
-					},
+					{Key: "level-asymmetry-allowed", Value: 1},
+					{Key: "packetization-mode", Value: 1},
+					{Key: "profile-level-id", Value: "4d0032"},
 				},
 				RtcpFeedback: []RtcpFeedback{
 					{Type: "nack", Parameter: ""},
@@ -81,7 +78,7 @@ func (suite *ConsumerTestingSuite) SetupTest() {
 				PreferredPayloadType: 102,
 				ClockRate:            90000,
 				Parameters: RtpCodecSpecificParameters{
-					Apt: 101,
+					{Key: "apt", Value: 101},
 				},
 			},
 		},
@@ -193,8 +190,8 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_Succeeds() {
 		PayloadType: 100,
 		Channels:    2,
 		Parameters: RtpCodecSpecificParameters{
-			Useinbandfec: 1,
-			Usedtx:       1,
+			{Key: "useinbandfec", Value: 1},
+			{Key: "usedtx", Value: 1},
 		},
 	}, audioConsumer.RtpParameters().Codecs[0])
 
@@ -243,10 +240,8 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_Succeeds() {
 		ClockRate:   90000,
 		PayloadType: 103,
 		Parameters: RtpCodecSpecificParameters{
-			RtpParameter: h264.RtpParameter{
-				PacketizationMode: 1,
-				ProfileLevelId:    "4d0032",
-			},
+			{Key: "packetization-mode", Value: 1},
+			{Key: "profile-level-id", Value: "4d0032"},
 		},
 		RtcpFeedback: []RtcpFeedback{
 			{Type: "nack"},
@@ -260,7 +255,7 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_Succeeds() {
 		ClockRate:   90000,
 		PayloadType: 104,
 		Parameters: RtpCodecSpecificParameters{
-			Apt: 103,
+			{Key: "apt", Value: 103},
 		},
 	}, videoConsumer.RtpParameters().Codecs[1])
 
@@ -296,10 +291,8 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_Succeeds() {
 		ClockRate:   90000,
 		PayloadType: 103,
 		Parameters: RtpCodecSpecificParameters{
-			RtpParameter: h264.RtpParameter{
-				PacketizationMode: 1,
-				ProfileLevelId:    "4d0032",
-			},
+			{Key: "packetization-mode", Value: 1},
+			{Key: "profile-level-id", Value: "4d0032"},
 		},
 		RtcpFeedback: []RtcpFeedback{
 			{Type: "nack"},
@@ -313,7 +306,7 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_Succeeds() {
 		ClockRate:   90000,
 		PayloadType: 104,
 		Parameters: RtpCodecSpecificParameters{
-			Apt: 103,
+			{Key: "apt", Value: 103},
 		},
 	}, videoPipeConsumer.RtpParameters().Codecs[1])
 	suite.EqualValues(ConsumerType_Pipe, videoPipeConsumer.Type())
@@ -413,6 +406,40 @@ func (suite *ConsumerTestingSuite) TestTransportConsume_UnsupportedError() {
 	suite.IsType(NewUnsupportedError(""), err)
 }
 
+func (suite *ConsumerTestingSuite) TestTransportConsume_CodecPolicy() {
+	transport2, videoProducer := suite.transport2, suite.videoProducer
+
+	banH264 := func(codecs []*RtpCodecParameters) (allowed []*RtpCodecParameters) {
+		for _, codec := range codecs {
+			if !strings.EqualFold(codec.MimeType, "video/H264") {
+				allowed = append(allowed, codec)
+			}
+		}
+		return
+	}
+
+	transport2.SetCodecPolicy(banH264)
+
+	_, err := transport2.Consume(ConsumerOptions{
+		ProducerId:      videoProducer.Id(),
+		RtpCapabilities: suite.consumerDeviceCapabilities,
+	})
+	suite.IsType(NewUnsupportedError(""), err)
+
+	// A per Consumer policy overrides the Transport one.
+	consumer, err := transport2.Consume(ConsumerOptions{
+		ProducerId:      videoProducer.Id(),
+		RtpCapabilities: suite.consumerDeviceCapabilities,
+		CodecPolicy: func(codecs []*RtpCodecParameters) []*RtpCodecParameters {
+			return codecs
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Equal("video/H264", consumer.RtpParameters().Codecs[0].MimeType)
+
+	transport2.SetCodecPolicy(nil)
+}
+
 func (suite *ConsumerTestingSuite) TestConsumerDump() {
 	audioConsumer := suite.audioConsumer()
 	data, _ := audioConsumer.Dump()
@@ -425,7 +452,7 @@ func (suite *ConsumerTestingSuite) TestConsumerDump() {
 	suite.EqualValues(100, data.RtpParameters.Codecs[0].PayloadType)
 	suite.EqualValues(48000, data.RtpParameters.Codecs[0].ClockRate)
 	suite.EqualValues(2, data.RtpParameters.Codecs[0].Channels)
-	suite.Equal(RtpCodecSpecificParameters{Useinbandfec: 1, Usedtx: 1}, data.RtpParameters.Codecs[0].Parameters)
+	suite.Equal(RtpCodecSpecificParameters{{Key: "useinbandfec", Value: 1}, {Key: "usedtx", Value: 1}}, data.RtpParameters.Codecs[0].Parameters)
 	suite.Equal([]RtcpFeedback{}, data.RtpParameters.Codecs[0].RtcpFeedback)
 	suite.Len(data.RtpParameters.HeaderExtensions, 3)
 	suite.Equal([]RtpHeaderExtensionParameters{
@@ -450,7 +477,7 @@ func (suite *ConsumerTestingSuite) TestConsumerDump() {
 	}, data.RtpParameters.HeaderExtensions)
 	suite.Len(data.RtpParameters.Encodings, 1)
 	suite.Equal([]RtpEncodingParameters{
-		{CodecPayloadType: 100, Ssrc: audioConsumer.RtpParameters().Encodings[0].Ssrc},
+		{CodecPayloadType: 100, Ssrc: audioConsumer.RtpParameters().Encodings[0].Ssrc, Dtx: true},
 	}, data.RtpParameters.Encodings)
 	suite.EqualValues("simple", data.Type)
 	suite.Len(data.ConsumableRtpEncodings, 1)
@@ -475,7 +502,7 @@ func (suite *ConsumerTestingSuite) TestConsumerDump() {
 	suite.Equal(h264.RtpParameter{
 		PacketizationMode: 1,
 		ProfileLevelId:    "4d0032",
-	}, data.RtpParameters.Codecs[0].Parameters.RtpParameter)
+	}, data.RtpParameters.Codecs[0].Parameters.h264())
 	suite.EqualValues([]RtcpFeedback{
 		{Type: "nack"},
 		{Type: "nack", Parameter: "pli"},
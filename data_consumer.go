@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"sync/atomic"
 )
@@ -35,6 +36,11 @@ type DataConsumerOptions struct {
 	 */
 	MaxRetransmits uint16 `json:"maxRetransmits,omitempty"`
 
+	/**
+	 * Base logger for the DataConsumer. Default the one of the Transport.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -67,6 +73,7 @@ type dataConsumerParams struct {
 	channel        *Channel
 	payloadChannel *PayloadChannel
 	appData        interface{}
+	loggerContext  loggerContext
 }
 
 type dataConsumerData struct {
@@ -105,7 +112,7 @@ type DataConsumer struct {
 }
 
 func newDataConsumer(params dataConsumerParams) *DataConsumer {
-	logger := NewLogger("DataConsumer")
+	logger := params.loggerContext.newLogger("DataConsumer")
 
 	logger.Debug("constructor()")
 
@@ -121,7 +128,7 @@ func newDataConsumer(params dataConsumerParams) *DataConsumer {
 		channel:        params.channel,
 		payloadChannel: params.payloadChannel,
 		appData:        params.appData,
-		observer:       NewEventEmitter(),
+		observer:       params.loggerContext.newObserver("dataconsumer", params.internal.DataConsumerId),
 	}
 
 	consumer.handleWorkerNotifications()
@@ -188,26 +195,29 @@ func (c *DataConsumer) Observer() IEventEmitter {
 
 // Close the DataConsumer.
 func (c *DataConsumer) Close() (err error) {
-	if atomic.CompareAndSwapUint32(&c.closed, 0, 1) {
-		c.logger.Debug("close()")
-
-		// Remove notification subscriptions.
-		c.channel.RemoveAllListeners(c.Id())
-		c.payloadChannel.RemoveAllListeners(c.Id())
+	if !atomic.CompareAndSwapUint32(&c.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		response := c.channel.Request("dataConsumer.close", c.internal)
+	c.logger.Debug("close()")
 
-		if err = response.Err(); err != nil {
-			c.logger.Error("dataConsumer close error: %s", err)
-		}
+	// Remove notification subscriptions.
+	c.channel.RemoveAllListeners(c.Id())
+	c.payloadChannel.RemoveAllListeners(c.Id())
 
-		c.Emit("@close")
-		c.RemoveAllListeners()
+	response := c.channel.Request("dataConsumer.close", c.internal)
 
-		// Emit observer event.
-		c.observer.SafeEmit("close")
-		c.observer.RemoveAllListeners()
+	if err = response.Err(); err != nil {
+		c.logger.Error("dataConsumer close error: %s", err)
 	}
+
+	c.Emit("@close")
+	c.RemoveAllListeners()
+
+	// Emit observer event.
+	c.observer.SafeEmit("close")
+	c.observer.RemoveAllListeners()
+
 	return
 }
 
@@ -231,9 +241,16 @@ func (c *DataConsumer) transportClosed() {
 
 // Dump DataConsumer.
 func (c *DataConsumer) Dump() (data DataConsumerDump, err error) {
+	return c.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (c *DataConsumer) DumpContext(ctx context.Context) (data DataConsumerDump, err error) {
 	c.logger.Debug("dump()")
 
-	resp := c.channel.Request("dataConsumer.dump", c.internal)
+	resp := c.channel.RequestContext(ctx, "dataConsumer.dump", c.internal)
 	err = resp.Unmarshal(&data)
 
 	return
@@ -241,9 +258,16 @@ func (c *DataConsumer) Dump() (data DataConsumerDump, err error) {
 
 // Get DataConsumer stats.
 func (c *DataConsumer) GetStats() (stats []*DataConsumerStat, err error) {
+	return c.GetStatsContext(context.Background())
+}
+
+/**
+ * GetStatsContext is GetStats with a context cancelling the worker request.
+ */
+func (c *DataConsumer) GetStatsContext(ctx context.Context) (stats []*DataConsumerStat, err error) {
 	c.logger.Debug("getStats()")
 
-	resp := c.channel.Request("dataConsumer.getStats", c.internal)
+	resp := c.channel.RequestContext(ctx, "dataConsumer.getStats", c.internal)
 	err = resp.Unmarshal(&stats)
 
 	return
@@ -253,9 +277,16 @@ func (c *DataConsumer) GetStats() (stats []*DataConsumerStat, err error) {
  * Set buffered amount low threshold.
  */
 func (c *DataConsumer) SetBufferedAmountLowThreshold(threshold int) error {
+	return c.SetBufferedAmountLowThresholdContext(context.Background(), threshold)
+}
+
+/**
+ * SetBufferedAmountLowThresholdContext is SetBufferedAmountLowThreshold with a context cancelling the worker request.
+ */
+func (c *DataConsumer) SetBufferedAmountLowThresholdContext(ctx context.Context, threshold int) error {
 	c.logger.Debug("setBufferedAmountLowThreshold() [threshold:%s]", threshold)
 
-	resp := c.channel.Request("dataConsumer.setBufferedAmountLowThreshold", c.internal, H{
+	resp := c.channel.RequestContext(ctx, "dataConsumer.setBufferedAmountLowThreshold", c.internal, H{
 		"threshold": threshold,
 	})
 
@@ -266,6 +297,13 @@ func (c *DataConsumer) SetBufferedAmountLowThreshold(threshold int) error {
  * Send data.
  */
 func (c *DataConsumer) Send(data []byte, ppid ...int) (err error) {
+	return c.SendContext(context.Background(), data, ppid...)
+}
+
+/**
+ * SendContext is Send with a context cancelling the worker request.
+ */
+func (c *DataConsumer) SendContext(ctx context.Context, data []byte, ppid ...int) (err error) {
 	/*
 	 * +-------------------------------+----------+
 	 * | Value                         | SCTP     |
@@ -297,7 +335,7 @@ func (c *DataConsumer) Send(data []byte, ppid ...int) (err error) {
 		data = make([]byte, 1)
 	}
 
-	resp := c.payloadChannel.Request("dataConsumer.send", c.internal, H{"ppid": ppid}, data)
+	resp := c.payloadChannel.RequestContext(ctx, "dataConsumer.send", c.internal, H{"ppid": ppid}, data)
 
 	return resp.Err()
 }
@@ -306,22 +344,36 @@ func (c *DataConsumer) Send(data []byte, ppid ...int) (err error) {
  * Send text.
  */
 func (c *DataConsumer) SendText(message string) error {
+	return c.SendTextContext(context.Background(), message)
+}
+
+/**
+ * SendTextContext is SendText with a context cancelling the worker request.
+ */
+func (c *DataConsumer) SendTextContext(ctx context.Context, message string) error {
 	ppid := 51
 
 	if len(message) == 0 {
 		ppid = 56
 	}
 
-	return c.Send([]byte(message), ppid)
+	return c.SendContext(ctx, []byte(message), ppid)
 }
 
 /**
  * Get buffered amount size.
  */
 func (c *DataConsumer) GetBufferedAmount() (bufferedAmount int64, err error) {
+	return c.GetBufferedAmountContext(context.Background())
+}
+
+/**
+ * GetBufferedAmountContext is GetBufferedAmount with a context cancelling the worker request.
+ */
+func (c *DataConsumer) GetBufferedAmountContext(ctx context.Context) (bufferedAmount int64, err error) {
 	c.logger.Debug("getBufferedAmount()")
 
-	resp := c.channel.Request("dataConsumer.getBufferedAmount", c.internal)
+	resp := c.channel.RequestContext(ctx, "dataConsumer.getBufferedAmount", c.internal)
 
 	var result struct {
 		BufferAmount int64
@@ -1,6 +1,9 @@
 package mediasoup
 
-import "sync/atomic"
+import (
+	"context"
+	"sync/atomic"
+)
 
 type DataProducerOptions struct {
 	/**
@@ -24,6 +27,11 @@ type DataProducerOptions struct {
 	 */
 	Protocol string `json:"protocol,omitempty"`
 
+	/**
+	 * Base logger for the DataProducer. Default the one of the Transport.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -60,6 +68,7 @@ type dataProducerParams struct {
 	channel        *Channel
 	payloadChannel *PayloadChannel
 	appData        interface{}
+	loggerContext  loggerContext
 }
 
 type dataProducerData struct {
@@ -87,7 +96,7 @@ type DataProducer struct {
 }
 
 func newDataProducer(params dataProducerParams) *DataProducer {
-	logger := NewLogger("DataProducer")
+	logger := params.loggerContext.newLogger("DataProducer")
 
 	logger.Debug("constructor()")
 
@@ -103,7 +112,7 @@ func newDataProducer(params dataProducerParams) *DataProducer {
 		channel:        params.channel,
 		payloadChannel: params.payloadChannel,
 		appData:        params.appData,
-		observer:       NewEventEmitter(),
+		observer:       params.loggerContext.newObserver("dataproducer", params.internal.DataProducerId),
 	}
 
 	p.handleWorkerNotifications()
@@ -165,26 +174,29 @@ func (p *DataProducer) Observer() IEventEmitter {
 
 // Close the DataProducer.
 func (p *DataProducer) Close() (err error) {
-	if atomic.CompareAndSwapUint32(&p.closed, 0, 1) {
-		p.logger.Debug("close()")
-
-		// Remove notification subscriptions.
-		p.channel.RemoveAllListeners(p.Id())
-		p.payloadChannel.RemoveAllListeners(p.Id())
+	if !atomic.CompareAndSwapUint32(&p.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		response := p.channel.Request("dataProducer.close", p.internal)
+	p.logger.Debug("close()")
 
-		if err = response.Err(); err != nil {
-			p.logger.Error("dataProducer close error: %s", err)
-		}
+	// Remove notification subscriptions.
+	p.channel.RemoveAllListeners(p.Id())
+	p.payloadChannel.RemoveAllListeners(p.Id())
 
-		p.Emit("@close")
-		p.RemoveAllListeners()
+	response := p.channel.Request("dataProducer.close", p.internal)
 
-		// Emit observer event.
-		p.observer.SafeEmit("close")
-		p.observer.RemoveAllListeners()
+	if err = response.Err(); err != nil {
+		p.logger.Error("dataProducer close error: %s", err)
 	}
+
+	p.Emit("@close")
+	p.RemoveAllListeners()
+
+	// Emit observer event.
+	p.observer.SafeEmit("close")
+	p.observer.RemoveAllListeners()
+
 	return
 }
 
@@ -204,18 +216,32 @@ func (p *DataProducer) transportClosed() {
 
 // Dump DataConsumer.
 func (p *DataProducer) Dump() (dump DataProducerDump, err error) {
+	return p.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (p *DataProducer) DumpContext(ctx context.Context) (dump DataProducerDump, err error) {
 	p.logger.Debug("dump()")
 
-	resp := p.channel.Request("dataProducer.dump", p.internal)
+	resp := p.channel.RequestContext(ctx, "dataProducer.dump", p.internal)
 	err = resp.Unmarshal(&dump)
 	return
 }
 
 // Get DataConsumer stats.
 func (p *DataProducer) GetStats() (stats []*DataProducerStat, err error) {
+	return p.GetStatsContext(context.Background())
+}
+
+/**
+ * GetStatsContext is GetStats with a context cancelling the worker request.
+ */
+func (p *DataProducer) GetStatsContext(ctx context.Context) (stats []*DataProducerStat, err error) {
 	p.logger.Debug("getStats()")
 
-	resp := p.channel.Request("dataProducer.getStats", p.internal)
+	resp := p.channel.RequestContext(ctx, "dataProducer.getStats", p.internal)
 	err = resp.Unmarshal(&stats)
 
 	return
@@ -0,0 +1,318 @@
+// Package debughttp provides a read-only HTTP handler to inspect the
+// topology, dumps and stats of mediasoup workers and their entities:
+//
+//	handler := debughttp.NewHandler(debughttp.Options{
+//		Authorize: func(r *http.Request) bool { return checkToken(r) },
+//	})
+//	handler.AddWorker(worker)
+//	http.Handle("/debug/mediasoup/", http.StripPrefix("/debug/mediasoup", handler))
+//
+// Routes:
+//
+//	GET /topology          - tree of every entity
+//	GET /{type}/{id}/dump  - dump of the entity
+//	GET /{type}/{id}/stats - stats (resource usage for workers) of the entity
+//	GET /workers/{pid}/ipc - state of the communication with the worker
+//
+// where {type} is workers (id is the pid), routers, transports, producers,
+// consumers, dataproducers or dataconsumers.
+//
+// The state of the communication with the workers can also be published with
+// expvar:
+//
+//	expvar.Publish("mediasoup", handler.Expvar())
+package debughttp
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Function called with every request, which is rejected with 403 if it
+	 * returns false. Every request is accepted if nil, so the handler must not
+	 * be reachable from untrusted networks then.
+	 */
+	Authorize func(r *http.Request) bool
+}
+
+type entity struct {
+	typ      string
+	id       string
+	parentId string
+	info     func() map[string]interface{}
+	dump     func() (interface{}, error)
+	stats    func() (interface{}, error)
+	ipc      func() (interface{}, error)
+}
+
+/**
+ * Node of the topology tree.
+ */
+type TopologyNode struct {
+	Type     string                 `json:"type"`
+	Id       string                 `json:"id"`
+	Info     map[string]interface{} `json:"info,omitempty"`
+	Children []*TopologyNode        `json:"children,omitempty"`
+}
+
+/**
+ * Handler is a http.Handler serving the entities of the workers added to it.
+ */
+type Handler struct {
+	authorize func(r *http.Request) bool
+	locker    sync.RWMutex
+	// Entities by "type/id".
+	entities map[string]*entity
+}
+
+/**
+ * Create a Handler.
+ */
+func NewHandler(options Options) *Handler {
+	return &Handler{
+		authorize: options.Authorize,
+		entities:  make(map[string]*entity),
+	}
+}
+
+/**
+ * Track the given Worker and every entity created in it.
+ */
+func (h *Handler) AddWorker(worker *mediasoup.Worker) {
+	id := strconv.Itoa(worker.Pid())
+
+	h.add(worker.Observer(), &entity{
+		typ:   "workers",
+		id:    id,
+		dump:  func() (interface{}, error) { return worker.Dump() },
+		stats: func() (interface{}, error) { return worker.GetResourceUsage() },
+		ipc:   func() (interface{}, error) { return worker.IPCStats(), nil },
+	})
+
+	worker.Observer().On("newrouter", func(router *mediasoup.Router) {
+		h.addRouter(id, router)
+	})
+}
+
+func (h *Handler) addRouter(workerId string, router *mediasoup.Router) {
+	h.add(router.Observer(), &entity{
+		typ:      "routers",
+		id:       router.Id(),
+		parentId: "workers/" + workerId,
+		dump:     func() (interface{}, error) { return router.Dump() },
+	})
+
+	router.Observer().On("newtransport", func(transport mediasoup.ITransport) {
+		h.addTransport(router.Id(), transport)
+	})
+}
+
+func (h *Handler) addTransport(routerId string, transport mediasoup.ITransport) {
+	transportId := transport.Id()
+	parentId := "transports/" + transportId
+
+	h.add(transport.Observer(), &entity{
+		typ:      "transports",
+		id:       transportId,
+		parentId: "routers/" + routerId,
+		dump:     func() (interface{}, error) { return transport.Dump() },
+		stats:    func() (interface{}, error) { return transport.GetStats() },
+	})
+
+	transport.Observer().On("newproducer", func(producer *mediasoup.Producer) {
+		h.add(producer.Observer(), &entity{
+			typ:      "producers",
+			id:       producer.Id(),
+			parentId: parentId,
+			info: func() map[string]interface{} {
+				return map[string]interface{}{
+					"kind":   producer.Kind(),
+					"type":   producer.Type(),
+					"paused": producer.Paused(),
+				}
+			},
+			dump:  func() (interface{}, error) { return producer.Dump() },
+			stats: func() (interface{}, error) { return producer.GetStats() },
+		})
+	})
+	transport.Observer().On("newconsumer", func(consumer *mediasoup.Consumer) {
+		h.add(consumer.Observer(), &entity{
+			typ:      "consumers",
+			id:       consumer.Id(),
+			parentId: parentId,
+			info: func() map[string]interface{} {
+				return map[string]interface{}{
+					"kind":           consumer.Kind(),
+					"type":           consumer.Type(),
+					"producerId":     consumer.ProducerId(),
+					"paused":         consumer.Paused(),
+					"producerPaused": consumer.ProducerPaused(),
+				}
+			},
+			dump:  func() (interface{}, error) { return consumer.Dump() },
+			stats: func() (interface{}, error) { return consumer.GetStats() },
+		})
+	})
+	transport.Observer().On("newdataproducer", func(dataProducer *mediasoup.DataProducer) {
+		h.add(dataProducer.Observer(), &entity{
+			typ:      "dataproducers",
+			id:       dataProducer.Id(),
+			parentId: parentId,
+			info: func() map[string]interface{} {
+				return map[string]interface{}{
+					"type":  dataProducer.Type(),
+					"label": dataProducer.Label(),
+				}
+			},
+			dump:  func() (interface{}, error) { return dataProducer.Dump() },
+			stats: func() (interface{}, error) { return dataProducer.GetStats() },
+		})
+	})
+	transport.Observer().On("newdataconsumer", func(dataConsumer *mediasoup.DataConsumer) {
+		h.add(dataConsumer.Observer(), &entity{
+			typ:      "dataconsumers",
+			id:       dataConsumer.Id(),
+			parentId: parentId,
+			info: func() map[string]interface{} {
+				return map[string]interface{}{
+					"type":           dataConsumer.Type(),
+					"label":          dataConsumer.Label(),
+					"dataProducerId": dataConsumer.DataProducerId(),
+				}
+			},
+			dump:  func() (interface{}, error) { return dataConsumer.Dump() },
+			stats: func() (interface{}, error) { return dataConsumer.GetStats() },
+		})
+	})
+}
+
+func (h *Handler) add(observer mediasoup.IEventEmitter, e *entity) {
+	key := e.typ + "/" + e.id
+
+	h.locker.Lock()
+	h.entities[key] = e
+	h.locker.Unlock()
+
+	observer.Once("close", func() {
+		h.locker.Lock()
+		delete(h.entities, key)
+		h.locker.Unlock()
+	})
+}
+
+/**
+ * Snapshot of the tree of the entities, with a root node per worker.
+ */
+func (h *Handler) Topology() (workers []*TopologyNode) {
+	h.locker.RLock()
+	defer h.locker.RUnlock()
+
+	nodes := make(map[string]*TopologyNode, len(h.entities))
+
+	for key, e := range h.entities {
+		node := &TopologyNode{Type: e.typ, Id: e.id}
+
+		if e.info != nil {
+			node.Info = e.info()
+		}
+		nodes[key] = node
+	}
+
+	for key, e := range h.entities {
+		if len(e.parentId) == 0 {
+			workers = append(workers, nodes[key])
+		} else if parent, ok := nodes[e.parentId]; ok {
+			parent.Children = append(parent.Children, nodes[key])
+		}
+	}
+
+	return
+}
+
+/**
+ * Variable with the state of the communication (mediasoup.WorkerIPCStats) with
+ * every worker, by pid.
+ */
+func (h *Handler) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		h.locker.RLock()
+		defer h.locker.RUnlock()
+
+		workers := make(map[string]interface{})
+
+		for _, e := range h.entities {
+			if e.ipc != nil {
+				workers[e.id], _ = e.ipc()
+			}
+		}
+
+		return workers
+	})
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorize != nil && !h.authorize(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(parts) == 1 && parts[0] == "topology" {
+		writeJSON(w, h.Topology())
+		return
+	}
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.locker.RLock()
+	e, ok := h.entities[parts[0]+"/"+parts[1]]
+	h.locker.RUnlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var get func() (interface{}, error)
+
+	switch parts[2] {
+	case "dump":
+		get = e.dump
+	case "stats":
+		get = e.stats
+	case "ipc":
+		get = e.ipc
+	}
+	if get == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := get()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, data)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
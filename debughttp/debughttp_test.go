@@ -0,0 +1,87 @@
+package debughttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHandler(options Options) *Handler {
+	h := NewHandler(options)
+
+	for _, e := range []*entity{
+		{
+			typ: "workers",
+			id:  "10",
+			ipc: func() (interface{}, error) { return mediasoup.WorkerIPCStats{}, nil },
+		},
+		{typ: "routers", id: "r1", parentId: "workers/10"},
+		{
+			typ:      "transports",
+			id:       "t1",
+			parentId: "routers/r1",
+			dump:     func() (interface{}, error) { return map[string]string{"id": "t1"}, nil },
+			stats:    func() (interface{}, error) { return nil, errors.New("closed") },
+		},
+	} {
+		h.entities[e.typ+"/"+e.id] = e
+	}
+
+	return h
+}
+
+func TestTopology(t *testing.T) {
+	workers := newTestHandler(Options{}).Topology()
+
+	require.Len(t, workers, 1)
+	require.Len(t, workers[0].Children, 1)
+	assert.Equal(t, "r1", workers[0].Children[0].Id)
+	require.Len(t, workers[0].Children[0].Children, 1)
+	assert.Equal(t, "transports", workers[0].Children[0].Children[0].Type)
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := newTestHandler(Options{
+		Authorize: func(r *http.Request) bool { return r.Header.Get("Authorization") == "secret" },
+	})
+
+	get := func(method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Authorization", "secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get(http.MethodGet, "/transports/t1/dump")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var dump map[string]string
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dump))
+	assert.Equal(t, "t1", dump["id"])
+
+	assert.Equal(t, http.StatusInternalServerError, get(http.MethodGet, "/transports/t1/stats").Code)
+	assert.Equal(t, http.StatusNotFound, get(http.MethodGet, "/routers/r1/stats").Code)
+	assert.Equal(t, http.StatusOK, get(http.MethodGet, "/workers/10/ipc").Code)
+	assert.Equal(t, http.StatusNotFound, get(http.MethodGet, "/producers/p1/dump").Code)
+	assert.Equal(t, http.StatusOK, get(http.MethodGet, "/topology").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, get(http.MethodPost, "/topology").Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topology", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestExpvar(t *testing.T) {
+	var workers map[string]mediasoup.WorkerIPCStats
+
+	require.NoError(t, json.Unmarshal([]byte(newTestHandler(Options{}).Expvar().String()), &workers))
+	assert.Contains(t, workers, "10")
+}
@@ -1,5 +1,7 @@
 package mediasoup
 
+import "context"
+
 type DirectTransportOptions struct {
 	/**
 	 * Maximum allowed size for direct messages sent from DataProducers.
@@ -7,6 +9,12 @@ type DirectTransportOptions struct {
 	 */
 	MaxMessageSize uint32 `json:"maxMessageSize,omitempty"`
 
+	/**
+	 * Base logger for the transport and its Producers and Consumers. Default
+	 * the one of the Router.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -32,7 +40,7 @@ func newDirectTransport(params transportParams) ITransport {
 	params.data = transportData{
 		transportType: TransportType_Direct,
 	}
-	params.logger = NewLogger("DirectTransport")
+	params.logger = params.loggerContext.newLogger("DirectTransport")
 
 	transport := &DirectTransport{
 		ITransport:     newTransport(params),
@@ -71,6 +79,15 @@ func (transport *DirectTransport) Connect(TransportConnectOptions) error {
 	return nil
 }
 
+/**
+ * NO-OP method in DirectTransport.
+ *
+ * @override
+ */
+func (transport *DirectTransport) ConnectContext(context.Context, TransportConnectOptions) error {
+	return transport.Connect(TransportConnectOptions{})
+}
+
 /**
  * @override
  */
@@ -0,0 +1,200 @@
+package mediasoup
+
+import (
+	"context"
+	"sort"
+)
+
+/**
+ * Dump of a Worker and of every entity created in it, see Worker.DumpTree().
+ * The Error of an entity whose dump failed is set, its children being dumped
+ * anyway.
+ */
+type WorkerDumpTree struct {
+	Pid     int               `json:"pid"`
+	Dump    *WorkerDump       `json:"dump,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Routers []*RouterDumpTree `json:"routers"`
+}
+
+type RouterDumpTree struct {
+	Id         string               `json:"id"`
+	Dump       *RouterDump          `json:"dump,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Transports []*TransportDumpTree `json:"transports"`
+}
+
+type TransportDumpTree struct {
+	Id            string                  `json:"id"`
+	Dump          *TransportDump          `json:"dump,omitempty"`
+	Error         string                  `json:"error,omitempty"`
+	Producers     []*ProducerDumpTree     `json:"producers"`
+	Consumers     []*ConsumerDumpTree     `json:"consumers"`
+	DataProducers []*DataProducerDumpTree `json:"dataProducers"`
+	DataConsumers []*DataConsumerDumpTree `json:"dataConsumers"`
+}
+
+type ProducerDumpTree struct {
+	Id    string        `json:"id"`
+	Dump  *ProducerDump `json:"dump,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+type ConsumerDumpTree struct {
+	Id    string        `json:"id"`
+	Dump  *ConsumerDump `json:"dump,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+type DataProducerDumpTree struct {
+	Id    string            `json:"id"`
+	Dump  *DataProducerDump `json:"dump,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+type DataConsumerDumpTree struct {
+	Id    string            `json:"id"`
+	Dump  *DataConsumerDump `json:"dump,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+/**
+ * Dump the Worker and every Router, Transport, Producer, Consumer,
+ * DataProducer and DataConsumer created in it, sorted by id, into one
+ * document. It stops dumping once the context is done, the entities left being
+ * given with the context error.
+ */
+func (w *Worker) DumpTree(ctx context.Context) *WorkerDumpTree {
+	w.logger.Debug("dumpTree()")
+
+	tree := &WorkerDumpTree{Pid: w.pid, Routers: []*RouterDumpTree{}}
+
+	if dump, err := w.DumpContext(ctx); err != nil {
+		tree.Error = err.Error()
+	} else {
+		tree.Dump = &dump
+	}
+
+	routers := w.Routers()
+	sort.Slice(routers, func(i, j int) bool { return routers[i].Id() < routers[j].Id() })
+
+	for _, router := range routers {
+		tree.Routers = append(tree.Routers, router.dumpTree(ctx))
+	}
+
+	return tree
+}
+
+/**
+ * Dump the trees of the Workers (e.g. the ones of a WorkerPool)
+ * concurrently, in the order of the Workers.
+ */
+func DumpWorkers(ctx context.Context, workers ...*Worker) []*WorkerDumpTree {
+	trees := make([]*WorkerDumpTree, len(workers))
+	done := make(chan struct{})
+
+	for i, worker := range workers {
+		go func(i int, worker *Worker) {
+			trees[i] = worker.DumpTree(ctx)
+			done <- struct{}{}
+		}(i, worker)
+	}
+	for range workers {
+		<-done
+	}
+
+	return trees
+}
+
+func (router *Router) dumpTree(ctx context.Context) *RouterDumpTree {
+	tree := &RouterDumpTree{Id: router.Id(), Transports: []*TransportDumpTree{}}
+
+	if dump, err := router.DumpContext(ctx); err != nil {
+		tree.Error = err.Error()
+	} else {
+		tree.Dump = dump
+	}
+
+	transports := router.Transports()
+	sort.Slice(transports, func(i, j int) bool { return transports[i].Id() < transports[j].Id() })
+
+	for _, transport := range transports {
+		tree.Transports = append(tree.Transports, dumpTransportTree(ctx, transport))
+	}
+
+	return tree
+}
+
+func dumpTransportTree(ctx context.Context, transport ITransport) *TransportDumpTree {
+	tree := &TransportDumpTree{
+		Id:            transport.Id(),
+		Producers:     []*ProducerDumpTree{},
+		Consumers:     []*ConsumerDumpTree{},
+		DataProducers: []*DataProducerDumpTree{},
+		DataConsumers: []*DataConsumerDumpTree{},
+	}
+
+	if dump, err := transport.DumpContext(ctx); err != nil {
+		tree.Error = err.Error()
+	} else {
+		tree.Dump = dump
+	}
+
+	producers := transport.Producers()
+	sort.Slice(producers, func(i, j int) bool { return producers[i].Id() < producers[j].Id() })
+
+	for _, producer := range producers {
+		node := &ProducerDumpTree{Id: producer.Id()}
+
+		if dump, err := producer.DumpContext(ctx); err != nil {
+			node.Error = err.Error()
+		} else {
+			node.Dump = &dump
+		}
+		tree.Producers = append(tree.Producers, node)
+	}
+
+	consumers := transport.Consumers()
+	sort.Slice(consumers, func(i, j int) bool { return consumers[i].Id() < consumers[j].Id() })
+
+	for _, consumer := range consumers {
+		node := &ConsumerDumpTree{Id: consumer.Id()}
+
+		if dump, err := consumer.DumpContext(ctx); err != nil {
+			node.Error = err.Error()
+		} else {
+			node.Dump = dump
+		}
+		tree.Consumers = append(tree.Consumers, node)
+	}
+
+	dataProducers := transport.DataProducers()
+	sort.Slice(dataProducers, func(i, j int) bool { return dataProducers[i].Id() < dataProducers[j].Id() })
+
+	for _, dataProducer := range dataProducers {
+		node := &DataProducerDumpTree{Id: dataProducer.Id()}
+
+		if dump, err := dataProducer.DumpContext(ctx); err != nil {
+			node.Error = err.Error()
+		} else {
+			node.Dump = &dump
+		}
+		tree.DataProducers = append(tree.DataProducers, node)
+	}
+
+	dataConsumers := transport.DataConsumers()
+	sort.Slice(dataConsumers, func(i, j int) bool { return dataConsumers[i].Id() < dataConsumers[j].Id() })
+
+	for _, dataConsumer := range dataConsumers {
+		node := &DataConsumerDumpTree{Id: dataConsumer.Id()}
+
+		if dump, err := dataConsumer.DumpContext(ctx); err != nil {
+			node.Error = err.Error()
+		} else {
+			node.Dump = &dump
+		}
+		tree.DataConsumers = append(tree.DataConsumers, node)
+	}
+
+	return tree
+}
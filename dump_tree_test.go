@@ -0,0 +1,65 @@
+package mediasoup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_DumpTree(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	transport, err := router.CreateDirectTransport()
+	require.NoError(t, err)
+
+	mock.Respond("transport.produce", H{"type": "simple"})
+
+	producer, err := transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+
+	mock.Respond("worker.dump", WorkerDump{Pid: mock.Pid(), RouterIds: []string{router.Id()}})
+	mock.Respond("router.dump", RouterDump{Id: router.Id()})
+	mock.Respond("producer.dump", ProducerDump{Id: producer.Id(), Kind: "audio"})
+	mock.Handle("transport.dump", func(MockRequest) (interface{}, error) {
+		return nil, NewInvalidStateError("not now")
+	})
+
+	tree := mock.DumpTree(context.Background())
+
+	assert.Equal(t, mock.Pid(), tree.Pid)
+	require.NotNil(t, tree.Dump)
+	assert.Equal(t, []string{router.Id()}, tree.Dump.RouterIds)
+	require.Len(t, tree.Routers, 1)
+	assert.Equal(t, router.Id(), tree.Routers[0].Id)
+	assert.NotNil(t, tree.Routers[0].Dump)
+
+	require.Len(t, tree.Routers[0].Transports, 1)
+	transportTree := tree.Routers[0].Transports[0]
+	assert.Equal(t, transport.Id(), transportTree.Id)
+	assert.Nil(t, transportTree.Dump)
+	assert.Contains(t, transportTree.Error, "not now")
+
+	require.Len(t, transportTree.Producers, 1)
+	assert.Equal(t, producer.Id(), transportTree.Producers[0].Id)
+	assert.Equal(t, "audio", transportTree.Producers[0].Dump.Kind)
+	assert.Empty(t, transportTree.Consumers)
+
+	trees := DumpWorkers(context.Background(), mock.Worker, mock.Worker)
+	require.Len(t, trees, 2)
+	assert.Equal(t, tree, trees[1])
+}
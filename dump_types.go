@@ -8,6 +8,8 @@ const (
 type WorkerDump struct {
 	Pid       int      `json:"pid,omitempty"`
 	RouterIds []string `json:"routerIds,omitempty"`
+	// Reported by recent workers only.
+	Version string `json:"version,omitempty"`
 }
 
 type RouterDump struct {
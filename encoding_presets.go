@@ -0,0 +1,86 @@
+package mediasoup
+
+import "strings"
+
+type EncodingPresetName string
+
+const (
+	// Three layers (180p, 360p and 720p) for a 720p camera.
+	EncodingPreset_Camera EncodingPresetName = "camera"
+
+	// Three layers (270p, 540p and 1080p) for a 1080p camera.
+	EncodingPreset_CameraHD EncodingPresetName = "camera-hd"
+
+	// Two full resolution layers with different bitrates for screen sharing.
+	EncodingPreset_Screenshare EncodingPresetName = "screenshare"
+)
+
+type encodingPreset struct {
+	// Simulcast layers, from the lowest to the highest.
+	layers []RtpEncodingParameters
+	// Scalability mode of the single encoding used with SVC codecs.
+	svcScalabilityMode string
+}
+
+var encodingPresets = map[EncodingPresetName]encodingPreset{
+	EncodingPreset_Camera: {
+		layers: []RtpEncodingParameters{
+			{ScaleResolutionDownBy: 4, MaxBitrate: 200000, ScalabilityMode: "L1T3"},
+			{ScaleResolutionDownBy: 2, MaxBitrate: 700000, ScalabilityMode: "L1T3"},
+			{ScaleResolutionDownBy: 1, MaxBitrate: 2500000, ScalabilityMode: "L1T3"},
+		},
+		svcScalabilityMode: "L3T3_KEY",
+	},
+	EncodingPreset_CameraHD: {
+		layers: []RtpEncodingParameters{
+			{ScaleResolutionDownBy: 4, MaxBitrate: 300000, ScalabilityMode: "L1T3"},
+			{ScaleResolutionDownBy: 2, MaxBitrate: 1200000, ScalabilityMode: "L1T3"},
+			{ScaleResolutionDownBy: 1, MaxBitrate: 4000000, ScalabilityMode: "L1T3"},
+		},
+		svcScalabilityMode: "L3T3_KEY",
+	},
+	EncodingPreset_Screenshare: {
+		// Static content is usual when sharing the screen, so enable DTX to
+		// disable the RTP inactivity checks.
+		layers: []RtpEncodingParameters{
+			{ScaleResolutionDownBy: 1, MaxBitrate: 500000, ScalabilityMode: "L1T3", Dtx: true},
+			{ScaleResolutionDownBy: 1, MaxBitrate: 1500000, ScalabilityMode: "L1T3", Dtx: true},
+		},
+		svcScalabilityMode: "L1T3",
+	},
+}
+
+/**
+ * Get the encodings of the given preset for sending the given video codec.
+ * Simulcast codecs (VP8, H264, H265) get one encoding per layer while SVC
+ * codecs (VP9, AV1) get a single encoding with all the layers, limited to the
+ * maxBitrate of the highest one. Neither ssrc nor rid is set.
+ */
+func GetEncodingPreset(name EncodingPresetName, mimeType string) (encodings []RtpEncodingParameters, err error) {
+	preset, ok := encodingPresets[name]
+	if !ok {
+		err = NewTypeError("unknown encoding preset %q", name)
+		return
+	}
+
+	mimeType = strings.ToLower(mimeType)
+
+	switch mimeType {
+	case "video/vp8", "video/h264", "video/h265":
+		encodings = append(encodings, preset.layers...)
+
+	case "video/vp9", "video/av1":
+		highest := preset.layers[len(preset.layers)-1]
+
+		encodings = append(encodings, RtpEncodingParameters{
+			ScalabilityMode: preset.svcScalabilityMode,
+			MaxBitrate:      highest.MaxBitrate,
+			Dtx:             highest.Dtx,
+		})
+
+	default:
+		err = NewUnsupportedError("no encoding presets for %s", mimeType)
+	}
+
+	return
+}
@@ -0,0 +1,46 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEncodingPreset_Simulcast(t *testing.T) {
+	encodings, err := GetEncodingPreset(EncodingPreset_Camera, "video/VP8")
+	require.NoError(t, err)
+	require.Len(t, encodings, 3)
+
+	assert.Equal(t, 4, encodings[0].ScaleResolutionDownBy)
+	assert.Equal(t, 1, encodings[2].ScaleResolutionDownBy)
+	assert.Equal(t, "L1T3", encodings[0].ScalabilityMode)
+
+	// Returned encodings can be modified safely.
+	encodings[0].Rid = "r0"
+
+	encodings, _ = GetEncodingPreset(EncodingPreset_Camera, "video/H264")
+	assert.Empty(t, encodings[0].Rid)
+
+	encodings, err = GetEncodingPreset(EncodingPreset_Screenshare, "video/VP8")
+	require.NoError(t, err)
+	require.Len(t, encodings, 2)
+	assert.True(t, encodings[0].Dtx)
+}
+
+func TestGetEncodingPreset_Svc(t *testing.T) {
+	encodings, err := GetEncodingPreset(EncodingPreset_CameraHD, "video/VP9")
+	require.NoError(t, err)
+
+	assert.Equal(t, []RtpEncodingParameters{
+		{ScalabilityMode: "L3T3_KEY", MaxBitrate: 4000000},
+	}, encodings)
+}
+
+func TestGetEncodingPreset_Invalid(t *testing.T) {
+	_, err := GetEncodingPreset("foo", "video/VP8")
+	assert.IsType(t, NewTypeError(""), err)
+
+	_, err = GetEncodingPreset(EncodingPreset_Camera, "audio/opus")
+	assert.IsType(t, NewUnsupportedError(""), err)
+}
@@ -0,0 +1,279 @@
+package mediasoup
+
+/**
+ * RouterOption customizes the RouterOptions given to Worker.CreateRouter.
+ */
+type RouterOption interface {
+	applyRouter(o *RouterOptions)
+}
+
+/**
+ * WebRtcTransportOption customizes the WebRtcTransportOptions given to
+ * Router.CreateWebRtcTransport.
+ */
+type WebRtcTransportOption interface {
+	applyWebRtcTransport(o *WebRtcTransportOptions)
+}
+
+/**
+ * PlainTransportOption customizes the PlainTransportOptions given to
+ * Router.CreatePlainTransport.
+ */
+type PlainTransportOption interface {
+	applyPlainTransport(o *PlainTransportOptions)
+}
+
+/**
+ * PipeTransportOption customizes the PipeTransportOptions given to
+ * Router.CreatePipeTransport.
+ */
+type PipeTransportOption interface {
+	applyPipeTransport(o *PipeTransportOptions)
+}
+
+/**
+ * ProducerOption customizes the ProducerOptions given to Transport.Produce.
+ */
+type ProducerOption interface {
+	applyProducer(o *ProducerOptions)
+}
+
+/**
+ * ConsumerOption customizes the ConsumerOptions given to Transport.Consume.
+ */
+type ConsumerOption interface {
+	applyConsumer(o *ConsumerOptions)
+}
+
+/**
+ * DataProducerOption customizes the DataProducerOptions given to
+ * Transport.ProduceData.
+ */
+type DataProducerOption interface {
+	applyDataProducer(o *DataProducerOptions)
+}
+
+/**
+ * DataConsumerOption customizes the DataConsumerOptions given to
+ * Transport.ConsumeData.
+ */
+type DataConsumerOption interface {
+	applyDataConsumer(o *DataConsumerOptions)
+}
+
+type appDataOption struct {
+	appData interface{}
+}
+
+/**
+ * Set the custom application data of a Router, WebRtcTransport, PlainTransport,
+ * PipeTransport, Producer, Consumer, DataProducer or DataConsumer.
+ */
+func WithAppData(appData interface{}) appDataOption {
+	return appDataOption{appData: appData}
+}
+
+func (opt appDataOption) applyRouter(o *RouterOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyWebRtcTransport(o *WebRtcTransportOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyPlainTransport(o *PlainTransportOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyPipeTransport(o *PipeTransportOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyProducer(o *ProducerOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyConsumer(o *ConsumerOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyDataProducer(o *DataProducerOptions) {
+	o.AppData = opt.appData
+}
+
+func (opt appDataOption) applyDataConsumer(o *DataConsumerOptions) {
+	o.AppData = opt.appData
+}
+
+type entityLoggerOption struct {
+	logger Logger
+}
+
+/**
+ * Set the base logger of a Router, WebRtcTransport, PlainTransport,
+ * PipeTransport, Producer, Consumer, DataProducer or DataConsumer, instead of
+ * the one of its parent. WithLogger() sets the one of a Worker.
+ */
+func WithEntityLogger(logger Logger) entityLoggerOption {
+	return entityLoggerOption{logger: logger}
+}
+
+func (opt entityLoggerOption) applyRouter(o *RouterOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyWebRtcTransport(o *WebRtcTransportOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyPlainTransport(o *PlainTransportOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyPipeTransport(o *PipeTransportOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyProducer(o *ProducerOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyConsumer(o *ConsumerOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyDataProducer(o *DataProducerOptions) {
+	o.Logger = opt.logger
+}
+
+func (opt entityLoggerOption) applyDataConsumer(o *DataConsumerOptions) {
+	o.Logger = opt.logger
+}
+
+type sctpOption struct {
+	numSctpStreams NumSctpStreams
+}
+
+/**
+ * Enable SCTP on a WebRtcTransport, PlainTransport or PipeTransport, with the
+ * given number of streams.
+ */
+func WithSctp(numSctpStreams NumSctpStreams) sctpOption {
+	return sctpOption{numSctpStreams: numSctpStreams}
+}
+
+func (opt sctpOption) applyWebRtcTransport(o *WebRtcTransportOptions) {
+	o.EnableSctp = true
+	o.NumSctpStreams = opt.numSctpStreams
+}
+
+func (opt sctpOption) applyPlainTransport(o *PlainTransportOptions) {
+	o.EnableSctp = true
+	o.NumSctpStreams = opt.numSctpStreams
+}
+
+func (opt sctpOption) applyPipeTransport(o *PipeTransportOptions) {
+	o.EnableSctp = true
+	o.NumSctpStreams = opt.numSctpStreams
+}
+
+type pausedOption bool
+
+/**
+ * Start a Producer or a Consumer in paused mode, or not.
+ */
+func WithPaused(paused bool) pausedOption {
+	return pausedOption(paused)
+}
+
+func (opt pausedOption) applyProducer(o *ProducerOptions) {
+	o.Paused = bool(opt)
+}
+
+func (opt pausedOption) applyConsumer(o *ConsumerOptions) {
+	o.Paused = bool(opt)
+}
+
+type traceEventsOption []string
+
+/**
+ * Enable the given trace events (e.g. "keyframe", "pli") on a Producer or a
+ * Consumer as soon as it is created.
+ */
+func WithTraceEvents(types ...string) traceEventsOption {
+	return traceEventsOption(types)
+}
+
+func (opt traceEventsOption) applyProducer(o *ProducerOptions) {
+	for _, typ := range opt {
+		o.TraceEventTypes = append(o.TraceEventTypes, ProducerTraceEventType(typ))
+	}
+}
+
+func (opt traceEventsOption) applyConsumer(o *ConsumerOptions) {
+	for _, typ := range opt {
+		o.TraceEventTypes = append(o.TraceEventTypes, ConsumerTraceEventType(typ))
+	}
+}
+
+type routerOptionFunc func(o *RouterOptions)
+
+func (f routerOptionFunc) applyRouter(o *RouterOptions) {
+	f(o)
+}
+
+/**
+ * Set the media codecs of a Router.
+ */
+func WithMediaCodecs(mediaCodecs ...*RtpCodecCapability) RouterOption {
+	return routerOptionFunc(func(o *RouterOptions) {
+		o.MediaCodecs = mediaCodecs
+	})
+}
+
+type webRtcTransportOptionFunc func(o *WebRtcTransportOptions)
+
+func (f webRtcTransportOptionFunc) applyWebRtcTransport(o *WebRtcTransportOptions) {
+	f(o)
+}
+
+/**
+ * Set the listening IPs of a WebRtcTransport.
+ */
+func WithListenIps(listenIps ...TransportListenIp) WebRtcTransportOption {
+	return webRtcTransportOptionFunc(func(o *WebRtcTransportOptions) {
+		o.ListenIps = listenIps
+	})
+}
+
+type producerOptionFunc func(o *ProducerOptions)
+
+func (f producerOptionFunc) applyProducer(o *ProducerOptions) {
+	f(o)
+}
+
+/**
+ * Set the time (in ms) a video Producer waits before asking the sender for a
+ * new key frame after having asked a previous one.
+ */
+func WithKeyFrameRequestDelay(keyFrameRequestDelay uint32) ProducerOption {
+	return producerOptionFunc(func(o *ProducerOptions) {
+		o.KeyFrameRequestDelay = keyFrameRequestDelay
+	})
+}
+
+type consumerOptionFunc func(o *ConsumerOptions)
+
+func (f consumerOptionFunc) applyConsumer(o *ConsumerOptions) {
+	f(o)
+}
+
+/**
+ * Set the preferred spatial and temporal layers of a simulcast or SVC
+ * Consumer.
+ */
+func WithPreferredLayers(layers ConsumerLayers) ConsumerOption {
+	return consumerOptionFunc(func(o *ConsumerOptions) {
+		o.PreferredLayers = &layers
+	})
+}
@@ -0,0 +1,54 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityOptions_Logger(t *testing.T) {
+	logger := NewLogger("test")
+
+	var routerOptions RouterOptions
+	WithEntityLogger(logger).applyRouter(&routerOptions)
+	assert.Equal(t, logger, routerOptions.Logger)
+
+	var dataConsumerOptions DataConsumerOptions
+	WithEntityLogger(logger).applyDataConsumer(&dataConsumerOptions)
+	assert.Equal(t, logger, dataConsumerOptions.Logger)
+}
+
+func TestEntityOptions(t *testing.T) {
+	appData := H{"peerId": "alice"}
+
+	var routerOptions RouterOptions
+	for _, opt := range []RouterOption{WithAppData(appData), WithMediaCodecs(&RtpCodecCapability{MimeType: "audio/opus"})} {
+		opt.applyRouter(&routerOptions)
+	}
+	assert.Equal(t, appData, routerOptions.AppData)
+	assert.Len(t, routerOptions.MediaCodecs, 1)
+
+	var transportOptions WebRtcTransportOptions
+	for _, opt := range []WebRtcTransportOption{WithSctp(NumSctpStreams{OS: 16, MIS: 16}), WithListenIps(TransportListenIp{Ip: "127.0.0.1"})} {
+		opt.applyWebRtcTransport(&transportOptions)
+	}
+	assert.True(t, transportOptions.EnableSctp)
+	assert.Equal(t, NumSctpStreams{OS: 16, MIS: 16}, transportOptions.NumSctpStreams)
+	assert.Equal(t, []TransportListenIp{{Ip: "127.0.0.1"}}, transportOptions.ListenIps)
+
+	var producerOptions ProducerOptions
+	for _, opt := range []ProducerOption{WithPaused(true), WithTraceEvents("keyframe", "pli"), WithKeyFrameRequestDelay(1000)} {
+		opt.applyProducer(&producerOptions)
+	}
+	assert.True(t, producerOptions.Paused)
+	assert.Equal(t, []ProducerTraceEventType{"keyframe", "pli"}, producerOptions.TraceEventTypes)
+	assert.EqualValues(t, 1000, producerOptions.KeyFrameRequestDelay)
+
+	var consumerOptions ConsumerOptions
+	for _, opt := range []ConsumerOption{WithAppData(appData), WithPaused(true), WithPreferredLayers(ConsumerLayers{SpatialLayer: 2})} {
+		opt.applyConsumer(&consumerOptions)
+	}
+	assert.Equal(t, appData, consumerOptions.AppData)
+	assert.True(t, consumerOptions.Paused)
+	assert.Equal(t, &ConsumerLayers{SpatialLayer: 2}, consumerOptions.PreferredLayers)
+}
@@ -1,11 +1,53 @@
 package mediasoup
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
+/**
+ * Error returned by Close() of an entity which was already closed, either by a
+ * previous call or by the closure of its parent.
+ */
+var ErrAlreadyClosed = errors.New("already closed")
+
+/**
+ * Error returned by NewWorker() when the worker process did not report it is
+ * running within WorkerSettings.SpawnTimeout.
+ */
+var ErrSpawnTimeout = errors.New("worker process spawn timeout")
+
+/**
+ * Error returned by NewWorker() when the version of the worker process is not
+ * in the supported range, see MinWorkerVersion and MaxWorkerVersion.
+ */
+var ErrIncompatibleWorker = errors.New("incompatible worker version")
+
+/**
+ * Error of a Channel request whose response was not received in time, wrapped
+ * with its method and id.
+ */
+var ErrRequestTimeout = errors.New("Channel request timeout")
+
+/**
+ * Error wrapped by the one of the "died" event of a Worker, with the exit code
+ * or signal of the worker process, and returned by the requests pending then.
+ */
+var ErrWorkerDied = errors.New("worker process died")
+
+/**
+ * Error returned by NewWorker() when the worker process failed as the RTC port
+ * range is exhausted or already in use.
+ */
+var ErrPortRangeUnavailable = errors.New("rtc port range unavailable")
+
 type TypeError struct {
 	err error
+	// Method of the worker request which failed, if any.
+	Method string
+	// Id of the entity targeted by the worker request, if any.
+	EntityId string
 }
 
 func NewTypeError(format string, args ...interface{}) error {
@@ -18,10 +60,21 @@ func (e TypeError) Error() string {
 	return e.err.Error()
 }
 
+func (e TypeError) Unwrap() error {
+	return e.err
+}
+
+func (e TypeError) Is(target error) bool {
+	_, ok := target.(TypeError)
+	return ok
+}
+
 // UnsupportedError indicating not support for something.
 type UnsupportedError struct {
-	name    string
-	message string
+	name     string
+	message  string
+	Method   string
+	EntityId string
 }
 
 func NewUnsupportedError(format string, args ...interface{}) error {
@@ -35,14 +88,21 @@ func (e UnsupportedError) Error() string {
 	return fmt.Sprintf("%s:%s", e.name, e.message)
 }
 
+func (e UnsupportedError) Is(target error) bool {
+	_, ok := target.(UnsupportedError)
+	return ok
+}
+
 // InvalidStateError produced when calling a method in an invalid state.
 type InvalidStateError struct {
-	name    string
-	message string
+	name     string
+	message  string
+	Method   string
+	EntityId string
 }
 
 func NewInvalidStateError(format string, args ...interface{}) error {
-	return UnsupportedError{
+	return InvalidStateError{
 		name:    "InvalidStateError",
 		message: fmt.Sprintf(format, args...),
 	}
@@ -51,3 +111,59 @@ func NewInvalidStateError(format string, args ...interface{}) error {
 func (e InvalidStateError) Error() string {
 	return fmt.Sprintf("%s:%s", e.name, e.message)
 }
+
+func (e InvalidStateError) Is(target error) bool {
+	_, ok := target.(InvalidStateError)
+	return ok
+}
+
+// NotFoundError produced when the worker does not find the targeted entity.
+type NotFoundError struct {
+	name     string
+	message  string
+	Method   string
+	EntityId string
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return NotFoundError{
+		name:    "NotFoundError",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("%s:%s", e.name, e.message)
+}
+
+func (e NotFoundError) Is(target error) bool {
+	_, ok := target.(NotFoundError)
+	return ok
+}
+
+/**
+ * Map the error of a worker response, given by its name and reason, to the Go
+ * error type of the same kind, carrying the method of the request and the id
+ * of the entity it targeted. Errors of the same kind match with errors.Is
+ * (e.g. errors.Is(err, NotFoundError{})).
+ */
+func newWorkerError(name, reason, method string, internal interface{}) error {
+	entityId := ""
+
+	if data, ok := internal.(internalData); ok {
+		entityId = data.entityId(method)
+	}
+
+	switch {
+	case name == "TypeError":
+		return TypeError{err: errors.New(reason), Method: method, EntityId: entityId}
+	case name == "UnsupportedError":
+		return UnsupportedError{name: name, message: reason, Method: method, EntityId: entityId}
+	case name == "InvalidStateError":
+		return InvalidStateError{name: name, message: reason, Method: method, EntityId: entityId}
+	case strings.Contains(reason, "not found"):
+		return NotFoundError{name: "NotFoundError", message: reason, Method: method, EntityId: entityId}
+	default:
+		return errors.New(reason)
+	}
+}
@@ -0,0 +1,65 @@
+package mediasoup
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWorkerError(t *testing.T) {
+	internal := internalData{RouterId: "r1", TransportId: "t1", ProducerId: "p1"}
+
+	err := newWorkerError("TypeError", "missing kind", "transport.produce", internal)
+	require.IsType(t, TypeError{}, err)
+	assert.Equal(t, "missing kind", err.Error())
+	assert.Equal(t, "transport.produce", err.(TypeError).Method)
+	assert.Equal(t, "t1", err.(TypeError).EntityId)
+
+	err = newWorkerError("Error", "Producer not found", "producer.pause", internal)
+	require.IsType(t, NotFoundError{}, err)
+	assert.Equal(t, "p1", err.(NotFoundError).EntityId)
+
+	err = newWorkerError("Error", "Channel request handler not found", "worker.dump", nil)
+	require.IsType(t, NotFoundError{}, err)
+	assert.Empty(t, err.(NotFoundError).EntityId)
+
+	assert.IsType(t, InvalidStateError{}, newWorkerError("InvalidStateError", "closed", "router.dump", internal))
+	assert.IsType(t, UnsupportedError{}, newWorkerError("UnsupportedError", "no codec", "router.dump", internal))
+	assert.Equal(t, errors.New("failed"), newWorkerError("Error", "failed", "router.dump", internal))
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	err := fmt.Errorf("consume failed: %w", newWorkerError("Error", "Consumer not found", "consumer.dump", internalData{ConsumerId: "c1"}))
+
+	assert.True(t, errors.Is(err, NotFoundError{}))
+	assert.False(t, errors.Is(err, TypeError{}))
+
+	var notFound NotFoundError
+
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "c1", notFound.EntityId)
+	assert.Equal(t, "consumer.dump", notFound.Method)
+
+	assert.IsType(t, InvalidStateError{}, NewInvalidStateError("closed"))
+	assert.True(t, errors.Is(NewInvalidStateError("closed"), InvalidStateError{}))
+	assert.True(t, errors.Is(NewTypeError("bad"), TypeError{}))
+	assert.True(t, errors.Is(NewUnsupportedError("bad"), UnsupportedError{}))
+}
+
+func TestCloseAlreadyClosed(t *testing.T) {
+	for _, entity := range []interface{ Close() error }{
+		&Worker{closed: 1},
+		&Router{closed: 1},
+		&Transport{closed: 1},
+		&Producer{closed: 1},
+		&Consumer{closed: 1},
+		&DataProducer{closed: 1},
+		&DataConsumer{closed: 1},
+		&RtpObserver{closed: 1},
+	} {
+		assert.Equal(t, ErrAlreadyClosed, entity.Close())
+	}
+}
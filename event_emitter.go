@@ -1,11 +1,64 @@
 package mediasoup
 
 import (
+	"context"
+
 	"github.com/jiyeyuran/go-eventemitter"
 )
 
 type IEventEmitter = eventemitter.IEventEmitter
 
 func NewEventEmitter() IEventEmitter {
-	return eventemitter.NewEventEmitter(eventemitter.WithLogger(NewLogger("EventEmitter")))
+	return newEventEmitter(getConfig().EmitterDispatchMode)
+}
+
+// newAsyncEventEmitter creates an emitter dispatching its events
+// asynchronously whatever the configuration. Used by the Channels, whose
+// processor goroutine must not run the listeners: it would not handle the
+// responses of the requests they send.
+func newAsyncEventEmitter() IEventEmitter {
+	return newEventEmitter(EmitterDispatchMode_Async)
+}
+
+func newEventEmitter(mode EmitterDispatchMode) IEventEmitter {
+	config := getConfig()
+	logger := NewLogger("EventEmitter")
+
+	emitter := eventemitter.NewEventEmitter(
+		eventemitter.WithLogger(logger),
+		eventemitter.WithQueueSize(config.EmitterQueueSize),
+		eventemitter.WithMaxListeners(config.EmitterMaxListeners),
+	)
+	if mode == EmitterDispatchMode_Sync {
+		return syncEventEmitter{IEventEmitter: emitter, logger: logger}
+	}
+	return emitter
+}
+
+// syncEventEmitter calls the listeners of SafeEmit() from the emitting
+// goroutine, see EmitterDispatchMode_Sync.
+type syncEventEmitter struct {
+	IEventEmitter
+	logger Logger
 }
+
+func (e syncEventEmitter) SafeEmit(evt string, argv ...interface{}) (result eventemitter.AysncResult) {
+	result = emittedResult{}
+
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Error("SafeEmit() | event listener threw an error [event:%s]: %v", evt, r)
+		}
+	}()
+
+	e.Emit(evt, argv...)
+
+	return
+}
+
+// emittedResult is the result of an event whose listeners were all called.
+type emittedResult struct{}
+
+func (emittedResult) Wait() {}
+
+func (emittedResult) WaitCtx(context.Context) error { return nil }
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 
 	"github.com/jiyeyuran/mediasoup-go"
-	"github.com/jiyeyuran/mediasoup-go/h264"
 )
 
 var logger = mediasoup.NewLogger("ExampleApp")
@@ -24,11 +23,9 @@ var consumerDeviceCapabilities = mediasoup.RtpCapabilities{
 			PreferredPayloadType: 101,
 			ClockRate:            90000,
 			Parameters: mediasoup.RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					LevelAsymmetryAllowed: 1,
-					PacketizationMode:     1,
-					ProfileLevelId:        "4d0032",
-				},
+				{Key: "level-asymmetry-allowed", Value: 1},
+				{Key: "packetization-mode", Value: 1},
+				{Key: "profile-level-id", Value: "4d0032"},
 			},
 			RtcpFeedback: []mediasoup.RtcpFeedback{
 				{Type: "nack", Parameter: ""},
@@ -43,7 +40,7 @@ var consumerDeviceCapabilities = mediasoup.RtpCapabilities{
 			PreferredPayloadType: 102,
 			ClockRate:            90000,
 			Parameters: mediasoup.RtpCodecSpecificParameters{
-				Apt: 101,
+				{Key: "apt", Value: 101},
 			},
 		},
 	},
@@ -86,11 +83,9 @@ func main() {
 				MimeType:  "video/H264",
 				ClockRate: 90000,
 				Parameters: mediasoup.RtpCodecSpecificParameters{
-					RtpParameter: h264.RtpParameter{
-						LevelAsymmetryAllowed: 1,
-						PacketizationMode:     1,
-						ProfileLevelId:        "4d0032",
-					},
+					{Key: "level-asymmetry-allowed", Value: 1},
+					{Key: "packetization-mode", Value: 1},
+					{Key: "profile-level-id", Value: "4d0032"},
 				},
 			},
 		},
@@ -118,10 +113,8 @@ func main() {
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: mediasoup.RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "4d0032",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "4d0032"},
 					},
 					RtcpFeedback: []mediasoup.RtcpFeedback{
 						{Type: "nack", Parameter: ""},
@@ -133,7 +126,7 @@ func main() {
 					MimeType:    "video/rtx",
 					PayloadType: 113,
 					ClockRate:   90000,
-					Parameters:  mediasoup.RtpCodecSpecificParameters{Apt: 112},
+					Parameters:  mediasoup.RtpCodecSpecificParameters{{Key: "apt", Value: 112}},
 				},
 			},
 			HeaderExtensions: []mediasoup.RtpHeaderExtensionParameters{
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/loadtest"
+)
+
+var logger = mediasoup.NewLogger("LoadTestExample")
+
+func main() {
+	var options loadtest.Options
+	var kind, transport string
+	var jsonOutput bool
+
+	flag.IntVar(&options.Workers, "workers", 1, "number of workers")
+	flag.IntVar(&options.RoutersPerWorker, "routers", 1, "routers per worker")
+	flag.IntVar(&options.ProducersPerRouter, "producers", 1, "producers per router")
+	flag.StringVar(&kind, "kind", "video", "kind of the producers (audio or video)")
+	flag.IntVar(&options.ConsumersPerProducer, "consumers", 10, "consumers per producer")
+	flag.StringVar(&transport, "transport", "plain", "transport of the consumers (plain or pipe)")
+	flag.IntVar(&options.ConsumersPerTransport, "consumers-per-transport", 100, "consumers per transport")
+	flag.DurationVar(&options.Duration, "duration", 30*time.Second, "duration of the test")
+	flag.BoolVar(&jsonOutput, "json", false, "print the report as JSON")
+	flag.Parse()
+
+	options.ProducerKind = mediasoup.MediaKind(kind)
+	options.ConsumerTransport = loadtest.TransportType(transport)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	report, err := loadtest.Run(ctx, options)
+	if err != nil {
+		logger.Error("load test failed: %v", err)
+	}
+	if report == nil {
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		report.Print(os.Stdout)
+	}
+}
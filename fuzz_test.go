@@ -0,0 +1,88 @@
+//go:build go1.18
+// +build go1.18
+
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func FuzzChannelPayload(f *testing.F) {
+	f.Add([]byte(`{"id":1,"accepted":true,"data":{}}`))
+	f.Add([]byte(`{"id":2,"error":"Error","reason":"Producer not found"}`))
+	f.Add([]byte(`{"targetId":"1234","event":"score","data":[{"score":10}]}`))
+	f.Add([]byte(`D(debug) RTC::Transport::Connect() | connected`))
+	f.Add([]byte(`W`))
+	f.Add([]byte{})
+
+	channel := &Channel{
+		IEventEmitter: NewEventEmitter(),
+		logger:        NewLogger("Channel"),
+		codec:         jsonChannelCodec{},
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		// Worker stdout, printed as is.
+		if len(payload) > 0 && payload[0] == 'X' {
+			return
+		}
+		channel.processNSPayload(payload)
+	})
+}
+
+func FuzzRtpCodecSpecificParameters(f *testing.F) {
+	f.Add([]byte(`{"profile-level-id":"42e01f","packetization-mode":1,"apt":96}`))
+	f.Add([]byte(`{"useinbandfec":"1","spatial-layers":2}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var params RtpCodecSpecificParameters
+		params.UnmarshalJSON(data)
+	})
+}
+
+func FuzzRtpParameters(f *testing.F) {
+	f.Add([]byte(`{"codecs":[{"mimeType":"audio/opus","payloadType":111,"clockRate":48000,"channels":2}],"encodings":[{"ssrc":1111}]}`))
+	f.Add([]byte(`{"codecs":[null],"encodings":[{}]}`))
+	f.Add([]byte(`{"codecs":[{"mimeType":"video/VP8","payloadType":96,"clockRate":90000,"rtcpFeedback":[null]}],"encodings":[{"rid":"r0"},{"rid":"r1"}]}`))
+
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var params RtpParameters
+
+		if json.Unmarshal(data, &params) != nil || ValidateRtpParameters(&params) != nil {
+			return
+		}
+
+		mapping, err := GetProducerRtpParametersMapping(params, caps)
+		if err != nil {
+			return
+		}
+
+		consumableParams, err := GetConsumableRtpParameters("video", params, caps, mapping)
+		if err != nil {
+			return
+		}
+
+		GetConsumerRtpParameters(consumableParams, caps, false)
+		GetPipeConsumerRtpParameters(consumableParams, true)
+	})
+}
+
+func FuzzParseWorkerLog(f *testing.F) {
+	f.Add("RTC::Transport::Connect() | connected")
+	f.Add("(debug) [rtp] RTC::Producer::ReceiveRtpPacket() | packet received")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, line string) {
+		parseWorkerLog(1234, WorkerLogLevel_Warn, line)
+	})
+}
@@ -296,7 +296,7 @@ func GenerateProfileLevelIdForAnswer(
 			remoteOfferedParams.LevelAsymmetryAllowed > 0
 	localLevel := localProfileLevelId.Level
 	remoteLevel := remoteProfileLevelId.Level
-	minLevel := minLevel(localLevel, remoteLevel)
+	minLevel := MinLevel(localLevel, remoteLevel)
 
 	// Determine answer level. When level asymmetry is not allowed, level upgrade
 	// is not allowed, i.e., the level in the answer must be equal to or lower
@@ -315,6 +315,40 @@ func GenerateProfileLevelIdForAnswer(
 	return profileLevelId.String(), nil
 }
 
+/**
+ * Whether the given H264 parameters match, so a codec with the local supported
+ * params can be negotiated with a codec with the remote offered params.
+ * packetization-mode must be equal and, if strict, profiles must be equal too.
+ *
+ * @returns The profile-level-id to use in the answer (see
+ *   GenerateProfileLevelIdForAnswer()) if strict, or the profile-level-id of
+ *   localSupportedParams otherwise.
+ */
+func MatchParameters(
+	localSupportedParams,
+	remoteOfferedParams RtpParameter,
+	strict bool,
+) (profileLevelId string, matched bool) {
+	if localSupportedParams.PacketizationMode != remoteOfferedParams.PacketizationMode {
+		return
+	}
+
+	if !strict {
+		return localSupportedParams.ProfileLevelId, true
+	}
+
+	if !IsSameProfile(localSupportedParams.ProfileLevelId, remoteOfferedParams.ProfileLevelId) {
+		return
+	}
+
+	profileLevelId, err := GenerateProfileLevelIdForAnswer(localSupportedParams, remoteOfferedParams)
+	if err != nil {
+		return
+	}
+
+	return profileLevelId, true
+}
+
 // Convert a string of 8 characters into a byte where the positions containing
 // character c will have their bit set. For example, c = "x", str = "x1xx0000"
 // will return 0b10110000.
@@ -330,21 +364,27 @@ func byteMaskString(c byte, str string) (mask byte) {
 	return
 }
 
-// Compare H264 levels and handle the level 1b case.
-func isLessLevel(a, b byte) bool {
+/**
+ * Compare H264 levels and handle the level 1b case. Returns true if level a is
+ * lower than level b.
+ */
+func IsLessLevel(a, b byte) bool {
 	if a == Level1_b {
 		return b != Level1 && b != Level1_b
 	}
 
 	if b == Level1_b {
-		return a != Level1
+		return a == Level1
 	}
 
 	return a < b
 }
 
-func minLevel(a, b byte) byte {
-	if isLessLevel(a, b) {
+/**
+ * Returns the lowest of the given H264 levels.
+ */
+func MinLevel(a, b byte) byte {
+	if IsLessLevel(a, b) {
 		return a
 	}
 	return b
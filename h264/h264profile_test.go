@@ -146,6 +146,39 @@ func TestGenerateProfileLevelIdForAnswerConstrainedBaselineLevelAsymmetry(t *tes
 	assert.Equal(t, answer, "42e01f")
 }
 
+func TestIsLessLevel(t *testing.T) {
+	assert.True(t, IsLessLevel(Level1_b, Level1_1))
+	assert.True(t, IsLessLevel(Level1, Level1_b))
+	assert.False(t, IsLessLevel(Level1_b, Level1))
+	assert.False(t, IsLessLevel(Level1_1, Level1_b))
+	assert.True(t, IsLessLevel(Level3_1, Level4))
+	assert.False(t, IsLessLevel(Level3_1, Level3_1))
+	assert.EqualValues(t, Level1_b, MinLevel(Level1_b, Level2))
+	assert.EqualValues(t, Level1, MinLevel(Level1_b, Level1))
+}
+
+func TestMatchParameters(t *testing.T) {
+	local := RtpParameter{PacketizationMode: 1, ProfileLevelId: "42e01f", LevelAsymmetryAllowed: 1}
+
+	profileLevelId, ok := MatchParameters(local, RtpParameter{PacketizationMode: 1, ProfileLevelId: "42e015"}, true)
+	assert.True(t, ok)
+	assert.Equal(t, "42e015", profileLevelId)
+
+	profileLevelId, ok = MatchParameters(local, RtpParameter{PacketizationMode: 1, ProfileLevelId: "42e015", LevelAsymmetryAllowed: 1}, true)
+	assert.True(t, ok)
+	assert.Equal(t, "42e01f", profileLevelId)
+
+	_, ok = MatchParameters(local, RtpParameter{PacketizationMode: 0, ProfileLevelId: "42e01f"}, true)
+	assert.False(t, ok)
+
+	_, ok = MatchParameters(local, RtpParameter{PacketizationMode: 1, ProfileLevelId: "640c1f"}, true)
+	assert.False(t, ok)
+
+	profileLevelId, ok = MatchParameters(local, RtpParameter{PacketizationMode: 1, ProfileLevelId: "640c1f"}, false)
+	assert.True(t, ok)
+	assert.Equal(t, "42e01f", profileLevelId)
+}
+
 func Test_byteMaskString(t *testing.T) {
 	type args struct {
 		c   byte
@@ -0,0 +1,210 @@
+package h265
+
+import (
+	"errors"
+	"strconv"
+)
+
+const (
+	ProfileMain              byte = 1
+	ProfileMain10            byte = 2
+	ProfileMainStillPicture  byte = 3
+	ProfileRangeExtensions   byte = 4
+	ProfileHighThroughput    byte = 5
+	ProfileMultiviewMain     byte = 6
+	ProfileScalableMain      byte = 7
+	Profile3dMain            byte = 8
+	ProfileScreenContent     byte = 9
+	ProfileScalableRangeExt  byte = 10
+	ProfileHighThroughputSCC byte = 11
+
+	TierMain byte = 0
+	TierHigh byte = 1
+
+	// All values are equal to 30 times the level number.
+	Level1   byte = 30
+	Level2   byte = 60
+	Level2_1 byte = 63
+	Level3   byte = 90
+	Level3_1 byte = 93
+	Level4   byte = 120
+	Level4_1 byte = 123
+	Level5   byte = 150
+	Level5_1 byte = 153
+	Level5_2 byte = 156
+	Level6   byte = 180
+	Level6_1 byte = 183
+	Level6_2 byte = 186
+
+	// Transmission modes.
+	TxModeSRST = "SRST"
+	TxModeMRST = "MRST"
+	TxModeMRMT = "MRMT"
+)
+
+/**
+ * H265 specific parameters: the tier-flag, level-id and tx-mode values of the
+ * codec parameters, zero if missing. profile-id is shared with VP9 and thus
+ * given apart, as a decimal string.
+ */
+type CodecParameters struct {
+	TierFlag int
+	LevelId  int
+	TxMode   string
+}
+
+type ProfileTierLevel struct {
+	Profile byte
+	Tier    byte
+	Level   byte
+}
+
+// Default values according to https://tools.ietf.org/html/rfc7798#section-7.1.
+var DefaultProfileTierLevel = ProfileTierLevel{
+	Profile: ProfileMain,
+	Tier:    TierMain,
+	Level:   Level3_1,
+}
+
+/**
+ * Parse the profile-id, tier-flag and level-id parameters, using the default
+ * value of any missing one. Nothing will be returned if any of them is not a
+ * recognized value.
+ *
+ * @param profileId - profile-id value as a decimal string.
+ */
+func ParseProfileTierLevel(profileId string, params CodecParameters) (profileTierLevel *ProfileTierLevel) {
+	result := DefaultProfileTierLevel
+
+	if len(profileId) > 0 {
+		profile, err := strconv.Atoi(profileId)
+		if err != nil || profile < int(ProfileMain) || profile > int(ProfileHighThroughputSCC) {
+			return
+		}
+		result.Profile = byte(profile)
+	}
+
+	switch params.TierFlag {
+	case int(TierMain), int(TierHigh):
+		result.Tier = byte(params.TierFlag)
+	default:
+		return
+	}
+
+	if params.LevelId != 0 {
+		switch params.LevelId {
+		case int(Level1), int(Level2), int(Level2_1), int(Level3), int(Level3_1),
+			int(Level4), int(Level4_1), int(Level5), int(Level5_1), int(Level5_2),
+			int(Level6), int(Level6_1), int(Level6_2):
+			result.Level = byte(params.LevelId)
+		default:
+			return
+		}
+	}
+
+	return &result
+}
+
+/**
+ * Returns the tx-mode of the given parameters, "SRST" if missing.
+ */
+func GetTxMode(params CodecParameters) string {
+	if len(params.TxMode) == 0 {
+		return TxModeSRST
+	}
+	return params.TxMode
+}
+
+/**
+ * Returns true if the parameters have the same H265 profile and tier.
+ */
+func IsSameProfileAndTier(
+	profileId1 string, params1 CodecParameters,
+	profileId2 string, params2 CodecParameters,
+) bool {
+	profileTierLevel1 := ParseProfileTierLevel(profileId1, params1)
+	profileTierLevel2 := ParseProfileTierLevel(profileId2, params2)
+
+	return profileTierLevel1 != nil && profileTierLevel2 != nil &&
+		profileTierLevel1.Profile == profileTierLevel2.Profile &&
+		profileTierLevel1.Tier == profileTierLevel2.Tier
+}
+
+/**
+ * Generate the level-id that will be used as answer in an SDP negotiation
+ * based on local supported parameters and remote offered parameters. The
+ * profile and tier of both parameters must be equal. The answer level is the
+ * lowest of both levels.
+ *
+ * @returns The level-id, or 0 if no one of the params have level-id.
+ */
+func GenerateLevelIdForAnswer(
+	localProfileId string, localSupportedParams CodecParameters,
+	remoteProfileId string, remoteOfferedParams CodecParameters,
+) (levelId int, err error) {
+	if localSupportedParams.LevelId == 0 && remoteOfferedParams.LevelId == 0 {
+		return 0, nil
+	}
+
+	localProfileTierLevel := ParseProfileTierLevel(localProfileId, localSupportedParams)
+	remoteProfileTierLevel := ParseProfileTierLevel(remoteProfileId, remoteOfferedParams)
+
+	if localProfileTierLevel == nil {
+		err = errors.New("invalid local profile-tier-level")
+		return
+	}
+	if remoteProfileTierLevel == nil {
+		err = errors.New("invalid remote profile-tier-level")
+		return
+	}
+	if localProfileTierLevel.Profile != remoteProfileTierLevel.Profile {
+		err = errors.New("H265 Profile mismatch")
+		return
+	}
+	if localProfileTierLevel.Tier != remoteProfileTierLevel.Tier {
+		err = errors.New("H265 Tier mismatch")
+		return
+	}
+
+	levelId = int(localProfileTierLevel.Level)
+
+	if remoteProfileTierLevel.Level < localProfileTierLevel.Level {
+		levelId = int(remoteProfileTierLevel.Level)
+	}
+
+	return
+}
+
+/**
+ * Whether the given H265 parameters match, so a codec with the local supported
+ * params can be negotiated with a codec with the remote offered params.
+ * tx-mode must be equal and, if strict, profile and tier must be equal too.
+ *
+ * @returns The level-id to use in the answer (see GenerateLevelIdForAnswer())
+ *   if strict, or the level-id of localSupportedParams otherwise.
+ */
+func MatchParameters(
+	localProfileId string, localSupportedParams CodecParameters,
+	remoteProfileId string, remoteOfferedParams CodecParameters,
+	strict bool,
+) (levelId int, matched bool) {
+	if GetTxMode(localSupportedParams) != GetTxMode(remoteOfferedParams) {
+		return
+	}
+
+	if !strict {
+		return localSupportedParams.LevelId, true
+	}
+
+	if !IsSameProfileAndTier(localProfileId, localSupportedParams, remoteProfileId, remoteOfferedParams) {
+		return
+	}
+
+	levelId, err := GenerateLevelIdForAnswer(
+		localProfileId, localSupportedParams, remoteProfileId, remoteOfferedParams)
+	if err != nil {
+		return
+	}
+
+	return levelId, true
+}
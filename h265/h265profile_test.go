@@ -0,0 +1,68 @@
+package h265
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfileTierLevelDefault(t *testing.T) {
+	profileTierLevel := ParseProfileTierLevel("", CodecParameters{})
+
+	assert.Equal(t, &DefaultProfileTierLevel, profileTierLevel)
+}
+
+func TestParseProfileTierLevel(t *testing.T) {
+	profileTierLevel := ParseProfileTierLevel("2", CodecParameters{TierFlag: 1, LevelId: 120})
+
+	assert.Equal(t, &ProfileTierLevel{Profile: ProfileMain10, Tier: TierHigh, Level: Level4}, profileTierLevel)
+}
+
+func TestParseProfileTierLevelInvalid(t *testing.T) {
+	assert.Nil(t, ParseProfileTierLevel("0", CodecParameters{}))
+	assert.Nil(t, ParseProfileTierLevel("12", CodecParameters{}))
+	assert.Nil(t, ParseProfileTierLevel("main", CodecParameters{}))
+	assert.Nil(t, ParseProfileTierLevel("1", CodecParameters{TierFlag: 2}))
+	assert.Nil(t, ParseProfileTierLevel("1", CodecParameters{LevelId: 91}))
+}
+
+func TestIsSameProfileAndTier(t *testing.T) {
+	assert.True(t, IsSameProfileAndTier("", CodecParameters{}, "1", CodecParameters{LevelId: 120}))
+	assert.False(t, IsSameProfileAndTier("1", CodecParameters{}, "2", CodecParameters{}))
+	assert.False(t, IsSameProfileAndTier("1", CodecParameters{}, "1", CodecParameters{TierFlag: 1}))
+}
+
+func TestGenerateLevelIdForAnswer(t *testing.T) {
+	levelId, err := GenerateLevelIdForAnswer("", CodecParameters{}, "", CodecParameters{})
+	assert.NoError(t, err)
+	assert.Zero(t, levelId)
+
+	levelId, err = GenerateLevelIdForAnswer("1", CodecParameters{LevelId: 120}, "1", CodecParameters{LevelId: 90})
+	assert.NoError(t, err)
+	assert.EqualValues(t, Level3, levelId)
+
+	levelId, err = GenerateLevelIdForAnswer("1", CodecParameters{LevelId: 120}, "", CodecParameters{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, Level3_1, levelId)
+
+	_, err = GenerateLevelIdForAnswer("1", CodecParameters{LevelId: 120}, "2", CodecParameters{})
+	assert.Error(t, err)
+}
+
+func TestMatchParameters(t *testing.T) {
+	local := CodecParameters{LevelId: 120}
+
+	levelId, ok := MatchParameters("1", local, "1", CodecParameters{LevelId: 93, TxMode: "SRST"}, true)
+	assert.True(t, ok)
+	assert.EqualValues(t, Level3_1, levelId)
+
+	_, ok = MatchParameters("1", local, "1", CodecParameters{TxMode: "MRST"}, false)
+	assert.False(t, ok)
+
+	_, ok = MatchParameters("1", local, "2", CodecParameters{}, true)
+	assert.False(t, ok)
+
+	levelId, ok = MatchParameters("1", local, "2", CodecParameters{}, false)
+	assert.True(t, ok)
+	assert.EqualValues(t, Level4, levelId)
+}
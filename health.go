@@ -0,0 +1,267 @@
+package mediasoup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+/**
+ * Health of a Worker.
+ */
+type WorkerHealth struct {
+	Pid int `json:"pid"`
+
+	/**
+	 * Whether the worker process is alive and its channel answered a request
+	 * within the timeout.
+	 */
+	Alive bool `json:"alive"`
+
+	/**
+	 * Whether the Worker is alive, spawned and neither draining nor closed, so
+	 * new Routers can be created in it.
+	 */
+	Ready bool `json:"ready"`
+
+	/**
+	 * Time taken by the channel to answer, 0 if it did not.
+	 */
+	Latency time.Duration `json:"latency,omitempty"`
+
+	/**
+	 * Reason why the Worker is not alive, if any.
+	 */
+	Error string `json:"error,omitempty"`
+}
+
+/**
+ * Health of a set of Workers.
+ */
+type HealthReport struct {
+	/**
+	 * Whether every Worker is alive.
+	 */
+	Alive bool `json:"alive"`
+
+	/**
+	 * Whether at least one Worker is ready.
+	 */
+	Ready bool `json:"ready"`
+
+	Workers []WorkerHealth `json:"workers"`
+}
+
+/**
+ * Check the health of the Worker, waiting at most the given timeout for its
+ * channel to answer.
+ */
+func (w *Worker) HealthCheck(timeout time.Duration) (health WorkerHealth) {
+	health.Pid = w.pid
+
+	if w.Closed() {
+		health.Error = "worker closed"
+		return
+	}
+
+	// No process to probe for the workers not spawned by this library, e.g.
+	// the MockWorkers (negative pids).
+	if w.pid > 0 {
+		if process, err := os.FindProcess(w.pid); err != nil {
+			health.Error = err.Error()
+			return
+		} else if err := process.Signal(syscall.Signal(0)); err != nil {
+			health.Error = "worker process not running: " + err.Error()
+			return
+		}
+	}
+
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := w.Ping(ctx); err == context.DeadlineExceeded || errors.Is(err, ErrRequestTimeout) {
+		health.Error = "worker channel request timeout"
+		return
+	} else if err != nil {
+		health.Error = "worker channel request failed: " + err.Error()
+		return
+	}
+
+	health.Alive = true
+	health.Latency = time.Since(start)
+	health.Ready = atomic.LoadUint32(&w.spawnDone) > 0 && !w.Draining() && !w.Closed()
+
+	return
+}
+
+/**
+ * Check that the worker process answers a lightweight request through the
+ * channel before the context is done.
+ */
+func (w *Worker) Ping(ctx context.Context) error {
+	return w.channel.RequestContext(ctx, "worker.dump", nil).Err()
+}
+
+/**
+ * Ping the worker process at the given interval, waiting at most the given
+ * timeout for each answer, until the returned function is called or the
+ * Worker is closed. "unresponsive" is emitted on the Worker each time it does
+ * not answer, "responsive" when it answers again.
+ */
+func (w *Worker) StartLivenessCheck(interval, timeout time.Duration) (stop func()) {
+	w.logger.Debug("startLivenessCheck() [interval:%s, timeout:%s]", interval, timeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	goWithWorkerLabels(w.pid, "liveness-check", func() {
+		defer cancel()
+
+		w.checkLiveness(ctx, interval, timeout)
+	})
+
+	return cancel
+}
+
+func (w *Worker) checkLiveness(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-w.Done():
+			return
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := w.Ping(pingCtx)
+		cancel()
+
+		if ctx.Err() != nil || w.Closed() {
+			return
+		}
+
+		if err != nil {
+			failures++
+			w.logger.Warn("worker process unresponsive [pid:%d, failures:%d]: %v", w.pid, failures, err)
+			w.SafeEmit("unresponsive", err, failures)
+		} else if failures > 0 {
+			failures = 0
+			w.logger.Info("worker process responsive again [pid:%d]", w.pid)
+			w.SafeEmit("responsive")
+		}
+	}
+}
+
+/**
+ * Check the health of the given Workers concurrently, waiting at most the
+ * given timeout for each of them.
+ */
+func HealthCheck(workers []*Worker, timeout time.Duration) HealthReport {
+	healths := make([]WorkerHealth, len(workers))
+	wg := sync.WaitGroup{}
+
+	for i, worker := range workers {
+		wg.Add(1)
+
+		go func(i int, worker *Worker) {
+			defer wg.Done()
+			healths[i] = worker.HealthCheck(timeout)
+		}(i, worker)
+	}
+	wg.Wait()
+
+	return newHealthReport(healths)
+}
+
+func newHealthReport(healths []WorkerHealth) (report HealthReport) {
+	report.Alive = true
+	report.Workers = healths
+
+	for _, health := range healths {
+		report.Alive = report.Alive && health.Alive
+		report.Ready = report.Ready || health.Ready
+	}
+
+	return
+}
+
+type HealthHandlerOptions struct {
+	/**
+	 * Function returning the Workers to check.
+	 */
+	Workers func() []*Worker
+
+	/**
+	 * Timeout of the check of each Worker. Default 1 second.
+	 */
+	Timeout time.Duration
+}
+
+type healthHandler struct {
+	options HealthHandlerOptions
+}
+
+/**
+ * Create a http.Handler serving the liveness ("/livez") and readiness
+ * ("/readyz") probes of the Workers, answering 200 if the probe succeeds and
+ * 503 otherwise, with the HealthReport as JSON body.
+ */
+func NewHealthHandler(options HealthHandlerOptions) http.Handler {
+	if options.Timeout <= 0 {
+		options.Timeout = time.Second
+	}
+
+	return healthHandler{options: options}
+}
+
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var workers []*Worker
+
+	if h.options.Workers != nil {
+		workers = h.options.Workers()
+	}
+
+	var ok bool
+	var report HealthReport
+
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/livez":
+		report = HealthCheck(workers, h.options.Timeout)
+		ok = report.Alive
+	case "/readyz":
+		report = HealthCheck(workers, h.options.Timeout)
+		ok = report.Ready
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
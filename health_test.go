@@ -0,0 +1,130 @@
+package mediasoup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckMockWorker(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	health := mock.HealthCheck(time.Second)
+	assert.True(t, health.Alive)
+	assert.True(t, health.Ready)
+	assert.Empty(t, health.Error)
+
+	atomic.StoreUint32(&mock.draining, 1)
+
+	health = mock.HealthCheck(time.Second)
+	assert.True(t, health.Alive)
+	assert.False(t, health.Ready)
+}
+
+func TestHealthCheckClosedWorker(t *testing.T) {
+	worker := &Worker{pid: 1234, closed: 1}
+
+	health := worker.HealthCheck(time.Second)
+
+	assert.Equal(t, 1234, health.Pid)
+	assert.False(t, health.Alive)
+	assert.False(t, health.Ready)
+	assert.Equal(t, "worker closed", health.Error)
+}
+
+func TestNewHealthReport(t *testing.T) {
+	report := newHealthReport([]WorkerHealth{
+		{Pid: 1, Alive: true, Ready: true},
+		{Pid: 2},
+	})
+	assert.False(t, report.Alive)
+	assert.True(t, report.Ready)
+
+	report = newHealthReport([]WorkerHealth{{Pid: 1, Alive: true}})
+	assert.True(t, report.Alive)
+	assert.False(t, report.Ready)
+}
+
+func TestHealthHandler(t *testing.T) {
+	closedWorker := &Worker{pid: 1234, closed: 1}
+	handler := NewHealthHandler(HealthHandlerOptions{
+		Workers: func() []*Worker { return []*Worker{closedWorker} },
+	})
+
+	for path, code := range map[string]int{
+		"/livez":   http.StatusServiceUnavailable,
+		"/readyz":  http.StatusServiceUnavailable,
+		"/healthz": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, code, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Workers, 1)
+	assert.Equal(t, 1234, report.Workers[0].Pid)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/livez", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestWorker_StartLivenessCheck(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	require.NoError(t, mock.Ping(context.Background()))
+
+	block := make(chan struct{})
+	blocked := uint32(1)
+
+	mock.Handle("worker.dump", func(MockRequest) (interface{}, error) {
+		if atomic.LoadUint32(&blocked) > 0 {
+			<-block
+		}
+		return H{}, nil
+	})
+
+	unresponsive := make(chan int, 10)
+	responsive := make(chan struct{}, 1)
+
+	mock.On("unresponsive", func(err error, failures int) {
+		assert.Equal(t, context.DeadlineExceeded, err)
+		unresponsive <- failures
+	})
+	mock.On("responsive", func() { responsive <- struct{}{} })
+
+	stop := mock.StartLivenessCheck(20*time.Millisecond, 20*time.Millisecond)
+	defer stop()
+
+	select {
+	case failures := <-unresponsive:
+		assert.Equal(t, 1, failures)
+	case <-time.After(time.Second):
+		t.Fatal("unresponsive not emitted")
+	}
+
+	atomic.StoreUint32(&blocked, 0)
+	close(block)
+
+	select {
+	case <-responsive:
+	case <-time.After(time.Second):
+		t.Fatal("responsive not emitted")
+	}
+}
@@ -1,7 +1,5 @@
 package mediasoup
 
-import "github.com/jiyeyuran/mediasoup-go/h264"
-
 var consumerDeviceCapabilities = RtpCapabilities{
 	Codecs: []*RtpCodecCapability{
 		{
@@ -29,7 +27,7 @@ var consumerDeviceCapabilities = RtpCapabilities{
 			PreferredPayloadType: 102,
 			ClockRate:            90000,
 			Parameters: RtpCodecSpecificParameters{
-				Apt: 101,
+				{Key: "apt", Value: 101},
 			},
 			RtcpFeedback: []RtcpFeedback{},
 		},
@@ -81,11 +79,9 @@ func CreateRouter(workers ...*Worker) *Router {
 				MimeType:  "video/H264",
 				ClockRate: 90000,
 				Parameters: RtpCodecSpecificParameters{
-					RtpParameter: h264.RtpParameter{
-						LevelAsymmetryAllowed: 1,
-						PacketizationMode:     1,
-						ProfileLevelId:        "4d0032",
-					},
+					{Key: "level-asymmetry-allowed", Value: 1},
+					{Key: "packetization-mode", Value: 1},
+					{Key: "profile-level-id", Value: "4d0032"},
 				},
 			},
 		},
@@ -108,8 +104,8 @@ func CreateAudioProducer(tranpsort ITransport) *Producer {
 					ClockRate:   48000,
 					Channels:    2,
 					Parameters: RtpCodecSpecificParameters{
-						Useinbandfec: 1,
-						Usedtx:       1,
+						{Key: "useinbandfec", Value: 1},
+						{Key: "usedtx", Value: 1},
 					},
 				},
 			},
@@ -149,10 +145,8 @@ func CreateH264Producer(tranpsort ITransport) *Producer {
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "4d0032",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "4d0032"},
 					},
 					RtcpFeedback: []RtcpFeedback{
 						{Type: "nack", Parameter: ""},
@@ -164,7 +158,7 @@ func CreateH264Producer(tranpsort ITransport) *Producer {
 					MimeType:    "video/rtx",
 					PayloadType: 113,
 					ClockRate:   90000,
-					Parameters:  RtpCodecSpecificParameters{Apt: 112},
+					Parameters:  RtpCodecSpecificParameters{{Key: "apt", Value: 112}},
 				},
 			},
 			HeaderExtensions: []RtpHeaderExtensionParameters{
@@ -0,0 +1,238 @@
+// Package hls publishes Producers as HLS with FFmpeg:
+//
+//	pipeline, err := hls.NewPipeline(hls.Options{
+//		Router:    router,
+//		Producers: []*mediasoup.Producer{audioProducer, videoProducer},
+//		OutputDir: "/var/www/live/room1",
+//	})
+//	pipeline.On("error", func(err error) { log.Println(err) })
+//	pipeline.On("restart", func(attempt int) { log.Println("ffmpeg restarted", attempt) })
+//	pipeline.On("close", func() { cleanup() })
+//
+// Every Producer is consumed through a PlainTransport sending RTP to FFmpeg,
+// which reads a generated SDP and writes the playlist and its segments into
+// OutputDir. FFmpeg is restarted when it exits unexpectedly, and the pipeline
+// is closed when a Producer or the Router is closed.
+package hls
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/internal/ffmpeg"
+)
+
+/**
+ * ErrTooManyRestarts is given to the "error" listeners when FFmpeg exited
+ * more than MaxRestarts times. The pipeline is closed afterwards.
+ */
+var ErrTooManyRestarts = ffmpeg.ErrTooManyRestarts
+
+/**
+ * ProcessError describes an unexpected exit of FFmpeg, given to the "error"
+ * listeners.
+ */
+type ProcessError = ffmpeg.ProcessError
+
+type Options struct {
+	/**
+	 * Router of the Producers.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Producers to publish, at most one audio and one video.
+	 */
+	Producers []*mediasoup.Producer
+
+	/**
+	 * Directory receiving the playlist and the segments. It must exist.
+	 */
+	OutputDir string
+
+	/**
+	 * Name of the playlist. Default "index.m3u8".
+	 */
+	PlaylistName string
+
+	/**
+	 * Target duration of the segments. Default 4 seconds, 1 second with
+	 * LowLatency.
+	 */
+	SegmentDuration time.Duration
+
+	/**
+	 * Number of segments in the playlist. Default 6.
+	 */
+	PlaylistSize int
+
+	/**
+	 * Write short fMP4 segments, suitable for low latency players. Default
+	 * false.
+	 */
+	LowLatency bool
+
+	/**
+	 * Path of the FFmpeg binary. Default "ffmpeg".
+	 */
+	FFmpegPath string
+
+	/**
+	 * Additional output arguments (e.g. "-b:v", "2M"), given before the HLS
+	 * ones.
+	 */
+	FFmpegArgs []string
+
+	/**
+	 * IP on which FFmpeg receives the RTP streams. Default "127.0.0.1".
+	 */
+	ListenIp string
+
+	/**
+	 * Number of times FFmpeg is restarted after exiting unexpectedly. Default
+	 * 3, never if negative.
+	 */
+	MaxRestarts int
+
+	/**
+	 * Delay before restarting FFmpeg. Default 1 second.
+	 */
+	RestartDelay time.Duration
+
+	/**
+	 * Logger of the pipeline. Default mediasoup.NewLogger("HlsPipeline").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Pipeline
+ * @emits start - (pid int)
+ * @emits restart - (attempt int)
+ * @emits error - (err error)
+ * @emits close
+ */
+type Pipeline struct {
+	*ffmpeg.Pipeline
+}
+
+/**
+ * Start publishing the given Producers.
+ */
+func NewPipeline(options Options) (*Pipeline, error) {
+	if len(options.OutputDir) == 0 {
+		return nil, mediasoup.NewTypeError("missing OutputDir")
+	}
+
+	options = withDefaults(options)
+
+	pipeline, err := ffmpeg.NewPipeline(ffmpeg.Options{
+		Router:    options.Router,
+		Producers: options.Producers,
+		Args: func(sdpPath, videoMimeType string) []string {
+			return ffmpegArgs(options, sdpPath, videoMimeType)
+		},
+		FFmpegPath:   options.FFmpegPath,
+		ListenIp:     options.ListenIp,
+		MaxRestarts:  options.MaxRestarts,
+		RestartDelay: options.RestartDelay,
+		Logger:       options.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{Pipeline: pipeline}, nil
+}
+
+func withDefaults(options Options) Options {
+	if len(options.PlaylistName) == 0 {
+		options.PlaylistName = "index.m3u8"
+	}
+	if options.SegmentDuration <= 0 {
+		options.SegmentDuration = 4 * time.Second
+
+		if options.LowLatency {
+			options.SegmentDuration = time.Second
+		}
+	}
+	if options.PlaylistSize <= 0 {
+		options.PlaylistSize = 6
+	}
+	if len(options.FFmpegPath) == 0 {
+		options.FFmpegPath = "ffmpeg"
+	}
+	if len(options.ListenIp) == 0 {
+		options.ListenIp = "127.0.0.1"
+	}
+	if options.MaxRestarts == 0 {
+		options.MaxRestarts = 3
+	}
+	if options.RestartDelay <= 0 {
+		options.RestartDelay = time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("HlsPipeline")
+	}
+
+	return options
+}
+
+// ffmpegArgs returns the arguments of FFmpeg reading the given SDP file. H264
+// is copied, other video codecs are transcoded to H264 and audio to AAC.
+func ffmpegArgs(options Options, sdpPath string, videoMimeType string) []string {
+	segmentSeconds := strconv.FormatFloat(options.SegmentDuration.Seconds(), 'f', -1, 64)
+
+	args := []string{
+		"-loglevel", "warning",
+		"-nostdin",
+		"-protocol_whitelist", "file,udp,rtp",
+		"-fflags", "+genpts",
+		"-i", sdpPath,
+	}
+
+	switch {
+	case len(videoMimeType) == 0:
+	case strings.EqualFold(videoMimeType, "video/h264"):
+		args = append(args, "-c:v", "copy")
+	default:
+		args = append(args,
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-tune", "zerolatency",
+			"-force_key_frames", "expr:gte(t,n_forced*"+segmentSeconds+")",
+		)
+	}
+
+	args = append(args, "-c:a", "aac", "-ar", "48000")
+	args = append(args, options.FFmpegArgs...)
+
+	flags := "delete_segments+independent_segments+program_date_time"
+	segmentName := "segment_%05d.ts"
+
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", segmentSeconds,
+		"-hls_list_size", strconv.Itoa(options.PlaylistSize),
+	)
+
+	if options.LowLatency {
+		segmentName = "segment_%05d.m4s"
+		args = append(args,
+			"-hls_segment_type", "fmp4",
+			"-hls_fmp4_init_filename", "init.mp4",
+		)
+		flags += "+split_by_time"
+	}
+
+	args = append(args,
+		"-hls_flags", flags,
+		"-hls_segment_filename", filepath.Join(options.OutputDir, segmentName),
+		filepath.Join(options.OutputDir, options.PlaylistName),
+	)
+
+	return args
+}
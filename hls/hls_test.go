@@ -0,0 +1,57 @@
+package hls
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFmpegArgs(t *testing.T) {
+	options := withDefaults(Options{OutputDir: "/out"})
+
+	args := strings.Join(ffmpegArgs(options, "/tmp/in.sdp", "video/H264"), " ")
+	assert.Contains(t, args, "-i /tmp/in.sdp")
+	assert.Contains(t, args, "-c:v copy")
+	assert.Contains(t, args, "-hls_time 4 -hls_list_size 6")
+	assert.True(t, strings.HasSuffix(args, filepath.Join("/out", "index.m3u8")))
+
+	options = withDefaults(Options{
+		OutputDir:    "/out",
+		LowLatency:   true,
+		PlaylistName: "live.m3u8",
+		FFmpegArgs:   []string{"-b:v", "2M"},
+	})
+
+	args = strings.Join(ffmpegArgs(options, "/tmp/in.sdp", "video/VP8"), " ")
+	assert.Contains(t, args, "-c:v libx264")
+	assert.Contains(t, args, "expr:gte(t,n_forced*1)")
+	assert.Contains(t, args, "-b:v 2M -f hls")
+	assert.Contains(t, args, "-hls_segment_type fmp4")
+	assert.Contains(t, args, filepath.Join("/out", "segment_%05d.m4s"))
+	assert.True(t, strings.HasSuffix(args, filepath.Join("/out", "live.m3u8")))
+
+	args = strings.Join(ffmpegArgs(options, "/tmp/in.sdp", ""), " ")
+	assert.NotContains(t, args, "-c:v")
+}
+
+func TestWithDefaults(t *testing.T) {
+	options := withDefaults(Options{})
+	assert.Equal(t, "index.m3u8", options.PlaylistName)
+	assert.Equal(t, 4*time.Second, options.SegmentDuration)
+	assert.Equal(t, "ffmpeg", options.FFmpegPath)
+	assert.Equal(t, "127.0.0.1", options.ListenIp)
+	assert.Equal(t, 3, options.MaxRestarts)
+	assert.NotNil(t, options.Logger)
+
+	options = withDefaults(Options{MaxRestarts: -1})
+	assert.Equal(t, -1, options.MaxRestarts)
+}
+
+func TestNewPipeline_InvalidOptions(t *testing.T) {
+	_, err := NewPipeline(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
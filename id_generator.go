@@ -0,0 +1,23 @@
+package mediasoup
+
+/**
+ * IdGenerator returns a new unique id for an entity of the given kind:
+ * "router", "transport", "producer", "consumer", "dataProducer",
+ * "dataConsumer" or "rtpObserver".
+ */
+type IdGenerator func(kind string) string
+
+/**
+ * IdGenerator of the workers created without WithIdGenerator(). Default the
+ * Config.IdGenerator if any, else UUIDs v4. Set it before creating any worker,
+ * or use SetConfig() which can be called at any time.
+ */
+var DefaultIdGenerator IdGenerator = generateConfiguredId
+
+// newId calls the generator, or DefaultIdGenerator if it is nil.
+func (generate IdGenerator) newId(kind string) string {
+	if generate == nil {
+		return DefaultIdGenerator(kind)
+	}
+	return generate(kind)
+}
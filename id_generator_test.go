@@ -0,0 +1,29 @@
+package mediasoup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdGenerator(t *testing.T) {
+	var generator IdGenerator
+
+	assert.Len(t, generator.newId("router"), 36)
+
+	count := 0
+	generator = func(kind string) string {
+		count++
+		return fmt.Sprintf("%s-%d", kind, count)
+	}
+	assert.Equal(t, "router-1", generator.newId("router"))
+	assert.Equal(t, "producer-2", generator.newId("producer"))
+
+	defaultIdGenerator := DefaultIdGenerator
+	defer func() { DefaultIdGenerator = defaultIdGenerator }()
+
+	DefaultIdGenerator = func(kind string) string { return "default-" + kind }
+
+	assert.Equal(t, "default-consumer", IdGenerator(nil).newId("consumer"))
+}
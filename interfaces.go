@@ -0,0 +1,408 @@
+package mediasoup
+
+import (
+	"context"
+	"time"
+)
+
+/**
+ * IWorker is the interface of a Worker, to be implemented by mocks in the
+ * tests of applications. AdaptWorker() gives the IWorker of a Worker.
+ */
+type IWorker interface {
+	IEventEmitter
+	Pid() int
+	Closed() bool
+	AppData() interface{}
+	Observer() IEventEmitter
+	Routers() []IRouter
+	Close() error
+	CloseGracefully(ctx context.Context) error
+	Draining() bool
+	Died() <-chan error
+	Done() <-chan struct{}
+	Dump() (WorkerDump, error)
+	DumpContext(ctx context.Context) (WorkerDump, error)
+	GetResourceUsage() (WorkerResourceUsage, error)
+	GetResourceUsageContext(ctx context.Context) (WorkerResourceUsage, error)
+	IPCStats() WorkerIPCStats
+	StartResourceMonitor(interval time.Duration) (stop func())
+	Ping(ctx context.Context) error
+	StartLivenessCheck(interval, timeout time.Duration) (stop func())
+	UpdateSettings(settings WorkerUpdateableSettings) error
+	UpdateSettingsContext(ctx context.Context, settings WorkerUpdateableSettings) error
+	CreateRouter(options RouterOptions, opts ...RouterOption) (IRouter, error)
+	CreateRouterContext(ctx context.Context, options RouterOptions, opts ...RouterOption) (IRouter, error)
+}
+
+/**
+ * IRouter is the interface of a Router. AdaptRouter() gives the IRouter of a
+ * Router.
+ */
+type IRouter interface {
+	IEventEmitter
+	Id() string
+	Closed() bool
+	AppData() interface{}
+	RtpCapabilities() RtpCapabilities
+	SetCodecPreferences(preferences CodecPreferences) error
+	Observer() IEventEmitter
+	Close() error
+	Dump() (*RouterDump, error)
+	DumpContext(ctx context.Context) (*RouterDump, error)
+	Producers() []IProducer
+	DataProducers() []IDataProducer
+	CreateWebRtcTransport(options WebRtcTransportOptions, opts ...WebRtcTransportOption) (IWebRtcTransport, error)
+	CreateWebRtcTransportContext(ctx context.Context, options WebRtcTransportOptions, opts ...WebRtcTransportOption) (IWebRtcTransport, error)
+	CreateAudioLevelObserver(options ...func(o *AudioLevelObserverOptions)) (IRtpObserver, error)
+	CreateAudioLevelObserverContext(ctx context.Context, options ...func(o *AudioLevelObserverOptions)) (IRtpObserver, error)
+	CanConsume(producerId string, rtpCapabilities RtpCapabilities) bool
+}
+
+/**
+ * IWebRtcTransport is the interface of a WebRtcTransport.
+ * AdaptWebRtcTransport() gives the IWebRtcTransport of a WebRtcTransport.
+ */
+type IWebRtcTransport interface {
+	IEventEmitter
+	Id() string
+	Closed() bool
+	AppData() interface{}
+	Observer() IEventEmitter
+	Close() error
+	IceRole() string
+	IceParameters() IceParameters
+	IceCandidates() []IceCandidate
+	IceState() IceState
+	IceSelectedTuple() *TransportTuple
+	DtlsParameters() DtlsParameters
+	DtlsState() DtlsState
+	DtlsRemoteCert() string
+	SctpParameters() SctpParameters
+	SctpState() SctpState
+	Producers() []IProducer
+	Consumers() []IConsumer
+	DataProducers() []IDataProducer
+	DataConsumers() []IDataConsumer
+	Dump() (*TransportDump, error)
+	DumpContext(ctx context.Context) (*TransportDump, error)
+	GetStats() ([]*TransportStat, error)
+	GetStatsContext(ctx context.Context) ([]*TransportStat, error)
+	Connect(options TransportConnectOptions) error
+	ConnectContext(ctx context.Context, options TransportConnectOptions) error
+	RestartIce() (IceParameters, error)
+	RestartIceContext(ctx context.Context) (IceParameters, error)
+	SetMaxIncomingBitrate(bitrate int) error
+	SetMaxIncomingBitrateContext(ctx context.Context, bitrate int) error
+	EnableTraceEvent(types ...TransportTraceEventType) error
+	EnableTraceEventContext(ctx context.Context, types ...TransportTraceEventType) error
+	Produce(options ProducerOptions, opts ...ProducerOption) (IProducer, error)
+	ProduceContext(ctx context.Context, options ProducerOptions, opts ...ProducerOption) (IProducer, error)
+	Consume(options ConsumerOptions, opts ...ConsumerOption) (IConsumer, error)
+	ConsumeContext(ctx context.Context, options ConsumerOptions, opts ...ConsumerOption) (IConsumer, error)
+	ProduceData(options DataProducerOptions, opts ...DataProducerOption) (IDataProducer, error)
+	ProduceDataContext(ctx context.Context, options DataProducerOptions, opts ...DataProducerOption) (IDataProducer, error)
+	ConsumeData(options DataConsumerOptions, opts ...DataConsumerOption) (IDataConsumer, error)
+	ConsumeDataContext(ctx context.Context, options DataConsumerOptions, opts ...DataConsumerOption) (IDataConsumer, error)
+}
+
+/**
+ * IProducer is the interface of a Producer, implemented by *Producer.
+ */
+type IProducer interface {
+	IEventEmitter
+	Id() string
+	Closed() bool
+	Kind() MediaKind
+	RtpParameters() RtpParameters
+	Type() ProducerType
+	ConsumableRtpParameters() RtpParameters
+	RtpMapping() RtpMapping
+	Paused() bool
+	Score() []ProducerScore
+	AppData() interface{}
+	Observer() IEventEmitter
+	Close() error
+	Dump() (ProducerDump, error)
+	DumpContext(ctx context.Context) (ProducerDump, error)
+	GetStats() ([]*ProducerStat, error)
+	GetStatsContext(ctx context.Context) ([]*ProducerStat, error)
+	Pause() error
+	PauseContext(ctx context.Context) error
+	Resume() error
+	ResumeContext(ctx context.Context) error
+	EnableTraceEvent(types ...ProducerTraceEventType) error
+	EnableTraceEventContext(ctx context.Context, types ...ProducerTraceEventType) error
+	Send(rtpPacket []byte) error
+}
+
+/**
+ * IConsumer is the interface of a Consumer, implemented by *Consumer.
+ */
+type IConsumer interface {
+	IEventEmitter
+	Id() string
+	ProducerId() string
+	Closed() bool
+	Kind() MediaKind
+	RtpParameters() RtpParameters
+	Type() ConsumerType
+	Paused() bool
+	ProducerPaused() bool
+	Priority() uint32
+	Score() ConsumerScore
+	PreferredLayers() *ConsumerLayers
+	CurrentLayers() *ConsumerLayers
+	AppData() interface{}
+	Observer() IEventEmitter
+	Close() error
+	Dump() (*ConsumerDump, error)
+	DumpContext(ctx context.Context) (*ConsumerDump, error)
+	GetStats() ([]*ConsumerStat, error)
+	GetStatsContext(ctx context.Context) ([]*ConsumerStat, error)
+	Pause() error
+	PauseContext(ctx context.Context) error
+	Resume() error
+	ResumeContext(ctx context.Context) error
+	SetPreferredLayers(layers ConsumerLayers) error
+	SetPreferredLayersContext(ctx context.Context, layers ConsumerLayers) error
+	SetPriority(priority uint32) error
+	SetPriorityContext(ctx context.Context, priority uint32) error
+	UnsetPriority() error
+	UnsetPriorityContext(ctx context.Context) error
+	RequestKeyFrame() error
+	RequestKeyFrameContext(ctx context.Context) error
+	EnableTraceEvent(types ...ConsumerTraceEventType) error
+	EnableTraceEventContext(ctx context.Context, types ...ConsumerTraceEventType) error
+}
+
+/**
+ * IDataProducer is the interface of a DataProducer, implemented by
+ * *DataProducer.
+ */
+type IDataProducer interface {
+	IEventEmitter
+	Id() string
+	Closed() bool
+	Type() DataConsumerType
+	SctpStreamParameters() SctpStreamParameters
+	Label() string
+	Protocol() string
+	AppData() interface{}
+	Observer() IEventEmitter
+	Close() error
+	Dump() (DataProducerDump, error)
+	DumpContext(ctx context.Context) (DataProducerDump, error)
+	GetStats() ([]*DataProducerStat, error)
+	GetStatsContext(ctx context.Context) ([]*DataProducerStat, error)
+	Send(data []byte, ppid ...int) error
+	SendText(message string) error
+}
+
+/**
+ * IDataConsumer is the interface of a DataConsumer, implemented by
+ * *DataConsumer.
+ */
+type IDataConsumer interface {
+	IEventEmitter
+	Id() string
+	DataProducerId() string
+	Closed() bool
+	Type() DataConsumerType
+	SctpStreamParameters() *SctpStreamParameters
+	Label() string
+	Protocol() string
+	AppData() interface{}
+	Observer() IEventEmitter
+	Close() error
+	Dump() (DataConsumerDump, error)
+	DumpContext(ctx context.Context) (DataConsumerDump, error)
+	GetStats() ([]*DataConsumerStat, error)
+	GetStatsContext(ctx context.Context) ([]*DataConsumerStat, error)
+	SetBufferedAmountLowThreshold(threshold int) error
+	SetBufferedAmountLowThresholdContext(ctx context.Context, threshold int) error
+	GetBufferedAmount() (int64, error)
+	GetBufferedAmountContext(ctx context.Context) (int64, error)
+	Send(data []byte, ppid ...int) error
+	SendContext(ctx context.Context, data []byte, ppid ...int) error
+	SendText(message string) error
+	SendTextContext(ctx context.Context, message string) error
+}
+
+var (
+	_ IProducer     = (*Producer)(nil)
+	_ IConsumer     = (*Consumer)(nil)
+	_ IDataProducer = (*DataProducer)(nil)
+	_ IDataConsumer = (*DataConsumer)(nil)
+)
+
+/**
+ * AdaptWorker gives the IWorker of the Worker, whose Routers are given as
+ * IRouter.
+ */
+func AdaptWorker(worker *Worker) IWorker {
+	return workerAdapter{worker}
+}
+
+type workerAdapter struct {
+	*Worker
+}
+
+func (w workerAdapter) Routers() []IRouter {
+	routers := w.Worker.Routers()
+	adapted := make([]IRouter, len(routers))
+
+	for i, router := range routers {
+		adapted[i] = AdaptRouter(router)
+	}
+	return adapted
+}
+
+func (w workerAdapter) CreateRouter(options RouterOptions, opts ...RouterOption) (IRouter, error) {
+	return w.CreateRouterContext(context.Background(), options, opts...)
+}
+
+func (w workerAdapter) CreateRouterContext(ctx context.Context, options RouterOptions, opts ...RouterOption) (IRouter, error) {
+	router, err := w.Worker.CreateRouterContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return AdaptRouter(router), nil
+}
+
+/**
+ * AdaptRouter gives the IRouter of the Router, whose WebRtcTransports are
+ * given as IWebRtcTransport.
+ */
+func AdaptRouter(router *Router) IRouter {
+	return routerAdapter{router}
+}
+
+type routerAdapter struct {
+	*Router
+}
+
+func (r routerAdapter) Producers() []IProducer {
+	return adaptProducers(r.Router.Producers())
+}
+
+func (r routerAdapter) DataProducers() []IDataProducer {
+	return adaptDataProducers(r.Router.DataProducers())
+}
+
+func (r routerAdapter) CreateWebRtcTransport(options WebRtcTransportOptions, opts ...WebRtcTransportOption) (IWebRtcTransport, error) {
+	return r.CreateWebRtcTransportContext(context.Background(), options, opts...)
+}
+
+func (r routerAdapter) CreateWebRtcTransportContext(ctx context.Context, options WebRtcTransportOptions, opts ...WebRtcTransportOption) (IWebRtcTransport, error) {
+	transport, err := r.Router.CreateWebRtcTransportContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return AdaptWebRtcTransport(transport), nil
+}
+
+/**
+ * AdaptWebRtcTransport gives the IWebRtcTransport of the WebRtcTransport.
+ */
+func AdaptWebRtcTransport(transport *WebRtcTransport) IWebRtcTransport {
+	return webRtcTransportAdapter{transport}
+}
+
+type webRtcTransportAdapter struct {
+	*WebRtcTransport
+}
+
+func (t webRtcTransportAdapter) Producers() []IProducer {
+	return adaptProducers(t.WebRtcTransport.Producers())
+}
+
+func (t webRtcTransportAdapter) Consumers() []IConsumer {
+	consumers := t.WebRtcTransport.Consumers()
+	adapted := make([]IConsumer, len(consumers))
+
+	for i, consumer := range consumers {
+		adapted[i] = consumer
+	}
+	return adapted
+}
+
+func (t webRtcTransportAdapter) DataProducers() []IDataProducer {
+	return adaptDataProducers(t.WebRtcTransport.DataProducers())
+}
+
+func (t webRtcTransportAdapter) DataConsumers() []IDataConsumer {
+	dataConsumers := t.WebRtcTransport.DataConsumers()
+	adapted := make([]IDataConsumer, len(dataConsumers))
+
+	for i, dataConsumer := range dataConsumers {
+		adapted[i] = dataConsumer
+	}
+	return adapted
+}
+
+func (t webRtcTransportAdapter) Produce(options ProducerOptions, opts ...ProducerOption) (IProducer, error) {
+	return t.ProduceContext(context.Background(), options, opts...)
+}
+
+func (t webRtcTransportAdapter) ProduceContext(ctx context.Context, options ProducerOptions, opts ...ProducerOption) (IProducer, error) {
+	producer, err := t.WebRtcTransport.ProduceContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return producer, nil
+}
+
+func (t webRtcTransportAdapter) Consume(options ConsumerOptions, opts ...ConsumerOption) (IConsumer, error) {
+	return t.ConsumeContext(context.Background(), options, opts...)
+}
+
+func (t webRtcTransportAdapter) ConsumeContext(ctx context.Context, options ConsumerOptions, opts ...ConsumerOption) (IConsumer, error) {
+	consumer, err := t.WebRtcTransport.ConsumeContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return consumer, nil
+}
+
+func (t webRtcTransportAdapter) ProduceData(options DataProducerOptions, opts ...DataProducerOption) (IDataProducer, error) {
+	return t.ProduceDataContext(context.Background(), options, opts...)
+}
+
+func (t webRtcTransportAdapter) ProduceDataContext(ctx context.Context, options DataProducerOptions, opts ...DataProducerOption) (IDataProducer, error) {
+	dataProducer, err := t.WebRtcTransport.ProduceDataContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return dataProducer, nil
+}
+
+func (t webRtcTransportAdapter) ConsumeData(options DataConsumerOptions, opts ...DataConsumerOption) (IDataConsumer, error) {
+	return t.ConsumeDataContext(context.Background(), options, opts...)
+}
+
+func (t webRtcTransportAdapter) ConsumeDataContext(ctx context.Context, options DataConsumerOptions, opts ...DataConsumerOption) (IDataConsumer, error) {
+	dataConsumer, err := t.WebRtcTransport.ConsumeDataContext(ctx, options, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return dataConsumer, nil
+}
+
+func adaptProducers(producers []*Producer) []IProducer {
+	adapted := make([]IProducer, len(producers))
+
+	for i, producer := range producers {
+		adapted[i] = producer
+	}
+	return adapted
+}
+
+func adaptDataProducers(dataProducers []*DataProducer) []IDataProducer {
+	adapted := make([]IDataProducer, len(dataProducers))
+
+	for i, dataProducer := range dataProducers {
+		adapted[i] = dataProducer
+	}
+	return adapted
+}
@@ -0,0 +1,54 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptWorker(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	var worker IWorker = AdaptWorker(mock.Worker)
+
+	router, err := worker.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+	require.Len(t, worker.Routers(), 1)
+	assert.Equal(t, router.Id(), worker.Routers()[0].Id())
+
+	mock.Respond("router.createWebRtcTransport", H{"iceRole": "controlled", "iceState": "new"})
+
+	transport, err := router.CreateWebRtcTransport(WebRtcTransportOptions{
+		ListenIps: []TransportListenIp{{Ip: "127.0.0.1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, IceState_New, transport.IceState())
+
+	mock.Respond("transport.produce", H{"type": "simple"})
+
+	producer, err := transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &Producer{}, producer)
+	assert.Len(t, transport.Producers(), 1)
+	assert.Len(t, router.Producers(), 1)
+
+	mock.Handle("router.createWebRtcTransport", func(MockRequest) (interface{}, error) {
+		return nil, NewTypeError("rejected")
+	})
+
+	_, err = router.CreateWebRtcTransport(WebRtcTransportOptions{
+		ListenIps: []TransportListenIp{{Ip: "127.0.0.1"}},
+	})
+	assert.IsType(t, TypeError{}, err)
+}
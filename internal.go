@@ -1,5 +1,7 @@
 package mediasoup
 
+import "strings"
+
 type internalData struct {
 	RouterId       string `json:"routerId,omitempty"`
 	TransportId    string `json:"transportId,omitempty"`
@@ -9,3 +11,25 @@ type internalData struct {
 	DataConsumerId string `json:"dataConsumerId,omitempty"`
 	RtpObserverId  string `json:"rtpObserverId,omitempty"`
 }
+
+// entityId returns the id of the entity targeted by a request of the method.
+func (data internalData) entityId(method string) string {
+	switch method[:strings.Index(method+".", ".")] {
+	case "router":
+		return data.RouterId
+	case "transport":
+		return data.TransportId
+	case "producer":
+		return data.ProducerId
+	case "consumer":
+		return data.ConsumerId
+	case "dataProducer":
+		return data.DataProducerId
+	case "dataConsumer":
+		return data.DataConsumerId
+	case "rtpObserver":
+		return data.RtpObserverId
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,564 @@
+//go:build jsongen
+// +build jsongen
+
+// Command jsongen generates the JSON marshalers of the types listed by
+// mediasoup.JSONGenTypes, which are the structs exchanged with the worker at a
+// high rate (RTP parameters, stats, scores, trace events and channel
+// messages), and the decoders of the slices of them it lists. The generated code writes and reads the same JSON as
+// encoding/json does, through the jsonx runtime instead of reflection.
+//
+// It is run by go generate in the root package:
+//
+//	go generate -tags jsongen
+//
+// The jsongen build tag excludes the generated file, so that the types are
+// inspected without their generated methods.
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+const pkgPath = "github.com/jiyeyuran/mediasoup-go"
+
+var (
+	marshalerType       = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+)
+
+func main() {
+	output := flag.String("o", "json_gen.go", "output file")
+	flag.Parse()
+
+	g := generator{types: make(map[reflect.Type]bool)}
+
+	for _, v := range mediasoup.JSONGenTypes {
+		t := reflect.TypeOf(v)
+
+		switch {
+		case t.Kind() == reflect.Slice:
+			g.slices = append(g.slices, t)
+		case t.Kind() == reflect.Struct && t.PkgPath() == pkgPath:
+			g.types[t] = true
+			g.order = append(g.order, t)
+		default:
+			log.Fatalf("jsongen: %s is not a struct of package mediasoup nor a slice", t)
+		}
+	}
+
+	src, err := format.Source(g.generate())
+	if err != nil {
+		log.Fatalf("jsongen: format: %v", err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatalf("jsongen: %v", err)
+	}
+}
+
+type generator struct {
+	buf    bytes.Buffer
+	types  map[reflect.Type]bool
+	order  []reflect.Type
+	slices []reflect.Type
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generate() []byte {
+	g.printf("// Code generated by jsongen. DO NOT EDIT.\n\n")
+	g.printf("//go:build !jsongen\n// +build !jsongen\n\n")
+	g.printf("package mediasoup\n\n")
+	g.printf("import (\n\"reflect\"\n\n\"github.com/jiyeyuran/mediasoup-go/internal/jsonx\"\n)\n")
+
+	for _, t := range g.order {
+		g.generateType(t)
+	}
+	g.generateSlices()
+
+	return g.buf.Bytes()
+}
+
+// generateSlices writes decodeGeneratedJSON, decoding the slices of
+// generated types that json.Unmarshal would decode with reflection.
+func (g *generator) generateSlices() {
+	g.printf("\n// decodeGeneratedJSON decodes data into v if it points to a slice with a\n")
+	g.printf("// generated decoder, returning false otherwise.\n")
+	g.printf("func decodeGeneratedJSON(data []byte, v interface{}) (bool, error) {\n")
+	g.printf("l := &jsonx.Lexer{Data: data}\n\n")
+	g.printf("switch v := v.(type) {\n")
+
+	for _, t := range g.slices {
+		if g.delegated(t) {
+			log.Fatalf("jsongen: slice %s has no generated decoder", t)
+		}
+		g.printf("case *%s:\n", g.typeName(t))
+		g.decode("(*v)", t, 1)
+	}
+	g.printf("default:\nreturn false, nil\n}\n\n")
+	g.printf("l.Done()\nreturn true, l.Error()\n}\n")
+}
+
+// field is a JSON member of a struct, the embedded structs being flattened.
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+}
+
+// fields returns the JSON members of the struct t following the rules of
+// encoding/json: the fields of embedded structs are promoted, and a name
+// given to several fields goes to the least nested one, or to the only
+// tagged one at that depth, or else to none.
+func fields(t reflect.Type) []field {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+	var (
+		all     []field
+		current []queued
+		next    = []queued{{typ: t}}
+		visited = map[reflect.Type]bool{}
+	)
+
+	for len(next) > 0 {
+		current, next = next, nil
+		count := map[string]int{}
+		var level []field
+
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if sf.PkgPath != "" {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts := tag, ""
+				if i := strings.Index(tag, ","); i >= 0 {
+					name, opts = tag[:i], tag[i+1:]
+				}
+				for _, opt := range strings.Split(opts, ",") {
+					if opt != "" && opt != "omitempty" {
+						log.Fatalf("jsongen: unsupported option %q of %s.%s", opt, q.typ, sf.Name)
+					}
+				}
+
+				index := append(append([]int(nil), q.index...), i)
+				ft := sf.Type
+
+				if name == "" && sf.Anonymous {
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						next = append(next, queued{typ: ft, index: index})
+						continue
+					}
+				}
+
+				f := field{
+					name:      name,
+					index:     index,
+					typ:       sf.Type,
+					tagged:    name != "",
+					omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				count[f.name]++
+				level = append(level, f)
+			}
+		}
+
+		// Resolve the names at this depth against the outer ones.
+		for _, f := range level {
+			if hidden(all, f.name) {
+				continue
+			}
+			if count[f.name] > 1 {
+				tagged := 0
+				for _, other := range level {
+					if other.name == f.name && other.tagged {
+						tagged++
+					}
+				}
+				if tagged != 1 || !f.tagged {
+					continue
+				}
+			}
+			all = append(all, f)
+		}
+		for _, f := range level {
+			if count[f.name] > 1 && !hidden(all, f.name) {
+				// Mark the conflicting name as taken.
+				all = append(all, field{name: f.name})
+			}
+		}
+	}
+
+	var result []field
+	for _, f := range all {
+		if f.typ != nil {
+			result = append(result, f)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].index, result[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return result
+}
+
+func hidden(fields []field, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the selector of the field in v, and the embedded pointers
+// it goes through.
+func path(t reflect.Type, f field) (expr string, pointers []string) {
+	expr = "v"
+
+	for i, index := range f.index {
+		sf := t.Field(index)
+		expr += "." + sf.Name
+		t = sf.Type
+
+		if i < len(f.index)-1 && t.Kind() == reflect.Ptr {
+			pointers = append(pointers, expr)
+			t = t.Elem()
+		}
+	}
+
+	return
+}
+
+func (g *generator) generateType(t reflect.Type) {
+	name := t.Name()
+	fs := fields(t)
+
+	g.printf("\n// MarshalJSON implements json.Marshaler.\n")
+	g.printf("func (v %s) MarshalJSON() ([]byte, error) {\n", name)
+	g.printf("w := jsonx.Writer{Buf: make([]byte, 0, 256)}\nv.encodeJSON(&w)\nreturn w.BuildBytes()\n}\n")
+
+	g.printf("\n// UnmarshalJSON implements json.Unmarshaler.\n")
+	g.printf("func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+	g.printf("l := jsonx.Lexer{Data: data}\nv.decodeJSON(&l)\nl.Done()\nreturn l.Error()\n}\n")
+
+	// Encoding.
+	g.printf("\nfunc (v *%s) encodeJSON(w *jsonx.Writer) {\n", name)
+	g.printf("first := true\nw.RawByte('{')\n")
+
+	for _, f := range fs {
+		expr, pointers := path(t, f)
+		conds := make([]string, 0, len(pointers)+1)
+
+		for _, p := range pointers {
+			conds = append(conds, p+" != nil")
+		}
+		if f.omitEmpty {
+			if cond := notEmpty(expr, f.typ); cond != "" {
+				conds = append(conds, cond)
+			}
+		}
+		key, _ := json.Marshal(f.name)
+
+		if len(conds) > 0 {
+			g.printf("if %s {\n", strings.Join(conds, " && "))
+		}
+		g.printf("w.Key(&first, `%s:`)\n", key)
+		g.encode(expr, f.typ, 1)
+		if len(conds) > 0 {
+			g.printf("}\n")
+		}
+	}
+	g.printf("w.RawByte('}')\n}\n")
+
+	// Decoding.
+	g.printf("\nfunc (v *%s) decodeJSON(l *jsonx.Lexer) {\n", name)
+	g.printf("if l.IsNull() {\nreturn\n}\n")
+	g.printf("if !l.Object() {\nl.TypeError(reflect.TypeOf(v).Elem())\nreturn\n}\n")
+	g.printf("for !l.End('}') {\n")
+	g.printf("key := l.Key()\n")
+	g.printf("if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, %s...)) {\n", keysName(name))
+	g.printf("l.Skip()\n}\nl.Comma()\n}\n}\n")
+
+	g.printf("\nvar %s = []string{", keysName(name))
+	for i, f := range fs {
+		if i > 0 {
+			g.printf(", ")
+		}
+		g.printf("%q", f.name)
+	}
+	g.printf("}\n")
+
+	g.printf("\nfunc (v *%s) decodeJSONField(l *jsonx.Lexer, key []byte) bool {\n", name)
+	g.printf("switch string(key) {\n")
+
+	for _, f := range fs {
+		expr, pointers := path(t, f)
+
+		g.printf("case %q:\n", f.name)
+
+		for i, p := range pointers {
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", p, p, g.typeName(embeddedType(t, f, i).Elem()))
+		}
+		g.decode(expr, f.typ, 1)
+	}
+	g.printf("default:\nreturn false\n}\nreturn true\n}\n")
+}
+
+// keysName returns the name of the variable listing the JSON keys of the
+// type.
+func keysName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:] + "JSONKeys"
+}
+
+// embeddedType returns the type of the i-th embedded pointer of the path of
+// the field.
+func embeddedType(t reflect.Type, f field, i int) reflect.Type {
+	n := 0
+
+	for _, index := range f.index {
+		ft := t.Field(index).Type
+
+		if ft.Kind() == reflect.Ptr {
+			if n == i {
+				return ft
+			}
+			n++
+			ft = ft.Elem()
+		}
+		t = ft
+	}
+
+	panic("unreachable")
+}
+
+// notEmpty returns the condition for the value not to be empty, as the
+// omitempty option of encoding/json defines it, or an empty string if the
+// value is never empty.
+func notEmpty(expr string, t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return expr
+	case reflect.String:
+		return expr + ` != ""`
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return expr + " != 0"
+	case reflect.Ptr, reflect.Interface:
+		return expr + " != nil"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "len(" + expr + ") != 0"
+	}
+	return ""
+}
+
+// delegated tells whether values of type t are left to encoding/json.
+func (g *generator) delegated(t reflect.Type) bool {
+	if t == rawMessageType {
+		return true
+	}
+	for _, i := range []reflect.Type{marshalerType, unmarshalerType, textMarshalerType, textUnmarshalerType} {
+		if t.Implements(i) || reflect.PtrTo(t).Implements(i) {
+			return true
+		}
+	}
+	if t.Name() != "" && t.PkgPath() != "" && t.PkgPath() != pkgPath {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return !g.types[t]
+	case reflect.Ptr:
+		return g.delegated(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8 || g.delegated(t.Elem())
+	case reflect.Map, reflect.Interface, reflect.Array, reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// typeName returns the name of the type t in package mediasoup.
+func (g *generator) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() != "" && t.PkgPath() != pkgPath {
+			log.Fatalf("jsongen: type %s of another package", t)
+		}
+		return t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeName(t.Elem())
+	}
+	log.Fatalf("jsongen: unsupported type %s", t)
+	return ""
+}
+
+// convert returns the conversion of the value expr of type t to the basic
+// type to.
+func convert(expr string, t reflect.Type, to string) string {
+	if t.PkgPath() == "" && t.Name() == to {
+		return expr
+	}
+	return to + "(" + expr + ")"
+}
+
+// convertFrom returns the conversion of the value expr of the basic type from
+// to the type t.
+func (g *generator) convertFrom(expr string, t reflect.Type, from string) string {
+	if t.PkgPath() == "" && t.Name() == from {
+		return expr
+	}
+	return g.typeName(t) + "(" + expr + ")"
+}
+
+func bits(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		return 0
+	}
+	return t.Bits()
+}
+
+// encode writes the code encoding the value expr of type t.
+func (g *generator) encode(expr string, t reflect.Type, depth int) {
+	if g.delegated(t) {
+		g.printf("w.Marshal(%s)\n", expr)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		g.printf("w.Bool(%s)\n", convert(expr, t, "bool"))
+	case reflect.String:
+		g.printf("w.String(%s)\n", convert(expr, t, "string"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		g.printf("w.Int64(%s)\n", convert(expr, t, "int64"))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		g.printf("w.Uint64(%s)\n", convert(expr, t, "uint64"))
+	case reflect.Float32, reflect.Float64:
+		g.printf("w.Float(%s, %d)\n", convert(expr, t, "float64"), t.Bits())
+	case reflect.Struct:
+		g.printf("%s.encodeJSON(w)\n", expr)
+	case reflect.Ptr:
+		g.printf("if %s == nil {\nw.RawString(\"null\")\n} else {\n", expr)
+		if t.Elem().Kind() == reflect.Struct {
+			g.encode(expr, t.Elem(), depth)
+		} else {
+			g.encode("*"+expr, t.Elem(), depth)
+		}
+		g.printf("}\n")
+	case reflect.Slice:
+		i := fmt.Sprintf("i%d", depth)
+		g.printf("if %s == nil {\nw.RawString(\"null\")\n} else {\nw.RawByte('[')\n", expr)
+		g.printf("for %s := range %s {\nif %s > 0 {\nw.RawByte(',')\n}\n", i, expr, i)
+		g.encode(fmt.Sprintf("%s[%s]", expr, i), t.Elem(), depth+1)
+		g.printf("}\nw.RawByte(']')\n}\n")
+	default:
+		log.Fatalf("jsongen: unsupported type %s", t)
+	}
+}
+
+// decode writes the code decoding the value expr of type t.
+func (g *generator) decode(expr string, t reflect.Type, depth int) {
+	if t == rawMessageType {
+		g.printf("%s = l.RawCopy()\n", expr)
+		return
+	}
+	if g.delegated(t) {
+		g.printf("l.Unmarshal(&%s)\n", expr)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		g.printf("if !l.IsNull() {\n%s = %s\n}\n", expr, g.convertFrom("l.Bool()", t, "bool"))
+	case reflect.String:
+		g.printf("if !l.IsNull() {\n%s = %s\n}\n", expr, g.convertFrom("l.String()", t, "string"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		g.printf("if !l.IsNull() {\n%s = %s\n}\n", expr, g.convertFrom(fmt.Sprintf("l.Int(%d)", bits(t)), t, "int64"))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		g.printf("if !l.IsNull() {\n%s = %s\n}\n", expr, g.convertFrom(fmt.Sprintf("l.Uint(%d)", bits(t)), t, "uint64"))
+	case reflect.Float32, reflect.Float64:
+		g.printf("if !l.IsNull() {\n%s = %s\n}\n", expr, g.convertFrom(fmt.Sprintf("l.Float(%d)", t.Bits()), t, "float64"))
+	case reflect.Struct:
+		g.printf("%s.decodeJSON(l)\n", expr)
+	case reflect.Ptr:
+		g.printf("if l.IsNull() {\n%s = nil\n} else {\n", expr)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeName(t.Elem()))
+		if t.Elem().Kind() == reflect.Struct {
+			g.decode(expr, t.Elem(), depth)
+		} else {
+			g.decode("*"+expr, t.Elem(), depth)
+		}
+		g.printf("}\n")
+	case reflect.Slice:
+		x := fmt.Sprintf("x%d", depth)
+		g.printf("if l.IsNull() {\n%s = nil\n} else if !l.Array() {\nl.TypeError(reflect.TypeOf(%s))\n} else {\n", expr, expr)
+		g.printf("%s = %s[:0]\n", expr, expr)
+		g.printf("for !l.End(']') {\n")
+		// Start with 4 elements like encoding/json.
+		g.printf("if %s == nil {\n%s = make(%s, 0, 4)\n}\n", expr, expr, g.typeName(t))
+		g.printf("var %s %s\n", x, g.typeName(t.Elem()))
+		g.decode(x, t.Elem(), depth+1)
+		g.printf("%s = append(%s, %s)\nl.Comma()\n}\n", expr, expr, x)
+		g.printf("if %s == nil {\n%s = %s{}\n}\n}\n", expr, expr, g.typeName(t))
+	default:
+		log.Fatalf("jsongen: unsupported type %s", t)
+	}
+}
@@ -0,0 +1,90 @@
+package ffmpeg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/sdp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionDescription(t *testing.T) {
+	text := sessionDescription("127.0.0.1", []input{
+		{
+			kind: mediasoup.MediaKind_Audio,
+			rtpParameters: mediasoup.RtpParameters{
+				Codecs: []*mediasoup.RtpCodecParameters{
+					{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+				},
+				Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 1111}},
+				Rtcp:      mediasoup.RtcpParameters{Cname: "cname", Mux: mediasoup.Bool(true)},
+			},
+			rtpPort: 20000,
+		},
+		{
+			kind: mediasoup.MediaKind_Video,
+			rtpParameters: mediasoup.RtpParameters{
+				Codecs: []*mediasoup.RtpCodecParameters{
+					{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+				},
+				Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 2222}},
+			},
+			rtpPort: 20002,
+		},
+	})
+
+	assert.Contains(t, text, "c=IN IP4 127.0.0.1\r\n")
+	assert.NotContains(t, text, "rtcp-mux")
+
+	desc, err := sdp.Parse(text)
+	require.NoError(t, err)
+	require.Len(t, desc.MediaSections, 2)
+
+	audio, video := desc.MediaSections[0], desc.MediaSections[1]
+	assert.Equal(t, 20000, audio.Port)
+	assert.Equal(t, "recvonly", audio.Direction())
+	assert.Equal(t, 20002, video.Port)
+
+	rtpmap, _ := video.Attribute("rtpmap")
+	assert.Equal(t, "101 VP8/90000", rtpmap)
+
+	assert.Contains(t, sessionDescription("::1", nil), "c=IN IP6 ::1\r\n")
+}
+
+func TestAllocatePorts(t *testing.T) {
+	port1, err := allocatePorts("127.0.0.1")
+	require.NoError(t, err)
+	defer releasePorts(port1)
+
+	port2, err := allocatePorts("127.0.0.1")
+	require.NoError(t, err)
+	defer releasePorts(port2)
+
+	assert.Zero(t, port1%2)
+	assert.Zero(t, port2%2)
+	assert.NotEqual(t, port1, port2)
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	b.Write([]byte("0123456789"))
+	b.Write([]byte("ab\n"))
+
+	assert.Equal(t, "56789ab", b.String())
+}
+
+func TestProcessError(t *testing.T) {
+	cause := errors.New("exit status 1")
+	err := &ProcessError{Err: cause, ExitCode: 1, Stderr: "Invalid data"}
+
+	assert.True(t, errors.Is(err, cause))
+	assert.Contains(t, err.Error(), "code 1")
+	assert.Contains(t, err.Error(), "Invalid data")
+}
+
+func TestNewPipeline_InvalidOptions(t *testing.T) {
+	_, err := NewPipeline(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
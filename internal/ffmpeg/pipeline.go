@@ -0,0 +1,505 @@
+// Package ffmpeg runs FFmpeg fed with Producers, for the egress helpers (hls,
+// rtmp). Every Producer is consumed through a PlainTransport sending RTP to
+// FFmpeg, which reads a generated SDP. FFmpeg is restarted when it exits
+// unexpectedly, and the pipeline is closed when a Producer or the Router is
+// closed.
+package ffmpeg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Time given to FFmpeg to finalize its output when the pipeline is closed.
+const stopTimeout = 5 * time.Second
+
+// Delay before requesting keyframes once FFmpeg is started, so that it is
+// ready to receive them.
+const keyFrameDelay = time.Second
+
+/**
+ * ErrTooManyRestarts is given to the "error" listeners when FFmpeg exited
+ * more than MaxRestarts times. The pipeline is closed afterwards.
+ */
+var ErrTooManyRestarts = errors.New("ffmpeg: too many restarts")
+
+/**
+ * ProcessError describes an unexpected exit of FFmpeg.
+ */
+type ProcessError struct {
+	/**
+	 * Error returned by the process, usually an *exec.ExitError.
+	 */
+	Err error
+
+	/**
+	 * Exit code, -1 if killed by a signal.
+	 */
+	ExitCode int
+
+	/**
+	 * Last lines written by FFmpeg to stderr.
+	 */
+	Stderr string
+}
+
+func (e *ProcessError) Error() string {
+	return fmt.Sprintf("ffmpeg exited with code %d: %v: %s", e.ExitCode, e.Err, e.Stderr)
+}
+
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+type Options struct {
+	/**
+	 * Router of the Producers.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Producers to send to FFmpeg, at most one audio and one video.
+	 */
+	Producers []*mediasoup.Producer
+
+	/**
+	 * Arguments of FFmpeg reading the given SDP file, the video being encoded
+	 * with the given codec (empty if none).
+	 */
+	Args func(sdpPath string, videoMimeType string) []string
+
+	/**
+	 * Path of the FFmpeg binary.
+	 */
+	FFmpegPath string
+
+	/**
+	 * IP on which FFmpeg receives the RTP streams.
+	 */
+	ListenIp string
+
+	/**
+	 * Number of times FFmpeg is restarted after exiting unexpectedly, never if
+	 * negative.
+	 */
+	MaxRestarts int
+
+	/**
+	 * Delay before restarting FFmpeg.
+	 */
+	RestartDelay time.Duration
+
+	/**
+	 * Interval of the keyframe requests to the video Producer. Only once FFmpeg
+	 * is started if 0.
+	 */
+	KeyFrameInterval time.Duration
+
+	Logger mediasoup.Logger
+}
+
+type track struct {
+	transport *mediasoup.PlainTransport
+	consumer  *mediasoup.Consumer
+	rtpPort   int
+}
+
+/**
+ * Pipeline
+ * @emits start - (pid int)
+ * @emits restart - (attempt int)
+ * @emits error - (err error)
+ * @emits close
+ */
+type Pipeline struct {
+	mediasoup.IEventEmitter
+	logger   mediasoup.Logger
+	options  Options
+	tracks   []*track
+	args     []string
+	sdpPath  string
+	locker   sync.Mutex
+	cmd      *exec.Cmd
+	exited   chan struct{}
+	restarts int
+	closed   bool
+	closeCh  chan struct{}
+}
+
+/**
+ * Start FFmpeg with the given Producers.
+ */
+func NewPipeline(options Options) (pipeline *Pipeline, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if len(options.Producers) == 0 {
+		return nil, mediasoup.NewTypeError("missing Producers")
+	}
+
+	kinds := map[mediasoup.MediaKind]bool{}
+
+	for _, producer := range options.Producers {
+		if kinds[producer.Kind()] {
+			return nil, mediasoup.NewTypeError("more than one %s Producer", producer.Kind())
+		}
+		kinds[producer.Kind()] = true
+	}
+
+	pipeline = &Pipeline{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		options:       options,
+		closeCh:       make(chan struct{}),
+	}
+
+	defer func() {
+		if err != nil {
+			pipeline.Close()
+			pipeline = nil
+		}
+	}()
+
+	var (
+		inputs        []input
+		videoMimeType string
+	)
+
+	for _, producer := range options.Producers {
+		var t *track
+
+		if t, err = pipeline.createTrack(producer); err != nil {
+			return
+		}
+		pipeline.tracks = append(pipeline.tracks, t)
+
+		rtpParameters := t.consumer.RtpParameters()
+
+		inputs = append(inputs, input{
+			kind:          t.consumer.Kind(),
+			rtpParameters: rtpParameters,
+			rtpPort:       t.rtpPort,
+		})
+
+		if t.consumer.Kind() == mediasoup.MediaKind_Video {
+			videoMimeType = rtpParameters.Codecs[0].MimeType
+		}
+	}
+
+	sdpFile, err := ioutil.TempFile("", "mediasoup-ffmpeg-*.sdp")
+	if err != nil {
+		return
+	}
+	pipeline.sdpPath = sdpFile.Name()
+
+	_, err = sdpFile.WriteString(sessionDescription(options.ListenIp, inputs))
+	if closeErr := sdpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	pipeline.args = options.Args(pipeline.sdpPath, videoMimeType)
+
+	pipeline.locker.Lock()
+	err = pipeline.start()
+	pipeline.locker.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	for _, t := range pipeline.tracks {
+		if err = t.consumer.Resume(); err != nil {
+			return
+		}
+		t.consumer.On("producerclose", func() {
+			pipeline.logger.Debug("Producer closed, closing")
+			go pipeline.Close()
+		})
+		t.consumer.On("transportclose", func() {
+			go pipeline.Close()
+		})
+	}
+
+	if options.KeyFrameInterval > 0 {
+		go pipeline.requestKeyFramesPeriodically()
+	}
+
+	return
+}
+
+// createTrack consumes the Producer, paused until FFmpeg is started, through
+// a PlainTransport sending to FFmpeg.
+func (p *Pipeline) createTrack(producer *mediasoup.Producer) (t *track, err error) {
+	t = &track{}
+
+	if t.rtpPort, err = allocatePorts(p.options.ListenIp); err != nil {
+		return nil, err
+	}
+
+	transport, err := p.options.Router.CreatePlainTransport(mediasoup.PlainTransportOptions{
+		ListenIp: mediasoup.TransportListenIp{Ip: p.options.ListenIp},
+		RtcpMux:  mediasoup.Bool(false),
+	})
+	if err != nil {
+		releasePorts(t.rtpPort)
+		return nil, err
+	}
+	t.transport = transport
+
+	err = transport.Connect(mediasoup.TransportConnectOptions{
+		Ip:       p.options.ListenIp,
+		Port:     uint16(t.rtpPort),
+		RtcpPort: uint16(t.rtpPort + 1),
+	})
+	if err != nil {
+		p.releaseTrack(t)
+		return nil, err
+	}
+
+	t.consumer, err = transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: p.options.Router.RtpCapabilities(),
+		Paused:          true,
+	})
+	if err != nil {
+		p.releaseTrack(t)
+		return nil, err
+	}
+
+	return
+}
+
+/**
+ * Process id of FFmpeg, 0 if not running.
+ */
+func (p *Pipeline) Pid() int {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.cmd == nil || p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+/**
+ * Number of times FFmpeg was restarted.
+ */
+func (p *Pipeline) Restarts() int {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.restarts
+}
+
+/**
+ * Whether the pipeline is closed.
+ */
+func (p *Pipeline) Closed() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.closed
+}
+
+/**
+ * Stop FFmpeg, letting it finalize its output, and close the transports.
+ */
+func (p *Pipeline) Close() error {
+	p.locker.Lock()
+
+	if p.closed {
+		p.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	p.logger.Debug("Close()")
+
+	p.closed = true
+	close(p.closeCh)
+	cmd, exited := p.cmd, p.exited
+
+	p.locker.Unlock()
+
+	if cmd != nil {
+		// Ask FFmpeg to exit gracefully.
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			cmd.Process.Kill()
+		}
+
+		select {
+		case <-exited:
+		case <-time.After(stopTimeout):
+			p.logger.Warn("ffmpeg did not exit in time, killing it")
+			cmd.Process.Kill()
+			<-exited
+		}
+	}
+
+	p.release()
+	p.SafeEmit("close")
+
+	return nil
+}
+
+// release closes the transports and removes the SDP file.
+func (p *Pipeline) release() {
+	for _, t := range p.tracks {
+		p.releaseTrack(t)
+	}
+	if len(p.sdpPath) > 0 {
+		os.Remove(p.sdpPath)
+	}
+}
+
+func (p *Pipeline) releaseTrack(t *track) {
+	t.transport.Close()
+	releasePorts(t.rtpPort)
+}
+
+// start starts FFmpeg, the locker being held.
+func (p *Pipeline) start() (err error) {
+	p.logger.Debug("start() [args:%q]", p.args)
+
+	stderr := &tailBuffer{max: 4096}
+	cmd := exec.Command(p.options.FFmpegPath, p.args...)
+	cmd.Stderr = stderr
+
+	if err = cmd.Start(); err != nil {
+		return
+	}
+
+	exited := make(chan struct{})
+	p.cmd, p.exited = cmd, exited
+
+	go p.wait(cmd, stderr, exited)
+
+	go func() {
+		select {
+		case <-time.After(keyFrameDelay):
+			p.requestKeyFrames()
+		case <-p.closeCh:
+		}
+	}()
+
+	p.SafeEmit("start", cmd.Process.Pid)
+
+	return
+}
+
+func (p *Pipeline) requestKeyFrames() {
+	for _, t := range p.tracks {
+		if t.consumer.Kind() == mediasoup.MediaKind_Video {
+			t.consumer.RequestKeyFrame()
+		}
+	}
+}
+
+func (p *Pipeline) requestKeyFramesPeriodically() {
+	ticker := time.NewTicker(p.options.KeyFrameInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.requestKeyFrames()
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+// wait waits for FFmpeg to exit, restarting it if the pipeline is not closed.
+func (p *Pipeline) wait(cmd *exec.Cmd, stderr *tailBuffer, exited chan struct{}) {
+	err := cmd.Wait()
+	close(exited)
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	processErr := &ProcessError{
+		Err:      err,
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stderr:   stderr.String(),
+	}
+	if processErr.Err == nil {
+		processErr.Err = errors.New("unexpected exit")
+	}
+
+	p.logger.Error("ffmpeg exited: %v", processErr)
+	p.SafeEmit("error", processErr)
+
+	if p.options.MaxRestarts < 0 || p.restarts >= p.options.MaxRestarts {
+		p.SafeEmit("error", ErrTooManyRestarts)
+		go p.Close()
+		return
+	}
+
+	p.restarts++
+	attempt := p.restarts
+
+	go func() {
+		select {
+		case <-time.After(p.options.RestartDelay):
+		case <-p.closeCh:
+			return
+		}
+
+		p.locker.Lock()
+		defer p.locker.Unlock()
+
+		if p.closed {
+			return
+		}
+		if err := p.start(); err != nil {
+			p.logger.Error("restarting ffmpeg failed: %v", err)
+			p.SafeEmit("error", err)
+			go p.Close()
+			return
+		}
+
+		p.SafeEmit("restart", attempt)
+	}()
+}
+
+// tailBuffer keeps the last bytes written.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf bytes.Buffer
+}
+
+func (b *tailBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Write(data)
+
+	if extra := b.buf.Len() - b.max; extra > 0 {
+		b.buf.Next(extra)
+	}
+
+	return len(data), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return strings.TrimSpace(b.buf.String())
+}
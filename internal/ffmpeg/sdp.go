@@ -0,0 +1,104 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/sdp"
+)
+
+// input is a stream sent to FFmpeg.
+type input struct {
+	kind          mediasoup.MediaKind
+	rtpParameters mediasoup.RtpParameters
+	rtpPort       int
+}
+
+// sessionDescription returns the SDP given to FFmpeg to receive the inputs on
+// the given IP.
+func sessionDescription(ip string, inputs []input) string {
+	addrType := "IP4"
+	if strings.Contains(ip, ":") {
+		addrType = "IP6"
+	}
+
+	desc := &sdp.SessionDescription{
+		Lines: []string{
+			"v=0",
+			fmt.Sprintf("o=- 0 0 IN %s %s", addrType, ip),
+			"s=mediasoup-go",
+			fmt.Sprintf("c=IN %s %s", addrType, ip),
+			"t=0 0",
+		},
+	}
+
+	for _, in := range inputs {
+		section := sdp.MediaSectionFromRtpParameters(in.kind, in.rtpParameters)
+		section.Port = in.rtpPort
+
+		// RTCP is received on the next port.
+		attributes := section.Attributes[:0]
+		for _, attr := range section.Attributes {
+			if attr.Key != "rtcp-mux" {
+				attributes = append(attributes, attr)
+			}
+		}
+		section.Attributes = append(attributes, sdp.Attribute{Key: "recvonly"})
+
+		desc.MediaSections = append(desc.MediaSections, section)
+	}
+
+	return desc.String()
+}
+
+// portLocker prevents two pipelines of the process from picking the same ports
+// between their allocation and FFmpeg binding them.
+var (
+	portLocker    sync.Mutex
+	reservedPorts = map[int]bool{}
+)
+
+// allocatePorts returns an even port free on the given IP, whose next port is
+// free as well, for RTP and RTCP.
+func allocatePorts(ip string) (port int, err error) {
+	portLocker.Lock()
+	defer portLocker.Unlock()
+
+	for attempt := 0; attempt < 50; attempt++ {
+		var conn net.PacketConn
+
+		conn, err = net.ListenPacket("udp", net.JoinHostPort(ip, "0"))
+		if err != nil {
+			return
+		}
+		port = conn.LocalAddr().(*net.UDPAddr).Port
+		conn.Close()
+
+		if port%2 != 0 || port >= 65535 || reservedPorts[port] {
+			continue
+		}
+
+		rtcpConn, rtcpErr := net.ListenPacket("udp", net.JoinHostPort(ip, strconv.Itoa(port+1)))
+		if rtcpErr != nil {
+			continue
+		}
+		rtcpConn.Close()
+
+		reservedPorts[port] = true
+
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("no free port pair on %s", ip)
+}
+
+func releasePorts(port int) {
+	portLocker.Lock()
+	defer portLocker.Unlock()
+
+	delete(reservedPorts, port)
+}
@@ -0,0 +1,205 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_String(t *testing.T) {
+	// \b and \f are left out, their escaping depends on the Go version.
+	for _, s := range []string{
+		"",
+		"opus",
+		`quote " and backslash \`,
+		"line\nbreak\r\ttab",
+		"\x00\x01\x1f\x7f",
+		"<script>&</script>",
+		"héllo wörld 日本 🎉",
+		"line separators \u2028 \u2029",
+	} {
+		w := Writer{}
+		w.String(s)
+
+		expected, _ := json.Marshal(s)
+		assert.Equal(t, string(expected), string(w.Buf), "%q", s)
+	}
+
+	// Invalid UTF-8 is replaced by U+FFFD, escaped or not depending on the Go
+	// version.
+	w := Writer{}
+	w.String("invalid \xff utf-8 \xe2\x82")
+
+	var s string
+	require.NoError(t, json.Unmarshal(w.Buf, &s))
+	assert.Equal(t, "invalid \ufffd utf-8 \ufffd\ufffd", s)
+}
+
+func TestWriter_Float(t *testing.T) {
+	for _, f := range []float64{0, 1, -1, 0.5, 1e-7, 123456789.123, 1e20, 1e21, 3.4e38, 1e-320} {
+		w := Writer{}
+		w.Float(f, 64)
+
+		expected, _ := json.Marshal(f)
+		assert.Equal(t, string(expected), string(w.Buf))
+
+		w = Writer{}
+		w.Float(float64(float32(f)), 32)
+
+		expected, _ = json.Marshal(float32(f))
+		assert.Equal(t, string(expected), string(w.Buf))
+	}
+
+	w := Writer{}
+	w.Float(math.NaN(), 64)
+
+	_, err := w.BuildBytes()
+	assert.IsType(t, &json.UnsupportedValueError{}, err)
+}
+
+func TestWriter_Marshal(t *testing.T) {
+	w := Writer{}
+	w.RawByte('[')
+	w.Marshal(map[string]int{"a": 1})
+	w.RawByte(',')
+	w.Marshal(func() {})
+	w.RawByte(']')
+
+	_, err := w.BuildBytes()
+	assert.Error(t, err)
+	assert.Equal(t, `[{"a":1},null]`, string(w.Buf))
+}
+
+func TestLexer_String(t *testing.T) {
+	for _, data := range []string{
+		`""`,
+		`"opus"`,
+		`"escapes \" \\ \/ \b \f \n \r \t"`,
+		`"é日 🎉"`,
+		`"lone surrogates \ud83c \udf89 \ud83cx"`,
+		"\"raw héllo \xff\"",
+	} {
+		var expected string
+		require.NoError(t, json.Unmarshal([]byte(data), &expected))
+
+		l := Lexer{Data: []byte(data)}
+		s := l.String()
+		l.Done()
+
+		assert.NoError(t, l.Error())
+		assert.Equal(t, expected, s, data)
+	}
+}
+
+func TestLexer_Numbers(t *testing.T) {
+	l := Lexer{Data: []byte(`[-12, 255, 1.5e3, 256, 1.5, 1e400]`)}
+
+	require.True(t, l.Array())
+	assert.EqualValues(t, -12, l.Int(32))
+	l.Comma()
+	assert.EqualValues(t, 255, l.Uint(8))
+	l.Comma()
+	assert.EqualValues(t, 1500, l.Float(64))
+	l.Comma()
+	assert.EqualValues(t, 0, l.Uint(8))
+	l.Comma()
+	assert.EqualValues(t, 0, l.Int(64))
+	l.Comma()
+	assert.EqualValues(t, 0, l.Float(64))
+	assert.True(t, l.End(']'))
+	l.Done()
+
+	var typeErr *json.UnmarshalTypeError
+
+	require.True(t, errors.As(l.Error(), &typeErr))
+	assert.Equal(t, "number 256", typeErr.Value)
+	assert.Equal(t, "uint8", typeErr.Type.String())
+}
+
+func TestLexer_IntegerRanges(t *testing.T) {
+	for _, c := range []struct {
+		data string
+		read func(l *Lexer) interface{}
+		want interface{}
+		ok   bool
+	}{
+		{"-128", func(l *Lexer) interface{} { return l.Int(8) }, int64(-128), true},
+		{"128", func(l *Lexer) interface{} { return l.Int(8) }, int64(0), false},
+		{"-9223372036854775808", func(l *Lexer) interface{} { return l.Int(64) }, int64(math.MinInt64), true},
+		{"9223372036854775808", func(l *Lexer) interface{} { return l.Int(64) }, int64(0), false},
+		{"18446744073709551615", func(l *Lexer) interface{} { return l.Uint(64) }, uint64(math.MaxUint64), true},
+		{"65536", func(l *Lexer) interface{} { return l.Uint(16) }, uint64(0), false},
+		{"-1", func(l *Lexer) interface{} { return l.Uint(32) }, uint64(0), false},
+		{"-0", func(l *Lexer) interface{} { return l.Float(64) }, math.Copysign(0, -1), true},
+		{"9007199254740993", func(l *Lexer) interface{} { return l.Float(64) }, float64(9007199254740992), true},
+	} {
+		l := Lexer{Data: []byte(c.data)}
+		v := c.read(&l)
+		l.Done()
+
+		assert.Equal(t, c.want, v, c.data)
+		assert.Equal(t, c.ok, l.Error() == nil, c.data)
+	}
+}
+
+func TestLexer_Skip(t *testing.T) {
+	data := `{"a": [1, {"b": "c\"}"}, null, true, false, -0.5e-3], "d": {}} `
+
+	l := Lexer{Data: []byte(data)}
+	raw := l.Raw()
+	l.Done()
+
+	assert.NoError(t, l.Error())
+	assert.Equal(t, data[:len(data)-1], string(raw))
+}
+
+func TestLexer_TypeError(t *testing.T) {
+	l := Lexer{Data: []byte(`{"a": {"x": 1}, "b": "c"}`)}
+
+	require.True(t, l.Object())
+	assert.Equal(t, "a", string(l.Key()))
+	assert.Equal(t, "", l.String())
+	l.Comma()
+	assert.Equal(t, "b", string(l.Key()))
+	assert.Equal(t, "c", l.String())
+	assert.True(t, l.End('}'))
+	l.Done()
+
+	var typeErr *json.UnmarshalTypeError
+
+	require.True(t, errors.As(l.Error(), &typeErr))
+	assert.Equal(t, "object", typeErr.Value)
+}
+
+func TestLexer_SyntaxError(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`{`,
+		`{"a" 1}`,
+		`{"a": 1,}`,
+		`[1 2]`,
+		`"unterminated`,
+		`"bad \x escape"`,
+		`nul`,
+		`01`,
+		`1 2`,
+	} {
+		l := Lexer{Data: []byte(data)}
+		l.Skip()
+		l.Done()
+
+		assert.Error(t, l.Error(), data)
+	}
+}
+
+func TestFoldKey(t *testing.T) {
+	keys := []string{"type", "messagesReceived"}
+
+	assert.Equal(t, "messagesReceived", string(FoldKey([]byte("MessagesReceived"), keys...)))
+	assert.Equal(t, "type", string(FoldKey([]byte("TYPE"), keys...)))
+	assert.Nil(t, FoldKey([]byte("label"), keys...))
+}
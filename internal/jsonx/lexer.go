@@ -0,0 +1,638 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Lexer reads JSON values from Data. A syntax error stops the reading, while a
+// value not matching its destination is skipped, the first of them being
+// reported by Error like encoding/json does.
+type Lexer struct {
+	Data []byte
+
+	pos     int
+	err     error
+	typeErr error
+}
+
+// Error returns the syntax error, or else the first type error.
+func (l *Lexer) Error() error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.typeErr
+}
+
+// Done checks that nothing but spaces follows the value read.
+func (l *Lexer) Done() {
+	l.skipSpace()
+
+	if l.err == nil && l.pos < len(l.Data) {
+		l.syntaxError("invalid character after top-level value")
+	}
+}
+
+func (l *Lexer) skipSpace() {
+	for l.pos < len(l.Data) {
+		switch l.Data[l.pos] {
+		case ' ', '\t', '\r', '\n':
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non space byte, or 0 at the end of the data or after a
+// syntax error.
+func (l *Lexer) peek() byte {
+	if l.err != nil {
+		return 0
+	}
+	l.skipSpace()
+
+	if l.pos >= len(l.Data) {
+		l.syntaxError("unexpected end of JSON input")
+		return 0
+	}
+	return l.Data[l.pos]
+}
+
+func (l *Lexer) syntaxError(msg string) {
+	if l.err == nil {
+		l.err = fmt.Errorf("jsonx: %s at offset %d", msg, l.pos)
+	}
+}
+
+// TypeError records that the next value does not match the type t, and skips
+// it.
+func (l *Lexer) TypeError(t reflect.Type) {
+	l.typeError(l.kind(), t)
+	l.Skip()
+}
+
+func (l *Lexer) typeError(value string, t reflect.Type) {
+	if l.typeErr == nil {
+		l.typeErr = &json.UnmarshalTypeError{Value: value, Type: t, Offset: int64(l.pos)}
+	}
+}
+
+// kind returns the kind of the next value, as named by json.UnmarshalTypeError.
+func (l *Lexer) kind() string {
+	switch l.peek() {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// IsNull consumes the next value if it is null.
+func (l *Lexer) IsNull() bool {
+	if l.peek() == 'n' {
+		l.literal("null")
+		return true
+	}
+	return false
+}
+
+func (l *Lexer) literal(lit string) {
+	if len(l.Data)-l.pos < len(lit) || string(l.Data[l.pos:l.pos+len(lit)]) != lit {
+		l.syntaxError("invalid literal")
+		return
+	}
+	l.pos += len(lit)
+}
+
+// Object consumes the start of an object, returning false if the next value
+// is not an object.
+func (l *Lexer) Object() bool {
+	if l.peek() == '{' {
+		l.pos++
+		return true
+	}
+	return false
+}
+
+// Array consumes the start of an array, returning false if the next value is
+// not an array.
+func (l *Lexer) Array() bool {
+	if l.peek() == '[' {
+		l.pos++
+		return true
+	}
+	return false
+}
+
+// End consumes the end c ('}' or ']') of the current object or array, if
+// next. It returns true after a syntax error too, to end the loops.
+func (l *Lexer) End(c byte) bool {
+	if next := l.peek(); next == c {
+		l.pos++
+		return true
+	} else if next == 0 {
+		return true
+	}
+	return false
+}
+
+// Comma consumes the comma separating the members or elements of an object or
+// array.
+func (l *Lexer) Comma() {
+	switch l.peek() {
+	case ',':
+		l.pos++
+
+		if next := l.peek(); next == '}' || next == ']' {
+			l.syntaxError("invalid character after comma")
+		}
+	case '}', ']', 0:
+	default:
+		l.syntaxError("invalid character after value")
+	}
+}
+
+// Key reads the key of an object member and its colon. The key is valid until
+// the next read.
+func (l *Lexer) Key() []byte {
+	if l.peek() != '"' {
+		l.syntaxError("invalid character looking for object key")
+		return nil
+	}
+	key := l.str()
+
+	if l.peek() != ':' {
+		l.syntaxError("invalid character after object key")
+		return key
+	}
+	l.pos++
+
+	return key
+}
+
+var stringType = reflect.TypeOf("")
+
+// String reads a string.
+func (l *Lexer) String() string {
+	if l.peek() != '"' {
+		if l.err == nil {
+			l.TypeError(stringType)
+		}
+		return ""
+	}
+	return string(l.str())
+}
+
+// str reads the string starting at pos, sharing the bytes of Data unless it
+// needs decoding.
+func (l *Lexer) str() []byte {
+	start := l.pos + 1
+
+	for i := start; i < len(l.Data); i++ {
+		switch c := l.Data[i]; {
+		case c == '"':
+			l.pos = i + 1
+			return l.Data[start:i]
+		case c == '\\' || c < 0x20 || c >= utf8.RuneSelf:
+			return l.slowStr(start, i)
+		}
+	}
+
+	l.pos = len(l.Data)
+	l.syntaxError("unexpected end of JSON input")
+
+	return nil
+}
+
+// slowStr reads the string from start, whose bytes before i need no decoding.
+func (l *Lexer) slowStr(start, i int) []byte {
+	b := make([]byte, 0, len(l.Data)-start)
+	b = append(b, l.Data[start:i]...)
+
+	for i < len(l.Data) {
+		c := l.Data[i]
+
+		switch {
+		case c == '"':
+			l.pos = i + 1
+			return b
+
+		case c < 0x20:
+			l.pos = i
+			l.syntaxError("invalid character in string literal")
+			return nil
+
+		case c == '\\':
+			if i+1 >= len(l.Data) {
+				i++
+				break
+			}
+			i += 2
+
+			switch e := l.Data[i-1]; e {
+			case '"', '\\', '/':
+				b = append(b, e)
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'u':
+				r := l.hex4(i)
+				if r < 0 {
+					l.pos = i
+					l.syntaxError("invalid escape in string literal")
+					return nil
+				}
+				i += 4
+
+				if utf16.IsSurrogate(r) {
+					r2 := rune(-1)
+					if i+1 < len(l.Data) && l.Data[i] == '\\' && l.Data[i+1] == 'u' {
+						r2 = l.hex4(i + 2)
+					}
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						i += 6
+						r = dec
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				b = append(b, string(r)...)
+			default:
+				l.pos = i - 1
+				l.syntaxError("invalid escape in string literal")
+				return nil
+			}
+
+		case c < utf8.RuneSelf:
+			b = append(b, c)
+			i++
+
+		default:
+			r, size := utf8.DecodeRune(l.Data[i:])
+			if r == utf8.RuneError && size == 1 {
+				b = append(b, string(utf8.RuneError)...)
+			} else {
+				b = append(b, l.Data[i:i+size]...)
+			}
+			i += size
+		}
+	}
+
+	l.pos = len(l.Data)
+	l.syntaxError("unexpected end of JSON input")
+
+	return nil
+}
+
+// hex4 returns the rune of the 4 hex digits at i, or -1.
+func (l *Lexer) hex4(i int) rune {
+	if i+4 > len(l.Data) {
+		return -1
+	}
+	var r rune
+
+	for _, c := range l.Data[i : i+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c = c - '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return -1
+		}
+		r = r*16 + rune(c)
+	}
+	return r
+}
+
+// number reads a number token, returning false if the next value is not a
+// number.
+func (l *Lexer) number() ([]byte, bool) {
+	switch c := l.peek(); {
+	case c == '-' || '0' <= c && c <= '9':
+	default:
+		return nil, false
+	}
+	start := l.pos
+
+	for ; l.pos < len(l.Data); l.pos++ {
+		if c := l.Data[l.pos]; !('0' <= c && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E') {
+			break
+		}
+	}
+	return l.Data[start:l.pos], true
+}
+
+// integer parses the number b if it is an integer of at most 18 digits, which
+// is most of them, without allocating.
+func integer(b []byte) (n uint64, neg bool, ok bool) {
+	if len(b) > 0 && b[0] == '-' {
+		b, neg = b[1:], true
+	}
+	if len(b) == 0 || len(b) > 18 || b[0] == '0' && len(b) > 1 {
+		return 0, false, false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false, false
+		}
+		n = n*10 + uint64(c-'0')
+	}
+	return n, neg, true
+}
+
+var (
+	intTypes   = map[int]reflect.Type{0: reflect.TypeOf(int(0)), 8: reflect.TypeOf(int8(0)), 16: reflect.TypeOf(int16(0)), 32: reflect.TypeOf(int32(0)), 64: reflect.TypeOf(int64(0))}
+	uintTypes  = map[int]reflect.Type{0: reflect.TypeOf(uint(0)), 8: reflect.TypeOf(uint8(0)), 16: reflect.TypeOf(uint16(0)), 32: reflect.TypeOf(uint32(0)), 64: reflect.TypeOf(uint64(0))}
+	floatTypes = map[int]reflect.Type{32: reflect.TypeOf(float32(0)), 64: reflect.TypeOf(float64(0))}
+	boolType   = reflect.TypeOf(false)
+)
+
+// Int reads an integer of the given bit size, 0 meaning the size of int.
+func (l *Lexer) Int(bits int) int64 {
+	b, ok := l.number()
+	if !ok {
+		if l.err == nil {
+			l.TypeError(intTypes[bits])
+		}
+		return 0
+	}
+	size := bits
+	if size == 0 {
+		size = strconv.IntSize
+	}
+
+	if n, neg, ok := integer(b); ok {
+		switch {
+		case neg && n <= 1<<uint(size-1):
+			return -int64(n)
+		case !neg && n < 1<<uint(size-1):
+			return int64(n)
+		}
+		l.typeError("number "+string(b), intTypes[bits])
+		return 0
+	}
+
+	str := string(b)
+	if !validNumber(str) {
+		l.syntaxError("invalid number")
+		return 0
+	}
+	n, err := strconv.ParseInt(str, 10, size)
+	if err != nil {
+		l.typeError("number "+str, intTypes[bits])
+		return 0
+	}
+	return n
+}
+
+// Uint reads an unsigned integer of the given bit size, 0 meaning the size of
+// uint.
+func (l *Lexer) Uint(bits int) uint64 {
+	b, ok := l.number()
+	if !ok {
+		if l.err == nil {
+			l.TypeError(uintTypes[bits])
+		}
+		return 0
+	}
+	size := bits
+	if size == 0 {
+		size = strconv.IntSize
+	}
+
+	if n, neg, ok := integer(b); ok {
+		if !neg && (size == 64 || n < 1<<uint(size)) {
+			return n
+		}
+		l.typeError("number "+string(b), uintTypes[bits])
+		return 0
+	}
+
+	str := string(b)
+	if !validNumber(str) {
+		l.syntaxError("invalid number")
+		return 0
+	}
+	n, err := strconv.ParseUint(str, 10, size)
+	if err != nil {
+		l.typeError("number "+str, uintTypes[bits])
+		return 0
+	}
+	return n
+}
+
+// Float reads a float of the given bit size.
+func (l *Lexer) Float(bits int) float64 {
+	b, ok := l.number()
+	if !ok {
+		if l.err == nil {
+			l.TypeError(floatTypes[bits])
+		}
+		return 0
+	}
+
+	// Integers below 2^53 are exact floats.
+	if n, neg, ok := integer(b); ok && n <= 1<<53 {
+		if neg {
+			return -float64(n)
+		}
+		return float64(n)
+	}
+
+	str := string(b)
+	if !validNumber(str) {
+		l.syntaxError("invalid number")
+		return 0
+	}
+	f, err := strconv.ParseFloat(str, bits)
+	if err != nil {
+		l.typeError("number "+str, floatTypes[bits])
+		return 0
+	}
+	return f
+}
+
+// validNumber tells whether s is a number of the JSON grammar.
+func validNumber(s string) bool {
+	if s != "" && s[0] == '-' {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = strings.TrimLeft(s, "0123456789")
+	default:
+		return false
+	}
+
+	if s != "" && s[0] == '.' {
+		s = s[1:]
+		if s == "" || s[0] < '0' || s[0] > '9' {
+			return false
+		}
+		s = strings.TrimLeft(s, "0123456789")
+	}
+
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			s = s[1:]
+		}
+		if s == "" || s[0] < '0' || s[0] > '9' {
+			return false
+		}
+		s = strings.TrimLeft(s, "0123456789")
+	}
+
+	return s == ""
+}
+
+// Bool reads a boolean.
+func (l *Lexer) Bool() bool {
+	switch l.peek() {
+	case 't':
+		l.literal("true")
+		return true
+	case 'f':
+		l.literal("false")
+		return false
+	}
+	if l.err == nil {
+		l.TypeError(boolType)
+	}
+	return false
+}
+
+// Skip skips the next value.
+func (l *Lexer) Skip() {
+	switch l.peek() {
+	case 0:
+	case '{':
+		l.pos++
+
+		for !l.End('}') {
+			l.Key()
+			l.Skip()
+			l.Comma()
+		}
+	case '[':
+		l.pos++
+
+		for !l.End(']') {
+			l.Skip()
+			l.Comma()
+		}
+	case '"':
+		l.skipStr()
+	case 't':
+		l.literal("true")
+	case 'f':
+		l.literal("false")
+	case 'n':
+		l.literal("null")
+	default:
+		if b, ok := l.number(); !ok || !validNumber(string(b)) {
+			l.syntaxError("invalid character looking for beginning of value")
+		}
+	}
+}
+
+// skipStr skips the string starting at pos without decoding it.
+func (l *Lexer) skipStr() {
+	for i := l.pos + 1; i < len(l.Data); i++ {
+		switch c := l.Data[i]; {
+		case c == '"':
+			l.pos = i + 1
+			return
+		case c < 0x20:
+			l.pos = i
+			l.syntaxError("invalid character in string literal")
+			return
+		case c == '\\':
+			i++
+			if i < len(l.Data) && strings.IndexByte(`"\/bfnrtu`, l.Data[i]) < 0 {
+				l.pos = i
+				l.syntaxError("invalid escape in string literal")
+				return
+			}
+		}
+	}
+
+	l.pos = len(l.Data)
+	l.syntaxError("unexpected end of JSON input")
+}
+
+// Raw returns the next value as is, sharing the bytes of Data.
+func (l *Lexer) Raw() []byte {
+	if l.peek() == 0 {
+		return nil
+	}
+	start := l.pos
+	l.Skip()
+
+	if l.err != nil {
+		return nil
+	}
+	return l.Data[start:l.pos]
+}
+
+// RawCopy returns a copy of the next value, as json.RawMessage is decoded.
+func (l *Lexer) RawCopy() []byte {
+	raw := l.Raw()
+	if raw == nil {
+		return nil
+	}
+	return append([]byte(nil), raw...)
+}
+
+// Unmarshal reads the next value with encoding/json, for the values without
+// generated unmarshaler.
+func (l *Lexer) Unmarshal(v interface{}) {
+	raw := l.Raw()
+	if raw == nil {
+		return
+	}
+	if err := json.Unmarshal(raw, v); err != nil && l.typeErr == nil {
+		l.typeErr = err
+	}
+}
+
+// FoldKey returns the key of keys equal to key under case folding, as
+// encoding/json matches the keys of objects with the fields of structs, or nil.
+func FoldKey(key []byte, keys ...string) []byte {
+	for _, k := range keys {
+		if strings.EqualFold(k, string(key)) {
+			return []byte(k)
+		}
+	}
+	return nil
+}
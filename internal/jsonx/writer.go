@@ -0,0 +1,171 @@
+// Package jsonx is the runtime of the JSON marshalers generated by jsongen
+// (see internal/cmd/jsongen) for the structs exchanged with the worker at a
+// high rate, like stats, scores and trace events. The output of Writer and the
+// values read by Lexer are the ones of encoding/json, without reflection.
+package jsonx
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Writer appends JSON values to a buffer, keeping the first error.
+type Writer struct {
+	Buf []byte
+	err error
+}
+
+// Error returns the first error of the writer.
+func (w *Writer) Error() error {
+	return w.err
+}
+
+// BuildBytes returns the written bytes, or the first error.
+func (w *Writer) BuildBytes() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.Buf, nil
+}
+
+// RawByte writes the byte as is.
+func (w *Writer) RawByte(c byte) {
+	w.Buf = append(w.Buf, c)
+}
+
+// RawString writes the string as is.
+func (w *Writer) RawString(s string) {
+	w.Buf = append(w.Buf, s...)
+}
+
+// Key writes the key of an object member, given quoted with its colon, after
+// a comma unless it is the first member of the object.
+func (w *Writer) Key(first *bool, key string) {
+	if !*first {
+		w.Buf = append(w.Buf, ',')
+	}
+	*first = false
+	w.Buf = append(w.Buf, key...)
+}
+
+// Bool writes a boolean.
+func (w *Writer) Bool(b bool) {
+	w.Buf = strconv.AppendBool(w.Buf, b)
+}
+
+// Int64 writes an integer.
+func (w *Writer) Int64(n int64) {
+	w.Buf = strconv.AppendInt(w.Buf, n, 10)
+}
+
+// Uint64 writes an unsigned integer.
+func (w *Writer) Uint64(n uint64) {
+	w.Buf = strconv.AppendUint(w.Buf, n, 10)
+}
+
+// Float writes a float of the given bit size (32 or 64) as encoding/json does.
+func (w *Writer) Float(f float64, bits int) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		if w.err == nil {
+			w.err = &json.UnsupportedValueError{
+				Value: reflect.ValueOf(f),
+				Str:   strconv.FormatFloat(f, 'g', -1, bits),
+			}
+		}
+		w.Buf = append(w.Buf, '0')
+		return
+	}
+
+	format := byte('f')
+
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	w.Buf = strconv.AppendFloat(w.Buf, f, format, -1, bits)
+
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(w.Buf); n >= 4 && w.Buf[n-4] == 'e' && w.Buf[n-3] == '-' && w.Buf[n-2] == '0' {
+			w.Buf[n-2] = w.Buf[n-1]
+			w.Buf = w.Buf[:n-1]
+		}
+	}
+}
+
+const hex = "0123456789abcdef"
+
+// String writes a string, escaped as encoding/json does (HTML characters
+// included).
+func (w *Writer) String(s string) {
+	b := append(w.Buf, '"')
+	start := 0
+
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, string(utf8.RuneError)...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JSONP.
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+
+	b = append(b, s[start:]...)
+	w.Buf = append(b, '"')
+}
+
+// Marshal writes the value with encoding/json, for the values without
+// generated marshaler.
+func (w *Writer) Marshal(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		if w.err == nil {
+			w.err = err
+		}
+		w.Buf = append(w.Buf, "null"...)
+		return
+	}
+	w.Buf = append(w.Buf, data...)
+}
@@ -0,0 +1,105 @@
+package mediasoup
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * State of the Channel or the PayloadChannel with a worker.
+ */
+type ChannelStats struct {
+	/**
+	 * Requests waiting for their response.
+	 */
+	PendingRequests int64 `json:"pendingRequests"`
+
+	/**
+	 * Messages received from the worker and not dispatched yet.
+	 */
+	QueuedMessages int `json:"queuedMessages"`
+
+	/**
+	 * Messages discarded: responses matching no request and receiving buffers
+	 * discarded because full.
+	 */
+	DroppedMessages int64 `json:"droppedMessages"`
+
+	/**
+	 * Messages dispatched to the requests and listeners.
+	 */
+	DispatchedMessages int64 `json:"dispatchedMessages"`
+
+	/**
+	 * Mean and maximum time taken to dispatch a message.
+	 */
+	MeanDispatchLatency time.Duration `json:"meanDispatchLatency"`
+	MaxDispatchLatency  time.Duration `json:"maxDispatchLatency"`
+
+	/**
+	 * Requests and notifications written to the worker, and the socket writes
+	 * they took, fewer when batched.
+	 */
+	WrittenMessages int64 `json:"writtenMessages"`
+	Writes          int64 `json:"writes"`
+}
+
+/**
+ * State of the communication with a worker.
+ */
+type WorkerIPCStats struct {
+	Channel        ChannelStats `json:"channel"`
+	PayloadChannel ChannelStats `json:"payloadChannel"`
+}
+
+// channelCounters are the counters shared by Channel and PayloadChannel.
+type channelCounters struct {
+	dropped          int64
+	dispatched       int64
+	dispatchNanos    int64
+	maxDispatchNanos int64
+	written          int64
+	writes           int64
+}
+
+func (c *channelCounters) drop() {
+	atomic.AddInt64(&c.dropped, 1)
+}
+
+// dispatched counts a message whose dispatching started at the given time.
+func (c *channelCounters) dispatchedSince(start time.Time) {
+	nanos := int64(time.Since(start))
+
+	atomic.AddInt64(&c.dispatched, 1)
+	atomic.AddInt64(&c.dispatchNanos, nanos)
+
+	for {
+		max := atomic.LoadInt64(&c.maxDispatchNanos)
+
+		if nanos <= max || atomic.CompareAndSwapInt64(&c.maxDispatchNanos, max, nanos) {
+			return
+		}
+	}
+}
+
+// wrote counts a socket write of the given number of messages.
+func (c *channelCounters) wrote(messages int) {
+	atomic.AddInt64(&c.written, int64(messages))
+	atomic.AddInt64(&c.writes, 1)
+}
+
+func (c *channelCounters) stats(pendingRequests int64, queuedMessages int) (stats ChannelStats) {
+	stats.PendingRequests = pendingRequests
+	stats.QueuedMessages = queuedMessages
+	stats.DroppedMessages = atomic.LoadInt64(&c.dropped)
+	stats.DispatchedMessages = atomic.LoadInt64(&c.dispatched)
+	stats.MaxDispatchLatency = time.Duration(atomic.LoadInt64(&c.maxDispatchNanos))
+	stats.WrittenMessages = atomic.LoadInt64(&c.written)
+	stats.Writes = atomic.LoadInt64(&c.writes)
+
+	if stats.DispatchedMessages > 0 {
+		stats.MeanDispatchLatency = time.Duration(atomic.LoadInt64(&c.dispatchNanos) / stats.DispatchedMessages)
+	}
+
+	return
+}
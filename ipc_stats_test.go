@@ -0,0 +1,28 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelCounters(t *testing.T) {
+	counters := channelCounters{}
+
+	stats := counters.stats(2, 3)
+	assert.EqualValues(t, 2, stats.PendingRequests)
+	assert.Equal(t, 3, stats.QueuedMessages)
+	assert.Zero(t, stats.MeanDispatchLatency)
+
+	counters.drop()
+	counters.dispatchedSince(time.Now().Add(-10 * time.Millisecond))
+	counters.dispatchedSince(time.Now().Add(-30 * time.Millisecond))
+
+	stats = counters.stats(0, 0)
+	assert.EqualValues(t, 1, stats.DroppedMessages)
+	assert.EqualValues(t, 2, stats.DispatchedMessages)
+	assert.GreaterOrEqual(t, int64(stats.MaxDispatchLatency), int64(30*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(stats.MeanDispatchLatency), int64(20*time.Millisecond))
+	assert.Less(t, int64(stats.MeanDispatchLatency), int64(stats.MaxDispatchLatency))
+}
@@ -0,0 +1,36 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityIterators(t *testing.T) {
+	worker := &Worker{}
+	worker.routers.Store("r1", &Router{})
+	worker.routers.Store("r2", &Router{})
+
+	assert.Len(t, worker.Routers(), 2)
+
+	count := 0
+	worker.AllRouters()(func(router *Router) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+
+	transport := &Transport{}
+	transport.producers.Store("p1", &Producer{})
+	transport.consumers.Store("c1", &Consumer{})
+	transport.consumers.Store("c2", &Consumer{})
+
+	var consumers []*Consumer
+	transport.AllConsumers()(func(consumer *Consumer) bool {
+		consumers = append(consumers, consumer)
+		return true
+	})
+	assert.Len(t, consumers, 2)
+	assert.Len(t, transport.Producers(), 1)
+	assert.Empty(t, transport.DataConsumers())
+}
@@ -0,0 +1,2329 @@
+// Code generated by jsongen. DO NOT EDIT.
+
+//go:build !jsongen
+// +build !jsongen
+
+package mediasoup
+
+import (
+	"reflect"
+
+	"github.com/jiyeyuran/mediasoup-go/internal/jsonx"
+)
+
+// MarshalJSON implements json.Marshaler.
+func (v RtpParameters) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtpParameters) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtpParameters) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Mid != "" {
+		w.Key(&first, `"mid":`)
+		w.String(v.Mid)
+	}
+	w.Key(&first, `"codecs":`)
+	if v.Codecs == nil {
+		w.RawString("null")
+	} else {
+		w.RawByte('[')
+		for i1 := range v.Codecs {
+			if i1 > 0 {
+				w.RawByte(',')
+			}
+			if v.Codecs[i1] == nil {
+				w.RawString("null")
+			} else {
+				v.Codecs[i1].encodeJSON(w)
+			}
+		}
+		w.RawByte(']')
+	}
+	if len(v.HeaderExtensions) != 0 {
+		w.Key(&first, `"headerExtensions":`)
+		if v.HeaderExtensions == nil {
+			w.RawString("null")
+		} else {
+			w.RawByte('[')
+			for i1 := range v.HeaderExtensions {
+				if i1 > 0 {
+					w.RawByte(',')
+				}
+				v.HeaderExtensions[i1].encodeJSON(w)
+			}
+			w.RawByte(']')
+		}
+	}
+	if len(v.Encodings) != 0 {
+		w.Key(&first, `"encodings":`)
+		if v.Encodings == nil {
+			w.RawString("null")
+		} else {
+			w.RawByte('[')
+			for i1 := range v.Encodings {
+				if i1 > 0 {
+					w.RawByte(',')
+				}
+				v.Encodings[i1].encodeJSON(w)
+			}
+			w.RawByte(']')
+		}
+	}
+	w.Key(&first, `"rtcp":`)
+	v.Rtcp.encodeJSON(w)
+	w.RawByte('}')
+}
+
+func (v *RtpParameters) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtpParametersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtpParametersJSONKeys = []string{"mid", "codecs", "headerExtensions", "encodings", "rtcp"}
+
+func (v *RtpParameters) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "mid":
+		if !l.IsNull() {
+			v.Mid = l.String()
+		}
+	case "codecs":
+		if l.IsNull() {
+			v.Codecs = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf(v.Codecs))
+		} else {
+			v.Codecs = v.Codecs[:0]
+			for !l.End(']') {
+				if v.Codecs == nil {
+					v.Codecs = make([]*RtpCodecParameters, 0, 4)
+				}
+				var x1 *RtpCodecParameters
+				if l.IsNull() {
+					x1 = nil
+				} else {
+					if x1 == nil {
+						x1 = new(RtpCodecParameters)
+					}
+					x1.decodeJSON(l)
+				}
+				v.Codecs = append(v.Codecs, x1)
+				l.Comma()
+			}
+			if v.Codecs == nil {
+				v.Codecs = []*RtpCodecParameters{}
+			}
+		}
+	case "headerExtensions":
+		if l.IsNull() {
+			v.HeaderExtensions = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf(v.HeaderExtensions))
+		} else {
+			v.HeaderExtensions = v.HeaderExtensions[:0]
+			for !l.End(']') {
+				if v.HeaderExtensions == nil {
+					v.HeaderExtensions = make([]RtpHeaderExtensionParameters, 0, 4)
+				}
+				var x1 RtpHeaderExtensionParameters
+				x1.decodeJSON(l)
+				v.HeaderExtensions = append(v.HeaderExtensions, x1)
+				l.Comma()
+			}
+			if v.HeaderExtensions == nil {
+				v.HeaderExtensions = []RtpHeaderExtensionParameters{}
+			}
+		}
+	case "encodings":
+		if l.IsNull() {
+			v.Encodings = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf(v.Encodings))
+		} else {
+			v.Encodings = v.Encodings[:0]
+			for !l.End(']') {
+				if v.Encodings == nil {
+					v.Encodings = make([]RtpEncodingParameters, 0, 4)
+				}
+				var x1 RtpEncodingParameters
+				x1.decodeJSON(l)
+				v.Encodings = append(v.Encodings, x1)
+				l.Comma()
+			}
+			if v.Encodings == nil {
+				v.Encodings = []RtpEncodingParameters{}
+			}
+		}
+	case "rtcp":
+		v.Rtcp.decodeJSON(l)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtpCodecParameters) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtpCodecParameters) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtpCodecParameters) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"mimeType":`)
+	w.String(v.MimeType)
+	w.Key(&first, `"payloadType":`)
+	w.Uint64(uint64(v.PayloadType))
+	w.Key(&first, `"clockRate":`)
+	w.Int64(int64(v.ClockRate))
+	if v.Channels != 0 {
+		w.Key(&first, `"channels":`)
+		w.Int64(int64(v.Channels))
+	}
+	w.Key(&first, `"parameters":`)
+	w.Marshal(v.Parameters)
+	if len(v.RtcpFeedback) != 0 {
+		w.Key(&first, `"rtcpFeedback":`)
+		if v.RtcpFeedback == nil {
+			w.RawString("null")
+		} else {
+			w.RawByte('[')
+			for i1 := range v.RtcpFeedback {
+				if i1 > 0 {
+					w.RawByte(',')
+				}
+				v.RtcpFeedback[i1].encodeJSON(w)
+			}
+			w.RawByte(']')
+		}
+	}
+	w.RawByte('}')
+}
+
+func (v *RtpCodecParameters) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtpCodecParametersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtpCodecParametersJSONKeys = []string{"mimeType", "payloadType", "clockRate", "channels", "parameters", "rtcpFeedback"}
+
+func (v *RtpCodecParameters) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "mimeType":
+		if !l.IsNull() {
+			v.MimeType = l.String()
+		}
+	case "payloadType":
+		if !l.IsNull() {
+			v.PayloadType = uint8(l.Uint(8))
+		}
+	case "clockRate":
+		if !l.IsNull() {
+			v.ClockRate = int(l.Int(0))
+		}
+	case "channels":
+		if !l.IsNull() {
+			v.Channels = int(l.Int(0))
+		}
+	case "parameters":
+		l.Unmarshal(&v.Parameters)
+	case "rtcpFeedback":
+		if l.IsNull() {
+			v.RtcpFeedback = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf(v.RtcpFeedback))
+		} else {
+			v.RtcpFeedback = v.RtcpFeedback[:0]
+			for !l.End(']') {
+				if v.RtcpFeedback == nil {
+					v.RtcpFeedback = make([]RtcpFeedback, 0, 4)
+				}
+				var x1 RtcpFeedback
+				x1.decodeJSON(l)
+				v.RtcpFeedback = append(v.RtcpFeedback, x1)
+				l.Comma()
+			}
+			if v.RtcpFeedback == nil {
+				v.RtcpFeedback = []RtcpFeedback{}
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtcpFeedback) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtcpFeedback) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtcpFeedback) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"type":`)
+	w.String(v.Type)
+	if v.Parameter != "" {
+		w.Key(&first, `"parameter":`)
+		w.String(v.Parameter)
+	}
+	w.RawByte('}')
+}
+
+func (v *RtcpFeedback) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtcpFeedbackJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtcpFeedbackJSONKeys = []string{"type", "parameter"}
+
+func (v *RtcpFeedback) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = l.String()
+		}
+	case "parameter":
+		if !l.IsNull() {
+			v.Parameter = l.String()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtpEncodingParameters) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtpEncodingParameters) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtpEncodingParameters) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Ssrc != 0 {
+		w.Key(&first, `"ssrc":`)
+		w.Uint64(uint64(v.Ssrc))
+	}
+	if v.Rid != "" {
+		w.Key(&first, `"rid":`)
+		w.String(v.Rid)
+	}
+	if v.CodecPayloadType != 0 {
+		w.Key(&first, `"codecPayloadType":`)
+		w.Uint64(uint64(v.CodecPayloadType))
+	}
+	if v.Rtx != nil {
+		w.Key(&first, `"rtx":`)
+		if v.Rtx == nil {
+			w.RawString("null")
+		} else {
+			v.Rtx.encodeJSON(w)
+		}
+	}
+	if v.Dtx {
+		w.Key(&first, `"dtx":`)
+		w.Bool(v.Dtx)
+	}
+	if v.ScalabilityMode != "" {
+		w.Key(&first, `"scalabilityMode":`)
+		w.String(v.ScalabilityMode)
+	}
+	if v.ScaleResolutionDownBy != 0 {
+		w.Key(&first, `"scaleResolutionDownBy":`)
+		w.Int64(int64(v.ScaleResolutionDownBy))
+	}
+	if v.MaxBitrate != 0 {
+		w.Key(&first, `"maxBitrate":`)
+		w.Int64(int64(v.MaxBitrate))
+	}
+	w.RawByte('}')
+}
+
+func (v *RtpEncodingParameters) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtpEncodingParametersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtpEncodingParametersJSONKeys = []string{"ssrc", "rid", "codecPayloadType", "rtx", "dtx", "scalabilityMode", "scaleResolutionDownBy", "maxBitrate"}
+
+func (v *RtpEncodingParameters) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "ssrc":
+		if !l.IsNull() {
+			v.Ssrc = uint32(l.Uint(32))
+		}
+	case "rid":
+		if !l.IsNull() {
+			v.Rid = l.String()
+		}
+	case "codecPayloadType":
+		if !l.IsNull() {
+			v.CodecPayloadType = uint8(l.Uint(8))
+		}
+	case "rtx":
+		if l.IsNull() {
+			v.Rtx = nil
+		} else {
+			if v.Rtx == nil {
+				v.Rtx = new(RtpEncodingRtx)
+			}
+			v.Rtx.decodeJSON(l)
+		}
+	case "dtx":
+		if !l.IsNull() {
+			v.Dtx = l.Bool()
+		}
+	case "scalabilityMode":
+		if !l.IsNull() {
+			v.ScalabilityMode = l.String()
+		}
+	case "scaleResolutionDownBy":
+		if !l.IsNull() {
+			v.ScaleResolutionDownBy = int(l.Int(0))
+		}
+	case "maxBitrate":
+		if !l.IsNull() {
+			v.MaxBitrate = int(l.Int(0))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtpEncodingRtx) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtpEncodingRtx) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtpEncodingRtx) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"ssrc":`)
+	w.Uint64(uint64(v.Ssrc))
+	w.RawByte('}')
+}
+
+func (v *RtpEncodingRtx) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtpEncodingRtxJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtpEncodingRtxJSONKeys = []string{"ssrc"}
+
+func (v *RtpEncodingRtx) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "ssrc":
+		if !l.IsNull() {
+			v.Ssrc = uint32(l.Uint(32))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtpHeaderExtensionParameters) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtpHeaderExtensionParameters) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtpHeaderExtensionParameters) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"uri":`)
+	w.String(v.Uri)
+	w.Key(&first, `"id":`)
+	w.Int64(int64(v.Id))
+	if v.Encrypt {
+		w.Key(&first, `"encrypt":`)
+		w.Bool(v.Encrypt)
+	}
+	if v.Parameters != nil {
+		w.Key(&first, `"parameters":`)
+		w.Marshal(v.Parameters)
+	}
+	w.RawByte('}')
+}
+
+func (v *RtpHeaderExtensionParameters) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtpHeaderExtensionParametersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtpHeaderExtensionParametersJSONKeys = []string{"uri", "id", "encrypt", "parameters"}
+
+func (v *RtpHeaderExtensionParameters) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "uri":
+		if !l.IsNull() {
+			v.Uri = l.String()
+		}
+	case "id":
+		if !l.IsNull() {
+			v.Id = int(l.Int(0))
+		}
+	case "encrypt":
+		if !l.IsNull() {
+			v.Encrypt = l.Bool()
+		}
+	case "parameters":
+		l.Unmarshal(&v.Parameters)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v RtcpParameters) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *RtcpParameters) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *RtcpParameters) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Cname != "" {
+		w.Key(&first, `"cname":`)
+		w.String(v.Cname)
+	}
+	if v.ReducedSize != nil {
+		w.Key(&first, `"reducedSize":`)
+		if v.ReducedSize == nil {
+			w.RawString("null")
+		} else {
+			w.Bool(*v.ReducedSize)
+		}
+	}
+	if v.Mux != nil {
+		w.Key(&first, `"mux":`)
+		if v.Mux == nil {
+			w.RawString("null")
+		} else {
+			w.Bool(*v.Mux)
+		}
+	}
+	w.RawByte('}')
+}
+
+func (v *RtcpParameters) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, rtcpParametersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var rtcpParametersJSONKeys = []string{"cname", "reducedSize", "mux"}
+
+func (v *RtcpParameters) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "cname":
+		if !l.IsNull() {
+			v.Cname = l.String()
+		}
+	case "reducedSize":
+		if l.IsNull() {
+			v.ReducedSize = nil
+		} else {
+			if v.ReducedSize == nil {
+				v.ReducedSize = new(bool)
+			}
+			if !l.IsNull() {
+				*v.ReducedSize = l.Bool()
+			}
+		}
+	case "mux":
+		if l.IsNull() {
+			v.Mux = nil
+		} else {
+			if v.Mux == nil {
+				v.Mux = new(bool)
+			}
+			if !l.IsNull() {
+				*v.Mux = l.Bool()
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ProducerStat) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ProducerStat) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ProducerStat) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(v.Type)
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Int64(v.Timestamp)
+	}
+	if v.Ssrc != 0 {
+		w.Key(&first, `"ssrc":`)
+		w.Uint64(uint64(v.Ssrc))
+	}
+	if v.RtxSsrc != 0 {
+		w.Key(&first, `"rtxSsrc":`)
+		w.Uint64(uint64(v.RtxSsrc))
+	}
+	if v.Rid != "" {
+		w.Key(&first, `"rid":`)
+		w.String(v.Rid)
+	}
+	if v.Kind != "" {
+		w.Key(&first, `"kind":`)
+		w.String(v.Kind)
+	}
+	if v.MimeType != "" {
+		w.Key(&first, `"mimeType":`)
+		w.String(v.MimeType)
+	}
+	if v.PacketsLost != 0 {
+		w.Key(&first, `"packetsLost":`)
+		w.Uint64(uint64(v.PacketsLost))
+	}
+	if v.FractionLost != 0 {
+		w.Key(&first, `"fractionLost":`)
+		w.Uint64(uint64(v.FractionLost))
+	}
+	if v.PacketsDiscarded != 0 {
+		w.Key(&first, `"packetsDiscarded":`)
+		w.Uint64(uint64(v.PacketsDiscarded))
+	}
+	if v.PacketsRetransmitted != 0 {
+		w.Key(&first, `"packetsRetransmitted":`)
+		w.Uint64(uint64(v.PacketsRetransmitted))
+	}
+	if v.PacketsRepaired != 0 {
+		w.Key(&first, `"packetsRepaired":`)
+		w.Uint64(uint64(v.PacketsRepaired))
+	}
+	if v.NackCount != 0 {
+		w.Key(&first, `"nackCount":`)
+		w.Uint64(uint64(v.NackCount))
+	}
+	if v.NackPacketCount != 0 {
+		w.Key(&first, `"nackPacketCount":`)
+		w.Uint64(uint64(v.NackPacketCount))
+	}
+	if v.PliCount != 0 {
+		w.Key(&first, `"pliCount":`)
+		w.Uint64(uint64(v.PliCount))
+	}
+	if v.FirCount != 0 {
+		w.Key(&first, `"firCount":`)
+		w.Uint64(uint64(v.FirCount))
+	}
+	if v.Score != 0 {
+		w.Key(&first, `"score":`)
+		w.Uint64(uint64(v.Score))
+	}
+	if v.PacketCount != 0 {
+		w.Key(&first, `"packetCount":`)
+		w.Int64(v.PacketCount)
+	}
+	if v.ByteCount != 0 {
+		w.Key(&first, `"byteCount":`)
+		w.Int64(v.ByteCount)
+	}
+	if v.Bitrate != 0 {
+		w.Key(&first, `"bitrate":`)
+		w.Uint64(uint64(v.Bitrate))
+	}
+	if v.RoundTripTime != 0 {
+		w.Key(&first, `"roundTripTime":`)
+		w.Float(float64(v.RoundTripTime), 32)
+	}
+	if v.RtxPacketsDiscarded != 0 {
+		w.Key(&first, `"rtxPacketsDiscarded":`)
+		w.Uint64(uint64(v.RtxPacketsDiscarded))
+	}
+	if v.Jitter != 0 {
+		w.Key(&first, `"jitter":`)
+		w.Uint64(uint64(v.Jitter))
+	}
+	if len(v.BitrateByLayer) != 0 {
+		w.Key(&first, `"bitrateByLayer":`)
+		w.Marshal(v.BitrateByLayer)
+	}
+	w.RawByte('}')
+}
+
+func (v *ProducerStat) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, producerStatJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var producerStatJSONKeys = []string{"type", "timestamp", "ssrc", "rtxSsrc", "rid", "kind", "mimeType", "packetsLost", "fractionLost", "packetsDiscarded", "packetsRetransmitted", "packetsRepaired", "nackCount", "nackPacketCount", "pliCount", "firCount", "score", "packetCount", "byteCount", "bitrate", "roundTripTime", "rtxPacketsDiscarded", "jitter", "bitrateByLayer"}
+
+func (v *ProducerStat) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = l.String()
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "ssrc":
+		if !l.IsNull() {
+			v.Ssrc = uint32(l.Uint(32))
+		}
+	case "rtxSsrc":
+		if !l.IsNull() {
+			v.RtxSsrc = uint32(l.Uint(32))
+		}
+	case "rid":
+		if !l.IsNull() {
+			v.Rid = l.String()
+		}
+	case "kind":
+		if !l.IsNull() {
+			v.Kind = l.String()
+		}
+	case "mimeType":
+		if !l.IsNull() {
+			v.MimeType = l.String()
+		}
+	case "packetsLost":
+		if !l.IsNull() {
+			v.PacketsLost = uint32(l.Uint(32))
+		}
+	case "fractionLost":
+		if !l.IsNull() {
+			v.FractionLost = uint32(l.Uint(32))
+		}
+	case "packetsDiscarded":
+		if !l.IsNull() {
+			v.PacketsDiscarded = uint32(l.Uint(32))
+		}
+	case "packetsRetransmitted":
+		if !l.IsNull() {
+			v.PacketsRetransmitted = uint32(l.Uint(32))
+		}
+	case "packetsRepaired":
+		if !l.IsNull() {
+			v.PacketsRepaired = uint32(l.Uint(32))
+		}
+	case "nackCount":
+		if !l.IsNull() {
+			v.NackCount = uint32(l.Uint(32))
+		}
+	case "nackPacketCount":
+		if !l.IsNull() {
+			v.NackPacketCount = uint32(l.Uint(32))
+		}
+	case "pliCount":
+		if !l.IsNull() {
+			v.PliCount = uint32(l.Uint(32))
+		}
+	case "firCount":
+		if !l.IsNull() {
+			v.FirCount = uint32(l.Uint(32))
+		}
+	case "score":
+		if !l.IsNull() {
+			v.Score = uint32(l.Uint(32))
+		}
+	case "packetCount":
+		if !l.IsNull() {
+			v.PacketCount = l.Int(64)
+		}
+	case "byteCount":
+		if !l.IsNull() {
+			v.ByteCount = l.Int(64)
+		}
+	case "bitrate":
+		if !l.IsNull() {
+			v.Bitrate = uint32(l.Uint(32))
+		}
+	case "roundTripTime":
+		if !l.IsNull() {
+			v.RoundTripTime = float32(l.Float(32))
+		}
+	case "rtxPacketsDiscarded":
+		if !l.IsNull() {
+			v.RtxPacketsDiscarded = uint32(l.Uint(32))
+		}
+	case "jitter":
+		if !l.IsNull() {
+			v.Jitter = uint32(l.Uint(32))
+		}
+	case "bitrateByLayer":
+		l.Unmarshal(&v.BitrateByLayer)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v DataProducerStat) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *DataProducerStat) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *DataProducerStat) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"Type":`)
+	w.String(v.Type)
+	w.Key(&first, `"Timestamp":`)
+	w.Int64(v.Timestamp)
+	w.Key(&first, `"Label":`)
+	w.String(v.Label)
+	w.Key(&first, `"Protocol":`)
+	w.String(v.Protocol)
+	w.Key(&first, `"MessagesReceived":`)
+	w.Int64(v.MessagesReceived)
+	w.Key(&first, `"BytesReceived":`)
+	w.Int64(v.BytesReceived)
+	w.RawByte('}')
+}
+
+func (v *DataProducerStat) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, dataProducerStatJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var dataProducerStatJSONKeys = []string{"Type", "Timestamp", "Label", "Protocol", "MessagesReceived", "BytesReceived"}
+
+func (v *DataProducerStat) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "Type":
+		if !l.IsNull() {
+			v.Type = l.String()
+		}
+	case "Timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "Label":
+		if !l.IsNull() {
+			v.Label = l.String()
+		}
+	case "Protocol":
+		if !l.IsNull() {
+			v.Protocol = l.String()
+		}
+	case "MessagesReceived":
+		if !l.IsNull() {
+			v.MessagesReceived = l.Int(64)
+		}
+	case "BytesReceived":
+		if !l.IsNull() {
+			v.BytesReceived = l.Int(64)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v DataConsumerStat) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *DataConsumerStat) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *DataConsumerStat) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(v.Type)
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Int64(v.Timestamp)
+	}
+	if v.Label != "" {
+		w.Key(&first, `"label":`)
+		w.String(v.Label)
+	}
+	if v.Protocol != "" {
+		w.Key(&first, `"protocol":`)
+		w.String(v.Protocol)
+	}
+	if v.MessagesSent != 0 {
+		w.Key(&first, `"messagesSent":`)
+		w.Int64(v.MessagesSent)
+	}
+	if v.BytesSent != 0 {
+		w.Key(&first, `"bytesSent":`)
+		w.Int64(v.BytesSent)
+	}
+	if v.BufferedAmount != 0 {
+		w.Key(&first, `"bufferedAmount":`)
+		w.Uint64(uint64(v.BufferedAmount))
+	}
+	w.RawByte('}')
+}
+
+func (v *DataConsumerStat) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, dataConsumerStatJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var dataConsumerStatJSONKeys = []string{"type", "timestamp", "label", "protocol", "messagesSent", "bytesSent", "bufferedAmount"}
+
+func (v *DataConsumerStat) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = l.String()
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "label":
+		if !l.IsNull() {
+			v.Label = l.String()
+		}
+	case "protocol":
+		if !l.IsNull() {
+			v.Protocol = l.String()
+		}
+	case "messagesSent":
+		if !l.IsNull() {
+			v.MessagesSent = l.Int(64)
+		}
+	case "bytesSent":
+		if !l.IsNull() {
+			v.BytesSent = l.Int(64)
+		}
+	case "bufferedAmount":
+		if !l.IsNull() {
+			v.BufferedAmount = uint32(l.Uint(32))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v TransportStat) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *TransportStat) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *TransportStat) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(v.Type)
+	}
+	if v.TransportId != "" {
+		w.Key(&first, `"transportId":`)
+		w.String(v.TransportId)
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Int64(v.Timestamp)
+	}
+	if v.SctpState != "" {
+		w.Key(&first, `"sctpState":`)
+		w.String(string(v.SctpState))
+	}
+	if v.BytesReceived != 0 {
+		w.Key(&first, `"bytesReceived":`)
+		w.Int64(v.BytesReceived)
+	}
+	if v.RecvBitrate != 0 {
+		w.Key(&first, `"recvBitrate":`)
+		w.Int64(v.RecvBitrate)
+	}
+	if v.BytesSent != 0 {
+		w.Key(&first, `"bytesSent":`)
+		w.Int64(v.BytesSent)
+	}
+	if v.SendBitrate != 0 {
+		w.Key(&first, `"sendBitrate":`)
+		w.Int64(v.SendBitrate)
+	}
+	if v.RtpBytesReceived != 0 {
+		w.Key(&first, `"rtpBytesReceived":`)
+		w.Int64(v.RtpBytesReceived)
+	}
+	if v.RtpRecvBitrate != 0 {
+		w.Key(&first, `"rtpRecvBitrate":`)
+		w.Int64(v.RtpRecvBitrate)
+	}
+	if v.RtpBytesSent != 0 {
+		w.Key(&first, `"rtpBytesSent":`)
+		w.Int64(v.RtpBytesSent)
+	}
+	if v.RtpSendBitrate != 0 {
+		w.Key(&first, `"rtpSendBitrate":`)
+		w.Int64(v.RtpSendBitrate)
+	}
+	if v.RtxBytesReceived != 0 {
+		w.Key(&first, `"rtxBytesReceived":`)
+		w.Int64(v.RtxBytesReceived)
+	}
+	if v.RtxRecvBitrate != 0 {
+		w.Key(&first, `"rtxRecvBitrate":`)
+		w.Int64(v.RtxRecvBitrate)
+	}
+	if v.RtxBytesSent != 0 {
+		w.Key(&first, `"rtxBytesSent":`)
+		w.Int64(v.RtxBytesSent)
+	}
+	if v.RtxSendBitrate != 0 {
+		w.Key(&first, `"rtxSendBitrate":`)
+		w.Int64(v.RtxSendBitrate)
+	}
+	if v.ProbationBytesSent != 0 {
+		w.Key(&first, `"probationBytesSent":`)
+		w.Int64(v.ProbationBytesSent)
+	}
+	if v.ProbationSendBitrate != 0 {
+		w.Key(&first, `"probationSendBitrate":`)
+		w.Int64(v.ProbationSendBitrate)
+	}
+	if v.AvailableOutgoingBitrate != 0 {
+		w.Key(&first, `"availableOutgoingBitrate":`)
+		w.Int64(v.AvailableOutgoingBitrate)
+	}
+	if v.AvailableIncomingBitrate != 0 {
+		w.Key(&first, `"availableIncomingBitrate":`)
+		w.Int64(v.AvailableIncomingBitrate)
+	}
+	if v.MaxIncomingBitrate != 0 {
+		w.Key(&first, `"maxIncomingBitrate":`)
+		w.Int64(v.MaxIncomingBitrate)
+	}
+	if v.RtpPacketLossReceived != 0 {
+		w.Key(&first, `"rtpPacketLossReceived":`)
+		w.Float(v.RtpPacketLossReceived, 64)
+	}
+	if v.RtpPacketLossSent != 0 {
+		w.Key(&first, `"rtpPacketLossSent":`)
+		w.Float(v.RtpPacketLossSent, 64)
+	}
+	if v.WebRtcTransportSpecificStat != nil {
+		w.Key(&first, `"iceRole":`)
+		w.String(v.WebRtcTransportSpecificStat.IceRole)
+	}
+	if v.WebRtcTransportSpecificStat != nil {
+		w.Key(&first, `"iceState":`)
+		w.String(string(v.WebRtcTransportSpecificStat.IceState))
+	}
+	if v.WebRtcTransportSpecificStat != nil {
+		w.Key(&first, `"dtlsState":`)
+		w.String(string(v.WebRtcTransportSpecificStat.DtlsState))
+	}
+	if v.WebRtcTransportSpecificStat != nil && v.WebRtcTransportSpecificStat.IceSelectedTuple != nil {
+		w.Key(&first, `"iceSelectedTuple":`)
+		if v.WebRtcTransportSpecificStat.IceSelectedTuple == nil {
+			w.RawString("null")
+		} else {
+			v.WebRtcTransportSpecificStat.IceSelectedTuple.encodeJSON(w)
+		}
+	}
+	if v.PlainTransportSpecificStat != nil {
+		w.Key(&first, `"rtcp_mux":`)
+		w.Bool(v.PlainTransportSpecificStat.RtcpMux)
+	}
+	if v.PlainTransportSpecificStat != nil {
+		w.Key(&first, `"comedia":`)
+		w.Bool(v.PlainTransportSpecificStat.Comedia)
+	}
+	if v.PlainTransportSpecificStat != nil {
+		w.Key(&first, `"tuple":`)
+		v.PlainTransportSpecificStat.Tuple.encodeJSON(w)
+	}
+	if v.PlainTransportSpecificStat != nil && v.PlainTransportSpecificStat.RtcpTuple != nil {
+		w.Key(&first, `"rtcpTuple":`)
+		if v.PlainTransportSpecificStat.RtcpTuple == nil {
+			w.RawString("null")
+		} else {
+			v.PlainTransportSpecificStat.RtcpTuple.encodeJSON(w)
+		}
+	}
+	w.RawByte('}')
+}
+
+func (v *TransportStat) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, transportStatJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var transportStatJSONKeys = []string{"type", "transportId", "timestamp", "sctpState", "bytesReceived", "recvBitrate", "bytesSent", "sendBitrate", "rtpBytesReceived", "rtpRecvBitrate", "rtpBytesSent", "rtpSendBitrate", "rtxBytesReceived", "rtxRecvBitrate", "rtxBytesSent", "rtxSendBitrate", "probationBytesSent", "probationSendBitrate", "availableOutgoingBitrate", "availableIncomingBitrate", "maxIncomingBitrate", "rtpPacketLossReceived", "rtpPacketLossSent", "iceRole", "iceState", "dtlsState", "iceSelectedTuple", "rtcp_mux", "comedia", "tuple", "rtcpTuple"}
+
+func (v *TransportStat) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = l.String()
+		}
+	case "transportId":
+		if !l.IsNull() {
+			v.TransportId = l.String()
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "sctpState":
+		if !l.IsNull() {
+			v.SctpState = SctpState(l.String())
+		}
+	case "bytesReceived":
+		if !l.IsNull() {
+			v.BytesReceived = l.Int(64)
+		}
+	case "recvBitrate":
+		if !l.IsNull() {
+			v.RecvBitrate = l.Int(64)
+		}
+	case "bytesSent":
+		if !l.IsNull() {
+			v.BytesSent = l.Int(64)
+		}
+	case "sendBitrate":
+		if !l.IsNull() {
+			v.SendBitrate = l.Int(64)
+		}
+	case "rtpBytesReceived":
+		if !l.IsNull() {
+			v.RtpBytesReceived = l.Int(64)
+		}
+	case "rtpRecvBitrate":
+		if !l.IsNull() {
+			v.RtpRecvBitrate = l.Int(64)
+		}
+	case "rtpBytesSent":
+		if !l.IsNull() {
+			v.RtpBytesSent = l.Int(64)
+		}
+	case "rtpSendBitrate":
+		if !l.IsNull() {
+			v.RtpSendBitrate = l.Int(64)
+		}
+	case "rtxBytesReceived":
+		if !l.IsNull() {
+			v.RtxBytesReceived = l.Int(64)
+		}
+	case "rtxRecvBitrate":
+		if !l.IsNull() {
+			v.RtxRecvBitrate = l.Int(64)
+		}
+	case "rtxBytesSent":
+		if !l.IsNull() {
+			v.RtxBytesSent = l.Int(64)
+		}
+	case "rtxSendBitrate":
+		if !l.IsNull() {
+			v.RtxSendBitrate = l.Int(64)
+		}
+	case "probationBytesSent":
+		if !l.IsNull() {
+			v.ProbationBytesSent = l.Int(64)
+		}
+	case "probationSendBitrate":
+		if !l.IsNull() {
+			v.ProbationSendBitrate = l.Int(64)
+		}
+	case "availableOutgoingBitrate":
+		if !l.IsNull() {
+			v.AvailableOutgoingBitrate = l.Int(64)
+		}
+	case "availableIncomingBitrate":
+		if !l.IsNull() {
+			v.AvailableIncomingBitrate = l.Int(64)
+		}
+	case "maxIncomingBitrate":
+		if !l.IsNull() {
+			v.MaxIncomingBitrate = l.Int(64)
+		}
+	case "rtpPacketLossReceived":
+		if !l.IsNull() {
+			v.RtpPacketLossReceived = l.Float(64)
+		}
+	case "rtpPacketLossSent":
+		if !l.IsNull() {
+			v.RtpPacketLossSent = l.Float(64)
+		}
+	case "iceRole":
+		if v.WebRtcTransportSpecificStat == nil {
+			v.WebRtcTransportSpecificStat = new(WebRtcTransportSpecificStat)
+		}
+		if !l.IsNull() {
+			v.WebRtcTransportSpecificStat.IceRole = l.String()
+		}
+	case "iceState":
+		if v.WebRtcTransportSpecificStat == nil {
+			v.WebRtcTransportSpecificStat = new(WebRtcTransportSpecificStat)
+		}
+		if !l.IsNull() {
+			v.WebRtcTransportSpecificStat.IceState = IceState(l.String())
+		}
+	case "dtlsState":
+		if v.WebRtcTransportSpecificStat == nil {
+			v.WebRtcTransportSpecificStat = new(WebRtcTransportSpecificStat)
+		}
+		if !l.IsNull() {
+			v.WebRtcTransportSpecificStat.DtlsState = DtlsRole(l.String())
+		}
+	case "iceSelectedTuple":
+		if v.WebRtcTransportSpecificStat == nil {
+			v.WebRtcTransportSpecificStat = new(WebRtcTransportSpecificStat)
+		}
+		if l.IsNull() {
+			v.WebRtcTransportSpecificStat.IceSelectedTuple = nil
+		} else {
+			if v.WebRtcTransportSpecificStat.IceSelectedTuple == nil {
+				v.WebRtcTransportSpecificStat.IceSelectedTuple = new(TransportTuple)
+			}
+			v.WebRtcTransportSpecificStat.IceSelectedTuple.decodeJSON(l)
+		}
+	case "rtcp_mux":
+		if v.PlainTransportSpecificStat == nil {
+			v.PlainTransportSpecificStat = new(PlainTransportSpecificStat)
+		}
+		if !l.IsNull() {
+			v.PlainTransportSpecificStat.RtcpMux = l.Bool()
+		}
+	case "comedia":
+		if v.PlainTransportSpecificStat == nil {
+			v.PlainTransportSpecificStat = new(PlainTransportSpecificStat)
+		}
+		if !l.IsNull() {
+			v.PlainTransportSpecificStat.Comedia = l.Bool()
+		}
+	case "tuple":
+		if v.PlainTransportSpecificStat == nil {
+			v.PlainTransportSpecificStat = new(PlainTransportSpecificStat)
+		}
+		v.PlainTransportSpecificStat.Tuple.decodeJSON(l)
+	case "rtcpTuple":
+		if v.PlainTransportSpecificStat == nil {
+			v.PlainTransportSpecificStat = new(PlainTransportSpecificStat)
+		}
+		if l.IsNull() {
+			v.PlainTransportSpecificStat.RtcpTuple = nil
+		} else {
+			if v.PlainTransportSpecificStat.RtcpTuple == nil {
+				v.PlainTransportSpecificStat.RtcpTuple = new(TransportTuple)
+			}
+			v.PlainTransportSpecificStat.RtcpTuple.decodeJSON(l)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v TransportTuple) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *TransportTuple) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *TransportTuple) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.LocalIp != "" {
+		w.Key(&first, `"localIp":`)
+		w.String(v.LocalIp)
+	}
+	if v.LocalPort != 0 {
+		w.Key(&first, `"localPort":`)
+		w.Uint64(uint64(v.LocalPort))
+	}
+	if v.RemoteIp != "" {
+		w.Key(&first, `"remoteIp":`)
+		w.String(v.RemoteIp)
+	}
+	if v.RemotePort != 0 {
+		w.Key(&first, `"remotePort":`)
+		w.Uint64(uint64(v.RemotePort))
+	}
+	if v.Protocol != "" {
+		w.Key(&first, `"protocol":`)
+		w.String(v.Protocol)
+	}
+	w.RawByte('}')
+}
+
+func (v *TransportTuple) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, transportTupleJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var transportTupleJSONKeys = []string{"localIp", "localPort", "remoteIp", "remotePort", "protocol"}
+
+func (v *TransportTuple) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "localIp":
+		if !l.IsNull() {
+			v.LocalIp = l.String()
+		}
+	case "localPort":
+		if !l.IsNull() {
+			v.LocalPort = uint16(l.Uint(16))
+		}
+	case "remoteIp":
+		if !l.IsNull() {
+			v.RemoteIp = l.String()
+		}
+	case "remotePort":
+		if !l.IsNull() {
+			v.RemotePort = uint16(l.Uint(16))
+		}
+	case "protocol":
+		if !l.IsNull() {
+			v.Protocol = l.String()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ProducerScore) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ProducerScore) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ProducerScore) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Ssrc != 0 {
+		w.Key(&first, `"ssrc":`)
+		w.Uint64(uint64(v.Ssrc))
+	}
+	if v.Rid != "" {
+		w.Key(&first, `"rid":`)
+		w.String(v.Rid)
+	}
+	w.Key(&first, `"score":`)
+	w.Uint64(uint64(v.Score))
+	w.RawByte('}')
+}
+
+func (v *ProducerScore) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, producerScoreJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var producerScoreJSONKeys = []string{"ssrc", "rid", "score"}
+
+func (v *ProducerScore) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "ssrc":
+		if !l.IsNull() {
+			v.Ssrc = uint32(l.Uint(32))
+		}
+	case "rid":
+		if !l.IsNull() {
+			v.Rid = l.String()
+		}
+	case "score":
+		if !l.IsNull() {
+			v.Score = uint32(l.Uint(32))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ConsumerScore) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ConsumerScore) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ConsumerScore) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"score":`)
+	w.Uint64(uint64(v.Score))
+	w.Key(&first, `"producerScore":`)
+	w.Uint64(uint64(v.ProducerScore))
+	if len(v.ProducerScores) != 0 {
+		w.Key(&first, `"producerScores":`)
+		if v.ProducerScores == nil {
+			w.RawString("null")
+		} else {
+			w.RawByte('[')
+			for i1 := range v.ProducerScores {
+				if i1 > 0 {
+					w.RawByte(',')
+				}
+				w.Uint64(uint64(v.ProducerScores[i1]))
+			}
+			w.RawByte(']')
+		}
+	}
+	w.RawByte('}')
+}
+
+func (v *ConsumerScore) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, consumerScoreJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var consumerScoreJSONKeys = []string{"score", "producerScore", "producerScores"}
+
+func (v *ConsumerScore) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "score":
+		if !l.IsNull() {
+			v.Score = uint16(l.Uint(16))
+		}
+	case "producerScore":
+		if !l.IsNull() {
+			v.ProducerScore = uint16(l.Uint(16))
+		}
+	case "producerScores":
+		if l.IsNull() {
+			v.ProducerScores = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf(v.ProducerScores))
+		} else {
+			v.ProducerScores = v.ProducerScores[:0]
+			for !l.End(']') {
+				if v.ProducerScores == nil {
+					v.ProducerScores = make([]uint16, 0, 4)
+				}
+				var x1 uint16
+				if !l.IsNull() {
+					x1 = uint16(l.Uint(16))
+				}
+				v.ProducerScores = append(v.ProducerScores, x1)
+				l.Comma()
+			}
+			if v.ProducerScores == nil {
+				v.ProducerScores = []uint16{}
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ConsumerLayers) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ConsumerLayers) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ConsumerLayers) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	w.Key(&first, `"spatialLayer":`)
+	w.Uint64(uint64(v.SpatialLayer))
+	w.Key(&first, `"temporalLayer":`)
+	w.Uint64(uint64(v.TemporalLayer))
+	w.RawByte('}')
+}
+
+func (v *ConsumerLayers) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, consumerLayersJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var consumerLayersJSONKeys = []string{"spatialLayer", "temporalLayer"}
+
+func (v *ConsumerLayers) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "spatialLayer":
+		if !l.IsNull() {
+			v.SpatialLayer = uint8(l.Uint(8))
+		}
+	case "temporalLayer":
+		if !l.IsNull() {
+			v.TemporalLayer = uint8(l.Uint(8))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ProducerVideoOrientation) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ProducerVideoOrientation) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ProducerVideoOrientation) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Camera {
+		w.Key(&first, `"Camera":`)
+		w.Bool(v.Camera)
+	}
+	if v.Flip {
+		w.Key(&first, `"flip":`)
+		w.Bool(v.Flip)
+	}
+	w.Key(&first, `"rotation":`)
+	w.Uint64(uint64(v.Rotation))
+	w.RawByte('}')
+}
+
+func (v *ProducerVideoOrientation) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, producerVideoOrientationJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var producerVideoOrientationJSONKeys = []string{"Camera", "flip", "rotation"}
+
+func (v *ProducerVideoOrientation) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "Camera":
+		if !l.IsNull() {
+			v.Camera = l.Bool()
+		}
+	case "flip":
+		if !l.IsNull() {
+			v.Flip = l.Bool()
+		}
+	case "rotation":
+		if !l.IsNull() {
+			v.Rotation = uint32(l.Uint(32))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ProducerTraceEventData) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ProducerTraceEventData) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ProducerTraceEventData) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(string(v.Type))
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Uint64(uint64(v.Timestamp))
+	}
+	if v.Direction != "" {
+		w.Key(&first, `"direction":`)
+		w.String(v.Direction)
+	}
+	if len(v.Info) != 0 {
+		w.Key(&first, `"info":`)
+		w.Marshal(v.Info)
+	}
+	w.RawByte('}')
+}
+
+func (v *ProducerTraceEventData) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, producerTraceEventDataJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var producerTraceEventDataJSONKeys = []string{"type", "timestamp", "direction", "info"}
+
+func (v *ProducerTraceEventData) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = ProducerTraceEventType(l.String())
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = uint32(l.Uint(32))
+		}
+	case "direction":
+		if !l.IsNull() {
+			v.Direction = l.String()
+		}
+	case "info":
+		l.Unmarshal(&v.Info)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ConsumerTraceEventData) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *ConsumerTraceEventData) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *ConsumerTraceEventData) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(string(v.Type))
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Int64(v.Timestamp)
+	}
+	if v.Direction != "" {
+		w.Key(&first, `"direction":`)
+		w.String(v.Direction)
+	}
+	if len(v.Info) != 0 {
+		w.Key(&first, `"info":`)
+		w.Marshal(v.Info)
+	}
+	w.RawByte('}')
+}
+
+func (v *ConsumerTraceEventData) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, consumerTraceEventDataJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var consumerTraceEventDataJSONKeys = []string{"type", "timestamp", "direction", "info"}
+
+func (v *ConsumerTraceEventData) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = ConsumerTraceEventType(l.String())
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "direction":
+		if !l.IsNull() {
+			v.Direction = l.String()
+		}
+	case "info":
+		l.Unmarshal(&v.Info)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v TransportTraceEventData) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *TransportTraceEventData) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *TransportTraceEventData) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Type != "" {
+		w.Key(&first, `"type":`)
+		w.String(string(v.Type))
+	}
+	if v.Timestamp != 0 {
+		w.Key(&first, `"timestamp":`)
+		w.Int64(v.Timestamp)
+	}
+	if v.Direction != "" {
+		w.Key(&first, `"direction":`)
+		w.String(v.Direction)
+	}
+	if v.Info != nil {
+		w.Key(&first, `"info":`)
+		w.Marshal(v.Info)
+	}
+	w.RawByte('}')
+}
+
+func (v *TransportTraceEventData) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, transportTraceEventDataJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var transportTraceEventDataJSONKeys = []string{"type", "timestamp", "direction", "info"}
+
+func (v *TransportTraceEventData) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "type":
+		if !l.IsNull() {
+			v.Type = TransportTraceEventType(l.String())
+		}
+	case "timestamp":
+		if !l.IsNull() {
+			v.Timestamp = l.Int(64)
+		}
+	case "direction":
+		if !l.IsNull() {
+			v.Direction = l.String()
+		}
+	case "info":
+		l.Unmarshal(&v.Info)
+	default:
+		return false
+	}
+	return true
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v channelMessage) MarshalJSON() ([]byte, error) {
+	w := jsonx.Writer{Buf: make([]byte, 0, 256)}
+	v.encodeJSON(&w)
+	return w.BuildBytes()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *channelMessage) UnmarshalJSON(data []byte) error {
+	l := jsonx.Lexer{Data: data}
+	v.decodeJSON(&l)
+	l.Done()
+	return l.Error()
+}
+
+func (v *channelMessage) encodeJSON(w *jsonx.Writer) {
+	first := true
+	w.RawByte('{')
+	if v.Id != 0 {
+		w.Key(&first, `"id":`)
+		w.Int64(v.Id)
+	}
+	if v.Accepted {
+		w.Key(&first, `"accepted":`)
+		w.Bool(v.Accepted)
+	}
+	if v.Error != "" {
+		w.Key(&first, `"error":`)
+		w.String(v.Error)
+	}
+	if v.Reason != "" {
+		w.Key(&first, `"reason":`)
+		w.String(v.Reason)
+	}
+	if v.TargetId != "" {
+		w.Key(&first, `"targetId":`)
+		w.String(v.TargetId)
+	}
+	if v.Event != "" {
+		w.Key(&first, `"event":`)
+		w.String(v.Event)
+	}
+	if len(v.Data) != 0 {
+		w.Key(&first, `"data":`)
+		w.Marshal(v.Data)
+	}
+	w.RawByte('}')
+}
+
+func (v *channelMessage) decodeJSON(l *jsonx.Lexer) {
+	if l.IsNull() {
+		return
+	}
+	if !l.Object() {
+		l.TypeError(reflect.TypeOf(v).Elem())
+		return
+	}
+	for !l.End('}') {
+		key := l.Key()
+		if !v.decodeJSONField(l, key) && !v.decodeJSONField(l, jsonx.FoldKey(key, channelMessageJSONKeys...)) {
+			l.Skip()
+		}
+		l.Comma()
+	}
+}
+
+var channelMessageJSONKeys = []string{"id", "accepted", "error", "reason", "targetId", "event", "data"}
+
+func (v *channelMessage) decodeJSONField(l *jsonx.Lexer, key []byte) bool {
+	switch string(key) {
+	case "id":
+		if !l.IsNull() {
+			v.Id = l.Int(64)
+		}
+	case "accepted":
+		if !l.IsNull() {
+			v.Accepted = l.Bool()
+		}
+	case "error":
+		if !l.IsNull() {
+			v.Error = l.String()
+		}
+	case "reason":
+		if !l.IsNull() {
+			v.Reason = l.String()
+		}
+	case "targetId":
+		if !l.IsNull() {
+			v.TargetId = l.String()
+		}
+	case "event":
+		if !l.IsNull() {
+			v.Event = l.String()
+		}
+	case "data":
+		v.Data = l.RawCopy()
+	default:
+		return false
+	}
+	return true
+}
+
+// decodeGeneratedJSON decodes data into v if it points to a slice with a
+// generated decoder, returning false otherwise.
+func decodeGeneratedJSON(data []byte, v interface{}) (bool, error) {
+	l := &jsonx.Lexer{Data: data}
+
+	switch v := v.(type) {
+	case *[]*ProducerStat:
+		if l.IsNull() {
+			(*v) = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf((*v)))
+		} else {
+			(*v) = (*v)[:0]
+			for !l.End(']') {
+				if (*v) == nil {
+					(*v) = make([]*ProducerStat, 0, 4)
+				}
+				var x1 *ProducerStat
+				if l.IsNull() {
+					x1 = nil
+				} else {
+					if x1 == nil {
+						x1 = new(ProducerStat)
+					}
+					x1.decodeJSON(l)
+				}
+				(*v) = append((*v), x1)
+				l.Comma()
+			}
+			if (*v) == nil {
+				(*v) = []*ProducerStat{}
+			}
+		}
+	case *[]*DataProducerStat:
+		if l.IsNull() {
+			(*v) = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf((*v)))
+		} else {
+			(*v) = (*v)[:0]
+			for !l.End(']') {
+				if (*v) == nil {
+					(*v) = make([]*DataProducerStat, 0, 4)
+				}
+				var x1 *DataProducerStat
+				if l.IsNull() {
+					x1 = nil
+				} else {
+					if x1 == nil {
+						x1 = new(DataProducerStat)
+					}
+					x1.decodeJSON(l)
+				}
+				(*v) = append((*v), x1)
+				l.Comma()
+			}
+			if (*v) == nil {
+				(*v) = []*DataProducerStat{}
+			}
+		}
+	case *[]*DataConsumerStat:
+		if l.IsNull() {
+			(*v) = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf((*v)))
+		} else {
+			(*v) = (*v)[:0]
+			for !l.End(']') {
+				if (*v) == nil {
+					(*v) = make([]*DataConsumerStat, 0, 4)
+				}
+				var x1 *DataConsumerStat
+				if l.IsNull() {
+					x1 = nil
+				} else {
+					if x1 == nil {
+						x1 = new(DataConsumerStat)
+					}
+					x1.decodeJSON(l)
+				}
+				(*v) = append((*v), x1)
+				l.Comma()
+			}
+			if (*v) == nil {
+				(*v) = []*DataConsumerStat{}
+			}
+		}
+	case *[]*TransportStat:
+		if l.IsNull() {
+			(*v) = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf((*v)))
+		} else {
+			(*v) = (*v)[:0]
+			for !l.End(']') {
+				if (*v) == nil {
+					(*v) = make([]*TransportStat, 0, 4)
+				}
+				var x1 *TransportStat
+				if l.IsNull() {
+					x1 = nil
+				} else {
+					if x1 == nil {
+						x1 = new(TransportStat)
+					}
+					x1.decodeJSON(l)
+				}
+				(*v) = append((*v), x1)
+				l.Comma()
+			}
+			if (*v) == nil {
+				(*v) = []*TransportStat{}
+			}
+		}
+	case *[]ProducerScore:
+		if l.IsNull() {
+			(*v) = nil
+		} else if !l.Array() {
+			l.TypeError(reflect.TypeOf((*v)))
+		} else {
+			(*v) = (*v)[:0]
+			for !l.End(']') {
+				if (*v) == nil {
+					(*v) = make([]ProducerScore, 0, 4)
+				}
+				var x1 ProducerScore
+				x1.decodeJSON(l)
+				(*v) = append((*v), x1)
+				l.Comma()
+			}
+			if (*v) == nil {
+				(*v) = []ProducerScore{}
+			}
+		}
+	default:
+		return false, nil
+	}
+
+	l.Done()
+	return true, l.Error()
+}
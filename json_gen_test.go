@@ -0,0 +1,317 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The generated marshalers are checked against encoding/json working on a
+// copy of the type without methods. The benchmarks decode as the channels do,
+// run them with the jsongen tag to measure encoding/json alone:
+//
+//	go test -run XXX -bench JSON
+//	go test -run XXX -bench JSON -tags jsongen
+
+// plainJSON returns a pointer to a copy of the struct v whose type has the same
+// fields but no methods, so that encoding/json uses reflection on it.
+func plainJSON(v interface{}) interface{} {
+	t := reflect.TypeOf(v)
+	fields := make([]reflect.StructField, t.NumField())
+
+	for i := range fields {
+		fields[i] = t.Field(i)
+	}
+	plain := reflect.New(reflect.StructOf(fields))
+	plain.Elem().Set(reflect.ValueOf(v).Convert(plain.Elem().Type()))
+
+	return plain.Interface()
+}
+
+func TestGeneratedJSON_Marshal(t *testing.T) {
+	yes := true
+
+	for _, v := range []interface{}{
+		RtpParameters{},
+		RtpParameters{
+			Mid: "0",
+			Codecs: []*RtpCodecParameters{
+				{
+					MimeType:     "video/VP8",
+					PayloadType:  101,
+					ClockRate:    90000,
+					RtcpFeedback: []RtcpFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}},
+				},
+				{
+					MimeType:    "video/rtx",
+					PayloadType: 102,
+					ClockRate:   90000,
+					Parameters:  RtpCodecSpecificParameters{{Key: "apt", Value: 101}},
+				},
+				nil,
+			},
+			HeaderExtensions: []RtpHeaderExtensionParameters{
+				{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+				{Uri: "urn:3gpp:video-orientation", Id: 4, Encrypt: true, Parameters: &RtpCodecSpecificParameters{}},
+			},
+			Encodings: []RtpEncodingParameters{
+				{Ssrc: 1111, Rtx: &RtpEncodingRtx{Ssrc: 2222}, ScalabilityMode: "L1T3", MaxBitrate: 500000},
+				{Rid: "r1", Dtx: true, ScaleResolutionDownBy: 2},
+			},
+			Rtcp: RtcpParameters{Cname: "<cname & \"quotes\">", ReducedSize: &yes},
+		},
+		ProducerStat{
+			Type:          "inbound-rtp",
+			Timestamp:     1234567890123,
+			Ssrc:          1111,
+			Kind:          "video",
+			MimeType:      "video/VP8",
+			FractionLost:  2,
+			Score:         10,
+			ByteCount:     123456789,
+			RoundTripTime: 12.345,
+			BitrateByLayer: H{
+				"0.0": 100000,
+			},
+		},
+		DataProducerStat{Type: "data-producer", Label: "chat", MessagesReceived: 3},
+		DataConsumerStat{Type: "data-consumer", BufferedAmount: 1024},
+		TransportStat{Type: "webrtc-transport", TransportId: "id", RtpPacketLossSent: 0.0001},
+		TransportStat{
+			Type: "webrtc-transport",
+			WebRtcTransportSpecificStat: &WebRtcTransportSpecificStat{
+				IceRole:          "controlled",
+				IceState:         IceState_Completed,
+				IceSelectedTuple: &TransportTuple{LocalIp: "127.0.0.1", LocalPort: 40000, Protocol: "udp"},
+			},
+			PlainTransportSpecificStat: &PlainTransportSpecificStat{RtcpMux: true},
+		},
+		ProducerScore{Ssrc: 1111, Score: 9},
+		ConsumerScore{Score: 10, ProducerScore: 0, ProducerScores: []uint16{0, 10}},
+		ConsumerLayers{SpatialLayer: 2, TemporalLayer: 1},
+		ProducerVideoOrientation{Camera: true, Rotation: 90},
+		ProducerTraceEventData{Type: ProducerTraceEventType_Rtp, Timestamp: 1, Direction: "in", Info: H{"a": "b"}},
+		ConsumerTraceEventData{Type: ConsumerTraceEventType_Pli, Direction: "out"},
+		TransportTraceEventData{Type: TransportTraceEventType_Bwe, Info: []interface{}{1, "2"}},
+		channelMessage{TargetId: "id", Event: "score", Data: json.RawMessage(`[{"ssrc":1,"score":10}]`)},
+	} {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		expected, err := json.Marshal(plainJSON(v))
+		require.NoError(t, err)
+
+		assert.Equal(t, string(expected), string(data), "%T", v)
+	}
+}
+
+func TestGeneratedJSON_Unmarshal(t *testing.T) {
+	for _, c := range []struct {
+		value interface{}
+		data  string
+	}{
+		{
+			value: RtpParameters{},
+			data: `{
+				"mid": "1",
+				"codecs": [
+					{"mimeType": "audio/opus", "payloadType": 100, "clockRate": 48000, "channels": 2,
+					 "parameters": {"useinbandfec": 1, "unknown": "x"}, "rtcpFeedback": []},
+					null
+				],
+				"headerExtensions": null,
+				"encodings": [{"ssrc": 1234, "rtx": {"ssrc": 5678}, "dtx": true}],
+				"rtcp": {"cname": "cé\"name", "reducedSize": false, "mux": null},
+				"unknown": {"nested": [1, 2, {"a": null}]}
+			}`,
+		},
+		{
+			value: ProducerStat{},
+			data: `{"type":"outbound-rtp","timestamp":1600000000000,"ssrc":1,"rtxSsrc":2,"score":10,
+				"roundTripTime":1.5,"jitter":3,"bitrateByLayer":{"0.0":1000}}`,
+		},
+		{
+			// Untagged fields, matched case-insensitively.
+			value: DataProducerStat{},
+			data:  `{"type":"data-producer","timestamp":1,"label":"chat","protocol":"","messagesReceived":10,"BYTESRECEIVED":100}`,
+		},
+		{
+			value: TransportStat{},
+			data: `{"type":"webrtc-transport","transportId":"t","iceRole":"controlled","iceState":"connected",
+				"iceSelectedTuple":{"localIp":"1.2.3.4","localPort":1000,"protocol":"tcp"},"rtcp_mux":true,
+				"rtpPacketLossReceived":0.25}`,
+		},
+		{
+			value: TransportStat{},
+			data:  `{"type":"direct-transport","bytesReceived":1000}`,
+		},
+		{
+			value: ConsumerScore{},
+			data:  `{"score":10,"producerScore":9,"producerScores":[9,0,10]}`,
+		},
+		{
+			value: ProducerTraceEventData{},
+			data:  `{"type":"keyframe","timestamp":100,"direction":"in","info":{"isKeyFrame":true,"payloadType":101}}`,
+		},
+		{
+			value: channelMessage{},
+			data:  `{"id":12,"accepted":true,"data":{"dtlsState":"connected"}}`,
+		},
+		{
+			value: channelMessage{},
+			data:  `{"targetId":"id","event":"trace","data":null}`,
+		},
+	} {
+		t.Run(reflect.TypeOf(c.value).Name(), func(t *testing.T) {
+			v := reflect.New(reflect.TypeOf(c.value))
+			require.NoError(t, json.Unmarshal([]byte(c.data), v.Interface()))
+
+			plain := plainJSON(c.value)
+			require.NoError(t, json.Unmarshal([]byte(c.data), plain))
+
+			expected := reflect.ValueOf(plain).Elem().Convert(v.Elem().Type()).Interface()
+
+			assert.Equal(t, expected, v.Elem().Interface())
+		})
+	}
+}
+
+func TestGeneratedJSON_DecodeSlices(t *testing.T) {
+	for _, c := range []struct {
+		value func() interface{}
+		data  string
+	}{
+		{func() interface{} { return &[]*ProducerStat{} }, `[{"type":"inbound-rtp","ssrc":1},null,{"type":"rtx","score":9}]`},
+		{func() interface{} { return &[]*DataProducerStat{} }, `[]`},
+		{func() interface{} { return &[]*DataConsumerStat{} }, `null`},
+		{func() interface{} { return &[]*TransportStat{} }, `[{"type":"pipe-transport","tuple":{"localIp":"127.0.0.1"}}]`},
+		{func() interface{} { return &[]ProducerScore{} }, `[{"ssrc":1,"score":10},{"rid":"r1","score":0}]`},
+	} {
+		v := c.value()
+		require.NoError(t, unmarshalJSON([]byte(c.data), v))
+
+		expected := c.value()
+		require.NoError(t, json.Unmarshal([]byte(c.data), expected))
+
+		assert.Equal(t, expected, v, c.data)
+	}
+
+	var scores []ProducerScore
+
+	assert.Error(t, unmarshalJSON([]byte(`{"ssrc":1}`), &scores))
+	assert.Error(t, unmarshalJSON([]byte(`[{"ssrc":1}`), &scores))
+}
+
+func TestGeneratedJSON_UnmarshalTypeError(t *testing.T) {
+	var score ProducerScore
+
+	err := json.Unmarshal([]byte(`{"ssrc":"1","rid":"r","score":1.5}`), &score)
+
+	assert.IsType(t, &json.UnmarshalTypeError{}, err)
+	assert.Equal(t, "r", score.Rid)
+
+	var layers ConsumerLayers
+
+	assert.Error(t, json.Unmarshal([]byte(`{"spatialLayer":256}`), &layers))
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &layers))
+	assert.Error(t, json.Unmarshal([]byte(`{"spatialLayer":1`), &layers))
+	assert.Error(t, json.Unmarshal([]byte(`{"spatialLayer":1} x`), &layers))
+}
+
+var (
+	benchmarkRtpParameters = []byte(`{"mid":"0","codecs":[{"mimeType":"video/VP8","payloadType":101,"clockRate":90000,` +
+		`"rtcpFeedback":[{"type":"nack"},{"type":"nack","parameter":"pli"},{"type":"ccm","parameter":"fir"},{"type":"goog-remb"}]},` +
+		`{"mimeType":"video/rtx","payloadType":102,"clockRate":90000,"parameters":{"apt":101}}],` +
+		`"headerExtensions":[{"uri":"urn:ietf:params:rtp-hdrext:sdes:mid","id":1},` +
+		`{"uri":"http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time","id":4},{"uri":"urn:3gpp:video-orientation","id":11}],` +
+		`"encodings":[{"ssrc":1111,"rtx":{"ssrc":2222},"scalabilityMode":"L1T3"},{"ssrc":3333,"rtx":{"ssrc":4444},"scalabilityMode":"L1T3"}],` +
+		`"rtcp":{"cname":"jsongen","reducedSize":true}}`)
+
+	benchmarkProducerStats = []byte(`[{"type":"inbound-rtp","timestamp":1600000000000,"ssrc":1111,"rtxSsrc":2222,"kind":"video",` +
+		`"mimeType":"video/VP8","packetsLost":2,"fractionLost":1,"packetsDiscarded":0,"packetsRetransmitted":10,"packetsRepaired":2,` +
+		`"nackCount":3,"nackPacketCount":12,"pliCount":1,"firCount":0,"score":10,"packetCount":12000,"byteCount":11000000,` +
+		`"bitrate":1200000,"roundTripTime":12.5,"jitter":30},` +
+		`{"type":"inbound-rtp","timestamp":1600000000000,"ssrc":3333,"rtxSsrc":4444,"kind":"video","mimeType":"video/VP8",` +
+		`"packetsLost":0,"score":9,"packetCount":6000,"byteCount":3000000,"bitrate":300000,"jitter":12}]`)
+
+	benchmarkTransportStats = []byte(`[{"type":"webrtc-transport","transportId":"5d5b2f3c-1a8e-4f0f-9f51-f2a1f6f6c4d2",` +
+		`"timestamp":1600000000000,"sctpState":"connected","bytesReceived":12000000,"recvBitrate":1500000,"bytesSent":500000,` +
+		`"sendBitrate":60000,"rtpBytesReceived":11000000,"rtpRecvBitrate":1400000,"rtpBytesSent":400000,"rtpSendBitrate":50000,` +
+		`"availableOutgoingBitrate":3000000,"maxIncomingBitrate":5000000,"rtpPacketLossReceived":0.01,` +
+		`"iceRole":"controlled","iceState":"completed","dtlsState":"connected",` +
+		`"iceSelectedTuple":{"localIp":"10.0.0.1","localPort":40000,"remoteIp":"10.0.0.2","remotePort":50000,"protocol":"udp"}}]`)
+
+	benchmarkNotification = []byte(`{"targetId":"5d5b2f3c-1a8e-4f0f-9f51-f2a1f6f6c4d2","event":"score",` +
+		`"data":{"score":10,"producerScore":10,"producerScores":[10,9,10]}}`)
+
+	benchmarkTraceEvent = []byte(`{"type":"rtp","timestamp":1600000000,"direction":"in","info":{"ssrc":1111,"payloadType":101}}`)
+)
+
+func benchmarkUnmarshal(b *testing.B, data []byte, v func() interface{}) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		if err := unmarshalJSON(data, v()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkMarshal(b *testing.B, data []byte, v interface{}) {
+	if err := json.Unmarshal(data, v); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSON_UnmarshalRtpParameters(b *testing.B) {
+	benchmarkUnmarshal(b, benchmarkRtpParameters, func() interface{} { return &RtpParameters{} })
+}
+
+func BenchmarkJSON_MarshalRtpParameters(b *testing.B) {
+	benchmarkMarshal(b, benchmarkRtpParameters, &RtpParameters{})
+}
+
+func BenchmarkJSON_UnmarshalProducerStats(b *testing.B) {
+	benchmarkUnmarshal(b, benchmarkProducerStats, func() interface{} { return &[]*ProducerStat{} })
+}
+
+func BenchmarkJSON_MarshalProducerStats(b *testing.B) {
+	benchmarkMarshal(b, benchmarkProducerStats, &[]*ProducerStat{})
+}
+
+func BenchmarkJSON_UnmarshalTransportStats(b *testing.B) {
+	benchmarkUnmarshal(b, benchmarkTransportStats, func() interface{} { return &[]*TransportStat{} })
+}
+
+func BenchmarkJSON_UnmarshalNotification(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkNotification)))
+
+	for i := 0; i < b.N; i++ {
+		var msg channelMessage
+		var score ConsumerScore
+
+		if err := unmarshalJSON(benchmarkNotification, &msg); err != nil {
+			b.Fatal(err)
+		}
+		if err := unmarshalJSON(msg.Data, &score); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSON_UnmarshalTraceEvent(b *testing.B) {
+	benchmarkUnmarshal(b, benchmarkTraceEvent, func() interface{} { return &ProducerTraceEventData{} })
+}
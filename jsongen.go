@@ -0,0 +1,55 @@
+//go:build jsongen
+// +build jsongen
+
+package mediasoup
+
+//go:generate go run -tags jsongen ./internal/cmd/jsongen -o json_gen.go
+
+/**
+ * Types given generated JSON marshalers (see json_gen.go), for the messages
+ * exchanged with the worker at a high rate, and slices of them given
+ * generated decoders. Built with the jsongen tag only, when running the
+ * generator.
+ */
+var JSONGenTypes = []interface{}{
+	// RTP parameters.
+	RtpParameters{},
+	RtpCodecParameters{},
+	RtcpFeedback{},
+	RtpEncodingParameters{},
+	RtpEncodingRtx{},
+	RtpHeaderExtensionParameters{},
+	RtcpParameters{},
+
+	// Stats.
+	ProducerStat{},
+	DataProducerStat{},
+	DataConsumerStat{},
+	TransportStat{},
+	TransportTuple{},
+
+	// Notification payloads.
+	ProducerScore{},
+	ConsumerScore{},
+	ConsumerLayers{},
+	ProducerVideoOrientation{},
+	ProducerTraceEventData{},
+	ConsumerTraceEventData{},
+	TransportTraceEventData{},
+
+	// Channel messages.
+	channelMessage{},
+
+	// Responses and notifications.
+	[]*ProducerStat(nil),
+	[]*DataProducerStat(nil),
+	[]*DataConsumerStat(nil),
+	[]*TransportStat(nil),
+	[]ProducerScore(nil),
+}
+
+// decodeGeneratedJSON is generated in json_gen.go, which is not built with the
+// jsongen tag.
+func decodeGeneratedJSON(data []byte, v interface{}) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,498 @@
+// Package loadtest measures the capacity of a host: it spawns workers and
+// Routers, creates synthetic Producers sending RTP through DirectTransports
+// and thousands of Consumers sending it to a local UDP sink, then reports the
+// latency of the requests to the workers and their CPU and memory usage:
+//
+//	report, err := loadtest.Run(ctx, loadtest.Options{
+//		Workers:              4,
+//		ProducersPerRouter:   10,
+//		ConsumersPerProducer: 50,
+//		Duration:             time.Minute,
+//	})
+//	report.Print(os.Stdout)
+//
+// The examples/loadtest command runs it from the command line.
+package loadtest
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type TransportType string
+
+const (
+	PlainTransport TransportType = "plain"
+	PipeTransport  TransportType = "pipe"
+)
+
+type Options struct {
+	/**
+	 * Number of workers. Default 1.
+	 */
+	Workers int
+
+	/**
+	 * Options of the workers.
+	 */
+	WorkerOptions []mediasoup.Option
+
+	/**
+	 * Number of Routers per worker. Default 1.
+	 */
+	RoutersPerWorker int
+
+	/**
+	 * Number of synthetic Producers per Router. Default 1.
+	 */
+	ProducersPerRouter int
+
+	/**
+	 * Kind of the Producers, Opus audio or VP8 video. Default video.
+	 */
+	ProducerKind mediasoup.MediaKind
+
+	/**
+	 * Number of Consumers per Producer. Default 10.
+	 */
+	ConsumersPerProducer int
+
+	/**
+	 * Type of the transports of the Consumers. Default PlainTransport.
+	 */
+	ConsumerTransport TransportType
+
+	/**
+	 * Number of Consumers per transport. Default 100.
+	 */
+	ConsumersPerTransport int
+
+	/**
+	 * RTP packets sent per second by every Producer. Default 50 for audio,
+	 * 100 for video.
+	 */
+	PacketRate int
+
+	/**
+	 * Size of the RTP payloads. Default 100 bytes for audio, 1000 for video.
+	 */
+	PayloadSize int
+
+	/**
+	 * Duration of the test once every Consumer is created. Default 30
+	 * seconds.
+	 */
+	Duration time.Duration
+
+	/**
+	 * Interval between two samples of the workers. Default 1 second.
+	 */
+	SampleInterval time.Duration
+
+	/**
+	 * Number of requests sent in parallel while creating the entities.
+	 * Default 16.
+	 */
+	Concurrency int
+
+	/**
+	 * Logger of the test. Default mediasoup.NewLogger("LoadTest").
+	 */
+	Logger mediasoup.Logger
+}
+
+var mediaCodecs = []*mediasoup.RtpCodecCapability{
+	{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	{Kind: mediasoup.MediaKind_Video, MimeType: "video/VP8", ClockRate: 90000},
+}
+
+// test is a running load test.
+type test struct {
+	options   Options
+	logger    mediasoup.Logger
+	latencies latencyRecorder
+	workers   []*mediasoup.Worker
+	producers []*producer
+	consumers int64
+	sink      *net.UDPConn
+	received  int64
+}
+
+/**
+ * Run the load test, closing the workers once done. The test is stopped when
+ * the context is done, the report being returned with the context error.
+ */
+func Run(ctx context.Context, options Options) (report *Report, err error) {
+	if options.ConsumerTransport != "" && options.ConsumerTransport != PlainTransport &&
+		options.ConsumerTransport != PipeTransport {
+		return nil, mediasoup.NewTypeError("invalid ConsumerTransport %q", options.ConsumerTransport)
+	}
+	if options.ProducerKind != "" && options.ProducerKind != mediasoup.MediaKind_Audio &&
+		options.ProducerKind != mediasoup.MediaKind_Video {
+		return nil, mediasoup.NewTypeError("invalid ProducerKind %q", options.ProducerKind)
+	}
+	options = withDefaults(options)
+
+	t := &test{options: options, logger: options.Logger}
+
+	if t.sink, err = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}); err != nil {
+		return
+	}
+	defer t.sink.Close()
+
+	go t.drainSink()
+
+	defer func() {
+		for _, producer := range t.producers {
+			producer.stop()
+		}
+		for _, worker := range t.workers {
+			worker.Close()
+		}
+	}()
+
+	report = &Report{Options: options}
+	start := time.Now()
+
+	err = t.setup(ctx)
+
+	report.SetupDuration = time.Since(start)
+	report.Workers = len(t.workers)
+	report.Producers = len(t.producers)
+	report.Consumers = int(atomic.LoadInt64(&t.consumers))
+
+	for _, worker := range t.workers {
+		report.Routers += len(worker.Routers())
+	}
+	if err != nil {
+		report.RequestLatency = t.latencies.stats()
+		return
+	}
+
+	t.logger.Info("setup done in %s, running for %s", report.SetupDuration, options.Duration)
+
+	err = t.run(ctx, report)
+
+	report.RequestLatency = t.latencies.stats()
+	report.PacketsReceived = atomic.LoadInt64(&t.received)
+
+	for _, producer := range t.producers {
+		report.PacketsSent += producer.sent()
+	}
+
+	return
+}
+
+func withDefaults(options Options) Options {
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.RoutersPerWorker <= 0 {
+		options.RoutersPerWorker = 1
+	}
+	if options.ProducersPerRouter <= 0 {
+		options.ProducersPerRouter = 1
+	}
+	if options.ProducerKind == "" {
+		options.ProducerKind = mediasoup.MediaKind_Video
+	}
+	if options.ConsumersPerProducer <= 0 {
+		options.ConsumersPerProducer = 10
+	}
+	if options.ConsumerTransport == "" {
+		options.ConsumerTransport = PlainTransport
+	}
+	if options.ConsumersPerTransport <= 0 {
+		options.ConsumersPerTransport = 100
+	}
+	if options.PacketRate <= 0 {
+		options.PacketRate = 100
+		if options.ProducerKind == mediasoup.MediaKind_Audio {
+			options.PacketRate = 50
+		}
+	}
+	if options.PayloadSize <= 0 {
+		options.PayloadSize = 1000
+		if options.ProducerKind == mediasoup.MediaKind_Audio {
+			options.PayloadSize = 100
+		}
+	}
+	if options.Duration <= 0 {
+		options.Duration = 30 * time.Second
+	}
+	if options.SampleInterval <= 0 {
+		options.SampleInterval = time.Second
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 16
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("LoadTest")
+	}
+
+	return options
+}
+
+// drainSink counts the packets sent by the Consumers.
+func (t *test) drainSink() {
+	buf := make([]byte, 1500)
+
+	for {
+		if _, _, err := t.sink.ReadFromUDP(buf); err != nil {
+			return
+		}
+		atomic.AddInt64(&t.received, 1)
+	}
+}
+
+// timed runs the request, recording its latency.
+func (t *test) timed(request func() error) error {
+	start := time.Now()
+	err := request()
+
+	if err == nil {
+		t.latencies.record(time.Since(start))
+	}
+	return err
+}
+
+func (t *test) setup(ctx context.Context) (err error) {
+	options := t.options
+
+	for i := 0; i < options.Workers; i++ {
+		var worker *mediasoup.Worker
+
+		if worker, err = mediasoup.NewWorker(options.WorkerOptions...); err != nil {
+			return
+		}
+		t.workers = append(t.workers, worker)
+
+		for j := 0; j < options.RoutersPerWorker; j++ {
+			var router *mediasoup.Router
+
+			err = t.timed(func() (err error) {
+				router, err = worker.CreateRouter(mediasoup.RouterOptions{MediaCodecs: mediaCodecs})
+				return
+			})
+			if err != nil {
+				return
+			}
+			if err = t.setupRouter(ctx, router); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// setupRouter creates the Producers of the Router and their Consumers.
+func (t *test) setupRouter(ctx context.Context, router *mediasoup.Router) (err error) {
+	options := t.options
+
+	var directTransport *mediasoup.DirectTransport
+
+	err = t.timed(func() (err error) {
+		directTransport, err = router.CreateDirectTransport()
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	var producers []*producer
+
+	for i := 0; i < options.ProducersPerRouter; i++ {
+		var p *producer
+
+		err = t.timed(func() (err error) {
+			p, err = newProducer(directTransport, options.ProducerKind, options.PayloadSize)
+			return
+		})
+		if err != nil {
+			return
+		}
+		producers = append(producers, p)
+		t.producers = append(t.producers, p)
+	}
+
+	// Consumers of every Producer, grouped in transports.
+	total := options.ProducersPerRouter * options.ConsumersPerProducer
+	transports := (total + options.ConsumersPerTransport - 1) / options.ConsumersPerTransport
+	semaphore := make(chan struct{}, options.Concurrency)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for i := 0; i < transports; i++ {
+		first := i * options.ConsumersPerTransport
+		count := options.ConsumersPerTransport
+
+		if first+count > total {
+			count = total - first
+		}
+
+		var transport mediasoup.ITransport
+
+		if transport, err = t.createConsumerTransport(router); err != nil {
+			return
+		}
+
+		for j := first; j < first+count; j++ {
+			if err = ctx.Err(); err != nil {
+				wg.Wait()
+				return
+			}
+
+			p := producers[j/options.ConsumersPerProducer]
+			semaphore <- struct{}{}
+			wg.Add(1)
+
+			go func() {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+
+				err := t.timed(func() error {
+					_, err := transport.Consume(mediasoup.ConsumerOptions{
+						ProducerId:      p.producer.Id(),
+						RtpCapabilities: router.RtpCapabilities(),
+					})
+					return err
+				})
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+				atomic.AddInt64(&t.consumers, 1)
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+// createConsumerTransport creates a transport sending to the sink.
+func (t *test) createConsumerTransport(router *mediasoup.Router) (transport mediasoup.ITransport, err error) {
+	listenIp := mediasoup.TransportListenIp{Ip: "127.0.0.1"}
+
+	err = t.timed(func() (err error) {
+		if t.options.ConsumerTransport == PipeTransport {
+			transport, err = router.CreatePipeTransport(mediasoup.PipeTransportOptions{ListenIp: listenIp})
+		} else {
+			transport, err = router.CreatePlainTransport(mediasoup.PlainTransportOptions{
+				ListenIp: listenIp,
+				RtcpMux:  mediasoup.Bool(true),
+			})
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	sink := t.sink.LocalAddr().(*net.UDPAddr)
+
+	err = t.timed(func() error {
+		return transport.Connect(mediasoup.TransportConnectOptions{Ip: sink.IP.String(), Port: uint16(sink.Port)})
+	})
+
+	return
+}
+
+// run sends the RTP of the Producers and samples the workers until the end of
+// the test.
+func (t *test) run(ctx context.Context, report *Report) (err error) {
+	interval := time.Second / time.Duration(t.options.PacketRate)
+
+	for _, producer := range t.producers {
+		go producer.run(interval, t.options.PacketRate)
+	}
+
+	ticker := time.NewTicker(t.options.SampleInterval)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(t.options.Duration)
+	defer timer.Stop()
+
+	previous := t.sample(time.Now(), nil)
+
+	for {
+		select {
+		case now := <-ticker.C:
+			previous = t.sample(now, previous)
+			report.Samples = append(report.Samples, previous)
+
+		case <-timer.C:
+			report.WorkerStats = t.workerStats()
+			return nil
+
+		case <-ctx.Done():
+			report.WorkerStats = t.workerStats()
+			return ctx.Err()
+		}
+	}
+}
+
+// sample measures the workers, the CPU usage being computed since the previous
+// sample.
+func (t *test) sample(now time.Time, previous *Sample) *Sample {
+	sample := &Sample{
+		Time:            now,
+		Workers:         make([]WorkerSample, len(t.workers)),
+		PacketsReceived: atomic.LoadInt64(&t.received),
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	sample.HeapAlloc = memStats.HeapAlloc
+
+	for i, worker := range t.workers {
+		var usage mediasoup.WorkerResourceUsage
+
+		err := t.timed(func() (err error) {
+			usage, err = worker.GetResourceUsage()
+			return
+		})
+		if err != nil {
+			t.logger.Warn("getting resource usage of worker %d failed: %v", worker.Pid(), err)
+			continue
+		}
+
+		workerSample := WorkerSample{
+			Pid:       worker.Pid(),
+			CpuTime:   time.Duration(usage.RU_Utime+usage.RU_Stime) * time.Millisecond,
+			MaxRssKiB: usage.RU_Maxrss,
+		}
+
+		if previous != nil && i < len(previous.Workers) && now.After(previous.Time) {
+			cpuTime := workerSample.CpuTime - previous.Workers[i].CpuTime
+			workerSample.CpuUsage = float64(cpuTime) / float64(now.Sub(previous.Time))
+		}
+		sample.Workers[i] = workerSample
+	}
+
+	return sample
+}
+
+func (t *test) workerStats() []WorkerStats {
+	stats := make([]WorkerStats, len(t.workers))
+
+	for i, worker := range t.workers {
+		stats[i] = WorkerStats{Pid: worker.Pid(), IPC: worker.IPCStats()}
+	}
+
+	return stats
+}
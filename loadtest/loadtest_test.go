@@ -0,0 +1,97 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_InvalidOptions(t *testing.T) {
+	_, err := Run(context.Background(), Options{ConsumerTransport: "webrtc"})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = Run(context.Background(), Options{ProducerKind: "data"})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestWithDefaults(t *testing.T) {
+	options := withDefaults(Options{ProducerKind: mediasoup.MediaKind_Audio})
+
+	assert.Equal(t, 1, options.Workers)
+	assert.Equal(t, PlainTransport, options.ConsumerTransport)
+	assert.Equal(t, 50, options.PacketRate)
+	assert.Equal(t, 100, options.PayloadSize)
+	assert.Equal(t, 30*time.Second, options.Duration)
+}
+
+func TestLatencyStats(t *testing.T) {
+	var recorder latencyRecorder
+
+	assert.Equal(t, LatencyStats{}, recorder.stats())
+
+	for i := 100; i >= 1; i-- {
+		recorder.record(time.Duration(i) * time.Millisecond)
+	}
+
+	stats := recorder.stats()
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 50500*time.Microsecond, stats.Mean)
+	assert.Equal(t, 50*time.Millisecond, stats.P50)
+	assert.Equal(t, 95*time.Millisecond, stats.P95)
+	assert.Equal(t, 99*time.Millisecond, stats.P99)
+	assert.Equal(t, 100*time.Millisecond, stats.Max)
+}
+
+func TestRtpPacket(t *testing.T) {
+	packet := rtpPacket(mediasoup.MediaKind_Video, 1234, 7, 9000, true, 1000)
+
+	assert.Len(t, packet, 1012)
+	assert.EqualValues(t, 0x80|101, packet[1])
+	assert.EqualValues(t, 7, binary.BigEndian.Uint16(packet[2:]))
+	assert.EqualValues(t, 9000, binary.BigEndian.Uint32(packet[4:]))
+	assert.EqualValues(t, 1234, binary.BigEndian.Uint32(packet[8:]))
+
+	// Keyframe.
+	payload := packet[12:]
+	assert.EqualValues(t, 0x10, payload[0])
+	assert.Zero(t, payload[1]&0x01)
+	assert.Equal(t, []byte{0x9d, 0x01, 0x2a}, payload[4:7])
+	assert.EqualValues(t, 640, binary.LittleEndian.Uint16(payload[7:]))
+
+	// Inter frame.
+	payload = rtpPacket(mediasoup.MediaKind_Video, 1234, 8, 9900, false, 1000)[12:]
+	assert.EqualValues(t, 1, payload[1]&0x01)
+
+	packet = rtpPacket(mediasoup.MediaKind_Audio, 1234, 7, 960, false, 100)
+	assert.Len(t, packet, 112)
+	assert.EqualValues(t, 0x80|100, packet[1])
+}
+
+func TestReport_Print(t *testing.T) {
+	report := &Report{
+		Workers:   1,
+		Routers:   2,
+		Producers: 3,
+		Consumers: 30,
+		Samples: []*Sample{
+			{Workers: []WorkerSample{{Pid: 42, CpuUsage: 0.2, MaxRssKiB: 1000}}},
+			{Workers: []WorkerSample{{Pid: 42, CpuUsage: 0.4, MaxRssKiB: 2000}}},
+		},
+		WorkerStats: []WorkerStats{{Pid: 42}},
+	}
+
+	usages := report.MeanCpuUsage()
+	assert.Len(t, usages, 1)
+	assert.InDelta(t, 0.3, usages[0], 1e-9)
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+
+	assert.Contains(t, buf.String(), "producers: 3, consumers: 30")
+	assert.Contains(t, buf.String(), "worker 42: cpu 30.0%, max rss 2000 KiB")
+}
@@ -0,0 +1,124 @@
+package loadtest
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// producer sends synthetic RTP packets through a DirectTransport.
+type producer struct {
+	producer    *mediasoup.Producer
+	kind        mediasoup.MediaKind
+	ssrc        uint32
+	payloadSize int
+	packets     int64
+	stopOnce    sync.Once
+	stopCh      chan struct{}
+}
+
+func newProducer(transport *mediasoup.DirectTransport, kind mediasoup.MediaKind, payloadSize int) (p *producer, err error) {
+	p = &producer{
+		kind:        kind,
+		ssrc:        rand.Uint32(),
+		payloadSize: payloadSize,
+		stopCh:      make(chan struct{}),
+	}
+
+	codec := &mediasoup.RtpCodecParameters{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000}
+
+	if kind == mediasoup.MediaKind_Audio {
+		codec = &mediasoup.RtpCodecParameters{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}
+	}
+
+	p.producer, err = transport.Produce(mediasoup.ProducerOptions{
+		Kind: kind,
+		RtpParameters: mediasoup.RtpParameters{
+			Codecs:    []*mediasoup.RtpCodecParameters{codec},
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: p.ssrc}},
+		},
+	})
+
+	return
+}
+
+// run sends the packets at the given interval until stopped, a video
+// keyframe being sent every second.
+func (p *producer) run(interval time.Duration, packetRate int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	clockRate := uint32(90000)
+
+	if p.kind == mediasoup.MediaKind_Audio {
+		clockRate = 48000
+	}
+
+	var seq uint16
+	var timestamp uint32
+	step := clockRate / uint32(packetRate)
+
+	for {
+		select {
+		case <-ticker.C:
+			keyframe := p.kind == mediasoup.MediaKind_Video && int(seq)%packetRate == 0
+			packet := rtpPacket(p.kind, p.ssrc, seq, timestamp, keyframe, p.payloadSize)
+
+			if err := p.producer.Send(packet); err != nil {
+				return
+			}
+			atomic.AddInt64(&p.packets, 1)
+
+			seq++
+			timestamp += step
+
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *producer) sent() int64 {
+	return atomic.LoadInt64(&p.packets)
+}
+
+func (p *producer) stop() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+// rtpPacket builds a packet carrying a whole frame (marker bit set), a VP8
+// payload starting with a payload descriptor and a frame header.
+func rtpPacket(kind mediasoup.MediaKind, ssrc uint32, seq uint16, timestamp uint32, keyframe bool, payloadSize int) []byte {
+	packet := make([]byte, 12+payloadSize)
+	packet[0] = 0x80
+	packet[1] = 0x80 | 100
+	binary.BigEndian.PutUint16(packet[2:], seq)
+	binary.BigEndian.PutUint32(packet[4:], timestamp)
+	binary.BigEndian.PutUint32(packet[8:], ssrc)
+
+	payload := packet[12:]
+
+	if kind == mediasoup.MediaKind_Video {
+		packet[1] = 0x80 | 101
+
+		if len(payload) >= 11 {
+			// Start of partition 0.
+			payload[0] = 0x10
+			// Frame header: show frame, inter frame unless keyframe.
+			payload[1] = 0x10 | 0x01
+
+			if keyframe {
+				payload[1] &^= 0x01
+				copy(payload[4:], []byte{0x9d, 0x01, 0x2a})
+				binary.LittleEndian.PutUint16(payload[7:], 640)
+				binary.LittleEndian.PutUint16(payload[9:], 360)
+			}
+		}
+	}
+
+	return packet
+}
@@ -0,0 +1,204 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Report of a load test.
+ */
+type Report struct {
+	/**
+	 * Options of the test, with their defaults.
+	 */
+	Options Options `json:"-"`
+
+	Workers   int `json:"workers"`
+	Routers   int `json:"routers"`
+	Producers int `json:"producers"`
+	Consumers int `json:"consumers"`
+
+	/**
+	 * Time taken to create the workers, Routers, Producers and Consumers.
+	 */
+	SetupDuration time.Duration `json:"setupDuration"`
+
+	/**
+	 * Latency of the requests to the workers, during the setup and the
+	 * sampling.
+	 */
+	RequestLatency LatencyStats `json:"requestLatency"`
+
+	/**
+	 * RTP packets sent by the Producers and received from the Consumers.
+	 */
+	PacketsSent     int64 `json:"packetsSent"`
+	PacketsReceived int64 `json:"packetsReceived"`
+
+	Samples     []*Sample     `json:"samples"`
+	WorkerStats []WorkerStats `json:"workerStats"`
+}
+
+/**
+ * Distribution of latencies.
+ */
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+/**
+ * Sample of the workers during the test.
+ */
+type Sample struct {
+	Time    time.Time      `json:"time"`
+	Workers []WorkerSample `json:"workers"`
+
+	/**
+	 * Packets received from the Consumers since the start of the test.
+	 */
+	PacketsReceived int64 `json:"packetsReceived"`
+
+	/**
+	 * Heap of the Go process.
+	 */
+	HeapAlloc uint64 `json:"heapAlloc"`
+}
+
+type WorkerSample struct {
+	Pid int `json:"pid"`
+
+	/**
+	 * CPU time used since the start of the worker.
+	 */
+	CpuTime time.Duration `json:"cpuTime"`
+
+	/**
+	 * CPU used since the previous sample, 1 being a full core.
+	 */
+	CpuUsage float64 `json:"cpuUsage"`
+
+	/**
+	 * Maximum resident set size, in KiB.
+	 */
+	MaxRssKiB int64 `json:"maxRssKiB"`
+}
+
+/**
+ * State of the communication with a worker at the end of the test.
+ */
+type WorkerStats struct {
+	Pid int                      `json:"pid"`
+	IPC mediasoup.WorkerIPCStats `json:"ipc"`
+}
+
+/**
+ * Mean CPU usage of every worker over the samples.
+ */
+func (r *Report) MeanCpuUsage() []float64 {
+	if len(r.Samples) == 0 {
+		return nil
+	}
+
+	usages := make([]float64, len(r.Samples[0].Workers))
+
+	for _, sample := range r.Samples {
+		for i, worker := range sample.Workers {
+			if i < len(usages) {
+				usages[i] += worker.CpuUsage / float64(len(r.Samples))
+			}
+		}
+	}
+
+	return usages
+}
+
+/**
+ * Print a summary of the report.
+ */
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "workers: %d, routers: %d, producers: %d, consumers: %d\n",
+		r.Workers, r.Routers, r.Producers, r.Consumers)
+	fmt.Fprintf(w, "setup: %s\n", r.SetupDuration.Round(time.Millisecond))
+
+	l := r.RequestLatency
+	fmt.Fprintf(w, "request latency (%d requests): mean %s, p50 %s, p95 %s, p99 %s, max %s\n",
+		l.Count, l.Mean, l.P50, l.P95, l.P99, l.Max)
+	fmt.Fprintf(w, "packets: %d sent, %d received\n", r.PacketsSent, r.PacketsReceived)
+
+	usages := r.MeanCpuUsage()
+
+	for i, stats := range r.WorkerStats {
+		var cpu float64
+		var maxRss int64
+
+		if i < len(usages) {
+			cpu = usages[i]
+		}
+		if len(r.Samples) > 0 && i < len(r.Samples[len(r.Samples)-1].Workers) {
+			maxRss = r.Samples[len(r.Samples)-1].Workers[i].MaxRssKiB
+		}
+
+		fmt.Fprintf(w, "worker %d: cpu %.1f%%, max rss %d KiB, channel dispatch mean %s max %s, dropped %d\n",
+			stats.Pid, cpu*100, maxRss, stats.IPC.Channel.MeanDispatchLatency, stats.IPC.Channel.MaxDispatchLatency,
+			stats.IPC.Channel.DroppedMessages+stats.IPC.PayloadChannel.DroppedMessages)
+	}
+}
+
+// latencyRecorder records the latencies of the requests.
+type latencyRecorder struct {
+	locker    sync.Mutex
+	latencies []time.Duration
+}
+
+func (r *latencyRecorder) record(latency time.Duration) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+}
+
+func (r *latencyRecorder) stats() LatencyStats {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return latencyStats(r.latencies)
+}
+
+func latencyStats(latencies []time.Duration) (stats LatencyStats) {
+	if len(latencies) == 0 {
+		return
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	percentile := func(p int) time.Duration {
+		return sorted[(len(sorted)-1)*p/100]
+	}
+
+	return LatencyStats{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(50),
+		P95:   percentile(95),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
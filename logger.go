@@ -1,10 +1,15 @@
 package mediasoup
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gobwas/glob"
@@ -27,8 +32,19 @@ const (
 var (
 	// DefaultLevel defines default log level.
 	DefaultLevel = DebugLevel
-	// NewLogger defines function to create logger instance.
-	NewLogger = newDefaultLogger
+	// RedactSecrets enables the redaction of ICE passwords, SRTP keys and
+	// private keys in the logs of the entities.
+	RedactSecrets = true
+	// NewLogger defines function to create logger instance. Replace it to
+	// integrate with the logging stack of the application, e.g.:
+	//
+	//   mediasoup.NewLogger = func(scope string) mediasoup.Logger {
+	//     return mediasoup.NewSlogLogger(slog.Default()).With("scope", scope)
+	//   }
+	//
+	// The default one uses the Config.NewLogger if any, which SetConfig() can
+	// change at any time.
+	NewLogger = newConfiguredLogger
 	// NewLoggerWriter defines function to create logger writer.
 	NewLoggerWriter = func() io.Writer {
 		writer := zerolog.ConsoleWriter{
@@ -61,6 +77,15 @@ type Logger interface {
 	Info(format string, v ...interface{})
 	Warn(format string, v ...interface{})
 	Error(format string, v ...interface{})
+	// With returns a Logger that adds the given key/value pairs (e.g.
+	// "routerId", id) to every log line.
+	With(keyvals ...interface{}) Logger
+}
+
+// levelLogger is implemented by the Loggers able to tell whether they discard
+// the logs of a level, to avoid formatting them.
+type levelLogger interface {
+	enabled(level zerolog.Level) bool
 }
 
 type defaultLogger struct {
@@ -68,6 +93,17 @@ type defaultLogger struct {
 	debug  bool
 }
 
+/**
+ * Create a Logger writing to the given zerolog Logger. Debug logs are not
+ * filtered by the DEBUG environment variable, but by the level of the logger.
+ */
+func NewZerologLogger(logger zerolog.Logger) Logger {
+	return &defaultLogger{
+		logger: logger,
+		debug:  true,
+	}
+}
+
 func newDefaultLogger(scope string) Logger {
 	shouldDebug := false
 
@@ -109,6 +145,13 @@ func (l defaultLogger) Debug(format string, v ...interface{}) {
 	}
 }
 
+func (l defaultLogger) enabled(level zerolog.Level) bool {
+	if level == zerolog.DebugLevel && !l.debug {
+		return false
+	}
+	return level >= l.logger.GetLevel() && level >= zerolog.GlobalLevel()
+}
+
 func (l defaultLogger) Info(format string, v ...interface{}) {
 	l.logger.Info().Msgf(format, v...)
 }
@@ -120,3 +163,221 @@ func (l defaultLogger) Warn(format string, v ...interface{}) {
 func (l defaultLogger) Error(format string, v ...interface{}) {
 	l.logger.Error().Msgf(format, v...)
 }
+
+func (l defaultLogger) With(keyvals ...interface{}) Logger {
+	context := l.logger.With()
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		context = context.Interface(fmt.Sprint(keyvals[i]), keyvals[i+1])
+	}
+
+	return &defaultLogger{
+		logger: context.Logger(),
+		debug:  l.debug,
+	}
+}
+
+// loggerContext is given by each entity to the entities it creates, so their
+// loggers share the same base logger and fields.
+type loggerContext struct {
+	// Base logger given by the application, if any.
+	base Logger
+	// Key/value pairs added to every log line.
+	fields []interface{}
+	// Labels of the Worker, added to every log line and observer event.
+	labels map[string]string
+}
+
+// with returns a copy of the context with the given base logger, if not nil,
+// and the given key/value pairs added.
+func (c loggerContext) with(base Logger, keyvals ...interface{}) loggerContext {
+	if base != nil {
+		c.base = base
+	}
+	c.fields = append(append([]interface{}{}, c.fields...), keyvals...)
+
+	return c
+}
+
+// withLabels returns a copy of the context with the given labels, added to the
+// key/value pairs sorted by key.
+func (c loggerContext) withLabels(labels map[string]string) loggerContext {
+	if len(labels) == 0 {
+		return c
+	}
+	keys := make([]string, 0, len(labels))
+	c.labels = make(map[string]string, len(labels))
+
+	for key, value := range labels {
+		keys = append(keys, key)
+		c.labels[key] = value
+	}
+	sort.Strings(keys)
+
+	keyvals := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		keyvals = append(keyvals, key, labels[key])
+	}
+
+	return c.with(nil, keyvals...)
+}
+
+// newLogger creates a logger for the given scope.
+func (c loggerContext) newLogger(scope string) Logger {
+	var logger Logger
+
+	if c.base != nil {
+		logger = c.base.With("scope", scope)
+	} else {
+		logger = NewLogger(scope)
+	}
+
+	return entityLogger{logger: logger, scope: scope}.With(c.fields...)
+}
+
+var (
+	// Overridden log levels by scope or entity id.
+	logLevels sync.Map
+	// Number of overridden log levels.
+	logLevelsCount int32
+)
+
+/**
+ * Override at runtime the level of the logs of the entities with the given
+ * scope (e.g. "Producer", "WebRtcTransport") or with the given id in their
+ * context (e.g. a router id applies to every entity of the Router, a producer
+ * id to its Consumers too). The most specific id takes precedence, then the
+ * scope. Logs filtered by the underlying logger are not enabled.
+ */
+func SetLogLevel(scopeOrId string, level zerolog.Level) {
+	if _, loaded := logLevels.LoadOrStore(scopeOrId, level); loaded {
+		logLevels.Store(scopeOrId, level)
+	} else {
+		atomic.AddInt32(&logLevelsCount, 1)
+	}
+}
+
+/**
+ * Remove the log level override of the given scope or id.
+ */
+func ResetLogLevel(scopeOrId string) {
+	if _, loaded := logLevels.LoadAndDelete(scopeOrId); loaded {
+		atomic.AddInt32(&logLevelsCount, -1)
+	}
+}
+
+// entityLogger is the logger of an entity, which applies the overridden log
+// levels and redacts secrets.
+type entityLogger struct {
+	logger Logger
+	scope  string
+	// Entity ids of the context, from the less to the most specific.
+	ids []string
+}
+
+func (l entityLogger) Debug(format string, v ...interface{}) {
+	if l.enabled(DebugLevel) {
+		l.logger.Debug("%s", l.message(format, v))
+	}
+}
+
+func (l entityLogger) Info(format string, v ...interface{}) {
+	if l.enabled(InfoLevel) {
+		l.logger.Info("%s", l.message(format, v))
+	}
+}
+
+func (l entityLogger) Warn(format string, v ...interface{}) {
+	if l.enabled(WarnLevel) {
+		l.logger.Warn("%s", l.message(format, v))
+	}
+}
+
+func (l entityLogger) Error(format string, v ...interface{}) {
+	if l.enabled(ErrorLevel) {
+		l.logger.Error("%s", l.message(format, v))
+	}
+}
+
+func (l entityLogger) With(keyvals ...interface{}) Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+
+	ids := append([]string{}, l.ids...)
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key := fmt.Sprint(keyvals[i]); strings.HasSuffix(key, "Id") || strings.HasSuffix(key, "Pid") {
+			ids = append(ids, fmt.Sprint(keyvals[i+1]))
+		}
+	}
+
+	return entityLogger{
+		logger: l.logger.With(keyvals...),
+		scope:  l.scope,
+		ids:    ids,
+	}
+}
+
+func (l entityLogger) enabled(level zerolog.Level) bool {
+	if logger, ok := l.logger.(levelLogger); ok && !logger.enabled(level) {
+		return false
+	}
+	if atomic.LoadInt32(&logLevelsCount) == 0 {
+		return true
+	}
+	for i := len(l.ids) - 1; i >= 0; i-- {
+		if value, ok := logLevels.Load(l.ids[i]); ok {
+			return level >= value.(zerolog.Level)
+		}
+	}
+	if value, ok := logLevels.Load(l.scope); ok {
+		return level >= value.(zerolog.Level)
+	}
+
+	return true
+}
+
+func (l entityLogger) message(format string, v []interface{}) string {
+	message := fmt.Sprintf(format, v...)
+
+	if RedactSecrets {
+		message = redactSecrets(message)
+	}
+
+	return message
+}
+
+var (
+	secretHints = []string{"assword", "eyBase64", "PRIVATE KEY", "ice-pwd", "inline:"}
+
+	secretPatterns = []struct {
+		regexp      *regexp.Regexp
+		replacement string
+	}{
+		// JSON payloads (iceParameters, srtpParameters).
+		{regexp.MustCompile(`("(?:password|keyBase64)"\s*:\s*)"[^"]*"`), `$1"[REDACTED]"`},
+		// Go values printed with %+v.
+		{regexp.MustCompile(`((?:Password|KeyBase64):)[^\s}]+`), `${1}[REDACTED]`},
+		// SDP.
+		{regexp.MustCompile(`(a=ice-pwd:)\S+`), `${1}[REDACTED]`},
+		{regexp.MustCompile(`(inline:)[A-Za-z0-9+/=]+`), `${1}[REDACTED]`},
+		// PEM private keys.
+		{regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`), `[REDACTED PRIVATE KEY]`},
+	}
+)
+
+// redactSecrets replaces the ICE passwords, SRTP keys and private keys in the
+// given text.
+func redactSecrets(text string) string {
+	for _, hint := range secretHints {
+		if strings.Contains(text, hint) {
+			for _, pattern := range secretPatterns {
+				text = pattern.regexp.ReplaceAllString(text, pattern.replacement)
+			}
+			break
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,65 @@
+//go:build go1.21
+// +build go1.21
+
+package mediasoup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+/**
+ * Create a Logger writing to the given slog Logger.
+ */
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Debug(format string, v ...interface{}) {
+	l.log(slog.LevelDebug, format, v...)
+}
+
+func (l slogLogger) Info(format string, v ...interface{}) {
+	l.log(slog.LevelInfo, format, v...)
+}
+
+func (l slogLogger) Warn(format string, v ...interface{}) {
+	l.log(slog.LevelWarn, format, v...)
+}
+
+func (l slogLogger) Error(format string, v ...interface{}) {
+	l.log(slog.LevelError, format, v...)
+}
+
+func (l slogLogger) With(keyvals ...interface{}) Logger {
+	return slogLogger{logger: l.logger.With(keyvals...)}
+}
+
+func (l slogLogger) enabled(level zerolog.Level) bool {
+	slogLevel := slog.LevelDebug
+
+	switch {
+	case level >= zerolog.ErrorLevel:
+		slogLevel = slog.LevelError
+	case level == zerolog.WarnLevel:
+		slogLevel = slog.LevelWarn
+	case level == zerolog.InfoLevel:
+		slogLevel = slog.LevelInfo
+	}
+
+	return l.logger.Enabled(context.Background(), slogLevel)
+}
+
+func (l slogLogger) log(level slog.Level, format string, v ...interface{}) {
+	// Avoid formatting the message if it is discarded.
+	if l.logger.Enabled(context.Background(), level) {
+		l.logger.Log(context.Background(), level, fmt.Sprintf(format, v...))
+	}
+}
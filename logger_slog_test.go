@@ -0,0 +1,30 @@
+//go:build go1.21
+// +build go1.21
+
+package mediasoup
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := NewSlogLogger(slog.New(handler)).With("routerId", "r1")
+
+	logger.Debug("discarded")
+	logger.Warn("hello %d", 1)
+
+	var line map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "hello 1", line["msg"])
+	assert.Equal(t, "r1", line["routerId"])
+}
@@ -0,0 +1,356 @@
+package mediasouptest
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Network conditions applied by an ImpairedLink to the packets going in one
+ * direction. The random decisions are drawn from a generator seeded by
+ * ImpairedLinkOptions.Seed, in the order the packets arrive, so that a test
+ * sending the same packets sees the same packets lost and reordered.
+ */
+type Impairment struct {
+	/**
+	 * Probability (from 0 to 1) of dropping a packet.
+	 */
+	Loss float64
+
+	/**
+	 * Drop the packets for which it returns true, given their index in the
+	 * direction (from 0) and their content, e.g. to lose given RTP sequence
+	 * numbers. Checked before Loss.
+	 */
+	Drop func(index int, packet []byte) bool
+
+	/**
+	 * Delay added to every packet.
+	 */
+	Delay time.Duration
+
+	/**
+	 * Maximum random delay added on top of Delay. Jitter reorders the packets
+	 * closer to each other than it.
+	 */
+	Jitter time.Duration
+
+	/**
+	 * Probability (from 0 to 1) of holding a packet back until the next one of
+	 * the direction is forwarded, swapping them.
+	 */
+	Reorder float64
+}
+
+/**
+ * Counters of the packets of a direction of an ImpairedLink.
+ */
+type LinkStats struct {
+	Received  int
+	Forwarded int
+	Dropped   int
+	Reordered int
+}
+
+/**
+ * ImpairedLink options.
+ */
+type ImpairedLinkOptions struct {
+	/**
+	 * Addresses of the endpoints A and B, where the link forwards the packets
+	 * of the other one.
+	 */
+	A, B *net.UDPAddr
+
+	/**
+	 * Impairments of the packets from A to B and from B to A.
+	 */
+	AToB, BToA Impairment
+
+	/**
+	 * Seed of the random decisions. Default 1.
+	 */
+	Seed int64
+}
+
+/**
+ * In-process UDP proxy between two endpoints of the local host, applying an
+ * Impairment to each direction. A sends to AddrA() and B to AddrB(), and
+ * each endpoint receives the packets of the other from the address it sends
+ * to, as PipeTransports require.
+ */
+type ImpairedLink struct {
+	aToB, bToA *impairedDirection
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// impairedDirection forwards the packets read from in to the address to,
+// writing them through out.
+type impairedDirection struct {
+	in, out *net.UDPConn
+	to      *net.UDPAddr
+
+	mu         sync.Mutex
+	impairment Impairment
+	rand       *rand.Rand
+	held       []byte
+	stats      LinkStats
+	closed     bool
+}
+
+/**
+ * Create an ImpairedLink between the endpoints A and B.
+ */
+func NewImpairedLink(options ImpairedLinkOptions) (link *ImpairedLink, err error) {
+	seed := options.Seed
+	if seed == 0 {
+		seed = 1
+	}
+
+	connA, err := net.ListenUDP("udp", &net.UDPAddr{IP: options.A.IP})
+	if err != nil {
+		return
+	}
+	connB, err := net.ListenUDP("udp", &net.UDPAddr{IP: options.B.IP})
+	if err != nil {
+		connA.Close()
+		return
+	}
+
+	link = &ImpairedLink{
+		aToB: &impairedDirection{
+			in:         connA,
+			out:        connB,
+			to:         options.B,
+			impairment: options.AToB,
+			rand:       rand.New(rand.NewSource(seed)),
+		},
+		bToA: &impairedDirection{
+			in:         connB,
+			out:        connA,
+			to:         options.A,
+			impairment: options.BToA,
+			rand:       rand.New(rand.NewSource(seed + 1)),
+		},
+	}
+
+	link.wg.Add(2)
+
+	go link.aToB.run(&link.wg)
+	go link.bToA.run(&link.wg)
+
+	return
+}
+
+/**
+ * Address A has to send to.
+ */
+func (link *ImpairedLink) AddrA() *net.UDPAddr {
+	return link.aToB.in.LocalAddr().(*net.UDPAddr)
+}
+
+/**
+ * Address B has to send to.
+ */
+func (link *ImpairedLink) AddrB() *net.UDPAddr {
+	return link.bToA.in.LocalAddr().(*net.UDPAddr)
+}
+
+/**
+ * Change the impairments of the link, e.g. to degrade it in the middle of a
+ * test.
+ */
+func (link *ImpairedLink) SetImpairment(aToB, bToA Impairment) {
+	link.aToB.setImpairment(aToB)
+	link.bToA.setImpairment(bToA)
+}
+
+/**
+ * Counters of the packets from A to B and from B to A.
+ */
+func (link *ImpairedLink) Stats() (aToB, bToA LinkStats) {
+	return link.aToB.getStats(), link.bToA.getStats()
+}
+
+/**
+ * Close the link. The delayed packets are not forwarded.
+ */
+func (link *ImpairedLink) Close() {
+	link.closeOnce.Do(func() {
+		link.aToB.close()
+		link.bToA.close()
+		link.wg.Wait()
+	})
+}
+
+func (d *impairedDirection) setImpairment(impairment Impairment) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.impairment = impairment
+}
+
+func (d *impairedDirection) getStats() LinkStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stats
+}
+
+func (d *impairedDirection) close() {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	d.in.Close()
+}
+
+func (d *impairedDirection) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	buf := make([]byte, 65536)
+
+	for {
+		n, err := d.in.Read(buf)
+		if err != nil {
+			return
+		}
+		d.handle(append([]byte(nil), buf[:n]...))
+	}
+}
+
+func (d *impairedDirection) handle(packet []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	impairment := d.impairment
+	index := d.stats.Received
+
+	d.stats.Received++
+
+	if impairment.Drop != nil && impairment.Drop(index, packet) ||
+		impairment.Loss > 0 && d.rand.Float64() < impairment.Loss {
+		d.stats.Dropped++
+		return
+	}
+
+	delay := impairment.Delay
+
+	if impairment.Jitter > 0 {
+		delay += time.Duration(d.rand.Int63n(int64(impairment.Jitter) + 1))
+	}
+
+	if d.held == nil && impairment.Reorder > 0 && d.rand.Float64() < impairment.Reorder {
+		d.held = packet
+		d.stats.Reordered++
+		return
+	}
+
+	packets := [][]byte{packet}
+
+	if d.held != nil {
+		packets = append(packets, d.held)
+		d.held = nil
+	}
+	d.stats.Forwarded += len(packets)
+
+	if delay == 0 {
+		d.write(packets)
+		return
+	}
+
+	time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if !d.closed {
+			d.write(packets)
+		}
+	})
+}
+
+// write sends the packets, d.mu being locked to keep their order.
+func (d *impairedDirection) write(packets [][]byte) {
+	for _, packet := range packets {
+		d.out.WriteToUDP(packet, d.to)
+	}
+}
+
+/**
+ * Pipe the Producer to the destination Router like Router.PipeToRouter does,
+ * through an ImpairedLink applying the impairment to the RTP packets and the
+ * reverse impairment to the RTCP feedback. RTX is enabled so that NACKs are
+ * answered. Returns the pipe Producer of the destination Router and the link,
+ * everything being closed at the end of the test.
+ */
+func NewImpairedPipe(tb testing.TB, producer *mediasoup.Producer, router, destination *mediasoup.Router, impairment, reverse Impairment) (*mediasoup.Producer, *ImpairedLink) {
+	tb.Helper()
+
+	options := mediasoup.PipeTransportOptions{
+		ListenIp:  mediasoup.TransportListenIp{Ip: "127.0.0.1"},
+		EnableRtx: true,
+	}
+
+	local, err := router.CreatePipeTransport(options)
+	if err != nil {
+		tb.Fatalf("mediasouptest: create pipe transport: %v", err)
+	}
+	tb.Cleanup(func() { local.Close() })
+
+	remote, err := destination.CreatePipeTransport(options)
+	if err != nil {
+		tb.Fatalf("mediasouptest: create pipe transport: %v", err)
+	}
+	tb.Cleanup(func() { remote.Close() })
+
+	localTuple, remoteTuple := local.Tuple(), remote.Tuple()
+
+	link, err := NewImpairedLink(ImpairedLinkOptions{
+		A:    &net.UDPAddr{IP: net.ParseIP(localTuple.LocalIp), Port: int(localTuple.LocalPort)},
+		B:    &net.UDPAddr{IP: net.ParseIP(remoteTuple.LocalIp), Port: int(remoteTuple.LocalPort)},
+		AToB: impairment,
+		BToA: reverse,
+	})
+	if err != nil {
+		tb.Fatalf("mediasouptest: create impaired link: %v", err)
+	}
+	tb.Cleanup(link.Close)
+
+	if err = local.Connect(mediasoup.TransportConnectOptions{
+		Ip:   link.AddrA().IP.String(),
+		Port: uint16(link.AddrA().Port),
+	}); err != nil {
+		tb.Fatalf("mediasouptest: connect pipe transport: %v", err)
+	}
+	if err = remote.Connect(mediasoup.TransportConnectOptions{
+		Ip:   link.AddrB().IP.String(),
+		Port: uint16(link.AddrB().Port),
+	}); err != nil {
+		tb.Fatalf("mediasouptest: connect pipe transport: %v", err)
+	}
+
+	pipeConsumer, err := local.Consume(mediasoup.ConsumerOptions{ProducerId: producer.Id()})
+	if err != nil {
+		tb.Fatalf("mediasouptest: consume %s: %v", producer.Id(), err)
+	}
+	tb.Cleanup(func() { pipeConsumer.Close() })
+
+	pipeProducer, err := remote.Produce(mediasoup.ProducerOptions{
+		Id:            producer.Id(),
+		Kind:          pipeConsumer.Kind(),
+		RtpParameters: pipeConsumer.RtpParameters(),
+		Paused:        pipeConsumer.ProducerPaused(),
+	})
+	if err != nil {
+		tb.Fatalf("mediasouptest: produce %s: %v", producer.Id(), err)
+	}
+	tb.Cleanup(func() { pipeProducer.Close() })
+
+	return pipeProducer, link
+}
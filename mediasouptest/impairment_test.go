@@ -0,0 +1,156 @@
+package mediasouptest
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var localhost = net.ParseIP("127.0.0.1")
+
+// newLinkPeers returns two UDP endpoints and a link between them.
+func newLinkPeers(t *testing.T, options ImpairedLinkOptions) (a, b *net.UDPConn, link *ImpairedLink) {
+	a, err := net.ListenUDP("udp", &net.UDPAddr{IP: localhost})
+	require.NoError(t, err)
+	t.Cleanup(func() { a.Close() })
+
+	b, err = net.ListenUDP("udp", &net.UDPAddr{IP: localhost})
+	require.NoError(t, err)
+	t.Cleanup(func() { b.Close() })
+
+	options.A = a.LocalAddr().(*net.UDPAddr)
+	options.B = b.LocalAddr().(*net.UDPAddr)
+
+	link, err = NewImpairedLink(options)
+	require.NoError(t, err)
+	t.Cleanup(link.Close)
+
+	return
+}
+
+// sendPackets sends the packets 0 to n-1 (one byte each) from the endpoint to
+// addr, and returns the ones received by the peer within a short time.
+func sendPackets(t *testing.T, from, to *net.UDPConn, addr *net.UDPAddr, n int) (received []byte) {
+	for i := 0; i < n; i++ {
+		_, err := from.WriteToUDP([]byte{byte(i)}, addr)
+		require.NoError(t, err)
+		// Keep the order of arrival at the link.
+		time.Sleep(time.Millisecond)
+	}
+
+	buf := make([]byte, 16)
+
+	for {
+		to.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+
+		n, _, err := to.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		received = append(received, buf[:n]...)
+	}
+}
+
+func TestImpairedLink_Forward(t *testing.T) {
+	a, b, link := newLinkPeers(t, ImpairedLinkOptions{})
+
+	assert.Equal(t, []byte{0, 1, 2}, sendPackets(t, a, b, link.AddrA(), 3))
+
+	// B receives from the address it sends to.
+	b.WriteToUDP([]byte{9}, link.AddrB())
+
+	buf := make([]byte, 16)
+	a.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, from, err := a.ReadFromUDP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{9}, buf[:n])
+	assert.Equal(t, link.AddrA().String(), from.String())
+
+	aToB, bToA := link.Stats()
+	assert.Equal(t, LinkStats{Received: 3, Forwarded: 3}, aToB)
+	assert.Equal(t, LinkStats{Received: 1, Forwarded: 1}, bToA)
+}
+
+func TestImpairedLink_Drop(t *testing.T) {
+	a, b, link := newLinkPeers(t, ImpairedLinkOptions{
+		AToB: Impairment{
+			Drop: func(index int, packet []byte) bool {
+				return index%3 == 1
+			},
+		},
+	})
+
+	assert.Equal(t, []byte{0, 2, 3, 5}, sendPackets(t, a, b, link.AddrA(), 6))
+
+	aToB, _ := link.Stats()
+	assert.Equal(t, 2, aToB.Dropped)
+}
+
+func TestImpairedLink_Loss(t *testing.T) {
+	run := func() []byte {
+		a, b, link := newLinkPeers(t, ImpairedLinkOptions{
+			AToB: Impairment{Loss: 0.3},
+			Seed: 42,
+		})
+		return sendPackets(t, a, b, link.AddrA(), 50)
+	}
+
+	received := run()
+
+	assert.Less(t, len(received), 50)
+	assert.Greater(t, len(received), 20)
+	assert.Equal(t, received, run())
+}
+
+func TestImpairedLink_Reorder(t *testing.T) {
+	a, b, link := newLinkPeers(t, ImpairedLinkOptions{
+		AToB: Impairment{Reorder: 1},
+	})
+
+	assert.Equal(t, []byte{1, 0, 3, 2}, sendPackets(t, a, b, link.AddrA(), 4))
+
+	aToB, _ := link.Stats()
+	assert.Equal(t, 2, aToB.Reordered)
+}
+
+func TestImpairedLink_Delay(t *testing.T) {
+	a, b, link := newLinkPeers(t, ImpairedLinkOptions{
+		AToB: Impairment{Delay: 50 * time.Millisecond, Jitter: 10 * time.Millisecond},
+	})
+
+	start := time.Now()
+	a.WriteToUDP([]byte{1}, link.AddrA())
+
+	buf := make([]byte, 16)
+	b.SetReadDeadline(time.Now().Add(time.Second))
+
+	_, _, err := b.ReadFromUDP(buf)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	link.SetImpairment(Impairment{Loss: 1}, Impairment{})
+
+	assert.Empty(t, sendPackets(t, a, b, link.AddrA(), 3))
+}
+
+func TestNewImpairedPipe(t *testing.T) {
+	router := NewRouter(t, nil)
+	destination := NewRouter(t, nil)
+
+	transport := NewPlainTransport(t, router)
+	producer := NewVideoProducer(t, transport)
+
+	pipeProducer, link := NewImpairedPipe(t, producer, router, destination,
+		Impairment{Loss: 0.05, Jitter: 5 * time.Millisecond}, Impairment{})
+
+	assert.Equal(t, producer.Id(), pipeProducer.Id())
+	assert.Equal(t, mediasoup.MediaKind_Video, pipeProducer.Kind())
+
+	aToB, _ := link.Stats()
+	assert.Zero(t, aToB.Dropped)
+}
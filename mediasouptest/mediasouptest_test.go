@@ -0,0 +1,62 @@
+package mediasouptest
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestPortRange(t *testing.T) {
+	seen := make(map[uint16]bool)
+
+	for i := 0; i < 10; i++ {
+		min, max := PortRange()
+
+		assert.EqualValues(t, PortsPerWorker-1, max-min)
+		assert.GreaterOrEqual(t, int(min), portsStart)
+		assert.Less(t, int(max), portsEnd)
+		assert.False(t, seen[min])
+
+		seen[min] = true
+	}
+}
+
+func TestNewRouter(t *testing.T) {
+	router := NewRouter(t, nil)
+
+	transport := NewWebRtcTransport(t, router)
+	producer := NewAudioProducer(t, transport)
+	consumer := NewConsumer(t, transport, producer.Id())
+
+	assert.Equal(t, mediasoup.MediaKind_Audio, consumer.Kind())
+
+	direct := NewDirectTransport(t, router)
+	video := NewVideoProducer(t, direct)
+
+	require.Len(t, router.Producers(), 2)
+
+	t.Run("cleanup", func(t *testing.T) {
+		plain := NewPlainTransport(t, router)
+		NewConsumer(t, plain, video.Id())
+
+		assert.Len(t, plain.Consumers(), 1)
+	})
+}
+
+func TestNewWorker_Cleanup(t *testing.T) {
+	var worker *mediasoup.Worker
+
+	t.Run("worker", func(t *testing.T) {
+		worker = NewWorker(t)
+	})
+
+	if worker != nil {
+		assert.True(t, worker.Closed())
+	}
+}
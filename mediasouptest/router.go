@@ -0,0 +1,272 @@
+package mediasouptest
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Media codecs of the Routers created by NewRouter: Opus, VP8 and H264.
+ */
+func MediaCodecs() []*mediasoup.RtpCodecCapability {
+	return []*mediasoup.RtpCodecCapability{
+		{
+			Kind:      mediasoup.MediaKind_Audio,
+			MimeType:  "audio/opus",
+			ClockRate: 48000,
+			Channels:  2,
+		},
+		{
+			Kind:      mediasoup.MediaKind_Video,
+			MimeType:  "video/VP8",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      mediasoup.MediaKind_Video,
+			MimeType:  "video/H264",
+			ClockRate: 90000,
+			Parameters: mediasoup.RtpCodecSpecificParameters{
+				{Key: "level-asymmetry-allowed", Value: 1},
+				{Key: "packetization-mode", Value: 1},
+				{Key: "profile-level-id", Value: "4d0032"},
+			},
+		},
+	}
+}
+
+/**
+ * RTP capabilities of a consuming endpoint supporting Opus and VP8, to
+ * consume the Producers created by NewAudioProducer and NewVideoProducer.
+ */
+func DeviceCapabilities() mediasoup.RtpCapabilities {
+	return mediasoup.RtpCapabilities{
+		Codecs: []*mediasoup.RtpCodecCapability{
+			{
+				Kind:                 mediasoup.MediaKind_Audio,
+				MimeType:             "audio/opus",
+				PreferredPayloadType: 100,
+				ClockRate:            48000,
+				Channels:             2,
+			},
+			{
+				Kind:                 mediasoup.MediaKind_Video,
+				MimeType:             "video/VP8",
+				PreferredPayloadType: 101,
+				ClockRate:            90000,
+				RtcpFeedback: []mediasoup.RtcpFeedback{
+					{Type: "nack"},
+					{Type: "ccm", Parameter: "fir"},
+					{Type: "transport-cc"},
+				},
+			},
+			{
+				Kind:                 mediasoup.MediaKind_Video,
+				MimeType:             "video/rtx",
+				PreferredPayloadType: 102,
+				ClockRate:            90000,
+				Parameters:           mediasoup.RtpCodecSpecificParameters{{Key: "apt", Value: 101}},
+				RtcpFeedback:         []mediasoup.RtcpFeedback{},
+			},
+		},
+		HeaderExtensions: []*mediasoup.RtpHeaderExtension{
+			{
+				Kind:        mediasoup.MediaKind_Audio,
+				Uri:         "urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+				PreferredId: 10,
+			},
+			{
+				Kind:        mediasoup.MediaKind_Video,
+				Uri:         "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01",
+				PreferredId: 5,
+			},
+		},
+	}
+}
+
+/**
+ * Create a Router with MediaCodecs() unless overridden by the options, in the
+ * given Worker or in a new one if nil. It is closed at the end of the test.
+ */
+func NewRouter(tb testing.TB, worker *mediasoup.Worker, opts ...mediasoup.RouterOption) *mediasoup.Router {
+	tb.Helper()
+
+	if worker == nil {
+		worker = NewWorker(tb)
+	}
+
+	router, err := worker.CreateRouter(mediasoup.RouterOptions{MediaCodecs: MediaCodecs()}, opts...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: create router: %v", err)
+	}
+
+	tb.Cleanup(func() { router.Close() })
+
+	return router
+}
+
+/**
+ * Create a WebRtcTransport listening on 127.0.0.1 in UDP and TCP, with SCTP
+ * enabled, unless overridden by the options. It is closed at the end of the
+ * test.
+ */
+func NewWebRtcTransport(tb testing.TB, router *mediasoup.Router, opts ...mediasoup.WebRtcTransportOption) *mediasoup.WebRtcTransport {
+	tb.Helper()
+
+	transport, err := router.CreateWebRtcTransport(mediasoup.WebRtcTransportOptions{
+		ListenIps:  []mediasoup.TransportListenIp{{Ip: "127.0.0.1"}},
+		EnableTcp:  true,
+		EnableSctp: true,
+	}, opts...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: create webrtc transport: %v", err)
+	}
+
+	tb.Cleanup(func() { transport.Close() })
+
+	return transport
+}
+
+/**
+ * Create a PlainTransport listening on 127.0.0.1 with comedia enabled, unless
+ * overridden by the options. It is closed at the end of the test.
+ */
+func NewPlainTransport(tb testing.TB, router *mediasoup.Router, opts ...mediasoup.PlainTransportOption) *mediasoup.PlainTransport {
+	tb.Helper()
+
+	transport, err := router.CreatePlainTransport(mediasoup.PlainTransportOptions{
+		ListenIp: mediasoup.TransportListenIp{Ip: "127.0.0.1"},
+		Comedia:  true,
+	}, opts...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: create plain transport: %v", err)
+	}
+
+	tb.Cleanup(func() { transport.Close() })
+
+	return transport
+}
+
+/**
+ * Create a DirectTransport, closed at the end of the test.
+ */
+func NewDirectTransport(tb testing.TB, router *mediasoup.Router) *mediasoup.DirectTransport {
+	tb.Helper()
+
+	transport, err := router.CreateDirectTransport()
+	if err != nil {
+		tb.Fatalf("mediasouptest: create direct transport: %v", err)
+	}
+
+	tb.Cleanup(func() { transport.Close() })
+
+	return transport
+}
+
+/**
+ * Options of the Opus Producers created by NewAudioProducer.
+ */
+func AudioProducerOptions() mediasoup.ProducerOptions {
+	return mediasoup.ProducerOptions{
+		Kind: mediasoup.MediaKind_Audio,
+		RtpParameters: mediasoup.RtpParameters{
+			Mid: "AUDIO",
+			Codecs: []*mediasoup.RtpCodecParameters{
+				{
+					MimeType:    "audio/opus",
+					PayloadType: 111,
+					ClockRate:   48000,
+					Channels:    2,
+				},
+			},
+			HeaderExtensions: []mediasoup.RtpHeaderExtensionParameters{
+				{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 10},
+				{Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", Id: 12},
+			},
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 11111111}},
+			Rtcp:      mediasoup.RtcpParameters{Cname: "mediasouptest"},
+		},
+	}
+}
+
+/**
+ * Options of the VP8 Producers created by NewVideoProducer.
+ */
+func VideoProducerOptions() mediasoup.ProducerOptions {
+	return mediasoup.ProducerOptions{
+		Kind: mediasoup.MediaKind_Video,
+		RtpParameters: mediasoup.RtpParameters{
+			Mid: "VIDEO",
+			Codecs: []*mediasoup.RtpCodecParameters{
+				{
+					MimeType:    "video/VP8",
+					PayloadType: 112,
+					ClockRate:   90000,
+					RtcpFeedback: []mediasoup.RtcpFeedback{
+						{Type: "nack"},
+						{Type: "nack", Parameter: "pli"},
+						{Type: "ccm", Parameter: "fir"},
+					},
+				},
+			},
+			HeaderExtensions: []mediasoup.RtpHeaderExtensionParameters{
+				{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 10},
+			},
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: 22222222}},
+			Rtcp:      mediasoup.RtcpParameters{Cname: "mediasouptest"},
+		},
+	}
+}
+
+/**
+ * Produce with AudioProducerOptions() on the transport. The Producer is
+ * closed at the end of the test.
+ */
+func NewAudioProducer(tb testing.TB, transport mediasoup.ITransport, opts ...mediasoup.ProducerOption) *mediasoup.Producer {
+	tb.Helper()
+
+	return newProducer(tb, transport, AudioProducerOptions(), opts...)
+}
+
+/**
+ * Produce with VideoProducerOptions() on the transport. The Producer is
+ * closed at the end of the test.
+ */
+func NewVideoProducer(tb testing.TB, transport mediasoup.ITransport, opts ...mediasoup.ProducerOption) *mediasoup.Producer {
+	tb.Helper()
+
+	return newProducer(tb, transport, VideoProducerOptions(), opts...)
+}
+
+func newProducer(tb testing.TB, transport mediasoup.ITransport, options mediasoup.ProducerOptions, opts ...mediasoup.ProducerOption) *mediasoup.Producer {
+	tb.Helper()
+
+	producer, err := transport.Produce(options, opts...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: produce %s: %v", options.Kind, err)
+	}
+
+	tb.Cleanup(func() { producer.Close() })
+
+	return producer
+}
+
+/**
+ * Consume the Producer on the transport with DeviceCapabilities(). The
+ * Consumer is closed at the end of the test.
+ */
+func NewConsumer(tb testing.TB, transport mediasoup.ITransport, producerId string, opts ...mediasoup.ConsumerOption) *mediasoup.Consumer {
+	tb.Helper()
+
+	consumer, err := transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producerId,
+		RtpCapabilities: DeviceCapabilities(),
+	}, opts...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: consume %s: %v", producerId, err)
+	}
+
+	tb.Cleanup(func() { consumer.Close() })
+
+	return consumer
+}
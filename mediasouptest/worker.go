@@ -0,0 +1,218 @@
+/**
+ * Package mediasouptest provides helpers for the integration tests of the
+ * applications of mediasoup-go: they spawn real mediasoup-worker processes
+ * with their own RTC ports and create Routers, transports and Producers with
+ * defaults suitable for tests, every entity being closed when the test ends.
+ *
+ *	func TestMain(m *testing.M) {
+ *		mediasouptest.Main(m)
+ *	}
+ *
+ *	func TestRoom(t *testing.T) {
+ *		router := mediasouptest.NewRouter(t, nil)
+ *		transport := mediasouptest.NewWebRtcTransport(t, router)
+ *		producer := mediasouptest.NewAudioProducer(t, transport)
+ *		...
+ *	}
+ *
+ * NewImpairedPipe pipes a Producer between two Routers through an
+ * ImpairedLink, a UDP proxy adding loss, jitter and reordering, to test the
+ * RTX/NACK behavior and the resilience of the application.
+ *
+ * The tests are skipped if the mediasoup-worker binary (mediasoup.WorkerBin,
+ * set with MEDIASOUP_WORKER_BIN) is missing.
+ */
+package mediasouptest
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// RTC ports given to the workers, in blocks of PortsPerWorker.
+const (
+	portsStart = 20000
+	portsEnd   = 60000
+)
+
+/**
+ * Number of RTC ports of each worker spawned by NewWorker.
+ */
+const PortsPerWorker = 100
+
+/**
+ * Time given to a worker process to exit once closed, before it is killed.
+ */
+var ExitTimeout = 5 * time.Second
+
+var (
+	// Next block of ports, starting at a block depending on the pid so that
+	// the test binaries of several packages run in parallel do not collide.
+	nextPortBlock = int64(os.Getpid())
+
+	// Workers spawned by NewWorker and not closed yet.
+	workers sync.Map
+)
+
+/**
+ * Return an RTC port range of PortsPerWorker ports not used by the other
+ * workers of the process, whose first port is free.
+ */
+func PortRange() (minPort, maxPort uint16) {
+	blocks := int64((portsEnd - portsStart) / PortsPerWorker)
+
+	for i := int64(0); i < blocks; i++ {
+		block := atomic.AddInt64(&nextPortBlock, 1) % blocks
+		port := portsStart + int(block)*PortsPerWorker
+
+		if portFree(port) {
+			return uint16(port), uint16(port + PortsPerWorker - 1)
+		}
+	}
+
+	// Every block is busy, let the worker fail if its ports are too.
+	block := atomic.AddInt64(&nextPortBlock, 1) % blocks
+	port := portsStart + int(block)*PortsPerWorker
+
+	return uint16(port), uint16(port + PortsPerWorker - 1)
+}
+
+func portFree(port int) bool {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+	if err != nil {
+		return false
+	}
+	conn.Close()
+
+	return true
+}
+
+/**
+ * Skip the test if the mediasoup-worker binary is missing.
+ */
+func SkipWithoutWorker(tb testing.TB) {
+	tb.Helper()
+
+	bin := strings.Fields(mediasoup.WorkerBin)
+
+	if len(bin) == 0 {
+		tb.Skip("mediasoup-worker not found, set MEDIASOUP_WORKER_BIN")
+	}
+	if _, err := exec.LookPath(bin[0]); err != nil {
+		tb.Skipf("mediasoup-worker not found at %q, set MEDIASOUP_WORKER_BIN", bin[0])
+	}
+}
+
+/**
+ * Spawn a Worker with its own RTC port range, closed at the end of the test.
+ * The options override the defaults, which are the ones of NewWorker with
+ * the warn log level.
+ */
+func NewWorker(tb testing.TB, options ...mediasoup.Option) *mediasoup.Worker {
+	tb.Helper()
+
+	SkipWithoutWorker(tb)
+
+	rtcMinPort, rtcMaxPort := PortRange()
+
+	options = append([]mediasoup.Option{
+		mediasoup.WithLogLevel(mediasoup.WorkerLogLevel_Warn),
+		mediasoup.WithRtcMinPort(rtcMinPort),
+		mediasoup.WithRtcMaxPort(rtcMaxPort),
+	}, options...)
+
+	worker, err := mediasoup.NewWorker(options...)
+	if err != nil {
+		tb.Fatalf("mediasouptest: spawn worker: %v", err)
+	}
+
+	pid := worker.Pid()
+
+	workers.Store(pid, worker)
+
+	worker.Observer().Once("close", func() {
+		workers.Delete(pid)
+	})
+
+	tb.Cleanup(func() {
+		closeWorker(worker)
+	})
+
+	return worker
+}
+
+// closeWorker closes the worker and makes sure its process is gone.
+func closeWorker(worker *mediasoup.Worker) {
+	pid := worker.Pid()
+
+	worker.Close()
+	workers.Delete(pid)
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(ExitTimeout)
+
+	for time.Now().Before(deadline) {
+		if process.Signal(syscall.Signal(0)) != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	process.Kill()
+}
+
+/**
+ * Close the Workers spawned by NewWorker which are still running, e.g. the
+ * ones of a test whose cleanup did not run.
+ */
+func CloseWorkers() {
+	var wg sync.WaitGroup
+
+	workers.Range(func(key, value interface{}) bool {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			closeWorker(value.(*mediasoup.Worker))
+		}()
+
+		return true
+	})
+
+	wg.Wait()
+}
+
+/**
+ * Run the tests of a TestMain, closing the Workers left running when they
+ * end or when the test binary is interrupted, so that no mediasoup-worker
+ * process outlives the tests. It does not return.
+ */
+func Main(m *testing.M) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		CloseWorkers()
+		os.Exit(1)
+	}()
+
+	code := m.Run()
+
+	CloseWorkers()
+	os.Exit(code)
+}
@@ -0,0 +1,15 @@
+module github.com/jiyeyuran/mediasoup-go/metrics
+
+go 1.15
+
+require (
+	github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.12.2
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/sys v0.7.0 // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../
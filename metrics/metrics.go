@@ -0,0 +1,434 @@
+// Package metrics exports Prometheus metrics of mediasoup workers and of every
+// entity created in them:
+//
+//	exporter := metrics.NewExporter(metrics.Options{})
+//	exporter.AddWorker(worker)
+//	http.Handle("/metrics", exporter.Handler())
+//
+// It is a separate module so that the mediasoup-go module does not depend on
+// the Prometheus client.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Options struct {
+	/**
+	 * Namespace of the metric names. Default "mediasoup".
+	 */
+	Namespace string
+
+	/**
+	 * Interval between two polls of the stats of transports, producers and
+	 * consumers. Default 10 seconds. A negative value disables the polling,
+	 * the Exporter can then be used as sink of another StatsCollector.
+	 */
+	StatsInterval time.Duration
+}
+
+/**
+ * Exporter is a prometheus.Collector with the metrics of the workers added to
+ * it.
+ */
+type Exporter struct {
+	workers       prometheus.Gauge
+	workersDied   prometheus.Counter
+	routers       prometheus.Gauge
+	transports    *prometheus.GaugeVec
+	producers     *prometheus.GaugeVec
+	consumers     *prometheus.GaugeVec
+	dataProducers prometheus.Gauge
+	dataConsumers prometheus.Gauge
+
+	requestDuration *prometheus.HistogramVec
+	requestErrors   *prometheus.CounterVec
+
+	channelPendingRequests     *prometheus.GaugeVec
+	channelQueuedMessages      *prometheus.GaugeVec
+	channelDroppedMessages     *prometheus.GaugeVec
+	channelMeanDispatchLatency *prometheus.GaugeVec
+	channelMaxDispatchLatency  *prometheus.GaugeVec
+
+	transportRecvBitrate  *prometheus.GaugeVec
+	transportSendBitrate  *prometheus.GaugeVec
+	producerBitrate       *prometheus.GaugeVec
+	producerPacketsLost   *prometheus.GaugeVec
+	producerScore         *prometheus.GaugeVec
+	consumerBitrate       *prometheus.GaugeVec
+	consumerPacketsLost   *prometheus.GaugeVec
+	consumerRoundTripTime *prometheus.GaugeVec
+	consumerScore         *prometheus.GaugeVec
+
+	collectors []prometheus.Collector
+
+	// Function returning the IPC stats of every worker by pid.
+	ipcStats sync.Map
+	// Label values of the stats metrics by entity id.
+	statsLabels sync.Map
+	// Collector of the stats, if polling is enabled.
+	statsCollector *mediasoup.StatsCollector
+}
+
+/**
+ * Create an Exporter. Its stats are polled until Close() is called.
+ */
+func NewExporter(options Options) *Exporter {
+	namespace := options.Namespace
+
+	if len(namespace) == 0 {
+		namespace = "mediasoup"
+	}
+
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help})
+	}
+	gaugeVec := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	}
+
+	e := &Exporter{
+		workers: gauge("workers", "Number of running workers."),
+		workersDied: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "workers_died_total",
+			Help:      "Number of workers that died unexpectedly and needed a restart.",
+		}),
+		routers:       gauge("routers", "Number of open routers."),
+		transports:    gaugeVec("transports", "Number of open transports.", "type"),
+		producers:     gaugeVec("producers", "Number of open producers.", "kind"),
+		consumers:     gaugeVec("consumers", "Number of open consumers.", "kind"),
+		dataProducers: gauge("data_producers", "Number of open data producers."),
+		dataConsumers: gauge("data_consumers", "Number of open data consumers."),
+
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "channel_request_duration_seconds",
+			Help:      "Duration of the requests sent to the workers.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"method"}),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "channel_request_errors_total",
+			Help:      "Number of failed requests sent to the workers.",
+		}, []string{"method"}),
+
+		channelPendingRequests: gaugeVec("channel_pending_requests",
+			"Requests sent to the worker waiting for their response.", "pid", "channel"),
+		channelQueuedMessages: gaugeVec("channel_queued_messages",
+			"Messages received from the worker and not dispatched yet.", "pid", "channel"),
+		channelDroppedMessages: gaugeVec("channel_dropped_messages",
+			"Messages received from the worker and discarded since it started.", "pid", "channel"),
+		channelMeanDispatchLatency: gaugeVec("channel_mean_dispatch_latency_seconds",
+			"Mean time taken to dispatch a message received from the worker.", "pid", "channel"),
+		channelMaxDispatchLatency: gaugeVec("channel_max_dispatch_latency_seconds",
+			"Maximum time taken to dispatch a message received from the worker.", "pid", "channel"),
+
+		transportRecvBitrate: gaugeVec("transport_recv_bitrate_bps",
+			"Receiving bitrate of the transport.", "transport_id"),
+		transportSendBitrate: gaugeVec("transport_send_bitrate_bps",
+			"Sending bitrate of the transport.", "transport_id"),
+		producerBitrate: gaugeVec("producer_bitrate_bps",
+			"Bitrate of all the RTP streams of the producer.", "producer_id", "kind"),
+		producerPacketsLost: gaugeVec("producer_packets_lost",
+			"Packets lost in all the RTP streams of the producer.", "producer_id", "kind"),
+		producerScore: gaugeVec("producer_score",
+			"Lowest score (0-10) of the RTP streams of the producer.", "producer_id", "kind"),
+		consumerBitrate: gaugeVec("consumer_bitrate_bps",
+			"Bitrate of the RTP stream of the consumer.", "consumer_id", "producer_id", "kind"),
+		consumerPacketsLost: gaugeVec("consumer_packets_lost",
+			"Packets lost in the RTP stream of the consumer.", "consumer_id", "producer_id", "kind"),
+		consumerRoundTripTime: gaugeVec("consumer_round_trip_time_seconds",
+			"Round trip time of the RTP stream of the consumer.", "consumer_id", "producer_id", "kind"),
+		consumerScore: gaugeVec("consumer_score",
+			"Score (0-10) of the RTP stream of the consumer.", "consumer_id", "producer_id", "kind"),
+	}
+
+	e.collectors = []prometheus.Collector{
+		e.workers, e.workersDied, e.routers, e.transports, e.producers,
+		e.consumers, e.dataProducers, e.dataConsumers, e.requestDuration,
+		e.requestErrors, e.channelPendingRequests, e.channelQueuedMessages,
+		e.channelDroppedMessages, e.channelMeanDispatchLatency,
+		e.channelMaxDispatchLatency, e.transportRecvBitrate, e.transportSendBitrate,
+		e.producerBitrate, e.producerPacketsLost, e.producerScore,
+		e.consumerBitrate, e.consumerPacketsLost, e.consumerRoundTripTime,
+		e.consumerScore,
+	}
+
+	if options.StatsInterval >= 0 {
+		e.statsCollector = mediasoup.NewStatsCollector(mediasoup.StatsCollectorOptions{
+			Interval: options.StatsInterval,
+			Sinks:    []mediasoup.StatsSink{e},
+		})
+	}
+
+	return e
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range e.collectors {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.publishIPCStats()
+
+	for _, collector := range e.collectors {
+		collector.Collect(ch)
+	}
+}
+
+/**
+ * HTTP handler serving the metrics of the Exporter only.
+ */
+func (e *Exporter) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+/**
+ * Stop polling stats. Metrics are still updated by the entity events.
+ */
+func (e *Exporter) Close() {
+	if e.statsCollector != nil {
+		e.statsCollector.Close()
+	}
+}
+
+/**
+ * Track the given Worker and every entity created in it. A Worker created to
+ * replace a dead one must be added too.
+ */
+func (e *Exporter) AddWorker(worker *mediasoup.Worker) {
+	pid := strconv.Itoa(worker.Pid())
+
+	e.workers.Inc()
+	e.ipcStats.Store(pid, worker.IPCStats)
+
+	worker.On("died", func(err error) {
+		e.workersDied.Inc()
+	})
+	worker.Observer().On("close", func() {
+		e.workers.Dec()
+		e.ipcStats.Delete(pid)
+
+		for _, channel := range []string{"channel", "payload_channel"} {
+			e.channelPendingRequests.DeleteLabelValues(pid, channel)
+			e.channelQueuedMessages.DeleteLabelValues(pid, channel)
+			e.channelDroppedMessages.DeleteLabelValues(pid, channel)
+			e.channelMeanDispatchLatency.DeleteLabelValues(pid, channel)
+			e.channelMaxDispatchLatency.DeleteLabelValues(pid, channel)
+		}
+	})
+	worker.Observer().On("request", func(info mediasoup.ChannelRequestInfo) {
+		e.observeRequest(info)
+	})
+	worker.Observer().On("newrouter", func(router *mediasoup.Router) {
+		e.addRouter(router)
+	})
+}
+
+func (e *Exporter) observeRequest(info mediasoup.ChannelRequestInfo) {
+	e.requestDuration.WithLabelValues(info.Method).Observe(info.Duration.Seconds())
+
+	if info.Error != nil {
+		e.requestErrors.WithLabelValues(info.Method).Inc()
+	}
+}
+
+func (e *Exporter) publishIPCStats() {
+	e.ipcStats.Range(func(key, value interface{}) bool {
+		pid, stats := key.(string), value.(func() mediasoup.WorkerIPCStats)()
+
+		for channel, stat := range map[string]mediasoup.ChannelStats{
+			"channel":         stats.Channel,
+			"payload_channel": stats.PayloadChannel,
+		} {
+			e.channelPendingRequests.WithLabelValues(pid, channel).Set(float64(stat.PendingRequests))
+			e.channelQueuedMessages.WithLabelValues(pid, channel).Set(float64(stat.QueuedMessages))
+			e.channelDroppedMessages.WithLabelValues(pid, channel).Set(float64(stat.DroppedMessages))
+			e.channelMeanDispatchLatency.WithLabelValues(pid, channel).Set(stat.MeanDispatchLatency.Seconds())
+			e.channelMaxDispatchLatency.WithLabelValues(pid, channel).Set(stat.MaxDispatchLatency.Seconds())
+		}
+
+		return true
+	})
+}
+
+func (e *Exporter) addRouter(router *mediasoup.Router) {
+	e.routers.Inc()
+
+	router.Observer().On("close", func() {
+		e.routers.Dec()
+	})
+	router.Observer().On("newtransport", func(transport mediasoup.ITransport) {
+		e.addTransport(transport)
+	})
+}
+
+func (e *Exporter) addTransport(transport mediasoup.ITransport) {
+	id := transport.Id()
+	typ := transportType(transport)
+
+	e.transports.WithLabelValues(typ).Inc()
+	e.statsLabels.Store(id, []string{id})
+
+	if e.statsCollector != nil {
+		e.statsCollector.AddTransport(transport)
+	}
+
+	transport.Observer().On("close", func() {
+		e.transports.WithLabelValues(typ).Dec()
+		e.statsLabels.Delete(id)
+		e.transportRecvBitrate.DeleteLabelValues(id)
+		e.transportSendBitrate.DeleteLabelValues(id)
+	})
+	transport.Observer().On("newproducer", func(producer *mediasoup.Producer) {
+		e.addProducer(producer)
+	})
+	transport.Observer().On("newconsumer", func(consumer *mediasoup.Consumer) {
+		e.addConsumer(consumer)
+	})
+	transport.Observer().On("newdataproducer", func(dataProducer *mediasoup.DataProducer) {
+		e.dataProducers.Inc()
+		dataProducer.Observer().On("close", func() { e.dataProducers.Dec() })
+	})
+	transport.Observer().On("newdataconsumer", func(dataConsumer *mediasoup.DataConsumer) {
+		e.dataConsumers.Inc()
+		dataConsumer.Observer().On("close", func() { e.dataConsumers.Dec() })
+	})
+}
+
+func (e *Exporter) addProducer(producer *mediasoup.Producer) {
+	id, kind := producer.Id(), string(producer.Kind())
+
+	e.producers.WithLabelValues(kind).Inc()
+	e.statsLabels.Store(id, []string{id, kind})
+
+	if e.statsCollector != nil {
+		e.statsCollector.AddProducer(producer)
+	}
+
+	producer.Observer().On("close", func() {
+		e.producers.WithLabelValues(kind).Dec()
+		e.statsLabels.Delete(id)
+		e.producerBitrate.DeleteLabelValues(id, kind)
+		e.producerPacketsLost.DeleteLabelValues(id, kind)
+		e.producerScore.DeleteLabelValues(id, kind)
+	})
+}
+
+func (e *Exporter) addConsumer(consumer *mediasoup.Consumer) {
+	id, producerId, kind := consumer.Id(), consumer.ProducerId(), string(consumer.Kind())
+
+	e.consumers.WithLabelValues(kind).Inc()
+	e.statsLabels.Store(id, []string{id, producerId, kind})
+
+	if e.statsCollector != nil {
+		e.statsCollector.AddConsumer(consumer)
+	}
+
+	consumer.Observer().On("close", func() {
+		e.consumers.WithLabelValues(kind).Dec()
+		e.statsLabels.Delete(id)
+		e.consumerBitrate.DeleteLabelValues(id, producerId, kind)
+		e.consumerPacketsLost.DeleteLabelValues(id, producerId, kind)
+		e.consumerRoundTripTime.DeleteLabelValues(id, producerId, kind)
+		e.consumerScore.DeleteLabelValues(id, producerId, kind)
+	})
+}
+
+/**
+ * Publish implements mediasoup.StatsSink. Only the stats of the entities
+ * tracked by the Exporter are exported.
+ */
+func (e *Exporter) Publish(reports []mediasoup.StatsReport) error {
+	for _, report := range reports {
+		value, ok := e.statsLabels.Load(report.EntityId)
+		if !ok || report.Error != nil {
+			continue
+		}
+		labels := value.([]string)
+
+		switch stats := report.Stats.(type) {
+		case []*mediasoup.TransportStat:
+			if len(stats) == 0 {
+				continue
+			}
+			e.transportRecvBitrate.WithLabelValues(labels...).Set(float64(stats[0].RecvBitrate))
+			e.transportSendBitrate.WithLabelValues(labels...).Set(float64(stats[0].SendBitrate))
+
+		case []*mediasoup.ProducerStat:
+			if report.EntityType == mediasoup.StatsEntityType_Consumer {
+				e.publishConsumerStats(labels, stats)
+			} else {
+				e.publishProducerStats(labels, stats)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) publishProducerStats(labels []string, stats []*mediasoup.ProducerStat) {
+	if len(stats) == 0 {
+		return
+	}
+
+	var bitrate, packetsLost float64
+
+	score := stats[0].Score
+
+	for _, stat := range stats {
+		bitrate += float64(stat.Bitrate)
+		packetsLost += float64(stat.PacketsLost)
+
+		if stat.Score < score {
+			score = stat.Score
+		}
+	}
+	e.producerBitrate.WithLabelValues(labels...).Set(bitrate)
+	e.producerPacketsLost.WithLabelValues(labels...).Set(packetsLost)
+	e.producerScore.WithLabelValues(labels...).Set(float64(score))
+}
+
+func (e *Exporter) publishConsumerStats(labels []string, stats []*mediasoup.ConsumerStat) {
+	// The stats of the consumer are followed by the ones of the producer.
+	for _, stat := range stats {
+		if stat.Type != "outbound-rtp" {
+			continue
+		}
+		e.consumerBitrate.WithLabelValues(labels...).Set(float64(stat.Bitrate))
+		e.consumerPacketsLost.WithLabelValues(labels...).Set(float64(stat.PacketsLost))
+		e.consumerRoundTripTime.WithLabelValues(labels...).Set(float64(stat.RoundTripTime) / 1000)
+		e.consumerScore.WithLabelValues(labels...).Set(float64(stat.Score))
+		break
+	}
+}
+
+func transportType(transport mediasoup.ITransport) string {
+	switch transport.(type) {
+	case *mediasoup.WebRtcTransport:
+		return "webrtc"
+	case *mediasoup.PlainTransport:
+		return "plain"
+	case *mediasoup.PipeTransport:
+		return "pipe"
+	case *mediasoup.DirectTransport:
+		return "direct"
+	default:
+		return "unknown"
+	}
+}
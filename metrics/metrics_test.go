@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveRequest(t *testing.T) {
+	exporter := NewExporter(Options{StatsInterval: -1})
+	defer exporter.Close()
+
+	exporter.observeRequest(mediasoup.ChannelRequestInfo{
+		Method:   "worker.dump",
+		Duration: time.Millisecond,
+	})
+	exporter.observeRequest(mediasoup.ChannelRequestInfo{
+		Method:   "worker.dump",
+		Duration: time.Millisecond,
+		Error:    errors.New("failed"),
+	})
+
+	assert.EqualValues(t, 1, testutil.ToFloat64(exporter.requestErrors.WithLabelValues("worker.dump")))
+	assert.Equal(t, 1, testutil.CollectAndCount(exporter.requestDuration))
+}
+
+func TestTransportType(t *testing.T) {
+	assert.Equal(t, "webrtc", transportType(&mediasoup.WebRtcTransport{}))
+	assert.Equal(t, "direct", transportType(&mediasoup.DirectTransport{}))
+}
+
+func TestPublishStats(t *testing.T) {
+	exporter := NewExporter(Options{StatsInterval: -1})
+
+	exporter.statsLabels.Store("p1", []string{"p1", "video"})
+	exporter.statsLabels.Store("c1", []string{"c1", "p1", "video"})
+
+	err := exporter.Publish([]mediasoup.StatsReport{
+		{
+			EntityType: mediasoup.StatsEntityType_Producer,
+			EntityId:   "p1",
+			Stats: []*mediasoup.ProducerStat{
+				{Bitrate: 100000, Score: 10},
+				{Bitrate: 300000, Score: 7},
+			},
+		},
+		{
+			EntityType: mediasoup.StatsEntityType_Consumer,
+			EntityId:   "c1",
+			Stats: []*mediasoup.ConsumerStat{
+				{Type: "outbound-rtp", Bitrate: 300000, RoundTripTime: 50, Score: 9},
+				{Type: "inbound-rtp", Bitrate: 400000},
+			},
+		},
+		{
+			EntityType: mediasoup.StatsEntityType_Consumer,
+			EntityId:   "unknown",
+			Stats:      []*mediasoup.ConsumerStat{{Type: "outbound-rtp", Bitrate: 1}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, 400000, testutil.ToFloat64(exporter.producerBitrate.WithLabelValues("p1", "video")))
+	assert.EqualValues(t, 7, testutil.ToFloat64(exporter.producerScore.WithLabelValues("p1", "video")))
+	assert.EqualValues(t, 300000, testutil.ToFloat64(exporter.consumerBitrate))
+	assert.EqualValues(t, 0.05, testutil.ToFloat64(exporter.consumerRoundTripTime.WithLabelValues("c1", "p1", "video")))
+}
+
+func TestPublishIPCStats(t *testing.T) {
+	exporter := NewExporter(Options{StatsInterval: -1})
+
+	exporter.ipcStats.Store("10", func() mediasoup.WorkerIPCStats {
+		return mediasoup.WorkerIPCStats{
+			Channel:        mediasoup.ChannelStats{PendingRequests: 3},
+			PayloadChannel: mediasoup.ChannelStats{MaxDispatchLatency: time.Second},
+		}
+	})
+	exporter.publishIPCStats()
+
+	assert.EqualValues(t, 3, testutil.ToFloat64(exporter.channelPendingRequests.WithLabelValues("10", "channel")))
+	assert.EqualValues(t, 1, testutil.ToFloat64(exporter.channelMaxDispatchLatency.WithLabelValues("10", "payload_channel")))
+}
@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package netstring
+
+import "testing"
+
+func FuzzDecoderFeed(f *testing.F) {
+	f.Add([]byte("5:hello,"))
+	f.Add([]byte("3:ab"))
+	f.Add([]byte("99999999999999999999999:data,"))
+	f.Add([]byte(":,"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Keep the results within the buffer of the decoder, nobody reads them.
+		if len(data) > BUFFER_SIZE {
+			return
+		}
+		decoder := NewDecoder()
+		decoder.Feed(data)
+	})
+}
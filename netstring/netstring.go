@@ -18,6 +18,10 @@ const (
 	PARSE_END
 )
 
+// MAX_LENGTH is the maximum payload length, the one of the mediasoup worker
+// messages. Lengths above are invalid, which also prevents integer overflows.
+const MAX_LENGTH int = 4194304
+
 func Encode(payload []byte) (raw []byte) {
 	length := strconv.Itoa(len(payload))
 
@@ -87,6 +91,10 @@ func (decoder *Decoder) parseLength(i int, data []byte) int {
 	if symbol >= '0' && symbol <= '9' {
 		decoder.length = (decoder.length * 10) + (int(symbol) - 48)
 		i++
+
+		if decoder.length > MAX_LENGTH {
+			decoder.Reset()
+		}
 	} else {
 		decoder.state = PARSE_SEPARATOR
 	}
@@ -68,3 +68,20 @@ func BenchmarkDecode(b *testing.B) {
 		<-decoder.Result()
 	}
 }
+
+func TestNetstringInvalidLength(t *testing.T) {
+	decoder := NewDecoder()
+
+	assert.NotPanics(t, func() {
+		decoder.Feed([]byte("99999999999999999999999:data,"))
+	})
+	assert.Empty(t, decoder.Result())
+
+	decoder.Reset()
+	decoder.Feed([]byte("4194305:data,"))
+	assert.Empty(t, decoder.Result())
+
+	decoder.Reset()
+	decoder.Feed(Encode([]byte("valid")))
+	assert.Equal(t, "valid", string(<-decoder.Result()))
+}
@@ -0,0 +1,166 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jiyeyuran/go-eventemitter"
+)
+
+/**
+ * An event emitted by the observer of an entity.
+ */
+type ObserverEvent struct {
+	/**
+	 * "worker", "router", "transport", "producer", "consumer", "dataproducer",
+	 * "dataconsumer" or "rtpobserver".
+	 */
+	EntityType string        `json:"entityType"`
+	EntityId   string        `json:"entityId"`
+	Event      string        `json:"event"`
+	Payload    []interface{} `json:"payload,omitempty"`
+	Timestamp  time.Time     `json:"timestamp"`
+	// Labels of the Worker of the entity, see WorkerSettings.Labels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+/**
+ * ObserverEventSink receives the observer events of every entity. Publish is
+ * called synchronously by the goroutine emitting the event, so it must not
+ * block.
+ */
+type ObserverEventSink interface {
+	Publish(event ObserverEvent) error
+}
+
+/**
+ * ObserverEventSinkFunc is an ObserverEventSink calling the function.
+ */
+type ObserverEventSinkFunc func(event ObserverEvent) error
+
+func (fn ObserverEventSinkFunc) Publish(event ObserverEvent) error {
+	return fn(event)
+}
+
+type channelObserverEventSink chan<- ObserverEvent
+
+/**
+ * Create an ObserverEventSink sending the events into the given channel. An
+ * event is dropped, and an error returned, if the channel is full.
+ */
+func NewChannelObserverEventSink(ch chan<- ObserverEvent) ObserverEventSink {
+	return channelObserverEventSink(ch)
+}
+
+func (ch channelObserverEventSink) Publish(event ObserverEvent) error {
+	select {
+	case ch <- event:
+		return nil
+	default:
+		return NewInvalidStateError("observer event channel is full, event dropped")
+	}
+}
+
+type jsonLinesObserverEventSink struct {
+	locker  sync.Mutex
+	encoder *json.Encoder
+}
+
+/**
+ * Create an ObserverEventSink writing every event as a JSON line (e.g. into an
+ * os.File). Entities in the payload are written as their id and errors as
+ * their message.
+ */
+func NewJSONLinesObserverEventSink(w io.Writer) ObserverEventSink {
+	return &jsonLinesObserverEventSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonLinesObserverEventSink) Publish(event ObserverEvent) error {
+	payload := make([]interface{}, len(event.Payload))
+
+	for i, arg := range event.Payload {
+		switch v := arg.(type) {
+		case interface{ Id() string }:
+			payload[i] = v.Id()
+		case error:
+			payload[i] = v.Error()
+		default:
+			payload[i] = v
+		}
+	}
+	event.Payload = payload
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.encoder.Encode(event)
+}
+
+type observerEventSinkHolder struct {
+	sink ObserverEventSink
+}
+
+var observerEventSink atomic.Value
+
+/**
+ * Set the sink receiving the observer events of every entity, or remove it if
+ * nil. Entities created before still use it.
+ */
+func SetObserverEventSink(sink ObserverEventSink) {
+	observerEventSink.Store(observerEventSinkHolder{sink: sink})
+}
+
+// auditedEmitter is the observer of an entity, publishing its events into the
+// observer event sink.
+type auditedEmitter struct {
+	IEventEmitter
+	entityType string
+	entityId   string
+	labels     map[string]string
+}
+
+// newObserver creates the observer of an entity, its events being published
+// with the labels of the context.
+func (c loggerContext) newObserver(entityType, entityId string) IEventEmitter {
+	return auditedEmitter{
+		IEventEmitter: NewEventEmitter(),
+		entityType:    entityType,
+		entityId:      entityId,
+		labels:        c.labels,
+	}
+}
+
+func (e auditedEmitter) Emit(evt string, args ...interface{}) bool {
+	e.audit(evt, args)
+
+	return e.IEventEmitter.Emit(evt, args...)
+}
+
+func (e auditedEmitter) SafeEmit(evt string, args ...interface{}) eventemitter.AysncResult {
+	e.audit(evt, args)
+
+	return e.IEventEmitter.SafeEmit(evt, args...)
+}
+
+func (e auditedEmitter) audit(evt string, args []interface{}) {
+	holder, _ := observerEventSink.Load().(observerEventSinkHolder)
+
+	if holder.sink == nil {
+		return
+	}
+
+	err := holder.sink.Publish(ObserverEvent{
+		EntityType: e.entityType,
+		EntityId:   e.entityId,
+		Event:      evt,
+		Payload:    args,
+		Timestamp:  time.Now(),
+		Labels:     e.labels,
+	})
+	if err != nil {
+		NewLogger("ObserverEventSink").Warn("publishing observer event failed: %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+package mediasoup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserverEventSink(t *testing.T) {
+	ch := make(chan ObserverEvent, 2)
+
+	SetObserverEventSink(NewChannelObserverEventSink(ch))
+	defer SetObserverEventSink(nil)
+
+	observer := loggerContext{}.newObserver("producer", "p1")
+	observer.Emit("pause")
+	observer.SafeEmit("score", []ProducerScore{{Score: 10}}).Wait()
+
+	event := <-ch
+	assert.Equal(t, "producer", event.EntityType)
+	assert.Equal(t, "p1", event.EntityId)
+	assert.Equal(t, "pause", event.Event)
+	assert.Empty(t, event.Payload)
+	assert.False(t, event.Timestamp.IsZero())
+
+	event = <-ch
+	assert.Equal(t, "score", event.Event)
+	assert.Len(t, event.Payload, 1)
+
+	SetObserverEventSink(nil)
+	observer.Emit("resume")
+	assert.Len(t, ch, 0)
+}
+
+type testEntity struct{ id string }
+
+func (e testEntity) Id() string { return e.id }
+
+func TestJSONLinesObserverEventSink(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	sink := NewJSONLinesObserverEventSink(buf)
+
+	require.NoError(t, sink.Publish(ObserverEvent{
+		EntityType: "transport",
+		EntityId:   "t1",
+		Event:      "newproducer",
+		Payload:    []interface{}{testEntity{id: "p1"}, errors.New("failed"), 1},
+	}))
+
+	var line struct {
+		EntityId string
+		Event    string
+		Payload  []interface{}
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "t1", line.EntityId)
+	assert.Equal(t, []interface{}{"p1", "failed", 1.0}, line.Payload)
+}
+
+func TestWorkerLabels(t *testing.T) {
+	ch := make(chan ObserverEvent, 10)
+
+	SetObserverEventSink(NewChannelObserverEventSink(ch))
+	defer SetObserverEventSink(nil)
+
+	buf := bytes.NewBuffer(nil)
+	labels := map[string]string{"tenant": "acme", "region": "eu"}
+
+	mock, err := NewMockWorker(WithLabels(labels), WithLogger(NewZerologLogger(zerolog.New(buf))))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, labels, mock.Labels())
+	assert.Equal(t, labels, router.Labels())
+
+	require.NotZero(t, len(ch))
+	for len(ch) > 0 {
+		assert.Equal(t, labels, (<-ch).Labels)
+	}
+
+	buf.Reset()
+	router.logger.Warn("labeled")
+
+	var line map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &line))
+	assert.Equal(t, "acme", line["tenant"])
+	assert.Equal(t, "eu", line["region"])
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/jiyeyuran/mediasoup-go/h264"
+	"github.com/jiyeyuran/mediasoup-go/h265"
 )
 
 var DYNAMIC_PAYLOAD_TYPES = [...]byte{
@@ -18,6 +20,15 @@ var DYNAMIC_PAYLOAD_TYPES = [...]byte{
 	57, 58, 59, 60, 61, 62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 }
 
+/**
+ * CodecPolicy selects the codecs of a Consumer. It is given the media and FEC
+ * codecs of the Producer supported by the consuming endpoint, in preference
+ * order, and returns the ones to use, in the desired order. The first media
+ * codec is the one the Consumer sends. RTX codecs follow their media codec.
+ * Returning no media codec vetoes the Consumer.
+ */
+type CodecPolicy func(codecs []*RtpCodecParameters) []*RtpCodecParameters
+
 type matchOptions struct {
 	strict bool
 	modify bool
@@ -45,6 +56,10 @@ type RtpMappingEncoding struct {
  * fields with default values.
  */
 func validateRtpCapabilities(params *RtpCapabilities) (err error) {
+	if params == nil {
+		return NewTypeError("missing rtpCapabilities")
+	}
+
 	for _, codec := range params.Codecs {
 		if err = validateRtpCodecCapability(codec); err != nil {
 			return
@@ -65,6 +80,10 @@ func validateRtpCapabilities(params *RtpCapabilities) (err error) {
  * fields with default values.
  */
 func validateRtpCodecCapability(code *RtpCodecCapability) (err error) {
+	if code == nil {
+		return NewTypeError("missing codec")
+	}
+
 	mimeType := strings.ToLower(code.MimeType)
 
 	//  mimeType is mandatory.
@@ -84,6 +103,12 @@ func validateRtpCodecCapability(code *RtpCodecCapability) (err error) {
 		code.Channels = 1
 	}
 
+	if mimeType == "audio/multiopus" {
+		if err = validateMultiopusParameters(code.Channels, code.Parameters); err != nil {
+			return
+		}
+	}
+
 	for _, fb := range code.RtcpFeedback {
 		if err = validateRtcpFeedback(fb); err != nil {
 			return
@@ -93,6 +118,54 @@ func validateRtpCodecCapability(code *RtpCodecCapability) (err error) {
 	return
 }
 
+/**
+ * Validates the parameters of a multiopus codec as defined in the opus
+ * multistream SDP parameters (num_streams, coupled_streams and
+ * channel_mapping).
+ */
+func validateMultiopusParameters(channels int, params RtpCodecSpecificParameters) error {
+	// channels is mandatory and must be greater than 2.
+	if channels <= 2 {
+		return NewTypeError("invalid codec.channels for multiopus")
+	}
+
+	numStreams := params.GetInt("num_streams")
+	coupledStreams := params.GetInt("coupled_streams")
+	channelMapping := params.GetString("channel_mapping")
+
+	// num_streams is mandatory.
+	if numStreams == 0 {
+		return NewTypeError("missing codec.parameters.num_streams")
+	}
+
+	if coupledStreams > numStreams {
+		return NewTypeError("codec.parameters.coupled_streams greater than num_streams")
+	}
+
+	// channel_mapping is optional. If given it must have an entry per channel.
+	if len(channelMapping) == 0 {
+		return nil
+	}
+
+	mapping := strings.Split(channelMapping, ",")
+
+	if len(mapping) != channels {
+		return NewTypeError("invalid codec.parameters.channel_mapping length")
+	}
+
+	totalStreams := numStreams + coupledStreams
+
+	for _, entry := range mapping {
+		index, err := strconv.Atoi(strings.TrimSpace(entry))
+		// 255 means a silent channel.
+		if err != nil || index < 0 || (index >= totalStreams && index != 255) {
+			return NewTypeError("invalid codec.parameters.channel_mapping entry %q", entry)
+		}
+	}
+
+	return nil
+}
+
 /**
  * Validates RtcpFeedback. It may modify given data by adding missing
  * fields with default values.
@@ -109,6 +182,10 @@ func validateRtcpFeedback(fb RtcpFeedback) error {
  * fields with default values.
  */
 func validateRtpHeaderExtension(ext *RtpHeaderExtension) (err error) {
+	if ext == nil {
+		return NewTypeError("missing ext")
+	}
+
 	if len(ext.Kind) > 0 && ext.Kind != MediaKind_Audio && ext.Kind != MediaKind_Video {
 		return NewTypeError("invalid ext.kind")
 	}
@@ -136,6 +213,10 @@ func validateRtpHeaderExtension(ext *RtpHeaderExtension) (err error) {
  * fields with default values.
  */
 func validateRtpParameters(params *RtpParameters) (err error) {
+	if params == nil {
+		return NewTypeError("missing rtpParameters")
+	}
+
 	for _, codec := range params.Codecs {
 		if err = validateRtpCodecParameters(codec); err != nil {
 			return
@@ -156,6 +237,10 @@ func validateRtpParameters(params *RtpParameters) (err error) {
  * fields with default values.
  */
 func validateRtpCodecParameters(code *RtpCodecParameters) (err error) {
+	if code == nil {
+		return NewTypeError("missing codec")
+	}
+
 	mimeType := strings.ToLower(code.MimeType)
 
 	//  mimeType is mandatory.
@@ -175,6 +260,12 @@ func validateRtpCodecParameters(code *RtpCodecParameters) (err error) {
 		code.Channels = 1
 	}
 
+	if mimeType == "audio/multiopus" {
+		if err = validateMultiopusParameters(code.Channels, code.Parameters); err != nil {
+			return
+		}
+	}
+
 	for _, fb := range code.RtcpFeedback {
 		if err = validateRtcpFeedback(fb); err != nil {
 			return
@@ -301,6 +392,77 @@ func validateSctpStreamParameters(params *SctpStreamParameters) (err error) {
 	return
 }
 
+/**
+ * Reorder the codecs of the given Router RTP capabilities so the ones with the
+ * given mimeTypes come first, in the given order. If exclusive, codecs of the
+ * same kind as any of the given ones are removed unless listed. RTX codecs
+ * follow their associated codec and FEC codecs are never removed. Payload
+ * types are kept.
+ */
+func applyCodecPreferences(caps RtpCapabilities, mimeTypes []string, exclusive bool) (newCaps RtpCapabilities, err error) {
+	var (
+		mediaCodecs    []*RtpCodecCapability
+		preferred      []*RtpCodecCapability
+		rest           []*RtpCodecCapability
+		preferredKinds = map[MediaKind]bool{}
+	)
+
+	for _, codec := range caps.Codecs {
+		if !codec.isRtxCodec() {
+			mediaCodecs = append(mediaCodecs, codec)
+		}
+	}
+
+	for _, mimeType := range mimeTypes {
+		found := false
+
+		for _, codec := range mediaCodecs {
+			if strings.EqualFold(codec.MimeType, mimeType) {
+				preferred = append(preferred, codec)
+				preferredKinds[codec.Kind] = true
+				found = true
+			}
+		}
+
+		if !found {
+			err = NewTypeError("codec not found in Router RTP capabilities [mimeType:%s]", mimeType)
+			return
+		}
+	}
+
+	for _, codec := range mediaCodecs {
+		isPreferred := false
+
+		for _, preferredCodec := range preferred {
+			if codec == preferredCodec {
+				isPreferred = true
+				break
+			}
+		}
+		if isPreferred {
+			continue
+		}
+		if exclusive && preferredKinds[codec.Kind] && !codec.isFecCodec() {
+			continue
+		}
+		rest = append(rest, codec)
+	}
+
+	newCaps.HeaderExtensions = caps.HeaderExtensions
+
+	for _, codec := range append(preferred, rest...) {
+		newCaps.Codecs = append(newCaps.Codecs, codec)
+
+		for _, rtxCodec := range caps.Codecs {
+			if rtxCodec.isRtxCodec() && rtxCodec.Parameters.GetInt("apt") == int(codec.PreferredPayloadType) {
+				newCaps.Codecs = append(newCaps.Codecs, rtxCodec)
+			}
+		}
+	}
+
+	return
+}
+
 /**
  * Generate RTP capabilities for the Router based on the given media codecs and
  * mediasoup supported RTP capabilities.
@@ -360,13 +522,17 @@ func generateRouterRtpCapabilities(mediaCodecs []*RtpCodecCapability) (caps RtpC
 		}
 
 		// Merge the media codec parameters.
-		override(&codec.Parameters, mediaCodec.Parameters)
+		for _, param := range mediaCodec.Parameters {
+			codec.Parameters.Set(param.Key, param.Value)
+		}
 
 		// Append to the codec list.
 		caps.Codecs = append(caps.Codecs, codec)
 
-		// Add a RTX video codec if video.
-		if codec.Kind == MediaKind_Video {
+		// Add a RTX video codec if video. ULPFEC and FlexFEC packets are never
+		// retransmitted.
+		if mimeType := strings.ToLower(codec.MimeType); codec.Kind == MediaKind_Video &&
+			mimeType != "video/ulpfec" && mimeType != "video/flexfec-03" {
 			if len(dynamicPayloadTypes) == 0 {
 				err = errors.New("cannot allocate more dynamic codec payload types")
 				return
@@ -380,7 +546,7 @@ func generateRouterRtpCapabilities(mediaCodecs []*RtpCodecCapability) (caps RtpC
 				PreferredPayloadType: pt,
 				ClockRate:            codec.ClockRate,
 				Parameters: RtpCodecSpecificParameters{
-					Apt: codec.PreferredPayloadType,
+					{Key: "apt", Value: int(codec.PreferredPayloadType)},
 				},
 				RtcpFeedback: []RtcpFeedback{},
 			}
@@ -424,7 +590,7 @@ func getProducerRtpParametersMapping(params RtpParameters, caps RtpCapabilities)
 
 		// Search for the associated media codec.
 		for _, mediaCodec := range params.Codecs {
-			if mediaCodec.PayloadType == codec.Parameters.Apt {
+			if codec.Parameters.GetInt("apt") == int(mediaCodec.PayloadType) {
 				associatedMediaCodec = mediaCodec
 				break
 			}
@@ -443,7 +609,7 @@ func getProducerRtpParametersMapping(params RtpParameters, caps RtpCapabilities)
 			if !capCodec.isRtxCodec() {
 				continue
 			}
-			if capCodec.Parameters.Apt == capMediaCodec.PreferredPayloadType {
+			if capCodec.Parameters.GetInt("apt") == int(capMediaCodec.PreferredPayloadType) {
 				associatedCapRtxCodec = capCodec
 				break
 			}
@@ -514,6 +680,11 @@ func getConsumableRtpParameters(
 				break
 			}
 		}
+
+		if matchedCapCodec == nil {
+			err = NewTypeError("no capability codec mapped to codec PT %d", codec.PayloadType)
+			return
+		}
 		consumableCodec := &RtpCodecParameters{
 			MimeType:     matchedCapCodec.MimeType,
 			ClockRate:    matchedCapCodec.ClockRate,
@@ -530,7 +701,7 @@ func getConsumableRtpParameters(
 
 		for _, capRtxCodec := range caps.Codecs {
 			if capRtxCodec.isRtxCodec() &&
-				capRtxCodec.Parameters.Apt == consumableCodec.PayloadType {
+				capRtxCodec.Parameters.GetInt("apt") == int(consumableCodec.PayloadType) {
 				consumableCapRtxCodec = capRtxCodec
 				break
 			}
@@ -564,6 +735,11 @@ func getConsumableRtpParameters(
 		consumableParams.HeaderExtensions = append(consumableParams.HeaderExtensions, consumableExt)
 	}
 
+	if len(rtpMapping.Encodings) < len(params.Encodings) {
+		err = NewTypeError("missing encodings in rtpMapping")
+		return
+	}
+
 	for i, encoding := range params.Encodings {
 		// Remove useless fields.
 		encoding.Rid = ""
@@ -607,11 +783,13 @@ func canConsume(consumableParams RtpParameters, caps RtpCapabilities) (ok bool,
 	}
 
 	// Ensure there is at least one media codec.
-	if len(matchingCodecs) == 0 || matchingCodecs[0].isRtxCodec() {
-		return
+	for _, codec := range matchingCodecs {
+		if !codec.isRtxCodec() && !codec.isFecCodec() {
+			return true, nil
+		}
 	}
 
-	return true, nil
+	return
 }
 
 /**
@@ -622,7 +800,16 @@ func canConsume(consumableParams RtpParameters, caps RtpCapabilities) (ok bool,
  * or disabled RTX.
  *
  */
-func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabilities, pipe bool) (consumerParams RtpParameters, err error) {
+func getConsumerRtpParameters(
+	consumableParams RtpParameters,
+	caps RtpCapabilities,
+	pipe bool,
+	policy CodecPolicy,
+) (consumerParams RtpParameters, err error) {
+	if err = checkNilEntries(&consumableParams, &caps); err != nil {
+		return
+	}
+
 	for _, capCodec := range caps.Codecs {
 		if err = validateRtpCodecCapability(capCodec); err != nil {
 			return
@@ -643,9 +830,19 @@ func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabiliti
 
 		codec.RtcpFeedback = matchedCapCodec.RtcpFeedback
 
+		if mimeType := strings.ToLower(codec.MimeType); mimeType == "audio/opus" || mimeType == "audio/multiopus" {
+			reduceOpusParameters(&codec.Parameters, matchedCapCodec.Parameters)
+		}
+
 		consumerParams.Codecs = append(consumerParams.Codecs, codec)
 	}
 
+	if policy != nil {
+		if consumerParams.Codecs, err = applyCodecPolicy(consumerParams.Codecs, policy); err != nil {
+			return
+		}
+	}
+
 	codecs := consumerParams.Codecs[:0]
 
 	// Must sanitize the list of matched codecs by removing useless RTX codecs.
@@ -653,7 +850,7 @@ func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabiliti
 		if codec.isRtxCodec() {
 			// Search for the associated media codec.
 			for _, mediaCodec := range consumerParams.Codecs {
-				if mediaCodec.PayloadType == codec.Parameters.Apt {
+				if codec.Parameters.GetInt("apt") == int(mediaCodec.PayloadType) {
 					rtxSupported = true
 					codecs = append(codecs, codec)
 					break
@@ -666,15 +863,33 @@ func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabiliti
 
 	consumerParams.Codecs = codecs
 
-	// Ensure there is at least one media codec.
-	if len(consumerParams.Codecs) == 0 || consumerParams.Codecs[0].isRtxCodec() {
+	// Ensure there is at least one media codec and that it goes first, since
+	// RED and FEC codecs may be preferred by the Producer.
+	mediaCodecIdx := -1
+
+	for i, codec := range consumerParams.Codecs {
+		if !codec.isRtxCodec() && !codec.isFecCodec() {
+			mediaCodecIdx = i
+			break
+		}
+	}
+
+	if mediaCodecIdx < 0 {
 		err = NewUnsupportedError("no compatible media codecs")
 		return
 	}
 
+	if mediaCodecIdx > 0 {
+		mediaCodec := consumerParams.Codecs[mediaCodecIdx]
+		copy(consumerParams.Codecs[1:mediaCodecIdx+1], consumerParams.Codecs[:mediaCodecIdx])
+		consumerParams.Codecs[0] = mediaCodec
+	}
+
 	for _, ext := range consumableParams.HeaderExtensions {
 		for _, capExt := range caps.HeaderExtensions {
 			if capExt.PreferredId == ext.Id && capExt.Uri == ext.Uri {
+				// Encrypt it if either the Router or the remote endpoint prefers so.
+				ext.Encrypt = ext.Encrypt || capExt.PreferredEncrypt
 				consumerParams.HeaderExtensions = append(consumerParams.HeaderExtensions, ext)
 				break
 			}
@@ -760,12 +975,22 @@ func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabiliti
 
 	// If there is simulast, mangle spatial layers in scalabilityMode.
 	if len(consumableParams.Encodings) > 1 {
-		temporalLayers := ParseScalabilityMode(scalabilityMode).TemporalLayers
+		temporalLayers := ParseScalabilityModeForCodec(
+			scalabilityMode, consumerParams.Codecs[0].MimeType, len(consumableParams.Encodings)).TemporalLayers
 		scalabilityMode = fmt.Sprintf("S%dT%d", len(consumableParams.Encodings), temporalLayers)
 	}
 
 	consumerEncoding.ScalabilityMode = scalabilityMode
 
+	// If any of the consumableParams.encodings uses DTX, let the Consumer use it
+	// as well so the remote endpoint does not consider the stream inactive.
+	for _, encoding := range consumableParams.Encodings {
+		if encoding.Dtx {
+			consumerEncoding.Dtx = true
+			break
+		}
+	}
+
 	maxEncodingMaxBitrate := 0
 
 	// Use the maximum maxBitrate in any encoding and honor it in the Consumer's encoding.
@@ -788,6 +1013,45 @@ func getConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabiliti
 	return
 }
 
+/**
+ * Let the given policy select the non RTX codecs and place the RTX codecs after
+ * their media codec.
+ */
+func applyCodecPolicy(codecs []*RtpCodecParameters, policy CodecPolicy) (selectedCodecs []*RtpCodecParameters, err error) {
+	var candidates []*RtpCodecParameters
+
+	for _, codec := range codecs {
+		if !codec.isRtxCodec() {
+			candidates = append(candidates, codec)
+		}
+	}
+
+	for _, codec := range policy(candidates) {
+		isCandidate := false
+
+		for _, candidate := range candidates {
+			if candidate == codec {
+				isCandidate = true
+				break
+			}
+		}
+		if !isCandidate {
+			err = NewTypeError("codec policy returned an unknown codec [mimeType:%s]", codec.MimeType)
+			return
+		}
+
+		selectedCodecs = append(selectedCodecs, codec)
+
+		for _, rtxCodec := range codecs {
+			if rtxCodec.isRtxCodec() && rtxCodec.Parameters.GetInt("apt") == int(codec.PayloadType) {
+				selectedCodecs = append(selectedCodecs, rtxCodec)
+			}
+		}
+	}
+
+	return
+}
+
 /**
  * Generate RTP parameters for a pipe Consumer.
  *
@@ -801,7 +1065,7 @@ func getPipeConsumerRtpParameters(consumableParams RtpParameters, enableRtx bool
 	clone(consumableParams.Codecs, &consumableCodecs)
 
 	for _, codec := range consumableCodecs {
-		if !enableRtx && codec.isRtxCodec() {
+		if codec == nil || !enableRtx && codec.isRtxCodec() {
 			continue
 		}
 
@@ -887,44 +1151,101 @@ func matchCodecs(aCodec *RtpCodecParameters, bCodec *RtpCodecCapability, options
 
 	switch aMimeType {
 	case "audio/multiopus":
-		aNumStreams := aCodec.Parameters.NumStreams
-		bNumstreams := bCodec.Parameters.NumStreams
+		aNumStreams := aCodec.Parameters.GetInt("num_streams")
+		bNumstreams := bCodec.Parameters.GetInt("num_streams")
 
 		if aNumStreams != bNumstreams {
 			return false
 		}
 
-		aCoupledStreams := aCodec.Parameters.CoupledStreams
-		bCoupledStreams := bCodec.Parameters.CoupledStreams
+		aCoupledStreams := aCodec.Parameters.GetInt("coupled_streams")
+		bCoupledStreams := bCodec.Parameters.GetInt("coupled_streams")
 
 		if aCoupledStreams != bCoupledStreams {
 			return false
 		}
 
-	case "video/h264":
+	case "video/vp9":
+		// If strict matching check profile-id.
+		if options.strict {
+			aProfileId := aCodec.Parameters.GetString("profile-id")
+			bProfileId := bCodec.Parameters.GetString("profile-id")
+
+			if len(aProfileId) == 0 {
+				aProfileId = "0"
+			}
+			if len(bProfileId) == 0 {
+				bProfileId = "0"
+			}
+			if aProfileId != bProfileId {
+				return false
+			}
+		}
+
+	case "video/h264", "video/h264-svc":
 		aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
 
-		if aParameters.PacketizationMode != bParameters.PacketizationMode {
+		selectedProfileLevelId, ok := h264.MatchParameters(
+			aParameters.h264(), bParameters.h264(), options.strict)
+		if !ok {
 			return
 		}
 
-		if options.strict {
-			selectedProfileLevelId, err := h264.GenerateProfileLevelIdForAnswer(
-				aParameters.RtpParameter, bParameters.RtpParameter)
-			if err != nil {
-				return
-			}
+		if options.strict && options.modify {
+			aParameters.Set("profile-level-id", selectedProfileLevelId)
+			aCodec.Parameters = aParameters
+		}
 
-			if options.modify {
-				aParameters.ProfileLevelId = selectedProfileLevelId
-				aCodec.Parameters = aParameters
-			}
+	case "video/h265":
+		aParameters, bParameters := aCodec.Parameters, bCodec.Parameters
+
+		selectedLevelId, ok := h265.MatchParameters(
+			aParameters.GetString("profile-id"), aParameters.h265(),
+			bParameters.GetString("profile-id"), bParameters.h265(),
+			options.strict)
+		if !ok {
+			return
+		}
+
+		if options.strict && options.modify {
+			aParameters.Set("level-id", selectedLevelId)
+			aCodec.Parameters = aParameters
 		}
 	}
 
 	return true
 }
 
+/**
+ * Reduce Opus parameters of a Consumer codec given the parameters of the
+ * matching capability codec of the receiving endpoint.
+ *
+ * Sender properties (sprop-stereo, useinbandfec, usedtx, ptime and cbr) are
+ * kept from the Producer while receiver preferences (stereo, maxplaybackrate
+ * and maxaveragebitrate) are taken from the endpoint capabilities, honoring the
+ * lowest limit if both sides set one.
+ */
+func reduceOpusParameters(params *RtpCodecSpecificParameters, capParams RtpCodecSpecificParameters) {
+	if capParams.Has("stereo") {
+		params.Set("stereo", capParams.GetInt("stereo"))
+	} else {
+		params.Delete("stereo")
+	}
+
+	for _, key := range []string{"maxplaybackrate", "maxaveragebitrate"} {
+		if value := minNonZero(params.GetInt(key), capParams.GetInt(key)); value > 0 {
+			params.Set(key, value)
+		}
+	}
+}
+
+func minNonZero(a, b int) int {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
 func matchHeaderExtensionUri(exts []RtpHeaderExtensionParameters, uri string) bool {
 	for _, ext := range exts {
 		if ext.Uri == uri {
@@ -946,3 +1267,104 @@ func filterRtcpFeedback(arr []RtcpFeedback, cond func(RtcpFeedback) bool) []Rtcp
 
 	return newArr
 }
+
+/**
+ * Validates RtpCapabilities. It may modify given data by adding missing
+ * fields with default values.
+ */
+func ValidateRtpCapabilities(params *RtpCapabilities) error {
+	return validateRtpCapabilities(params)
+}
+
+/**
+ * Validates RtpParameters. It may modify given data by adding missing
+ * fields with default values.
+ */
+func ValidateRtpParameters(params *RtpParameters) error {
+	return validateRtpParameters(params)
+}
+
+/**
+ * Get a mapping of the codec payload, RTP header extensions and encodings from
+ * the given Producer RTP parameters to the values expected by the Router.
+ */
+func GetProducerRtpParametersMapping(params RtpParameters, caps RtpCapabilities) (RtpMapping, error) {
+	if err := checkNilEntries(&params, &caps); err != nil {
+		return RtpMapping{}, err
+	}
+	return getProducerRtpParametersMapping(params, caps)
+}
+
+/**
+ * Generate RTP parameters for Consumers given the RTP parameters of a Producer
+ * and the RTP capabilities of the Router.
+ */
+func GetConsumableRtpParameters(
+	kind MediaKind,
+	params RtpParameters,
+	caps RtpCapabilities,
+	rtpMapping RtpMapping,
+) (RtpParameters, error) {
+	if err := checkNilEntries(&params, &caps); err != nil {
+		return RtpParameters{}, err
+	}
+	return getConsumableRtpParameters(kind, params, caps, rtpMapping)
+}
+
+/**
+ * Check whether the given RTP capabilities can consume the given Producer.
+ */
+func CanConsume(consumableParams RtpParameters, caps RtpCapabilities) (bool, error) {
+	if err := checkNilEntries(&consumableParams, &caps); err != nil {
+		return false, err
+	}
+	return canConsume(consumableParams, caps)
+}
+
+/**
+ * Generate RTP parameters for a specific Consumer.
+ */
+func GetConsumerRtpParameters(consumableParams RtpParameters, caps RtpCapabilities, pipe bool) (RtpParameters, error) {
+	return getConsumerRtpParameters(consumableParams, caps, pipe, nil)
+}
+
+/**
+ * Generate RTP parameters for a pipe Consumer.
+ */
+func GetPipeConsumerRtpParameters(consumableParams RtpParameters, enableRtx bool) RtpParameters {
+	return getPipeConsumerRtpParameters(consumableParams, enableRtx)
+}
+
+/**
+ * Whether the given RTP codec parameters match the given codec capability.
+ * If strict is true, codec specific parameters (such as H264 profile-level-id)
+ * must be compatible too.
+ */
+func MatchCodecs(aCodec *RtpCodecParameters, bCodec *RtpCodecCapability, strict bool) bool {
+	if aCodec == nil || bCodec == nil {
+		return false
+	}
+	return matchCodecs(aCodec, bCodec, matchOptions{strict: strict})
+}
+
+// checkNilEntries returns a TypeError if a codec or a header extension of the
+// given RtpParameters or RtpCapabilities is nil.
+func checkNilEntries(params *RtpParameters, caps *RtpCapabilities) error {
+	for _, codec := range params.Codecs {
+		if codec == nil {
+			return NewTypeError("missing codec in rtpParameters")
+		}
+	}
+	for _, codec := range caps.Codecs {
+		if codec == nil {
+			return NewTypeError("missing codec in rtpCapabilities")
+		}
+	}
+	for _, ext := range caps.HeaderExtensions {
+		if ext == nil {
+			return NewTypeError("missing ext in rtpCapabilities")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,485 @@
+// Package ortc exposes the client side RTP negotiation helpers used by
+// mediasoup-client, so that Go programs (bots, gateways, etc.) can generate
+// parameters against a remote mediasoup server. The server side ones are the
+// ones of the mediasoup package, e.g. mediasoup.ValidateRtpCapabilities() and
+// mediasoup.GetConsumerRtpParameters().
+package ortc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Extended RTP capabilities resulting from matching local capabilities with
+ * the remote (mediasoup router) ones.
+ */
+type ExtendedRtpCapabilities struct {
+	Codecs           []*ExtendedCodec           `json:"codecs"`
+	HeaderExtensions []*ExtendedHeaderExtension `json:"headerExtensions"`
+}
+
+type ExtendedCodec struct {
+	Kind                 mediasoup.MediaKind                  `json:"kind"`
+	MimeType             string                               `json:"mimeType"`
+	ClockRate            int                                  `json:"clockRate"`
+	Channels             int                                  `json:"channels,omitempty"`
+	LocalPayloadType     byte                                 `json:"localPayloadType"`
+	LocalRtxPayloadType  byte                                 `json:"localRtxPayloadType,omitempty"`
+	RemotePayloadType    byte                                 `json:"remotePayloadType"`
+	RemoteRtxPayloadType byte                                 `json:"remoteRtxPayloadType,omitempty"`
+	LocalParameters      mediasoup.RtpCodecSpecificParameters `json:"localParameters"`
+	RemoteParameters     mediasoup.RtpCodecSpecificParameters `json:"remoteParameters"`
+	RtcpFeedback         []mediasoup.RtcpFeedback             `json:"rtcpFeedback"`
+}
+
+type ExtendedHeaderExtension struct {
+	Kind      mediasoup.MediaKind                   `json:"kind"`
+	Uri       string                                `json:"uri"`
+	SendId    int                                   `json:"sendId"`
+	RecvId    int                                   `json:"recvId"`
+	Encrypt   bool                                  `json:"encrypt"`
+	Direction mediasoup.RtpHeaderExtensionDirection `json:"direction"`
+}
+
+/**
+ * Generate extended RTP capabilities for sending and receiving.
+ */
+func GetExtendedRtpCapabilities(
+	localCaps, remoteCaps mediasoup.RtpCapabilities,
+) (extendedRtpCapabilities ExtendedRtpCapabilities) {
+	// Match media codecs and keep the order preferred by remoteCaps.
+	for _, remoteCodec := range remoteCaps.Codecs {
+		if isRtxCodec(remoteCodec.MimeType) {
+			continue
+		}
+		var matchingLocalCodec *mediasoup.RtpCodecCapability
+
+		for _, localCodec := range localCaps.Codecs {
+			if mediasoup.MatchCodecs(capabilityToParameters(localCodec), remoteCodec, true) {
+				matchingLocalCodec = localCodec
+				break
+			}
+		}
+
+		if matchingLocalCodec == nil {
+			continue
+		}
+
+		extendedCodec := &ExtendedCodec{
+			Kind:              matchingLocalCodec.Kind,
+			MimeType:          matchingLocalCodec.MimeType,
+			ClockRate:         matchingLocalCodec.ClockRate,
+			Channels:          matchingLocalCodec.Channels,
+			LocalPayloadType:  matchingLocalCodec.PreferredPayloadType,
+			RemotePayloadType: remoteCodec.PreferredPayloadType,
+			LocalParameters:   matchingLocalCodec.Parameters,
+			RemoteParameters:  remoteCodec.Parameters,
+			RtcpFeedback:      reduceRtcpFeedback(matchingLocalCodec.RtcpFeedback, remoteCodec.RtcpFeedback),
+		}
+
+		extendedRtpCapabilities.Codecs = append(extendedRtpCapabilities.Codecs, extendedCodec)
+	}
+
+	// Match RTX codecs.
+	for _, extendedCodec := range extendedRtpCapabilities.Codecs {
+		var matchingLocalRtxCodec, matchingRemoteRtxCodec *mediasoup.RtpCodecCapability
+
+		for _, localCodec := range localCaps.Codecs {
+			if isRtxCodec(localCodec.MimeType) && localCodec.Parameters.GetInt("apt") == int(extendedCodec.LocalPayloadType) {
+				matchingLocalRtxCodec = localCodec
+				break
+			}
+		}
+		for _, remoteCodec := range remoteCaps.Codecs {
+			if isRtxCodec(remoteCodec.MimeType) && remoteCodec.Parameters.GetInt("apt") == int(extendedCodec.RemotePayloadType) {
+				matchingRemoteRtxCodec = remoteCodec
+				break
+			}
+		}
+
+		if matchingLocalRtxCodec != nil && matchingRemoteRtxCodec != nil {
+			extendedCodec.LocalRtxPayloadType = matchingLocalRtxCodec.PreferredPayloadType
+			extendedCodec.RemoteRtxPayloadType = matchingRemoteRtxCodec.PreferredPayloadType
+		}
+	}
+
+	// Match header extensions.
+	for _, remoteExt := range remoteCaps.HeaderExtensions {
+		var matchingLocalExt *mediasoup.RtpHeaderExtension
+
+		for _, localExt := range localCaps.HeaderExtensions {
+			if matchHeaderExtensions(localExt, remoteExt) {
+				matchingLocalExt = localExt
+				break
+			}
+		}
+
+		if matchingLocalExt == nil {
+			continue
+		}
+
+		extendedExt := &ExtendedHeaderExtension{
+			Kind:      remoteExt.Kind,
+			Uri:       remoteExt.Uri,
+			SendId:    matchingLocalExt.PreferredId,
+			RecvId:    remoteExt.PreferredId,
+			Encrypt:   matchingLocalExt.PreferredEncrypt,
+			Direction: mediasoup.Direction_Sendrecv,
+		}
+
+		switch remoteExt.Direction {
+		case mediasoup.Direction_Recvonly:
+			extendedExt.Direction = mediasoup.Direction_Sendonly
+		case mediasoup.Direction_Sendonly:
+			extendedExt.Direction = mediasoup.Direction_Recvonly
+		case mediasoup.Direction_Inactive:
+			extendedExt.Direction = mediasoup.Direction_Inactive
+		}
+
+		extendedRtpCapabilities.HeaderExtensions = append(extendedRtpCapabilities.HeaderExtensions, extendedExt)
+	}
+
+	return
+}
+
+/**
+ * Generate RTP capabilities for receiving media based on the given extended
+ * RTP capabilities.
+ */
+func GetRecvRtpCapabilities(extendedRtpCapabilities ExtendedRtpCapabilities) (rtpCapabilities mediasoup.RtpCapabilities) {
+	for _, extendedCodec := range extendedRtpCapabilities.Codecs {
+		codec := &mediasoup.RtpCodecCapability{
+			Kind:                 extendedCodec.Kind,
+			MimeType:             extendedCodec.MimeType,
+			PreferredPayloadType: extendedCodec.RemotePayloadType,
+			ClockRate:            extendedCodec.ClockRate,
+			Channels:             extendedCodec.Channels,
+			Parameters:           extendedCodec.LocalParameters,
+			RtcpFeedback:         extendedCodec.RtcpFeedback,
+		}
+
+		rtpCapabilities.Codecs = append(rtpCapabilities.Codecs, codec)
+
+		// Add RTX codec.
+		if extendedCodec.RemoteRtxPayloadType == 0 {
+			continue
+		}
+
+		rtxCodec := &mediasoup.RtpCodecCapability{
+			Kind:                 extendedCodec.Kind,
+			MimeType:             fmt.Sprintf("%s/rtx", extendedCodec.Kind),
+			PreferredPayloadType: extendedCodec.RemoteRtxPayloadType,
+			ClockRate:            extendedCodec.ClockRate,
+			Parameters: mediasoup.RtpCodecSpecificParameters{
+				{Key: "apt", Value: int(extendedCodec.RemotePayloadType)},
+			},
+			RtcpFeedback: []mediasoup.RtcpFeedback{},
+		}
+
+		rtpCapabilities.Codecs = append(rtpCapabilities.Codecs, rtxCodec)
+	}
+
+	for _, extendedExtension := range extendedRtpCapabilities.HeaderExtensions {
+		// Ignore RTP extensions not valid for receiving.
+		if extendedExtension.Direction != mediasoup.Direction_Sendrecv &&
+			extendedExtension.Direction != mediasoup.Direction_Recvonly {
+			continue
+		}
+
+		ext := &mediasoup.RtpHeaderExtension{
+			Kind:             extendedExtension.Kind,
+			Uri:              extendedExtension.Uri,
+			PreferredId:      extendedExtension.RecvId,
+			PreferredEncrypt: extendedExtension.Encrypt,
+			Direction:        extendedExtension.Direction,
+		}
+
+		rtpCapabilities.HeaderExtensions = append(rtpCapabilities.HeaderExtensions, ext)
+	}
+
+	return
+}
+
+/**
+ * Generate RTP parameters of the given kind for sending media.
+ * NOTE: mid, encodings and rtcp fields are left empty.
+ */
+func GetSendingRtpParameters(
+	kind mediasoup.MediaKind,
+	extendedRtpCapabilities ExtendedRtpCapabilities,
+) (rtpParameters mediasoup.RtpParameters) {
+	for _, extendedCodec := range extendedRtpCapabilities.Codecs {
+		if extendedCodec.Kind != kind {
+			continue
+		}
+
+		codec := &mediasoup.RtpCodecParameters{
+			MimeType:     extendedCodec.MimeType,
+			PayloadType:  extendedCodec.LocalPayloadType,
+			ClockRate:    extendedCodec.ClockRate,
+			Channels:     extendedCodec.Channels,
+			Parameters:   extendedCodec.LocalParameters,
+			RtcpFeedback: extendedCodec.RtcpFeedback,
+		}
+
+		rtpParameters.Codecs = append(rtpParameters.Codecs, codec)
+
+		// Add RTX codec.
+		if extendedCodec.LocalRtxPayloadType > 0 {
+			rtxCodec := &mediasoup.RtpCodecParameters{
+				MimeType:    fmt.Sprintf("%s/rtx", extendedCodec.Kind),
+				PayloadType: extendedCodec.LocalRtxPayloadType,
+				ClockRate:   extendedCodec.ClockRate,
+				Parameters: mediasoup.RtpCodecSpecificParameters{
+					{Key: "apt", Value: int(extendedCodec.LocalPayloadType)},
+				},
+				RtcpFeedback: []mediasoup.RtcpFeedback{},
+			}
+
+			rtpParameters.Codecs = append(rtpParameters.Codecs, rtxCodec)
+		}
+	}
+
+	rtpParameters.HeaderExtensions = getSendingHeaderExtensions(kind, extendedRtpCapabilities)
+
+	return
+}
+
+/**
+ * Generate RTP parameters of the given kind suitable for the remote SDP answer.
+ */
+func GetSendingRemoteRtpParameters(
+	kind mediasoup.MediaKind,
+	extendedRtpCapabilities ExtendedRtpCapabilities,
+) (rtpParameters mediasoup.RtpParameters) {
+	for _, extendedCodec := range extendedRtpCapabilities.Codecs {
+		if extendedCodec.Kind != kind {
+			continue
+		}
+
+		codec := &mediasoup.RtpCodecParameters{
+			MimeType:     extendedCodec.MimeType,
+			PayloadType:  extendedCodec.LocalPayloadType,
+			ClockRate:    extendedCodec.ClockRate,
+			Channels:     extendedCodec.Channels,
+			Parameters:   extendedCodec.RemoteParameters,
+			RtcpFeedback: extendedCodec.RtcpFeedback,
+		}
+
+		rtpParameters.Codecs = append(rtpParameters.Codecs, codec)
+
+		// Add RTX codec.
+		if extendedCodec.LocalRtxPayloadType > 0 {
+			rtxCodec := &mediasoup.RtpCodecParameters{
+				MimeType:    fmt.Sprintf("%s/rtx", extendedCodec.Kind),
+				PayloadType: extendedCodec.LocalRtxPayloadType,
+				ClockRate:   extendedCodec.ClockRate,
+				Parameters: mediasoup.RtpCodecSpecificParameters{
+					{Key: "apt", Value: int(extendedCodec.LocalPayloadType)},
+				},
+				RtcpFeedback: []mediasoup.RtcpFeedback{},
+			}
+
+			rtpParameters.Codecs = append(rtpParameters.Codecs, rtxCodec)
+		}
+	}
+
+	rtpParameters.HeaderExtensions = getSendingHeaderExtensions(kind, extendedRtpCapabilities)
+
+	// Reduce codecs' RTCP feedback. Use Transport-CC if available, REMB otherwise.
+	if hasHeaderExtension(rtpParameters.HeaderExtensions,
+		"http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01") {
+		for _, codec := range rtpParameters.Codecs {
+			codec.RtcpFeedback = filterRtcpFeedback(codec.RtcpFeedback, func(fb mediasoup.RtcpFeedback) bool {
+				return fb.Type != "goog-remb"
+			})
+		}
+	} else if hasHeaderExtension(rtpParameters.HeaderExtensions,
+		"http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time") {
+		for _, codec := range rtpParameters.Codecs {
+			codec.RtcpFeedback = filterRtcpFeedback(codec.RtcpFeedback, func(fb mediasoup.RtcpFeedback) bool {
+				return fb.Type != "transport-cc"
+			})
+		}
+	} else {
+		for _, codec := range rtpParameters.Codecs {
+			codec.RtcpFeedback = filterRtcpFeedback(codec.RtcpFeedback, func(fb mediasoup.RtcpFeedback) bool {
+				return fb.Type != "transport-cc" && fb.Type != "goog-remb"
+			})
+		}
+	}
+
+	return
+}
+
+/**
+ * Reduce given codecs by returning an array of codecs "compatible" with the
+ * given capability codec. If no capability codec is given, take the first
+ * one(s).
+ *
+ * Given codecs must be generated by GetSendingRtpParameters() or
+ * GetSendingRemoteRtpParameters().
+ *
+ * The returned array of codecs also include a RTX codec if available.
+ */
+func ReduceCodecs(
+	codecs []*mediasoup.RtpCodecParameters,
+	capCodec *mediasoup.RtpCodecCapability,
+) (filteredCodecs []*mediasoup.RtpCodecParameters, err error) {
+	if len(codecs) == 0 {
+		return nil, mediasoup.NewTypeError("no codecs given")
+	}
+
+	// If no capability codec is given, take the first one (and RTX).
+	if capCodec == nil {
+		filteredCodecs = append(filteredCodecs, codecs[0])
+
+		if len(codecs) > 1 && isRtxCodec(codecs[1].MimeType) {
+			filteredCodecs = append(filteredCodecs, codecs[1])
+		}
+
+		return
+	}
+
+	// Otherwise look for a compatible set of codecs.
+	for idx, codec := range codecs {
+		if mediasoup.MatchCodecs(codec, capCodec, false) {
+			filteredCodecs = append(filteredCodecs, codec)
+
+			if idx+1 < len(codecs) && isRtxCodec(codecs[idx+1].MimeType) {
+				filteredCodecs = append(filteredCodecs, codecs[idx+1])
+			}
+
+			break
+		}
+	}
+
+	if len(filteredCodecs) == 0 {
+		return nil, mediasoup.NewTypeError("no matching codec found")
+	}
+
+	return
+}
+
+/**
+ * Whether media can be sent based on the given RTP capabilities.
+ */
+func CanSend(kind mediasoup.MediaKind, extendedRtpCapabilities ExtendedRtpCapabilities) bool {
+	for _, codec := range extendedRtpCapabilities.Codecs {
+		if codec.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+/**
+ * Whether the given RTP parameters can be received with the given RTP
+ * capabilities.
+ */
+func CanReceive(
+	rtpParameters mediasoup.RtpParameters,
+	extendedRtpCapabilities ExtendedRtpCapabilities,
+) (ok bool, err error) {
+	if err = mediasoup.ValidateRtpParameters(&rtpParameters); err != nil {
+		return
+	}
+
+	if len(rtpParameters.Codecs) == 0 {
+		return
+	}
+
+	firstMediaCodec := rtpParameters.Codecs[0]
+
+	for _, codec := range extendedRtpCapabilities.Codecs {
+		if codec.RemotePayloadType == firstMediaCodec.PayloadType {
+			return true, nil
+		}
+	}
+
+	return
+}
+
+func getSendingHeaderExtensions(
+	kind mediasoup.MediaKind,
+	extendedRtpCapabilities ExtendedRtpCapabilities,
+) (headerExtensions []mediasoup.RtpHeaderExtensionParameters) {
+	for _, extendedExtension := range extendedRtpCapabilities.HeaderExtensions {
+		// Ignore RTP extensions of a different kind and those not valid for sending.
+		if (len(extendedExtension.Kind) > 0 && extendedExtension.Kind != kind) ||
+			(extendedExtension.Direction != mediasoup.Direction_Sendrecv &&
+				extendedExtension.Direction != mediasoup.Direction_Sendonly) {
+			continue
+		}
+
+		headerExtensions = append(headerExtensions, mediasoup.RtpHeaderExtensionParameters{
+			Uri:     extendedExtension.Uri,
+			Id:      extendedExtension.SendId,
+			Encrypt: extendedExtension.Encrypt,
+		})
+	}
+
+	return
+}
+
+func capabilityToParameters(codec *mediasoup.RtpCodecCapability) *mediasoup.RtpCodecParameters {
+	return &mediasoup.RtpCodecParameters{
+		MimeType:    codec.MimeType,
+		PayloadType: codec.PreferredPayloadType,
+		ClockRate:   codec.ClockRate,
+		Channels:    codec.Channels,
+		Parameters:  codec.Parameters,
+	}
+}
+
+func isRtxCodec(mimeType string) bool {
+	return strings.HasSuffix(strings.ToLower(mimeType), "/rtx")
+}
+
+func matchHeaderExtensions(aExt, bExt *mediasoup.RtpHeaderExtension) bool {
+	if len(aExt.Kind) > 0 && len(bExt.Kind) > 0 && aExt.Kind != bExt.Kind {
+		return false
+	}
+
+	return aExt.Uri == bExt.Uri
+}
+
+func reduceRtcpFeedback(aFbs, bFbs []mediasoup.RtcpFeedback) (reducedRtcpFeedback []mediasoup.RtcpFeedback) {
+	for _, aFb := range aFbs {
+		for _, bFb := range bFbs {
+			if bFb.Type == aFb.Type && bFb.Parameter == aFb.Parameter {
+				reducedRtcpFeedback = append(reducedRtcpFeedback, bFb)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func hasHeaderExtension(exts []mediasoup.RtpHeaderExtensionParameters, uri string) bool {
+	for _, ext := range exts {
+		if ext.Uri == uri {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterRtcpFeedback(arr []mediasoup.RtcpFeedback, cond func(mediasoup.RtcpFeedback) bool) []mediasoup.RtcpFeedback {
+	// Do not reuse the given array, it may be shared with extended capabilities.
+	newArr := []mediasoup.RtcpFeedback{}
+
+	for _, x := range arr {
+		if cond(x) {
+			newArr = append(newArr, x)
+		}
+	}
+
+	return newArr
+}
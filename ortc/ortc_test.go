@@ -0,0 +1,206 @@
+package ortc
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var localCaps = mediasoup.RtpCapabilities{
+	Codecs: []*mediasoup.RtpCodecCapability{
+		{
+			Kind:                 "audio",
+			MimeType:             "audio/opus",
+			PreferredPayloadType: 111,
+			ClockRate:            48000,
+			Channels:             2,
+			RtcpFeedback: []mediasoup.RtcpFeedback{
+				{Type: "transport-cc"},
+			},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/VP8",
+			PreferredPayloadType: 96,
+			ClockRate:            90000,
+			RtcpFeedback: []mediasoup.RtcpFeedback{
+				{Type: "nack"},
+				{Type: "nack", Parameter: "pli"},
+				{Type: "goog-remb"},
+				{Type: "transport-cc"},
+			},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/rtx",
+			PreferredPayloadType: 97,
+			ClockRate:            90000,
+			Parameters: mediasoup.RtpCodecSpecificParameters{
+				{Key: "apt", Value: 96},
+			},
+		},
+	},
+	HeaderExtensions: []*mediasoup.RtpHeaderExtension{
+		{
+			Kind:        "video",
+			Uri:         "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01",
+			PreferredId: 3,
+		},
+		{
+			Kind:        "audio",
+			Uri:         "urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+			PreferredId: 1,
+		},
+	},
+}
+
+var remoteCaps = mediasoup.RtpCapabilities{
+	Codecs: []*mediasoup.RtpCodecCapability{
+		{
+			Kind:                 "audio",
+			MimeType:             "audio/opus",
+			PreferredPayloadType: 100,
+			ClockRate:            48000,
+			Channels:             2,
+			RtcpFeedback: []mediasoup.RtcpFeedback{
+				{Type: "transport-cc"},
+			},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/VP8",
+			PreferredPayloadType: 101,
+			ClockRate:            90000,
+			RtcpFeedback: []mediasoup.RtcpFeedback{
+				{Type: "nack"},
+				{Type: "nack", Parameter: "pli"},
+				{Type: "ccm", Parameter: "fir"},
+				{Type: "goog-remb"},
+				{Type: "transport-cc"},
+			},
+		},
+		{
+			Kind:                 "video",
+			MimeType:             "video/rtx",
+			PreferredPayloadType: 102,
+			ClockRate:            90000,
+			Parameters: mediasoup.RtpCodecSpecificParameters{
+				{Key: "apt", Value: 101},
+			},
+		},
+	},
+	HeaderExtensions: []*mediasoup.RtpHeaderExtension{
+		{
+			Kind:        "video",
+			Uri:         "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01",
+			PreferredId: 5,
+			Direction:   mediasoup.Direction_Sendrecv,
+		},
+		{
+			Kind:        "audio",
+			Uri:         "urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+			PreferredId: 10,
+			Direction:   mediasoup.Direction_Recvonly,
+		},
+	},
+}
+
+func TestGetExtendedRtpCapabilities(t *testing.T) {
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+
+	require.Len(t, extendedCaps.Codecs, 2)
+
+	opus := extendedCaps.Codecs[0]
+	assert.Equal(t, "audio/opus", opus.MimeType)
+	assert.EqualValues(t, 111, opus.LocalPayloadType)
+	assert.EqualValues(t, 100, opus.RemotePayloadType)
+	assert.Zero(t, opus.LocalRtxPayloadType)
+
+	vp8 := extendedCaps.Codecs[1]
+	assert.EqualValues(t, 96, vp8.LocalPayloadType)
+	assert.EqualValues(t, 97, vp8.LocalRtxPayloadType)
+	assert.EqualValues(t, 101, vp8.RemotePayloadType)
+	assert.EqualValues(t, 102, vp8.RemoteRtxPayloadType)
+	assert.Len(t, vp8.RtcpFeedback, 4)
+
+	require.Len(t, extendedCaps.HeaderExtensions, 2)
+	assert.Equal(t, 3, extendedCaps.HeaderExtensions[0].SendId)
+	assert.Equal(t, 5, extendedCaps.HeaderExtensions[0].RecvId)
+	assert.Equal(t, mediasoup.Direction_Sendonly, extendedCaps.HeaderExtensions[1].Direction)
+
+	assert.True(t, CanSend(mediasoup.MediaKind_Audio, extendedCaps))
+	assert.True(t, CanSend(mediasoup.MediaKind_Video, extendedCaps))
+}
+
+func TestGetRecvRtpCapabilities(t *testing.T) {
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+	recvCaps := GetRecvRtpCapabilities(extendedCaps)
+
+	require.Len(t, recvCaps.Codecs, 3)
+	assert.EqualValues(t, 100, recvCaps.Codecs[0].PreferredPayloadType)
+	assert.EqualValues(t, 101, recvCaps.Codecs[1].PreferredPayloadType)
+	assert.Equal(t, "video/rtx", recvCaps.Codecs[2].MimeType)
+	assert.EqualValues(t, 101, recvCaps.Codecs[2].Parameters.GetInt("apt"))
+
+	// ssrc-audio-level is sendonly from the local point of view.
+	require.Len(t, recvCaps.HeaderExtensions, 1)
+	assert.Equal(t, 5, recvCaps.HeaderExtensions[0].PreferredId)
+}
+
+func TestGetSendingRtpParameters(t *testing.T) {
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+
+	params := GetSendingRtpParameters(mediasoup.MediaKind_Video, extendedCaps)
+	require.Len(t, params.Codecs, 2)
+	assert.EqualValues(t, 96, params.Codecs[0].PayloadType)
+	assert.EqualValues(t, 97, params.Codecs[1].PayloadType)
+	assert.EqualValues(t, 96, params.Codecs[1].Parameters.GetInt("apt"))
+	require.Len(t, params.HeaderExtensions, 1)
+	assert.Equal(t, 3, params.HeaderExtensions[0].Id)
+
+	remoteParams := GetSendingRemoteRtpParameters(mediasoup.MediaKind_Video, extendedCaps)
+	require.Len(t, remoteParams.Codecs, 2)
+	for _, fb := range remoteParams.Codecs[0].RtcpFeedback {
+		assert.NotEqual(t, "goog-remb", fb.Type)
+	}
+	// Extended capabilities must not be modified.
+	assert.Len(t, extendedCaps.Codecs[1].RtcpFeedback, 4)
+}
+
+func TestReduceCodecs(t *testing.T) {
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+	params := GetSendingRtpParameters(mediasoup.MediaKind_Video, extendedCaps)
+
+	codecs, err := ReduceCodecs(params.Codecs, nil)
+	require.NoError(t, err)
+	assert.Len(t, codecs, 2)
+
+	codecs, err = ReduceCodecs(params.Codecs, localCaps.Codecs[1])
+	require.NoError(t, err)
+	assert.Len(t, codecs, 2)
+
+	_, err = ReduceCodecs(params.Codecs, localCaps.Codecs[0])
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestCanReceive(t *testing.T) {
+	extendedCaps := GetExtendedRtpCapabilities(localCaps, remoteCaps)
+
+	ok, err := CanReceive(mediasoup.RtpParameters{
+		Codecs: []*mediasoup.RtpCodecParameters{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+		},
+	}, extendedCaps)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CanReceive(mediasoup.RtpParameters{
+		Codecs: []*mediasoup.RtpCodecParameters{
+			{MimeType: "video/H264", PayloadType: 125, ClockRate: 90000},
+		},
+	}, extendedCaps)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
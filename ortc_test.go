@@ -0,0 +1,433 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConsumerRtpParameters_OpusParameters(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{
+				MimeType:    "audio/opus",
+				PayloadType: 100,
+				ClockRate:   48000,
+				Channels:    2,
+				Parameters: RtpCodecSpecificParameters{
+					{Key: "stereo", Value: 1},
+					{Key: "sprop-stereo", Value: 1},
+					{Key: "useinbandfec", Value: 1},
+					{Key: "usedtx", Value: 1},
+					{Key: "maxplaybackrate", Value: 48000},
+					{Key: "maxaveragebitrate", Value: 128000},
+					{Key: "ptime", Value: 20},
+					{Key: "cbr", Value: 1},
+				},
+			},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 11111111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []*RtpCodecCapability{
+			{
+				Kind:                 "audio",
+				MimeType:             "audio/opus",
+				PreferredPayloadType: 100,
+				ClockRate:            48000,
+				Channels:             2,
+				Parameters: RtpCodecSpecificParameters{
+					{Key: "maxplaybackrate", Value: 24000},
+					{Key: "maxaveragebitrate", Value: 256000},
+				},
+			},
+		},
+	}
+
+	consumerParams, err := getConsumerRtpParameters(consumableParams, caps, false, nil)
+	require.NoError(t, err)
+	require.Len(t, consumerParams.Codecs, 1)
+
+	assert.Equal(t, RtpCodecSpecificParameters{
+		{Key: "sprop-stereo", Value: 1},
+		{Key: "useinbandfec", Value: 1},
+		{Key: "usedtx", Value: 1},
+		{Key: "maxplaybackrate", Value: 24000},
+		{Key: "maxaveragebitrate", Value: 128000},
+		{Key: "ptime", Value: 20},
+		{Key: "cbr", Value: 1},
+	}, consumerParams.Codecs[0].Parameters)
+}
+
+func TestValidateRtpCodecCapability_Multiopus(t *testing.T) {
+	codec := &RtpCodecCapability{
+		MimeType:  "audio/multiopus",
+		ClockRate: 48000,
+		Channels:  6,
+		Parameters: RtpCodecSpecificParameters{
+			{Key: "channel_mapping", Value: "0,4,1,2,3,5"},
+			{Key: "num_streams", Value: 4},
+			{Key: "coupled_streams", Value: 2},
+		},
+	}
+	assert.NoError(t, validateRtpCodecCapability(codec))
+
+	codec.Parameters.Set("channel_mapping", "0,4,1,2")
+	assert.IsType(t, TypeError{}, validateRtpCodecCapability(codec))
+
+	codec.Parameters.Set("channel_mapping", "0,4,1,2,3,6")
+	assert.IsType(t, TypeError{}, validateRtpCodecCapability(codec))
+
+	codec.Parameters.Set("channel_mapping", "")
+	codec.Parameters.Set("num_streams", 0)
+	assert.IsType(t, TypeError{}, validateRtpCodecCapability(codec))
+
+	codec.Parameters.Set("num_streams", 4)
+	codec.Channels = 2
+	assert.IsType(t, TypeError{}, validateRtpCodecCapability(codec))
+}
+
+func TestGenerateRouterRtpCapabilities_Multiopus(t *testing.T) {
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{
+			MimeType:  "audio/multiopus",
+			ClockRate: 48000,
+			Channels:  6,
+			Parameters: RtpCodecSpecificParameters{
+				{Key: "channel_mapping", Value: "0,4,1,2,3,5"},
+				{Key: "num_streams", Value: 4},
+				{Key: "coupled_streams", Value: 2},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, caps.Codecs, 1)
+	assert.Equal(t, 6, caps.Codecs[0].Channels)
+	assert.EqualValues(t, 4, caps.Codecs[0].Parameters.GetInt("num_streams"))
+
+	_, err = generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{
+			MimeType:  "audio/multiopus",
+			ClockRate: 48000,
+			Channels:  6,
+			Parameters: RtpCodecSpecificParameters{
+				{Key: "num_streams", Value: 3},
+				{Key: "coupled_streams", Value: 3},
+			},
+		},
+	})
+	assert.IsType(t, UnsupportedError{}, err)
+}
+
+func TestGenerateRouterRtpCapabilities_RedAndUlpfec(t *testing.T) {
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{MimeType: "audio/red", ClockRate: 48000, Channels: 2},
+		{MimeType: "video/VP8", ClockRate: 90000},
+		{MimeType: "video/red", ClockRate: 90000},
+		{MimeType: "video/ulpfec", ClockRate: 90000},
+	})
+	require.NoError(t, err)
+
+	var mimeTypes []string
+	for _, codec := range caps.Codecs {
+		mimeTypes = append(mimeTypes, codec.MimeType)
+	}
+	assert.Equal(t, []string{
+		"audio/opus", "audio/red", "video/VP8", "video/rtx", "video/red", "video/rtx", "video/ulpfec",
+	}, mimeTypes)
+}
+
+func TestGetConsumerRtpParameters_RedFirst(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "audio/red", PayloadType: 63, ClockRate: 48000, Channels: 2},
+			{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 11111111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []*RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/opus", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+			{Kind: "audio", MimeType: "audio/red", PreferredPayloadType: 63, ClockRate: 48000, Channels: 2},
+		},
+	}
+
+	ok, err := canConsume(consumableParams, caps)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	consumerParams, err := getConsumerRtpParameters(consumableParams, caps, false, nil)
+	require.NoError(t, err)
+	require.Len(t, consumerParams.Codecs, 2)
+	assert.Equal(t, "audio/opus", consumerParams.Codecs[0].MimeType)
+	assert.Equal(t, "audio/red", consumerParams.Codecs[1].MimeType)
+
+	// RED alone is not enough.
+	_, err = getConsumerRtpParameters(consumableParams, RtpCapabilities{
+		Codecs: caps.Codecs[1:],
+	}, false, nil)
+	assert.IsType(t, UnsupportedError{}, err)
+}
+
+func TestGetConsumerRtpParameters_EncryptedHeaderExtensions(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		HeaderExtensions: []RtpHeaderExtensionParameters{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+			{Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", Id: 10, Encrypt: true},
+			{Uri: "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time", Id: 13},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 11111111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []*RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/opus", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		HeaderExtensions: []*RtpHeaderExtension{
+			{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", PreferredId: 1},
+			{Kind: "audio", Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", PreferredId: 10},
+			{Kind: "audio", Uri: "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time", PreferredId: 13, PreferredEncrypt: true},
+		},
+	}
+
+	consumerParams, err := getConsumerRtpParameters(consumableParams, caps, false, nil)
+	require.NoError(t, err)
+	require.Len(t, consumerParams.HeaderExtensions, 3)
+
+	assert.False(t, consumerParams.HeaderExtensions[0].Encrypt)
+	assert.True(t, consumerParams.HeaderExtensions[1].Encrypt)
+	assert.True(t, consumerParams.HeaderExtensions[2].Encrypt)
+}
+
+func TestGetConsumerRtpParameters_Dtx(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 11111111, Dtx: true}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []*RtpCodecCapability{
+			{Kind: "audio", MimeType: "audio/opus", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+		},
+	}
+
+	consumerParams, err := getConsumerRtpParameters(consumableParams, caps, false, nil)
+	require.NoError(t, err)
+	require.Len(t, consumerParams.Encodings, 1)
+	assert.True(t, consumerParams.Encodings[0].Dtx)
+
+	consumerParams, err = getConsumerRtpParameters(consumableParams, caps, true, nil)
+	require.NoError(t, err)
+	require.Len(t, consumerParams.Encodings, 1)
+	assert.True(t, consumerParams.Encodings[0].Dtx)
+
+	consumableParams.Encodings[0].Dtx = false
+
+	consumerParams, err = getConsumerRtpParameters(consumableParams, caps, false, nil)
+	require.NoError(t, err)
+	assert.False(t, consumerParams.Encodings[0].Dtx)
+}
+
+func TestMatchCodecs_H264Svc(t *testing.T) {
+	codec := &RtpCodecParameters{
+		MimeType:    "video/H264-SVC",
+		PayloadType: 103,
+		ClockRate:   90000,
+		Parameters: RtpCodecSpecificParameters{
+			{Key: "packetization-mode", Value: 1},
+			{Key: "profile-level-id", Value: "42e01f"},
+		},
+	}
+	capability := &RtpCodecCapability{
+		Kind:      "video",
+		MimeType:  "video/H264-SVC",
+		ClockRate: 90000,
+		Parameters: RtpCodecSpecificParameters{
+			{Key: "packetization-mode", Value: 1},
+			{Key: "profile-level-id", Value: "42e015"},
+		},
+	}
+
+	assert.True(t, MatchCodecs(codec, capability, true))
+
+	capability.Parameters.Set("profile-level-id", "640c1f")
+	assert.False(t, MatchCodecs(codec, capability, true))
+	assert.True(t, MatchCodecs(codec, capability, false))
+
+	capability.Parameters.Set("packetization-mode", 0)
+	assert.False(t, MatchCodecs(codec, capability, false))
+}
+
+func TestMatchCodecs_H265(t *testing.T) {
+	codec := &RtpCodecParameters{
+		MimeType:    "video/H265",
+		PayloadType: 104,
+		ClockRate:   90000,
+		Parameters: RtpCodecSpecificParameters{
+			{Key: "profile-id", Value: "1"},
+			{Key: "level-id", Value: 120},
+		},
+	}
+	capability := &RtpCodecCapability{
+		Kind:      "video",
+		MimeType:  "video/H265",
+		ClockRate: 90000,
+		Parameters: RtpCodecSpecificParameters{
+			{Key: "level-id", Value: 93},
+		},
+	}
+
+	assert.True(t, matchCodecs(codec, capability, matchOptions{strict: true, modify: true}))
+	assert.Equal(t, 93, codec.Parameters.GetInt("level-id"))
+
+	capability.Parameters.Set("profile-id", "2")
+	assert.False(t, MatchCodecs(codec, capability, true))
+	assert.True(t, MatchCodecs(codec, capability, false))
+
+	capability.Parameters.Set("tx-mode", "MRST")
+	assert.False(t, MatchCodecs(codec, capability, false))
+}
+
+func TestApplyCodecPreferences(t *testing.T) {
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		{Kind: "video", MimeType: "video/VP8", ClockRate: 90000},
+		{Kind: "video", MimeType: "video/VP9", ClockRate: 90000},
+		{Kind: "video", MimeType: "video/red", ClockRate: 90000},
+	})
+	require.NoError(t, err)
+
+	mimeTypes := func(caps RtpCapabilities) (mimeTypes []string) {
+		for _, codec := range caps.Codecs {
+			mimeTypes = append(mimeTypes, codec.MimeType)
+		}
+		return
+	}
+
+	newCaps, err := applyCodecPreferences(caps, []string{"video/vp9"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"video/VP9", "video/rtx", "audio/opus", "video/VP8", "video/rtx", "video/red", "video/rtx",
+	}, mimeTypes(newCaps))
+	assert.EqualValues(t, newCaps.Codecs[0].PreferredPayloadType, newCaps.Codecs[1].Parameters.GetInt("apt"))
+	assert.Equal(t, caps.HeaderExtensions, newCaps.HeaderExtensions)
+
+	newCaps, err = applyCodecPreferences(caps, []string{"video/VP9"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"video/VP9", "video/rtx", "audio/opus", "video/red", "video/rtx",
+	}, mimeTypes(newCaps))
+
+	_, err = applyCodecPreferences(caps, []string{"video/H264"}, false)
+	assert.IsType(t, NewTypeError(""), err)
+
+	// Given capabilities are not modified.
+	assert.Equal(t, "audio/opus", caps.Codecs[0].MimeType)
+}
+
+func TestGetConsumerRtpParameters_CodecPolicy(t *testing.T) {
+	consumableParams := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 101}}},
+			{MimeType: "video/VP9", PayloadType: 103, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 104, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 103}}},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 11111111}},
+	}
+	caps := RtpCapabilities{
+		Codecs: []*RtpCodecCapability{
+			{Kind: "video", MimeType: "video/VP8", PreferredPayloadType: 101, ClockRate: 90000},
+			{Kind: "video", MimeType: "video/rtx", PreferredPayloadType: 102, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 101}}},
+			{Kind: "video", MimeType: "video/VP9", PreferredPayloadType: 103, ClockRate: 90000},
+			{Kind: "video", MimeType: "video/rtx", PreferredPayloadType: 104, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 103}}},
+		},
+	}
+
+	preferVP9 := func(codecs []*RtpCodecParameters) []*RtpCodecParameters {
+		require.Len(t, codecs, 2)
+		return []*RtpCodecParameters{codecs[1], codecs[0]}
+	}
+
+	consumerParams, err := getConsumerRtpParameters(consumableParams, caps, false, preferVP9)
+	require.NoError(t, err)
+
+	payloadTypes := []byte{}
+	for _, codec := range consumerParams.Codecs {
+		payloadTypes = append(payloadTypes, codec.PayloadType)
+	}
+	assert.Equal(t, []byte{103, 104, 101, 102}, payloadTypes)
+
+	banAll := func(codecs []*RtpCodecParameters) []*RtpCodecParameters {
+		return nil
+	}
+
+	_, err = getConsumerRtpParameters(consumableParams, caps, false, banAll)
+	assert.IsType(t, UnsupportedError{}, err)
+
+	unknown := func(codecs []*RtpCodecParameters) []*RtpCodecParameters {
+		return []*RtpCodecParameters{{MimeType: "video/H264", PayloadType: 105, ClockRate: 90000}}
+	}
+
+	_, err = getConsumerRtpParameters(consumableParams, caps, false, unknown)
+	assert.IsType(t, NewTypeError(""), err)
+}
+
+func TestOrtcMalformedInput(t *testing.T) {
+	caps := RtpCapabilities{Codecs: []*RtpCodecCapability{nil}}
+	params := RtpParameters{
+		Codecs:    []*RtpCodecParameters{nil},
+		Encodings: []RtpEncodingParameters{{Ssrc: 1111}},
+	}
+
+	assert.NotPanics(t, func() {
+		assert.Error(t, ValidateRtpCapabilities(nil))
+		assert.Error(t, ValidateRtpCapabilities(&caps))
+		assert.Error(t, ValidateRtpParameters(nil))
+		assert.Error(t, ValidateRtpParameters(&params))
+
+		_, err := GetProducerRtpParametersMapping(params, caps)
+		assert.Error(t, err)
+
+		_, err = GetConsumableRtpParameters("video", params, caps, RtpMapping{})
+		assert.Error(t, err)
+
+		_, err = CanConsume(params, caps)
+		assert.Error(t, err)
+
+		_, err = GetConsumerRtpParameters(params, caps, false)
+		assert.Error(t, err)
+
+		GetPipeConsumerRtpParameters(params, true)
+
+		assert.False(t, MatchCodecs(nil, nil, true))
+	})
+}
+
+func TestGetConsumableRtpParameters_MissingMapping(t *testing.T) {
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{Kind: "audio", MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	})
+	require.NoError(t, err)
+
+	params := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "audio/opus", PayloadType: 111, ClockRate: 48000, Channels: 2},
+		},
+		Encodings: []RtpEncodingParameters{{Ssrc: 1111}},
+	}
+	mapping, err := GetProducerRtpParametersMapping(params, caps)
+	require.NoError(t, err)
+
+	mapping.Encodings = nil
+
+	assert.NotPanics(t, func() {
+		_, err = GetConsumableRtpParameters("audio", params, caps, mapping)
+	})
+	assert.Error(t, err)
+}
@@ -1,14 +1,14 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/jiyeyuran/mediasoup-go/netstring"
 )
 
 type notification struct {
@@ -19,7 +19,6 @@ type notification struct {
 
 type PayloadChannel struct {
 	IEventEmitter
-	locker              sync.Mutex
 	logger              Logger
 	closed              int32
 	producerSocket      net.Conn
@@ -27,24 +26,46 @@ type PayloadChannel struct {
 	nextId              int64
 	sents               sync.Map
 	sentsLen            int64
+	pid                 int
 	ongoingNotification *notification
 	closeCh             chan struct{}
+	codec               channelCodec
+	decoder             channelFrameDecoder
+	counters            channelCounters
+	writer              *channelWriter
+	// Reader, processor and writer goroutines.
+	goroutines sync.WaitGroup
+	// Set while the processor goroutine runs the handlers of a message.
+	dispatching int32
+	// Error of the requests pending when the worker process died.
+	channelExit
 }
 
-func newPayloadChannel(producerSocket, consumerSocket net.Conn) *PayloadChannel {
-	logger := NewLogger("PayloadChannel")
+func newPayloadChannel(producerSocket, consumerSocket net.Conn, pid int, codec channelCodec, loggerContext loggerContext) *PayloadChannel {
+	logger := loggerContext.newLogger("PayloadChannel")
 
 	logger.Debug("constructor()")
 
 	channel := &PayloadChannel{
-		IEventEmitter:  NewEventEmitter(),
+		IEventEmitter:  newAsyncEventEmitter(),
 		logger:         logger,
 		producerSocket: producerSocket,
 		consumerSocket: consumerSocket,
+		pid:            pid,
 		closeCh:        make(chan struct{}),
+		channelExit:    newChannelExit(),
+		codec:          codec,
+		decoder:        codec.newFrameDecoder(),
 	}
 
-	go channel.runReadLoop()
+	channel.writer = newChannelWriter(producerSocket, channel.closeCh, &channel.counters)
+
+	channel.goroutines.Add(2)
+	goWithWorkerLabels(pid, "payload-channel-reader", channel.runReadLoop)
+	goWithWorkerLabels(pid, "payload-channel-writer", func() {
+		defer channel.goroutines.Done()
+		channel.writer.run()
+	})
 
 	return channel
 }
@@ -65,22 +86,47 @@ func (c *PayloadChannel) Closed() bool {
 	return atomic.LoadInt32(&c.closed) > 0
 }
 
+// wait waits at most timeout for the goroutines of the closed PayloadChannel
+// to return, reporting whether they did.
+func (c *PayloadChannel) wait(timeout time.Duration) bool {
+	return waitTimeout(&c.goroutines, timeout)
+}
+
+// isDispatching reports whether the processor goroutine is running the
+// handlers of a message, which may be the caller.
+func (c *PayloadChannel) isDispatching() bool {
+	return atomic.LoadInt32(&c.dispatching) > 0
+}
+
+/**
+ * State of the PayloadChannel.
+ */
+func (c *PayloadChannel) Stats() ChannelStats {
+	return c.counters.stats(atomic.LoadInt64(&c.sentsLen), len(c.decoder.Result()))
+}
+
 func (c *PayloadChannel) Notify(event string, internal interface{}, data interface{}, payload []byte) (err error) {
 	if c.Closed() {
 		err = NewInvalidStateError("PayloadChannel closed")
 		return
 	}
-	notification := H{
-		"event":    event,
-		"internal": internal,
-		"data":     data,
+	rawData, err := c.codec.encodeNotification(event, internal, data)
+	if err != nil {
+		return
 	}
-	rawData, _ := json.Marshal(notification)
 
 	return c.writeAll(rawData, payload)
 }
 
 func (c *PayloadChannel) Request(method string, internal interface{}, data interface{}, payload []byte) (rsp workerResponse) {
+	return c.RequestContext(context.Background(), method, internal, data, payload)
+}
+
+/**
+ * Send a request with its payload to the worker, waiting for its response
+ * until the context is done.
+ */
+func (c *PayloadChannel) RequestContext(ctx context.Context, method string, internal interface{}, data interface{}, payload []byte) (rsp workerResponse) {
 	if c.Closed() {
 		rsp.err = NewInvalidStateError("PayloadChannel closed")
 		return
@@ -97,9 +143,10 @@ func (c *PayloadChannel) Request(method string, internal interface{}, data inter
 	c.logger.Debug("request() [method:%s, id:%d]", method, id)
 
 	sent := sentInfo{
-		id:     id,
-		method: method,
-		respCh: make(chan workerResponse),
+		id:       id,
+		method:   method,
+		internal: internal,
+		respCh:   make(chan workerResponse, 1),
 	}
 	c.sents.Store(id, sent)
 
@@ -110,36 +157,40 @@ func (c *PayloadChannel) Request(method string, internal interface{}, data inter
 		atomic.AddInt64(&c.sentsLen, -1)
 	}()
 
-	rawData, _ := json.Marshal(H{
-		"id":       id,
-		"method":   method,
-		"internal": internal,
-		"data":     data,
-	})
+	rawData, err := c.codec.encodeRequest(id, method, internal, data)
+	if err != nil {
+		rsp.err = err
+		return
+	}
+
+	if rsp.err = ctx.Err(); rsp.err != nil {
+		return
+	}
 
 	if rsp.err = c.writeAll(rawData, payload); rsp.err != nil {
 		return
 	}
 
-	timeout := 1000 * (15 + (0.1 * float64(size)))
-	timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+	timer := time.NewTimer(requestTimeout(size))
 	defer timer.Stop()
 
 	select {
 	case rsp = <-sent.respCh:
 		return
 	case <-timer.C:
-		rsp.err = errors.New("Channel request timeout")
+		rsp.err = fmt.Errorf("%w [method:%s, id:%d]", ErrRequestTimeout, method, id)
 	case <-c.closeCh:
-		rsp.err = NewInvalidStateError("Channel closed")
+		rsp.err = c.closedError()
+	case <-ctx.Done():
+		rsp.err = ctx.Err()
 	}
 
 	return
 }
 
 func (c *PayloadChannel) writeAll(data, payload []byte) (err error) {
-	ns1 := netstring.Encode(data)
-	ns2 := netstring.Encode(payload)
+	ns1 := c.codec.frame(data)
+	ns2 := c.codec.frame(payload)
 
 	if len(ns1) > NS_MESSAGE_MAX_LEN {
 		return errors.New("PayloadChannel data too big")
@@ -148,31 +199,31 @@ func (c *PayloadChannel) writeAll(data, payload []byte) (err error) {
 		return errors.New("PayloadChannel payload too big")
 	}
 
-	c.locker.Lock()
-	defer c.locker.Unlock()
-
-	if _, err = c.producerSocket.Write(ns1); err != nil {
-		return
-	}
-	if _, err = c.producerSocket.Write(ns2); err != nil {
-		return
-	}
-	return
+	return c.writer.write(ns1, ns2)
 }
 
 func (c *PayloadChannel) runReadLoop() {
-	decoder := netstring.NewDecoder()
+	defer c.goroutines.Done()
+
+	decoder := c.decoder
+
+	c.goroutines.Add(1)
+	goWithWorkerLabels(c.pid, "payload-channel-processor", func() {
+		defer c.goroutines.Done()
 
-	go func() {
 		for {
 			select {
 			case nsPayload := <-decoder.Result():
+				start := time.Now()
+				atomic.StoreInt32(&c.dispatching, 1)
 				c.processData(nsPayload)
+				atomic.StoreInt32(&c.dispatching, 0)
+				c.counters.dispatchedSince(start)
 			case <-c.closeCh:
 				return
 			}
 		}
-	}()
+	})
 
 	buf := make([]byte, NS_PAYLOAD_MAX_LEN)
 
@@ -180,6 +231,10 @@ func (c *PayloadChannel) runReadLoop() {
 		n, err := c.consumerSocket.Read(buf)
 		if err != nil {
 			c.logger.Error("Channel error: %s", err)
+			// Not closed by Close(), the worker process is likely dead.
+			if !c.Closed() {
+				atomic.StoreInt32(&c.lost, 1)
+			}
 			break
 		}
 		data := buf[:n]
@@ -189,6 +244,7 @@ func (c *PayloadChannel) runReadLoop() {
 		if decoder.Length() > NS_PAYLOAD_MAX_LEN {
 			c.logger.Error("receiving buffer is full, discarding all data into it")
 			decoder.Reset()
+			c.counters.drop()
 		}
 	}
 
@@ -203,24 +259,17 @@ func (c *PayloadChannel) processData(payload []byte) {
 		return
 	}
 
-	var msg struct {
-		// response
-		Id       int64  `json:"id,omitempty"`
-		Accepted bool   `json:"accepted,omitempty"`
-		Error    string `json:"error,omitempty"`
-		Reason   string `json:"reason,omitempty"`
-		// notification
-		TargetId string `json:"targetId,omitempty"`
-		Event    string `json:"event,omitempty"`
-		// common data
-		Data json.RawMessage `json:"data,omitempty"`
+	msg, err := c.codec.decodeMessage(payload)
+	if err != nil || len(msg.LogLevel) > 0 || msg.Dump != nil {
+		c.logger.Error("received message is not a response nor a notification")
+		return
 	}
-	json.Unmarshal(payload, &msg)
 
 	if msg.Id > 0 {
 		value, ok := c.sents.Load(msg.Id)
 		if !ok {
 			c.logger.Error("received response does not match any sent request [id:%d]", msg.Id)
+			c.counters.drop()
 			return
 		}
 		sent := value.(sentInfo)
@@ -232,11 +281,7 @@ func (c *PayloadChannel) processData(payload []byte) {
 		} else if len(msg.Error) > 0 {
 			c.logger.Warn("request failed [method:%s, id:%d]: %s", sent.method, sent.id, msg.Reason)
 
-			if msg.Error == "TypeError" {
-				sent.respCh <- workerResponse{err: NewTypeError(msg.Reason)}
-			} else {
-				sent.respCh <- workerResponse{err: errors.New(msg.Reason)}
-			}
+			sent.respCh <- workerResponse{err: newWorkerError(msg.Error, msg.Reason, sent.method, sent.internal)}
 		} else {
 			c.logger.Error("received response is not accepted nor rejected [method:%s, id:%s]", sent.method, sent.id)
 		}
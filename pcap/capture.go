@@ -0,0 +1,387 @@
+// Package pcap captures the RTP of a transport into a pcap file, to be opened
+// with Wireshark ("Decode As" RTP if needed):
+//
+//	file, _ := os.Create("transport.pcap")
+//	capture, err := pcap.NewCapture(pcap.Options{
+//		Router:    router,
+//		Transport: transport,
+//		Writer:    file,
+//	})
+//	...
+//	capture.Close()
+//	file.Close()
+//
+// The RTP headers of the Producers (received) and Consumers (sent) of the
+// transport are given by their "rtp" trace events, the packets being written
+// with their header only and their original length. With the Payloads option,
+// the Producers are also consumed through a DirectTransport to capture their
+// full packets. The IP addresses and ports are the ones of the transport tuple
+// when known. The RTCP of a transport is not exposed by the worker, thus not
+// captured.
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Router of the transport, required with Payloads.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Transport to capture.
+	 */
+	Transport mediasoup.ITransport
+
+	/**
+	 * Destination of the pcap file. It is not closed by the capture.
+	 */
+	Writer io.Writer
+
+	/**
+	 * SSRCs of the captured RTP streams. Default every stream.
+	 */
+	Ssrcs []uint32
+
+	/**
+	 * Whether the full packets of the Producers are captured, through a
+	 * DirectTransport, instead of their header only. The captured stream of a
+	 * simulcast Producer is the layer selected by the DirectTransport
+	 * Consumer, with the SSRC of that Consumer.
+	 */
+	Payloads bool
+
+	/**
+	 * Maximum number of bytes written per packet. Default 65535.
+	 */
+	SnapLen int
+
+	/**
+	 * Logger of the capture. Default mediasoup.NewLogger("Capture").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Capture writes the RTP of a transport into a pcap file. Enabling the trace
+ * events of the Producers and Consumers of the transport while capturing
+ * replaces the types enabled by the capture.
+ */
+type Capture struct {
+	logger    mediasoup.Logger
+	options   Options
+	writer    *Writer
+	ssrcs     map[uint32]bool
+	local     *net.UDPAddr
+	remote    *net.UDPAddr
+	tap       *mediasoup.DirectTransport
+	locker    sync.Mutex
+	producers []*mediasoup.Producer
+	consumers []*mediasoup.Consumer
+	packets   int
+	err       error
+	closed    bool
+}
+
+/**
+ * Start capturing the transport.
+ */
+func NewCapture(options Options) (capture *Capture, err error) {
+	if options.Transport == nil {
+		return nil, mediasoup.NewTypeError("missing Transport")
+	}
+	if options.Writer == nil {
+		return nil, mediasoup.NewTypeError("missing Writer")
+	}
+	if options.Payloads && options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("Capture")
+	}
+
+	capture = &Capture{
+		logger:  options.Logger,
+		options: options,
+	}
+	capture.local, capture.remote = addresses(options.Transport)
+
+	if len(options.Ssrcs) > 0 {
+		capture.ssrcs = make(map[uint32]bool, len(options.Ssrcs))
+
+		for _, ssrc := range options.Ssrcs {
+			capture.ssrcs[ssrc] = true
+		}
+	}
+
+	if capture.writer, err = NewWriter(options.Writer, options.SnapLen); err != nil {
+		return nil, err
+	}
+
+	if options.Payloads {
+		if capture.tap, err = options.Router.CreateDirectTransport(); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := options.Transport
+
+	transport.Observer().On("newproducer", capture.addProducer)
+	transport.Observer().On("newconsumer", capture.addConsumer)
+	transport.Observer().Once("close", func() { capture.Close() })
+
+	for _, producer := range transport.Producers() {
+		capture.addProducer(producer)
+	}
+	for _, consumer := range transport.Consumers() {
+		capture.addConsumer(consumer)
+	}
+
+	return
+}
+
+// addresses returns the local and remote addresses of the transport tuple,
+// 127.0.0.1 with arbitrary ports if unknown.
+func addresses(transport mediasoup.ITransport) (local, remote *net.UDPAddr) {
+	var tuple *mediasoup.TransportTuple
+
+	switch t := transport.(type) {
+	case *mediasoup.WebRtcTransport:
+		tuple = t.IceSelectedTuple()
+	case *mediasoup.PlainTransport:
+		tuple = t.Tuple()
+	case *mediasoup.PipeTransport:
+		pipeTuple := t.Tuple()
+		tuple = &pipeTuple
+	}
+
+	local = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 10000}
+	remote = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 20000}
+
+	if tuple != nil {
+		if ip := net.ParseIP(tuple.LocalIp); ip != nil {
+			local = &net.UDPAddr{IP: ip, Port: int(tuple.LocalPort)}
+		}
+		if ip := net.ParseIP(tuple.RemoteIp); ip != nil {
+			remote = &net.UDPAddr{IP: ip, Port: int(tuple.RemotePort)}
+		}
+	}
+
+	return
+}
+
+func (c *Capture) addProducer(producer *mediasoup.Producer) {
+	c.locker.Lock()
+
+	if c.closed {
+		c.locker.Unlock()
+		return
+	}
+	c.producers = append(c.producers, producer)
+
+	c.locker.Unlock()
+
+	if c.options.Payloads {
+		c.tapProducer(producer)
+		return
+	}
+
+	producer.Observer().On("trace", func(trace mediasoup.ProducerTraceEventData) {
+		if trace.Type == mediasoup.ProducerTraceEventType_Rtp {
+			c.writeTrace(trace.Info, c.remote, c.local)
+		}
+	})
+
+	if err := producer.EnableTraceEvent(mediasoup.ProducerTraceEventType_Rtp); err != nil {
+		c.logger.Warn("enabling trace events of producer %s failed: %s", producer.Id(), err)
+	}
+}
+
+// tapProducer consumes the Producer through the DirectTransport, restoring the
+// SSRC and payload type of the Producer if it has a single stream.
+func (c *Capture) tapProducer(producer *mediasoup.Producer) {
+	consumer, err := c.tap.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: c.options.Router.RtpCapabilities(),
+	})
+	if err != nil {
+		c.logger.Warn("tapping producer %s failed: %s", producer.Id(), err)
+		return
+	}
+
+	var ssrc uint32
+	var payloadType byte
+
+	if producerParams := producer.RtpParameters(); len(producerParams.Encodings) == 1 {
+		ssrc = producerParams.Encodings[0].Ssrc
+		payloadType = producerParams.Codecs[0].PayloadType
+	}
+
+	consumer.On("rtp", func(packet []byte) {
+		if len(packet) < 12 {
+			return
+		}
+		if ssrc != 0 {
+			packet = append([]byte(nil), packet...)
+			packet[1] = packet[1]&0x80 | payloadType
+			binary.BigEndian.PutUint32(packet[8:], ssrc)
+		}
+		c.write(packet, len(packet), c.remote, c.local)
+	})
+}
+
+func (c *Capture) addConsumer(consumer *mediasoup.Consumer) {
+	c.locker.Lock()
+
+	if c.closed {
+		c.locker.Unlock()
+		return
+	}
+	c.consumers = append(c.consumers, consumer)
+
+	c.locker.Unlock()
+
+	consumer.Observer().On("trace", func(trace mediasoup.ConsumerTraceEventData) {
+		if trace.Type == mediasoup.ConsumerTraceEventType_Rtp {
+			c.writeTrace(trace.Info, c.local, c.remote)
+		}
+	})
+
+	if err := consumer.EnableTraceEvent(mediasoup.ConsumerTraceEventType_Rtp); err != nil {
+		c.logger.Warn("enabling trace events of consumer %s failed: %s", consumer.Id(), err)
+	}
+}
+
+func (c *Capture) writeTrace(info mediasoup.H, src, dst *net.UDPAddr) {
+	header, length, ok := traceRtpHeader(info)
+	if !ok {
+		return
+	}
+	c.write(header, length, src, dst)
+}
+
+func (c *Capture) write(packet []byte, length int, src, dst *net.UDPAddr) {
+	if c.ssrcs != nil && !c.ssrcs[binary.BigEndian.Uint32(packet[8:])] {
+		return
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	if c.closed || c.err != nil {
+		return
+	}
+
+	if c.err = c.writer.WritePacket(time.Now(), src, dst, packet, length); c.err != nil {
+		c.logger.Error("writing packet failed, stopping the capture: %v", c.err)
+		return
+	}
+	c.packets++
+}
+
+// traceRtpHeader builds the RTP header of the packet described by the info of
+// a "rtp" trace event, returning it with the length of the packet.
+func traceRtpHeader(info mediasoup.H) (header []byte, length int, ok bool) {
+	number := func(key string) uint32 {
+		value, _ := info[key].(float64)
+		return uint32(value)
+	}
+
+	if _, ok = info["ssrc"].(float64); !ok {
+		return
+	}
+
+	header = make([]byte, 12)
+	header[0] = 0x80
+	header[1] = byte(number("payloadType") & 0x7f)
+
+	if marker, _ := info["marker"].(bool); marker {
+		header[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(header[2:], uint16(number("sequenceNumber")))
+	binary.BigEndian.PutUint32(header[4:], number("timestamp"))
+	binary.BigEndian.PutUint32(header[8:], number("ssrc"))
+
+	length = int(number("size"))
+
+	if length < len(header) {
+		length = len(header)
+	}
+
+	return
+}
+
+/**
+ * Number of packets written so far.
+ */
+func (c *Capture) Packets() int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.packets
+}
+
+/**
+ * Error which stopped the capture, nil if none.
+ */
+func (c *Capture) Err() error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.err
+}
+
+/**
+ * Whether the capture is closed.
+ */
+func (c *Capture) Closed() bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.closed
+}
+
+/**
+ * Stop capturing, disabling the trace events enabled by the capture. It is
+ * called when the transport is closed.
+ */
+func (c *Capture) Close() error {
+	c.locker.Lock()
+
+	if c.closed {
+		c.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	c.logger.Debug("Close()")
+
+	c.closed = true
+	producers, consumers := c.producers, c.consumers
+
+	c.locker.Unlock()
+
+	if !c.options.Payloads {
+		for _, producer := range producers {
+			if !producer.Closed() {
+				producer.EnableTraceEvent()
+			}
+		}
+	}
+	for _, consumer := range consumers {
+		if !consumer.Closed() {
+			consumer.EnableTraceEvent()
+		}
+	}
+	if c.tap != nil {
+		c.tap.Close()
+	}
+
+	return nil
+}
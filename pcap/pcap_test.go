@@ -0,0 +1,99 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCapture_InvalidOptions(t *testing.T) {
+	_, err := NewCapture(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, buf.Len())
+	assert.EqualValues(t, 0xa1b2c3d4, binary.LittleEndian.Uint32(buf.Bytes()))
+	assert.EqualValues(t, 65535, binary.LittleEndian.Uint32(buf.Bytes()[16:]))
+	assert.EqualValues(t, linkTypeRaw, binary.LittleEndian.Uint32(buf.Bytes()[20:]))
+
+	src := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	dst := &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 6000}
+	at := time.Unix(1600000000, 123456000)
+
+	// Header only of a 1000 bytes packet.
+	assert.NoError(t, w.WritePacket(at, src, dst, make([]byte, 12), 1000))
+
+	record := buf.Bytes()[24:]
+	assert.EqualValues(t, 1600000000, binary.LittleEndian.Uint32(record[0:]))
+	assert.EqualValues(t, 123456, binary.LittleEndian.Uint32(record[4:]))
+	assert.EqualValues(t, 20+8+12, binary.LittleEndian.Uint32(record[8:]))
+	assert.EqualValues(t, 20+8+1000, binary.LittleEndian.Uint32(record[12:]))
+
+	ip := record[16:36]
+	assert.EqualValues(t, 0x45, ip[0])
+	assert.EqualValues(t, 1028, binary.BigEndian.Uint16(ip[2:]))
+	assert.Zero(t, checksum(ip))
+	assert.Equal(t, []byte{10, 0, 0, 1, 10, 0, 0, 2}, []byte(ip[12:20]))
+
+	udp := record[36:44]
+	assert.EqualValues(t, 5000, binary.BigEndian.Uint16(udp[0:]))
+	assert.EqualValues(t, 6000, binary.BigEndian.Uint16(udp[2:]))
+	assert.EqualValues(t, 1008, binary.BigEndian.Uint16(udp[4:]))
+}
+
+func TestIpUdpHeader_IPv6(t *testing.T) {
+	src := &net.UDPAddr{IP: net.ParseIP("::1"), Port: 5000}
+	dst := &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 6000}
+
+	header := ipUdpHeader(src, dst, 100)
+	assert.Len(t, header, 48)
+	assert.EqualValues(t, 0x60, header[0])
+	assert.EqualValues(t, 108, binary.BigEndian.Uint16(header[4:]))
+	assert.Equal(t, []byte(net.ParseIP("::ffff:10.0.0.2")), header[24:40])
+}
+
+func TestTraceRtpHeader(t *testing.T) {
+	header, length, ok := traceRtpHeader(mediasoup.H{
+		"payloadType":    float64(101),
+		"sequenceNumber": float64(65535),
+		"timestamp":      float64(3000000000),
+		"marker":         true,
+		"ssrc":           float64(1234),
+		"size":           float64(1200),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 1200, length)
+	assert.Equal(t, []byte{0x80, 0x80 | 101, 0xff, 0xff, 0xb2, 0xd0, 0x5e, 0x00, 0, 0, 0x04, 0xd2}, header)
+
+	_, _, ok = traceRtpHeader(mediasoup.H{"payloadType": float64(100)})
+	assert.False(t, ok)
+}
+
+func TestCapture_SsrcFilter(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, _ := NewWriter(&buf, 0)
+	capture := &Capture{
+		logger: mediasoup.NewLogger("Capture"),
+		writer: writer,
+		ssrcs:  map[uint32]bool{1234: true},
+	}
+	capture.local, capture.remote = addresses(nil)
+
+	for _, ssrc := range []float64{1234, 5678} {
+		capture.writeTrace(mediasoup.H{"ssrc": ssrc, "size": float64(100)}, capture.remote, capture.local)
+	}
+
+	assert.Equal(t, 1, capture.Packets())
+	assert.Equal(t, 24+16+20+8+12, buf.Len())
+}
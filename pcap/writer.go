@@ -0,0 +1,130 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Link type of the packets, starting with their IPv4 or IPv6 header.
+const linkTypeRaw = 101
+
+/**
+ * Writer writes UDP datagrams into a pcap file, with synthesized IP and UDP
+ * headers.
+ */
+type Writer struct {
+	locker  sync.Mutex
+	w       io.Writer
+	snapLen int
+}
+
+/**
+ * Create a Writer, writing the header of the file. Packets are truncated to
+ * snapLen bytes (65535 if 0).
+ */
+func NewWriter(w io.Writer, snapLen int) (*Writer, error) {
+	if snapLen <= 0 {
+		snapLen = 65535
+	}
+
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], uint32(snapLen))
+	binary.LittleEndian.PutUint32(header[20:], linkTypeRaw)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, snapLen: snapLen}, nil
+}
+
+/**
+ * Write a UDP datagram. Only the first captured bytes of the payload are
+ * written, the datagram having the whole payload length (e.g. a RTP packet of
+ * which only the header is known).
+ */
+func (w *Writer) WritePacket(at time.Time, src, dst *net.UDPAddr, payload []byte, payloadLen int) error {
+	if payloadLen < len(payload) {
+		payloadLen = len(payload)
+	}
+
+	packet := ipUdpHeader(src, dst, payloadLen)
+	origLen := len(packet) + payloadLen
+	packet = append(packet, payload...)
+
+	if len(packet) > w.snapLen {
+		packet = packet[:w.snapLen]
+	}
+
+	record := make([]byte, 16, 16+len(packet))
+	binary.LittleEndian.PutUint32(record[0:], uint32(at.Unix()))
+	binary.LittleEndian.PutUint32(record[4:], uint32(at.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(record[8:], uint32(len(packet)))
+	binary.LittleEndian.PutUint32(record[12:], uint32(origLen))
+
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	_, err := w.w.Write(append(record, packet...))
+
+	return err
+}
+
+// ipUdpHeader returns the IPv4 (IPv6 if any address is IPv6) and UDP headers
+// of a datagram. The UDP checksum is left empty, the payload not being always
+// captured.
+func ipUdpHeader(src, dst *net.UDPAddr, payloadLen int) []byte {
+	udpLen := 8 + payloadLen
+	udp := make([]byte, 8)
+	binary.BigEndian.PutUint16(udp[0:], uint16(src.Port))
+	binary.BigEndian.PutUint16(udp[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint16(udp[4:], uint16(udpLen))
+
+	src4, dst4 := src.IP.To4(), dst.IP.To4()
+
+	if src4 != nil && dst4 != nil {
+		ip := make([]byte, 20)
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:], uint16(20+udpLen))
+		ip[6] = 0x40 // don't fragment
+		ip[8] = 64
+		ip[9] = 17
+		copy(ip[12:], src4)
+		copy(ip[16:], dst4)
+		binary.BigEndian.PutUint16(ip[10:], checksum(ip))
+
+		return append(ip, udp...)
+	}
+
+	ip := make([]byte, 40)
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:], uint16(udpLen))
+	ip[6] = 17
+	ip[7] = 64
+	copy(ip[8:], src.IP.To16())
+	copy(ip[24:], dst.IP.To16())
+
+	return append(ip, udp...)
+}
+
+// checksum returns the Internet checksum of the data.
+func checksum(data []byte) uint16 {
+	var sum uint32
+
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,73 @@
+package pionwebrtc
+
+import (
+	"errors"
+	"net"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/pion/webrtc/v3"
+)
+
+/**
+ * Create a pion track fed with the RTP packets of the given Consumer. The
+ * Consumer must belong to a DirectTransport, which delivers its RTP packets to
+ * the Go process. The track is not closed when the Consumer is closed, it just
+ * stops receiving packets.
+ */
+func BridgeConsumer(consumer *mediasoup.Consumer, id, streamID string) (*webrtc.TrackLocalStaticRTP, error) {
+	track, err := NewTrackLocal(consumer.RtpParameters(), id, streamID)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer.On("rtp", func(payload []byte) {
+		// Packets are dropped when the track is not bound to any PeerConnection.
+		track.Write(payload)
+	})
+
+	return track, nil
+}
+
+/**
+ * Forward the RTP packets received on the given connection to the track until
+ * the connection is closed. It is intended for Consumers of a PlainTransport
+ * connected to the local address of conn (with rtcpMux and without comedia).
+ */
+func ForwardRTP(conn net.PacketConn, track *webrtc.TrackLocalStaticRTP) error {
+	buf := make([]byte, 1500)
+
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+
+			if errors.As(err, &netErr) && netErr.Temporary() {
+				continue
+			}
+			return err
+		}
+
+		// Ignore RTCP packets (payload type 192-223 when RTCP-mux is used).
+		if n < 12 || (buf[1] >= 192 && buf[1] <= 223) {
+			continue
+		}
+
+		if _, err = track.Write(buf[:n]); err != nil {
+			return err
+		}
+	}
+}
+
+/**
+ * Create a pion track for the media described by the given RTP parameters
+ * (e.g. consumer.RtpParameters()). The first codec is used.
+ */
+func NewTrackLocal(rtpParameters mediasoup.RtpParameters, id, streamID string) (*webrtc.TrackLocalStaticRTP, error) {
+	if len(rtpParameters.Codecs) == 0 {
+		return nil, mediasoup.NewTypeError("missing codecs")
+	}
+
+	codec := ToRTPCodecParameters(rtpParameters.Codecs[0])
+
+	return webrtc.NewTrackLocalStaticRTP(codec.RTPCodecCapability, id, streamID)
+}
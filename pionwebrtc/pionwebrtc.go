@@ -0,0 +1,201 @@
+// Package pionwebrtc converts mediasoup RTP parameters and capabilities to and
+// from the pion/webrtc ones, and bridges mediasoup Consumers into pion tracks.
+//
+// It is a separate module so that the mediasoup-go module does not depend on
+// pion/webrtc.
+package pionwebrtc
+
+import (
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/sdp"
+	"github.com/pion/webrtc/v3"
+)
+
+/**
+ * Convert a mediasoup codec into a pion codec.
+ */
+func ToRTPCodecParameters(codec *mediasoup.RtpCodecParameters) webrtc.RTPCodecParameters {
+	return webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:     codec.MimeType,
+			ClockRate:    uint32(codec.ClockRate),
+			Channels:     uint16(codec.Channels),
+			SDPFmtpLine:  sdp.FormatFmtp(codec.Parameters),
+			RTCPFeedback: toRTCPFeedback(codec.RtcpFeedback),
+		},
+		PayloadType: webrtc.PayloadType(codec.PayloadType),
+	}
+}
+
+/**
+ * Convert a pion codec into a mediasoup codec.
+ */
+func FromRTPCodecParameters(codec webrtc.RTPCodecParameters) (*mediasoup.RtpCodecParameters, error) {
+	params, err := sdp.ParseFmtp(codec.SDPFmtpLine)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mediasoup.RtpCodecParameters{
+		MimeType:     codec.MimeType,
+		PayloadType:  byte(codec.PayloadType),
+		ClockRate:    int(codec.ClockRate),
+		Channels:     int(codec.Channels),
+		Parameters:   params,
+		RtcpFeedback: fromRTCPFeedback(codec.RTCPFeedback),
+	}, nil
+}
+
+/**
+ * Convert mediasoup RTP parameters into pion ones. Encodings and RTCP
+ * parameters have no pion counterpart and are ignored.
+ */
+func ToRTPParameters(params mediasoup.RtpParameters) (rtpParameters webrtc.RTPParameters) {
+	for _, codec := range params.Codecs {
+		rtpParameters.Codecs = append(rtpParameters.Codecs, ToRTPCodecParameters(codec))
+	}
+	for _, ext := range params.HeaderExtensions {
+		rtpParameters.HeaderExtensions = append(rtpParameters.HeaderExtensions, webrtc.RTPHeaderExtensionParameter{
+			URI: ext.Uri,
+			ID:  ext.Id,
+		})
+	}
+
+	return
+}
+
+/**
+ * Convert pion RTP parameters into mediasoup ones.
+ */
+func FromRTPParameters(params webrtc.RTPParameters) (rtpParameters mediasoup.RtpParameters, err error) {
+	for _, codec := range params.Codecs {
+		rtpCodec, err := FromRTPCodecParameters(codec)
+		if err != nil {
+			return rtpParameters, err
+		}
+		rtpParameters.Codecs = append(rtpParameters.Codecs, rtpCodec)
+	}
+	for _, ext := range params.HeaderExtensions {
+		rtpParameters.HeaderExtensions = append(rtpParameters.HeaderExtensions, mediasoup.RtpHeaderExtensionParameters{
+			Uri: ext.URI,
+			Id:  ext.ID,
+		})
+	}
+
+	return
+}
+
+/**
+ * Generate the mediasoup RTP capabilities of a pion endpoint given the codecs
+ * and header extensions registered in its MediaEngine, so it can consume from
+ * mediasoup. Header extensions have no kind in pion, so they are declared for
+ * both audio and video.
+ */
+func RtpCapabilities(
+	codecs []webrtc.RTPCodecParameters,
+	headerExtensions []webrtc.RTPHeaderExtensionParameter,
+) (caps mediasoup.RtpCapabilities, err error) {
+	for _, codec := range codecs {
+		rtpCodec, err := FromRTPCodecParameters(codec)
+		if err != nil {
+			return caps, err
+		}
+		caps.Codecs = append(caps.Codecs, &mediasoup.RtpCodecCapability{
+			Kind:                 kindOf(codec.MimeType),
+			MimeType:             rtpCodec.MimeType,
+			PreferredPayloadType: rtpCodec.PayloadType,
+			ClockRate:            rtpCodec.ClockRate,
+			Channels:             rtpCodec.Channels,
+			Parameters:           rtpCodec.Parameters,
+			RtcpFeedback:         rtpCodec.RtcpFeedback,
+		})
+	}
+	for _, ext := range headerExtensions {
+		caps.HeaderExtensions = append(caps.HeaderExtensions, &mediasoup.RtpHeaderExtension{
+			Uri:         ext.URI,
+			PreferredId: ext.ID,
+		})
+	}
+
+	err = mediasoup.ValidateRtpCapabilities(&caps)
+
+	return
+}
+
+/**
+ * Register the codecs and header extensions of the given mediasoup RTP
+ * capabilities (e.g. router.RtpCapabilities()) into a pion MediaEngine, so the
+ * pion endpoint negotiates the same payload types and extension ids.
+ */
+func RegisterRtpCapabilities(m *webrtc.MediaEngine, caps mediasoup.RtpCapabilities) error {
+	for _, codec := range caps.Codecs {
+		rtpCodec := ToRTPCodecParameters(&mediasoup.RtpCodecParameters{
+			MimeType:     codec.MimeType,
+			PayloadType:  codec.PreferredPayloadType,
+			ClockRate:    codec.ClockRate,
+			Channels:     codec.Channels,
+			Parameters:   codec.Parameters,
+			RtcpFeedback: codec.RtcpFeedback,
+		})
+
+		if err := m.RegisterCodec(rtpCodec, toRTPCodecType(codec.Kind)); err != nil {
+			return err
+		}
+	}
+
+	for _, ext := range caps.HeaderExtensions {
+		capability := webrtc.RTPHeaderExtensionCapability{URI: ext.Uri}
+
+		var directions []webrtc.RTPTransceiverDirection
+
+		switch ext.Direction {
+		case mediasoup.Direction_Sendonly:
+			// mediasoup sends it, so pion receives it.
+			directions = append(directions, webrtc.RTPTransceiverDirectionRecvonly)
+		case mediasoup.Direction_Recvonly:
+			directions = append(directions, webrtc.RTPTransceiverDirectionSendonly)
+		case mediasoup.Direction_Inactive:
+			continue
+		}
+
+		kinds := []mediasoup.MediaKind{ext.Kind}
+
+		if len(ext.Kind) == 0 {
+			kinds = []mediasoup.MediaKind{mediasoup.MediaKind_Audio, mediasoup.MediaKind_Video}
+		}
+		for _, kind := range kinds {
+			if err := m.RegisterHeaderExtension(capability, toRTPCodecType(kind), directions...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func toRTCPFeedback(fbs []mediasoup.RtcpFeedback) (rtcpFeedback []webrtc.RTCPFeedback) {
+	for _, fb := range fbs {
+		rtcpFeedback = append(rtcpFeedback, webrtc.RTCPFeedback{Type: fb.Type, Parameter: fb.Parameter})
+	}
+	return
+}
+
+func fromRTCPFeedback(fbs []webrtc.RTCPFeedback) (rtcpFeedback []mediasoup.RtcpFeedback) {
+	for _, fb := range fbs {
+		rtcpFeedback = append(rtcpFeedback, mediasoup.RtcpFeedback{Type: fb.Type, Parameter: fb.Parameter})
+	}
+	return
+}
+
+func toRTPCodecType(kind mediasoup.MediaKind) webrtc.RTPCodecType {
+	if kind == mediasoup.MediaKind_Audio {
+		return webrtc.RTPCodecTypeAudio
+	}
+	return webrtc.RTPCodecTypeVideo
+}
+
+func kindOf(mimeType string) mediasoup.MediaKind {
+	return mediasoup.MediaKind(strings.ToLower(strings.Split(mimeType, "/")[0]))
+}
@@ -0,0 +1,56 @@
+package pionwebrtc
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTPCodecParameters(t *testing.T) {
+	codec := &mediasoup.RtpCodecParameters{
+		MimeType:    "video/VP9",
+		PayloadType: 103,
+		ClockRate:   90000,
+		Parameters:  mediasoup.RtpCodecSpecificParameters{{Key: "profile-id", Value: "2"}},
+		RtcpFeedback: []mediasoup.RtcpFeedback{
+			{Type: "nack"},
+			{Type: "nack", Parameter: "pli"},
+		},
+	}
+
+	pionCodec := ToRTPCodecParameters(codec)
+
+	assert.EqualValues(t, 103, pionCodec.PayloadType)
+	assert.EqualValues(t, 90000, pionCodec.ClockRate)
+	assert.Equal(t, "profile-id=2", pionCodec.SDPFmtpLine)
+	assert.Equal(t, []webrtc.RTCPFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}}, pionCodec.RTCPFeedback)
+
+	converted, err := FromRTPCodecParameters(pionCodec)
+	require.NoError(t, err)
+	assert.Equal(t, codec, converted)
+}
+
+func TestRtpCapabilities(t *testing.T) {
+	caps, err := RtpCapabilities(
+		[]webrtc.RTPCodecParameters{
+			{
+				RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/opus", ClockRate: 48000, Channels: 2, SDPFmtpLine: "minptime=10;useinbandfec=1"},
+				PayloadType:        111,
+			},
+		},
+		[]webrtc.RTPHeaderExtensionParameter{
+			{URI: "urn:ietf:params:rtp-hdrext:sdes:mid", ID: 4},
+		},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, caps.Codecs, 1)
+	assert.Equal(t, mediasoup.MediaKind_Audio, caps.Codecs[0].Kind)
+	assert.EqualValues(t, 111, caps.Codecs[0].PreferredPayloadType)
+	assert.EqualValues(t, 1, caps.Codecs[0].Parameters.GetInt("useinbandfec"))
+	require.Len(t, caps.HeaderExtensions, 1)
+	assert.Equal(t, mediasoup.Direction_Sendrecv, caps.HeaderExtensions[0].Direction)
+}
@@ -1,11 +1,10 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
-
-	uuid "github.com/satori/go.uuid"
 )
 
 type PipeTransportOptions struct {
@@ -50,6 +49,12 @@ type PipeTransportOptions struct {
 	 */
 	EnableSrtp bool `json:"enableSrtp,omitempty"`
 
+	/**
+	 * Base logger for the transport and its Producers and Consumers. Default
+	 * the one of the Router.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -105,7 +110,7 @@ func newPipeTransport(params transportParams) ITransport {
 		sctpState:      data.SctpState,
 		transportType:  TransportType_Pipe,
 	}
-	params.logger = NewLogger("PipeTransport")
+	params.logger = params.loggerContext.newLogger("PipeTransport")
 
 	transport := &PipeTransport{
 		ITransport:      newTransport(params),
@@ -126,6 +131,9 @@ func newPipeTransport(params transportParams) ITransport {
  * Transport tuple.
  */
 func (t PipeTransport) Tuple() TransportTuple {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.Tuple
 }
 
@@ -140,7 +148,7 @@ func (t PipeTransport) SctpParameters() SctpParameters {
  * SCTP state.
  */
 func (t PipeTransport) SctpState() SctpState {
-	return t.data.SctpState
+	return t.data.GetSctpState()
 }
 
 /**
@@ -171,16 +179,16 @@ func (transport *PipeTransport) Observer() IEventEmitter {
  *
  * @override
  */
-func (transport *PipeTransport) Close() {
+func (transport *PipeTransport) Close() error {
 	if transport.Closed() {
-		return
+		return ErrAlreadyClosed
 	}
 
 	if len(transport.data.GetSctpState()) > 0 {
 		transport.data.SetSctpState(SctpState_Closed)
 	}
 
-	transport.ITransport.Close()
+	return transport.ITransport.Close()
 }
 
 /**
@@ -206,6 +214,13 @@ func (transport *PipeTransport) routerClosed() {
  * @override
  */
 func (transport *PipeTransport) Connect(options TransportConnectOptions) (err error) {
+	return transport.ConnectContext(context.Background(), options)
+}
+
+/**
+ * ConnectContext is Connect with a context cancelling the worker request.
+ */
+func (transport *PipeTransport) ConnectContext(ctx context.Context, options TransportConnectOptions) (err error) {
 	transport.logger.Debug("connect()")
 
 	reqData := TransportConnectOptions{
@@ -213,7 +228,7 @@ func (transport *PipeTransport) Connect(options TransportConnectOptions) (err er
 		Port:           options.Port,
 		SrtpParameters: options.SrtpParameters,
 	}
-	resp := transport.channel.Request("transport.connect", transport.internal, reqData)
+	resp := transport.channel.RequestContext(ctx, "transport.connect", transport.internal, reqData)
 
 	var data struct {
 		Tuple TransportTuple
@@ -233,9 +248,20 @@ func (transport *PipeTransport) Connect(options TransportConnectOptions) (err er
  *
  * @override
  */
-func (transport *PipeTransport) Consume(options ConsumerOptions) (consumer *Consumer, err error) {
+func (transport *PipeTransport) Consume(options ConsumerOptions, opts ...ConsumerOption) (consumer *Consumer, err error) {
+	return transport.ConsumeContext(context.Background(), options, opts...)
+}
+
+/**
+ * ConsumeContext is Consume with a context cancelling the worker request.
+ */
+func (transport *PipeTransport) ConsumeContext(ctx context.Context, options ConsumerOptions, opts ...ConsumerOption) (consumer *Consumer, err error) {
 	transport.logger.Debug("consume()")
 
+	for _, opt := range opts {
+		opt.applyConsumer(&options)
+	}
+
 	producerId := options.ProducerId
 	appData := options.AppData
 
@@ -248,7 +274,7 @@ func (transport *PipeTransport) Consume(options ConsumerOptions) (consumer *Cons
 
 	rtpParameters := getPipeConsumerRtpParameters(producer.ConsumableRtpParameters(), transport.data.Rtx)
 	internal := transport.internal
-	internal.ConsumerId = uuid.NewV4().String()
+	internal.ConsumerId = transport.base().idGenerator.newId("consumer")
 	internal.ProducerId = producerId
 
 	reqData := H{
@@ -257,7 +283,7 @@ func (transport *PipeTransport) Consume(options ConsumerOptions) (consumer *Cons
 		"type":                   "pipe",
 		"consumableRtpEncodings": producer.ConsumableRtpParameters().Encodings,
 	}
-	resp := transport.channel.Request("transport.consume", internal, reqData)
+	resp := transport.channel.requestCreation(ctx, "transport.consume", "consumer.close", internal, reqData)
 
 	var status struct {
 		Paused         bool
@@ -282,6 +308,14 @@ func (transport *PipeTransport) Consume(options ConsumerOptions) (consumer *Cons
 		producerPaused: status.ProducerPaused,
 	})
 
+	if len(options.TraceEventTypes) > 0 {
+		if err = consumer.EnableTraceEvent(options.TraceEventTypes...); err != nil {
+			consumer.Close()
+			consumer = nil
+			return
+		}
+	}
+
 	baseTransport := transport.ITransport.(*Transport)
 
 	baseTransport.consumers.Store(consumer.Id(), consumer)
@@ -316,7 +350,7 @@ func (transport *PipeTransport) handleWorkerNotifications() {
 
 		case "trace":
 			var result TransportTraceEventData
-			json.Unmarshal(data, &result)
+			unmarshalJSON(data, &result)
 
 			transport.SafeEmit("trace", result)
 
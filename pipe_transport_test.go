@@ -91,7 +91,7 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithAudio
 			ClockRate:    48000,
 			PayloadType:  100,
 			Channels:     2,
-			Parameters:   RtpCodecSpecificParameters{Useinbandfec: 1, Usedtx: 1},
+			Parameters:   RtpCodecSpecificParameters{{Key: "useinbandfec", Value: 1}, {Key: "usedtx", Value: 1}},
 			RtcpFeedback: []RtcpFeedback{},
 		},
 	}, pipeConsumer.RtpParameters().Codecs)
@@ -101,6 +101,11 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithAudio
 			Id:      10,
 			Encrypt: false,
 		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			Id:      13,
+			Encrypt: false,
+		},
 	}, pipeConsumer.RtpParameters().HeaderExtensions)
 	suite.EqualValues("pipe", pipeConsumer.Type())
 	suite.False(pipeConsumer.Paused())
@@ -122,7 +127,7 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithAudio
 			ClockRate:    48000,
 			PayloadType:  100,
 			Channels:     2,
-			Parameters:   RtpCodecSpecificParameters{Useinbandfec: 1, Usedtx: 1},
+			Parameters:   RtpCodecSpecificParameters{{Key: "useinbandfec", Value: 1}, {Key: "usedtx", Value: 1}},
 			RtcpFeedback: []RtcpFeedback{},
 		},
 	}, pipeProducer.RtpParameters().Codecs)
@@ -132,6 +137,11 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithAudio
 			Id:      10,
 			Encrypt: false,
 		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			Id:      13,
+			Encrypt: false,
+		},
 	}, pipeProducer.RtpParameters().HeaderExtensions)
 	suite.False(pipeProducer.Paused())
 }
@@ -195,6 +205,16 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithVideo
 			Id:      12,
 			Encrypt: false,
 		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			Id:      13,
+			Encrypt: false,
+		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay",
+			Id:      14,
+			Encrypt: false,
+		},
 	}, pipeConsumer.RtpParameters().HeaderExtensions)
 	suite.EqualValues("pipe", pipeConsumer.Type())
 	suite.False(pipeConsumer.Paused())
@@ -242,6 +262,16 @@ func (suite *PipeTransportTestingSuite) TestRouterPipeToRouter_SucceedsWithVideo
 			Id:      12,
 			Encrypt: false,
 		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			Id:      13,
+			Encrypt: false,
+		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay",
+			Id:      14,
+			Encrypt: false,
+		},
 	}, pipeProducer.RtpParameters().HeaderExtensions)
 	suite.True(pipeProducer.Paused())
 }
@@ -289,7 +319,7 @@ func (suite *PipeTransportTestingSuite) TestRouterCreatePipeTransport_WithEnable
 			ClockRate:   90000,
 			PayloadType: 102,
 			Parameters: RtpCodecSpecificParameters{
-				Apt: 101,
+				{Key: "apt", Value: 101},
 			},
 		},
 	}, pipeConsumer.RtpParameters().Codecs)
@@ -314,6 +344,16 @@ func (suite *PipeTransportTestingSuite) TestRouterCreatePipeTransport_WithEnable
 			Id:      12,
 			Encrypt: false,
 		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			Id:      13,
+			Encrypt: false,
+		},
+		{
+			Uri:     "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay",
+			Id:      14,
+			Encrypt: false,
+		},
 	}, pipeConsumer.RtpParameters().HeaderExtensions)
 	suite.EqualValues("pipe", pipeConsumer.Type())
 	suite.False(pipeConsumer.Paused())
@@ -422,7 +462,7 @@ func (suite *PipeTransportTestingSuite) TestTransportConsume_ForAPipeProducerSuc
 			ClockRate:   90000,
 			PayloadType: 102,
 			Parameters: RtpCodecSpecificParameters{
-				Apt: 101,
+				{Key: "apt", Value: 101},
 			},
 			RtcpFeedback: []RtcpFeedback{},
 		},
@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 )
@@ -58,6 +59,12 @@ type PlainTransportOptions struct {
 	 */
 	SrtpCryptoSuite SrtpCryptoSuite `json:"srtpCryptoSuite,omitempty"`
 
+	/**
+	 * Base logger for the transport and its Producers and Consumers. Default
+	 * the one of the Router.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -134,7 +141,7 @@ func newPlainTransport(params transportParams) ITransport {
 		sctpState:      data.SctpState,
 		transportType:  TransportType_Plain,
 	}
-	params.logger = NewLogger("PlainTransport")
+	params.logger = params.loggerContext.newLogger("PlainTransport")
 
 	transport := &PlainTransport{
 		ITransport: newTransport(params),
@@ -153,6 +160,9 @@ func newPlainTransport(params transportParams) ITransport {
  * Transport tuple.
  */
 func (t PlainTransport) Tuple() *TransportTuple {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.Tuple
 }
 
@@ -160,6 +170,9 @@ func (t PlainTransport) Tuple() *TransportTuple {
  * Transport RTCP tuple.
  */
 func (t PlainTransport) RtcpTuple() *TransportTuple {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.RtcpTuple
 }
 
@@ -174,13 +187,16 @@ func (t PlainTransport) SctpParameters() SctpParameters {
  * SCTP state.
  */
 func (t PlainTransport) SctpState() SctpState {
-	return t.data.SctpState
+	return t.data.GetSctpState()
 }
 
 /**
  * SRTP parameters.
  */
 func (t PlainTransport) SrtpParameters() *SrtpParameters {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.SrtpParameters
 }
 
@@ -207,16 +223,16 @@ func (transport *PlainTransport) Observer() IEventEmitter {
  *
  * @override
  */
-func (transport *PlainTransport) Close() {
+func (transport *PlainTransport) Close() error {
 	if transport.Closed() {
-		return
+		return ErrAlreadyClosed
 	}
 
 	if len(transport.data.GetSctpState()) > 0 {
 		transport.data.SetSctpState(SctpState_Closed)
 	}
 
-	transport.ITransport.Close()
+	return transport.ITransport.Close()
 }
 
 /**
@@ -242,6 +258,13 @@ func (transport *PlainTransport) routerClosed() {
  * @override
  */
 func (transport *PlainTransport) Connect(options TransportConnectOptions) (err error) {
+	return transport.ConnectContext(context.Background(), options)
+}
+
+/**
+ * ConnectContext is Connect with a context cancelling the worker request.
+ */
+func (transport *PlainTransport) ConnectContext(ctx context.Context, options TransportConnectOptions) (err error) {
 	transport.logger.Debug("connect()")
 
 	reqData := TransportConnectOptions{
@@ -250,7 +273,7 @@ func (transport *PlainTransport) Connect(options TransportConnectOptions) (err e
 		RtcpPort:       options.RtcpPort,
 		SrtpParameters: options.SrtpParameters,
 	}
-	resp := transport.channel.Request("transport.connect", transport.internal, reqData)
+	resp := transport.channel.RequestContext(ctx, "transport.connect", transport.internal, reqData)
 
 	var data struct {
 		Tuple          *TransportTuple
@@ -318,7 +341,7 @@ func (transport *PlainTransport) handleWorkerNotifications() {
 
 		case "trace":
 			var result TransportTraceEventData
-			json.Unmarshal(data, &result)
+			unmarshalJSON(data, &result)
 
 			transport.SafeEmit("trace", result)
 
@@ -1,7 +1,7 @@
 package mediasoup
 
 import (
-	"encoding/json"
+	"context"
 	"sync"
 	"sync/atomic"
 )
@@ -33,6 +33,16 @@ type ProducerOptions struct {
 	 */
 	KeyFrameRequestDelay uint32 `json:"keyFrameRequestDelay,omitempty"`
 
+	/**
+	 * Trace events to enable as soon as the Producer is created.
+	 */
+	TraceEventTypes []ProducerTraceEventType `json:"-"`
+
+	/**
+	 * Base logger for the Producer. Default the one of the Transport.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -157,6 +167,7 @@ type producerData struct {
 	Type                    ProducerType  `json:"type,omitempty"`
 	RtpParameters           RtpParameters `json:"rtpParameters,omitempty"`
 	ConsumableRtpParameters RtpParameters `json:"consumableRtpParameters,omitempty"`
+	RtpMapping              RtpMapping    `json:"rtpMapping,omitempty"`
 }
 
 type producerParams struct {
@@ -172,6 +183,7 @@ type producerParams struct {
 	payloadChannel *PayloadChannel
 	appData        interface{}
 	paused         bool
+	loggerContext  loggerContext
 }
 
 /**
@@ -195,10 +207,12 @@ type Producer struct {
 	closed         uint32
 	score          []ProducerScore
 	observer       IEventEmitter
+	// Guards paused and score.
+	dataLocker sync.RWMutex
 }
 
 func newProducer(params producerParams) *Producer {
-	logger := NewLogger("Producer")
+	logger := params.loggerContext.newLogger("Producer")
 
 	logger.Debug("constructor()")
 
@@ -215,7 +229,7 @@ func newProducer(params producerParams) *Producer {
 		payloadChannel: params.payloadChannel,
 		appData:        params.appData,
 		paused:         params.paused,
-		observer:       NewEventEmitter(),
+		observer:       params.loggerContext.newObserver("producer", params.internal.ProducerId),
 	}
 
 	producer.handleWorkerNotifications()
@@ -253,16 +267,28 @@ func (producer *Producer) ConsumableRtpParameters() RtpParameters {
 	return producer.data.ConsumableRtpParameters
 }
 
+/**
+ * RTP mapping computed when producing: the mapped payload type of each codec
+ * and the mapped SSRC of each encoding (identified by ssrc or rid), which is
+ * the SSRC of the matching encoding in the consumable RTP parameters.
+ */
+func (producer *Producer) RtpMapping() RtpMapping {
+	return producer.data.RtpMapping
+}
+
 // Whether the Producer is paused.
 func (producer *Producer) Paused() bool {
-	producer.locker.Lock()
-	defer producer.locker.Unlock()
+	producer.dataLocker.RLock()
+	defer producer.dataLocker.RUnlock()
 
 	return producer.paused
 }
 
 // Producer score list.
 func (producer *Producer) Score() []ProducerScore {
+	producer.dataLocker.RLock()
+	defer producer.dataLocker.RUnlock()
+
 	return producer.score
 }
 
@@ -287,27 +313,29 @@ func (producer *Producer) Observer() IEventEmitter {
 
 // Close the Producer.
 func (producer *Producer) Close() (err error) {
-	if atomic.CompareAndSwapUint32(&producer.closed, 0, 1) {
-		producer.logger.Debug("close()")
-
-		// Remove notification subscriptions.
-		producer.channel.RemoveAllListeners(producer.Id())
-		producer.payloadChannel.RemoveAllListeners(producer.Id())
+	if !atomic.CompareAndSwapUint32(&producer.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		response := producer.channel.Request("producer.close", producer.internal)
+	producer.logger.Debug("close()")
 
-		if err = response.Err(); err != nil {
-			producer.logger.Error("producer close error: %s", err)
-		}
+	// Remove notification subscriptions.
+	producer.channel.RemoveAllListeners(producer.Id())
+	producer.payloadChannel.RemoveAllListeners(producer.Id())
 
-		producer.Emit("@close")
-		producer.RemoveAllListeners()
+	response := producer.channel.Request("producer.close", producer.internal)
 
-		// Emit observer event.
-		producer.observer.SafeEmit("close")
-		producer.observer.RemoveAllListeners()
+	if err = response.Err(); err != nil {
+		producer.logger.Error("producer close error: %s", err)
 	}
 
+	producer.Emit("@close")
+	producer.RemoveAllListeners()
+
+	// Emit observer event.
+	producer.observer.SafeEmit("close")
+	producer.observer.RemoveAllListeners()
+
 	return
 }
 
@@ -331,9 +359,16 @@ func (producer *Producer) transportClosed() {
 
 // Dump Producer.
 func (producer *Producer) Dump() (dump ProducerDump, err error) {
+	return producer.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (producer *Producer) DumpContext(ctx context.Context) (dump ProducerDump, err error) {
 	producer.logger.Debug("dump()")
 
-	resp := producer.channel.Request("producer.dump", producer.internal)
+	resp := producer.channel.RequestContext(ctx, "producer.dump", producer.internal)
 	err = resp.Unmarshal(&dump)
 
 	return
@@ -341,9 +376,16 @@ func (producer *Producer) Dump() (dump ProducerDump, err error) {
 
 // Get Producer stats.
 func (producer *Producer) GetStats() (stats []*ProducerStat, err error) {
+	return producer.GetStatsContext(context.Background())
+}
+
+/**
+ * GetStatsContext is GetStats with a context cancelling the worker request.
+ */
+func (producer *Producer) GetStatsContext(ctx context.Context) (stats []*ProducerStat, err error) {
 	producer.logger.Debug("getStats()")
 
-	resp := producer.channel.Request("producer.getStats", producer.internal)
+	resp := producer.channel.RequestContext(ctx, "producer.getStats", producer.internal)
 	err = resp.Unmarshal(&stats)
 
 	return
@@ -351,20 +393,29 @@ func (producer *Producer) GetStats() (stats []*ProducerStat, err error) {
 
 // Pause the Producer.
 func (producer *Producer) Pause() (err error) {
+	return producer.PauseContext(context.Background())
+}
+
+/**
+ * PauseContext is Pause with a context cancelling the worker request.
+ */
+func (producer *Producer) PauseContext(ctx context.Context) (err error) {
 	producer.locker.Lock()
 	defer producer.locker.Unlock()
 
 	producer.logger.Debug("pause()")
 
-	wasPaused := producer.paused
+	wasPaused := producer.Paused()
 
-	response := producer.channel.Request("producer.pause", producer.internal)
+	response := producer.channel.RequestContext(ctx, "producer.pause", producer.internal)
 
 	if err = response.Err(); err != nil {
 		return
 	}
 
+	producer.dataLocker.Lock()
 	producer.paused = true
+	producer.dataLocker.Unlock()
 
 	// Emit observer event.
 	if !wasPaused {
@@ -376,20 +427,29 @@ func (producer *Producer) Pause() (err error) {
 
 // Resume the Producer.
 func (producer *Producer) Resume() (err error) {
+	return producer.ResumeContext(context.Background())
+}
+
+/**
+ * ResumeContext is Resume with a context cancelling the worker request.
+ */
+func (producer *Producer) ResumeContext(ctx context.Context) (err error) {
 	producer.locker.Lock()
 	defer producer.locker.Unlock()
 
 	producer.logger.Debug("resume()")
 
-	wasPaused := producer.paused
+	wasPaused := producer.Paused()
 
-	result := producer.channel.Request("producer.resume", producer.internal)
+	result := producer.channel.RequestContext(ctx, "producer.resume", producer.internal)
 
 	if err = result.Err(); err != nil {
 		return
 	}
 
+	producer.dataLocker.Lock()
 	producer.paused = false
+	producer.dataLocker.Unlock()
 
 	// Emit observer event.
 	if wasPaused {
@@ -403,13 +463,20 @@ func (producer *Producer) Resume() (err error) {
  * Enable 'trace' event.
  */
 func (producer *Producer) EnableTraceEvent(types ...ProducerTraceEventType) error {
+	return producer.EnableTraceEventContext(context.Background(), types...)
+}
+
+/**
+ * EnableTraceEventContext is EnableTraceEvent with a context cancelling the worker request.
+ */
+func (producer *Producer) EnableTraceEventContext(ctx context.Context, types ...ProducerTraceEventType) error {
 	producer.logger.Debug("enableTraceEvent()")
 
 	if types == nil {
 		types = []ProducerTraceEventType{}
 	}
 
-	result := producer.channel.Request("producer.enableTraceEvent", producer.internal, H{"types": types})
+	result := producer.channel.RequestContext(ctx, "producer.enableTraceEvent", producer.internal, H{"types": types})
 
 	return result.Err()
 }
@@ -425,19 +492,23 @@ func (producer *Producer) handleWorkerNotifications() {
 	producer.channel.On(producer.Id(), func(event string, data []byte) {
 		switch event {
 		case "score":
-			producer.score = []ProducerScore{}
+			score := []ProducerScore{}
+
+			unmarshalJSON(data, &score)
 
-			json.Unmarshal([]byte(data), &producer.score)
+			producer.dataLocker.Lock()
+			producer.score = score
+			producer.dataLocker.Unlock()
 
-			producer.SafeEmit("score", producer.score)
+			producer.SafeEmit("score", score)
 
 			// Emit observer event.
-			producer.observer.SafeEmit("score", producer.score)
+			producer.observer.SafeEmit("score", score)
 
 		case "videoorientationchange":
 			orientation := ProducerVideoOrientation{}
 
-			json.Unmarshal([]byte(data), &orientation)
+			unmarshalJSON(data, &orientation)
 
 			producer.SafeEmit("videoorientationchange", orientation)
 
@@ -447,7 +518,7 @@ func (producer *Producer) handleWorkerNotifications() {
 		case "trace":
 			var trace ProducerTraceEventData
 
-			json.Unmarshal(data, &trace)
+			unmarshalJSON(data, &trace)
 
 			producer.SafeEmit("trace", trace)
 
@@ -3,8 +3,6 @@ package mediasoup
 import (
 	"testing"
 
-	"github.com/jiyeyuran/mediasoup-go/h264"
-
 	"github.com/stretchr/testify/suite"
 )
 
@@ -34,11 +32,9 @@ func (suite *ProducerTestingSuite) SetupTest() {
 			MimeType:  "video/H264",
 			ClockRate: 90000,
 			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					LevelAsymmetryAllowed: 1,
-					PacketizationMode:     1,
-					ProfileLevelId:        "4d0032",
-				},
+				{Key: "level-asymmetry-allowed", Value: 1},
+				{Key: "packetization-mode", Value: 1},
+				{Key: "profile-level-id", Value: "4d0032"},
 			},
 		},
 	}
@@ -100,6 +96,47 @@ func (suite *ProducerTestingSuite) TestWebRtcTransportProduce_Succeeds() {
 	suite.Empty(transportDump.ConsumerIds)
 }
 
+func (suite *ProducerTestingSuite) TestProducerRtpMapping_Succeeds() {
+	producer, err := suite.transport1.Produce(ProducerOptions{
+		Kind: MediaKind_Video,
+		RtpParameters: RtpParameters{
+			Mid: "VIDEO",
+			Codecs: []*RtpCodecParameters{
+				{
+					MimeType:    "video/VP8",
+					PayloadType: 112,
+					ClockRate:   90000,
+				},
+			},
+			HeaderExtensions: []RtpHeaderExtensionParameters{
+				{
+					Uri: "urn:ietf:params:rtp-hdrext:sdes:rtp-stream-id",
+					Id:  10,
+				},
+			},
+			Encodings: []RtpEncodingParameters{
+				{Rid: "r0"},
+				{Rid: "r1"},
+				{Rid: "r2"},
+			},
+		},
+	})
+	suite.Require().NoError(err)
+
+	rtpMapping := producer.RtpMapping()
+	consumableEncodings := producer.ConsumableRtpParameters().Encodings
+
+	suite.Require().Len(rtpMapping.Codecs, 1)
+	suite.EqualValues(112, rtpMapping.Codecs[0].PayloadType)
+	suite.Equal(producer.ConsumableRtpParameters().Codecs[0].PayloadType, rtpMapping.Codecs[0].MappedPayloadType)
+	suite.Require().Len(rtpMapping.Encodings, 3)
+
+	for i, rid := range []string{"r0", "r1", "r2"} {
+		suite.Equal(rid, rtpMapping.Encodings[i].Rid)
+		suite.Equal(consumableEncodings[i].Ssrc, rtpMapping.Encodings[i].MappedSsrc)
+	}
+}
+
 func (suite *ProducerTestingSuite) TestPlainRtpTransportProduce_Succeeds() {
 	onObserverNewProducer := NewMockFunc(suite.T())
 
@@ -169,17 +206,15 @@ func (suite *ProducerTestingSuite) TestWebRtcTransportProduce_TypeError() {
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "4d0032",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "4d0032"},
 					},
 				},
 				{
 					MimeType:    "video/rtx",
 					PayloadType: 113,
 					ClockRate:   90000,
-					Parameters:  RtpCodecSpecificParameters{Apt: 112},
+					Parameters:  RtpCodecSpecificParameters{{Key: "apt", Value: 112}},
 				},
 			},
 			Rtcp: RtcpParameters{
@@ -199,17 +234,15 @@ func (suite *ProducerTestingSuite) TestWebRtcTransportProduce_TypeError() {
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "4d0032",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "4d0032"},
 					},
 				},
 				{
 					MimeType:    "video/rtx",
 					PayloadType: 113,
 					ClockRate:   90000,
-					Parameters:  RtpCodecSpecificParameters{Apt: 111},
+					Parameters:  RtpCodecSpecificParameters{{Key: "apt", Value: 111}},
 				},
 			},
 			Encodings: []RtpEncodingParameters{
@@ -253,17 +286,15 @@ func (suite *ProducerTestingSuite) TestWebRtcTransportProduce_UnsupportedError()
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "CHICKEN",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "CHICKEN"},
 					},
 				},
 				{
 					MimeType:    "video/rtx",
 					PayloadType: 113,
 					ClockRate:   90000,
-					Parameters:  RtpCodecSpecificParameters{Apt: 112},
+					Parameters:  RtpCodecSpecificParameters{{Key: "apt", Value: 112}},
 				},
 			},
 			Encodings: []RtpEncodingParameters{
@@ -311,10 +342,8 @@ func (suite *ProducerTestingSuite) TestWebRtcTransportProduce_WithAlreadyUsedMID
 					PayloadType: 112,
 					ClockRate:   90000,
 					Parameters: RtpCodecSpecificParameters{
-						RtpParameter: h264.RtpParameter{
-							PacketizationMode: 1,
-							ProfileLevelId:    "4d0032",
-						},
+						{Key: "packetization-mode", Value: 1},
+						{Key: "profile-level-id", Value: "4d0032"},
 					},
 				},
 			},
@@ -345,8 +374,8 @@ func (suite *ProducerTestingSuite) TestProduerDump_Succeeds() {
 	suite.EqualValues(48000, data.RtpParameters.Codecs[0].ClockRate)
 	suite.EqualValues(2, data.RtpParameters.Codecs[0].Channels)
 	suite.Equal(RtpCodecSpecificParameters{
-		Useinbandfec: 1,
-		Usedtx:       1,
+		{Key: "useinbandfec", Value: 1},
+		{Key: "usedtx", Value: 1},
 	}, data.RtpParameters.Codecs[0].Parameters)
 	suite.Empty(data.RtpParameters.Codecs[0].RtcpFeedback)
 	suite.Len(data.RtpParameters.HeaderExtensions, 2)
@@ -381,10 +410,8 @@ func (suite *ProducerTestingSuite) TestProduerDump_Succeeds() {
 	suite.EqualValues(90000, data.RtpParameters.Codecs[0].ClockRate)
 	suite.Empty(data.RtpParameters.Codecs[0].Channels)
 	suite.Equal(RtpCodecSpecificParameters{
-		RtpParameter: h264.RtpParameter{
-			PacketizationMode: 1,
-			ProfileLevelId:    "4d0032",
-		},
+		{Key: "packetization-mode", Value: 1},
+		{Key: "profile-level-id", Value: "4d0032"},
 	}, data.RtpParameters.Codecs[0].Parameters)
 	suite.EqualValues([]RtcpFeedback{
 		{Type: "nack"},
@@ -397,7 +424,7 @@ func (suite *ProducerTestingSuite) TestProduerDump_Succeeds() {
 	suite.EqualValues(90000, data.RtpParameters.Codecs[1].ClockRate)
 	suite.Empty(data.RtpParameters.Codecs[1].Channels)
 	suite.Equal(RtpCodecSpecificParameters{
-		Apt: 112,
+		{Key: "apt", Value: 112},
 	}, data.RtpParameters.Codecs[1].Parameters)
 	suite.Empty(data.RtpParameters.Codecs[1].RtcpFeedback)
 	suite.Len(data.RtpParameters.HeaderExtensions, 2)
@@ -469,6 +496,31 @@ func (suite *ProducerTestingSuite) TestProducerEnableTraceEventSucceed() {
 	suite.Zero(data.TraceEventTypes)
 }
 
+func (suite *ProducerTestingSuite) TestProduceWithOptions() {
+	producer, err := suite.transport1.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Mid: "AUDIO",
+			Codecs: []*RtpCodecParameters{
+				{
+					MimeType:    "audio/opus",
+					PayloadType: 111,
+					ClockRate:   48000,
+					Channels:    2,
+				},
+			},
+			Encodings: []RtpEncodingParameters{{Ssrc: 11111111}},
+		},
+	}, WithPaused(true), WithTraceEvents("rtp", "pli"), WithAppData(H{"baz": 3}))
+	suite.NoError(err)
+	suite.True(producer.Paused())
+	suite.Equal(H{"baz": 3}, producer.AppData())
+
+	data, _ := producer.Dump()
+	suite.True(data.Paused)
+	suite.EqualValues("rtp,pli", data.TraceEventTypes)
+}
+
 func (suite *ProducerTestingSuite) TestProducerEmitsScore() {
 	videoProducer := suite.videoProducer()
 	channel := videoProducer.channel
@@ -0,0 +1,99 @@
+package protoo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+/**
+ * Message is a protoo request, response or notification.
+ */
+type Message struct {
+	Request      bool            `json:"request,omitempty"`
+	Response     bool            `json:"response,omitempty"`
+	Notification bool            `json:"notification,omitempty"`
+	Id           uint32          `json:"id,omitempty"`
+	Method       string          `json:"method,omitempty"`
+	Ok           bool            `json:"ok,omitempty"`
+	ErrorCode    int             `json:"errorCode,omitempty"`
+	ErrorReason  string          `json:"errorReason,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+}
+
+/**
+ * Error of a request, sent as an error response.
+ */
+type Error struct {
+	Code   int
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("protoo: error %d: %s", e.Code, e.Reason)
+}
+
+var emptyData = json.RawMessage("{}")
+
+func marshalData(data interface{}) (json.RawMessage, error) {
+	if data == nil {
+		return emptyData, nil
+	}
+	return json.Marshal(data)
+}
+
+func newRequest(id uint32, method string, data interface{}) (msg Message, err error) {
+	msg = Message{Request: true, Id: id, Method: method}
+	msg.Data, err = marshalData(data)
+	return
+}
+
+func newNotification(method string, data interface{}) (msg Message, err error) {
+	msg = Message{Notification: true, Method: method}
+	msg.Data, err = marshalData(data)
+	return
+}
+
+// newResponse returns the response of the request, an error response if err
+// is not nil.
+func newResponse(request Message, data interface{}, err error) Message {
+	msg := Message{Response: true, Id: request.Id}
+
+	if err == nil {
+		msg.Data, err = marshalData(data)
+	}
+	if err != nil {
+		var protooErr *Error
+
+		if errors.As(err, &protooErr) {
+			msg.ErrorCode, msg.ErrorReason = protooErr.Code, protooErr.Reason
+		} else {
+			msg.ErrorCode, msg.ErrorReason = 500, err.Error()
+		}
+		msg.Data = nil
+		return msg
+	}
+
+	msg.Ok = true
+
+	return msg
+}
+
+// parseMessage parses and validates a received message.
+func parseMessage(data []byte) (msg Message, err error) {
+	if err = json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	switch {
+	case msg.Request, msg.Notification:
+		if len(msg.Method) == 0 {
+			err = errors.New("protoo: missing method")
+		}
+	case msg.Response:
+	default:
+		err = errors.New("protoo: invalid message")
+	}
+
+	return
+}
@@ -0,0 +1,72 @@
+package protoo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	msg, err := parseMessage([]byte(`{"request":true,"id":12,"method":"join","data":{"displayName":"foo"}}`))
+	require.NoError(t, err)
+	assert.True(t, msg.Request)
+	assert.EqualValues(t, 12, msg.Id)
+	assert.Equal(t, "join", msg.Method)
+	assert.JSONEq(t, `{"displayName":"foo"}`, string(msg.Data))
+
+	msg, err = parseMessage([]byte(`{"response":true,"id":3,"ok":false,"errorCode":404,"errorReason":"nope"}`))
+	require.NoError(t, err)
+	assert.False(t, msg.Ok)
+	assert.Equal(t, 404, msg.ErrorCode)
+
+	_, err = parseMessage([]byte(`{"request":true,"id":1}`))
+	assert.Error(t, err)
+
+	_, err = parseMessage([]byte(`{"id":1}`))
+	assert.Error(t, err)
+
+	_, err = parseMessage([]byte(`[`))
+	assert.Error(t, err)
+}
+
+func TestNewResponse(t *testing.T) {
+	request := Message{Request: true, Id: 7, Method: "produce"}
+
+	data, _ := json.Marshal(newResponse(request, map[string]string{"id": "p1"}, nil))
+	assert.JSONEq(t, `{"response":true,"id":7,"ok":true,"data":{"id":"p1"}}`, string(data))
+
+	data, _ = json.Marshal(newResponse(request, nil, nil))
+	assert.JSONEq(t, `{"response":true,"id":7,"ok":true,"data":{}}`, string(data))
+
+	data, _ = json.Marshal(newResponse(request, nil, &Error{Code: 403, Reason: "peer not yet joined"}))
+	assert.JSONEq(t, `{"response":true,"id":7,"errorCode":403,"errorReason":"peer not yet joined"}`, string(data))
+
+	data, _ = json.Marshal(newResponse(request, nil, errors.New("boom")))
+	assert.JSONEq(t, `{"response":true,"id":7,"errorCode":500,"errorReason":"boom"}`, string(data))
+}
+
+func TestNewRequestAndNotification(t *testing.T) {
+	msg, err := newRequest(1, "newConsumer", map[string]string{"id": "c1"})
+	require.NoError(t, err)
+	data, _ := json.Marshal(msg)
+	assert.JSONEq(t, `{"request":true,"id":1,"method":"newConsumer","data":{"id":"c1"}}`, string(data))
+
+	msg, err = newNotification("peerClosed", nil)
+	require.NoError(t, err)
+	data, _ = json.Marshal(msg)
+	assert.JSONEq(t, `{"notification":true,"method":"peerClosed","data":{}}`, string(data))
+}
+
+func TestNewServer_InvalidOptions(t *testing.T) {
+	_, err := NewServer(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewServer(Options{
+		NewRouter: func(roomId string) (*mediasoup.Router, error) { return nil, nil },
+	})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
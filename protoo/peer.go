@@ -0,0 +1,220 @@
+package protoo
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Interval of the pings keeping the connection alive.
+const pingInterval = 30 * time.Second
+
+/**
+ * RequestHandler handles a request of the remote peer, returning the data of
+ * the response.
+ */
+type RequestHandler func(method string, data json.RawMessage) (interface{}, error)
+
+/**
+ * Peer is a protoo connection.
+ * @emits notification - (method string, data json.RawMessage)
+ * @emits close
+ */
+type Peer struct {
+	mediasoup.IEventEmitter
+	id          string
+	logger      mediasoup.Logger
+	conn        *websocket.Conn
+	writeLocker sync.Mutex
+	locker      sync.Mutex
+	nextId      uint32
+	sents       map[uint32]chan Message
+	closed      bool
+	closeCh     chan struct{}
+}
+
+func newPeer(id string, conn *websocket.Conn, logger mediasoup.Logger) *Peer {
+	return &Peer{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		id:            id,
+		logger:        logger,
+		conn:          conn,
+		sents:         make(map[uint32]chan Message),
+		closeCh:       make(chan struct{}),
+	}
+}
+
+/**
+ * Peer id.
+ */
+func (p *Peer) Id() string {
+	return p.id
+}
+
+/**
+ * Send a request to the remote peer, returning the data of its response.
+ */
+func (p *Peer) Request(ctx context.Context, method string, data interface{}) (response json.RawMessage, err error) {
+	p.locker.Lock()
+
+	if p.closed {
+		p.locker.Unlock()
+		return nil, mediasoup.NewInvalidStateError("Peer closed")
+	}
+
+	p.nextId++
+	id := p.nextId
+	responseCh := make(chan Message, 1)
+	p.sents[id] = responseCh
+
+	p.locker.Unlock()
+
+	defer func() {
+		p.locker.Lock()
+		delete(p.sents, id)
+		p.locker.Unlock()
+	}()
+
+	msg, err := newRequest(id, method, data)
+	if err != nil {
+		return
+	}
+	if err = p.send(msg); err != nil {
+		return
+	}
+
+	select {
+	case msg := <-responseCh:
+		if !msg.Ok {
+			return nil, &Error{Code: msg.ErrorCode, Reason: msg.ErrorReason}
+		}
+		return msg.Data, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-p.closeCh:
+		return nil, mediasoup.NewInvalidStateError("Peer closed")
+	}
+}
+
+/**
+ * Send a notification to the remote peer.
+ */
+func (p *Peer) Notify(method string, data interface{}) error {
+	msg, err := newNotification(method, data)
+	if err != nil {
+		return err
+	}
+
+	return p.send(msg)
+}
+
+func (p *Peer) send(msg Message) error {
+	p.writeLocker.Lock()
+	defer p.writeLocker.Unlock()
+
+	return p.conn.WriteJSON(msg)
+}
+
+// run reads the messages until the connection is closed, calling the handler
+// for every request.
+func (p *Peer) run(handler RequestHandler) {
+	defer p.Close()
+
+	go p.ping()
+
+	for {
+		_, data, err := p.conn.ReadMessage()
+		if err != nil {
+			p.logger.Debug("run() | connection closed [peerId:%s]: %v", p.id, err)
+			return
+		}
+
+		msg, err := parseMessage(data)
+		if err != nil {
+			p.logger.Warn("run() | invalid message [peerId:%s]: %v", p.id, err)
+			continue
+		}
+
+		switch {
+		case msg.Request:
+			go func() {
+				result, err := handler(msg.Method, msg.Data)
+
+				if err := p.send(newResponse(msg, result, err)); err != nil {
+					p.logger.Warn("run() | sending response failed [peerId:%s]: %v", p.id, err)
+				}
+			}()
+
+		case msg.Response:
+			p.locker.Lock()
+			responseCh := p.sents[msg.Id]
+			p.locker.Unlock()
+
+			if responseCh == nil {
+				p.logger.Warn("run() | response of unknown request [peerId:%s, id:%d]", p.id, msg.Id)
+				continue
+			}
+			responseCh <- msg
+
+		case msg.Notification:
+			p.SafeEmit("notification", msg.Method, msg.Data)
+		}
+	}
+}
+
+func (p *Peer) ping() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.writeLocker.Lock()
+			err := p.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+			p.writeLocker.Unlock()
+
+			if err != nil {
+				return
+			}
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+/**
+ * Whether the connection is closed.
+ */
+func (p *Peer) Closed() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.closed
+}
+
+/**
+ * Close the connection.
+ */
+func (p *Peer) Close() error {
+	p.locker.Lock()
+
+	if p.closed {
+		p.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	p.closed = true
+	close(p.closeCh)
+
+	p.locker.Unlock()
+
+	p.conn.Close()
+	p.SafeEmit("close")
+
+	return nil
+}
@@ -0,0 +1,710 @@
+package protoo
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Timeout of the requests sent to the peers.
+const requestTimeout = 10 * time.Second
+
+// roomPeer is the state of a peer in a room, as in the Room of
+// mediasoup-demo.
+type roomPeer struct {
+	*Peer
+	joined           bool
+	displayName      string
+	device           json.RawMessage
+	rtpCapabilities  *mediasoup.RtpCapabilities
+	sctpCapabilities *mediasoup.SctpCapabilities
+	transports       map[string]*mediasoup.WebRtcTransport
+	// Transports created with "consuming" set.
+	consumingTransports map[string]bool
+	producers           map[string]*mediasoup.Producer
+	consumers           map[string]*mediasoup.Consumer
+	dataProducers       map[string]*mediasoup.DataProducer
+	dataConsumers       map[string]*mediasoup.DataConsumer
+}
+
+type peerInfo struct {
+	Id          string          `json:"id"`
+	DisplayName string          `json:"displayName"`
+	Device      json.RawMessage `json:"device,omitempty"`
+}
+
+func (p *roomPeer) info() peerInfo {
+	return peerInfo{Id: p.id, DisplayName: p.displayName, Device: p.device}
+}
+
+/**
+ * Room is a conference of the protoo peers with the same room id.
+ * @emits close
+ */
+type Room struct {
+	mediasoup.IEventEmitter
+	server *Server
+	logger mediasoup.Logger
+	id     string
+	router *mediasoup.Router
+	locker sync.Mutex
+	peers  map[string]*roomPeer
+	closed bool
+}
+
+func newRoom(server *Server, id string, router *mediasoup.Router) *Room {
+	room := &Room{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		server:        server,
+		logger:        server.logger,
+		id:            id,
+		router:        router,
+		peers:         make(map[string]*roomPeer),
+	}
+
+	router.Observer().On("close", func() {
+		go room.Close()
+	})
+
+	return room
+}
+
+/**
+ * Room id.
+ */
+func (r *Room) Id() string {
+	return r.id
+}
+
+/**
+ * Router of the room.
+ */
+func (r *Room) Router() *mediasoup.Router {
+	return r.router
+}
+
+/**
+ * Close the Router of the room, disconnecting its peers.
+ */
+func (r *Room) Close() error {
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	r.logger.Debug("Close() [roomId:%s]", r.id)
+
+	r.closed = true
+
+	peers := make([]*roomPeer, 0, len(r.peers))
+
+	for _, peer := range r.peers {
+		peers = append(peers, peer)
+	}
+
+	r.locker.Unlock()
+
+	for _, peer := range peers {
+		peer.Close()
+	}
+
+	r.router.Close()
+	r.server.removeRoom(r)
+	r.SafeEmit("close")
+
+	return nil
+}
+
+// addPeer adds the connection to the room, closing the previous one of the
+// same peer.
+func (r *Room) addPeer(peer *Peer) error {
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return mediasoup.NewInvalidStateError("Room closed")
+	}
+
+	previous := r.peers[peer.id]
+	p := &roomPeer{
+		Peer:                peer,
+		transports:          make(map[string]*mediasoup.WebRtcTransport),
+		consumingTransports: make(map[string]bool),
+		producers:           make(map[string]*mediasoup.Producer),
+		consumers:           make(map[string]*mediasoup.Consumer),
+		dataProducers:       make(map[string]*mediasoup.DataProducer),
+		dataConsumers:       make(map[string]*mediasoup.DataConsumer),
+	}
+	r.peers[peer.id] = p
+
+	r.locker.Unlock()
+
+	if previous != nil {
+		r.logger.Warn("addPeer() | peer %q reconnected, closing the previous connection", peer.id)
+		previous.Close()
+	}
+
+	peer.On("close", func() {
+		r.removePeer(p)
+	})
+
+	return nil
+}
+
+func (r *Room) removePeer(p *roomPeer) {
+	r.locker.Lock()
+
+	if r.peers[p.id] == p {
+		delete(r.peers, p.id)
+	}
+	joined := p.joined
+	transports := make([]*mediasoup.WebRtcTransport, 0, len(p.transports))
+
+	for _, transport := range p.transports {
+		transports = append(transports, transport)
+	}
+
+	empty := len(r.peers) == 0
+
+	r.locker.Unlock()
+
+	if joined {
+		for _, other := range r.joinedPeers(p) {
+			other.Notify("peerClosed", map[string]string{"peerId": p.id})
+		}
+	}
+
+	for _, transport := range transports {
+		transport.Close()
+	}
+
+	if empty {
+		r.logger.Debug("removePeer() | last peer left, closing room [roomId:%s]", r.id)
+		r.Close()
+	}
+}
+
+// joinedPeers returns the joined peers other than the given one.
+func (r *Room) joinedPeers(exclude *roomPeer) (peers []*roomPeer) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	for _, peer := range r.peers {
+		if peer.joined && peer != exclude {
+			peers = append(peers, peer)
+		}
+	}
+
+	return
+}
+
+func (r *Room) roomPeer(peer *Peer) (p *roomPeer, err error) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if p = r.peers[peer.id]; p == nil || p.Peer != peer {
+		return nil, mediasoup.NewInvalidStateError("peer not in room")
+	}
+
+	return
+}
+
+// handleRequest handles a request of a peer, like the Room of mediasoup-demo.
+func (r *Room) handleRequest(peer *Peer, method string, data json.RawMessage) (result interface{}, err error) {
+	p, err := r.roomPeer(peer)
+	if err != nil {
+		return
+	}
+
+	switch method {
+	case "getRouterRtpCapabilities":
+		return r.router.RtpCapabilities(), nil
+
+	case "join":
+		return r.join(p, data)
+
+	case "createWebRtcTransport":
+		return r.createWebRtcTransport(p, data)
+
+	case "changeDisplayName":
+		var req struct {
+			DisplayName string `json:"displayName"`
+		}
+		if err = json.Unmarshal(data, &req); err != nil {
+			return
+		}
+
+		r.locker.Lock()
+		oldDisplayName := p.displayName
+		p.displayName = req.DisplayName
+		r.locker.Unlock()
+
+		for _, other := range r.joinedPeers(p) {
+			other.Notify("peerDisplayNameChanged", map[string]string{
+				"peerId":         p.id,
+				"displayName":    req.DisplayName,
+				"oldDisplayName": oldDisplayName,
+			})
+		}
+		return
+	}
+
+	// The other requests target an entity of the peer.
+	var req struct {
+		TransportId    string                          `json:"transportId"`
+		ProducerId     string                          `json:"producerId"`
+		ConsumerId     string                          `json:"consumerId"`
+		DataProducerId string                          `json:"dataProducerId"`
+		DataConsumerId string                          `json:"dataConsumerId"`
+		DtlsParameters *mediasoup.DtlsParameters       `json:"dtlsParameters"`
+		SpatialLayer   uint8                           `json:"spatialLayer"`
+		TemporalLayer  uint8                           `json:"temporalLayer"`
+		Priority       uint32                          `json:"priority"`
+		Kind           mediasoup.MediaKind             `json:"kind"`
+		RtpParameters  mediasoup.RtpParameters         `json:"rtpParameters"`
+		AppData        map[string]interface{}          `json:"appData"`
+		SctpStream     *mediasoup.SctpStreamParameters `json:"sctpStreamParameters"`
+		Label          string                          `json:"label"`
+		Protocol       string                          `json:"protocol"`
+	}
+	if err = json.Unmarshal(data, &req); err != nil {
+		return
+	}
+
+	r.locker.Lock()
+	joined := p.joined
+	transport := p.transports[req.TransportId]
+	producer := p.producers[req.ProducerId]
+	consumer := p.consumers[req.ConsumerId]
+	dataProducer := p.dataProducers[req.DataProducerId]
+	dataConsumer := p.dataConsumers[req.DataConsumerId]
+	r.locker.Unlock()
+
+	switch method {
+	case "connectWebRtcTransport", "restartIce", "getTransportStats":
+		if transport == nil {
+			return nil, &Error{Code: 404, Reason: "transport not found"}
+		}
+	case "produce", "produceData":
+		if !joined {
+			return nil, &Error{Code: 403, Reason: "peer not yet joined"}
+		}
+		if transport == nil {
+			return nil, &Error{Code: 404, Reason: "transport not found"}
+		}
+	case "closeProducer", "pauseProducer", "resumeProducer", "getProducerStats":
+		if producer == nil {
+			return nil, &Error{Code: 404, Reason: "producer not found"}
+		}
+	case "pauseConsumer", "resumeConsumer", "setConsumerPreferredLayers", "setConsumerPriority",
+		"requestConsumerKeyFrame", "getConsumerStats":
+		if consumer == nil {
+			return nil, &Error{Code: 404, Reason: "consumer not found"}
+		}
+	case "getDataProducerStats":
+		if dataProducer == nil {
+			return nil, &Error{Code: 404, Reason: "dataProducer not found"}
+		}
+	case "getDataConsumerStats":
+		if dataConsumer == nil {
+			return nil, &Error{Code: 404, Reason: "dataConsumer not found"}
+		}
+	default:
+		return nil, &Error{Code: 500, Reason: "unknown request.method \"" + method + "\""}
+	}
+
+	switch method {
+	case "connectWebRtcTransport":
+		err = transport.Connect(mediasoup.TransportConnectOptions{DtlsParameters: req.DtlsParameters})
+
+	case "restartIce":
+		result, err = transport.RestartIce()
+
+	case "getTransportStats":
+		result, err = transport.GetStats()
+
+	case "produce":
+		if req.AppData == nil {
+			req.AppData = map[string]interface{}{}
+		}
+		req.AppData["peerId"] = p.id
+
+		if producer, err = transport.Produce(mediasoup.ProducerOptions{
+			Kind:          req.Kind,
+			RtpParameters: req.RtpParameters,
+			AppData:       req.AppData,
+		}); err != nil {
+			return
+		}
+		r.addProducer(p, producer)
+		result = map[string]string{"id": producer.Id()}
+
+	case "produceData":
+		if req.AppData == nil {
+			req.AppData = map[string]interface{}{}
+		}
+		req.AppData["peerId"] = p.id
+
+		if dataProducer, err = transport.ProduceData(mediasoup.DataProducerOptions{
+			SctpStreamParameters: req.SctpStream,
+			Label:                req.Label,
+			Protocol:             req.Protocol,
+			AppData:              req.AppData,
+		}); err != nil {
+			return
+		}
+		r.addDataProducer(p, dataProducer)
+		result = map[string]string{"id": dataProducer.Id()}
+
+	case "closeProducer":
+		err = producer.Close()
+
+	case "pauseProducer":
+		err = producer.Pause()
+
+	case "resumeProducer":
+		err = producer.Resume()
+
+	case "getProducerStats":
+		result, err = producer.GetStats()
+
+	case "pauseConsumer":
+		err = consumer.Pause()
+
+	case "resumeConsumer":
+		err = consumer.Resume()
+
+	case "setConsumerPreferredLayers":
+		err = consumer.SetPreferredLayers(mediasoup.ConsumerLayers{
+			SpatialLayer:  req.SpatialLayer,
+			TemporalLayer: req.TemporalLayer,
+		})
+
+	case "setConsumerPriority":
+		err = consumer.SetPriority(req.Priority)
+
+	case "requestConsumerKeyFrame":
+		err = consumer.RequestKeyFrame()
+
+	case "getConsumerStats":
+		result, err = consumer.GetStats()
+
+	case "getDataProducerStats":
+		result, err = dataProducer.GetStats()
+
+	case "getDataConsumerStats":
+		result, err = dataConsumer.GetStats()
+	}
+
+	return
+}
+
+func (r *Room) join(p *roomPeer, data json.RawMessage) (result interface{}, err error) {
+	var req struct {
+		DisplayName      string                      `json:"displayName"`
+		Device           json.RawMessage             `json:"device"`
+		RtpCapabilities  *mediasoup.RtpCapabilities  `json:"rtpCapabilities"`
+		SctpCapabilities *mediasoup.SctpCapabilities `json:"sctpCapabilities"`
+	}
+	if err = json.Unmarshal(data, &req); err != nil {
+		return
+	}
+	if req.RtpCapabilities != nil {
+		if err = mediasoup.ValidateRtpCapabilities(req.RtpCapabilities); err != nil {
+			return
+		}
+	}
+
+	r.locker.Lock()
+
+	if p.joined {
+		r.locker.Unlock()
+		return nil, &Error{Code: 403, Reason: "peer already joined"}
+	}
+	p.joined = true
+	p.displayName = req.DisplayName
+	p.device = req.Device
+	p.rtpCapabilities = req.RtpCapabilities
+	p.sctpCapabilities = req.SctpCapabilities
+
+	r.locker.Unlock()
+
+	others := r.joinedPeers(p)
+	peers := make([]peerInfo, 0, len(others))
+
+	for _, other := range others {
+		peers = append(peers, other.info())
+	}
+
+	// Consume the other peers once the response is sent.
+	go func() {
+		for _, other := range others {
+			r.locker.Lock()
+			producers := make([]*mediasoup.Producer, 0, len(other.producers))
+			for _, producer := range other.producers {
+				producers = append(producers, producer)
+			}
+			dataProducers := make([]*mediasoup.DataProducer, 0, len(other.dataProducers))
+			for _, dataProducer := range other.dataProducers {
+				dataProducers = append(dataProducers, dataProducer)
+			}
+			r.locker.Unlock()
+
+			for _, producer := range producers {
+				r.createConsumer(p, other, producer)
+			}
+			for _, dataProducer := range dataProducers {
+				r.createDataConsumer(p, other, dataProducer)
+			}
+		}
+
+		for _, other := range others {
+			other.Notify("newPeer", p.info())
+		}
+	}()
+
+	return map[string]interface{}{"peers": peers}, nil
+}
+
+func (r *Room) createWebRtcTransport(p *roomPeer, data json.RawMessage) (result interface{}, err error) {
+	var req struct {
+		ForceTcp         bool                        `json:"forceTcp"`
+		Producing        bool                        `json:"producing"`
+		Consuming        bool                        `json:"consuming"`
+		SctpCapabilities *mediasoup.SctpCapabilities `json:"sctpCapabilities"`
+	}
+	if err = json.Unmarshal(data, &req); err != nil {
+		return
+	}
+
+	options := r.server.options.WebRtcTransportOptions
+	options.AppData = map[string]interface{}{
+		"producing": req.Producing,
+		"consuming": req.Consuming,
+	}
+
+	if req.ForceTcp {
+		options.EnableUdp = mediasoup.Bool(false)
+		options.EnableTcp = true
+	}
+	if req.SctpCapabilities != nil {
+		options.EnableSctp = true
+		options.NumSctpStreams = req.SctpCapabilities.NumStreams
+	}
+
+	transport, err := r.router.CreateWebRtcTransport(options)
+	if err != nil {
+		return
+	}
+
+	r.locker.Lock()
+	p.transports[transport.Id()] = transport
+	p.consumingTransports[transport.Id()] = req.Consuming
+	r.locker.Unlock()
+
+	transport.Observer().On("close", func() {
+		r.locker.Lock()
+		delete(p.transports, transport.Id())
+		delete(p.consumingTransports, transport.Id())
+		r.locker.Unlock()
+	})
+
+	response := map[string]interface{}{
+		"id":             transport.Id(),
+		"iceParameters":  transport.IceParameters(),
+		"iceCandidates":  transport.IceCandidates(),
+		"dtlsParameters": transport.DtlsParameters(),
+	}
+
+	if options.EnableSctp {
+		response["sctpParameters"] = transport.SctpParameters()
+	}
+
+	return response, nil
+}
+
+func (r *Room) addProducer(p *roomPeer, producer *mediasoup.Producer) {
+	r.locker.Lock()
+	p.producers[producer.Id()] = producer
+	r.locker.Unlock()
+
+	producer.Observer().On("close", func() {
+		r.locker.Lock()
+		delete(p.producers, producer.Id())
+		r.locker.Unlock()
+	})
+	producer.On("score", func(score []mediasoup.ProducerScore) {
+		p.Notify("producerScore", map[string]interface{}{
+			"producerId": producer.Id(),
+			"score":      score,
+		})
+	})
+
+	go func() {
+		for _, other := range r.joinedPeers(p) {
+			r.createConsumer(other, p, producer)
+		}
+	}()
+}
+
+func (r *Room) addDataProducer(p *roomPeer, dataProducer *mediasoup.DataProducer) {
+	r.locker.Lock()
+	p.dataProducers[dataProducer.Id()] = dataProducer
+	r.locker.Unlock()
+
+	dataProducer.Observer().On("close", func() {
+		r.locker.Lock()
+		delete(p.dataProducers, dataProducer.Id())
+		r.locker.Unlock()
+	})
+
+	go func() {
+		for _, other := range r.joinedPeers(p) {
+			r.createDataConsumer(other, p, dataProducer)
+		}
+	}()
+}
+
+// consumingTransport returns the transport created by the peer to consume,
+// the locker being held.
+func (p *roomPeer) consumingTransport() *mediasoup.WebRtcTransport {
+	for id, transport := range p.transports {
+		if p.consumingTransports[id] {
+			return transport
+		}
+	}
+	return nil
+}
+
+// createConsumer creates a paused Consumer, announces it to the peer with a
+// "newConsumer" request and resumes it once the peer accepted it.
+func (r *Room) createConsumer(p *roomPeer, producerPeer *roomPeer, producer *mediasoup.Producer) {
+	r.locker.Lock()
+	rtpCapabilities := p.rtpCapabilities
+	transport := p.consumingTransport()
+	r.locker.Unlock()
+
+	if rtpCapabilities == nil || transport == nil || !r.router.CanConsume(producer.Id(), *rtpCapabilities) {
+		return
+	}
+
+	consumer, err := transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: *rtpCapabilities,
+		Paused:          true,
+	})
+	if err != nil {
+		r.logger.Warn("createConsumer() | failed [peerId:%s]: %v", p.id, err)
+		return
+	}
+
+	r.locker.Lock()
+	p.consumers[consumer.Id()] = consumer
+	r.locker.Unlock()
+
+	notify := func(method string) func() {
+		return func() {
+			p.Notify(method, map[string]string{"consumerId": consumer.Id()})
+		}
+	}
+
+	consumer.Observer().On("close", func() {
+		r.locker.Lock()
+		delete(p.consumers, consumer.Id())
+		r.locker.Unlock()
+	})
+	consumer.On("transportclose", notify("consumerClosed"))
+	consumer.On("producerclose", notify("consumerClosed"))
+	consumer.On("producerpause", notify("consumerPaused"))
+	consumer.On("producerresume", notify("consumerResumed"))
+	consumer.On("score", func(score mediasoup.ConsumerScore) {
+		p.Notify("consumerScore", map[string]interface{}{"consumerId": consumer.Id(), "score": score})
+	})
+	consumer.On("layerschange", func(layers mediasoup.ConsumerLayers) {
+		p.Notify("consumerLayersChanged", map[string]interface{}{
+			"consumerId":    consumer.Id(),
+			"spatialLayer":  layers.SpatialLayer,
+			"temporalLayer": layers.TemporalLayer,
+		})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	_, err = p.Request(ctx, "newConsumer", map[string]interface{}{
+		"peerId":         producerPeer.id,
+		"producerId":     producer.Id(),
+		"id":             consumer.Id(),
+		"kind":           consumer.Kind(),
+		"rtpParameters":  consumer.RtpParameters(),
+		"type":           consumer.Type(),
+		"appData":        producer.AppData(),
+		"producerPaused": consumer.ProducerPaused(),
+	})
+	if err != nil {
+		r.logger.Warn("createConsumer() | newConsumer request failed [peerId:%s]: %v", p.id, err)
+		consumer.Close()
+		return
+	}
+
+	if err = consumer.Resume(); err != nil {
+		return
+	}
+
+	p.Notify("consumerScore", map[string]interface{}{"consumerId": consumer.Id(), "score": consumer.Score()})
+}
+
+// createDataConsumer creates a DataConsumer and announces it to the peer with
+// a "newDataConsumer" request.
+func (r *Room) createDataConsumer(p *roomPeer, producerPeer *roomPeer, dataProducer *mediasoup.DataProducer) {
+	r.locker.Lock()
+	sctpCapabilities := p.sctpCapabilities
+	transport := p.consumingTransport()
+	r.locker.Unlock()
+
+	if sctpCapabilities == nil || transport == nil {
+		return
+	}
+
+	dataConsumer, err := transport.ConsumeData(mediasoup.DataConsumerOptions{
+		DataProducerId: dataProducer.Id(),
+	})
+	if err != nil {
+		r.logger.Warn("createDataConsumer() | failed [peerId:%s]: %v", p.id, err)
+		return
+	}
+
+	r.locker.Lock()
+	p.dataConsumers[dataConsumer.Id()] = dataConsumer
+	r.locker.Unlock()
+
+	dataConsumer.Observer().On("close", func() {
+		r.locker.Lock()
+		delete(p.dataConsumers, dataConsumer.Id())
+		r.locker.Unlock()
+	})
+	dataConsumer.On("dataproducerclose", func() {
+		p.Notify("dataConsumerClosed", map[string]string{"dataConsumerId": dataConsumer.Id()})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	_, err = p.Request(ctx, "newDataConsumer", map[string]interface{}{
+		"peerId":               producerPeer.id,
+		"dataProducerId":       dataProducer.Id(),
+		"id":                   dataConsumer.Id(),
+		"sctpStreamParameters": dataConsumer.SctpStreamParameters(),
+		"label":                dataConsumer.Label(),
+		"protocol":             dataConsumer.Protocol(),
+		"appData":              dataProducer.AppData(),
+	})
+	if err != nil {
+		r.logger.Warn("createDataConsumer() | newDataConsumer request failed [peerId:%s]: %v", p.id, err)
+		dataConsumer.Close()
+	}
+}
@@ -0,0 +1,196 @@
+// Package protoo is a WebSocket signaling server speaking the protoo protocol
+// of the mediasoup-demo clients, so that the demo front-end can be used
+// against a mediasoup-go backend:
+//
+//	server, err := protoo.NewServer(protoo.Options{
+//		NewRouter: func(roomId string) (*mediasoup.Router, error) {
+//			return worker.CreateRouter(mediasoup.RouterOptions{MediaCodecs: mediaCodecs})
+//		},
+//		WebRtcTransportOptions: mediasoup.WebRtcTransportOptions{
+//			ListenIps: []mediasoup.TransportListenIp{{Ip: "0.0.0.0", AnnouncedIp: publicIp}},
+//		},
+//	})
+//	http.ListenAndServeTLS(":4443", certFile, keyFile, server)
+//
+// Clients connect with the "roomId" and "peerId" query parameters. A room is
+// created, with its Router, when its first peer connects, and closed when its
+// last peer disconnects. The requests of the demo client are mapped onto the
+// mediasoup-go API (createWebRtcTransport, join, produce, etc.), and the
+// Consumers are announced to the peers with "newConsumer" requests.
+//
+// It is a separate module so that the mediasoup-go module does not depend on
+// the WebSocket library.
+package protoo
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Create the Router of a room.
+	 */
+	NewRouter func(roomId string) (*mediasoup.Router, error)
+
+	/**
+	 * Options of the WebRtcTransports of the peers, ListenIps being required.
+	 */
+	WebRtcTransportOptions mediasoup.WebRtcTransportOptions
+
+	/**
+	 * Whether a WebSocket connection may be accepted. Default the same origin
+	 * policy of gorilla/websocket.
+	 */
+	CheckOrigin func(r *http.Request) bool
+
+	/**
+	 * Logger of the server. Default mediasoup.NewLogger("ProtooServer").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Server is the http.Handler accepting the protoo connections.
+ */
+type Server struct {
+	options  Options
+	logger   mediasoup.Logger
+	upgrader websocket.Upgrader
+	locker   sync.Mutex
+	rooms    map[string]*Room
+	closed   bool
+}
+
+/**
+ * Create a server.
+ */
+func NewServer(options Options) (*Server, error) {
+	if options.NewRouter == nil {
+		return nil, mediasoup.NewTypeError("missing NewRouter")
+	}
+	if len(options.WebRtcTransportOptions.ListenIps) == 0 {
+		return nil, mediasoup.NewTypeError("missing WebRtcTransportOptions.ListenIps")
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("ProtooServer")
+	}
+
+	return &Server{
+		options: options,
+		logger:  options.Logger,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{"protoo"},
+			CheckOrigin:  options.CheckOrigin,
+		},
+		rooms: make(map[string]*Room),
+	}, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	roomId, peerId := r.URL.Query().Get("roomId"), r.URL.Query().Get("peerId")
+
+	if len(roomId) == 0 || len(peerId) == 0 {
+		http.Error(w, "missing roomId or peerId", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Warn("ServeHTTP() | upgrade failed: %v", err)
+		return
+	}
+
+	peer := newPeer(peerId, conn, s.logger)
+
+	// The room may be closing, with its last peer leaving.
+	for attempt := 0; attempt < 2; attempt++ {
+		var room *Room
+
+		if room, err = s.room(roomId); err != nil {
+			break
+		}
+		if err = room.addPeer(peer); err == nil {
+			go peer.run(func(method string, data json.RawMessage) (interface{}, error) {
+				return room.handleRequest(peer, method, data)
+			})
+			return
+		}
+	}
+
+	s.logger.Error("ServeHTTP() | joining room %q failed: %v", roomId, err)
+	conn.Close()
+}
+
+/**
+ * Get the room with the given id, nil if none.
+ */
+func (s *Server) Room(roomId string) *Room {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.rooms[roomId]
+}
+
+// room returns the room, creating it if needed.
+func (s *Server) room(roomId string) (room *Room, err error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if s.closed {
+		return nil, mediasoup.NewInvalidStateError("Server closed")
+	}
+	if room = s.rooms[roomId]; room != nil {
+		return
+	}
+
+	router, err := s.options.NewRouter(roomId)
+	if err != nil {
+		return
+	}
+
+	room = newRoom(s, roomId, router)
+	s.rooms[roomId] = room
+
+	return
+}
+
+func (s *Server) removeRoom(room *Room) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if s.rooms[room.id] == room {
+		delete(s.rooms, room.id)
+	}
+}
+
+/**
+ * Close every room, disconnecting their peers.
+ */
+func (s *Server) Close() error {
+	s.locker.Lock()
+
+	if s.closed {
+		s.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	s.closed = true
+
+	rooms := make([]*Room, 0, len(s.rooms))
+
+	for _, room := range s.rooms {
+		rooms = append(rooms, room)
+	}
+
+	s.locker.Unlock()
+
+	for _, room := range rooms {
+		room.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,423 @@
+package mediasoup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ * PublicIpSource gives the public IP of the host.
+ */
+type PublicIpSource func(ctx context.Context) (ip string, err error)
+
+type PublicIpOptions struct {
+	/**
+	 * Sources tried in order, the first answer being returned. Default STUN
+	 * servers of Google and Cloudflare, then the metadata services of AWS,
+	 * Google Cloud and Azure.
+	 */
+	Sources []PublicIpSource
+
+	/**
+	 * Time given to each source. Default 3 seconds.
+	 */
+	Timeout time.Duration
+
+	/**
+	 * Family of the IP to discover: "ip4", "ip6" or "" (any). The IPs of the
+	 * other family are ignored, and the default Sources are the ones able to
+	 * give an IP of the family.
+	 */
+	Network string
+}
+
+/**
+ * Discover the public IP of the host, e.g. to be used as AnnouncedIp when
+ * running behind NAT or in a container.
+ */
+func DiscoverPublicIp(ctx context.Context, options PublicIpOptions) (ip string, err error) {
+	if len(options.Sources) == 0 {
+		options.Sources = defaultPublicIpSources(options.Network)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 3 * time.Second
+	}
+
+	var errs []string
+
+	for _, source := range options.Sources {
+		sourceCtx, cancel := context.WithTimeout(ctx, options.Timeout)
+		ip, err = source(sourceCtx)
+		cancel()
+
+		if err == nil {
+			if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed == nil || parsed.IsUnspecified() {
+				err = fmt.Errorf("invalid IP %q", ip)
+			} else if !ipInNetwork(parsed, options.Network) {
+				err = fmt.Errorf("IP %s not in %s", parsed, options.Network)
+			} else {
+				return parsed.String(), nil
+			}
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		errs = append(errs, err.Error())
+	}
+
+	return "", fmt.Errorf("public IP not discovered: %s", strings.Join(errs, "; "))
+}
+
+// Time during which a discovered public IP is used by the listen IPs with
+// DiscoverAnnouncedIp.
+const publicIpTtl = 5 * time.Minute
+
+// Public IPs discovered for the listen IPs with DiscoverAnnouncedIp, by
+// network ("ip4" or "ip6").
+var publicIpCache = struct {
+	sync.Mutex
+	discoveries map[string]*publicIpDiscovery
+	// discover discovers the public IP of the network, DiscoverPublicIp() if
+	// nil.
+	discover func(ctx context.Context, network string) (string, error)
+}{
+	discoveries: map[string]*publicIpDiscovery{},
+}
+
+// publicIpDiscovery is a discovery of the public IP, shared by the listen IPs
+// resolved meanwhile.
+type publicIpDiscovery struct {
+	done    chan struct{}
+	ip      string
+	err     error
+	expires time.Time
+}
+
+// valid reports whether the discovery is pending or gave an IP not expired.
+func (d *publicIpDiscovery) valid(now time.Time) bool {
+	select {
+	case <-d.done:
+		return d.err == nil && now.Before(d.expires)
+	default:
+		return true
+	}
+}
+
+// resolveListenIp fills the AnnouncedIp of the listen IP if it is to be
+// discovered, the public IP of its family being discovered at most once per
+// publicIpTtl. The discovery does not stop when the context is done, only the
+// wait for it.
+func resolveListenIp(ctx context.Context, listenIp TransportListenIp) (TransportListenIp, error) {
+	if !listenIp.DiscoverAnnouncedIp || len(listenIp.AnnouncedIp) > 0 {
+		return listenIp, nil
+	}
+
+	network := "ip4"
+
+	if ip := net.ParseIP(listenIp.Ip); ip != nil && ip.To4() == nil {
+		network = "ip6"
+	}
+
+	publicIpCache.Lock()
+	discovery := publicIpCache.discoveries[network]
+
+	if discovery == nil || !discovery.valid(time.Now()) {
+		discovery = &publicIpDiscovery{done: make(chan struct{})}
+		publicIpCache.discoveries[network] = discovery
+
+		discover := publicIpCache.discover
+		if discover == nil {
+			discover = func(ctx context.Context, network string) (string, error) {
+				return DiscoverPublicIp(ctx, PublicIpOptions{Network: network})
+			}
+		}
+
+		go func() {
+			defer close(discovery.done)
+
+			discovery.ip, discovery.err = discover(context.Background(), network)
+			discovery.expires = time.Now().Add(publicIpTtl)
+		}()
+	}
+	publicIpCache.Unlock()
+
+	select {
+	case <-discovery.done:
+	case <-ctx.Done():
+		return listenIp, ctx.Err()
+	}
+
+	if discovery.err != nil {
+		return listenIp, discovery.err
+	}
+	listenIp.AnnouncedIp = discovery.ip
+
+	return listenIp, nil
+}
+
+func resolveListenIps(ctx context.Context, listenIps []TransportListenIp) (resolved []TransportListenIp, err error) {
+	resolved = make([]TransportListenIp, len(listenIps))
+
+	for i, listenIp := range listenIps {
+		if resolved[i], err = resolveListenIp(ctx, listenIp); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// defaultPublicIpSources returns the default sources of the IPs of the network,
+// the metadata services only giving IPv4.
+func defaultPublicIpSources(network string) []PublicIpSource {
+	switch network {
+	case "ip6":
+		return []PublicIpSource{
+			stunPublicIpSource("udp6", "stun.l.google.com:19302"),
+			stunPublicIpSource("udp6", "stun.cloudflare.com:3478"),
+		}
+	case "ip4":
+		return []PublicIpSource{
+			stunPublicIpSource("udp4", "stun.l.google.com:19302"),
+			stunPublicIpSource("udp4", "stun.cloudflare.com:3478"),
+			AwsPublicIpSource,
+			GcpPublicIpSource,
+			AzurePublicIpSource,
+		}
+	default:
+		return []PublicIpSource{
+			StunPublicIpSource("stun.l.google.com:19302"),
+			StunPublicIpSource("stun.cloudflare.com:3478"),
+			AwsPublicIpSource,
+			GcpPublicIpSource,
+			AzurePublicIpSource,
+		}
+	}
+}
+
+// ipInNetwork reports whether the IP is of the family of the network, any
+// family matching "".
+func ipInNetwork(ip net.IP, network string) bool {
+	switch network {
+	case "ip4":
+		return ip.To4() != nil
+	case "ip6":
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+/**
+ * StunPublicIpSource gives the public IP mapped by the given STUN server
+ * ("host:port"), with a Binding request (RFC 5389) over UDP.
+ */
+func StunPublicIpSource(server string) PublicIpSource {
+	return stunPublicIpSource("udp", server)
+}
+
+// stunPublicIpSource is StunPublicIpSource() over the given network ("udp",
+// "udp4" or "udp6").
+func stunPublicIpSource(network, server string) PublicIpSource {
+	return func(ctx context.Context) (ip string, err error) {
+		var dialer net.Dialer
+
+		conn, err := dialer.DialContext(ctx, network, server)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		request := make([]byte, 20)
+		binary.BigEndian.PutUint16(request[0:], 0x0001)
+		binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+
+		if _, err = rand.Read(request[8:20]); err != nil {
+			return
+		}
+
+		response := make([]byte, 1500)
+
+		// Send the request again every 500 ms until the deadline.
+		for ctx.Err() == nil {
+			if _, err = conn.Write(request); err != nil {
+				return
+			}
+			conn.SetReadDeadline(stunDeadline(ctx))
+
+			for {
+				var n int
+
+				if n, err = conn.Read(response); err != nil {
+					if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+						return "", fmt.Errorf("stun %s: %v", server, err)
+					}
+					break
+				}
+				if mapped, ok := parseStunBindingResponse(response[:n], request[8:20]); ok {
+					return mapped.String(), nil
+				}
+			}
+		}
+
+		return "", fmt.Errorf("stun %s: %v", server, err)
+	}
+}
+
+const stunMagicCookie = 0x2112a442
+
+func stunDeadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(500 * time.Millisecond)
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// parseStunBindingResponse returns the address mapped by the Binding success
+// response of the given transaction.
+func parseStunBindingResponse(msg, transactionId []byte) (ip net.IP, ok bool) {
+	if len(msg) < 20 || binary.BigEndian.Uint16(msg) != 0x0101 ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || string(msg[8:20]) != string(transactionId) {
+		return
+	}
+
+	attrs := msg[20:]
+
+	if length := int(binary.BigEndian.Uint16(msg[2:])); length <= len(attrs) {
+		attrs = attrs[:length]
+	}
+
+	for len(attrs) >= 4 {
+		typ, length := binary.BigEndian.Uint16(attrs), int(binary.BigEndian.Uint16(attrs[2:]))
+
+		if 4+length > len(attrs) {
+			return
+		}
+		value := attrs[4 : 4+length]
+
+		switch typ {
+		case 0x0020: // XOR-MAPPED-ADDRESS
+			if mapped := stunAddress(value); mapped != nil {
+				key := msg[4:20]
+
+				for i := range mapped {
+					mapped[i] ^= key[i]
+				}
+				return mapped, true
+			}
+
+		case 0x0001: // MAPPED-ADDRESS
+			if mapped := stunAddress(value); mapped != nil {
+				ip, ok = mapped, true
+			}
+		}
+
+		// Attributes are padded to 4 bytes.
+		if padded := 4 + (length+3)&^3; padded < len(attrs) {
+			attrs = attrs[padded:]
+		} else {
+			break
+		}
+	}
+
+	return
+}
+
+// stunAddress returns a copy of the IP of an address attribute.
+func stunAddress(value []byte) net.IP {
+	if len(value) < 4 {
+		return nil
+	}
+
+	switch value[1] {
+	case 0x01:
+		if len(value) >= 8 {
+			return append(net.IP(nil), value[4:8]...)
+		}
+	case 0x02:
+		if len(value) >= 20 {
+			return append(net.IP(nil), value[4:20]...)
+		}
+	}
+
+	return nil
+}
+
+/**
+ * MetadataPublicIpSource gives the public IP returned as plain text by the
+ * given URL, requested with the given headers.
+ */
+func MetadataPublicIpSource(url string, header http.Header) PublicIpSource {
+	return func(ctx context.Context) (ip string, err error) {
+		return httpGet(ctx, http.MethodGet, url, header)
+	}
+}
+
+/**
+ * GcpPublicIpSource gives the external IP of the Google Cloud instance.
+ */
+var GcpPublicIpSource = MetadataPublicIpSource(
+	"http://metadata.google.internal/computeMetadata/v1/instance/network-interfaces/0/access-configs/0/external-ip",
+	http.Header{"Metadata-Flavor": {"Google"}},
+)
+
+/**
+ * AzurePublicIpSource gives the public IP of the Azure virtual machine.
+ */
+var AzurePublicIpSource = MetadataPublicIpSource(
+	"http://169.254.169.254/metadata/instance/network/interface/0/ipv4/ipAddress/0/publicIpAddress?api-version=2021-02-01&format=text",
+	http.Header{"Metadata": {"true"}},
+)
+
+/**
+ * AwsPublicIpSource gives the public IPv4 of the EC2 instance, with IMDSv2.
+ */
+func AwsPublicIpSource(ctx context.Context) (ip string, err error) {
+	token, err := httpGet(ctx, http.MethodPut, "http://169.254.169.254/latest/api/token",
+		http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"60"}})
+	if err != nil {
+		return
+	}
+
+	return httpGet(ctx, http.MethodGet, "http://169.254.169.254/latest/meta-data/public-ipv4",
+		http.Header{"X-Aws-Ec2-Metadata-Token": {token}})
+}
+
+func httpGet(ctx context.Context, method, url string, header http.Header) (body string, err error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return
+	}
+	req = req.WithContext(ctx)
+
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(url + ": " + resp.Status)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
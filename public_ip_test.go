@@ -0,0 +1,201 @@
+package mediasoup
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stunServer answers the Binding requests with the given mapped address.
+func stunServer(t *testing.T, mapped net.IP, mappedPort int) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 20 {
+				continue
+			}
+
+			ip := mapped.To4()
+			family := byte(0x01)
+			if ip == nil {
+				ip, family = mapped.To16(), 0x02
+			}
+
+			value := make([]byte, 4+len(ip))
+			value[1] = family
+			binary.BigEndian.PutUint16(value[2:], uint16(mappedPort)^0x2112)
+			for i := range ip {
+				value[4+i] = ip[i] ^ buf[4+i]
+			}
+
+			response := make([]byte, 20, 24+len(value))
+			binary.BigEndian.PutUint16(response[0:], 0x0101)
+			binary.BigEndian.PutUint16(response[2:], uint16(4+len(value)))
+			copy(response[4:20], buf[4:20])
+			response = append(response, 0x00, 0x20, 0, byte(len(value)))
+			response = append(response, value...)
+
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestStunPublicIpSource(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ip, err := StunPublicIpSource(stunServer(t, net.ParseIP("203.0.113.7"), 4000))(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip)
+
+	ip, err = StunPublicIpSource(stunServer(t, net.ParseIP("2001:db8::7"), 4000))(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::7", ip)
+}
+
+func TestParseStunBindingResponse(t *testing.T) {
+	transactionId := []byte("0123456789ab")
+	msg := make([]byte, 20)
+	binary.BigEndian.PutUint16(msg[0:], 0x0101)
+	binary.BigEndian.PutUint16(msg[2:], 12)
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], transactionId)
+	// MAPPED-ADDRESS
+	msg = append(msg, 0x00, 0x01, 0x00, 0x08, 0x00, 0x01, 0x0f, 0xa0, 198, 51, 100, 1)
+
+	ip, ok := parseStunBindingResponse(msg, transactionId)
+	assert.True(t, ok)
+	assert.Equal(t, "198.51.100.1", ip.String())
+
+	_, ok = parseStunBindingResponse(msg, []byte("another trid"))
+	assert.False(t, ok)
+
+	_, ok = parseStunBindingResponse(msg[:24], transactionId)
+	assert.False(t, ok)
+}
+
+func TestMetadataPublicIpSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("198.51.100.2\n"))
+	}))
+	defer server.Close()
+
+	ip, err := MetadataPublicIpSource(server.URL, http.Header{"Metadata-Flavor": {"Google"}})(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "198.51.100.2", ip)
+
+	_, err = MetadataPublicIpSource(server.URL, nil)(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDiscoverPublicIp(t *testing.T) {
+	failing := func(ctx context.Context) (string, error) { return "", errors.New("unreachable") }
+	invalid := func(ctx context.Context) (string, error) { return "not an ip", nil }
+	working := func(ctx context.Context) (string, error) { return " 198.51.100.3\n", nil }
+
+	ip, err := DiscoverPublicIp(context.Background(), PublicIpOptions{
+		Sources: []PublicIpSource{failing, invalid, working},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "198.51.100.3", ip)
+
+	_, err = DiscoverPublicIp(context.Background(), PublicIpOptions{
+		Sources: []PublicIpSource{failing, invalid},
+	})
+	assert.EqualError(t, err, `public IP not discovered: unreachable; invalid IP "not an ip"`)
+
+	_, err = DiscoverPublicIp(context.Background(), PublicIpOptions{
+		Sources: []PublicIpSource{working},
+		Network: "ip6",
+	})
+	assert.EqualError(t, err, "public IP not discovered: IP 198.51.100.3 not in ip6")
+}
+
+func TestResolveListenIp(t *testing.T) {
+	var calls int32
+
+	publicIpCache.Lock()
+	publicIpCache.discover = func(ctx context.Context, network string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		if network == "ip6" {
+			return "2001:db8::4", nil
+		}
+		return "198.51.100.4", nil
+	}
+	publicIpCache.Unlock()
+
+	defer func() {
+		publicIpCache.Lock()
+		publicIpCache.discover = nil
+		publicIpCache.discoveries = map[string]*publicIpDiscovery{}
+		publicIpCache.Unlock()
+	}()
+
+	listenIp := TransportListenIp{Ip: "0.0.0.0", AnnouncedIp: "192.0.2.1", DiscoverAnnouncedIp: true}
+
+	resolved, err := resolveListenIp(context.Background(), listenIp)
+	assert.NoError(t, err)
+	assert.Equal(t, listenIp, resolved)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resolved, err := resolveListenIp(context.Background(), TransportListenIp{Ip: "0.0.0.0", DiscoverAnnouncedIp: true})
+			assert.NoError(t, err)
+			assert.Equal(t, "198.51.100.4", resolved.AnnouncedIp)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	resolved, err = resolveListenIp(context.Background(), TransportListenIp{Ip: "::", DiscoverAnnouncedIp: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::4", resolved.AnnouncedIp)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	resolved, err = resolveListenIp(context.Background(), TransportListenIp{Ip: "0.0.0.0"})
+	assert.NoError(t, err)
+	assert.Empty(t, resolved.AnnouncedIp)
+
+	publicIpCache.Lock()
+	publicIpCache.discoveries["ip4"].expires = time.Now()
+	publicIpCache.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = resolveListenIp(ctx, TransportListenIp{Ip: "0.0.0.0", DiscoverAnnouncedIp: true})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,448 @@
+package recording
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// SCTP payload protocol identifiers of WebRTC DataChannel text messages
+// (RFC 8831), the empty one included.
+const (
+	ppidString      = 51
+	ppidStringEmpty = 56
+)
+
+/**
+ * Message sent over a DataChannel.
+ */
+type Message struct {
+	Time time.Time `json:"time"`
+
+	DataProducerId string `json:"dataProducerId"`
+
+	/**
+	 * DataConsumer the message is delivered to, if recorded for a DataConsumer.
+	 */
+	DataConsumerId string `json:"dataConsumerId,omitempty"`
+
+	/**
+	 * Peer of the DataProducer sending the message, or of the DataConsumer
+	 * receiving it if recorded for a DataConsumer.
+	 */
+	PeerId string `json:"peerId,omitempty"`
+
+	Label    string `json:"label,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+
+	/**
+	 * SCTP payload protocol identifier, 51 for text and 53 for binary.
+	 */
+	Ppid int `json:"ppid"`
+
+	Data []byte `json:"data"`
+}
+
+/**
+ * Whether the message is text.
+ */
+func (m Message) IsText() bool {
+	return m.Ppid == ppidString || m.Ppid == ppidStringEmpty
+}
+
+/**
+ * MessageStore persists the recorded messages.
+ */
+type MessageStore interface {
+	/**
+	 * Store the message, the messages of a MessageRecorder being stored one at
+	 * a time.
+	 */
+	StoreMessage(message Message) error
+
+	/**
+	 * Close the store, called when the MessageRecorder is closed.
+	 */
+	Close() error
+}
+
+/**
+ * MessageStoreFunc is a MessageStore storing the messages with a function.
+ */
+type MessageStoreFunc func(message Message) error
+
+func (f MessageStoreFunc) StoreMessage(message Message) error {
+	return f(message)
+}
+
+func (f MessageStoreFunc) Close() error {
+	return nil
+}
+
+/**
+ * NewJSONLinesStore creates a MessageStore writing each message as a line of
+ * JSON, text data being written as a string and binary data in base64 (with a
+ * "binary" field). The writer is closed with the store if it is an io.Closer.
+ */
+func NewJSONLinesStore(w io.Writer) MessageStore {
+	return &jsonLinesStore{w: w, encoder: json.NewEncoder(w)}
+}
+
+type jsonLinesStore struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+func (s *jsonLinesStore) StoreMessage(message Message) error {
+	line := struct {
+		Message
+		Data   interface{} `json:"data"`
+		Binary bool        `json:"binary,omitempty"`
+	}{Message: message, Data: message.Data}
+
+	if message.IsText() && utf8.Valid(message.Data) {
+		line.Data = string(message.Data)
+	} else {
+		line.Binary = true
+	}
+
+	return s.encoder.Encode(line)
+}
+
+func (s *jsonLinesStore) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type MessageRecorderOptions struct {
+	/**
+	 * Router of the DataProducers and DataConsumers.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * DataProducers whose messages are recorded.
+	 */
+	DataProducers []*mediasoup.DataProducer
+
+	/**
+	 * DataConsumers whose messages are recorded, i.e. the messages of their
+	 * DataProducer while they are open, with their id and peer.
+	 */
+	DataConsumers []*mediasoup.DataConsumer
+
+	/**
+	 * Store of the messages.
+	 */
+	Store MessageStore
+
+	/**
+	 * Peer of a DataProducer or DataConsumer, given its AppData. Default the
+	 * "peerId" string of the AppData if it is a map.
+	 */
+	PeerId func(appData interface{}) string
+
+	/**
+	 * Logger of the recorder. Default mediasoup.NewLogger("MessageRecorder").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * MessageRecorder records the messages of DataProducers and DataConsumers into
+ * a MessageStore. The DataProducers are consumed through a DirectTransport,
+ * once whatever the number of recorded entities of a DataProducer.
+ * @emits error - (err error)
+ * @emits close
+ */
+type MessageRecorder struct {
+	mediasoup.IEventEmitter
+	logger    mediasoup.Logger
+	options   MessageRecorderOptions
+	transport *mediasoup.DirectTransport
+	locker    sync.Mutex
+	taps      map[string]*messageTap
+	storing   sync.Mutex
+	messages  int
+	closed    bool
+}
+
+// messageTap consumes a DataProducer for its subscriptions.
+type messageTap struct {
+	consumer      *mediasoup.DataConsumer
+	subscriptions []messageSubscription
+}
+
+// messageSubscription is a recorded DataProducer or DataConsumer, giving the
+// fields of its messages.
+type messageSubscription struct {
+	dataConsumerId string
+	peerId         string
+	label          string
+	protocol       string
+}
+
+/**
+ * Start recording the messages of the given DataProducers and DataConsumers.
+ */
+func NewMessageRecorder(options MessageRecorderOptions) (recorder *MessageRecorder, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Store == nil {
+		return nil, mediasoup.NewTypeError("missing Store")
+	}
+	if options.PeerId == nil {
+		options.PeerId = appDataPeerId
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("MessageRecorder")
+	}
+
+	recorder = &MessageRecorder{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		options:       options,
+		taps:          make(map[string]*messageTap),
+	}
+
+	if recorder.transport, err = options.Router.CreateDirectTransport(); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			recorder.transport.Close()
+			recorder = nil
+		}
+	}()
+
+	for _, dataProducer := range options.DataProducers {
+		if err = recorder.AddDataProducer(dataProducer); err != nil {
+			return
+		}
+	}
+	for _, dataConsumer := range options.DataConsumers {
+		if err = recorder.AddDataConsumer(dataConsumer); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// appDataPeerId returns the "peerId" of the AppData.
+func appDataPeerId(appData interface{}) (peerId string) {
+	switch data := appData.(type) {
+	case map[string]interface{}:
+		peerId, _ = data["peerId"].(string)
+	case mediasoup.H:
+		peerId, _ = data["peerId"].(string)
+	case map[string]string:
+		peerId = data["peerId"]
+	}
+	return
+}
+
+/**
+ * Record the messages of the DataProducer.
+ */
+func (r *MessageRecorder) AddDataProducer(dataProducer *mediasoup.DataProducer) error {
+	return r.subscribe(dataProducer.Id(), messageSubscription{
+		peerId:   r.options.PeerId(dataProducer.AppData()),
+		label:    dataProducer.Label(),
+		protocol: dataProducer.Protocol(),
+	})
+}
+
+/**
+ * Record the messages delivered to the DataConsumer until it is closed.
+ */
+func (r *MessageRecorder) AddDataConsumer(dataConsumer *mediasoup.DataConsumer) (err error) {
+	subscription := messageSubscription{
+		dataConsumerId: dataConsumer.Id(),
+		peerId:         r.options.PeerId(dataConsumer.AppData()),
+		label:          dataConsumer.Label(),
+		protocol:       dataConsumer.Protocol(),
+	}
+
+	if err = r.subscribe(dataConsumer.DataProducerId(), subscription); err != nil {
+		return
+	}
+
+	dataConsumer.Observer().Once("close", func() {
+		r.unsubscribe(dataConsumer.DataProducerId(), dataConsumer.Id())
+	})
+
+	return
+}
+
+func (r *MessageRecorder) subscribe(dataProducerId string, subscription messageSubscription) (err error) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	if r.closed {
+		return mediasoup.NewInvalidStateError("MessageRecorder closed")
+	}
+
+	if tap, ok := r.taps[dataProducerId]; ok {
+		tap.subscriptions = append(tap.subscriptions, subscription)
+		return
+	}
+
+	consumer, err := r.transport.ConsumeData(mediasoup.DataConsumerOptions{
+		DataProducerId: dataProducerId,
+	})
+	if err != nil {
+		return
+	}
+
+	tap := &messageTap{
+		consumer:      consumer,
+		subscriptions: []messageSubscription{subscription},
+	}
+	r.taps[dataProducerId] = tap
+
+	consumer.On("message", func(data []byte, ppid int) {
+		r.handleMessage(dataProducerId, tap, data, ppid)
+	})
+	consumer.Observer().Once("close", func() {
+		r.locker.Lock()
+		defer r.locker.Unlock()
+
+		if r.taps[dataProducerId] == tap {
+			delete(r.taps, dataProducerId)
+		}
+	})
+
+	return
+}
+
+// unsubscribe removes the subscription of the DataConsumer, the DataProducer
+// being no longer consumed if it has no subscription left.
+func (r *MessageRecorder) unsubscribe(dataProducerId, dataConsumerId string) {
+	r.locker.Lock()
+
+	tap, ok := r.taps[dataProducerId]
+	if !ok {
+		r.locker.Unlock()
+		return
+	}
+
+	subscriptions := tap.subscriptions[:0]
+
+	for _, subscription := range tap.subscriptions {
+		if subscription.dataConsumerId != dataConsumerId {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	tap.subscriptions = subscriptions
+
+	if len(subscriptions) == 0 {
+		delete(r.taps, dataProducerId)
+	}
+
+	r.locker.Unlock()
+
+	if len(subscriptions) == 0 {
+		tap.consumer.Close()
+	}
+}
+
+func (r *MessageRecorder) handleMessage(dataProducerId string, tap *messageTap, data []byte, ppid int) {
+	now := time.Now()
+
+	r.locker.Lock()
+	subscriptions := append([]messageSubscription(nil), tap.subscriptions...)
+	r.locker.Unlock()
+
+	var errs []error
+
+	r.storing.Lock()
+	// Emitted once storing is released, so that the listeners can call the
+	// methods of the MessageRecorder.
+	defer func() {
+		r.storing.Unlock()
+
+		for _, err := range errs {
+			r.SafeEmit("error", err)
+		}
+	}()
+
+	if r.Closed() {
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		err := r.options.Store.StoreMessage(Message{
+			Time:           now,
+			DataProducerId: dataProducerId,
+			DataConsumerId: subscription.dataConsumerId,
+			PeerId:         subscription.peerId,
+			Label:          subscription.label,
+			Protocol:       subscription.protocol,
+			Ppid:           ppid,
+			Data:           data,
+		})
+		if err != nil {
+			r.logger.Error("storing message of data producer %s failed: %v", dataProducerId, err)
+			errs = append(errs, err)
+			continue
+		}
+		r.messages++
+	}
+}
+
+/**
+ * Number of messages stored so far.
+ */
+func (r *MessageRecorder) Messages() int {
+	r.storing.Lock()
+	defer r.storing.Unlock()
+
+	return r.messages
+}
+
+/**
+ * Whether the recorder is closed.
+ */
+func (r *MessageRecorder) Closed() bool {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return r.closed
+}
+
+/**
+ * Stop recording and close the store.
+ */
+func (r *MessageRecorder) Close() (err error) {
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	r.logger.Debug("Close()")
+
+	r.closed = true
+	r.taps = make(map[string]*messageTap)
+
+	r.locker.Unlock()
+
+	r.transport.Close()
+
+	r.storing.Lock()
+	err = r.options.Store.Close()
+	r.storing.Unlock()
+
+	r.SafeEmit("close")
+
+	return
+}
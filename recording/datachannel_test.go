@@ -0,0 +1,76 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessageRecorder_InvalidOptions(t *testing.T) {
+	_, err := NewMessageRecorder(MessageRecorderOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewMessageRecorder(MessageRecorderOptions{Router: &mediasoup.Router{}})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestJSONLinesStore(t *testing.T) {
+	var buf bytes.Buffer
+
+	store := NewJSONLinesStore(&buf)
+	at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.NoError(t, store.StoreMessage(Message{
+		Time:           at,
+		DataProducerId: "p1",
+		PeerId:         "alice",
+		Label:          "chat",
+		Ppid:           51,
+		Data:           []byte("hello"),
+	}))
+	assert.NoError(t, store.StoreMessage(Message{
+		Time:           at,
+		DataProducerId: "p1",
+		DataConsumerId: "c1",
+		Ppid:           53,
+		Data:           []byte{1, 2, 3},
+	}))
+	assert.NoError(t, store.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var text, binary map[string]interface{}
+
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &text))
+	assert.Equal(t, "hello", text["data"])
+	assert.Equal(t, "alice", text["peerId"])
+	assert.Equal(t, "chat", text["label"])
+	assert.Equal(t, "2021-01-02T03:04:05Z", text["time"])
+	assert.Nil(t, text["binary"])
+	assert.Nil(t, text["dataConsumerId"])
+
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &binary))
+	assert.Equal(t, "AQID", binary["data"])
+	assert.Equal(t, true, binary["binary"])
+	assert.Equal(t, "c1", binary["dataConsumerId"])
+}
+
+func TestMessage_IsText(t *testing.T) {
+	assert.True(t, Message{Ppid: 51}.IsText())
+	assert.True(t, Message{Ppid: 56}.IsText())
+	assert.False(t, Message{Ppid: 53}.IsText())
+}
+
+func TestAppDataPeerId(t *testing.T) {
+	assert.Equal(t, "alice", appDataPeerId(map[string]interface{}{"peerId": "alice"}))
+	assert.Equal(t, "bob", appDataPeerId(mediasoup.H{"peerId": "bob"}))
+	assert.Equal(t, "carol", appDataPeerId(map[string]string{"peerId": "carol"}))
+	assert.Empty(t, appDataPeerId(nil))
+	assert.Empty(t, appDataPeerId(map[string]interface{}{"peerId": 1}))
+}
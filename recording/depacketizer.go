@@ -0,0 +1,696 @@
+package recording
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+/**
+ * Depacketizer assembles the frames of a stream from its RTP packets.
+ */
+type depacketizer interface {
+	/**
+	 * Push the next RTP packet, returning the frame it completes, if any, and
+	 * whether frames were lost since the previous call, in which case the
+	 * following frames of a video stream cannot be decoded until a keyframe.
+	 */
+	push(pkt rtpPacket) (frame []byte, keyframe bool, lost bool)
+}
+
+/**
+ * Information given by the keyframes of a video stream, needed to write the
+ * headers of the files.
+ */
+type videoInfo struct {
+	width  int
+	height int
+	// H264 SPS and PPS NAL units.
+	sps []byte
+	pps []byte
+}
+
+// opusDepacketizer returns every packet as a frame.
+type opusDepacketizer struct{}
+
+func (opusDepacketizer) push(pkt rtpPacket) (frame []byte, keyframe bool, lost bool) {
+	if len(pkt.payload) == 0 {
+		return
+	}
+	return append([]byte(nil), pkt.payload...), true, false
+}
+
+/**
+ * Payload format of a video codec, unwrapping the frame carried by the RTP
+ * packets.
+ */
+type videoPayload interface {
+	// Whether the payload can start a frame.
+	isStart(payload []byte) bool
+	// Append the payload of a packet to the frame being assembled.
+	append(payload []byte) error
+	// Return the frame assembled, and start a new one.
+	frame() (data []byte, keyframe bool)
+	// Drop the frame being assembled.
+	reset()
+	// Information given by the keyframe.
+	info(keyframe []byte) videoInfo
+}
+
+// videoDepacketizer assembles the frames of a video stream, which end with the
+// packet having the marker bit.
+type videoDepacketizer struct {
+	payload   videoPayload
+	started   bool
+	lastSeq   uint16
+	active    bool
+	broken    bool
+	timestamp uint32
+}
+
+func newVideoDepacketizer(payload videoPayload) *videoDepacketizer {
+	return &videoDepacketizer{payload: payload}
+}
+
+func (d *videoDepacketizer) push(pkt rtpPacket) (frame []byte, keyframe bool, lost bool) {
+	missing := d.started && pkt.sequenceNumber != d.lastSeq+1
+	d.started = true
+	d.lastSeq = pkt.sequenceNumber
+
+	// Padding only packet.
+	if len(pkt.payload) == 0 {
+		lost = missing
+		d.broken = d.broken || missing
+		return
+	}
+
+	if !d.active || pkt.timestamp != d.timestamp {
+		// The previous frame did not end.
+		lost = d.active
+		// Missing packets after a complete frame started this one.
+		d.broken = !d.payload.isStart(pkt.payload) || (missing && !d.active)
+		d.active = true
+		d.timestamp = pkt.timestamp
+		d.payload.reset()
+	} else if missing {
+		d.broken = true
+	}
+
+	if !d.broken && d.payload.append(pkt.payload) != nil {
+		d.broken = true
+	}
+
+	if pkt.marker {
+		d.active = false
+
+		if d.broken {
+			d.payload.reset()
+			lost = true
+			return
+		}
+		frame, keyframe = d.payload.frame()
+	}
+
+	return
+}
+
+func (d *videoDepacketizer) info(keyframe []byte) videoInfo {
+	return d.payload.info(keyframe)
+}
+
+/**
+ * VP8 payload (RFC 7741).
+ */
+type vp8Payload struct {
+	data []byte
+}
+
+// parseVp8Descriptor returns the size of the payload descriptor and whether
+// the packet starts a frame.
+func parseVp8Descriptor(payload []byte) (size int, start bool, err error) {
+	if len(payload) < 1 {
+		err = errors.New("vp8 payload too short")
+		return
+	}
+
+	size = 1
+
+	if payload[0]&0x80 != 0 {
+		if len(payload) <= size {
+			err = errors.New("vp8 payload too short")
+			return
+		}
+		ext := payload[size]
+		size++
+
+		if ext&0x80 != 0 {
+			if len(payload) <= size {
+				err = errors.New("vp8 payload too short")
+				return
+			}
+			if payload[size]&0x80 != 0 {
+				size += 2
+			} else {
+				size++
+			}
+		}
+		if ext&0x40 != 0 {
+			size++
+		}
+		if ext&0x30 != 0 {
+			size++
+		}
+	}
+
+	if len(payload) <= size {
+		err = errors.New("vp8 payload too short")
+		return
+	}
+	start = payload[0]&0x10 != 0 && payload[0]&0x07 == 0
+
+	return
+}
+
+func (p *vp8Payload) isStart(payload []byte) bool {
+	_, start, err := parseVp8Descriptor(payload)
+	return err == nil && start
+}
+
+func (p *vp8Payload) append(payload []byte) error {
+	size, _, err := parseVp8Descriptor(payload)
+	if err != nil {
+		return err
+	}
+	p.data = append(p.data, payload[size:]...)
+
+	return nil
+}
+
+func (p *vp8Payload) frame() (data []byte, keyframe bool) {
+	data, p.data = p.data, nil
+	keyframe = len(data) > 0 && data[0]&0x01 == 0
+
+	return
+}
+
+func (p *vp8Payload) reset() {
+	p.data = nil
+}
+
+func (p *vp8Payload) info(keyframe []byte) (info videoInfo) {
+	// Frame tag (3 bytes), start code (3 bytes), width and height (2 bytes).
+	if len(keyframe) >= 10 && keyframe[3] == 0x9d && keyframe[4] == 0x01 && keyframe[5] == 0x2a {
+		info.width = int(binary.LittleEndian.Uint16(keyframe[6:]) & 0x3fff)
+		info.height = int(binary.LittleEndian.Uint16(keyframe[8:]) & 0x3fff)
+	}
+	return
+}
+
+/**
+ * VP9 payload (draft-ietf-payload-vp9). The frames of the spatial layers of a
+ * picture are written as a superframe.
+ */
+type vp9Payload struct {
+	layers   [][]byte
+	keyframe bool
+	// Resolution of the highest spatial layer, given by the scalability
+	// structure.
+	width  int
+	height int
+}
+
+type vp9Descriptor struct {
+	size          int
+	interPicture  bool
+	start         bool
+	spatialLayer  int
+	width, height int
+}
+
+func parseVp9Descriptor(payload []byte) (desc vp9Descriptor, err error) {
+	tooShort := errors.New("vp9 payload too short")
+
+	if len(payload) < 1 {
+		err = tooShort
+		return
+	}
+
+	b := payload[0]
+	i := 1
+
+	desc.interPicture = b&0x40 != 0
+	desc.start = b&0x08 != 0
+
+	// Picture id.
+	if b&0x80 != 0 {
+		if len(payload) <= i {
+			err = tooShort
+			return
+		}
+		if payload[i]&0x80 != 0 {
+			i += 2
+		} else {
+			i++
+		}
+	}
+	// Layer indices, and TL0PICIDX in non flexible mode.
+	if b&0x20 != 0 {
+		if len(payload) <= i {
+			err = tooShort
+			return
+		}
+		desc.spatialLayer = int(payload[i]>>1) & 0x07
+		i++
+
+		if b&0x10 == 0 {
+			i++
+		}
+	}
+	// Reference indices in flexible mode.
+	if b&0x10 != 0 && desc.interPicture {
+		for n := 0; ; n++ {
+			if len(payload) <= i || n == 3 {
+				err = tooShort
+				return
+			}
+			more := payload[i]&0x01 != 0
+			i++
+
+			if !more {
+				break
+			}
+		}
+	}
+	// Scalability structure.
+	if b&0x02 != 0 {
+		if len(payload) <= i {
+			err = tooShort
+			return
+		}
+		layers := int(payload[i]>>5) + 1
+		hasResolutions := payload[i]&0x10 != 0
+		hasGroup := payload[i]&0x08 != 0
+		i++
+
+		if hasResolutions {
+			if len(payload) < i+4*layers {
+				err = tooShort
+				return
+			}
+			for n := 0; n < layers; n++ {
+				desc.width = int(binary.BigEndian.Uint16(payload[i:]))
+				desc.height = int(binary.BigEndian.Uint16(payload[i+2:]))
+				i += 4
+			}
+		}
+		if hasGroup {
+			if len(payload) <= i {
+				err = tooShort
+				return
+			}
+			pictures := int(payload[i])
+			i++
+
+			for n := 0; n < pictures; n++ {
+				if len(payload) <= i {
+					err = tooShort
+					return
+				}
+				i += 1 + int(payload[i]>>2)&0x03
+			}
+		}
+	}
+
+	if len(payload) <= i {
+		err = tooShort
+		return
+	}
+	desc.size = i
+
+	return
+}
+
+func (p *vp9Payload) isStart(payload []byte) bool {
+	desc, err := parseVp9Descriptor(payload)
+	return err == nil && desc.start
+}
+
+func (p *vp9Payload) append(payload []byte) error {
+	desc, err := parseVp9Descriptor(payload)
+	if err != nil {
+		return err
+	}
+
+	if desc.width > 0 && desc.height > 0 {
+		p.width, p.height = desc.width, desc.height
+	}
+
+	if desc.start {
+		if len(p.layers) == 0 {
+			p.keyframe = !desc.interPicture && desc.spatialLayer == 0
+		}
+		if len(p.layers) == 8 {
+			return errors.New("too many vp9 layer frames")
+		}
+		p.layers = append(p.layers, nil)
+	} else if len(p.layers) == 0 {
+		return errors.New("vp9 frame without start")
+	}
+
+	last := len(p.layers) - 1
+	p.layers[last] = append(p.layers[last], payload[desc.size:]...)
+
+	return nil
+}
+
+func (p *vp9Payload) frame() (data []byte, keyframe bool) {
+	layers, keyframe := p.layers, p.keyframe
+	p.reset()
+
+	if len(layers) == 1 {
+		return layers[0], keyframe
+	}
+
+	// Superframe: the frames followed by the index of their sizes.
+	maxSize := 0
+
+	for _, layer := range layers {
+		data = append(data, layer...)
+
+		if len(layer) > maxSize {
+			maxSize = len(layer)
+		}
+	}
+
+	sizeBytes := 1
+	for maxSize >= 1<<(8*uint(sizeBytes)) {
+		sizeBytes++
+	}
+
+	marker := byte(0xc0 | (sizeBytes-1)<<3 | (len(layers) - 1))
+	data = append(data, marker)
+
+	for _, layer := range layers {
+		for n := 0; n < sizeBytes; n++ {
+			data = append(data, byte(len(layer)>>(8*uint(n))))
+		}
+	}
+	data = append(data, marker)
+
+	return
+}
+
+func (p *vp9Payload) reset() {
+	p.layers = nil
+	p.keyframe = false
+}
+
+func (p *vp9Payload) info(keyframe []byte) (info videoInfo) {
+	if p.width > 0 && p.height > 0 {
+		info.width, info.height = p.width, p.height
+		return
+	}
+
+	info.width, info.height = parseVp9FrameSize(keyframe)
+
+	return
+}
+
+// parseVp9FrameSize parses the size of a keyframe from its uncompressed header.
+func parseVp9FrameSize(frame []byte) (width, height int) {
+	r := &bitReader{data: frame}
+
+	if r.u(2) != 2 {
+		return
+	}
+	profile := r.u(1) | r.u(1)<<1
+
+	if profile == 3 {
+		r.u(1)
+	}
+	// show_existing_frame, frame_type (0 for keyframes)
+	if r.flag() || r.flag() {
+		return
+	}
+	// show_frame, error_resilient_mode
+	r.u(2)
+
+	if r.u(24) != 0x498342 {
+		return
+	}
+	if profile >= 2 {
+		r.u(1)
+	}
+	if colorSpace := r.u(3); colorSpace != 7 {
+		r.u(1)
+
+		if profile == 1 || profile == 3 {
+			r.u(3)
+		}
+	} else if profile == 1 || profile == 3 {
+		r.u(1)
+	}
+
+	width = int(r.u(16)) + 1
+	height = int(r.u(16)) + 1
+
+	if r.err != nil {
+		return 0, 0
+	}
+	return
+}
+
+/**
+ * H264 payload (RFC 6184), written as NAL units prefixed by their 4 bytes
+ * length (AVCC).
+ */
+type h264Payload struct {
+	data     []byte
+	keyframe bool
+	// Offset of the NAL unit being reassembled from FU-A packets, -1 if none.
+	fragmentStart int
+	sps           []byte
+	pps           []byte
+}
+
+func newH264Payload() *h264Payload {
+	return &h264Payload{fragmentStart: -1}
+}
+
+func (p *h264Payload) isStart(payload []byte) bool {
+	// Anything but the continuation of a fragmented NAL unit.
+	return len(payload) > 0 && (payload[0]&0x1f != 28 || (len(payload) > 1 && payload[1]&0x80 != 0))
+}
+
+func (p *h264Payload) append(payload []byte) error {
+	if len(payload) < 1 {
+		return errors.New("h264 payload too short")
+	}
+
+	switch typ := payload[0] & 0x1f; {
+	case typ >= 1 && typ <= 23:
+		p.appendNalUnit(payload)
+
+	case typ == 24:
+		// STAP-A.
+		for i := 1; i < len(payload); {
+			if i+2 > len(payload) {
+				return errors.New("h264 STAP-A too short")
+			}
+			size := int(binary.BigEndian.Uint16(payload[i:]))
+			i += 2
+
+			if size == 0 || i+size > len(payload) {
+				return errors.New("h264 STAP-A too short")
+			}
+			p.appendNalUnit(payload[i : i+size])
+			i += size
+		}
+
+	case typ == 28:
+		// FU-A.
+		if len(payload) < 2 {
+			return errors.New("h264 FU-A too short")
+		}
+		indicator, header := payload[0], payload[1]
+
+		if header&0x80 != 0 {
+			p.fragmentStart = len(p.data)
+			p.data = append(p.data, 0, 0, 0, 0, indicator&0xe0|header&0x1f)
+		} else if p.fragmentStart < 0 {
+			return errors.New("h264 FU-A without start")
+		}
+		p.data = append(p.data, payload[2:]...)
+
+		if header&0x40 != 0 {
+			p.endNalUnit(p.fragmentStart)
+			p.fragmentStart = -1
+		}
+
+	default:
+		return errors.New("unsupported h264 NAL unit type")
+	}
+
+	return nil
+}
+
+func (p *h264Payload) appendNalUnit(nalUnit []byte) {
+	start := len(p.data)
+	p.data = append(p.data, 0, 0, 0, 0)
+	p.data = append(p.data, nalUnit...)
+	p.endNalUnit(start)
+}
+
+func (p *h264Payload) endNalUnit(start int) {
+	nalUnit := p.data[start+4:]
+	binary.BigEndian.PutUint32(p.data[start:], uint32(len(nalUnit)))
+
+	switch nalUnit[0] & 0x1f {
+	case 5:
+		p.keyframe = true
+	case 7:
+		p.sps = append([]byte(nil), nalUnit...)
+	case 8:
+		p.pps = append([]byte(nil), nalUnit...)
+	}
+}
+
+func (p *h264Payload) frame() (data []byte, keyframe bool) {
+	// Drop a NAL unit whose last fragment is missing.
+	if p.fragmentStart >= 0 {
+		p.data = p.data[:p.fragmentStart]
+	}
+	data, keyframe = p.data, p.keyframe && len(p.sps) > 0 && len(p.pps) > 0
+	p.reset()
+
+	return
+}
+
+func (p *h264Payload) reset() {
+	p.data = nil
+	p.keyframe = false
+	p.fragmentStart = -1
+}
+
+func (p *h264Payload) info(keyframe []byte) (info videoInfo) {
+	info.sps, info.pps = p.sps, p.pps
+	info.width, info.height = parseH264Resolution(p.sps)
+
+	return
+}
+
+// parseH264Resolution parses the resolution given by the SPS.
+func parseH264Resolution(sps []byte) (width, height int) {
+	if len(sps) < 4 {
+		return
+	}
+
+	// Remove the emulation prevention bytes.
+	rbsp := make([]byte, 0, len(sps))
+
+	for i := 1; i < len(sps); i++ {
+		if i >= 3 && sps[i] == 0x03 && sps[i-1] == 0 && sps[i-2] == 0 {
+			continue
+		}
+		rbsp = append(rbsp, sps[i])
+	}
+
+	r := &bitReader{data: rbsp}
+	profile := r.u(8)
+	r.u(16) // constraint flags and level
+	r.ue()  // seq_parameter_set_id
+
+	chromaFormat := uint32(1)
+	separateColourPlane := false
+
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		chromaFormat = r.ue()
+
+		if chromaFormat == 3 {
+			separateColourPlane = r.flag()
+		}
+		r.ue()        // bit_depth_luma_minus8
+		r.ue()        // bit_depth_chroma_minus8
+		r.u(1)        // qpprime_y_zero_transform_bypass_flag
+		if r.flag() { // seq_scaling_matrix_present_flag
+			lists := 8
+			if chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if !r.flag() {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				last, next := int32(8), int32(8)
+
+				for j := 0; j < size && r.err == nil; j++ {
+					if next != 0 {
+						next = (last + r.se() + 256) % 256
+					}
+					if next != 0 {
+						last = next
+					}
+				}
+			}
+		}
+	}
+
+	r.ue() // log2_max_frame_num_minus4
+
+	switch r.ue() { // pic_order_cnt_type
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.u(1) // delta_pic_order_always_zero_flag
+		r.se() // offset_for_non_ref_pic
+		r.se() // offset_for_top_to_bottom_field
+		for n := r.ue(); n > 0 && r.err == nil; n-- {
+			r.se()
+		}
+	}
+
+	r.ue() // max_num_ref_frames
+	r.u(1) // gaps_in_frame_num_value_allowed_flag
+	widthInMbs := int(r.ue()) + 1
+	heightInMapUnits := int(r.ue()) + 1
+	frameMbsOnly := r.flag()
+
+	if !frameMbsOnly {
+		r.u(1) // mb_adaptive_frame_field_flag
+	}
+	r.u(1) // direct_8x8_inference_flag
+
+	fieldFactor := 2
+	if frameMbsOnly {
+		fieldFactor = 1
+	}
+
+	width = widthInMbs * 16
+	height = fieldFactor * heightInMapUnits * 16
+
+	if r.flag() { // frame_cropping_flag
+		left, right, top, bottom := int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+		cropX, cropY := 1, fieldFactor
+
+		if !separateColourPlane {
+			switch chromaFormat {
+			case 1:
+				cropX, cropY = 2, 2*fieldFactor
+			case 2:
+				cropX = 2
+			}
+		}
+
+		width -= (left + right) * cropX
+		height -= (top + bottom) * cropY
+	}
+
+	if r.err != nil || width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	return
+}
@@ -0,0 +1,255 @@
+package recording
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pushPacket(d depacketizer, seq uint16, timestamp uint32, marker bool, payload []byte) (frame []byte, keyframe bool, lost bool) {
+	pkt, err := parseRtpPacket(newRtpPacket(seq, timestamp, marker, payload))
+	if err != nil {
+		panic(err)
+	}
+	return d.push(pkt)
+}
+
+// vp8Keyframe returns a keyframe of the given resolution.
+func vp8Keyframe(width, height uint16) []byte {
+	return []byte{
+		0x00, 0x00, 0x00, 0x9d, 0x01, 0x2a,
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+		0xaa, 0xbb,
+	}
+}
+
+func TestVp8Depacketizer(t *testing.T) {
+	d := newVideoDepacketizer(&vp8Payload{})
+	keyframe := vp8Keyframe(640, 480)
+
+	// Extended descriptor with a 15 bits picture id.
+	frame, _, lost := pushPacket(d, 1, 100, false, append([]byte{0x90, 0x80, 0x81, 0x02}, keyframe[:6]...))
+	assert.Nil(t, frame)
+	assert.False(t, lost)
+
+	frame, isKeyframe, lost := pushPacket(d, 2, 100, true, append([]byte{0x00}, keyframe[6:]...))
+	assert.Equal(t, keyframe, frame)
+	assert.True(t, isKeyframe)
+	assert.False(t, lost)
+
+	info := d.info(frame)
+	assert.Equal(t, 640, info.width)
+	assert.Equal(t, 480, info.height)
+
+	frame, isKeyframe, lost = pushPacket(d, 3, 200, true, []byte{0x10, 0x01, 0x02})
+	assert.Equal(t, []byte{0x01, 0x02}, frame)
+	assert.False(t, isKeyframe)
+	assert.False(t, lost)
+}
+
+func TestVp8Depacketizer_Loss(t *testing.T) {
+	d := newVideoDepacketizer(&vp8Payload{})
+
+	pushPacket(d, 1, 100, false, []byte{0x10, 0x01})
+	// The packet 2 ending the frame is lost.
+	frame, _, lost := pushPacket(d, 3, 200, false, []byte{0x10, 0x03})
+	assert.Nil(t, frame)
+	assert.True(t, lost)
+
+	// The packet 5 of the frame is lost.
+	frame, _, lost = pushPacket(d, 6, 200, true, []byte{0x00, 0x04})
+	assert.Nil(t, frame)
+	assert.True(t, lost)
+
+	frame, _, lost = pushPacket(d, 7, 300, true, []byte{0x10, 0x05})
+	assert.Equal(t, []byte{0x05}, frame)
+	assert.False(t, lost)
+
+	// A frame not starting with its first packet.
+	frame, _, lost = pushPacket(d, 8, 400, true, []byte{0x00, 0x06})
+	assert.Nil(t, frame)
+	assert.True(t, lost)
+}
+
+func TestVp9Depacketizer(t *testing.T) {
+	d := newVideoDepacketizer(&vp9Payload{})
+
+	// Keyframe with a scalability structure of 2 spatial layers, each in a
+	// single packet.
+	frame, _, _ := pushPacket(d, 1, 100, false, []byte{
+		0x2a, 0x00, 0x00, // start, layer indices (S0), TL0PICIDX, scalability structure
+		0x30, 0x01, 0x40, 0x00, 0xb4, 0x02, 0x80, 0x01, 0x68, // 2 layers: 320x180 and 640x360
+		0x11, 0x12,
+	})
+	assert.Nil(t, frame)
+
+	frame, keyframe, lost := pushPacket(d, 2, 100, true, []byte{0x28, 0x02, 0x00, 0x21, 0x22, 0x23})
+	require.NotNil(t, frame)
+	assert.True(t, keyframe)
+	assert.False(t, lost)
+
+	// Superframe of 2 frames whose sizes take 1 byte.
+	assert.Equal(t, []byte{0x11, 0x12, 0x21, 0x22, 0x23, 0xc1, 2, 3, 0xc1}, frame)
+
+	info := d.info(frame)
+	assert.Equal(t, 640, info.width)
+	assert.Equal(t, 360, info.height)
+
+	frame, keyframe, _ = pushPacket(d, 3, 200, true, []byte{0x48, 0x31})
+	assert.Equal(t, []byte{0x31}, frame)
+	assert.False(t, keyframe)
+}
+
+func TestParseVp9FrameSize(t *testing.T) {
+	// Profile 0 keyframe, color space BT.601, 1280x720.
+	frame := []byte{0x82, 0x49, 0x83, 0x42, 0x40, 0x4f, 0xf0, 0x2c, 0xf0}
+
+	width, height := parseVp9FrameSize(frame)
+	assert.Equal(t, 1280, width)
+	assert.Equal(t, 720, height)
+
+	width, height = parseVp9FrameSize(frame[:4])
+	assert.Zero(t, width)
+	assert.Zero(t, height)
+}
+
+type bitWriter struct {
+	data []byte
+	bits int
+}
+
+func (w *bitWriter) u(n int, v uint32) {
+	for i := n - 1; i >= 0; i-- {
+		if w.bits%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		w.data[len(w.data)-1] |= byte(v>>uint(i)&0x01) << (7 - uint(w.bits%8))
+		w.bits++
+	}
+}
+
+func (w *bitWriter) ue(v uint32) {
+	zeros := 0
+	for (v+1)>>uint(zeros+1) != 0 {
+		zeros++
+	}
+	w.u(zeros, 0)
+	w.u(zeros+1, v+1)
+}
+
+// h264Sps returns the SPS of a stream of the given resolution, multiple of 16
+// and cropped.
+func h264Sps(profile uint32, widthInMbs, heightInMbs, cropBottom uint32) []byte {
+	w := &bitWriter{}
+	w.u(8, 0x67)
+	w.u(8, profile)
+	w.u(16, 0x001f) // constraints and level
+	w.ue(0)         // seq_parameter_set_id
+
+	if profile == 100 {
+		w.ue(1)   // chroma_format_idc
+		w.ue(0)   // bit_depth_luma_minus8
+		w.ue(0)   // bit_depth_chroma_minus8
+		w.u(1, 0) // qpprime_y_zero_transform_bypass_flag
+		w.u(1, 1) // seq_scaling_matrix_present_flag
+		w.u(1, 1) // first scaling list present
+		for i := 0; i < 16; i++ {
+			w.ue(0) // delta_scale 0
+		}
+		w.u(7, 0) // other lists absent
+	}
+
+	w.ue(0)               // log2_max_frame_num_minus4
+	w.ue(0)               // pic_order_cnt_type
+	w.ue(0)               // log2_max_pic_order_cnt_lsb_minus4
+	w.ue(1)               // max_num_ref_frames
+	w.u(1, 0)             // gaps_in_frame_num_value_allowed_flag
+	w.ue(widthInMbs - 1)  // pic_width_in_mbs_minus1
+	w.ue(heightInMbs - 1) // pic_height_in_map_units_minus1
+	w.u(1, 1)             // frame_mbs_only_flag
+	w.u(1, 1)             // direct_8x8_inference_flag
+
+	if cropBottom > 0 {
+		w.u(1, 1)
+		w.ue(0)
+		w.ue(0)
+		w.ue(0)
+		w.ue(cropBottom)
+	} else {
+		w.u(1, 0)
+	}
+	w.u(1, 0) // vui_parameters_present_flag
+	w.u(1, 1) // rbsp_stop_one_bit
+
+	return w.data
+}
+
+func TestParseH264Resolution(t *testing.T) {
+	width, height := parseH264Resolution(h264Sps(66, 80, 45, 0))
+	assert.Equal(t, 1280, width)
+	assert.Equal(t, 720, height)
+
+	width, height = parseH264Resolution(h264Sps(100, 120, 68, 4))
+	assert.Equal(t, 1920, width)
+	assert.Equal(t, 1080, height)
+
+	width, height = parseH264Resolution([]byte{0x67, 0x42})
+	assert.Zero(t, width)
+	assert.Zero(t, height)
+}
+
+func TestH264Depacketizer(t *testing.T) {
+	d := newVideoDepacketizer(newH264Payload())
+	sps := h264Sps(66, 40, 30, 0)
+	pps := []byte{0x68, 0xce, 0x3c, 0x80}
+
+	// STAP-A with the SPS and the PPS.
+	stapA := []byte{0x18, 0x00, byte(len(sps))}
+	stapA = append(stapA, sps...)
+	stapA = append(stapA, 0x00, byte(len(pps)))
+	stapA = append(stapA, pps...)
+
+	frame, _, _ := pushPacket(d, 1, 100, false, stapA)
+	assert.Nil(t, frame)
+
+	// IDR in 2 FU-A packets.
+	pushPacket(d, 2, 100, false, []byte{0x7c, 0x85, 0x01, 0x02})
+	frame, keyframe, lost := pushPacket(d, 3, 100, true, []byte{0x7c, 0x45, 0x03})
+	require.NotNil(t, frame)
+	assert.True(t, keyframe)
+	assert.False(t, lost)
+
+	expected := []byte{0, 0, 0, byte(len(sps))}
+	expected = append(expected, sps...)
+	expected = append(expected, 0, 0, 0, byte(len(pps)))
+	expected = append(expected, pps...)
+	expected = append(expected, 0, 0, 0, 4, 0x65, 0x01, 0x02, 0x03)
+	assert.Equal(t, expected, frame)
+
+	info := d.info(frame)
+	assert.Equal(t, 640, info.width)
+	assert.Equal(t, 480, info.height)
+	assert.Equal(t, sps, info.sps)
+	assert.Equal(t, pps, info.pps)
+
+	// Non IDR slice.
+	frame, keyframe, _ = pushPacket(d, 4, 200, true, []byte{0x41, 0x09})
+	assert.Equal(t, []byte{0, 0, 0, 2, 0x41, 0x09}, frame)
+	assert.False(t, keyframe)
+
+	// Continuation of a FU-A whose start is lost.
+	frame, _, lost = pushPacket(d, 6, 300, true, []byte{0x7c, 0x41, 0x03})
+	assert.Nil(t, frame)
+	assert.True(t, lost)
+}
+
+func TestOpusDepacketizer(t *testing.T) {
+	frame, keyframe, lost := pushPacket(opusDepacketizer{}, 1, 960, true, []byte{0xfc, 0x01})
+	assert.Equal(t, []byte{0xfc, 0x01}, frame)
+	assert.True(t, keyframe)
+	assert.False(t, lost)
+
+	frame, _, _ = pushPacket(opusDepacketizer{}, 2, 1920, true, nil)
+	assert.Nil(t, frame)
+}
@@ -0,0 +1,413 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+	"time"
+)
+
+// Longest duration of a fragment of a file without video.
+const maxFragmentDuration = 2 * time.Second
+
+// Sample flags of the keyframes and of the other samples.
+const (
+	syncSampleFlags    = 0x02000000
+	nonSyncSampleFlags = 0x01010000
+)
+
+func mp4Box(typ string, data ...[]byte) []byte {
+	size := 8
+	for _, d := range data {
+		size += len(d)
+	}
+
+	box := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(box, uint32(size))
+	copy(box[4:], typ)
+
+	for _, d := range data {
+		box = append(box, d...)
+	}
+
+	return box
+}
+
+func mp4FullBox(typ string, version uint8, flags uint32, data ...[]byte) []byte {
+	header := []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+
+	return mp4Box(typ, append([][]byte{header}, data...)...)
+}
+
+func u16(v uint16) []byte {
+	return []byte{byte(v >> 8), byte(v)}
+}
+
+func u32(v uint32) []byte {
+	return []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func u64(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return buf
+}
+
+// Unity transformation matrix of mvhd and tkhd.
+var mp4Matrix = []byte{
+	0x00, 0x01, 0x00, 0x00, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0x00, 0x01, 0x00, 0x00, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0x40, 0x00, 0x00, 0x00,
+}
+
+type mp4Sample struct {
+	data     []byte
+	dts      uint64
+	duration uint32
+	keyframe bool
+}
+
+type mp4Track struct {
+	id        uint32
+	timescale uint32
+	// Last sample written, whose duration is given by the next one.
+	pending *mp4Sample
+	// Samples of the fragment being built.
+	samples      []mp4Sample
+	lastDuration uint32
+}
+
+/**
+ * Fragmented MP4 muxer, writing a fragment every video keyframe, or every 2
+ * seconds if there is no video.
+ */
+type mp4Muxer struct {
+	file          *os.File
+	writer        *bufio.Writer
+	written       int64
+	buffered      int64
+	tracks        map[*track]*mp4Track
+	order         []*mp4Track
+	hasVideo      bool
+	sequence      uint32
+	fragmentStart time.Duration
+	fragmentOpen  bool
+}
+
+func newMp4Muxer(file *os.File, tracks []*track) (m *mp4Muxer, err error) {
+	m = &mp4Muxer{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		tracks: make(map[*track]*mp4Track, len(tracks)),
+	}
+
+	compatibleBrands := []byte("isomiso6mp41")
+	var traks, trexs [][]byte
+
+	for i, t := range tracks {
+		mt := &mp4Track{id: uint32(i + 1), timescale: uint32(t.clockRate)}
+		m.tracks[t] = mt
+		m.order = append(m.order, mt)
+
+		var sampleEntry []byte
+
+		switch t.mimeType {
+		case "video/h264":
+			compatibleBrands = append(compatibleBrands, "avc1"...)
+			sampleEntry = visualSampleEntry("avc1", t.info, mp4Box("avcC", avcConfiguration(t.info)))
+		case "video/vp8":
+			sampleEntry = visualSampleEntry("vp08", t.info, vpcConfiguration())
+		case "video/vp9":
+			sampleEntry = visualSampleEntry("vp09", t.info, vpcConfiguration())
+		case "audio/opus":
+			sampleEntry = opusSampleEntry(t.channels, uint32(t.clockRate))
+		default:
+			err = unsupportedCodecError(t.mimeType, MP4)
+			return
+		}
+
+		if t.kind == "video" {
+			m.hasVideo = true
+		}
+
+		traks = append(traks, mp4Trak(mt, t, sampleEntry))
+		trexs = append(trexs, mp4FullBox("trex", 0, 0,
+			u32(mt.id), u32(1), u32(0), u32(0), u32(0),
+		))
+	}
+
+	ftyp := mp4Box("ftyp", []byte("isom"), u32(0x200), compatibleBrands)
+
+	mvhd := mp4FullBox("mvhd", 0, 0,
+		u32(0), u32(0), // creation and modification times
+		u32(1000), u32(0), // timescale and duration
+		u32(0x00010000), u16(0x0100), make([]byte, 10), // rate, volume, reserved
+		mp4Matrix,
+		make([]byte, 24), // pre_defined
+		u32(uint32(len(tracks)+1)),
+	)
+
+	moov := mp4Box("moov", append([][]byte{mvhd}, append(traks, mp4Box("mvex", trexs...))...)...)
+
+	err = m.write(append(ftyp, moov...))
+
+	return
+}
+
+func mp4Trak(mt *mp4Track, t *track, sampleEntry []byte) []byte {
+	var (
+		volume        uint16
+		handler       string
+		mediaHeader   []byte
+		width, height uint32
+	)
+
+	if t.kind == "video" {
+		handler = "vide"
+		mediaHeader = mp4FullBox("vmhd", 0, 1, make([]byte, 8))
+		width, height = uint32(t.info.width), uint32(t.info.height)
+	} else {
+		volume = 0x0100
+		handler = "soun"
+		mediaHeader = mp4FullBox("smhd", 0, 0, make([]byte, 4))
+	}
+
+	tkhd := mp4FullBox("tkhd", 0, 3,
+		u32(0), u32(0), // creation and modification times
+		u32(mt.id), u32(0), u32(0), // track id, reserved, duration
+		make([]byte, 8), u16(0), u16(0), u16(volume), u16(0),
+		mp4Matrix,
+		u32(width<<16), u32(height<<16),
+	)
+
+	mdhd := mp4FullBox("mdhd", 0, 0,
+		u32(0), u32(0), u32(mt.timescale), u32(0),
+		u16(0x55c4), u16(0), // language "und"
+	)
+
+	hdlr := mp4FullBox("hdlr", 0, 0,
+		u32(0), []byte(handler), make([]byte, 12), []byte("mediasoup-go\x00"),
+	)
+
+	dinf := mp4Box("dinf", mp4FullBox("dref", 0, 0, u32(1), mp4FullBox("url ", 0, 1)))
+
+	stbl := mp4Box("stbl",
+		mp4FullBox("stsd", 0, 0, u32(1), sampleEntry),
+		mp4FullBox("stts", 0, 0, u32(0)),
+		mp4FullBox("stsc", 0, 0, u32(0)),
+		mp4FullBox("stsz", 0, 0, u32(0), u32(0)),
+		mp4FullBox("stco", 0, 0, u32(0)),
+	)
+
+	return mp4Box("trak", tkhd,
+		mp4Box("mdia", mdhd, hdlr, mp4Box("minf", mediaHeader, dinf, stbl)),
+	)
+}
+
+func visualSampleEntry(typ string, info videoInfo, config []byte) []byte {
+	return mp4Box(typ,
+		make([]byte, 6), u16(1), // reserved, data reference index
+		make([]byte, 16), // pre_defined and reserved
+		u16(uint16(info.width)), u16(uint16(info.height)),
+		u32(0x00480000), u32(0x00480000), // 72 dpi
+		u32(0), u16(1), // reserved, frame count
+		make([]byte, 32),         // compressor name
+		u16(0x0018), u16(0xffff), // depth, pre_defined
+		config,
+	)
+}
+
+// avcConfiguration returns the AVCDecoderConfigurationRecord (ISO 14496-15).
+func avcConfiguration(info videoInfo) []byte {
+	config := []byte{1, 0x42, 0, 0x1f}
+	if len(info.sps) >= 4 {
+		copy(config[1:], info.sps[1:4])
+	}
+
+	config = append(config, 0xff, 0xe1)
+	config = append(config, u16(uint16(len(info.sps)))...)
+	config = append(config, info.sps...)
+	config = append(config, 1)
+	config = append(config, u16(uint16(len(info.pps)))...)
+	config = append(config, info.pps...)
+
+	return config
+}
+
+// vpcConfiguration returns the VP codec configuration box of 8 bits 4:2:0
+// BT.709 streams.
+func vpcConfiguration() []byte {
+	return mp4FullBox("vpcC", 1, 0,
+		[]byte{0, 0, 8<<4 | 1<<1, 1, 1, 1},
+		u16(0), // codec initialization data size
+	)
+}
+
+func opusSampleEntry(channels uint8, sampleRate uint32) []byte {
+	dOps := mp4Box("dOps",
+		[]byte{0, channels},
+		u16(0), u32(sampleRate), u16(0), // pre-skip, input sample rate, gain
+		[]byte{0}, // channel mapping family
+	)
+
+	return mp4Box("Opus",
+		make([]byte, 6), u16(1), // reserved, data reference index
+		make([]byte, 8),                // reserved
+		u16(uint16(channels)), u16(16), // channel count, sample size
+		u16(0), u16(0), // pre_defined, reserved
+		u32(sampleRate<<16),
+		dOps,
+	)
+}
+
+func (m *mp4Muxer) write(data []byte) error {
+	n, err := m.writer.Write(data)
+	m.written += int64(n)
+
+	return err
+}
+
+func (m *mp4Muxer) writeFrame(t *track, f frame) (err error) {
+	mt, ok := m.tracks[t]
+	if !ok || f.timestamp < 0 {
+		return nil
+	}
+
+	timestamp := uint64(f.timestamp)
+	second := uint64(time.Second)
+	dts := timestamp/second*uint64(mt.timescale) + timestamp%second*uint64(mt.timescale)/second
+
+	// The previous sample ends with this one.
+	if pending := mt.pending; pending != nil {
+		if dts <= pending.dts {
+			dts = pending.dts + 1
+		}
+		pending.duration = uint32(dts - pending.dts)
+		mt.lastDuration = pending.duration
+		mt.samples = append(mt.samples, *pending)
+		mt.pending = nil
+	}
+
+	if m.fragmentOpen &&
+		((t.kind == "video" && f.keyframe) ||
+			(!m.hasVideo && f.timestamp-m.fragmentStart >= maxFragmentDuration)) {
+		if err = m.flushFragment(); err != nil {
+			return
+		}
+	}
+	if !m.fragmentOpen {
+		m.fragmentOpen = true
+		m.fragmentStart = f.timestamp
+	}
+
+	mt.pending = &mp4Sample{data: f.data, dts: dts, keyframe: f.keyframe}
+	m.buffered += int64(len(f.data))
+
+	return
+}
+
+func (m *mp4Muxer) flushFragment() error {
+	m.fragmentOpen = false
+	m.sequence++
+
+	var (
+		trafs   [][]byte
+		samples [][]byte
+		// Size of the moof box, to which the data offsets are relative.
+		moofSize = 8 + 16
+		dataSize = 0
+	)
+
+	for _, mt := range m.order {
+		if len(mt.samples) > 0 {
+			moofSize += 8 + 16 + 20 + 20 + 12*len(mt.samples)
+		}
+	}
+
+	for _, mt := range m.order {
+		if len(mt.samples) == 0 {
+			continue
+		}
+
+		entries := make([]byte, 0, 12*len(mt.samples))
+
+		for _, sample := range mt.samples {
+			flags := uint32(nonSyncSampleFlags)
+			if sample.keyframe {
+				flags = syncSampleFlags
+			}
+			entries = append(entries, u32(sample.duration)...)
+			entries = append(entries, u32(uint32(len(sample.data)))...)
+			entries = append(entries, u32(flags)...)
+			samples = append(samples, sample.data)
+		}
+
+		trafs = append(trafs, mp4Box("traf",
+			mp4FullBox("tfhd", 0, 0x020000, u32(mt.id)),
+			mp4FullBox("tfdt", 1, 0, u64(mt.samples[0].dts)),
+			mp4FullBox("trun", 0, 0x000701,
+				u32(uint32(len(mt.samples))),
+				u32(uint32(moofSize+8+dataSize)),
+				entries,
+			),
+		))
+
+		for _, sample := range mt.samples {
+			dataSize += len(sample.data)
+			m.buffered -= int64(len(sample.data))
+		}
+		mt.samples = nil
+	}
+
+	if len(trafs) == 0 {
+		return nil
+	}
+
+	moof := mp4Box("moof", append([][]byte{mp4FullBox("mfhd", 0, 0, u32(m.sequence))}, trafs...)...)
+
+	if err := m.write(moof); err != nil {
+		return err
+	}
+
+	return m.write(mp4Box("mdat", samples...))
+}
+
+func (m *mp4Muxer) size() int64 {
+	return m.written + m.buffered
+}
+
+func (m *mp4Muxer) close() (err error) {
+	defer func() {
+		if closeErr := m.file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for t, mt := range m.tracks {
+		if mt.pending == nil {
+			continue
+		}
+		duration := mt.lastDuration
+		if duration == 0 {
+			duration = uint32(defaultFrameDuration(t) * time.Duration(mt.timescale) / time.Second)
+		}
+		mt.pending.duration = duration
+		mt.samples = append(mt.samples, *mt.pending)
+		mt.pending = nil
+	}
+
+	if err = m.flushFragment(); err != nil {
+		return
+	}
+
+	return m.writer.Flush()
+}
+
+// defaultFrameDuration returns the duration of the last frame of a track
+// having a single one.
+func defaultFrameDuration(t *track) time.Duration {
+	if t.kind == "video" {
+		return time.Second / 30
+	}
+	return 20 * time.Millisecond
+}
@@ -0,0 +1,245 @@
+package recording
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracks() (video, audio *track) {
+	video = &track{
+		kind:      "video",
+		mimeType:  "video/vp8",
+		clockRate: 90000,
+		info:      videoInfo{width: 640, height: 480},
+	}
+	audio = &track{
+		kind:      "audio",
+		mimeType:  "audio/opus",
+		clockRate: 48000,
+		channels:  2,
+	}
+	return
+}
+
+func createTestFile(t *testing.T, name string) *os.File {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	file, err := os.Create(filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestEbmlSize(t *testing.T) {
+	assert.Equal(t, []byte{0x81}, ebmlSize(1))
+	assert.Equal(t, []byte{0x40, 0x7f}, ebmlSize(127))
+	assert.Equal(t, []byte{0x40, 0x80}, ebmlSize(128))
+	assert.Equal(t, []byte{0x20, 0x40, 0x00}, ebmlSize(0x4000))
+}
+
+// readEbmlElement returns the id, the data and the size of the element
+// starting the buffer.
+func readEbmlElement(t *testing.T, buf []byte) (id uint32, data []byte, size int) {
+	length := 1
+	for buf[0]&(0x80>>uint(length-1)) == 0 {
+		length++
+	}
+	for i := 0; i < length; i++ {
+		id = id<<8 | uint32(buf[i])
+	}
+
+	sizeLength := 1
+	for buf[length]&(0x80>>uint(sizeLength-1)) == 0 {
+		sizeLength++
+	}
+	dataSize := uint64(buf[length] & (0xff >> uint(sizeLength)))
+	for i := 1; i < sizeLength; i++ {
+		dataSize = dataSize<<8 | uint64(buf[length+i])
+	}
+
+	start := length + sizeLength
+	require.True(t, start+int(dataSize) <= len(buf), "element 0x%x overflows", id)
+
+	return id, buf[start : start+int(dataSize)], start + int(dataSize)
+}
+
+func readEbmlElements(t *testing.T, buf []byte) (ids []uint32, elements map[uint32][][]byte) {
+	elements = make(map[uint32][][]byte)
+
+	for len(buf) > 0 {
+		id, data, size := readEbmlElement(t, buf)
+		ids = append(ids, id)
+		elements[id] = append(elements[id], data)
+		buf = buf[size:]
+	}
+	return
+}
+
+func TestWebmMuxer(t *testing.T) {
+	video, audio := newTestTracks()
+	file := createTestFile(t, "test.webm")
+
+	m, err := newWebmMuxer(file, []*track{video, audio})
+	require.NoError(t, err)
+
+	require.NoError(t, m.writeFrame(video, frame{timestamp: 0, data: []byte{1, 2, 3}, keyframe: true}))
+	require.NoError(t, m.writeFrame(audio, frame{timestamp: 10 * time.Millisecond, data: []byte{4}, keyframe: true}))
+	require.NoError(t, m.writeFrame(video, frame{timestamp: 33 * time.Millisecond, data: []byte{5, 6}}))
+	// A new cluster after 5 seconds.
+	require.NoError(t, m.writeFrame(audio, frame{timestamp: 6 * time.Second, data: []byte{7}, keyframe: true}))
+	assert.True(t, m.size() > 0)
+	require.NoError(t, m.close())
+
+	data, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+
+	ids, elements := readEbmlElements(t, data)
+	require.Equal(t, []uint32{ebmlHeaderId, segmentId}, ids)
+
+	_, header := readEbmlElements(t, elements[ebmlHeaderId][0])
+	assert.Equal(t, "webm", string(header[docTypeId][0]))
+
+	ids, segment := readEbmlElements(t, elements[segmentId][0])
+	assert.Equal(t, []uint32{infoId, tracksId, clusterId, clusterId}, ids)
+
+	_, info := readEbmlElements(t, segment[infoId][0])
+	assert.Equal(t, 6000.0, math.Float64frombits(binary.BigEndian.Uint64(info[durationId][0])))
+
+	_, tracks := readEbmlElements(t, segment[tracksId][0])
+	require.Len(t, tracks[trackEntryId], 2)
+	_, videoEntry := readEbmlElements(t, tracks[trackEntryId][0])
+	assert.Equal(t, "V_VP8", string(videoEntry[codecIdId][0]))
+	_, audioEntry := readEbmlElements(t, tracks[trackEntryId][1])
+	assert.Equal(t, "A_OPUS", string(audioEntry[codecIdId][0]))
+	assert.Equal(t, opusHead(2), audioEntry[codecPrivateId][0])
+
+	_, cluster := readEbmlElements(t, segment[clusterId][0])
+	assert.Equal(t, []byte{0}, cluster[timecodeId][0])
+	assert.Equal(t, [][]byte{
+		{0x81, 0, 0, 0x80, 1, 2, 3},
+		{0x82, 0, 10, 0x80, 4},
+		{0x81, 0, 33, 0, 5, 6},
+	}, cluster[simpleBlockId])
+
+	_, cluster = readEbmlElements(t, segment[clusterId][1])
+	assert.Equal(t, []byte{0x17, 0x70}, cluster[timecodeId][0])
+}
+
+func TestWebmMuxer_UnsupportedCodec(t *testing.T) {
+	video, _ := newTestTracks()
+	video.mimeType = "video/h264"
+
+	_, err := newWebmMuxer(createTestFile(t, "test.webm"), []*track{video})
+	assert.Error(t, err)
+}
+
+type mp4TestBox struct {
+	typ  string
+	data []byte
+}
+
+func readMp4Boxes(t *testing.T, buf []byte) (boxes []mp4TestBox) {
+	for len(buf) > 0 {
+		require.True(t, len(buf) >= 8)
+		size := int(binary.BigEndian.Uint32(buf))
+		require.True(t, size >= 8 && size <= len(buf), "invalid box size %d", size)
+
+		boxes = append(boxes, mp4TestBox{typ: string(buf[4:8]), data: buf[8:size]})
+		buf = buf[size:]
+	}
+	return
+}
+
+func mp4BoxTypes(boxes []mp4TestBox) (types []string) {
+	for _, box := range boxes {
+		types = append(types, box.typ)
+	}
+	return
+}
+
+func TestMp4Muxer(t *testing.T) {
+	video, audio := newTestTracks()
+	file := createTestFile(t, "test.mp4")
+
+	m, err := newMp4Muxer(file, []*track{video, audio})
+	require.NoError(t, err)
+
+	require.NoError(t, m.writeFrame(video, frame{timestamp: 0, data: []byte{1, 2, 3}, keyframe: true}))
+	require.NoError(t, m.writeFrame(audio, frame{timestamp: 0, data: []byte{4}, keyframe: true}))
+	require.NoError(t, m.writeFrame(audio, frame{timestamp: 20 * time.Millisecond, data: []byte{5}, keyframe: true}))
+	require.NoError(t, m.writeFrame(video, frame{timestamp: 100 * time.Millisecond, data: []byte{6, 7}}))
+	// A new fragment.
+	require.NoError(t, m.writeFrame(video, frame{timestamp: 200 * time.Millisecond, data: []byte{8}, keyframe: true}))
+	require.NoError(t, m.close())
+
+	data, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+
+	boxes := readMp4Boxes(t, data)
+	require.Equal(t, []string{"ftyp", "moov", "moof", "mdat", "moof", "mdat"}, mp4BoxTypes(boxes))
+	assert.Equal(t, "isom", string(boxes[0].data[:4]))
+
+	moov := readMp4Boxes(t, boxes[1].data)
+	assert.Equal(t, []string{"mvhd", "trak", "trak", "mvex"}, mp4BoxTypes(moov))
+
+	// First fragment: 2 video samples and the first audio sample.
+	moof := readMp4Boxes(t, boxes[2].data)
+	require.Equal(t, []string{"mfhd", "traf", "traf"}, mp4BoxTypes(moof))
+	assert.Equal(t, []byte{1, 2, 3, 6, 7, 4}, boxes[3].data)
+
+	traf := readMp4Boxes(t, moof[1].data)
+	require.Equal(t, []string{"tfhd", "tfdt", "trun"}, mp4BoxTypes(traf))
+
+	trun := traf[2].data
+	assert.EqualValues(t, 2, binary.BigEndian.Uint32(trun[4:]))
+	// The data offset points to the mdat data, following the moof.
+	assert.EqualValues(t, len(boxes[2].data)+16, binary.BigEndian.Uint32(trun[8:]))
+	// Duration, size and flags of the first sample.
+	assert.EqualValues(t, 9000, binary.BigEndian.Uint32(trun[12:]))
+	assert.EqualValues(t, 3, binary.BigEndian.Uint32(trun[16:]))
+	assert.EqualValues(t, syncSampleFlags, binary.BigEndian.Uint32(trun[20:]))
+	assert.EqualValues(t, nonSyncSampleFlags, binary.BigEndian.Uint32(trun[32:]))
+
+	// Last fragment: the last samples of both tracks.
+	assert.Equal(t, []byte{8, 5}, boxes[5].data)
+
+	traf = readMp4Boxes(t, readMp4Boxes(t, boxes[4].data)[1].data)
+	assert.EqualValues(t, 18000, binary.BigEndian.Uint64(traf[1].data[4:]))
+	// The duration of the last sample is the one of the previous sample.
+	assert.EqualValues(t, 9000, binary.BigEndian.Uint32(traf[2].data[12:]))
+}
+
+func TestMp4Muxer_H264(t *testing.T) {
+	sps := h264Sps(66, 40, 30, 0)
+	video := &track{
+		kind:      "video",
+		mimeType:  "video/h264",
+		clockRate: 90000,
+		info:      videoInfo{width: 640, height: 480, sps: sps, pps: []byte{0x68, 0xce}},
+	}
+	file := createTestFile(t, "test.mp4")
+
+	m, err := newMp4Muxer(file, []*track{video})
+	require.NoError(t, err)
+	require.NoError(t, m.close())
+
+	data, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+
+	boxes := readMp4Boxes(t, data)
+	require.Equal(t, []string{"ftyp", "moov"}, mp4BoxTypes(boxes))
+	assert.Equal(t, "isomiso6mp41avc1", string(boxes[0].data[8:]))
+
+	config := avcConfiguration(video.info)
+	assert.Equal(t, []byte{1, 66, 0x00, 0x1f, 0xff, 0xe1}, config[:6])
+}
@@ -0,0 +1,562 @@
+// Package recording records the Producers of a Router into WebM or
+// fragmented MP4 files, without transcoding:
+//
+//	recorder, err := recording.NewRecorder(recording.Options{
+//		Router:      router,
+//		Producers:   []*mediasoup.Producer{audioProducer, videoProducer},
+//		Format:      recording.MP4,
+//		Path:        "/var/recordings/room-%03d.mp4",
+//		MaxDuration: time.Hour,
+//	})
+//	recorder.On("fileclose", func(path string) { upload(path) })
+//	...
+//	recorder.Close()
+//
+// The Producers are consumed through a DirectTransport. VP8, VP9 and Opus can
+// be recorded in both formats, H264 only in MP4. A file starts with a keyframe
+// of every video track, and the recording goes on until Close() is called or
+// every Producer is closed.
+//
+// A Thumbnailer extracts the keyframes of a video Producer at a given interval,
+// decoding them into JPEG or PNG images with FFmpeg.
+//
+// A MessageRecorder records the messages of DataProducers and DataConsumers,
+// with their time, peer and ppid, into a pluggable MessageStore.
+package recording
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Format string
+
+const (
+	WebM Format = "webm"
+	MP4  Format = "mp4"
+)
+
+// Codecs (lowercase MIME types) supported by each format.
+var supportedCodecs = map[Format]map[string]bool{
+	WebM: {"video/vp8": true, "video/vp9": true, "audio/opus": true},
+	MP4:  {"video/vp8": true, "video/vp9": true, "video/h264": true, "audio/opus": true},
+}
+
+func unsupportedCodecError(mimeType string, format Format) error {
+	return mediasoup.NewUnsupportedError("%s cannot be recorded in %s", mimeType, format)
+}
+
+type Options struct {
+	/**
+	 * Router of the Producers.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Producers to record, each one being a track of the files.
+	 */
+	Producers []*mediasoup.Producer
+
+	/**
+	 * Format of the files. Default WebM.
+	 */
+	Format Format
+
+	/**
+	 * Path of the files. If it contains a verb (e.g. "rec-%d.webm"), it is
+	 * formatted with the index of the file, starting from 0. Otherwise the
+	 * index is inserted before the extension of the files following the first
+	 * one (e.g. "rec.webm", "rec-1.webm"...).
+	 */
+	Path string
+
+	/**
+	 * Duration after which a new file is started, at the next keyframe.
+	 * Unlimited if 0.
+	 */
+	MaxDuration time.Duration
+
+	/**
+	 * Size (in bytes) after which a new file is started, at the next keyframe.
+	 * Unlimited if 0.
+	 */
+	MaxSize int64
+
+	/**
+	 * Logger of the recorder. Default mediasoup.NewLogger("Recorder").
+	 */
+	Logger mediasoup.Logger
+}
+
+// muxer writes the frames of the tracks into a file.
+type muxer interface {
+	writeFrame(t *track, f frame) error
+	// Number of bytes written so far, including the buffered ones.
+	size() int64
+	// Finalize and close the file.
+	close() error
+}
+
+type frame struct {
+	// Time since the start of the file.
+	timestamp time.Duration
+	data      []byte
+	keyframe  bool
+}
+
+// track is a consumed Producer.
+type track struct {
+	consumer     *mediasoup.Consumer
+	kind         string
+	mimeType     string
+	clockRate    int
+	channels     uint8
+	depacketizer depacketizer
+	info         videoInfo
+	// Whether a keyframe was received, giving the info of the video track.
+	ready bool
+	// Whether frames were lost and the next keyframe is awaited.
+	waitKeyframe bool
+	// Whether a keyframe was written in the current file.
+	synced bool
+	ended  bool
+	// Unwrapping of the RTP timestamps.
+	clockStarted  bool
+	lastTimestamp uint32
+	extTimestamp  int64
+	baseTime      time.Time
+}
+
+// time returns the time of the frame having the given RTP timestamp, relative
+// to the arrival of the first frame of the track.
+func (t *track) time(rtpTimestamp uint32, now time.Time) time.Time {
+	if !t.clockStarted {
+		t.clockStarted = true
+		t.lastTimestamp = rtpTimestamp
+		t.baseTime = now
+
+		return now
+	}
+
+	t.extTimestamp += int64(int32(rtpTimestamp - t.lastTimestamp))
+	t.lastTimestamp = rtpTimestamp
+
+	clockRate := int64(t.clockRate)
+	elapsed := time.Duration(t.extTimestamp/clockRate)*time.Second +
+		time.Duration(t.extTimestamp%clockRate)*time.Second/time.Duration(clockRate)
+
+	return t.baseTime.Add(elapsed)
+}
+
+/**
+ * Recorder
+ * @emits newfile - (path string)
+ * @emits fileclose - (path string)
+ * @emits error - (err error)
+ * @emits close
+ */
+type Recorder struct {
+	mediasoup.IEventEmitter
+	logger    mediasoup.Logger
+	options   Options
+	transport *mediasoup.DirectTransport
+	locker    sync.Mutex
+	tracks    []*track
+	muxer     muxer
+	path      string
+	fileStart time.Time
+	files     []string
+	closed    bool
+	// Events to emit once the locker is released.
+	events []recorderEvent
+}
+
+type recorderEvent struct {
+	name string
+	argv []interface{}
+}
+
+/**
+ * Start recording the given Producers.
+ */
+func NewRecorder(options Options) (recorder *Recorder, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if len(options.Producers) == 0 {
+		return nil, mediasoup.NewTypeError("missing Producers")
+	}
+	if len(options.Path) == 0 {
+		return nil, mediasoup.NewTypeError("missing Path")
+	}
+	if len(options.Format) == 0 {
+		options.Format = WebM
+	}
+	if _, ok := supportedCodecs[options.Format]; !ok {
+		return nil, mediasoup.NewTypeError("invalid Format %q", options.Format)
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("Recorder")
+	}
+
+	recorder = &Recorder{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		options:       options,
+	}
+
+	recorder.transport, err = options.Router.CreateDirectTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			recorder.transport.Close()
+			recorder = nil
+		}
+	}()
+
+	rtpCapabilities := options.Router.RtpCapabilities()
+
+	for _, producer := range options.Producers {
+		var consumer *mediasoup.Consumer
+
+		consumer, err = recorder.transport.Consume(mediasoup.ConsumerOptions{
+			ProducerId:      producer.Id(),
+			RtpCapabilities: rtpCapabilities,
+		})
+		if err != nil {
+			return
+		}
+
+		codec := consumer.RtpParameters().Codecs[0]
+		t := &track{
+			consumer:  consumer,
+			kind:      string(consumer.Kind()),
+			mimeType:  strings.ToLower(codec.MimeType),
+			clockRate: codec.ClockRate,
+			channels:  uint8(codec.Channels),
+		}
+
+		if !supportedCodecs[options.Format][t.mimeType] {
+			err = unsupportedCodecError(t.mimeType, options.Format)
+			return
+		}
+
+		switch t.mimeType {
+		case "video/vp8":
+			t.depacketizer = newVideoDepacketizer(&vp8Payload{})
+		case "video/vp9":
+			t.depacketizer = newVideoDepacketizer(&vp9Payload{})
+		case "video/h264":
+			t.depacketizer = newVideoDepacketizer(newH264Payload())
+		default:
+			t.depacketizer = opusDepacketizer{}
+			if t.channels == 0 {
+				t.channels = 1
+			}
+		}
+
+		recorder.tracks = append(recorder.tracks, t)
+	}
+
+	for _, t := range recorder.tracks {
+		t := t
+
+		t.consumer.On("rtp", func(packet []byte) {
+			recorder.handleRtp(t, packet)
+		})
+		t.consumer.On("producerclose", func() {
+			recorder.handleTrackEnd(t)
+		})
+		if t.kind == "video" {
+			t.consumer.RequestKeyFrame()
+		}
+	}
+
+	return
+}
+
+/**
+ * Paths of the files created so far, the last one being recorded unless the
+ * recorder is closed.
+ */
+func (r *Recorder) Files() []string {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return append([]string(nil), r.files...)
+}
+
+/**
+ * Whether the recorder is closed.
+ */
+func (r *Recorder) Closed() bool {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return r.closed
+}
+
+/**
+ * Stop recording, finalizing the current file.
+ */
+func (r *Recorder) Close() (err error) {
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	r.logger.Debug("Close()")
+
+	r.closed = true
+	err = r.closeFile()
+
+	r.unlock()
+
+	r.transport.Close()
+	r.SafeEmit("close")
+
+	return
+}
+
+func (r *Recorder) handleTrackEnd(t *track) {
+	r.locker.Lock()
+
+	t.ended = true
+
+	for _, t := range r.tracks {
+		if !t.ended {
+			r.locker.Unlock()
+			return
+		}
+	}
+
+	r.locker.Unlock()
+
+	r.logger.Debug("every Producer closed, closing")
+	r.Close()
+}
+
+func (r *Recorder) handleRtp(t *track, packet []byte) {
+	r.locker.Lock()
+	defer r.unlock()
+
+	if r.closed {
+		return
+	}
+
+	pkt, err := parseRtpPacket(packet)
+	if err != nil {
+		r.logger.Warn("invalid RTP packet: %v", err)
+		return
+	}
+
+	data, keyframe, lost := t.depacketizer.push(pkt)
+
+	if lost && !t.waitKeyframe {
+		r.logger.Debug("frames lost, waiting for a keyframe [mimeType:%s]", t.mimeType)
+		t.waitKeyframe = true
+		r.requestKeyFrame(t)
+	}
+	if data == nil {
+		return
+	}
+
+	at := t.time(pkt.timestamp, time.Now())
+
+	if t.kind == "video" {
+		if keyframe {
+			if vd, ok := t.depacketizer.(*videoDepacketizer); ok {
+				t.info = vd.info(data)
+			}
+			t.ready = true
+			t.waitKeyframe = false
+		}
+		if !t.ready || t.waitKeyframe {
+			return
+		}
+	}
+
+	if err = r.writeFrame(t, data, keyframe, at); err != nil {
+		r.logger.Error("writing %s failed: %v", r.path, err)
+		r.emitUnlocked("error", err)
+
+		r.closed = true
+		r.closeFile()
+
+		go func() {
+			r.transport.Close()
+			r.SafeEmit("close")
+		}()
+	}
+}
+
+func (r *Recorder) writeFrame(t *track, data []byte, keyframe bool, at time.Time) (err error) {
+	hasVideo := r.hasVideo()
+	// Whether the frame can start a file.
+	canStart := (t.kind == "video" && keyframe) || !hasVideo
+
+	switch {
+	case r.muxer == nil:
+		if !canStart || !r.videoReady() {
+			return
+		}
+		if err = r.openFile(t, at); err != nil {
+			return
+		}
+
+	case canStart && r.rotationDue(at):
+		if err = r.closeFile(); err != nil {
+			return
+		}
+		if err = r.openFile(t, at); err != nil {
+			return
+		}
+	}
+
+	if !t.synced {
+		if t.kind == "video" && !keyframe {
+			return
+		}
+		t.synced = true
+	}
+
+	timestamp := at.Sub(r.fileStart)
+	if timestamp < 0 {
+		return
+	}
+
+	return r.muxer.writeFrame(t, frame{timestamp: timestamp, data: data, keyframe: keyframe})
+}
+
+// emitUnlocked queues an event emitted once the locker is released, so that
+// the listeners can call the methods of the Recorder.
+func (r *Recorder) emitUnlocked(evt string, argv ...interface{}) {
+	r.events = append(r.events, recorderEvent{name: evt, argv: argv})
+}
+
+// unlock releases the locker, then emits the queued events.
+func (r *Recorder) unlock() {
+	events := r.events
+	r.events = nil
+
+	r.locker.Unlock()
+
+	for _, event := range events {
+		r.SafeEmit(event.name, event.argv...)
+	}
+}
+
+func (r *Recorder) hasVideo() bool {
+	for _, t := range r.tracks {
+		if t.kind == "video" && !t.ended {
+			return true
+		}
+	}
+	return false
+}
+
+// videoReady returns whether every video track received a keyframe.
+func (r *Recorder) videoReady() bool {
+	for _, t := range r.tracks {
+		if t.kind == "video" && !t.ended && !t.ready {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) rotationDue(at time.Time) bool {
+	return (r.options.MaxDuration > 0 && at.Sub(r.fileStart) >= r.options.MaxDuration) ||
+		(r.options.MaxSize > 0 && r.muxer.size() >= r.options.MaxSize)
+}
+
+func (r *Recorder) requestKeyFrame(t *track) {
+	if t.kind != "video" {
+		return
+	}
+	go func() {
+		if err := t.consumer.RequestKeyFrame(); err != nil {
+			r.logger.Warn("requesting keyframe failed: %v", err)
+		}
+	}()
+}
+
+func (r *Recorder) openFile(first *track, at time.Time) (err error) {
+	path := r.filePath(len(r.files))
+
+	r.logger.Debug("openFile() [path:%s]", path)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+
+	switch r.options.Format {
+	case MP4:
+		r.muxer, err = newMp4Muxer(file, r.tracks)
+	default:
+		r.muxer, err = newWebmMuxer(file, r.tracks)
+	}
+	if err != nil {
+		r.muxer = nil
+		file.Close()
+		return
+	}
+
+	r.path = path
+	r.fileStart = at
+	r.files = append(r.files, path)
+
+	// The other video tracks wait for their next keyframe.
+	for _, t := range r.tracks {
+		t.synced = false
+
+		if t != first && t.kind == "video" && !t.ended {
+			r.requestKeyFrame(t)
+		}
+	}
+
+	r.emitUnlocked("newfile", path)
+
+	return
+}
+
+func (r *Recorder) closeFile() (err error) {
+	if r.muxer == nil {
+		return
+	}
+
+	r.logger.Debug("closeFile() [path:%s]", r.path)
+
+	err = r.muxer.close()
+	r.muxer = nil
+
+	r.emitUnlocked("fileclose", r.path)
+
+	return
+}
+
+func (r *Recorder) filePath(index int) string {
+	path := r.options.Path
+
+	if strings.Contains(path, "%") {
+		return fmt.Sprintf(path, index)
+	}
+	if index == 0 {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), index, ext)
+}
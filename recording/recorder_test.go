@@ -0,0 +1,58 @@
+package recording
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderFilePath(t *testing.T) {
+	r := &Recorder{options: Options{Path: "/tmp/rec.webm"}}
+	assert.Equal(t, "/tmp/rec.webm", r.filePath(0))
+	assert.Equal(t, "/tmp/rec-2.webm", r.filePath(2))
+
+	r = &Recorder{options: Options{Path: "/tmp/rec-%03d.mp4"}}
+	assert.Equal(t, "/tmp/rec-000.mp4", r.filePath(0))
+	assert.Equal(t, "/tmp/rec-001.mp4", r.filePath(1))
+}
+
+func TestNewRecorder_InvalidOptions(t *testing.T) {
+	_, err := NewRecorder(Options{})
+	assert.Error(t, err)
+}
+
+func TestTrackTime(t *testing.T) {
+	tr := &track{clockRate: 90000}
+	now := time.Now()
+	start := uint32(0xffffff00)
+
+	assert.Equal(t, now, tr.time(start, now))
+	// The timestamp wraps around.
+	assert.Equal(t, now.Add(time.Second), tr.time(start+90000, now.Add(time.Hour)))
+	// Frames may be out of order.
+	assert.Equal(t, now.Add(500*time.Millisecond), tr.time(start+45000, now))
+}
+
+func TestRecorderEventsEmittedUnlocked(t *testing.T) {
+	mediasoup.SetConfig(mediasoup.Config{EmitterDispatchMode: mediasoup.EmitterDispatchMode_Sync})
+	defer mediasoup.SetConfig(mediasoup.DefaultConfig())
+
+	r := &Recorder{IEventEmitter: mediasoup.NewEventEmitter()}
+
+	var files []string
+
+	r.On("newfile", func(path string) {
+		// Would deadlock if emitted with the locker held.
+		files = r.Files()
+	})
+
+	r.locker.Lock()
+	r.files = append(r.files, "/tmp/rec.webm")
+	r.emitUnlocked("newfile", "/tmp/rec.webm")
+	assert.Empty(t, files)
+	r.unlock()
+
+	assert.Equal(t, []string{"/tmp/rec.webm"}, files)
+}
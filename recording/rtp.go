@@ -0,0 +1,112 @@
+package recording
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+type rtpPacket struct {
+	marker         bool
+	payloadType    uint8
+	sequenceNumber uint16
+	timestamp      uint32
+	ssrc           uint32
+	payload        []byte
+}
+
+/**
+ * Parse the RTP packet, the payload refers to the given buffer.
+ */
+func parseRtpPacket(buf []byte) (pkt rtpPacket, err error) {
+	if len(buf) < 12 {
+		err = errors.New("rtp packet too short")
+		return
+	}
+	if version := buf[0] >> 6; version != 2 {
+		err = errors.New("invalid rtp version")
+		return
+	}
+
+	padding := buf[0]&0x20 != 0
+	extension := buf[0]&0x10 != 0
+	offset := 12 + 4*int(buf[0]&0x0f)
+
+	pkt.marker = buf[1]&0x80 != 0
+	pkt.payloadType = buf[1] & 0x7f
+	pkt.sequenceNumber = binary.BigEndian.Uint16(buf[2:])
+	pkt.timestamp = binary.BigEndian.Uint32(buf[4:])
+	pkt.ssrc = binary.BigEndian.Uint32(buf[8:])
+
+	if extension {
+		if len(buf) < offset+4 {
+			err = errors.New("rtp header extension too short")
+			return
+		}
+		offset += 4 + 4*int(binary.BigEndian.Uint16(buf[offset+2:]))
+	}
+	if len(buf) < offset {
+		err = errors.New("rtp header too short")
+		return
+	}
+
+	end := len(buf)
+
+	if padding {
+		if end == offset || int(buf[end-1]) == 0 || end-int(buf[end-1]) < offset {
+			err = errors.New("invalid rtp padding")
+			return
+		}
+		end -= int(buf[end-1])
+	}
+	pkt.payload = buf[offset:end]
+
+	return
+}
+
+// bitReader reads the bits of a buffer, MSB first, recording the first error.
+type bitReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *bitReader) u(n int) (v uint32) {
+	for i := 0; i < n; i++ {
+		if r.pos >= len(r.data)*8 {
+			r.err = errors.New("bitstream too short")
+			return
+		}
+		v = v<<1 | uint32(r.data[r.pos/8]>>(7-uint(r.pos%8))&0x01)
+		r.pos++
+	}
+	return
+}
+
+func (r *bitReader) flag() bool {
+	return r.u(1) == 1
+}
+
+// ue reads an unsigned Exp-Golomb code.
+func (r *bitReader) ue() uint32 {
+	zeros := 0
+
+	for r.u(1) == 0 {
+		if r.err != nil || zeros > 31 {
+			r.err = errors.New("invalid exp-golomb code")
+			return 0
+		}
+		zeros++
+	}
+
+	return 1<<uint(zeros) - 1 + r.u(zeros)
+}
+
+// se reads a signed Exp-Golomb code.
+func (r *bitReader) se() int32 {
+	v := r.ue()
+
+	if v%2 == 1 {
+		return int32(v/2 + 1)
+	}
+	return -int32(v / 2)
+}
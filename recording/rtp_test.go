@@ -0,0 +1,83 @@
+package recording
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRtpPacket(seq uint16, timestamp uint32, marker bool, payload []byte) []byte {
+	buf := make([]byte, 12, 12+len(payload))
+	buf[0] = 0x80
+	buf[1] = 96
+	if marker {
+		buf[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(buf[2:], seq)
+	binary.BigEndian.PutUint32(buf[4:], timestamp)
+	binary.BigEndian.PutUint32(buf[8:], 1234)
+
+	return append(buf, payload...)
+}
+
+func TestParseRtpPacket(t *testing.T) {
+	pkt, err := parseRtpPacket(newRtpPacket(10, 3000, true, []byte{1, 2, 3}))
+	require.NoError(t, err)
+	assert.True(t, pkt.marker)
+	assert.EqualValues(t, 96, pkt.payloadType)
+	assert.EqualValues(t, 10, pkt.sequenceNumber)
+	assert.EqualValues(t, 3000, pkt.timestamp)
+	assert.EqualValues(t, 1234, pkt.ssrc)
+	assert.Equal(t, []byte{1, 2, 3}, pkt.payload)
+}
+
+func TestParseRtpPacket_CsrcExtensionPadding(t *testing.T) {
+	buf := newRtpPacket(1, 0, false, nil)
+	buf[0] |= 0x20 | 0x10 | 0x01
+	buf = append(buf, 0, 0, 0, 1)                   // CSRC
+	buf = append(buf, 0xbe, 0xde, 0, 1, 1, 2, 3, 4) // extension of 1 word
+	buf = append(buf, 9, 8)                         // payload
+	buf = append(buf, 0, 0, 3)                      // padding
+
+	pkt, err := parseRtpPacket(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{9, 8}, pkt.payload)
+}
+
+func TestParseRtpPacket_Invalid(t *testing.T) {
+	_, err := parseRtpPacket([]byte{0x80, 0})
+	assert.Error(t, err)
+
+	buf := newRtpPacket(1, 0, false, []byte{1})
+	buf[0] = 0x40
+	_, err = parseRtpPacket(buf)
+	assert.Error(t, err)
+
+	buf = newRtpPacket(1, 0, false, []byte{1})
+	buf[0] |= 0x20
+	buf[len(buf)-1] = 10
+	_, err = parseRtpPacket(buf)
+	assert.Error(t, err)
+
+	buf = newRtpPacket(1, 0, false, nil)
+	buf[0] |= 0x10
+	_, err = parseRtpPacket(append(buf, 0xbe, 0xde, 0, 4))
+	assert.Error(t, err)
+}
+
+func TestBitReader(t *testing.T) {
+	// 1 | 010 | 011 | 00100 | 00101: ue 0, 1, 2, 3 and se -2.
+	r := &bitReader{data: []byte{0xa6, 0x42, 0x80}}
+
+	assert.EqualValues(t, 0, r.ue())
+	assert.EqualValues(t, 1, r.ue())
+	assert.EqualValues(t, 2, r.ue())
+	assert.EqualValues(t, 3, r.ue())
+	assert.EqualValues(t, -2, r.se())
+	assert.NoError(t, r.err)
+
+	r.u(16)
+	assert.Error(t, r.err)
+}
@@ -0,0 +1,411 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Time given to FFmpeg to decode a keyframe.
+const decodeTimeout = 10 * time.Second
+
+type ImageFormat string
+
+const (
+	JPEG ImageFormat = "jpeg"
+	PNG  ImageFormat = "png"
+)
+
+type ThumbnailerOptions struct {
+	/**
+	 * Router of the Producer.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Video Producer (VP8, VP9 or H264).
+	 */
+	Producer *mediasoup.Producer
+
+	/**
+	 * Interval between two thumbnails, a keyframe being requested when the
+	 * next one is due. Default 10 seconds.
+	 */
+	Interval time.Duration
+
+	/**
+	 * Format of the thumbnails. Default JPEG.
+	 */
+	Format ImageFormat
+
+	/**
+	 * Width of the thumbnails, the aspect ratio being kept. Default the width
+	 * of the video.
+	 */
+	Width int
+
+	/**
+	 * Whether the keyframes are only emitted ("keyframe" event), without
+	 * decoding them into thumbnails.
+	 */
+	KeyframesOnly bool
+
+	/**
+	 * Path of the FFmpeg binary decoding the keyframes. Default "ffmpeg".
+	 */
+	FFmpegPath string
+
+	/**
+	 * Logger of the thumbnailer. Default mediasoup.NewLogger("Thumbnailer").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Keyframe of a video Producer.
+ */
+type Keyframe struct {
+	/**
+	 * Codec of the Producer (e.g. "video/vp8").
+	 */
+	MimeType string
+
+	/**
+	 * The keyframe as a single frame IVF file for VP8 and VP9, as an Annex B
+	 * stream starting with the SPS and PPS for H264. Both can be decoded by
+	 * FFmpeg.
+	 */
+	Data []byte
+
+	Width  int
+	Height int
+	Time   time.Time
+}
+
+/**
+ * Thumbnail is a decoded keyframe.
+ */
+type Thumbnail struct {
+	Format ImageFormat
+	Data   []byte
+	Time   time.Time
+}
+
+/**
+ * Thumbnailer extracts the keyframes of a video Producer at a given interval,
+ * decoding them with FFmpeg into images, without a full recording pipeline.
+ * @emits keyframe - (keyframe Keyframe)
+ * @emits thumbnail - (thumbnail Thumbnail)
+ * @emits error - (err error)
+ * @emits close
+ */
+type Thumbnailer struct {
+	mediasoup.IEventEmitter
+	logger       mediasoup.Logger
+	options      ThumbnailerOptions
+	transport    *mediasoup.DirectTransport
+	consumer     *mediasoup.Consumer
+	mimeType     string
+	depacketizer *videoDepacketizer
+	locker       sync.Mutex
+	last         time.Time
+	decoding     bool
+	closed       bool
+	closeCh      chan struct{}
+}
+
+/**
+ * Start extracting the keyframes of the given Producer.
+ */
+func NewThumbnailer(options ThumbnailerOptions) (thumbnailer *Thumbnailer, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Producer == nil {
+		return nil, mediasoup.NewTypeError("missing Producer")
+	}
+	if options.Producer.Kind() != mediasoup.MediaKind_Video {
+		return nil, mediasoup.NewTypeError("Producer is not video")
+	}
+	if options.Interval <= 0 {
+		options.Interval = 10 * time.Second
+	}
+	if len(options.Format) == 0 {
+		options.Format = JPEG
+	}
+	if options.Format != JPEG && options.Format != PNG {
+		return nil, mediasoup.NewTypeError("invalid Format %q", options.Format)
+	}
+	if len(options.FFmpegPath) == 0 {
+		options.FFmpegPath = "ffmpeg"
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("Thumbnailer")
+	}
+
+	thumbnailer = &Thumbnailer{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		options:       options,
+		closeCh:       make(chan struct{}),
+	}
+
+	if thumbnailer.transport, err = options.Router.CreateDirectTransport(); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			thumbnailer.transport.Close()
+			thumbnailer = nil
+		}
+	}()
+
+	thumbnailer.consumer, err = thumbnailer.transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      options.Producer.Id(),
+		RtpCapabilities: options.Router.RtpCapabilities(),
+	})
+	if err != nil {
+		return
+	}
+
+	thumbnailer.mimeType = strings.ToLower(thumbnailer.consumer.RtpParameters().Codecs[0].MimeType)
+
+	switch thumbnailer.mimeType {
+	case "video/vp8":
+		thumbnailer.depacketizer = newVideoDepacketizer(&vp8Payload{})
+	case "video/vp9":
+		thumbnailer.depacketizer = newVideoDepacketizer(&vp9Payload{})
+	case "video/h264":
+		thumbnailer.depacketizer = newVideoDepacketizer(newH264Payload())
+	default:
+		err = mediasoup.NewUnsupportedError("no thumbnails of %s", thumbnailer.mimeType)
+		return
+	}
+
+	thumbnailer.consumer.On("rtp", thumbnailer.handleRtp)
+	thumbnailer.consumer.On("producerclose", func() { thumbnailer.Close() })
+
+	go thumbnailer.requestKeyFrames()
+
+	return
+}
+
+// requestKeyFrames requests a keyframe at once, then whenever a thumbnail is
+// due.
+func (t *Thumbnailer) requestKeyFrames() {
+	ticker := time.NewTicker(t.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.consumer.RequestKeyFrame(); err != nil && !t.Closed() {
+			t.logger.Warn("requesting keyframe failed: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-t.closeCh:
+			return
+		}
+	}
+}
+
+func (t *Thumbnailer) handleRtp(packet []byte) {
+	pkt, err := parseRtpPacket(packet)
+	if err != nil {
+		t.logger.Warn("invalid RTP packet: %v", err)
+		return
+	}
+
+	t.locker.Lock()
+
+	if t.closed {
+		t.locker.Unlock()
+		return
+	}
+
+	data, keyframe, _ := t.depacketizer.push(pkt)
+	now := time.Now()
+
+	// A small tolerance, the requested keyframes arriving a bit after the tick.
+	if data == nil || !keyframe || now.Sub(t.last) < t.options.Interval*9/10 {
+		t.locker.Unlock()
+		return
+	}
+	t.last = now
+
+	info := t.depacketizer.info(data)
+	frame := Keyframe{
+		MimeType: t.mimeType,
+		Width:    info.width,
+		Height:   info.height,
+		Time:     now,
+	}
+
+	if t.mimeType == "video/h264" {
+		frame.Data = annexB(info.sps, info.pps, data)
+	} else {
+		frame.Data = ivf(t.mimeType, info.width, info.height, data)
+	}
+
+	decode := !t.options.KeyframesOnly && !t.decoding
+	t.decoding = t.decoding || decode
+
+	t.locker.Unlock()
+
+	t.SafeEmit("keyframe", frame)
+
+	if decode {
+		go t.decode(frame)
+	}
+}
+
+// decode decodes the keyframe with FFmpeg, one at a time.
+func (t *Thumbnailer) decode(frame Keyframe) {
+	defer func() {
+		t.locker.Lock()
+		t.decoding = false
+		t.locker.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), decodeTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, t.options.FFmpegPath, decodeArgs(frame.MimeType, t.options.Format, t.options.Width)...)
+	cmd.Stdin = bytes.NewReader(frame.Data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if t.Closed() {
+			return
+		}
+		err = fmt.Errorf("decoding keyframe failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		t.logger.Error("%v", err)
+		t.SafeEmit("error", err)
+		return
+	}
+
+	t.SafeEmit("thumbnail", Thumbnail{
+		Format: t.options.Format,
+		Data:   stdout.Bytes(),
+		Time:   frame.Time,
+	})
+}
+
+// decodeArgs returns the arguments of FFmpeg decoding a keyframe from stdin
+// into an image written to stdout.
+func decodeArgs(mimeType string, format ImageFormat, width int) []string {
+	demuxer := "ivf"
+
+	if mimeType == "video/h264" {
+		demuxer = "h264"
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-f", demuxer, "-i", "pipe:0", "-frames:v", "1"}
+
+	if width > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=%d:-2", width))
+	}
+
+	codec := "mjpeg"
+
+	if format == PNG {
+		codec = "png"
+	}
+
+	return append(args, "-c:v", codec, "-f", "image2", "pipe:1")
+}
+
+// ivf returns a single frame IVF file.
+func ivf(mimeType string, width, height int, frame []byte) []byte {
+	data := make([]byte, 32+12, 32+12+len(frame))
+	fourcc := "VP80"
+
+	if mimeType == "video/vp9" {
+		fourcc = "VP90"
+	}
+
+	copy(data, "DKIF")
+	binary.LittleEndian.PutUint16(data[6:], 32)
+	copy(data[8:], fourcc)
+	binary.LittleEndian.PutUint16(data[12:], uint16(width))
+	binary.LittleEndian.PutUint16(data[14:], uint16(height))
+	binary.LittleEndian.PutUint32(data[16:], 30) // time base denominator
+	binary.LittleEndian.PutUint32(data[20:], 1)  // time base numerator
+	binary.LittleEndian.PutUint32(data[24:], 1)  // number of frames
+
+	binary.LittleEndian.PutUint32(data[32:], uint32(len(frame)))
+
+	return append(data, frame...)
+}
+
+// annexB converts the length prefixed NAL units of the frame into an Annex B
+// stream, starting with the SPS and PPS.
+func annexB(sps, pps, frame []byte) []byte {
+	startCode := []byte{0, 0, 0, 1}
+	data := make([]byte, 0, 12+len(sps)+len(pps)+len(frame))
+
+	data = append(append(data, startCode...), sps...)
+	data = append(append(data, startCode...), pps...)
+
+	for len(frame) >= 4 {
+		size := int(binary.BigEndian.Uint32(frame))
+
+		if size > len(frame)-4 {
+			break
+		}
+		nalUnit := frame[4 : 4+size]
+		frame = frame[4+size:]
+
+		// The SPS and PPS are already written.
+		if size == 0 || nalUnit[0]&0x1f == 7 || nalUnit[0]&0x1f == 8 {
+			continue
+		}
+		data = append(append(data, startCode...), nalUnit...)
+	}
+
+	return data
+}
+
+/**
+ * Whether the thumbnailer is closed.
+ */
+func (t *Thumbnailer) Closed() bool {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	return t.closed
+}
+
+/**
+ * Stop extracting keyframes. It is called when the Producer is closed.
+ */
+func (t *Thumbnailer) Close() error {
+	t.locker.Lock()
+
+	if t.closed {
+		t.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	t.logger.Debug("Close()")
+
+	t.closed = true
+	close(t.closeCh)
+
+	t.locker.Unlock()
+
+	t.transport.Close()
+	t.SafeEmit("close")
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package recording
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewThumbnailer_InvalidOptions(t *testing.T) {
+	_, err := NewThumbnailer(ThumbnailerOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestIvf(t *testing.T) {
+	data := ivf("video/vp9", 640, 360, []byte{1, 2, 3})
+
+	assert.Len(t, data, 32+12+3)
+	assert.Equal(t, "DKIF", string(data[:4]))
+	assert.Equal(t, "VP90", string(data[8:12]))
+	assert.EqualValues(t, 640, binary.LittleEndian.Uint16(data[12:]))
+	assert.EqualValues(t, 360, binary.LittleEndian.Uint16(data[14:]))
+	assert.EqualValues(t, 3, binary.LittleEndian.Uint32(data[32:]))
+	assert.Equal(t, []byte{1, 2, 3}, data[44:])
+}
+
+func TestAnnexB(t *testing.T) {
+	sps, pps := []byte{0x67, 1}, []byte{0x68, 2}
+	frame := []byte{
+		0, 0, 0, 2, 0x67, 1,
+		0, 0, 0, 2, 0x68, 2,
+		0, 0, 0, 3, 0x65, 3, 4,
+	}
+
+	assert.Equal(t, []byte{
+		0, 0, 0, 1, 0x67, 1,
+		0, 0, 0, 1, 0x68, 2,
+		0, 0, 0, 1, 0x65, 3, 4,
+	}, annexB(sps, pps, frame))
+}
+
+func TestDecodeArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-hide_banner", "-loglevel", "error", "-f", "h264", "-i", "pipe:0", "-frames:v", "1",
+		"-vf", "scale=320:-2", "-c:v", "png", "-f", "image2", "pipe:1",
+	}, decodeArgs("video/h264", PNG, 320))
+
+	assert.Equal(t, []string{
+		"-hide_banner", "-loglevel", "error", "-f", "ivf", "-i", "pipe:0", "-frames:v", "1",
+		"-c:v", "mjpeg", "-f", "image2", "pipe:1",
+	}, decodeArgs("video/vp8", JPEG, 0))
+}
@@ -0,0 +1,329 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"math"
+	"os"
+	"time"
+)
+
+// Matroska element ids.
+const (
+	ebmlHeaderId         = 0x1a45dfa3
+	ebmlVersionId        = 0x4286
+	ebmlReadVersionId    = 0x42f7
+	ebmlMaxIdLengthId    = 0x42f2
+	ebmlMaxSizeLengthId  = 0x42f3
+	docTypeId            = 0x4282
+	docTypeVersionId     = 0x4287
+	docTypeReadVersionId = 0x4285
+	segmentId            = 0x18538067
+	infoId               = 0x1549a966
+	timecodeScaleId      = 0x2ad7b1
+	muxingAppId          = 0x4d80
+	writingAppId         = 0x5741
+	durationId           = 0x4489
+	tracksId             = 0x1654ae6b
+	trackEntryId         = 0xae
+	trackNumberId        = 0xd7
+	trackUidId           = 0x73c5
+	trackTypeId          = 0x83
+	codecIdId            = 0x86
+	codecPrivateId       = 0x63a2
+	codecDelayId         = 0x56aa
+	seekPreRollId        = 0x56bb
+	videoId              = 0xe0
+	pixelWidthId         = 0xb0
+	pixelHeightId        = 0xba
+	audioId              = 0xe1
+	samplingFrequencyId  = 0xb5
+	channelsId           = 0x9f
+	clusterId            = 0x1f43b675
+	timecodeId           = 0xe7
+	simpleBlockId        = 0xa3
+)
+
+// Longest duration of a cluster.
+const maxClusterDuration = 5 * time.Second
+
+func ebmlId(id uint32) []byte {
+	switch {
+	case id > 0xffffff:
+		return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xffff:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xff:
+		return []byte{byte(id >> 8), byte(id)}
+	default:
+		return []byte{byte(id)}
+	}
+}
+
+// ebmlSize encodes the size as a variable length integer.
+func ebmlSize(size uint64) []byte {
+	length := 1
+	for length < 8 && size >= 1<<(7*uint(length))-1 {
+		length++
+	}
+
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buf[i] = byte(size)
+		size >>= 8
+	}
+	buf[0] |= 0x80 >> uint(length-1)
+
+	return buf
+}
+
+func ebmlElement(id uint32, data ...[]byte) []byte {
+	size := 0
+	for _, d := range data {
+		size += len(d)
+	}
+
+	element := append(ebmlId(id), ebmlSize(uint64(size))...)
+	for _, d := range data {
+		element = append(element, d...)
+	}
+
+	return element
+}
+
+func ebmlUint(id uint32, value uint64) []byte {
+	length := 1
+	for length < 8 && value >= 1<<(8*uint(length)) {
+		length++
+	}
+
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buf[i] = byte(value)
+		value >>= 8
+	}
+
+	return ebmlElement(id, buf)
+}
+
+func ebmlFloat(id uint32, value float64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(value))
+
+	return ebmlElement(id, buf)
+}
+
+func ebmlString(id uint32, value string) []byte {
+	return ebmlElement(id, []byte(value))
+}
+
+/**
+ * WebM muxer, writing a cluster every video keyframe or 5 seconds. The sizes
+ * and the duration of the file are written on close.
+ */
+type webmMuxer struct {
+	file    *os.File
+	writer  *bufio.Writer
+	written int64
+	tracks  map[*track]uint64
+	// Offsets in the file of the size of the Segment, of its data and of the
+	// value of the Duration.
+	segmentSizeOffset int64
+	segmentOffset     int64
+	durationOffset    int64
+	cluster           []byte
+	clusterTime       time.Duration
+	clusterOpen       bool
+	duration          time.Duration
+}
+
+func newWebmMuxer(file *os.File, tracks []*track) (m *webmMuxer, err error) {
+	m = &webmMuxer{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		tracks: make(map[*track]uint64, len(tracks)),
+	}
+
+	header := ebmlElement(ebmlHeaderId,
+		ebmlUint(ebmlVersionId, 1),
+		ebmlUint(ebmlReadVersionId, 1),
+		ebmlUint(ebmlMaxIdLengthId, 4),
+		ebmlUint(ebmlMaxSizeLengthId, 8),
+		ebmlString(docTypeId, "webm"),
+		ebmlUint(docTypeVersionId, 4),
+		ebmlUint(docTypeReadVersionId, 2),
+	)
+
+	// Segment of unknown size until closed.
+	header = append(header, ebmlId(segmentId)...)
+	m.segmentSizeOffset = int64(len(header))
+	header = append(header, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	m.segmentOffset = int64(len(header))
+
+	info := ebmlElement(infoId,
+		ebmlUint(timecodeScaleId, uint64(time.Millisecond)),
+		ebmlString(muxingAppId, "mediasoup-go"),
+		ebmlString(writingAppId, "mediasoup-go"),
+		ebmlFloat(durationId, 0),
+	)
+	header = append(header, info...)
+	m.durationOffset = int64(len(header)) - 8
+
+	var entries [][]byte
+
+	for i, t := range tracks {
+		number := uint64(i + 1)
+		m.tracks[t] = number
+
+		entry := [][]byte{
+			ebmlUint(trackNumberId, number),
+			ebmlUint(trackUidId, number),
+		}
+
+		switch t.mimeType {
+		case "audio/opus":
+			entry = append(entry,
+				ebmlUint(trackTypeId, 2),
+				ebmlString(codecIdId, "A_OPUS"),
+				ebmlElement(codecPrivateId, opusHead(t.channels)),
+				ebmlUint(codecDelayId, 0),
+				ebmlUint(seekPreRollId, uint64(80*time.Millisecond)),
+				ebmlElement(audioId,
+					ebmlFloat(samplingFrequencyId, float64(t.clockRate)),
+					ebmlUint(channelsId, uint64(t.channels)),
+				),
+			)
+
+		case "video/vp8", "video/vp9":
+			codecId := "V_VP8"
+			if t.mimeType == "video/vp9" {
+				codecId = "V_VP9"
+			}
+			entry = append(entry,
+				ebmlUint(trackTypeId, 1),
+				ebmlString(codecIdId, codecId),
+				ebmlElement(videoId,
+					ebmlUint(pixelWidthId, uint64(t.info.width)),
+					ebmlUint(pixelHeightId, uint64(t.info.height)),
+				),
+			)
+
+		default:
+			err = unsupportedCodecError(t.mimeType, WebM)
+			return
+		}
+
+		entries = append(entries, ebmlElement(trackEntryId, entry...))
+	}
+
+	header = append(header, ebmlElement(tracksId, entries...)...)
+
+	err = m.write(header)
+
+	return
+}
+
+func (m *webmMuxer) write(data []byte) error {
+	n, err := m.writer.Write(data)
+	m.written += int64(n)
+
+	return err
+}
+
+func (m *webmMuxer) writeFrame(t *track, f frame) (err error) {
+	number, ok := m.tracks[t]
+	if !ok {
+		return nil
+	}
+
+	if !m.clusterOpen ||
+		(t.kind == "video" && f.keyframe) ||
+		f.timestamp-m.clusterTime >= maxClusterDuration {
+		if err = m.flushCluster(); err != nil {
+			return
+		}
+		m.clusterOpen = true
+		m.clusterTime = f.timestamp
+	}
+
+	relative := int64((f.timestamp - m.clusterTime) / time.Millisecond)
+
+	if relative < math.MinInt16 {
+		relative = math.MinInt16
+	}
+
+	flags := byte(0)
+	if f.keyframe {
+		flags = 0x80
+	}
+
+	block := append(ebmlSize(number), byte(uint16(relative)>>8), byte(relative), flags)
+	m.cluster = append(m.cluster, ebmlElement(simpleBlockId, block, f.data)...)
+
+	if f.timestamp > m.duration {
+		m.duration = f.timestamp
+	}
+
+	return
+}
+
+func (m *webmMuxer) flushCluster() error {
+	if !m.clusterOpen {
+		return nil
+	}
+
+	cluster := ebmlElement(clusterId,
+		ebmlUint(timecodeId, uint64(m.clusterTime/time.Millisecond)),
+		m.cluster,
+	)
+	m.cluster = nil
+	m.clusterOpen = false
+
+	return m.write(cluster)
+}
+
+func (m *webmMuxer) size() int64 {
+	return m.written + int64(len(m.cluster))
+}
+
+func (m *webmMuxer) close() (err error) {
+	defer func() {
+		if closeErr := m.file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if err = m.flushCluster(); err != nil {
+		return
+	}
+	if err = m.writer.Flush(); err != nil {
+		return
+	}
+
+	segmentSize := make([]byte, 8)
+	binary.BigEndian.PutUint64(segmentSize, uint64(m.written-m.segmentOffset))
+	segmentSize[0] = 0x01
+
+	if _, err = m.file.WriteAt(segmentSize, m.segmentSizeOffset); err != nil {
+		return
+	}
+
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(float64(m.duration/time.Millisecond)))
+
+	_, err = m.file.WriteAt(duration, m.durationOffset)
+
+	return
+}
+
+// opusHead returns the Opus identification header (RFC 7845).
+func opusHead(channels uint8) []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, channels)
+	head = append(head, 0, 0)                   // pre-skip
+	head = append(head, 0x80, 0xbb, 0x00, 0x00) // 48000 Hz
+	head = append(head, 0, 0)                   // output gain
+	head = append(head, 0)                      // channel mapping family
+
+	return head
+}
@@ -0,0 +1,465 @@
+package room
+
+import (
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type PeerOptions struct {
+	/**
+	 * Id of the Peer, unique in the room.
+	 */
+	Id string
+
+	/**
+	 * RTP capabilities of the client, the media it cannot receive are not
+	 * consumed.
+	 */
+	RtpCapabilities mediasoup.RtpCapabilities
+
+	/**
+	 * SCTP capabilities of the client, nil if it does not use data channels.
+	 */
+	SctpCapabilities *mediasoup.SctpCapabilities
+
+	/**
+	 * Custom application data.
+	 */
+	AppData interface{}
+}
+
+/**
+ * Peer is a participant of a room.
+ * @emits newconsumer - (consumer *mediasoup.Consumer, producerPeer *Peer)
+ * @emits newdataconsumer - (dataConsumer *mediasoup.DataConsumer, producerPeer *Peer)
+ * @emits leave
+ */
+type Peer struct {
+	mediasoup.IEventEmitter
+	room          *Room
+	id            string
+	options       PeerOptions
+	locker        sync.Mutex
+	transports    map[string]*mediasoup.WebRtcTransport
+	sendTransport *mediasoup.WebRtcTransport
+	recvTransport *mediasoup.WebRtcTransport
+	producers     map[string]*mediasoup.Producer
+	consumers     map[string]*mediasoup.Consumer
+	dataProducers map[string]*mediasoup.DataProducer
+	dataConsumers map[string]*mediasoup.DataConsumer
+	left          bool
+}
+
+func newPeer(room *Room, options PeerOptions) *Peer {
+	return &Peer{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		room:          room,
+		id:            options.Id,
+		options:       options,
+		transports:    make(map[string]*mediasoup.WebRtcTransport),
+		producers:     make(map[string]*mediasoup.Producer),
+		consumers:     make(map[string]*mediasoup.Consumer),
+		dataProducers: make(map[string]*mediasoup.DataProducer),
+		dataConsumers: make(map[string]*mediasoup.DataConsumer),
+	}
+}
+
+/**
+ * Peer id.
+ */
+func (p *Peer) Id() string {
+	return p.id
+}
+
+/**
+ * Room of the Peer.
+ */
+func (p *Peer) Room() *Room {
+	return p.room
+}
+
+/**
+ * RTP capabilities of the client.
+ */
+func (p *Peer) RtpCapabilities() mediasoup.RtpCapabilities {
+	return p.options.RtpCapabilities
+}
+
+/**
+ * App custom data.
+ */
+func (p *Peer) AppData() interface{} {
+	return p.options.AppData
+}
+
+/**
+ * Get a transport of the Peer by id, nil if none.
+ */
+func (p *Peer) Transport(id string) *mediasoup.WebRtcTransport {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.transports[id]
+}
+
+/**
+ * Get a Producer of the Peer by id, nil if none.
+ */
+func (p *Peer) Producer(id string) *mediasoup.Producer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.producers[id]
+}
+
+/**
+ * Get a Consumer of the Peer by id, nil if none.
+ */
+func (p *Peer) Consumer(id string) *mediasoup.Consumer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.consumers[id]
+}
+
+/**
+ * Get a DataProducer of the Peer by id, nil if none.
+ */
+func (p *Peer) DataProducer(id string) *mediasoup.DataProducer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.dataProducers[id]
+}
+
+/**
+ * Get a DataConsumer of the Peer by id, nil if none.
+ */
+func (p *Peer) DataConsumer(id string) *mediasoup.DataConsumer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.dataConsumers[id]
+}
+
+/**
+ * Producers of the Peer.
+ */
+func (p *Peer) Producers() []*mediasoup.Producer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	producers := make([]*mediasoup.Producer, 0, len(p.producers))
+
+	for _, producer := range p.producers {
+		producers = append(producers, producer)
+	}
+
+	return producers
+}
+
+/**
+ * DataProducers of the Peer.
+ */
+func (p *Peer) DataProducers() []*mediasoup.DataProducer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	dataProducers := make([]*mediasoup.DataProducer, 0, len(p.dataProducers))
+
+	for _, dataProducer := range p.dataProducers {
+		dataProducers = append(dataProducers, dataProducer)
+	}
+
+	return dataProducers
+}
+
+/**
+ * Consumers of the Peer.
+ */
+func (p *Peer) Consumers() []*mediasoup.Consumer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	consumers := make([]*mediasoup.Consumer, 0, len(p.consumers))
+
+	for _, consumer := range p.consumers {
+		consumers = append(consumers, consumer)
+	}
+
+	return consumers
+}
+
+/**
+ * DataConsumers of the Peer.
+ */
+func (p *Peer) DataConsumers() []*mediasoup.DataConsumer {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	dataConsumers := make([]*mediasoup.DataConsumer, 0, len(p.dataConsumers))
+
+	for _, dataConsumer := range p.dataConsumers {
+		dataConsumers = append(dataConsumers, dataConsumer)
+	}
+
+	return dataConsumers
+}
+
+/**
+ * Create the transport used by Produce() and ProduceData(). SCTP is enabled
+ * if the Peer has SCTP capabilities.
+ */
+func (p *Peer) CreateSendTransport(options mediasoup.WebRtcTransportOptions) (transport *mediasoup.WebRtcTransport, err error) {
+	p.room.logger.Debug("CreateSendTransport() [peerId:%s]", p.id)
+
+	return p.createTransport(options, &p.sendTransport)
+}
+
+/**
+ * Create the transport receiving the media of the other Peers. The Producers
+ * of the other Peers are consumed once it is created, and the new ones when
+ * they are created.
+ */
+func (p *Peer) CreateRecvTransport(options mediasoup.WebRtcTransportOptions) (transport *mediasoup.WebRtcTransport, err error) {
+	p.room.logger.Debug("CreateRecvTransport() [peerId:%s]", p.id)
+
+	if transport, err = p.createTransport(options, &p.recvTransport); err != nil {
+		return
+	}
+
+	for _, other := range p.room.otherPeers(p) {
+		for _, producer := range other.Producers() {
+			p.consume(other, producer)
+		}
+		for _, dataProducer := range other.DataProducers() {
+			p.consumeData(other, dataProducer)
+		}
+	}
+
+	return
+}
+
+func (p *Peer) createTransport(options mediasoup.WebRtcTransportOptions, slot **mediasoup.WebRtcTransport) (transport *mediasoup.WebRtcTransport, err error) {
+	p.locker.Lock()
+	left, exists := p.left, *slot != nil
+	p.locker.Unlock()
+
+	if left {
+		return nil, mediasoup.NewInvalidStateError("Peer left")
+	}
+	if exists {
+		return nil, mediasoup.NewInvalidStateError("transport already created")
+	}
+
+	if sctpCaps := p.options.SctpCapabilities; sctpCaps != nil && !options.EnableSctp {
+		options.EnableSctp = true
+		options.NumSctpStreams = sctpCaps.NumStreams
+	}
+
+	if transport, err = p.room.router.CreateWebRtcTransport(options); err != nil {
+		return
+	}
+
+	p.locker.Lock()
+
+	// The Peer may have left in the meanwhile.
+	if p.left || *slot != nil {
+		p.locker.Unlock()
+		transport.Close()
+		return nil, mediasoup.NewInvalidStateError("Peer left or transport already created")
+	}
+	p.transports[transport.Id()] = transport
+	*slot = transport
+
+	p.locker.Unlock()
+
+	transport.Observer().On("close", func() {
+		p.locker.Lock()
+		defer p.locker.Unlock()
+
+		delete(p.transports, transport.Id())
+
+		if *slot == transport {
+			*slot = nil
+		}
+	})
+
+	return
+}
+
+/**
+ * Produce through the send transport, the other Peers consuming the Producer.
+ */
+func (p *Peer) Produce(options mediasoup.ProducerOptions) (producer *mediasoup.Producer, err error) {
+	p.locker.Lock()
+	transport := p.sendTransport
+	p.locker.Unlock()
+
+	if transport == nil {
+		return nil, mediasoup.NewInvalidStateError("no send transport")
+	}
+
+	if producer, err = transport.Produce(options); err != nil {
+		return
+	}
+
+	p.locker.Lock()
+	p.producers[producer.Id()] = producer
+	p.locker.Unlock()
+
+	producer.Observer().On("close", func() {
+		p.locker.Lock()
+		delete(p.producers, producer.Id())
+		p.locker.Unlock()
+	})
+
+	for _, other := range p.room.otherPeers(p) {
+		other.consume(p, producer)
+	}
+
+	return
+}
+
+/**
+ * Produce data through the send transport, the other Peers having SCTP
+ * capabilities consuming the DataProducer.
+ */
+func (p *Peer) ProduceData(options mediasoup.DataProducerOptions) (dataProducer *mediasoup.DataProducer, err error) {
+	p.locker.Lock()
+	transport := p.sendTransport
+	p.locker.Unlock()
+
+	if transport == nil {
+		return nil, mediasoup.NewInvalidStateError("no send transport")
+	}
+
+	if dataProducer, err = transport.ProduceData(options); err != nil {
+		return
+	}
+
+	p.locker.Lock()
+	p.dataProducers[dataProducer.Id()] = dataProducer
+	p.locker.Unlock()
+
+	dataProducer.Observer().On("close", func() {
+		p.locker.Lock()
+		delete(p.dataProducers, dataProducer.Id())
+		p.locker.Unlock()
+	})
+
+	for _, other := range p.room.otherPeers(p) {
+		other.consumeData(p, dataProducer)
+	}
+
+	return
+}
+
+// consume creates a paused Consumer of the Producer of another Peer, if the
+// Peer can consume it.
+func (p *Peer) consume(producerPeer *Peer, producer *mediasoup.Producer) {
+	p.locker.Lock()
+	transport := p.recvTransport
+	p.locker.Unlock()
+
+	if transport == nil || !p.room.router.CanConsume(producer.Id(), p.options.RtpCapabilities) {
+		return
+	}
+
+	consumer, err := transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: p.options.RtpCapabilities,
+		Paused:          true,
+	})
+	if err != nil {
+		p.room.logger.Warn("consume() | failed [peerId:%s, producerId:%s]: %v", p.id, producer.Id(), err)
+		return
+	}
+
+	p.locker.Lock()
+	p.consumers[consumer.Id()] = consumer
+	p.locker.Unlock()
+
+	consumer.Observer().On("close", func() {
+		p.locker.Lock()
+		delete(p.consumers, consumer.Id())
+		p.locker.Unlock()
+	})
+
+	p.SafeEmit("newconsumer", consumer, producerPeer)
+}
+
+// consumeData creates a DataConsumer of the DataProducer of another Peer, if
+// the Peer has SCTP capabilities.
+func (p *Peer) consumeData(producerPeer *Peer, dataProducer *mediasoup.DataProducer) {
+	p.locker.Lock()
+	transport := p.recvTransport
+	p.locker.Unlock()
+
+	if transport == nil || p.options.SctpCapabilities == nil {
+		return
+	}
+
+	dataConsumer, err := transport.ConsumeData(mediasoup.DataConsumerOptions{
+		DataProducerId: dataProducer.Id(),
+	})
+	if err != nil {
+		p.room.logger.Warn("consumeData() | failed [peerId:%s, dataProducerId:%s]: %v", p.id, dataProducer.Id(), err)
+		return
+	}
+
+	p.locker.Lock()
+	p.dataConsumers[dataConsumer.Id()] = dataConsumer
+	p.locker.Unlock()
+
+	dataConsumer.Observer().On("close", func() {
+		p.locker.Lock()
+		delete(p.dataConsumers, dataConsumer.Id())
+		p.locker.Unlock()
+	})
+
+	p.SafeEmit("newdataconsumer", dataConsumer, producerPeer)
+}
+
+/**
+ * Whether the Peer left the room.
+ */
+func (p *Peer) Left() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.left
+}
+
+/**
+ * Leave the room, closing the transports of the Peer.
+ */
+func (p *Peer) Leave() error {
+	p.locker.Lock()
+
+	if p.left {
+		p.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	p.room.logger.Debug("Leave() [peerId:%s]", p.id)
+
+	p.left = true
+
+	transports := make([]*mediasoup.WebRtcTransport, 0, len(p.transports))
+
+	for _, transport := range p.transports {
+		transports = append(transports, transport)
+	}
+
+	p.locker.Unlock()
+
+	for _, transport := range transports {
+		transport.Close()
+	}
+
+	p.room.remove(p)
+	p.SafeEmit("leave")
+
+	return nil
+}
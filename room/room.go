@@ -0,0 +1,235 @@
+// Package room is a conferencing layer on top of a Router, similar to the Room
+// of mediasoup-demo. Peers join with their capabilities, send media through a
+// send transport and receive the media of the other Peers through a receive
+// transport, the Consumers being created automatically:
+//
+//	r, err := room.NewRoom(room.Options{Id: "room1", Router: router})
+//	peer, err := r.Join(room.PeerOptions{Id: "alice", RtpCapabilities: caps})
+//	peer.On("newconsumer", func(consumer *mediasoup.Consumer, producerPeer *room.Peer) {
+//		// Signal the Consumer to the client, then resume it once it is ready.
+//		consumer.Resume()
+//	})
+//	sendTransport, err := peer.CreateSendTransport(transportOptions)
+//	recvTransport, err := peer.CreateRecvTransport(transportOptions)
+//	producer, err := peer.Produce(producerOptions)
+//	peer.Leave()
+//
+// Leaving closes the transports of the Peer, and so its Producers and
+// Consumers, and the Consumers of its Producers in the other Peers.
+package room
+
+import (
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type Options struct {
+	/**
+	 * Id of the room.
+	 */
+	Id string
+
+	/**
+	 * Router of the room. It is not closed with the room.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Logger of the room. Default mediasoup.NewLogger("Room").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Room
+ * @emits peerjoin - (peer *Peer)
+ * @emits peerleave - (peer *Peer)
+ * @emits close
+ */
+type Room struct {
+	mediasoup.IEventEmitter
+	logger mediasoup.Logger
+	id     string
+	router *mediasoup.Router
+	locker sync.Mutex
+	peers  map[string]*Peer
+	// Order of arrival of the peers.
+	peerIds []string
+	closed  bool
+}
+
+/**
+ * Create a room. It is closed with its Router.
+ */
+func NewRoom(options Options) (room *Room, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("Room")
+	}
+
+	room = &Room{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        options.Logger,
+		id:            options.Id,
+		router:        options.Router,
+		peers:         make(map[string]*Peer),
+	}
+
+	options.Router.Observer().On("close", func() {
+		go room.Close()
+	})
+
+	return
+}
+
+/**
+ * Room id.
+ */
+func (r *Room) Id() string {
+	return r.id
+}
+
+/**
+ * Router of the room.
+ */
+func (r *Room) Router() *mediasoup.Router {
+	return r.router
+}
+
+/**
+ * RTP capabilities to be given to the clients before joining.
+ */
+func (r *Room) RtpCapabilities() mediasoup.RtpCapabilities {
+	return r.router.RtpCapabilities()
+}
+
+/**
+ * Get the Peer with the given id, nil if none.
+ */
+func (r *Room) Peer(id string) *Peer {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return r.peers[id]
+}
+
+/**
+ * Peers in order of arrival.
+ */
+func (r *Room) Peers() []*Peer {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	peers := make([]*Peer, 0, len(r.peerIds))
+
+	for _, id := range r.peerIds {
+		peers = append(peers, r.peers[id])
+	}
+
+	return peers
+}
+
+/**
+ * Add a Peer to the room. The id must not be used by another Peer.
+ */
+func (r *Room) Join(options PeerOptions) (peer *Peer, err error) {
+	r.logger.Debug("Join() [peerId:%s]", options.Id)
+
+	if len(options.Id) == 0 {
+		return nil, mediasoup.NewTypeError("missing peer id")
+	}
+	if err = mediasoup.ValidateRtpCapabilities(&options.RtpCapabilities); err != nil {
+		return
+	}
+
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return nil, mediasoup.NewInvalidStateError("Room closed")
+	}
+	if _, ok := r.peers[options.Id]; ok {
+		r.locker.Unlock()
+		return nil, mediasoup.NewTypeError("peer %q already joined", options.Id)
+	}
+
+	peer = newPeer(r, options)
+	r.peers[peer.id] = peer
+	r.peerIds = append(r.peerIds, peer.id)
+
+	r.locker.Unlock()
+
+	r.SafeEmit("peerjoin", peer)
+
+	return
+}
+
+// remove removes the Peer once it left.
+func (r *Room) remove(peer *Peer) {
+	r.locker.Lock()
+
+	if r.peers[peer.id] != peer {
+		r.locker.Unlock()
+		return
+	}
+	delete(r.peers, peer.id)
+
+	for i, id := range r.peerIds {
+		if id == peer.id {
+			r.peerIds = append(r.peerIds[:i:i], r.peerIds[i+1:]...)
+			break
+		}
+	}
+
+	r.locker.Unlock()
+
+	r.SafeEmit("peerleave", peer)
+}
+
+// otherPeers returns the Peers other than the given one.
+func (r *Room) otherPeers(peer *Peer) (peers []*Peer) {
+	for _, other := range r.Peers() {
+		if other != peer {
+			peers = append(peers, other)
+		}
+	}
+	return
+}
+
+/**
+ * Whether the room is closed.
+ */
+func (r *Room) Closed() bool {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	return r.closed
+}
+
+/**
+ * Make every Peer leave the room.
+ */
+func (r *Room) Close() error {
+	r.locker.Lock()
+
+	if r.closed {
+		r.locker.Unlock()
+		return mediasoup.ErrAlreadyClosed
+	}
+	r.logger.Debug("Close()")
+
+	r.closed = true
+
+	r.locker.Unlock()
+
+	for _, peer := range r.Peers() {
+		peer.Leave()
+	}
+
+	r.SafeEmit("close")
+
+	return nil
+}
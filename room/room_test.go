@@ -0,0 +1,65 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRoom_InvalidOptions(t *testing.T) {
+	_, err := NewRoom(Options{Id: "room1"})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestRoom_Join(t *testing.T) {
+	room := &Room{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        mediasoup.NewLogger("Room"),
+		peers:         make(map[string]*Peer),
+	}
+
+	joined := make(chan string, 3)
+	left := make(chan string, 3)
+
+	room.On("peerjoin", func(peer *Peer) { joined <- peer.Id() })
+	room.On("peerleave", func(peer *Peer) { left <- peer.Id() })
+
+	_, err := room.Join(PeerOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	alice, err := room.Join(PeerOptions{Id: "alice", AppData: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", alice.AppData())
+	assert.Equal(t, room, alice.Room())
+
+	bob, err := room.Join(PeerOptions{Id: "bob"})
+	assert.NoError(t, err)
+
+	_, err = room.Join(PeerOptions{Id: "bob"})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	assert.Equal(t, []*Peer{alice, bob}, room.Peers())
+	assert.Equal(t, []*Peer{bob}, room.otherPeers(alice))
+	assert.Equal(t, bob, room.Peer("bob"))
+
+	_, err = alice.Produce(mediasoup.ProducerOptions{})
+	assert.IsType(t, mediasoup.InvalidStateError{}, err)
+
+	assert.NoError(t, alice.Leave())
+	assert.True(t, alice.Left())
+	assert.Equal(t, mediasoup.ErrAlreadyClosed, alice.Leave())
+	assert.Nil(t, room.Peer("alice"))
+
+	_, err = alice.CreateRecvTransport(mediasoup.WebRtcTransportOptions{})
+	assert.IsType(t, mediasoup.InvalidStateError{}, err)
+
+	assert.NoError(t, room.Close())
+	assert.True(t, bob.Left())
+	assert.Empty(t, room.Peers())
+	assert.ElementsMatch(t, []string{"alice", "bob"}, []string{<-joined, <-joined})
+	assert.ElementsMatch(t, []string{"alice", "bob"}, []string{<-left, <-left})
+
+	_, err = room.Join(PeerOptions{Id: "carol"})
+	assert.IsType(t, mediasoup.InvalidStateError{}, err)
+}
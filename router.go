@@ -1,11 +1,10 @@
 package mediasoup
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
-
-	uuid "github.com/satori/go.uuid"
 )
 
 type RouterOptions struct {
@@ -14,12 +13,34 @@ type RouterOptions struct {
 	 */
 	MediaCodecs []*RtpCodecCapability `json:"mediaCodecs,omitempty"`
 
+	/**
+	 * Base logger for the Router and the entities created in it. Default
+	 * the one given to the Worker. Use logger.With() to add fields (e.g. a room
+	 * id) to every log line.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
 	AppData interface{} `json:"appData,omitempty"`
 }
 
+type CodecPreferences struct {
+	/**
+	 * Mime types of the preferred codecs (e.g. "video/VP9"), the first one
+	 * being the most preferred. Codecs not listed keep their relative order
+	 * after them.
+	 */
+	MimeTypes []string `json:"mimeTypes,omitempty"`
+
+	/**
+	 * Remove the codecs not listed in MimeTypes whose kind is the same as any of
+	 * the listed ones. Default false.
+	 */
+	Exclusive bool `json:"exclusive,omitempty"`
+}
+
 type PipeToRouterOptions struct {
 	/**
 	 * The id of the Producer to consume.
@@ -93,10 +114,14 @@ type routerParams struct {
 	// 	routerId: string;
 	// };
 	internal       internalData
+	loggerContext  loggerContext
 	data           routerData
 	channel        *Channel
 	payloadChannel *PayloadChannel
 	appData        interface{}
+	idGenerator    IdGenerator
+	// Whether the Worker is draining, nil if it never does.
+	draining func() bool
 }
 
 /**
@@ -120,21 +145,35 @@ type Router struct {
 	mapRouterPipeTransports sync.Map
 	observer                IEventEmitter
 	locker                  sync.Mutex
+	// Guards data.RtpCapabilities.
+	dataLocker sync.RWMutex
+	// RTP capabilities given by the media codecs, before codec preferences.
+	mediaRtpCapabilities RtpCapabilities
+	// Logger context for the entities created in the Router.
+	loggerContext loggerContext
+	// Generator of the entity ids.
+	idGenerator IdGenerator
+	// Whether the Worker is draining.
+	draining func() bool
 }
 
 func newRouter(params routerParams) *Router {
-	logger := NewLogger("Router")
+	logger := params.loggerContext.newLogger("Router")
 	logger.Debug("constructor()")
 
 	return &Router{
-		IEventEmitter:  NewEventEmitter(),
-		logger:         logger,
-		internal:       params.internal,
-		data:           params.data,
-		channel:        params.channel,
-		payloadChannel: params.payloadChannel,
-		appData:        params.appData,
-		observer:       NewEventEmitter(),
+		IEventEmitter:        NewEventEmitter(),
+		logger:               logger,
+		internal:             params.internal,
+		data:                 params.data,
+		channel:              params.channel,
+		payloadChannel:       params.payloadChannel,
+		appData:              params.appData,
+		observer:             params.loggerContext.newObserver("router", params.internal.RouterId),
+		mediaRtpCapabilities: params.data.RtpCapabilities,
+		loggerContext:        params.loggerContext,
+		idGenerator:          params.idGenerator,
+		draining:             params.draining,
 	}
 }
 
@@ -148,41 +187,95 @@ func (router *Router) Closed() bool {
 	return atomic.LoadUint32(&router.closed) > 0
 }
 
+// checkDraining returns an InvalidStateError if the Worker is draining.
+func (router *Router) checkDraining() error {
+	if router.draining != nil && router.draining() {
+		return NewInvalidStateError("worker draining")
+	}
+	return nil
+}
+
+// App custom data.
+func (router *Router) AppData() interface{} {
+	return router.appData
+}
+
+// Labels of the Worker of the Router, see WorkerSettings.Labels. Must not be
+// modified.
+func (router *Router) Labels() map[string]string {
+	return router.loggerContext.labels
+}
+
 // RTC capabilities of the Router.
 func (router *Router) RtpCapabilities() RtpCapabilities {
+	router.dataLocker.RLock()
+	defer router.dataLocker.RUnlock()
+
 	return router.data.RtpCapabilities
 }
 
+/**
+ * Reorder and/or limit the codecs in the Router RTP capabilities without
+ * redefining the media codecs of the Router. Preferences are always applied
+ * to the codecs given in RouterOptions, so previous preferences are replaced.
+ * Payload types do not change. It affects Producers created and endpoints
+ * loading the Router RTP capabilities afterwards.
+ */
+func (router *Router) SetCodecPreferences(preferences CodecPreferences) (err error) {
+	router.logger.Debug("setCodecPreferences()")
+
+	rtpCapabilities, err := applyCodecPreferences(
+		router.mediaRtpCapabilities, preferences.MimeTypes, preferences.Exclusive)
+	if err != nil {
+		return
+	}
+
+	router.dataLocker.Lock()
+	defer router.dataLocker.Unlock()
+
+	router.data.RtpCapabilities = rtpCapabilities
+
+	return
+}
+
 func (router *Router) Observer() IEventEmitter {
 	return router.observer
 }
 
 // Close the Router.
-func (router *Router) Close() {
-	if atomic.CompareAndSwapUint32(&router.closed, 0, 1) {
-		router.logger.Debug("close()")
+func (router *Router) Close() (err error) {
+	if !atomic.CompareAndSwapUint32(&router.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		router.channel.Request("router.close", router.internal)
+	router.logger.Debug("close()")
 
-		// Close every Transport.
-		router.transports.Range(func(key, value interface{}) bool {
-			value.(ITransport).routerClosed()
-			return true
-		})
+	response := router.channel.Request("router.close", router.internal)
 
-		// Close every RtpObserver.
-		router.rtpObservers.Range(func(key, value interface{}) bool {
-			value.(IRtpObserver).routerClosed()
-			return true
-		})
+	if err = response.Err(); err != nil {
+		router.logger.Error("router close error: %s", err)
+	}
 
-		router.Emit("@close")
-		router.RemoveAllListeners()
+	// Close every Transport.
+	router.transports.Range(func(key, value interface{}) bool {
+		value.(ITransport).routerClosed()
+		return true
+	})
 
-		// Emit observer event.
-		router.observer.SafeEmit("close")
-		router.observer.RemoveAllListeners()
-	}
+	// Close every RtpObserver.
+	router.rtpObservers.Range(func(key, value interface{}) bool {
+		value.(IRtpObserver).routerClosed()
+		return true
+	})
+
+	router.Emit("@close")
+	router.RemoveAllListeners()
+
+	// Emit observer event.
+	router.observer.SafeEmit("close")
+	router.observer.RemoveAllListeners()
+
+	return
 }
 
 func (router *Router) workerClosed() {
@@ -194,20 +287,20 @@ func (router *Router) workerClosed() {
 			value.(ITransport).routerClosed()
 			return true
 		})
-		router.transports = sync.Map{}
+		clearSyncMap(&router.transports)
 
 		// Clear the Producers map.
-		router.producers = sync.Map{}
+		clearSyncMap(&router.producers)
 
 		// Close every RtpObserver.
 		router.rtpObservers.Range(func(key, value interface{}) bool {
 			value.(IRtpObserver).routerClosed()
 			return true
 		})
-		router.rtpObservers = sync.Map{}
+		clearSyncMap(&router.rtpObservers)
 
 		// Clear map of Router/PipeTransports.
-		router.mapRouterPipeTransports = sync.Map{}
+		clearSyncMap(&router.mapRouterPipeTransports)
 
 		router.Emit("workerclose")
 		router.RemoveAllListeners()
@@ -220,9 +313,16 @@ func (router *Router) workerClosed() {
 
 // Dump Router.
 func (router *Router) Dump() (data *RouterDump, err error) {
+	return router.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (router *Router) DumpContext(ctx context.Context) (data *RouterDump, err error) {
 	router.logger.Debug("dump()")
 
-	resp := router.channel.Request("router.dump", router.internal)
+	resp := router.channel.RequestContext(ctx, "router.dump", router.internal)
 	err = resp.Unmarshal(&data)
 
 	return
@@ -270,10 +370,51 @@ func (router *Router) Transports() []ITransport {
 	return transports
 }
 
+// AllProducers iterates over the producers of the Router, like Worker.AllRouters().
+func (router *Router) AllProducers() func(yield func(*Producer) bool) {
+	return func(yield func(*Producer) bool) {
+		router.producers.Range(func(key, value interface{}) bool {
+			return yield(value.(*Producer))
+		})
+	}
+}
+
+// AllDataProducers iterates over the data producers of the Router, like Worker.AllRouters().
+func (router *Router) AllDataProducers() func(yield func(*DataProducer) bool) {
+	return func(yield func(*DataProducer) bool) {
+		router.dataProducers.Range(func(key, value interface{}) bool {
+			return yield(value.(*DataProducer))
+		})
+	}
+}
+
+// AllTransports iterates over the transports of the Router, like Worker.AllRouters().
+func (router *Router) AllTransports() func(yield func(ITransport) bool) {
+	return func(yield func(ITransport) bool) {
+		router.transports.Range(func(key, value interface{}) bool {
+			return yield(value.(ITransport))
+		})
+	}
+}
+
 /**
  * Create a WebRtcTransport.
  */
-func (router *Router) CreateWebRtcTransport(option WebRtcTransportOptions) (transport *WebRtcTransport, err error) {
+func (router *Router) CreateWebRtcTransport(option WebRtcTransportOptions, opts ...WebRtcTransportOption) (transport *WebRtcTransport, err error) {
+	return router.CreateWebRtcTransportContext(context.Background(), option, opts...)
+}
+
+/**
+ * CreateWebRtcTransportContext is CreateWebRtcTransport with a context cancelling the worker request.
+ */
+func (router *Router) CreateWebRtcTransportContext(ctx context.Context, option WebRtcTransportOptions, opts ...WebRtcTransportOption) (transport *WebRtcTransport, err error) {
+	for _, opt := range opts {
+		opt.applyWebRtcTransport(&option)
+	}
+	if err = router.checkDraining(); err != nil {
+		return
+	}
+
 	options := &WebRtcTransportOptions{
 		EnableUdp:                       Bool(true),
 		InitialAvailableOutgoingBitrate: 600000,
@@ -287,8 +428,12 @@ func (router *Router) CreateWebRtcTransport(option WebRtcTransportOptions) (tran
 
 	router.logger.Debug("createWebRtcTransport()")
 
+	if options.ListenIps, err = resolveListenIps(ctx, options.ListenIps); err != nil {
+		return
+	}
+
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.idGenerator.newId("transport")
 	reqData := H{
 		"listenIps":                       options.ListenIps,
 		"enableUdp":                       options.EnableUdp,
@@ -303,14 +448,14 @@ func (router *Router) CreateWebRtcTransport(option WebRtcTransportOptions) (tran
 		"isDataChannel":                   true,
 	}
 
-	resp := router.channel.Request("router.createWebRtcTransport", internal, reqData)
+	resp := router.channel.requestCreation(ctx, "router.createWebRtcTransport", "transport.close", internal, reqData)
 
 	var data *webrtcTransportData
 	if err = resp.Unmarshal(&data); err != nil {
 		return
 	}
 
-	iTransport := router.createTransport(internal, data, options.AppData)
+	iTransport := router.createTransport(internal, data, options.AppData, options.Logger)
 
 	return iTransport.(*WebRtcTransport), nil
 }
@@ -318,7 +463,21 @@ func (router *Router) CreateWebRtcTransport(option WebRtcTransportOptions) (tran
 /**
  * Create a PlainTransport.
  */
-func (router *Router) CreatePlainTransport(option PlainTransportOptions) (transport *PlainTransport, err error) {
+func (router *Router) CreatePlainTransport(option PlainTransportOptions, opts ...PlainTransportOption) (transport *PlainTransport, err error) {
+	return router.CreatePlainTransportContext(context.Background(), option, opts...)
+}
+
+/**
+ * CreatePlainTransportContext is CreatePlainTransport with a context cancelling the worker request.
+ */
+func (router *Router) CreatePlainTransportContext(ctx context.Context, option PlainTransportOptions, opts ...PlainTransportOption) (transport *PlainTransport, err error) {
+	for _, opt := range opts {
+		opt.applyPlainTransport(&option)
+	}
+	if err = router.checkDraining(); err != nil {
+		return
+	}
+
 	options := &PlainTransportOptions{
 		RtcpMux:            Bool(true),
 		NumSctpStreams:     NumSctpStreams{OS: 1024, MIS: 1024},
@@ -332,8 +491,12 @@ func (router *Router) CreatePlainTransport(option PlainTransportOptions) (transp
 
 	router.logger.Debug("createPlainTransport()")
 
+	if options.ListenIp, err = resolveListenIp(ctx, options.ListenIp); err != nil {
+		return
+	}
+
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.idGenerator.newId("transport")
 	reqData := H{
 		"listenIp":           options.ListenIp,
 		"rtcpMux":            options.RtcpMux,
@@ -347,14 +510,14 @@ func (router *Router) CreatePlainTransport(option PlainTransportOptions) (transp
 		"srtpCryptoSuite":    options.SrtpCryptoSuite,
 	}
 
-	resp := router.channel.Request("router.createPlainTransport", internal, reqData)
+	resp := router.channel.requestCreation(ctx, "router.createPlainTransport", "transport.close", internal, reqData)
 
 	var data *plainTransportData
 	if err = resp.Unmarshal(&data); err != nil {
 		return
 	}
 
-	iTransport := router.createTransport(internal, data, options.AppData)
+	iTransport := router.createTransport(internal, data, options.AppData, options.Logger)
 
 	return iTransport.(*PlainTransport), nil
 }
@@ -362,7 +525,21 @@ func (router *Router) CreatePlainTransport(option PlainTransportOptions) (transp
 /**
  * Create a PipeTransport.
  */
-func (router *Router) CreatePipeTransport(option PipeTransportOptions) (transport *PipeTransport, err error) {
+func (router *Router) CreatePipeTransport(option PipeTransportOptions, opts ...PipeTransportOption) (transport *PipeTransport, err error) {
+	return router.CreatePipeTransportContext(context.Background(), option, opts...)
+}
+
+/**
+ * CreatePipeTransportContext is CreatePipeTransport with a context cancelling the worker request.
+ */
+func (router *Router) CreatePipeTransportContext(ctx context.Context, option PipeTransportOptions, opts ...PipeTransportOption) (transport *PipeTransport, err error) {
+	for _, opt := range opts {
+		opt.applyPipeTransport(&option)
+	}
+	if err = router.checkDraining(); err != nil {
+		return
+	}
+
 	options := &PipeTransportOptions{
 		NumSctpStreams:     NumSctpStreams{OS: 1024, MIS: 1024},
 		MaxSctpMessageSize: 268435456,
@@ -374,8 +551,12 @@ func (router *Router) CreatePipeTransport(option PipeTransportOptions) (transpor
 
 	router.logger.Debug("createPipeTransport()")
 
+	if options.ListenIp, err = resolveListenIp(ctx, options.ListenIp); err != nil {
+		return
+	}
+
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.idGenerator.newId("transport")
 	reqData := H{
 		"listenIp":           options.ListenIp,
 		"enableSctp":         options.EnableSctp,
@@ -387,14 +568,14 @@ func (router *Router) CreatePipeTransport(option PipeTransportOptions) (transpor
 		"enableSrtp":         options.EnableSrtp,
 	}
 
-	resp := router.channel.Request("router.createPipeTransport", internal, reqData)
+	resp := router.channel.requestCreation(ctx, "router.createPipeTransport", "transport.close", internal, reqData)
 
 	var data *pipeTransortData
 	if err = resp.Unmarshal(&data); err != nil {
 		return
 	}
 
-	iTransport := router.createTransport(internal, data, options.AppData)
+	iTransport := router.createTransport(internal, data, options.AppData, options.Logger)
 
 	return iTransport.(*PipeTransport), nil
 }
@@ -403,6 +584,16 @@ func (router *Router) CreatePipeTransport(option PipeTransportOptions) (transpor
  * Create a DirectTransport.
  */
 func (router *Router) CreateDirectTransport(params ...DirectTransportOptions) (transport *DirectTransport, err error) {
+	return router.CreateDirectTransportContext(context.Background(), params...)
+}
+
+/**
+ * CreateDirectTransportContext is CreateDirectTransport with a context cancelling the worker request.
+ */
+func (router *Router) CreateDirectTransportContext(ctx context.Context, params ...DirectTransportOptions) (transport *DirectTransport, err error) {
+	if err = router.checkDraining(); err != nil {
+		return
+	}
 	options := &DirectTransportOptions{
 		MaxMessageSize: 262144,
 	}
@@ -415,17 +606,17 @@ func (router *Router) CreateDirectTransport(params ...DirectTransportOptions) (t
 	router.logger.Debug("createDirectTransport()")
 
 	internal := router.internal
-	internal.TransportId = uuid.NewV4().String()
+	internal.TransportId = router.idGenerator.newId("transport")
 	reqData := H{"direct": true, "maxMessageSize": options.MaxMessageSize}
 
-	resp := router.channel.Request("router.createDirectTransport", internal, reqData)
+	resp := router.channel.requestCreation(ctx, "router.createDirectTransport", "transport.close", internal, reqData)
 
 	var data *directTransportData
 	if err = resp.Unmarshal(&data); err != nil {
 		return
 	}
 
-	iTransport := router.createTransport(internal, data, options.AppData)
+	iTransport := router.createTransport(internal, data, options.AppData, options.Logger)
 
 	return iTransport.(*DirectTransport), nil
 }
@@ -434,6 +625,13 @@ func (router *Router) CreateDirectTransport(params ...DirectTransportOptions) (t
  * Pipes the given Producer or DataProducer into another Router in same host.
  */
 func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRouterResult, err error) {
+	return router.PipeToRouterContext(context.Background(), option)
+}
+
+/**
+ * PipeToRouterContext is PipeToRouter with a context cancelling the worker requests.
+ */
+func (router *Router) PipeToRouterContext(ctx context.Context, option PipeToRouterOptions) (result *PipeToRouterResult, err error) {
 	options := &PipeToRouterOptions{
 		ListenIp: TransportListenIp{
 			Ip: "127.0.0.1",
@@ -522,16 +720,16 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
 			EnableRtx:      options.EnableRtx,
 			EnableSrtp:     options.EnableSrtp,
 		}
-		localPipeTransport, err = router.CreatePipeTransport(option)
+		localPipeTransport, err = router.CreatePipeTransportContext(ctx, option)
 		if err != nil {
 			return
 		}
-		remotePipeTransport, err = options.Router.CreatePipeTransport(option)
+		remotePipeTransport, err = options.Router.CreatePipeTransportContext(ctx, option)
 		if err != nil {
 			return
 		}
 
-		err = localPipeTransport.Connect(TransportConnectOptions{
+		err = localPipeTransport.ConnectContext(ctx, TransportConnectOptions{
 			Ip:             remotePipeTransport.Tuple().LocalIp,
 			Port:           remotePipeTransport.Tuple().LocalPort,
 			SrtpParameters: remotePipeTransport.SrtpParameters(),
@@ -539,7 +737,7 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
 		if err != nil {
 			return
 		}
-		err = remotePipeTransport.Connect(TransportConnectOptions{
+		err = remotePipeTransport.ConnectContext(ctx, TransportConnectOptions{
 			Ip:             localPipeTransport.Tuple().LocalIp,
 			Port:           localPipeTransport.Tuple().LocalPort,
 			SrtpParameters: localPipeTransport.SrtpParameters(),
@@ -578,14 +776,14 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
 			}
 		}()
 
-		pipeConsumer, err = localPipeTransport.Consume(ConsumerOptions{
+		pipeConsumer, err = localPipeTransport.ConsumeContext(ctx, ConsumerOptions{
 			ProducerId: options.ProducerId,
 		})
 		if err != nil {
 			return
 		}
 
-		pipeProducer, err = remotePipeTransport.Produce(ProducerOptions{
+		pipeProducer, err = remotePipeTransport.ProduceContext(ctx, ProducerOptions{
 			Id:            producer.Id(),
 			Kind:          pipeConsumer.Kind(),
 			RtpParameters: pipeConsumer.RtpParameters(),
@@ -606,9 +804,9 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
 		// so, sych the pipeProducer.
 		if pipeProducer.Paused() != producer.Paused() {
 			if producer.Paused() {
-				err = pipeProducer.Pause()
+				err = pipeProducer.PauseContext(ctx)
 			} else {
-				err = pipeProducer.Resume()
+				err = pipeProducer.ResumeContext(ctx)
 			}
 			if err != nil {
 				return
@@ -648,14 +846,14 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
 			}
 		}()
 
-		pipeDataConsumer, err = localPipeTransport.ConsumeData(DataConsumerOptions{
+		pipeDataConsumer, err = localPipeTransport.ConsumeDataContext(ctx, DataConsumerOptions{
 			DataProducerId: options.DataProducerId,
 		})
 		if err != nil {
 			return
 		}
 
-		pipeDataProducer, err = remotePipeTransport.ProduceData(DataProducerOptions{
+		pipeDataProducer, err = remotePipeTransport.ProduceDataContext(ctx, DataProducerOptions{
 			Id:                   dataProducer.Id(),
 			SctpStreamParameters: pipeDataConsumer.SctpStreamParameters(),
 			Label:                pipeDataConsumer.Label(),
@@ -694,6 +892,13 @@ func (router *Router) PipeToRouter(option PipeToRouterOptions) (result *PipeToRo
  * Create an AudioLevelObserver.
  */
 func (router *Router) CreateAudioLevelObserver(options ...func(o *AudioLevelObserverOptions)) (rtpObserver IRtpObserver, err error) {
+	return router.CreateAudioLevelObserverContext(context.Background(), options...)
+}
+
+/**
+ * CreateAudioLevelObserverContext is CreateAudioLevelObserver with a context cancelling the worker request.
+ */
+func (router *Router) CreateAudioLevelObserverContext(ctx context.Context, options ...func(o *AudioLevelObserverOptions)) (rtpObserver IRtpObserver, err error) {
 	router.logger.Debug("createAudioLevelObserver()")
 
 	defaultOptions := NewAudioLevelObserverOptions()
@@ -703,9 +908,9 @@ func (router *Router) CreateAudioLevelObserver(options ...func(o *AudioLevelObse
 	}
 
 	internal := router.internal
-	internal.RtpObserverId = uuid.NewV4().String()
+	internal.RtpObserverId = router.idGenerator.newId("rtpObserver")
 
-	resp := router.channel.Request("router.createAudioLevelObserver", internal, defaultOptions)
+	resp := router.channel.requestCreation(ctx, "router.createAudioLevelObserver", "rtpObserver.close", internal, defaultOptions)
 
 	if err = resp.Err(); err != nil {
 		return
@@ -713,6 +918,7 @@ func (router *Router) CreateAudioLevelObserver(options ...func(o *AudioLevelObse
 
 	rtpObserver = newAudioLevelObserver(rtpObserverParams{
 		internal:       internal,
+		loggerContext:  router.loggerContext.with(nil, "rtpObserverId", internal.RtpObserverId),
 		channel:        router.channel,
 		payloadChannel: router.payloadChannel,
 		appData:        router.appData,
@@ -758,7 +964,7 @@ func (router *Router) CanConsume(producerId string, rtpCapabilities RtpCapabilit
 /**
  * Create a Transport interface.
  */
-func (router *Router) createTransport(internal internalData, data, appData interface{}) (transport ITransport) {
+func (router *Router) createTransport(internal internalData, data, appData interface{}, logger Logger) (transport ITransport) {
 	if appData == nil {
 		appData = H{}
 	}
@@ -781,12 +987,14 @@ func (router *Router) createTransport(internal internalData, data, appData inter
 
 	transport = newTransport(transportParams{
 		internal:       internal,
+		loggerContext:  router.loggerContext.with(logger, "transportId", internal.TransportId),
 		channel:        router.channel,
 		payloadChannel: router.payloadChannel,
 		data:           data,
 		appData:        appData,
+		idGenerator:    router.idGenerator,
 		getRouterRtpCapabilities: func() RtpCapabilities {
-			return router.data.RtpCapabilities
+			return router.RtpCapabilities()
 		},
 		getProducerById: func(producerId string) *Producer {
 			if producer, ok := router.producers.Load(producerId); ok {
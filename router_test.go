@@ -3,7 +3,6 @@ package mediasoup
 import (
 	"testing"
 
-	"github.com/jiyeyuran/mediasoup-go/h264"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +13,7 @@ var testRouterMediaCodecs = []*RtpCodecCapability{
 		ClockRate: 48000,
 		Channels:  2,
 		Parameters: RtpCodecSpecificParameters{
-			Useinbandfec: 1,
+			{Key: "useinbandfec", Value: 1},
 		},
 	},
 	{
@@ -27,11 +26,9 @@ var testRouterMediaCodecs = []*RtpCodecCapability{
 		MimeType:  "video/H264",
 		ClockRate: 90000,
 		Parameters: RtpCodecSpecificParameters{
-			RtpParameter: h264.RtpParameter{
-				LevelAsymmetryAllowed: 1,
-				PacketizationMode:     1,
-				ProfileLevelId:        "4d0032",
-			},
+			{Key: "level-asymmetry-allowed", Value: 1},
+			{Key: "packetization-mode", Value: 1},
+			{Key: "profile-level-id", Value: "4d0032"},
 		},
 	},
 }
@@ -110,3 +107,30 @@ func TestRouterEmitsWorkCloseIfWorkerIsClosed(t *testing.T) {
 	onObserverClose.ExpectCalled()
 	assert.True(t, router.Closed())
 }
+
+func TestRouterSetCodecPreferences_Succeeds(t *testing.T) {
+	worker := CreateTestWorker()
+	defer worker.Close()
+
+	router, _ := worker.CreateRouter(RouterOptions{
+		MediaCodecs: testRouterMediaCodecs,
+	})
+
+	err := router.SetCodecPreferences(CodecPreferences{
+		MimeTypes: []string{"video/H264"},
+		Exclusive: true,
+	})
+	assert.NoError(t, err)
+
+	codecs := router.RtpCapabilities().Codecs
+
+	assert.Len(t, codecs, 3)
+	assert.Equal(t, "audio/opus", codecs[0].MimeType)
+	assert.Equal(t, "video/H264", codecs[1].MimeType)
+	assert.Equal(t, "video/rtx", codecs[2].MimeType)
+
+	err = router.SetCodecPreferences(CodecPreferences{
+		MimeTypes: []string{"video/VP9"},
+	})
+	assert.IsType(t, NewTypeError(""), err)
+}
@@ -0,0 +1,220 @@
+// Package rtmp pushes an audio and a video Producer to a RTMP endpoint
+// (YouTube, Twitch, etc.) with FFmpeg:
+//
+//	pusher, err := rtmp.NewPusher(rtmp.Options{
+//		Router:        router,
+//		AudioProducer: audioProducer,
+//		VideoProducer: videoProducer,
+//		Url:           "rtmp://a.rtmp.youtube.com/live2/" + streamKey,
+//	})
+//	pusher.On("restart", func(attempt int) { log.Println("reconnected", attempt) })
+//	pusher.On("close", func() { notifyStreamEnded() })
+//
+// Every Producer is consumed through a PlainTransport sending RTP to FFmpeg,
+// which reads a generated SDP. H264 is sent as is, other video codecs are
+// transcoded to H264, and audio is transcoded to AAC. Keyframes are produced
+// every KeyFrameInterval, as required by the streaming platforms: they are
+// forced when transcoding, and requested to the video Producer otherwise.
+// FFmpeg is restarted, reconnecting to the endpoint, when it exits
+// unexpectedly, and the pusher is closed when a Producer or the Router is
+// closed.
+package rtmp
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/internal/ffmpeg"
+)
+
+/**
+ * ErrTooManyRestarts is given to the "error" listeners when FFmpeg exited
+ * more than MaxRestarts times. The pusher is closed afterwards.
+ */
+var ErrTooManyRestarts = ffmpeg.ErrTooManyRestarts
+
+/**
+ * ProcessError describes an unexpected exit of FFmpeg (e.g. the connection to
+ * the endpoint was lost), given to the "error" listeners.
+ */
+type ProcessError = ffmpeg.ProcessError
+
+type Options struct {
+	/**
+	 * Router of the Producers.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * Audio Producer. Optional, though most platforms require audio.
+	 */
+	AudioProducer *mediasoup.Producer
+
+	/**
+	 * Video Producer.
+	 */
+	VideoProducer *mediasoup.Producer
+
+	/**
+	 * URL of the endpoint, including the stream key (e.g.
+	 * "rtmp://live.twitch.tv/app/<stream key>"). "rtmps" URLs are supported if
+	 * FFmpeg is built with TLS.
+	 */
+	Url string
+
+	/**
+	 * Interval between two keyframes. Default 2 seconds.
+	 */
+	KeyFrameInterval time.Duration
+
+	/**
+	 * Path of the FFmpeg binary. Default "ffmpeg".
+	 */
+	FFmpegPath string
+
+	/**
+	 * Additional output arguments (e.g. "-b:v", "4500k"), given before the
+	 * FLV ones.
+	 */
+	FFmpegArgs []string
+
+	/**
+	 * IP on which FFmpeg receives the RTP streams. Default "127.0.0.1".
+	 */
+	ListenIp string
+
+	/**
+	 * Number of times FFmpeg is restarted, reconnecting to the endpoint, after
+	 * exiting unexpectedly. Default 10, never if negative.
+	 */
+	MaxRestarts int
+
+	/**
+	 * Delay before restarting FFmpeg. Default 2 seconds.
+	 */
+	RestartDelay time.Duration
+
+	/**
+	 * Logger of the pusher. Default mediasoup.NewLogger("RtmpPusher").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Pusher
+ * @emits start - (pid int)
+ * @emits restart - (attempt int)
+ * @emits error - (err error)
+ * @emits close
+ */
+type Pusher struct {
+	*ffmpeg.Pipeline
+}
+
+/**
+ * Start pushing the given Producers.
+ */
+func NewPusher(options Options) (*Pusher, error) {
+	if options.VideoProducer == nil {
+		return nil, mediasoup.NewTypeError("missing VideoProducer")
+	}
+	if options.VideoProducer.Kind() != mediasoup.MediaKind_Video {
+		return nil, mediasoup.NewTypeError("VideoProducer is not a video Producer")
+	}
+	if options.AudioProducer != nil && options.AudioProducer.Kind() != mediasoup.MediaKind_Audio {
+		return nil, mediasoup.NewTypeError("AudioProducer is not an audio Producer")
+	}
+	if !strings.HasPrefix(options.Url, "rtmp://") && !strings.HasPrefix(options.Url, "rtmps://") {
+		return nil, mediasoup.NewTypeError("invalid Url %q", options.Url)
+	}
+
+	options = withDefaults(options)
+	producers := []*mediasoup.Producer{options.VideoProducer}
+
+	if options.AudioProducer != nil {
+		producers = append(producers, options.AudioProducer)
+	}
+
+	var keyFrameInterval time.Duration
+
+	// The keyframes of copied H264 come from the Producer.
+	if codecs := options.VideoProducer.RtpParameters().Codecs; len(codecs) > 0 &&
+		strings.EqualFold(codecs[0].MimeType, "video/h264") {
+		keyFrameInterval = options.KeyFrameInterval
+	}
+
+	pipeline, err := ffmpeg.NewPipeline(ffmpeg.Options{
+		Router:    options.Router,
+		Producers: producers,
+		Args: func(sdpPath, videoMimeType string) []string {
+			return ffmpegArgs(options, sdpPath, videoMimeType)
+		},
+		FFmpegPath:       options.FFmpegPath,
+		ListenIp:         options.ListenIp,
+		MaxRestarts:      options.MaxRestarts,
+		RestartDelay:     options.RestartDelay,
+		KeyFrameInterval: keyFrameInterval,
+		Logger:           options.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pusher{Pipeline: pipeline}, nil
+}
+
+func withDefaults(options Options) Options {
+	if options.KeyFrameInterval <= 0 {
+		options.KeyFrameInterval = 2 * time.Second
+	}
+	if len(options.FFmpegPath) == 0 {
+		options.FFmpegPath = "ffmpeg"
+	}
+	if len(options.ListenIp) == 0 {
+		options.ListenIp = "127.0.0.1"
+	}
+	if options.MaxRestarts == 0 {
+		options.MaxRestarts = 10
+	}
+	if options.RestartDelay <= 0 {
+		options.RestartDelay = 2 * time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("RtmpPusher")
+	}
+
+	return options
+}
+
+// ffmpegArgs returns the arguments of FFmpeg reading the given SDP file and
+// pushing FLV to the endpoint.
+func ffmpegArgs(options Options, sdpPath string, videoMimeType string) []string {
+	interval := strconv.FormatFloat(options.KeyFrameInterval.Seconds(), 'f', -1, 64)
+
+	args := []string{
+		"-loglevel", "warning",
+		"-nostdin",
+		"-protocol_whitelist", "file,udp,rtp",
+		"-fflags", "+genpts",
+		"-i", sdpPath,
+	}
+
+	if strings.EqualFold(videoMimeType, "video/h264") {
+		args = append(args, "-c:v", "copy")
+	} else {
+		args = append(args,
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-tune", "zerolatency",
+			"-pix_fmt", "yuv420p",
+			"-force_key_frames", "expr:gte(t,n_forced*"+interval+")",
+		)
+	}
+
+	args = append(args, "-c:a", "aac", "-b:a", "128k", "-ar", "48000")
+	args = append(args, options.FFmpegArgs...)
+
+	return append(args, "-f", "flv", options.Url)
+}
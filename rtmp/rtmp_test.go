@@ -0,0 +1,41 @@
+package rtmp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFmpegArgs(t *testing.T) {
+	options := withDefaults(Options{Url: "rtmp://live.twitch.tv/app/key"})
+
+	args := strings.Join(ffmpegArgs(options, "/tmp/in.sdp", "video/H264"), " ")
+	assert.Contains(t, args, "-i /tmp/in.sdp -c:v copy")
+	assert.Contains(t, args, "-c:a aac")
+	assert.True(t, strings.HasSuffix(args, "-f flv rtmp://live.twitch.tv/app/key"))
+
+	options.FFmpegArgs = []string{"-b:v", "4500k"}
+
+	args = strings.Join(ffmpegArgs(options, "/tmp/in.sdp", "video/VP8"), " ")
+	assert.Contains(t, args, "-c:v libx264")
+	assert.Contains(t, args, "expr:gte(t,n_forced*2)")
+	assert.Contains(t, args, "-b:v 4500k -f flv")
+}
+
+func TestWithDefaults(t *testing.T) {
+	options := withDefaults(Options{})
+	assert.Equal(t, 2*time.Second, options.KeyFrameInterval)
+	assert.Equal(t, "ffmpeg", options.FFmpegPath)
+	assert.Equal(t, "127.0.0.1", options.ListenIp)
+	assert.Equal(t, 10, options.MaxRestarts)
+	assert.Equal(t, 2*time.Second, options.RestartDelay)
+	assert.NotNil(t, options.Logger)
+}
+
+func TestNewPusher_InvalidOptions(t *testing.T) {
+	_, err := NewPusher(Options{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 )
@@ -12,12 +13,16 @@ type IRtpObserver interface {
 	Closed() bool
 	Paused() bool
 	Observer() IEventEmitter
-	Close()
+	Close() error
 	routerClosed()
 	Pause()
+	PauseContext(ctx context.Context) error
 	Resume()
+	ResumeContext(ctx context.Context) error
 	AddProducer(producerId string)
+	AddProducerContext(ctx context.Context, producerId string) error
 	RemoveProducer(producerId string)
+	RemoveProducerContext(ctx context.Context, producerId string) error
 }
 
 /**
@@ -46,10 +51,11 @@ type rtpObserverParams struct {
 	payloadChannel  *PayloadChannel
 	appData         interface{}
 	getProducerById func(string) *Producer
+	loggerContext   loggerContext
 }
 
 func newRtpObserver(params rtpObserverParams) IRtpObserver {
-	logger := NewLogger("RtpObserver")
+	logger := params.loggerContext.newLogger("RtpObserver")
 
 	logger.Debug("constructor()")
 
@@ -63,7 +69,7 @@ func newRtpObserver(params rtpObserverParams) IRtpObserver {
 		payloadChannel:  params.payloadChannel,
 		appData:         params.appData,
 		getProducerById: params.getProducerById,
-		observer:        NewEventEmitter(),
+		observer:        params.loggerContext.newObserver("rtpobserver", params.internal.RtpObserverId),
 	}
 }
 
@@ -114,23 +120,31 @@ func (o *RtpObserver) Observer() IEventEmitter {
 /**
  * Close the RtpObserver.
  */
-func (o *RtpObserver) Close() {
-	if atomic.CompareAndSwapUint32(&o.closed, 0, 1) {
-		o.logger.Debug("close()")
+func (o *RtpObserver) Close() (err error) {
+	if !atomic.CompareAndSwapUint32(&o.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-		// Remove notification subscriptions.
-		o.channel.RemoveAllListeners(o.internal.RtpObserverId)
-		o.payloadChannel.RemoveAllListeners(o.internal.RtpObserverId)
+	o.logger.Debug("close()")
 
-		o.channel.Request("rtpObserver.close", o.internal)
+	// Remove notification subscriptions.
+	o.channel.RemoveAllListeners(o.internal.RtpObserverId)
+	o.payloadChannel.RemoveAllListeners(o.internal.RtpObserverId)
 
-		o.Emit("@close")
-		o.RemoveAllListeners()
+	response := o.channel.Request("rtpObserver.close", o.internal)
 
-		// Emit observer event.
-		o.observer.SafeEmit("close")
-		o.observer.RemoveAllListeners()
+	if err = response.Err(); err != nil {
+		o.logger.Error("rtpObserver close error: %s", err)
 	}
+
+	o.Emit("@close")
+	o.RemoveAllListeners()
+
+	// Emit observer event.
+	o.observer.SafeEmit("close")
+	o.observer.RemoveAllListeners()
+
+	return
 }
 
 /**
@@ -157,6 +171,13 @@ func (o *RtpObserver) routerClosed() {
  * Pause the RtpObserver.
  */
 func (o *RtpObserver) Pause() {
+	o.PauseContext(context.Background())
+}
+
+/**
+ * PauseContext is Pause with a context cancelling the worker request.
+ */
+func (o *RtpObserver) PauseContext(ctx context.Context) (err error) {
 	o.locker.Lock()
 	defer o.locker.Unlock()
 
@@ -164,7 +185,9 @@ func (o *RtpObserver) Pause() {
 
 	wasPaused := o.paused
 
-	o.channel.Request("rtpObserver.pause", o.internal)
+	if err = o.channel.RequestContext(ctx, "rtpObserver.pause", o.internal).Err(); err != nil {
+		return
+	}
 
 	o.paused = true
 
@@ -172,12 +195,21 @@ func (o *RtpObserver) Pause() {
 	if !wasPaused {
 		o.observer.SafeEmit("pause")
 	}
+
+	return
 }
 
 /**
  * Resume the RtpObserver.
  */
 func (o *RtpObserver) Resume() {
+	o.ResumeContext(context.Background())
+}
+
+/**
+ * ResumeContext is Resume with a context cancelling the worker request.
+ */
+func (o *RtpObserver) ResumeContext(ctx context.Context) (err error) {
 	o.locker.Lock()
 	defer o.locker.Unlock()
 
@@ -185,7 +217,9 @@ func (o *RtpObserver) Resume() {
 
 	wasPaused := o.paused
 
-	o.channel.Request("rtpObserver.resume", o.internal)
+	if err = o.channel.RequestContext(ctx, "rtpObserver.resume", o.internal).Err(); err != nil {
+		return
+	}
 
 	o.paused = false
 
@@ -193,12 +227,22 @@ func (o *RtpObserver) Resume() {
 	if wasPaused {
 		o.observer.SafeEmit("resume")
 	}
+
+	return
 }
 
 /**
  * Add a Producer to the RtpObserver.
  */
 func (o *RtpObserver) AddProducer(producerId string) {
+	o.AddProducerContext(context.Background(), producerId)
+}
+
+/**
+ * AddProducerContext is AddProducer with a context cancelling the worker
+ * request.
+ */
+func (o *RtpObserver) AddProducerContext(ctx context.Context, producerId string) (err error) {
 	o.locker.Lock()
 	defer o.locker.Unlock()
 
@@ -208,16 +252,28 @@ func (o *RtpObserver) AddProducer(producerId string) {
 	internal := o.internal
 	internal.ProducerId = producerId
 
-	o.channel.Request("rtpObserver.addProducer", internal)
+	if err = o.channel.RequestContext(ctx, "rtpObserver.addProducer", internal).Err(); err != nil {
+		return
+	}
 
 	// Emit observer event.
 	o.observer.SafeEmit("addproducer", producer)
+
+	return
 }
 
 /**
  * Remove a Producer from the RtpObserver.
  */
 func (o *RtpObserver) RemoveProducer(producerId string) {
+	o.RemoveProducerContext(context.Background(), producerId)
+}
+
+/**
+ * RemoveProducerContext is RemoveProducer with a context cancelling the worker
+ * request.
+ */
+func (o *RtpObserver) RemoveProducerContext(ctx context.Context, producerId string) (err error) {
 	o.locker.Lock()
 	defer o.locker.Unlock()
 
@@ -227,8 +283,12 @@ func (o *RtpObserver) RemoveProducer(producerId string) {
 	internal := o.internal
 	internal.ProducerId = producerId
 
-	o.channel.Request("rtpObserver.removeProducer", internal)
+	if err = o.channel.RequestContext(ctx, "rtpObserver.removeProducer", internal).Err(); err != nil {
+		return
+	}
 
 	// Emit observer event.
 	o.observer.SafeEmit("removeproducer", producer)
+
+	return
 }
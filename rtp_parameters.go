@@ -1,9 +1,15 @@
 package mediasoup
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/jiyeyuran/mediasoup-go/h264"
+	"github.com/jiyeyuran/mediasoup-go/h265"
 )
 
 /**
@@ -86,7 +92,7 @@ type RtpCodecCapability struct {
 	 * and 'profile-level-id' in H264 or 'profile-id' in VP9) are critical for
 	 * codec matching.
 	 */
-	Parameters RtpCodecSpecificParameters `json:"parameters,omitempty"`
+	Parameters RtpCodecSpecificParameters `json:"parameters"`
 
 	/**
 	 * Transport layer and codec-specific feedback messages for this codec.
@@ -98,6 +104,10 @@ func (r RtpCodecCapability) isRtxCodec() bool {
 	return strings.HasSuffix(strings.ToLower(r.MimeType), "/rtx")
 }
 
+func (r RtpCodecCapability) isFecCodec() bool {
+	return isFecMimeType(r.MimeType)
+}
+
 /**
  * Direction of RTP header extension.
  */
@@ -242,7 +252,7 @@ type RtpCodecParameters struct {
 	 * as 'packetization-mode' and 'profile-level-id' in H264 or 'profile-id' in
 	 * VP9) are critical for codec matching.
 	 */
-	Parameters RtpCodecSpecificParameters `json:"parameters,omitempty"`
+	Parameters RtpCodecSpecificParameters `json:"parameters"`
 
 	/**
 	 * Transport layer and codec-specific feedback messages for this codec.
@@ -254,25 +264,304 @@ func (r RtpCodecParameters) isRtxCodec() bool {
 	return strings.HasSuffix(strings.ToLower(r.MimeType), "/rtx")
 }
 
+func (r RtpCodecParameters) isFecCodec() bool {
+	return isFecMimeType(r.MimeType)
+}
+
+// isFecMimeType tells whether the given codec carries redundancy (RED) or
+// forward error correction (ULPFEC, FlexFEC) data instead of media.
+func isFecMimeType(mimeType string) bool {
+	switch strings.ToLower(mimeType) {
+	case "audio/red", "video/red", "video/ulpfec", "video/flexfec-03":
+		return true
+	}
+	return false
+}
+
 /**
  * RtpCodecSpecificParameters the Codec-specific parameters available for signaling. Some parameters (such
  * as 'packetization-mode' and 'profile-level-id' in H264 or 'profile-id' in
  * VP9) are critical for codec matching.
+ *
+ * It is an ordered key/value map: the parameters keep the order they were set
+ * (or unmarshaled) in, zero values included. Integer numbers are int once set
+ * or unmarshaled, other numbers float64.
+ */
+type RtpCodecSpecificParameters []CodecParameter
+
+/**
+ * CodecParameter is a single codec specific parameter (e.g. a fmtp key/value
+ * pair).
+ */
+type CodecParameter struct {
+	Key   string
+	Value interface{}
+}
+
+func (p RtpCodecSpecificParameters) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 16*len(p)+2))
+	buf.WriteByte('{')
+
+	for i, param := range p {
+		key, _ := json.Marshal(param.Key)
+		value, err := json.Marshal(param.Value)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (p *RtpCodecSpecificParameters) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*p = nil
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return &json.UnmarshalTypeError{Value: fmt.Sprint(token), Type: reflect.TypeOf(p).Elem()}
+	}
+
+	var params RtpCodecSpecificParameters
+
+	for decoder.More() {
+		if token, err = decoder.Token(); err != nil {
+			return err
+		}
+		var value interface{}
+
+		if err = decoder.Decode(&value); err != nil {
+			return err
+		}
+		params.Set(token.(string), value)
+	}
+	*p = params
+
+	return nil
+}
+
+/**
+ * Get returns the value of the given parameter.
+ */
+func (p RtpCodecSpecificParameters) Get(key string) (value interface{}, ok bool) {
+	if i := p.index(key); i >= 0 {
+		return p[i].Value, true
+	}
+	return
+}
+
+/**
+ * Has tells whether the given parameter is present.
+ */
+func (p RtpCodecSpecificParameters) Has(key string) bool {
+	return p.index(key) >= 0
+}
+
+/**
+ * GetString returns the given parameter as string, or "" if not present.
+ */
+func (p RtpCodecSpecificParameters) GetString(key string) string {
+	value, ok := p.Get(key)
+	if !ok {
+		return ""
+	}
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return fmt.Sprint(value)
+}
+
+/**
+ * GetInt returns the given parameter as int, or 0 if not present or not
+ * numeric.
+ */
+func (p RtpCodecSpecificParameters) GetInt(key string) int {
+	value, ok := p.Get(key)
+	if !ok {
+		return 0
+	}
+
+	switch v := normalizeCodecParameter(value).(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+
+	return 0
+}
+
+/**
+ * Set sets the value of the given parameter, appended if not present yet. The
+ * parameters are copied, the ones sharing them being left unchanged.
+ */
+func (p *RtpCodecSpecificParameters) Set(key string, value interface{}) {
+	params := make(RtpCodecSpecificParameters, len(*p), len(*p)+1)
+	copy(params, *p)
+
+	value = normalizeCodecParameter(value)
+
+	if i := params.index(key); i >= 0 {
+		params[i].Value = value
+	} else {
+		params = append(params, CodecParameter{Key: key, Value: value})
+	}
+	*p = params
+}
+
+/**
+ * Delete removes the given parameter. The parameters are copied, the ones
+ * sharing them being left unchanged.
+ */
+func (p *RtpCodecSpecificParameters) Delete(key string) {
+	i := p.index(key)
+	if i < 0 {
+		return
+	}
+	if len(*p) == 1 {
+		*p = nil
+		return
+	}
+	params := make(RtpCodecSpecificParameters, 0, len(*p)-1)
+
+	*p = append(append(params, (*p)[:i]...), (*p)[i+1:]...)
+}
+
+/**
+ * Keys returns the keys of the parameters, in order.
+ */
+func (p RtpCodecSpecificParameters) Keys() []string {
+	keys := make([]string, 0, len(p))
+
+	for _, param := range p {
+		keys = append(keys, param.Key)
+	}
+	return keys
+}
+
+func (p RtpCodecSpecificParameters) index(key string) int {
+	for i, param := range p {
+		if param.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeCodecParameter converts the integer json and Go numbers into int,
+// the other numbers into float64.
+func normalizeCodecParameter(value interface{}) interface{} {
+	if number, ok := value.(json.Number); ok {
+		if i, err := strconv.ParseInt(string(number), 10, 0); err == nil {
+			return int(i)
+		}
+		f, _ := number.Float64()
+		return f
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+
+	return value
+}
+
+// h264 returns the h264 parameters of the codec.
+func (p RtpCodecSpecificParameters) h264() h264.RtpParameter {
+	return h264.RtpParameter{
+		PacketizationMode:     p.GetInt("packetization-mode"),
+		ProfileLevelId:        p.GetString("profile-level-id"),
+		LevelAsymmetryAllowed: p.GetInt("level-asymmetry-allowed"),
+	}
+}
+
+// h265 returns the h265 parameters of the codec.
+func (p RtpCodecSpecificParameters) h265() h265.CodecParameters {
+	return h265.CodecParameters{
+		TierFlag: p.GetInt("tier-flag"),
+		LevelId:  p.GetInt("level-id"),
+		TxMode:   p.GetString("tx-mode"),
+	}
+}
+
+/**
+ * OpusParameters are the Opus parameters of a codec (RFC 7587), read with
+ * RtpCodecSpecificParameters.Opus() and written with SetOpus(). A zero field
+ * means the parameter is not present, 0 being the default of the flags.
+ */
+type OpusParameters struct {
+	Stereo            uint8  // 1 or 0
+	SpropStereo       uint8  // 1 or 0
+	Useinbandfec      uint8  // 1 or 0
+	Usedtx            uint8  // 1 or 0
+	Maxplaybackrate   uint32 // Hz
+	Maxaveragebitrate uint32 // bps
+	Ptime             uint32 // ms
+	Cbr               uint8  // 1 or 0
+}
+
+// opusParameterKeys are the keys of the OpusParameters fields, in order.
+var opusParameterKeys = []string{
+	"stereo", "sprop-stereo", "useinbandfec", "usedtx",
+	"maxplaybackrate", "maxaveragebitrate", "ptime", "cbr",
+}
+
+/**
+ * Opus returns the Opus parameters of the codec.
+ */
+func (p RtpCodecSpecificParameters) Opus() OpusParameters {
+	return OpusParameters{
+		Stereo:            uint8(p.GetInt("stereo")),
+		SpropStereo:       uint8(p.GetInt("sprop-stereo")),
+		Useinbandfec:      uint8(p.GetInt("useinbandfec")),
+		Usedtx:            uint8(p.GetInt("usedtx")),
+		Maxplaybackrate:   uint32(p.GetInt("maxplaybackrate")),
+		Maxaveragebitrate: uint32(p.GetInt("maxaveragebitrate")),
+		Ptime:             uint32(p.GetInt("ptime")),
+		Cbr:               uint8(p.GetInt("cbr")),
+	}
+}
+
+/**
+ * SetOpus sets the Opus parameters of the codec, the zero fields being
+ * removed. The other parameters are left unchanged and the parameters are
+ * copied, the ones sharing them being left unchanged.
  */
-type RtpCodecSpecificParameters struct {
-	h264.RtpParameter          // used by h264 codec
-	ProfileId           string `json:"profile-id,omitempty"`   // used by vp9
-	Apt                 byte   `json:"apt,omitempty"`          // used by rtx codec
-	SpropStereo         uint8  `json:"sprop-stereo,omitempty"` // used by audio, 1 or 0
-	Useinbandfec        uint8  `json:"useinbandfec,omitempty"` // used by audio, 1 or 0
-	Usedtx              uint8  `json:"usedtx,omitempty"`       // used by audio, 1 or 0
-	Maxplaybackrate     uint32 `json:"maxplaybackrate,omitempty"`
-	XGoogleMinBitrate   uint32 `json:"x-google-min-bitrate,omitempty"`
-	XGoogleMaxBitrate   uint32 `json:"x-google-max-bitrate,omitempty"`
-	XGoogleStartBitrate uint32 `json:"x-google-start-bitrate,omitempty"`
-	ChannelMapping      string `json:"channel_mapping,omitempty"`
-	NumStreams          uint8  `json:"num_streams,omitempty"`
-	CoupledStreams      uint8  `json:"coupled_streams,omitempty"`
+func (p *RtpCodecSpecificParameters) SetOpus(opus OpusParameters) {
+	values := []uint32{
+		uint32(opus.Stereo), uint32(opus.SpropStereo), uint32(opus.Useinbandfec), uint32(opus.Usedtx),
+		opus.Maxplaybackrate, opus.Maxaveragebitrate, opus.Ptime, uint32(opus.Cbr),
+	}
+
+	for i, key := range opusParameterKeys {
+		if values[i] > 0 {
+			p.Set(key, values[i])
+		} else {
+			p.Delete(key)
+		}
+	}
 }
 
 /**
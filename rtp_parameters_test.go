@@ -0,0 +1,132 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRtpCodecSpecificParameters_JSON(t *testing.T) {
+	data := []byte(`{"x-foo":"bar","packetization-mode":0,"profile-level-id":"4d0032","a-custom":3,"z-flag":true,"x-rate":0.5}`)
+
+	var params RtpCodecSpecificParameters
+	require.NoError(t, json.Unmarshal(data, &params))
+
+	assert.Equal(t, "bar", params.GetString("x-foo"))
+	assert.Equal(t, "4d0032", params.GetString("profile-level-id"))
+	assert.Equal(t, 3, params.GetInt("a-custom"))
+
+	// Zero values are kept.
+	value, ok := params.Get("packetization-mode")
+	assert.True(t, ok)
+	assert.Equal(t, 0, value)
+
+	value, ok = params.Get("z-flag")
+	assert.True(t, ok)
+	assert.Equal(t, true, value)
+
+	value, _ = params.Get("x-rate")
+	assert.Equal(t, 0.5, value)
+
+	// The parameters keep their order.
+	assert.Equal(t, []string{"x-foo", "packetization-mode", "profile-level-id", "a-custom", "z-flag", "x-rate"}, params.Keys())
+
+	output, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(output))
+}
+
+func TestRtpCodecSpecificParameters_Empty(t *testing.T) {
+	var params RtpCodecSpecificParameters
+
+	output, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(output))
+
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &params))
+	assert.Nil(t, params)
+
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &params))
+}
+
+func TestRtpCodecSpecificParameters_SetAndDelete(t *testing.T) {
+	params := RtpCodecSpecificParameters{
+		{Key: "packetization-mode", Value: 1},
+	}
+	shared := params
+
+	params.Set("profile-level-id", "42e01f")
+	params.Set("apt", uint8(100))
+	params.Set("x-custom", "1")
+	params.Set("x-custom", "2")
+	params.Set("packetization-mode", 0)
+
+	assert.Equal(t, RtpCodecSpecificParameters{
+		{Key: "packetization-mode", Value: 0},
+		{Key: "profile-level-id", Value: "42e01f"},
+		{Key: "apt", Value: 100},
+		{Key: "x-custom", Value: "2"},
+	}, params)
+	assert.Equal(t, 2, params.GetInt("x-custom"))
+
+	params.Delete("apt")
+	params.Delete("x-custom")
+	params.Delete("unknown")
+
+	assert.False(t, params.Has("apt"))
+	assert.Equal(t, []string{"packetization-mode", "profile-level-id"}, params.Keys())
+
+	// The parameters sharing the original ones are unchanged.
+	assert.Equal(t, RtpCodecSpecificParameters{{Key: "packetization-mode", Value: 1}}, shared)
+}
+
+func TestRtpCodecSpecificParameters_Clone(t *testing.T) {
+	params := RtpCodecSpecificParameters{{Key: "profile-id", Value: "2"}}
+	params.Set("x-custom", 1)
+
+	var cloned RtpCodecSpecificParameters
+	require.NoError(t, clone(params, &cloned))
+
+	assert.Equal(t, params, cloned)
+}
+
+func TestRtpCodecSpecificParameters_Opus(t *testing.T) {
+	data := []byte(`{"minptime":10,"useinbandfec":1,"stereo":0,"maxaveragebitrate":64000,"ptime":20}`)
+
+	var params RtpCodecSpecificParameters
+	require.NoError(t, json.Unmarshal(data, &params))
+
+	assert.Equal(t, OpusParameters{
+		Useinbandfec:      1,
+		Maxaveragebitrate: 64000,
+		Ptime:             20,
+	}, params.Opus())
+
+	shared := params
+	opus := params.Opus()
+	opus.Stereo = 1
+	opus.Usedtx = 1
+	opus.Maxaveragebitrate = 128000
+	opus.Ptime = 0
+	params.SetOpus(opus)
+
+	// Existing parameters keep their order, the new ones are appended and the
+	// zero ones removed.
+	assert.Equal(t, RtpCodecSpecificParameters{
+		{Key: "minptime", Value: 10},
+		{Key: "useinbandfec", Value: 1},
+		{Key: "stereo", Value: 1},
+		{Key: "maxaveragebitrate", Value: 128000},
+		{Key: "usedtx", Value: 1},
+	}, params)
+	assert.Equal(t, opus, params.Opus())
+
+	// The parameters sharing the original ones are unchanged.
+	assert.Equal(t, 20, shared.GetInt("ptime"))
+
+	output, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.Equal(t, `{"minptime":10,"useinbandfec":1,"stereo":1,"maxaveragebitrate":128000,"usedtx":1}`, string(output))
+}
@@ -3,6 +3,7 @@ package mediasoup
 import (
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 var scalabilityModeRegex = regexp.MustCompile(`^[LS]([1-9]\d{0,1})T([1-9]\d{0,1})(_KEY)?`)
@@ -13,6 +14,10 @@ type ScalabilityMode struct {
 	Ksvc           bool  `json:"ksvc"`
 }
 
+/**
+ * Parse the given scalability mode (e.g. 'L3T2_KEY'). If invalid or empty a
+ * single spatial and temporal layer is assumed.
+ */
 func ParseScalabilityMode(scalabilityMode string) ScalabilityMode {
 	match := scalabilityModeRegex.FindStringSubmatch(scalabilityMode)
 
@@ -33,3 +38,32 @@ func ParseScalabilityMode(scalabilityMode string) ScalabilityMode {
 		}
 	}
 }
+
+/**
+ * Get the scalability mode assumed when none is given for the given codec with
+ * the given number of encodings. As in mediasoup, VP8 and H264 simulcast
+ * streams are assumed to have a single temporal layer, the endpoints sending
+ * more being expected to signal it.
+ */
+func DefaultScalabilityMode(mimeType string, numEncodings int) string {
+	switch strings.ToLower(mimeType) {
+	case "video/vp8", "video/h264":
+		if numEncodings > 1 {
+			return "S1T1"
+		}
+	}
+
+	return ""
+}
+
+/**
+ * Parse the given scalability mode, falling back to the default one of the
+ * given codec if empty.
+ */
+func ParseScalabilityModeForCodec(scalabilityMode, mimeType string, numEncodings int) ScalabilityMode {
+	if len(scalabilityMode) == 0 {
+		scalabilityMode = DefaultScalabilityMode(mimeType, numEncodings)
+	}
+
+	return ParseScalabilityMode(scalabilityMode)
+}
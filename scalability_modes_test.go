@@ -58,3 +58,50 @@ func TestParseScalabilityMode(t *testing.T) {
 		assert.EqualValues(t, testCase.want, mode)
 	}
 }
+
+func TestParseScalabilityModeForCodec(t *testing.T) {
+	testCases := []struct {
+		scalabilityMode string
+		mimeType        string
+		numEncodings    int
+		want            ScalabilityMode
+	}{
+		{
+			mimeType:     "video/VP8",
+			numEncodings: 3,
+			want:         ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1, Ksvc: false},
+		},
+		{
+			mimeType:     "video/H264",
+			numEncodings: 2,
+			want:         ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1, Ksvc: false},
+		},
+		{
+			mimeType:     "video/VP8",
+			numEncodings: 1,
+			want:         ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1, Ksvc: false},
+		},
+		{
+			scalabilityMode: "S1T2",
+			mimeType:        "video/VP8",
+			numEncodings:    3,
+			want:            ScalabilityMode{SpatialLayers: 1, TemporalLayers: 2, Ksvc: false},
+		},
+		{
+			scalabilityMode: "L3T3_KEY",
+			mimeType:        "video/VP9",
+			numEncodings:    1,
+			want:            ScalabilityMode{SpatialLayers: 3, TemporalLayers: 3, Ksvc: true},
+		},
+		{
+			mimeType:     "video/VP9",
+			numEncodings: 1,
+			want:         ScalabilityMode{SpatialLayers: 1, TemporalLayers: 1, Ksvc: false},
+		},
+	}
+
+	for _, testCase := range testCases {
+		mode := ParseScalabilityModeForCodec(testCase.scalabilityMode, testCase.mimeType, testCase.numEncodings)
+		assert.EqualValues(t, testCase.want, mode)
+	}
+}
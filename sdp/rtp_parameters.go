@@ -0,0 +1,633 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// URI used in "a=extmap" lines to signal encrypted header extensions (RFC 6904).
+const encryptedHeaderExtensionUri = "urn:ietf:params:rtp-hdrext:encrypt"
+
+// Static payload types which may be used without "a=rtpmap" line (RFC 3551).
+var staticPayloadTypes = map[byte]mediasoup.RtpCodecParameters{
+	0: {MimeType: "audio/PCMU", ClockRate: 8000, Channels: 1},
+	8: {MimeType: "audio/PCMA", ClockRate: 8000, Channels: 1},
+	9: {MimeType: "audio/G722", ClockRate: 8000, Channels: 1},
+}
+
+/**
+ * Get the RtpParameters described by the given media section. Codecs are
+ * taken from "a=rtpmap", "a=fmtp" and "a=rtcp-fb" lines, header extensions
+ * from "a=extmap" lines and encodings from "a=rid"/"a=simulcast" lines or,
+ * if not present, from "a=ssrc"/"a=ssrc-group" lines.
+ */
+func RtpParametersFromMediaSection(section *MediaSection) (params mediasoup.RtpParameters, err error) {
+	kind := mediasoup.MediaKind(section.Kind)
+
+	if kind != mediasoup.MediaKind_Audio && kind != mediasoup.MediaKind_Video {
+		err = fmt.Errorf("invalid media section kind %q", section.Kind)
+		return
+	}
+
+	params.Mid, _ = section.Attribute("mid")
+
+	if params.Codecs, err = parseCodecs(section); err != nil {
+		return
+	}
+	if params.HeaderExtensions, err = parseHeaderExtensions(section); err != nil {
+		return
+	}
+	if params.Encodings, params.Rtcp.Cname, err = parseEncodings(section); err != nil {
+		return
+	}
+
+	_, reducedSize := section.Attribute("rtcp-rsize")
+	_, mux := section.Attribute("rtcp-mux")
+	params.Rtcp.ReducedSize = mediasoup.Bool(reducedSize)
+	params.Rtcp.Mux = mediasoup.Bool(mux)
+
+	return
+}
+
+/**
+ * Get the RtpCapabilities described by the given media sections. Sections
+ * which are not audio or video are ignored.
+ */
+func RtpCapabilitiesFromMediaSections(sections ...*MediaSection) (caps mediasoup.RtpCapabilities, err error) {
+	for _, section := range sections {
+		kind := mediasoup.MediaKind(section.Kind)
+
+		if kind != mediasoup.MediaKind_Audio && kind != mediasoup.MediaKind_Video {
+			continue
+		}
+
+		codecs, err := parseCodecs(section)
+		if err != nil {
+			return caps, err
+		}
+
+	nextCodec:
+		for _, codec := range codecs {
+			for _, capCodec := range caps.Codecs {
+				if capCodec.Kind == kind && capCodec.PreferredPayloadType == codec.PayloadType {
+					continue nextCodec
+				}
+			}
+			caps.Codecs = append(caps.Codecs, &mediasoup.RtpCodecCapability{
+				Kind:                 kind,
+				MimeType:             codec.MimeType,
+				PreferredPayloadType: codec.PayloadType,
+				ClockRate:            codec.ClockRate,
+				Channels:             codec.Channels,
+				Parameters:           codec.Parameters,
+				RtcpFeedback:         codec.RtcpFeedback,
+			})
+		}
+
+		for _, value := range section.AttributeValues("extmap") {
+			id, direction, uri, encrypt, err := parseExtmap(value)
+			if err != nil {
+				return caps, err
+			}
+			if len(direction) == 0 {
+				direction = mediasoup.Direction_Sendrecv
+			}
+
+			found := false
+
+			for _, capExt := range caps.HeaderExtensions {
+				if capExt.Kind == kind && capExt.Uri == uri {
+					found = true
+					break
+				}
+			}
+			if !found {
+				caps.HeaderExtensions = append(caps.HeaderExtensions, &mediasoup.RtpHeaderExtension{
+					Kind:             kind,
+					Uri:              uri,
+					PreferredId:      id,
+					PreferredEncrypt: encrypt,
+					Direction:        direction,
+				})
+			}
+		}
+	}
+
+	return
+}
+
+/**
+ * Generate a media section describing the given RtpParameters. The port is
+ * set to 9 (discard) and no "c=" line is written, so the caller should set
+ * them (e.g. with the tuple of a PlainTransport) before using the section.
+ */
+func MediaSectionFromRtpParameters(kind mediasoup.MediaKind, params mediasoup.RtpParameters) *MediaSection {
+	section := newMediaSection(kind, params.Codecs)
+
+	if len(params.Mid) > 0 {
+		section.AddAttribute("mid", params.Mid)
+	}
+
+	writeCodecs(section, params.Codecs)
+
+	for _, ext := range params.HeaderExtensions {
+		if ext.Encrypt {
+			section.AddAttribute("extmap", fmt.Sprintf("%d %s %s", ext.Id, encryptedHeaderExtensionUri, ext.Uri))
+		} else {
+			section.AddAttribute("extmap", fmt.Sprintf("%d %s", ext.Id, ext.Uri))
+		}
+	}
+
+	if params.Rtcp.Mux == nil || *params.Rtcp.Mux {
+		section.AddAttribute("rtcp-mux", "")
+	}
+	if params.Rtcp.ReducedSize == nil || *params.Rtcp.ReducedSize {
+		section.AddAttribute("rtcp-rsize", "")
+	}
+
+	var rids, ssrcs []string
+
+	for _, encoding := range params.Encodings {
+		if len(encoding.Rid) > 0 {
+			rids = append(rids, encoding.Rid)
+			section.AddAttribute("rid", encoding.Rid+" send")
+		}
+	}
+	if len(rids) > 1 {
+		section.AddAttribute("simulcast", "send "+strings.Join(rids, ";"))
+	}
+
+	for _, encoding := range params.Encodings {
+		if encoding.Ssrc == 0 {
+			continue
+		}
+		ssrcs = append(ssrcs, strconv.FormatUint(uint64(encoding.Ssrc), 10))
+
+		if encoding.Rtx != nil && encoding.Rtx.Ssrc > 0 {
+			section.AddAttribute("ssrc-group", fmt.Sprintf("FID %d %d", encoding.Ssrc, encoding.Rtx.Ssrc))
+		}
+	}
+	if len(ssrcs) > 1 {
+		section.AddAttribute("ssrc-group", "SIM "+strings.Join(ssrcs, " "))
+	}
+
+	for _, encoding := range params.Encodings {
+		if encoding.Ssrc == 0 {
+			continue
+		}
+		if len(params.Rtcp.Cname) > 0 {
+			section.AddAttribute("ssrc", fmt.Sprintf("%d cname:%s", encoding.Ssrc, params.Rtcp.Cname))
+		}
+		if encoding.Rtx != nil && encoding.Rtx.Ssrc > 0 && len(params.Rtcp.Cname) > 0 {
+			section.AddAttribute("ssrc", fmt.Sprintf("%d cname:%s", encoding.Rtx.Ssrc, params.Rtcp.Cname))
+		}
+	}
+
+	return section
+}
+
+/**
+ * Generate a media section describing the given RtpCapabilities for the
+ * given kind, useful to offer them to a remote endpoint.
+ */
+func MediaSectionFromRtpCapabilities(kind mediasoup.MediaKind, caps mediasoup.RtpCapabilities) *MediaSection {
+	var codecs []*mediasoup.RtpCodecParameters
+
+	for _, codec := range caps.Codecs {
+		if codec.Kind != kind {
+			continue
+		}
+		codecs = append(codecs, &mediasoup.RtpCodecParameters{
+			MimeType:     codec.MimeType,
+			PayloadType:  codec.PreferredPayloadType,
+			ClockRate:    codec.ClockRate,
+			Channels:     codec.Channels,
+			Parameters:   codec.Parameters,
+			RtcpFeedback: codec.RtcpFeedback,
+		})
+	}
+
+	section := newMediaSection(kind, codecs)
+
+	writeCodecs(section, codecs)
+
+	for _, ext := range caps.HeaderExtensions {
+		if ext.Kind != kind {
+			continue
+		}
+
+		id := strconv.Itoa(ext.PreferredId)
+
+		if len(ext.Direction) > 0 && ext.Direction != mediasoup.Direction_Sendrecv {
+			id += "/" + string(ext.Direction)
+		}
+		if ext.PreferredEncrypt {
+			section.AddAttribute("extmap", fmt.Sprintf("%s %s %s", id, encryptedHeaderExtensionUri, ext.Uri))
+		} else {
+			section.AddAttribute("extmap", fmt.Sprintf("%s %s", id, ext.Uri))
+		}
+	}
+
+	section.AddAttribute("rtcp-mux", "")
+	section.AddAttribute("rtcp-rsize", "")
+
+	return section
+}
+
+func newMediaSection(kind mediasoup.MediaKind, codecs []*mediasoup.RtpCodecParameters) *MediaSection {
+	section := &MediaSection{
+		Kind:     string(kind),
+		Port:     9,
+		Protocol: "RTP/AVP",
+	}
+
+	for _, codec := range codecs {
+		section.Formats = append(section.Formats, strconv.Itoa(int(codec.PayloadType)))
+
+		if len(codec.RtcpFeedback) > 0 {
+			section.Protocol = "RTP/AVPF"
+		}
+	}
+
+	return section
+}
+
+func writeCodecs(section *MediaSection, codecs []*mediasoup.RtpCodecParameters) {
+	for _, codec := range codecs {
+		name := codec.MimeType[strings.Index(codec.MimeType, "/")+1:]
+		rtpmap := fmt.Sprintf("%d %s/%d", codec.PayloadType, name, codec.ClockRate)
+
+		if strings.HasPrefix(strings.ToLower(codec.MimeType), "audio/") && codec.Channels > 1 {
+			rtpmap += "/" + strconv.Itoa(codec.Channels)
+		}
+		section.AddAttribute("rtpmap", rtpmap)
+
+		if fmtp := FormatFmtp(codec.Parameters); len(fmtp) > 0 {
+			section.AddAttribute("fmtp", fmt.Sprintf("%d %s", codec.PayloadType, fmtp))
+		}
+
+		for _, fb := range codec.RtcpFeedback {
+			value := fmt.Sprintf("%d %s", codec.PayloadType, fb.Type)
+
+			if len(fb.Parameter) > 0 {
+				value += " " + fb.Parameter
+			}
+			section.AddAttribute("rtcp-fb", value)
+		}
+	}
+}
+
+func parseCodecs(section *MediaSection) (codecs []*mediasoup.RtpCodecParameters, err error) {
+	codecsByPayloadType := map[byte]*mediasoup.RtpCodecParameters{}
+
+	for _, format := range section.Formats {
+		pt, err := parsePayloadType(format)
+		if err != nil {
+			return nil, err
+		}
+
+		var codec *mediasoup.RtpCodecParameters
+
+		if static, ok := staticPayloadTypes[pt]; ok && section.Kind == "audio" {
+			codec = &static
+			codec.PayloadType = pt
+		}
+		codecsByPayloadType[pt] = codec
+	}
+
+	for _, value := range section.AttributeValues("rtpmap") {
+		parts := strings.Fields(value)
+
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid a=rtpmap line %q", value)
+		}
+		pt, err := parsePayloadType(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := codecsByPayloadType[pt]; !ok {
+			continue
+		}
+
+		encoding := strings.Split(parts[1], "/")
+
+		if len(encoding) < 2 {
+			return nil, fmt.Errorf("invalid a=rtpmap line %q", value)
+		}
+		codec := &mediasoup.RtpCodecParameters{
+			MimeType:    section.Kind + "/" + encoding[0],
+			PayloadType: pt,
+		}
+		if codec.ClockRate, err = strconv.Atoi(encoding[1]); err != nil {
+			return nil, fmt.Errorf("invalid a=rtpmap clock rate %q", value)
+		}
+		if len(encoding) > 2 {
+			if codec.Channels, err = strconv.Atoi(encoding[2]); err != nil {
+				return nil, fmt.Errorf("invalid a=rtpmap channels %q", value)
+			}
+		} else if section.Kind == "audio" {
+			codec.Channels = 1
+		}
+		codecsByPayloadType[pt] = codec
+	}
+
+	for _, format := range section.Formats {
+		pt, _ := parsePayloadType(format)
+		codec := codecsByPayloadType[pt]
+
+		if codec == nil {
+			return nil, fmt.Errorf("missing a=rtpmap for payload type %d", pt)
+		}
+		codecs = append(codecs, codec)
+	}
+
+	for _, value := range section.AttributeValues("fmtp") {
+		parts := strings.SplitN(value, " ", 2)
+
+		if len(parts) != 2 {
+			continue
+		}
+		pt, err := parsePayloadType(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		codec := codecsByPayloadType[pt]
+
+		if codec == nil {
+			continue
+		}
+
+		if codec.Parameters, err = ParseFmtp(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, value := range section.AttributeValues("rtcp-fb") {
+		parts := strings.Fields(value)
+
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid a=rtcp-fb line %q", value)
+		}
+		fb := mediasoup.RtcpFeedback{Type: parts[1]}
+
+		if len(parts) > 2 {
+			fb.Parameter = strings.Join(parts[2:], " ")
+		}
+
+		if parts[0] == "*" {
+			for _, codec := range codecs {
+				codec.RtcpFeedback = append(codec.RtcpFeedback, fb)
+			}
+			continue
+		}
+
+		pt, err := parsePayloadType(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if codec := codecsByPayloadType[pt]; codec != nil {
+			codec.RtcpFeedback = append(codec.RtcpFeedback, fb)
+		}
+	}
+
+	return
+}
+
+/**
+ * Parse the parameters of a "a=fmtp" line (e.g. "minptime=10;useinbandfec=1").
+ * Numeric values are stored as numbers unless the parameter is a string one
+ * (e.g. profile-id).
+ */
+func ParseFmtp(value string) (params mediasoup.RtpCodecSpecificParameters, err error) {
+	for _, param := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+
+		if len(kv[0]) == 0 {
+			continue
+		}
+		if len(kv) == 1 {
+			kv = append(kv, "")
+		}
+		if n, err := strconv.Atoi(kv[1]); err == nil && !stringFmtpParameters[kv[0]] {
+			params.Set(kv[0], n)
+		} else {
+			params.Set(kv[0], kv[1])
+		}
+	}
+
+	return
+}
+
+// Parameters whose values are strings even if numeric.
+var stringFmtpParameters = map[string]bool{
+	"profile-level-id": true,
+	"profile-id":       true,
+	"channel_mapping":  true,
+	"tx-mode":          true,
+}
+
+/**
+ * Format the given codec parameters as the value of a "a=fmtp" line, without
+ * payload type.
+ */
+func FormatFmtp(params mediasoup.RtpCodecSpecificParameters) string {
+	var fmtp []string
+
+	for _, param := range params {
+		fmtp = append(fmtp, fmt.Sprintf("%s=%v", param.Key, param.Value))
+	}
+
+	return strings.Join(fmtp, ";")
+}
+
+func parseHeaderExtensions(section *MediaSection) (exts []mediasoup.RtpHeaderExtensionParameters, err error) {
+	for _, value := range section.AttributeValues("extmap") {
+		id, _, uri, encrypt, err := parseExtmap(value)
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, mediasoup.RtpHeaderExtensionParameters{
+			Uri:     uri,
+			Id:      id,
+			Encrypt: encrypt,
+		})
+	}
+
+	return
+}
+
+func parseExtmap(value string) (id int, direction mediasoup.RtpHeaderExtensionDirection, uri string, encrypt bool, err error) {
+	parts := strings.Fields(value)
+
+	if len(parts) < 2 {
+		err = fmt.Errorf("invalid a=extmap line %q", value)
+		return
+	}
+
+	idAndDirection := strings.SplitN(parts[0], "/", 2)
+
+	if id, err = strconv.Atoi(idAndDirection[0]); err != nil {
+		err = fmt.Errorf("invalid a=extmap id %q", value)
+		return
+	}
+	if len(idAndDirection) == 2 {
+		direction = mediasoup.RtpHeaderExtensionDirection(idAndDirection[1])
+	}
+
+	uri = parts[1]
+
+	if uri == encryptedHeaderExtensionUri {
+		if len(parts) < 3 {
+			err = fmt.Errorf("invalid a=extmap line %q", value)
+			return
+		}
+		uri, encrypt = parts[2], true
+	}
+
+	return
+}
+
+func parseEncodings(section *MediaSection) (encodings []mediasoup.RtpEncodingParameters, cname string, err error) {
+	var (
+		ssrcs    []uint32
+		rtxSsrcs = map[uint32]uint32{}
+		isRtx    = map[uint32]bool{}
+		simSsrcs []uint32
+	)
+
+	for _, value := range section.AttributeValues("ssrc") {
+		parts := strings.SplitN(value, " ", 2)
+		ssrc, err := parseSsrc(parts[0])
+		if err != nil {
+			return nil, "", err
+		}
+
+		found := false
+
+		for _, s := range ssrcs {
+			if s == ssrc {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ssrcs = append(ssrcs, ssrc)
+		}
+		if len(parts) == 2 && strings.HasPrefix(parts[1], "cname:") && len(cname) == 0 {
+			cname = strings.TrimPrefix(parts[1], "cname:")
+		}
+	}
+
+	for _, value := range section.AttributeValues("ssrc-group") {
+		parts := strings.Fields(value)
+
+		if len(parts) < 2 {
+			continue
+		}
+
+		var group []uint32
+
+		for _, part := range parts[1:] {
+			ssrc, err := parseSsrc(part)
+			if err != nil {
+				return nil, "", err
+			}
+			group = append(group, ssrc)
+		}
+
+		switch parts[0] {
+		case "FID":
+			if len(group) == 2 {
+				rtxSsrcs[group[0]] = group[1]
+				isRtx[group[1]] = true
+			}
+		case "SIM":
+			simSsrcs = group
+		}
+	}
+
+	// Simulcast with RID.
+	if rids := parseRids(section); len(rids) > 0 {
+		for _, rid := range rids {
+			encodings = append(encodings, mediasoup.RtpEncodingParameters{Rid: rid})
+		}
+		return
+	}
+
+	if len(simSsrcs) == 0 {
+		for _, ssrc := range ssrcs {
+			if !isRtx[ssrc] {
+				simSsrcs = append(simSsrcs, ssrc)
+			}
+		}
+	}
+
+	for _, ssrc := range simSsrcs {
+		encoding := mediasoup.RtpEncodingParameters{Ssrc: ssrc}
+
+		if rtxSsrc, ok := rtxSsrcs[ssrc]; ok {
+			encoding.Rtx = &mediasoup.RtpEncodingRtx{Ssrc: rtxSsrc}
+		}
+		encodings = append(encodings, encoding)
+	}
+
+	return
+}
+
+func parseRids(section *MediaSection) (rids []string) {
+	var sendRids []string
+
+	for _, value := range section.AttributeValues("rid") {
+		parts := strings.Fields(value)
+
+		if len(parts) >= 2 && parts[1] == "send" {
+			sendRids = append(sendRids, parts[0])
+		}
+	}
+
+	simulcast, ok := section.Attribute("simulcast")
+
+	if !ok {
+		return sendRids
+	}
+
+	parts := strings.Fields(simulcast)
+
+	for i := 0; i+1 < len(parts); i += 2 {
+		if parts[i] != "send" {
+			continue
+		}
+		// Alternatives are separated by "," and paused streams start with "~".
+		for _, stream := range strings.Split(parts[i+1], ";") {
+			rid := strings.TrimPrefix(strings.Split(stream, ",")[0], "~")
+
+			for _, sendRid := range sendRids {
+				if sendRid == rid {
+					rids = append(rids, rid)
+					break
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func parsePayloadType(value string) (byte, error) {
+	pt, err := strconv.ParseUint(value, 10, 7)
+	if err != nil {
+		return 0, fmt.Errorf("invalid payload type %q", value)
+	}
+	return byte(pt), nil
+}
+
+func parseSsrc(value string) (uint32, error) {
+	ssrc, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ssrc %q", value)
+	}
+	return uint32(ssrc), nil
+}
@@ -0,0 +1,261 @@
+// Package sdp converts between SDP media sections and mediasoup RtpParameters
+// and RtpCapabilities, so that mediasoup can be bridged with endpoints that
+// only speak SDP (SIP gateways, FFmpeg, GStreamer, plain RTP devices, etc.).
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Attribute is a "a=" line of a SDP. Value is empty for property attributes
+ * (e.g. "a=rtcp-mux").
+ */
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+func (a Attribute) String() string {
+	if len(a.Value) == 0 {
+		return a.Key
+	}
+	return a.Key + ":" + a.Value
+}
+
+/**
+ * MediaSection is a "m=" section of a SDP.
+ */
+type MediaSection struct {
+	/**
+	 * Media type ("audio", "video", "application").
+	 */
+	Kind string
+
+	/**
+	 * Transport port.
+	 */
+	Port int
+
+	/**
+	 * Transport protocol (e.g. "RTP/AVP", "UDP/TLS/RTP/SAVPF").
+	 */
+	Protocol string
+
+	/**
+	 * Media formats (payload types for RTP media).
+	 */
+	Formats []string
+
+	/**
+	 * Connection address of the media level "c=" line (e.g. "IN IP4 127.0.0.1"),
+	 * if any.
+	 */
+	Connection string
+
+	/**
+	 * Other lines of the section ("b=", "i=", etc.) in their original order.
+	 */
+	Lines []string
+
+	/**
+	 * Attributes in their original order.
+	 */
+	Attributes []Attribute
+}
+
+/**
+ * Get the value of the first attribute with the given key.
+ */
+func (m *MediaSection) Attribute(key string) (value string, ok bool) {
+	for _, attr := range m.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return
+}
+
+/**
+ * Get the values of every attribute with the given key.
+ */
+func (m *MediaSection) AttributeValues(key string) (values []string) {
+	for _, attr := range m.Attributes {
+		if attr.Key == key {
+			values = append(values, attr.Value)
+		}
+	}
+	return
+}
+
+/**
+ * Append an attribute.
+ */
+func (m *MediaSection) AddAttribute(key, value string) {
+	m.Attributes = append(m.Attributes, Attribute{Key: key, Value: value})
+}
+
+/**
+ * Get the media direction ("sendrecv", "sendonly", "recvonly" or "inactive").
+ * It defaults to "sendrecv".
+ */
+func (m *MediaSection) Direction() string {
+	for _, attr := range m.Attributes {
+		switch attr.Key {
+		case "sendrecv", "sendonly", "recvonly", "inactive":
+			return attr.Key
+		}
+	}
+	return "sendrecv"
+}
+
+/**
+ * Get the media section in SDP format.
+ */
+func (m *MediaSection) String() string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "m=%s %d %s %s\r\n", m.Kind, m.Port, m.Protocol, strings.Join(m.Formats, " "))
+
+	if len(m.Connection) > 0 {
+		fmt.Fprintf(b, "c=%s\r\n", m.Connection)
+	}
+	for _, line := range m.Lines {
+		fmt.Fprintf(b, "%s\r\n", line)
+	}
+	for _, attr := range m.Attributes {
+		fmt.Fprintf(b, "a=%s\r\n", attr)
+	}
+
+	return b.String()
+}
+
+/**
+ * SessionDescription is a parsed SDP.
+ */
+type SessionDescription struct {
+	/**
+	 * Session level lines ("v=", "o=", "s=", "t=", "a=", etc.) in their original
+	 * order.
+	 */
+	Lines []string
+
+	/**
+	 * Media sections.
+	 */
+	MediaSections []*MediaSection
+}
+
+/**
+ * Get the session description in SDP format.
+ */
+func (s *SessionDescription) String() string {
+	b := &strings.Builder{}
+
+	for _, line := range s.Lines {
+		fmt.Fprintf(b, "%s\r\n", line)
+	}
+	for _, section := range s.MediaSections {
+		b.WriteString(section.String())
+	}
+
+	return b.String()
+}
+
+/**
+ * Parse a SDP.
+ */
+func Parse(text string) (desc *SessionDescription, err error) {
+	desc = &SessionDescription{}
+
+	var section *MediaSection
+
+	for i, line := range splitLines(text) {
+		if len(line) < 2 || line[1] != '=' {
+			return nil, fmt.Errorf("invalid SDP line %d: %q", i+1, line)
+		}
+
+		if line[0] == 'm' {
+			if section, err = parseMediaLine(line[2:]); err != nil {
+				return nil, fmt.Errorf("invalid SDP line %d: %w", i+1, err)
+			}
+			desc.MediaSections = append(desc.MediaSections, section)
+			continue
+		}
+
+		if section == nil {
+			desc.Lines = append(desc.Lines, line)
+			continue
+		}
+
+		switch line[0] {
+		case 'c':
+			section.Connection = line[2:]
+		case 'a':
+			section.Attributes = append(section.Attributes, parseAttribute(line[2:]))
+		default:
+			section.Lines = append(section.Lines, line)
+		}
+	}
+
+	return
+}
+
+/**
+ * Parse a single media section, starting with its "m=" line.
+ */
+func ParseMediaSection(text string) (section *MediaSection, err error) {
+	desc, err := Parse(text)
+	if err != nil {
+		return
+	}
+	if len(desc.MediaSections) != 1 || len(desc.Lines) > 0 {
+		return nil, fmt.Errorf("expected a single media section")
+	}
+
+	return desc.MediaSections[0], nil
+}
+
+func splitLines(text string) (lines []string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if len(strings.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
+func parseMediaLine(value string) (section *MediaSection, err error) {
+	fields := strings.Fields(value)
+
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid m= line %q", value)
+	}
+
+	// Ignore the number of ports (e.g. "5004/2").
+	port, err := strconv.Atoi(strings.SplitN(fields[1], "/", 2)[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid m= line port %q", fields[1])
+	}
+
+	return &MediaSection{
+		Kind:     fields[0],
+		Port:     port,
+		Protocol: fields[2],
+		Formats:  fields[3:],
+	}, nil
+}
+
+func parseAttribute(value string) Attribute {
+	parts := strings.SplitN(value, ":", 2)
+
+	if len(parts) == 1 {
+		return Attribute{Key: parts[0]}
+	}
+
+	return Attribute{Key: parts[0], Value: parts[1]}
+}
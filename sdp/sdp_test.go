@@ -0,0 +1,206 @@
+package sdp
+
+import (
+	"testing"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const offer = "v=0\r\n" +
+	"o=- 0 0 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"c=IN IP4 127.0.0.1\r\n" +
+	"t=0 0\r\n" +
+	"m=audio 5004 RTP/AVPF 100 0\r\n" +
+	"a=mid:0\r\n" +
+	"a=rtpmap:100 opus/48000/2\r\n" +
+	"a=fmtp:100 minptime=10;useinbandfec=1;x-custom=foo\r\n" +
+	"a=rtcp-fb:100 transport-cc\r\n" +
+	"a=extmap:1 urn:ietf:params:rtp-hdrext:ssrc-audio-level\r\n" +
+	"a=extmap:2/recvonly urn:ietf:params:rtp-hdrext:encrypt urn:ietf:params:rtp-hdrext:sdes:mid\r\n" +
+	"a=rtcp-mux\r\n" +
+	"a=ssrc:1111 cname:foo\r\n" +
+	"m=video 5006 RTP/AVPF 101 102\r\n" +
+	"c=IN IP4 10.0.0.1\r\n" +
+	"b=AS:1000\r\n" +
+	"a=rtpmap:101 VP9/90000\r\n" +
+	"a=rtpmap:102 rtx/90000\r\n" +
+	"a=fmtp:101 profile-id=2\r\n" +
+	"a=fmtp:102 apt=101\r\n" +
+	"a=rtcp-fb:* nack\r\n" +
+	"a=rtcp-fb:101 nack pli\r\n" +
+	"a=rtcp-mux\r\n" +
+	"a=rtcp-rsize\r\n" +
+	"a=ssrc-group:FID 2222 3333\r\n" +
+	"a=ssrc:2222 cname:bar\r\n" +
+	"a=ssrc:3333 cname:bar\r\n"
+
+func TestParse(t *testing.T) {
+	desc, err := Parse(offer)
+	require.NoError(t, err)
+
+	assert.Len(t, desc.Lines, 5)
+	require.Len(t, desc.MediaSections, 2)
+
+	audio := desc.MediaSections[0]
+	assert.Equal(t, "audio", audio.Kind)
+	assert.Equal(t, 5004, audio.Port)
+	assert.Equal(t, []string{"100", "0"}, audio.Formats)
+	assert.Empty(t, audio.Connection)
+	assert.Equal(t, "sendrecv", audio.Direction())
+
+	video := desc.MediaSections[1]
+	assert.Equal(t, "IN IP4 10.0.0.1", video.Connection)
+	assert.Equal(t, []string{"b=AS:1000"}, video.Lines)
+
+	assert.Equal(t, offer, desc.String())
+
+	_, err = Parse("m=audio\r\n")
+	assert.Error(t, err)
+}
+
+func TestRtpParametersFromMediaSection(t *testing.T) {
+	desc, err := Parse(offer)
+	require.NoError(t, err)
+
+	params, err := RtpParametersFromMediaSection(desc.MediaSections[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, "0", params.Mid)
+	require.Len(t, params.Codecs, 2)
+	assert.Equal(t, "audio/opus", params.Codecs[0].MimeType)
+	assert.Equal(t, 2, params.Codecs[0].Channels)
+	assert.Equal(t, 10, params.Codecs[0].Parameters.GetInt("minptime"))
+	assert.EqualValues(t, 1, params.Codecs[0].Parameters.GetInt("useinbandfec"))
+	assert.Equal(t, "foo", params.Codecs[0].Parameters.GetString("x-custom"))
+	assert.Equal(t, []mediasoup.RtcpFeedback{{Type: "transport-cc"}}, params.Codecs[0].RtcpFeedback)
+	assert.Equal(t, "audio/PCMU", params.Codecs[1].MimeType)
+	assert.Equal(t, []mediasoup.RtpHeaderExtensionParameters{
+		{Uri: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", Id: 1},
+		{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 2, Encrypt: true},
+	}, params.HeaderExtensions)
+	assert.Equal(t, []mediasoup.RtpEncodingParameters{{Ssrc: 1111}}, params.Encodings)
+	assert.Equal(t, "foo", params.Rtcp.Cname)
+	assert.True(t, *params.Rtcp.Mux)
+	assert.False(t, *params.Rtcp.ReducedSize)
+
+	params, err = RtpParametersFromMediaSection(desc.MediaSections[1])
+	require.NoError(t, err)
+
+	require.Len(t, params.Codecs, 2)
+	assert.Equal(t, "2", params.Codecs[0].Parameters.GetString("profile-id"))
+	assert.Equal(t, []mediasoup.RtcpFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}}, params.Codecs[0].RtcpFeedback)
+	assert.EqualValues(t, 101, params.Codecs[1].Parameters.GetInt("apt"))
+	assert.Equal(t, []mediasoup.RtpEncodingParameters{
+		{Ssrc: 2222, Rtx: &mediasoup.RtpEncodingRtx{Ssrc: 3333}},
+	}, params.Encodings)
+	assert.NoError(t, mediasoup.ValidateRtpParameters(&params))
+}
+
+func TestRtpParametersFromMediaSection_Rid(t *testing.T) {
+	section, err := ParseMediaSection("m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=rid:h send\r\n" +
+		"a=rid:m send\r\n" +
+		"a=rid:l send\r\n" +
+		"a=simulcast:send l;m;~h\r\n")
+	require.NoError(t, err)
+
+	params, err := RtpParametersFromMediaSection(section)
+	require.NoError(t, err)
+
+	assert.Equal(t, []mediasoup.RtpEncodingParameters{{Rid: "l"}, {Rid: "m"}, {Rid: "h"}}, params.Encodings)
+
+	// Missing a=rtpmap.
+	section, err = ParseMediaSection("m=video 9 RTP/AVP 96\r\n")
+	require.NoError(t, err)
+
+	_, err = RtpParametersFromMediaSection(section)
+	assert.Error(t, err)
+}
+
+func TestMediaSectionFromRtpParameters(t *testing.T) {
+	params := mediasoup.RtpParameters{
+		Mid: "1",
+		Codecs: []*mediasoup.RtpCodecParameters{
+			{
+				MimeType:     "video/VP8",
+				PayloadType:  101,
+				ClockRate:    90000,
+				RtcpFeedback: []mediasoup.RtcpFeedback{{Type: "nack"}, {Type: "nack", Parameter: "pli"}},
+			},
+			{
+				MimeType:    "video/rtx",
+				PayloadType: 102,
+				ClockRate:   90000,
+				Parameters:  mediasoup.RtpCodecSpecificParameters{{Key: "apt", Value: 101}},
+			},
+		},
+		HeaderExtensions: []mediasoup.RtpHeaderExtensionParameters{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1, Encrypt: true},
+		},
+		Encodings: []mediasoup.RtpEncodingParameters{
+			{Ssrc: 1111, Rtx: &mediasoup.RtpEncodingRtx{Ssrc: 2222}},
+			{Ssrc: 3333},
+		},
+		Rtcp: mediasoup.RtcpParameters{Cname: "foo", ReducedSize: mediasoup.Bool(false)},
+	}
+
+	section := MediaSectionFromRtpParameters(mediasoup.MediaKind_Video, params)
+
+	assert.Equal(t, "m=video 9 RTP/AVPF 101 102\r\n"+
+		"a=mid:1\r\n"+
+		"a=rtpmap:101 VP8/90000\r\n"+
+		"a=rtcp-fb:101 nack\r\n"+
+		"a=rtcp-fb:101 nack pli\r\n"+
+		"a=rtpmap:102 rtx/90000\r\n"+
+		"a=fmtp:102 apt=101\r\n"+
+		"a=extmap:1 urn:ietf:params:rtp-hdrext:encrypt urn:ietf:params:rtp-hdrext:sdes:mid\r\n"+
+		"a=rtcp-mux\r\n"+
+		"a=ssrc-group:FID 1111 2222\r\n"+
+		"a=ssrc-group:SIM 1111 3333\r\n"+
+		"a=ssrc:1111 cname:foo\r\n"+
+		"a=ssrc:2222 cname:foo\r\n"+
+		"a=ssrc:3333 cname:foo\r\n", section.String())
+
+	// Round trip.
+	parsed, err := RtpParametersFromMediaSection(section)
+	require.NoError(t, err)
+
+	assert.Equal(t, params.Mid, parsed.Mid)
+	assert.Equal(t, params.Codecs, parsed.Codecs)
+	assert.Equal(t, params.HeaderExtensions, parsed.HeaderExtensions)
+	assert.Equal(t, params.Encodings, parsed.Encodings)
+	assert.Equal(t, params.Rtcp.Cname, parsed.Rtcp.Cname)
+}
+
+func TestRtpCapabilities(t *testing.T) {
+	desc, err := Parse(offer)
+	require.NoError(t, err)
+
+	caps, err := RtpCapabilitiesFromMediaSections(desc.MediaSections...)
+	require.NoError(t, err)
+
+	require.Len(t, caps.Codecs, 4)
+	assert.Equal(t, mediasoup.MediaKind_Audio, caps.Codecs[0].Kind)
+	assert.EqualValues(t, 100, caps.Codecs[0].PreferredPayloadType)
+	assert.Equal(t, mediasoup.MediaKind_Video, caps.Codecs[2].Kind)
+	require.Len(t, caps.HeaderExtensions, 2)
+	assert.Equal(t, mediasoup.Direction_Sendrecv, caps.HeaderExtensions[0].Direction)
+	assert.Equal(t, mediasoup.Direction_Recvonly, caps.HeaderExtensions[1].Direction)
+	assert.True(t, caps.HeaderExtensions[1].PreferredEncrypt)
+
+	section := MediaSectionFromRtpCapabilities(mediasoup.MediaKind_Audio, caps)
+
+	assert.Equal(t, []string{"100", "0"}, section.Formats)
+	assert.Equal(t, []string{
+		"1 urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+		"2/recvonly urn:ietf:params:rtp-hdrext:encrypt urn:ietf:params:rtp-hdrext:sdes:mid",
+	}, section.AttributeValues("extmap"))
+
+	roundTrip, err := RtpCapabilitiesFromMediaSections(section)
+	require.NoError(t, err)
+	assert.Equal(t, caps.Codecs[:2], roundTrip.Codecs)
+}
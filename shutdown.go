@@ -0,0 +1,58 @@
+package mediasoup
+
+import "context"
+
+/**
+ * Result of closing a Worker with CloseAll().
+ */
+type WorkerCloseResult struct {
+	Pid int
+
+	/**
+	 * Error returned by Worker.CloseGracefully(), i.e. ErrAlreadyClosed if it
+	 * was already closed or the error of the context if it expired first.
+	 */
+	Err error
+}
+
+/**
+ * Close the given Workers gracefully and concurrently, e.g. on service
+ * shutdown, see Worker.CloseGracefully(). It returns once they are all closed
+ * or the context is done, with the result of every Worker in the given order.
+ * The Workers not closed in time keep closing in background.
+ */
+func CloseAll(ctx context.Context, workers ...*Worker) []WorkerCloseResult {
+	type closed struct {
+		index int
+		err   error
+	}
+	results := make([]WorkerCloseResult, len(workers))
+	closedCh := make(chan closed, len(workers))
+	pending := make([]bool, len(workers))
+
+	for i, worker := range workers {
+		results[i].Pid = worker.Pid()
+		pending[i] = true
+
+		go func(i int, worker *Worker) {
+			closedCh <- closed{index: i, err: worker.CloseGracefully(ctx)}
+		}(i, worker)
+	}
+
+	for range workers {
+		select {
+		case c := <-closedCh:
+			results[c.index].Err = c.err
+			pending[c.index] = false
+		case <-ctx.Done():
+			for i := range results {
+				if pending[i] {
+					results[i].Err = ctx.Err()
+				}
+			}
+			return results
+		}
+	}
+
+	return results
+}
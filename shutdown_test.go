@@ -0,0 +1,54 @@
+package mediasoup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseAll(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	results := CloseAll(ctx, &Worker{pid: 1, closed: 1}, &Worker{pid: 2, closed: 1})
+
+	assert.Equal(t, []WorkerCloseResult{
+		{Pid: 1, Err: ErrAlreadyClosed},
+		{Pid: 2, Err: ErrAlreadyClosed},
+	}, results)
+}
+
+func TestCloseAll_Gracefully(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	mock1, err := NewMockWorker()
+	require.NoError(t, err)
+	mock2, err := NewMockWorker()
+	require.NoError(t, err)
+
+	results := CloseAll(ctx, mock1.Worker, mock2.Worker)
+
+	assert.Equal(t, []WorkerCloseResult{
+		{Pid: mock1.Pid()},
+		{Pid: mock2.Pid()},
+	}, results)
+
+	for _, mock := range []*MockWorker{mock1, mock2} {
+		assert.True(t, mock.Draining())
+		assert.True(t, mock.Closed())
+	}
+}
+
+func TestCloseAllContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := CloseAll(ctx, &Worker{pid: 1, closed: 1})
+
+	assert.Len(t, results, 1)
+	assert.Contains(t, []error{ErrAlreadyClosed, context.Canceled}, results[0].Err)
+}
@@ -0,0 +1,385 @@
+package sip
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+// Interval between two packets of an event (RFC 4733 section 2.5.1.2).
+const dtmfPacketInterval = 50 * time.Millisecond
+
+// Number of times the end of an event is sent.
+const dtmfEndPackets = 3
+
+// Events of the DTMF digits, by code (RFC 4733 section 3.2).
+const dtmfDigits = "0123456789*#ABCD"
+
+/**
+ * TelephoneEvent is the payload of a RFC 4733 telephone-event packet.
+ */
+type TelephoneEvent struct {
+	/**
+	 * Event code, 0-15 for the DTMF digits.
+	 */
+	Event uint8
+
+	/**
+	 * Whether the event ended.
+	 */
+	End bool
+
+	/**
+	 * Power level of the tone, in -dBm0 (0-63).
+	 */
+	Volume uint8
+
+	/**
+	 * Duration of the event so far, in units of the clock rate.
+	 */
+	Duration uint16
+}
+
+/**
+ * Parse a telephone-event payload.
+ */
+func ParseTelephoneEvent(payload []byte) (event TelephoneEvent, err error) {
+	if len(payload) < 4 {
+		err = errors.New("telephone-event payload too short")
+		return
+	}
+
+	event.Event = payload[0]
+	event.End = payload[1]&0x80 != 0
+	event.Volume = payload[1] & 0x3f
+	event.Duration = binary.BigEndian.Uint16(payload[2:])
+
+	return
+}
+
+/**
+ * Get the telephone-event payload.
+ */
+func (e TelephoneEvent) Marshal() []byte {
+	payload := []byte{e.Event, e.Volume & 0x3f, byte(e.Duration >> 8), byte(e.Duration)}
+
+	if e.End {
+		payload[1] |= 0x80
+	}
+
+	return payload
+}
+
+/**
+ * Get the DTMF digit ('0'-'9', '*', '#', 'A'-'D') of an event code.
+ */
+func DtmfDigit(event uint8) (digit rune, ok bool) {
+	if int(event) >= len(dtmfDigits) {
+		return 0, false
+	}
+	return rune(dtmfDigits[event]), true
+}
+
+/**
+ * Get the event code of a DTMF digit ('0'-'9', '*', '#', 'A'-'D').
+ */
+func DtmfEventCode(digit rune) (event uint8, ok bool) {
+	index := strings.IndexRune(dtmfDigits, digit)
+
+	if index < 0 && digit >= 'a' && digit <= 'd' {
+		index = strings.IndexRune(dtmfDigits, digit-'a'+'A')
+	}
+	if index < 0 {
+		return 0, false
+	}
+	return uint8(index), true
+}
+
+/**
+ * Options of a Producer of telephone-events (payload type 101, 8000 Hz) on a
+ * DirectTransport, to be used with a DtmfSender.
+ */
+func DtmfProducerOptions(ssrc uint32) mediasoup.ProducerOptions {
+	return mediasoup.ProducerOptions{
+		Kind: mediasoup.MediaKind_Audio,
+		RtpParameters: mediasoup.RtpParameters{
+			Codecs: []*mediasoup.RtpCodecParameters{
+				{
+					MimeType:    telephoneEvent.MimeType,
+					PayloadType: telephoneEvent.PreferredPayloadType,
+					ClockRate:   telephoneEvent.ClockRate,
+				},
+			},
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: ssrc}},
+		},
+	}
+}
+
+// telephoneEventCodec returns the telephone-event codec of the RTP parameters.
+func telephoneEventCodec(params mediasoup.RtpParameters) (*mediasoup.RtpCodecParameters, error) {
+	for _, codec := range params.Codecs {
+		if isTelephoneEvent(codec.MimeType) {
+			return codec, nil
+		}
+	}
+	return nil, mediasoup.NewTypeError("no telephone-event codec")
+}
+
+/**
+ * DtmfSender sends DTMF digits as telephone-events through a Producer of a
+ * DirectTransport, e.g. created with DtmfProducerOptions().
+ */
+type DtmfSender struct {
+	producer       *mediasoup.Producer
+	payloadType    byte
+	clockRate      int
+	ssrc           uint32
+	locker         sync.Mutex
+	sequenceNumber uint16
+	timestamp      uint32
+}
+
+/**
+ * Create a sender of DTMF digits through the given Producer.
+ */
+func NewDtmfSender(producer *mediasoup.Producer) (*DtmfSender, error) {
+	params := producer.RtpParameters()
+
+	codec, err := telephoneEventCodec(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.Encodings) == 0 || params.Encodings[0].Ssrc == 0 {
+		return nil, mediasoup.NewTypeError("missing SSRC")
+	}
+
+	return &DtmfSender{
+		producer:       producer,
+		payloadType:    codec.PayloadType,
+		clockRate:      codec.ClockRate,
+		ssrc:           params.Encodings[0].Ssrc,
+		sequenceNumber: uint16(rand.Uint32()),
+		timestamp:      rand.Uint32(),
+	}, nil
+}
+
+/**
+ * Send the given digits, each one lasting the given duration (default 100ms)
+ * and followed by the given pause (default 70ms). It returns once every digit
+ * is sent, or when the context is done.
+ */
+func (s *DtmfSender) SendDigits(ctx context.Context, digits string, duration, pause time.Duration) error {
+	events := make([]uint8, 0, len(digits))
+
+	for _, digit := range digits {
+		event, ok := DtmfEventCode(digit)
+		if !ok {
+			return mediasoup.NewTypeError("invalid DTMF digit %q", digit)
+		}
+		events = append(events, event)
+	}
+
+	if duration <= 0 {
+		duration = 100 * time.Millisecond
+	}
+	if pause <= 0 {
+		pause = 70 * time.Millisecond
+	}
+
+	// A single sequence of digits at a time.
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	for _, event := range events {
+		var packets [][]byte
+
+		packets, s.sequenceNumber = telephoneEventPackets(s.payloadType, s.ssrc, s.sequenceNumber, s.timestamp, s.clockRate, event, duration)
+
+		for i, packet := range packets {
+			if err := s.producer.Send(packet); err != nil {
+				return err
+			}
+			// The end packets are sent together.
+			if i < len(packets)-dtmfEndPackets {
+				if err := sleep(ctx, dtmfPacketInterval); err != nil {
+					return err
+				}
+			}
+		}
+
+		s.timestamp += uint32((duration + pause).Seconds() * float64(s.clockRate))
+
+		if err := sleep(ctx, pause); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// telephoneEventPackets returns the RTP packets of an event: one every 50ms
+// with the growing duration, the first one having the marker bit, then the
+// end of the event sent 3 times. It returns the next sequence number.
+func telephoneEventPackets(payloadType byte, ssrc uint32, sequenceNumber uint16, timestamp uint32, clockRate int, event uint8, duration time.Duration) (packets [][]byte, nextSequenceNumber uint16) {
+	units := func(d time.Duration) uint16 {
+		value := d.Seconds() * float64(clockRate)
+
+		if value > 0xffff {
+			return 0xffff
+		}
+		return uint16(value)
+	}
+
+	packet := func(e TelephoneEvent, marker bool) []byte {
+		buf := make([]byte, 12, 16)
+		buf[0] = 0x80
+		buf[1] = payloadType & 0x7f
+
+		if marker {
+			buf[1] |= 0x80
+		}
+		binary.BigEndian.PutUint16(buf[2:], sequenceNumber)
+		binary.BigEndian.PutUint32(buf[4:], timestamp)
+		binary.BigEndian.PutUint32(buf[8:], ssrc)
+		sequenceNumber++
+
+		return append(buf, e.Marshal()...)
+	}
+
+	for d := dtmfPacketInterval; d < duration; d += dtmfPacketInterval {
+		packets = append(packets, packet(TelephoneEvent{Event: event, Volume: 10, Duration: units(d)}, len(packets) == 0))
+	}
+
+	for i := 0; i < dtmfEndPackets; i++ {
+		end := TelephoneEvent{Event: event, End: true, Volume: 10, Duration: units(duration)}
+		packets = append(packets, packet(end, len(packets) == 0))
+	}
+
+	return packets, sequenceNumber
+}
+
+/**
+ * DtmfEvent is a DTMF digit received by a DtmfReceiver.
+ */
+type DtmfEvent struct {
+	Digit    rune
+	Event    uint8
+	Duration time.Duration
+}
+
+/**
+ * DtmfReceiver parses the telephone-events received by a Consumer of a
+ * DirectTransport.
+ * @emits dtmf - (event: DtmfEvent)
+ */
+type DtmfReceiver struct {
+	mediasoup.IEventEmitter
+	payloadType byte
+	clockRate   int
+	locker      sync.Mutex
+	ended       bool
+	timestamp   uint32
+}
+
+/**
+ * Start parsing the telephone-events received by the given Consumer.
+ */
+func NewDtmfReceiver(consumer *mediasoup.Consumer) (*DtmfReceiver, error) {
+	codec, err := telephoneEventCodec(consumer.RtpParameters())
+	if err != nil {
+		return nil, err
+	}
+
+	receiver := &DtmfReceiver{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		payloadType:   codec.PayloadType,
+		clockRate:     codec.ClockRate,
+	}
+
+	consumer.On("rtp", receiver.handleRtp)
+
+	return receiver, nil
+}
+
+func (r *DtmfReceiver) handleRtp(packet []byte) {
+	if event, ok := r.parse(packet); ok {
+		r.SafeEmit("dtmf", event)
+	}
+}
+
+// parse returns the event ended by the packet, once per event.
+func (r *DtmfReceiver) parse(packet []byte) (event DtmfEvent, ok bool) {
+	payloadType, timestamp, payload, valid := parseRtpHeader(packet)
+	if !valid || payloadType != r.payloadType {
+		return
+	}
+
+	te, err := ParseTelephoneEvent(payload)
+	if err != nil || !te.End {
+		return
+	}
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	// Retransmission of the end of the event.
+	if r.ended && timestamp == r.timestamp {
+		return
+	}
+	r.ended = true
+	r.timestamp = timestamp
+
+	digit, _ := DtmfDigit(te.Event)
+
+	return DtmfEvent{
+		Digit:    digit,
+		Event:    te.Event,
+		Duration: time.Duration(te.Duration) * time.Second / time.Duration(r.clockRate),
+	}, true
+}
+
+// parseRtpHeader returns the payload type, the timestamp and the payload of
+// an RTP packet.
+func parseRtpHeader(packet []byte) (payloadType byte, timestamp uint32, payload []byte, ok bool) {
+	if len(packet) < 12 || packet[0]>>6 != 2 {
+		return
+	}
+
+	offset := 12 + 4*int(packet[0]&0x0f)
+
+	if packet[0]&0x10 != 0 {
+		if len(packet) < offset+4 {
+			return
+		}
+		offset += 4 + 4*int(binary.BigEndian.Uint16(packet[offset+2:]))
+	}
+
+	end := len(packet)
+
+	if packet[0]&0x20 != 0 && end > offset {
+		end -= int(packet[end-1])
+	}
+	if offset > end {
+		return
+	}
+
+	return packet[1] & 0x7f, binary.BigEndian.Uint32(packet[4:]), packet[offset:end], true
+}
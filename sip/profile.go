@@ -0,0 +1,155 @@
+package sip
+
+import (
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * Profile is the list of audio codecs negotiated with SIP endpoints, in order
+ * of preference. The Router must support them, e.g. by being created with
+ * profile.MediaCodecs() in addition to the codecs of the WebRTC endpoints.
+ * Note that mediasoup does not transcode: the Producers consumed by a Session
+ * must use the negotiated codec.
+ */
+type Profile []*mediasoup.RtpCodecCapability
+
+var (
+	pcmu = &mediasoup.RtpCodecCapability{
+		Kind:                 mediasoup.MediaKind_Audio,
+		MimeType:             "audio/PCMU",
+		PreferredPayloadType: 0,
+		ClockRate:            8000,
+	}
+	pcma = &mediasoup.RtpCodecCapability{
+		Kind:                 mediasoup.MediaKind_Audio,
+		MimeType:             "audio/PCMA",
+		PreferredPayloadType: 8,
+		ClockRate:            8000,
+	}
+	g722 = &mediasoup.RtpCodecCapability{
+		Kind:                 mediasoup.MediaKind_Audio,
+		MimeType:             "audio/G722",
+		PreferredPayloadType: 9,
+		ClockRate:            8000,
+	}
+	telephoneEvent = &mediasoup.RtpCodecCapability{
+		Kind:                 mediasoup.MediaKind_Audio,
+		MimeType:             "audio/telephone-event",
+		PreferredPayloadType: 101,
+		ClockRate:            8000,
+	}
+)
+
+var (
+	/**
+	 * G.711 (PCMU, PCMA) with DTMF, supported by every PSTN gateway.
+	 */
+	G711Profile = Profile{pcmu, pcma, telephoneEvent}
+
+	/**
+	 * G.722 wideband, falling back to G.711, with DTMF.
+	 */
+	G722Profile = Profile{g722, pcmu, pcma, telephoneEvent}
+)
+
+/**
+ * Copy of the codecs of the profile, to be given to Worker.CreateRouter().
+ */
+func (p Profile) MediaCodecs() []*mediasoup.RtpCodecCapability {
+	codecs := make([]*mediasoup.RtpCodecCapability, 0, len(p))
+
+	for _, codec := range p {
+		clone := *codec
+		codecs = append(codecs, &clone)
+	}
+
+	return codecs
+}
+
+func isTelephoneEvent(mimeType string) bool {
+	return strings.EqualFold(mimeType, "audio/telephone-event")
+}
+
+// sameCodec returns whether the codecs have the same MIME type and clock rate.
+func sameCodec(mimeType string, clockRate int, other *mediasoup.RtpCodecCapability) bool {
+	return strings.EqualFold(mimeType, other.MimeType) && clockRate == other.ClockRate
+}
+
+// routerCodec returns the codec of the Router matching the given one, if any.
+func routerCodec(caps mediasoup.RtpCapabilities, mimeType string, clockRate int) *mediasoup.RtpCodecCapability {
+	for _, codec := range caps.Codecs {
+		if codec.Kind == mediasoup.MediaKind_Audio && sameCodec(mimeType, clockRate, codec) {
+			return codec
+		}
+	}
+	return nil
+}
+
+// offerCodecs returns the codecs of the profile supported by the Router, with
+// the payload types of the Router, so that they are the same in both
+// directions.
+func offerCodecs(profile Profile, caps mediasoup.RtpCapabilities) (codecs []*mediasoup.RtpCodecParameters) {
+	for _, codec := range profile {
+		supported := routerCodec(caps, codec.MimeType, codec.ClockRate)
+		if supported == nil {
+			continue
+		}
+		codecs = append(codecs, &mediasoup.RtpCodecParameters{
+			MimeType:    supported.MimeType,
+			PayloadType: supported.PreferredPayloadType,
+			ClockRate:   supported.ClockRate,
+		})
+	}
+	return
+}
+
+// negotiate selects the first remote codec (in the remote order of preference)
+// which belongs to the profile and is supported by the Router, and the
+// telephone-event codec of the same clock rate if offered. The payload types
+// are the remote ones.
+func negotiate(profile Profile, caps mediasoup.RtpCapabilities, remote []*mediasoup.RtpCodecParameters) (codecs []*mediasoup.RtpCodecParameters, err error) {
+	var selected *mediasoup.RtpCodecParameters
+
+	for _, codec := range remote {
+		if isTelephoneEvent(codec.MimeType) || routerCodec(caps, codec.MimeType, codec.ClockRate) == nil {
+			continue
+		}
+		for _, candidate := range profile {
+			if sameCodec(codec.MimeType, codec.ClockRate, candidate) {
+				selected = codec
+				break
+			}
+		}
+		if selected != nil {
+			break
+		}
+	}
+
+	if selected == nil {
+		return nil, mediasoup.NewUnsupportedError("no common audio codec")
+	}
+
+	selectedCodec := *selected
+	codecs = append(codecs, &selectedCodec)
+
+	for _, codec := range remote {
+		if !isTelephoneEvent(codec.MimeType) || codec.ClockRate != selected.ClockRate {
+			continue
+		}
+		if routerCodec(caps, codec.MimeType, codec.ClockRate) == nil {
+			break
+		}
+		for _, candidate := range profile {
+			if sameCodec(codec.MimeType, codec.ClockRate, candidate) {
+				dtmfCodec := *codec
+				codecs = append(codecs, &dtmfCodec)
+				break
+			}
+		}
+		break
+	}
+
+	return
+}
@@ -0,0 +1,426 @@
+// Package sip provides the building blocks to bridge SIP calls with mediasoup
+// Routers, without a separate media gateway: a Session negotiates the audio
+// of a call leg with SDP offer/answer over a PlainTransport, G711Profile and
+// G722Profile define the codecs offered to PSTN trunks, and DtmfSender and
+// DtmfReceiver inject and parse RFC 4733 telephone-events on DirectTransports.
+//
+// The SIP signaling itself (INVITE, ACK, BYE, etc.) is left to a SIP stack:
+//
+//	session, _ := sip.NewSession(sip.SessionOptions{
+//		Router:   router,
+//		ListenIp: mediasoup.TransportListenIp{Ip: "0.0.0.0", AnnouncedIp: publicIp},
+//	})
+//	answer, err := session.ProcessOffer(invite.Body)
+//	// reply 200 OK with answer, then:
+//	producer, _ := session.Produce(0)
+//	consumer, _ := session.Consume(otherProducer.Id())
+package sip
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/sdp"
+)
+
+type SessionOptions struct {
+	/**
+	 * Router bridged with the call.
+	 */
+	Router *mediasoup.Router
+
+	/**
+	 * IP on which the RTP of the call is received.
+	 */
+	ListenIp mediasoup.TransportListenIp
+
+	/**
+	 * Codecs negotiated with the remote endpoint. Default G711Profile.
+	 */
+	Profile Profile
+
+	/**
+	 * Logger of the session. Default mediasoup.NewLogger("SipSession").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Session is the audio of a SIP call leg, received and sent through a
+ * PlainTransport.
+ */
+type Session struct {
+	logger    mediasoup.Logger
+	router    *mediasoup.Router
+	transport *mediasoup.PlainTransport
+	profile   Profile
+	locker    sync.Mutex
+	sessionId uint64
+	version   uint64
+	// Codecs of the last local offer.
+	offered []*mediasoup.RtpCodecParameters
+	// Negotiated codecs, with the payload types of the remote endpoint.
+	codecs     []*mediasoup.RtpCodecParameters
+	remoteSsrc uint32
+	remoteAddr string
+}
+
+/**
+ * Create the PlainTransport of a call leg.
+ */
+func NewSession(options SessionOptions) (session *Session, err error) {
+	if options.Router == nil {
+		return nil, mediasoup.NewTypeError("missing Router")
+	}
+	if len(options.Profile) == 0 {
+		options.Profile = G711Profile
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("SipSession")
+	}
+
+	transport, err := options.Router.CreatePlainTransport(mediasoup.PlainTransportOptions{
+		ListenIp: options.ListenIp,
+		RtcpMux:  mediasoup.Bool(false),
+	})
+	if err != nil {
+		return
+	}
+
+	session = &Session{
+		logger:    options.Logger,
+		router:    options.Router,
+		transport: transport,
+		profile:   options.Profile,
+		sessionId: uint64(rand.Int63()),
+	}
+
+	return
+}
+
+/**
+ * PlainTransport of the session.
+ */
+func (s *Session) Transport() *mediasoup.PlainTransport {
+	return s.transport
+}
+
+/**
+ * Negotiated codecs, with the payload types of the remote endpoint. Empty
+ * until an offer or an answer is processed.
+ */
+func (s *Session) Codecs() []*mediasoup.RtpCodecParameters {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.codecs
+}
+
+/**
+ * Create an offer of the codecs of the profile supported by the Router, to be
+ * sent in an INVITE.
+ */
+func (s *Session) CreateOffer() (offer string, err error) {
+	s.logger.Debug("CreateOffer()")
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	codecs := offerCodecs(s.profile, s.router.RtpCapabilities())
+	if len(codecs) == 0 {
+		return "", mediasoup.NewUnsupportedError("no codec of the profile is supported by the Router")
+	}
+	s.offered = codecs
+
+	return s.localDescription(codecs), nil
+}
+
+/**
+ * Process the answer to the offer created by CreateOffer(), connecting the
+ * transport to the remote endpoint.
+ */
+func (s *Session) ProcessAnswer(answer string) (err error) {
+	s.logger.Debug("ProcessAnswer()")
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if len(s.offered) == 0 {
+		return mediasoup.NewInvalidStateError("no offer created")
+	}
+
+	offered := Profile{}
+
+	for _, codec := range s.offered {
+		offered = append(offered, &mediasoup.RtpCodecCapability{
+			Kind:      mediasoup.MediaKind_Audio,
+			MimeType:  codec.MimeType,
+			ClockRate: codec.ClockRate,
+		})
+	}
+
+	return s.processRemoteDescription(answer, offered)
+}
+
+/**
+ * Process an offer received in an INVITE, connecting the transport to the
+ * remote endpoint, and return the answer.
+ */
+func (s *Session) ProcessOffer(offer string) (answer string, err error) {
+	s.logger.Debug("ProcessOffer()")
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if err = s.processRemoteDescription(offer, s.profile); err != nil {
+		return
+	}
+
+	return s.localDescription(s.codecs), nil
+}
+
+func (s *Session) processRemoteDescription(text string, profile Profile) (err error) {
+	remote, err := parseRemoteDescription(text)
+	if err != nil {
+		return
+	}
+
+	codecs, err := negotiate(profile, s.router.RtpCapabilities(), remote.codecs)
+	if err != nil {
+		return
+	}
+
+	addr := fmt.Sprintf("%s:%d:%d", remote.ip, remote.port, remote.rtcpPort)
+
+	switch {
+	case len(s.remoteAddr) == 0:
+		err = s.transport.Connect(mediasoup.TransportConnectOptions{
+			Ip:       remote.ip,
+			Port:     remote.port,
+			RtcpPort: remote.rtcpPort,
+		})
+		if err != nil {
+			return
+		}
+		s.remoteAddr = addr
+
+	case s.remoteAddr != addr:
+		return mediasoup.NewUnsupportedError("changing the remote address of a session")
+	}
+
+	s.codecs = codecs
+	s.remoteSsrc = remote.ssrc
+
+	return
+}
+
+/**
+ * Produce the audio sent by the remote endpoint, having the given SSRC. If 0,
+ * the SSRC signaled in the remote description ("a=ssrc") is used.
+ */
+func (s *Session) Produce(ssrc uint32, opts ...mediasoup.ProducerOption) (producer *mediasoup.Producer, err error) {
+	s.logger.Debug("Produce()")
+
+	s.locker.Lock()
+	codecs := s.codecs
+	if ssrc == 0 {
+		ssrc = s.remoteSsrc
+	}
+	s.locker.Unlock()
+
+	if len(codecs) == 0 {
+		return nil, mediasoup.NewInvalidStateError("no codec negotiated")
+	}
+	if ssrc == 0 {
+		return nil, mediasoup.NewTypeError("unknown SSRC of the remote endpoint")
+	}
+
+	return s.transport.Produce(mediasoup.ProducerOptions{
+		Kind: mediasoup.MediaKind_Audio,
+		RtpParameters: mediasoup.RtpParameters{
+			Codecs:    codecs,
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: ssrc}},
+			Rtcp: mediasoup.RtcpParameters{
+				Mux:         mediasoup.Bool(false),
+				ReducedSize: mediasoup.Bool(false),
+			},
+		},
+	}, opts...)
+}
+
+/**
+ * Send the given Producer, which must use the negotiated codec, to the remote
+ * endpoint. The payload types are the ones of the Router, used by the offers
+ * of the session but possibly different from the ones of a remote offer for
+ * the dynamic ones (telephone-event).
+ */
+func (s *Session) Consume(producerId string, opts ...mediasoup.ConsumerOption) (consumer *mediasoup.Consumer, err error) {
+	s.logger.Debug("Consume()")
+
+	s.locker.Lock()
+	codecs := s.codecs
+	s.locker.Unlock()
+
+	if len(codecs) == 0 {
+		return nil, mediasoup.NewInvalidStateError("no codec negotiated")
+	}
+
+	routerCaps := s.router.RtpCapabilities()
+	caps := mediasoup.RtpCapabilities{}
+
+	for _, codec := range codecs {
+		if supported := routerCodec(routerCaps, codec.MimeType, codec.ClockRate); supported != nil {
+			clone := *supported
+			clone.RtcpFeedback = nil
+			caps.Codecs = append(caps.Codecs, &clone)
+		}
+	}
+
+	return s.transport.Consume(mediasoup.ConsumerOptions{
+		ProducerId:      producerId,
+		RtpCapabilities: caps,
+	}, opts...)
+}
+
+/**
+ * Close the transport, and so the Producers and Consumers of the session.
+ */
+func (s *Session) Close() error {
+	s.logger.Debug("Close()")
+
+	return s.transport.Close()
+}
+
+// localDescription returns the SDP describing the transport with the given
+// codecs, the locker being held.
+func (s *Session) localDescription(codecs []*mediasoup.RtpCodecParameters) string {
+	s.version++
+
+	tuple := s.transport.Tuple()
+	rtcpPort := tuple.LocalPort + 1
+
+	if rtcpTuple := s.transport.RtcpTuple(); rtcpTuple != nil {
+		rtcpPort = rtcpTuple.LocalPort
+	}
+
+	return localDescription(s.sessionId, s.version, tuple.LocalIp, tuple.LocalPort, rtcpPort, codecs)
+}
+
+func localDescription(sessionId, version uint64, ip string, port, rtcpPort uint16, codecs []*mediasoup.RtpCodecParameters) string {
+	addrType := "IP4"
+	if strings.Contains(ip, ":") {
+		addrType = "IP6"
+	}
+
+	section := sdp.MediaSectionFromRtpParameters(mediasoup.MediaKind_Audio, mediasoup.RtpParameters{
+		Codecs: codecs,
+		Rtcp: mediasoup.RtcpParameters{
+			Mux:         mediasoup.Bool(false),
+			ReducedSize: mediasoup.Bool(false),
+		},
+	})
+	section.Port = int(port)
+
+	for _, codec := range codecs {
+		if isTelephoneEvent(codec.MimeType) {
+			section.AddAttribute("fmtp", fmt.Sprintf("%d 0-16", codec.PayloadType))
+		}
+	}
+	section.AddAttribute("rtcp", strconv.Itoa(int(rtcpPort)))
+	section.AddAttribute("ptime", "20")
+	section.AddAttribute("sendrecv", "")
+
+	desc := &sdp.SessionDescription{
+		Lines: []string{
+			"v=0",
+			fmt.Sprintf("o=mediasoup-go %d %d IN %s %s", sessionId, version, addrType, ip),
+			"s=mediasoup-go",
+			fmt.Sprintf("c=IN %s %s", addrType, ip),
+			"t=0 0",
+		},
+		MediaSections: []*sdp.MediaSection{section},
+	}
+
+	return desc.String()
+}
+
+type remoteDescription struct {
+	ip       string
+	port     uint16
+	rtcpPort uint16
+	codecs   []*mediasoup.RtpCodecParameters
+	ssrc     uint32
+}
+
+// parseRemoteDescription parses the first active audio section of the SDP.
+func parseRemoteDescription(text string) (remote remoteDescription, err error) {
+	desc, err := sdp.Parse(text)
+	if err != nil {
+		return
+	}
+
+	var section *sdp.MediaSection
+
+	for _, s := range desc.MediaSections {
+		if s.Kind == "audio" && s.Port != 0 {
+			section = s
+			break
+		}
+	}
+	if section == nil {
+		err = mediasoup.NewTypeError("no audio section")
+		return
+	}
+
+	connection := section.Connection
+
+	if len(connection) == 0 {
+		for _, line := range desc.Lines {
+			if strings.HasPrefix(line, "c=") {
+				connection = line[2:]
+			}
+		}
+	}
+
+	// "IN IP4 <address>"
+	if fields := strings.Fields(connection); len(fields) == 3 {
+		remote.ip = fields[2]
+	} else {
+		err = mediasoup.NewTypeError("missing connection address")
+		return
+	}
+
+	remote.port = uint16(section.Port)
+	remote.rtcpPort = remote.port + 1
+
+	if value, ok := section.Attribute("rtcp"); ok {
+		// "<port> [IN IP4 <address>]"
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			err = mediasoup.NewTypeError("invalid rtcp attribute %q", value)
+			return
+		}
+		port, parseErr := strconv.ParseUint(fields[0], 10, 16)
+		if parseErr != nil {
+			err = mediasoup.NewTypeError("invalid rtcp attribute %q", value)
+			return
+		}
+		remote.rtcpPort = uint16(port)
+	}
+
+	params, err := sdp.RtpParametersFromMediaSection(section)
+	if err != nil {
+		return
+	}
+	remote.codecs = params.Codecs
+
+	if len(params.Encodings) > 0 {
+		remote.ssrc = params.Encodings[0].Ssrc
+	}
+
+	return
+}
@@ -0,0 +1,253 @@
+package sip
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/sdp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func routerCapabilities() mediasoup.RtpCapabilities {
+	return mediasoup.RtpCapabilities{
+		Codecs: []*mediasoup.RtpCodecCapability{
+			{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/opus", PreferredPayloadType: 100, ClockRate: 48000, Channels: 2},
+			{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/PCMU", PreferredPayloadType: 0, ClockRate: 8000},
+			{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/PCMA", PreferredPayloadType: 8, ClockRate: 8000},
+			{Kind: mediasoup.MediaKind_Audio, MimeType: "audio/telephone-event", PreferredPayloadType: 101, ClockRate: 8000},
+		},
+	}
+}
+
+func TestProfile_MediaCodecs(t *testing.T) {
+	codecs := G722Profile.MediaCodecs()
+	require.Len(t, codecs, 4)
+	assert.Equal(t, "audio/G722", codecs[0].MimeType)
+
+	codecs[0].MimeType = "audio/foo"
+	assert.Equal(t, "audio/G722", G722Profile[0].MimeType)
+}
+
+func TestOfferCodecs(t *testing.T) {
+	codecs := offerCodecs(G722Profile, routerCapabilities())
+
+	require.Len(t, codecs, 3)
+	assert.Equal(t, "audio/PCMU", codecs[0].MimeType)
+	assert.EqualValues(t, 0, codecs[0].PayloadType)
+	assert.Equal(t, "audio/PCMA", codecs[1].MimeType)
+	assert.Equal(t, "audio/telephone-event", codecs[2].MimeType)
+	assert.EqualValues(t, 101, codecs[2].PayloadType)
+}
+
+func TestNegotiate(t *testing.T) {
+	remote := []*mediasoup.RtpCodecParameters{
+		{MimeType: "audio/G729", PayloadType: 18, ClockRate: 8000},
+		{MimeType: "audio/PCMA", PayloadType: 8, ClockRate: 8000},
+		{MimeType: "audio/PCMU", PayloadType: 0, ClockRate: 8000},
+		{MimeType: "audio/telephone-event", PayloadType: 96, ClockRate: 8000},
+	}
+
+	codecs, err := negotiate(G711Profile, routerCapabilities(), remote)
+	require.NoError(t, err)
+	require.Len(t, codecs, 2)
+	assert.Equal(t, "audio/PCMA", codecs[0].MimeType)
+	assert.Equal(t, "audio/telephone-event", codecs[1].MimeType)
+	assert.EqualValues(t, 96, codecs[1].PayloadType)
+
+	// G.722 is not supported by the Router.
+	codecs, err = negotiate(G722Profile, routerCapabilities(), append([]*mediasoup.RtpCodecParameters{
+		{MimeType: "audio/G722", PayloadType: 9, ClockRate: 8000},
+	}, remote[2:3]...))
+	require.NoError(t, err)
+	require.Len(t, codecs, 1)
+	assert.Equal(t, "audio/PCMU", codecs[0].MimeType)
+
+	_, err = negotiate(G711Profile, routerCapabilities(), remote[:1])
+	assert.IsType(t, mediasoup.UnsupportedError{}, err)
+}
+
+func TestLocalDescription(t *testing.T) {
+	codecs := offerCodecs(G711Profile, routerCapabilities())
+	text := localDescription(1234, 2, "203.0.113.1", 40000, 40001, codecs)
+
+	desc, err := sdp.Parse(text)
+	require.NoError(t, err)
+	assert.Contains(t, desc.Lines, "o=mediasoup-go 1234 2 IN IP4 203.0.113.1")
+	assert.Contains(t, desc.Lines, "c=IN IP4 203.0.113.1")
+	require.Len(t, desc.MediaSections, 1)
+
+	section := desc.MediaSections[0]
+	assert.Equal(t, 40000, section.Port)
+	assert.Equal(t, "sendrecv", section.Direction())
+	assert.Contains(t, section.AttributeValues("fmtp"), "101 0-16")
+
+	rtcp, _ := section.Attribute("rtcp")
+	assert.Equal(t, "40001", rtcp)
+
+	_, mux := section.Attribute("rtcp-mux")
+	assert.False(t, mux)
+
+	remote, err := parseRemoteDescription(text)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.1", remote.ip)
+	assert.EqualValues(t, 40000, remote.port)
+	assert.EqualValues(t, 40001, remote.rtcpPort)
+	assert.Len(t, remote.codecs, 3)
+
+	text = localDescription(1234, 2, "2001:db8::1", 40000, 40001, codecs)
+	assert.Contains(t, text, "c=IN IP6 2001:db8::1")
+}
+
+func TestParseRemoteDescription(t *testing.T) {
+	text := "v=0\r\n" +
+		"o=- 1 1 IN IP4 198.51.100.7\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 198.51.100.7\r\n" +
+		"t=0 0\r\n" +
+		"m=video 0 RTP/AVP 96\r\n" +
+		"m=audio 30000 RTP/AVP 8 101\r\n" +
+		"a=rtpmap:8 PCMA/8000\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n" +
+		"a=fmtp:101 0-15\r\n" +
+		"a=rtcp:30005 IN IP4 198.51.100.7\r\n" +
+		"a=ssrc:1111 cname:foo\r\n"
+
+	remote, err := parseRemoteDescription(text)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.7", remote.ip)
+	assert.EqualValues(t, 30000, remote.port)
+	assert.EqualValues(t, 30005, remote.rtcpPort)
+	assert.EqualValues(t, 1111, remote.ssrc)
+	require.Len(t, remote.codecs, 2)
+	assert.Equal(t, "audio/PCMA", remote.codecs[0].MimeType)
+
+	_, err = parseRemoteDescription("v=0\r\nm=audio 30000 RTP/AVP 0\r\na=rtpmap:0 PCMU/8000\r\n")
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestTelephoneEvent(t *testing.T) {
+	event := TelephoneEvent{Event: 11, End: true, Volume: 10, Duration: 800}
+	payload := event.Marshal()
+	assert.Equal(t, []byte{11, 0x8a, 0x03, 0x20}, payload)
+
+	parsed, err := ParseTelephoneEvent(payload)
+	require.NoError(t, err)
+	assert.Equal(t, event, parsed)
+
+	_, err = ParseTelephoneEvent(payload[:3])
+	assert.Error(t, err)
+}
+
+func TestDtmfDigits(t *testing.T) {
+	for i, digit := range "0123456789*#ABCD" {
+		event, ok := DtmfEventCode(digit)
+		assert.True(t, ok)
+		assert.EqualValues(t, i, event)
+
+		d, ok := DtmfDigit(event)
+		assert.True(t, ok)
+		assert.Equal(t, digit, d)
+	}
+
+	event, ok := DtmfEventCode('b')
+	assert.True(t, ok)
+	assert.EqualValues(t, 13, event)
+
+	_, ok = DtmfEventCode('E')
+	assert.False(t, ok)
+
+	_, ok = DtmfDigit(16)
+	assert.False(t, ok)
+}
+
+func TestTelephoneEventPackets(t *testing.T) {
+	packets, next := telephoneEventPackets(101, 1234, 65534, 5000, 8000, 5, 160*time.Millisecond)
+
+	// 50, 100, 150ms then 3 end packets.
+	require.Len(t, packets, 6)
+	assert.EqualValues(t, 4, next)
+
+	for i, packet := range packets {
+		require.Len(t, packet, 16)
+		assert.EqualValues(t, 0x80, packet[0])
+		assert.Equal(t, i == 0, packet[1]&0x80 != 0)
+		assert.EqualValues(t, 101, packet[1]&0x7f)
+		assert.EqualValues(t, uint16(65534+i), binary.BigEndian.Uint16(packet[2:]))
+		assert.EqualValues(t, 5000, binary.BigEndian.Uint32(packet[4:]))
+		assert.EqualValues(t, 1234, binary.BigEndian.Uint32(packet[8:]))
+
+		event, err := ParseTelephoneEvent(packet[12:])
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, event.Event)
+		assert.Equal(t, i >= 3, event.End)
+
+		if i < 3 {
+			assert.EqualValues(t, 400*(i+1), event.Duration)
+		} else {
+			assert.EqualValues(t, 1280, event.Duration)
+		}
+	}
+
+	// A short event only has the end packets, the first one with the marker.
+	packets, _ = telephoneEventPackets(101, 1234, 0, 0, 8000, 5, 40*time.Millisecond)
+	require.Len(t, packets, 3)
+	assert.True(t, packets[0][1]&0x80 != 0)
+	assert.False(t, packets[1][1]&0x80 != 0)
+}
+
+func TestDtmfReceiver_Parse(t *testing.T) {
+	receiver := &DtmfReceiver{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		payloadType:   101,
+		clockRate:     8000,
+	}
+
+	var events []DtmfEvent
+
+	for _, digit := range []uint8{1, 1, 11} {
+		packets, _ := telephoneEventPackets(101, 1234, 0, uint32(len(events))*8000, 8000, digit, 100*time.Millisecond)
+
+		for _, packet := range packets {
+			if event, ok := receiver.parse(packet); ok {
+				events = append(events, event)
+			}
+		}
+	}
+
+	require.Len(t, events, 3)
+	assert.Equal(t, '1', events[0].Digit)
+	assert.Equal(t, '1', events[1].Digit)
+	assert.Equal(t, '#', events[2].Digit)
+	assert.Equal(t, 100*time.Millisecond, events[2].Duration)
+
+	// Other payload types are ignored.
+	packets, _ := telephoneEventPackets(0, 1234, 0, 99, 8000, 1, 100*time.Millisecond)
+	_, ok := receiver.parse(packets[len(packets)-1])
+	assert.False(t, ok)
+}
+
+func TestParseRtpHeader(t *testing.T) {
+	packet := []byte{
+		0x91, 0x65, 0, 1, 0, 0, 0, 9, 0, 0, 0, 1,
+		0, 0, 0, 2, // CSRC
+		0xbe, 0xde, 0, 1, 0x10, 0xff, 0, 0, // extension
+		1, 2, 3, 4,
+	}
+
+	payloadType, timestamp, payload, ok := parseRtpHeader(packet)
+	require.True(t, ok)
+	assert.EqualValues(t, 101, payloadType)
+	assert.EqualValues(t, 9, timestamp)
+	assert.Equal(t, []byte{1, 2, 3, 4}, payload)
+
+	_, _, _, ok = parseRtpHeader(packet[:10])
+	assert.False(t, ok)
+}
+
+func TestNewSession_InvalidOptions(t *testing.T) {
+	_, err := NewSession(SessionOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
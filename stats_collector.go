@@ -0,0 +1,322 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type StatsEntityType string
+
+const (
+	StatsEntityType_Transport    StatsEntityType = "transport"
+	StatsEntityType_Producer     StatsEntityType = "producer"
+	StatsEntityType_Consumer     StatsEntityType = "consumer"
+	StatsEntityType_DataProducer StatsEntityType = "dataproducer"
+	StatsEntityType_DataConsumer StatsEntityType = "dataconsumer"
+)
+
+/**
+ * Stats of an entity got by a StatsCollector.
+ */
+type StatsReport struct {
+	EntityType StatsEntityType `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	Timestamp  time.Time       `json:"timestamp"`
+
+	/**
+	 * Result of GetStats() of the entity: []*TransportStat, []*ProducerStat,
+	 * []*ConsumerStat, []*DataProducerStat or []*DataConsumerStat.
+	 */
+	Stats interface{} `json:"stats,omitempty"`
+
+	/**
+	 * Error of GetStats(), if any.
+	 */
+	Error error `json:"-"`
+}
+
+/**
+ * StatsSink receives the batches of StatsReport published by a StatsCollector.
+ * Publish is called from a single goroutine.
+ */
+type StatsSink interface {
+	Publish(reports []StatsReport) error
+}
+
+/**
+ * StatsSinkFunc is a StatsSink calling the function.
+ */
+type StatsSinkFunc func(reports []StatsReport) error
+
+func (fn StatsSinkFunc) Publish(reports []StatsReport) error {
+	return fn(reports)
+}
+
+type channelStatsSink chan<- []StatsReport
+
+/**
+ * Create a StatsSink sending the batches into the given channel. A batch is
+ * dropped, and an error returned, if the channel is full.
+ */
+func NewChannelStatsSink(ch chan<- []StatsReport) StatsSink {
+	return channelStatsSink(ch)
+}
+
+func (ch channelStatsSink) Publish(reports []StatsReport) error {
+	select {
+	case ch <- reports:
+		return nil
+	default:
+		return NewInvalidStateError("stats channel is full, batch dropped")
+	}
+}
+
+type jsonLinesStatsSink struct {
+	locker  sync.Mutex
+	encoder *json.Encoder
+}
+
+/**
+ * Create a StatsSink writing every StatsReport as a JSON line (e.g. into an
+ * os.File), with an "error" field if GetStats() failed.
+ */
+func NewJSONLinesStatsSink(w io.Writer) StatsSink {
+	return &jsonLinesStatsSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonLinesStatsSink) Publish(reports []StatsReport) (err error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	for _, report := range reports {
+		line := struct {
+			StatsReport
+			Error string `json:"error,omitempty"`
+		}{
+			StatsReport: report,
+		}
+		if report.Error != nil {
+			line.Error = report.Error.Error()
+		}
+		if err = s.encoder.Encode(line); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+type StatsCollectorOptions struct {
+	/**
+	 * Interval between two polls. Default the StatsInterval of the Config.
+	 */
+	Interval time.Duration
+
+	/**
+	 * Maximum number of reports in a batch. Default 0, which means every
+	 * report of a poll is published in a single batch.
+	 */
+	MaxBatchSize int
+
+	/**
+	 * Sinks the batches are published to.
+	 */
+	Sinks []StatsSink
+}
+
+type statsEntry struct {
+	entityType StatsEntityType
+	getStats   func() (interface{}, error)
+	closed     func() bool
+}
+
+/**
+ * StatsCollector polls the stats of the entities added to it at an interval
+ * and publishes them to its sinks. Entities are removed once closed.
+ */
+type StatsCollector struct {
+	logger       Logger
+	interval     time.Duration
+	maxBatchSize int
+	sinks        []StatsSink
+	entries      sync.Map
+	closeOnce    sync.Once
+	closeCh      chan struct{}
+}
+
+/**
+ * Create a StatsCollector and start polling.
+ */
+func NewStatsCollector(options StatsCollectorOptions) *StatsCollector {
+	logger := NewLogger("StatsCollector")
+
+	logger.Debug("constructor()")
+
+	collector := &StatsCollector{
+		logger:       logger,
+		interval:     options.Interval,
+		maxBatchSize: options.MaxBatchSize,
+		sinks:        options.Sinks,
+		closeCh:      make(chan struct{}),
+	}
+
+	if collector.interval <= 0 {
+		collector.interval = getConfig().StatsInterval
+	}
+
+	go collector.run()
+
+	return collector
+}
+
+/**
+ * Stop polling.
+ */
+func (c *StatsCollector) Close() {
+	c.closeOnce.Do(func() {
+		c.logger.Debug("close()")
+
+		close(c.closeCh)
+	})
+}
+
+/**
+ * Poll the stats of the Transport.
+ */
+func (c *StatsCollector) AddTransport(transport ITransport) {
+	c.add(transport.Id(), transport.Observer(), statsEntry{
+		entityType: StatsEntityType_Transport,
+		getStats:   func() (interface{}, error) { return transport.GetStats() },
+		closed:     transport.Closed,
+	})
+}
+
+/**
+ * Poll the stats of the Producer.
+ */
+func (c *StatsCollector) AddProducer(producer *Producer) {
+	c.add(producer.Id(), producer.Observer(), statsEntry{
+		entityType: StatsEntityType_Producer,
+		getStats:   func() (interface{}, error) { return producer.GetStats() },
+		closed:     producer.Closed,
+	})
+}
+
+/**
+ * Poll the stats of the Consumer.
+ */
+func (c *StatsCollector) AddConsumer(consumer *Consumer) {
+	c.add(consumer.Id(), consumer.Observer(), statsEntry{
+		entityType: StatsEntityType_Consumer,
+		getStats:   func() (interface{}, error) { return consumer.GetStats() },
+		closed:     consumer.Closed,
+	})
+}
+
+/**
+ * Poll the stats of the DataProducer.
+ */
+func (c *StatsCollector) AddDataProducer(dataProducer *DataProducer) {
+	c.add(dataProducer.Id(), dataProducer.Observer(), statsEntry{
+		entityType: StatsEntityType_DataProducer,
+		getStats:   func() (interface{}, error) { return dataProducer.GetStats() },
+		closed:     dataProducer.Closed,
+	})
+}
+
+/**
+ * Poll the stats of the DataConsumer.
+ */
+func (c *StatsCollector) AddDataConsumer(dataConsumer *DataConsumer) {
+	c.add(dataConsumer.Id(), dataConsumer.Observer(), statsEntry{
+		entityType: StatsEntityType_DataConsumer,
+		getStats:   func() (interface{}, error) { return dataConsumer.GetStats() },
+		closed:     dataConsumer.Closed,
+	})
+}
+
+/**
+ * Stop polling the stats of the entity with the given id.
+ */
+func (c *StatsCollector) Remove(id string) {
+	c.entries.Delete(id)
+}
+
+/**
+ * Poll the stats of every entity now and publish them.
+ */
+func (c *StatsCollector) Collect() {
+	var reports []StatsReport
+
+	c.entries.Range(func(key, value interface{}) bool {
+		entry := value.(statsEntry)
+
+		stats, err := entry.getStats()
+
+		// Do not publish the stats of an entity closed while polling.
+		if entry.closed() {
+			c.entries.Delete(key)
+			return true
+		}
+		reports = append(reports, StatsReport{
+			EntityType: entry.entityType,
+			EntityId:   key.(string),
+			Timestamp:  time.Now(),
+			Stats:      stats,
+			Error:      err,
+		})
+
+		return true
+	})
+
+	if len(reports) == 0 {
+		return
+	}
+
+	batchSize := c.maxBatchSize
+
+	if batchSize <= 0 {
+		batchSize = len(reports)
+	}
+
+	for start := 0; start < len(reports); start += batchSize {
+		end := start + batchSize
+
+		if end > len(reports) {
+			end = len(reports)
+		}
+		c.publish(reports[start:end])
+	}
+}
+
+func (c *StatsCollector) add(id string, observer IEventEmitter, entry statsEntry) {
+	c.entries.Store(id, entry)
+
+	observer.Once("close", func() {
+		c.entries.Delete(id)
+	})
+}
+
+func (c *StatsCollector) publish(batch []StatsReport) {
+	for _, sink := range c.sinks {
+		if err := sink.Publish(batch); err != nil {
+			c.logger.Warn("publishing stats failed: %s", err)
+		}
+	}
+}
+
+func (c *StatsCollector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Collect()
+		case <-c.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package mediasoup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCollector(t *testing.T) {
+	var batches [][]StatsReport
+
+	collector := NewStatsCollector(StatsCollectorOptions{
+		Interval:     time.Hour,
+		MaxBatchSize: 2,
+		Sinks: []StatsSink{StatsSinkFunc(func(reports []StatsReport) error {
+			batches = append(batches, reports)
+			return nil
+		})},
+	})
+	defer collector.Close()
+
+	observers := map[string]IEventEmitter{}
+
+	for _, id := range []string{"p1", "p2", "p3"} {
+		observers[id] = NewEventEmitter()
+		collector.add(id, observers[id], statsEntry{
+			entityType: StatsEntityType_Producer,
+			getStats: func() (interface{}, error) {
+				return []*ProducerStat{{Type: "inbound-rtp"}}, nil
+			},
+			closed: func() bool { return false },
+		})
+	}
+
+	collector.Collect()
+
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+
+	observers["p1"].Emit("close")
+	collector.Remove("p2")
+	batches = nil
+
+	collector.Collect()
+
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 1)
+	assert.Equal(t, "p3", batches[0][0].EntityId)
+	assert.Equal(t, StatsEntityType_Producer, batches[0][0].EntityType)
+}
+
+func TestChannelStatsSink(t *testing.T) {
+	ch := make(chan []StatsReport, 1)
+	sink := NewChannelStatsSink(ch)
+
+	assert.NoError(t, sink.Publish([]StatsReport{{EntityId: "t1"}}))
+	assert.Error(t, sink.Publish([]StatsReport{{EntityId: "t2"}}))
+	assert.Equal(t, "t1", (<-ch)[0].EntityId)
+}
+
+func TestJSONLinesStatsSink(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	sink := NewJSONLinesStatsSink(buf)
+
+	err := sink.Publish([]StatsReport{
+		{EntityType: StatsEntityType_Consumer, EntityId: "c1", Stats: []*ConsumerStat{{Bitrate: 1000}}},
+		{EntityType: StatsEntityType_Consumer, EntityId: "c2", Error: errors.New("closed")},
+	})
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var line map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(lines[0], &line))
+	assert.Equal(t, "c1", line["entityId"])
+	assert.NotContains(t, line, "error")
+
+	require.NoError(t, json.Unmarshal(lines[1], &line))
+	assert.Equal(t, "closed", line["error"])
+}
@@ -0,0 +1,201 @@
+package mediasoup
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Monotonic counters of every stats struct, by Go field name.
+var statsCounterFields = map[reflect.Type][]string{
+	reflect.TypeOf(ProducerStat{}): {
+		"PacketsLost", "PacketsDiscarded", "PacketsRetransmitted", "PacketsRepaired",
+		"NackCount", "NackPacketCount", "PliCount", "FirCount", "PacketCount",
+		"ByteCount", "RtxPacketsDiscarded",
+	},
+	reflect.TypeOf(TransportStat{}): {
+		"BytesReceived", "BytesSent", "RtpBytesReceived", "RtpBytesSent",
+		"RtxBytesReceived", "RtxBytesSent", "ProbationBytesSent",
+	},
+	reflect.TypeOf(DataProducerStat{}): {"MessagesReceived", "BytesReceived"},
+	reflect.TypeOf(DataConsumerStat{}): {"MessagesSent", "BytesSent"},
+}
+
+/**
+ * Difference of the counters of two stats of the same stream.
+ */
+type StatsDiff struct {
+	/**
+	 * Time between the two stats.
+	 */
+	Interval time.Duration `json:"interval"`
+
+	/**
+	 * Increase of every counter, by JSON name (e.g. "byteCount"). A counter
+	 * lower than before (i.e. reset) increased by its current value.
+	 */
+	Deltas map[string]int64 `json:"deltas"`
+
+	/**
+	 * Increase of every counter per second.
+	 */
+	Rates map[string]float64 `json:"rates"`
+}
+
+/**
+ * Increase per second of the counter with the given JSON name, 0 if unknown.
+ */
+func (d StatsDiff) Rate(name string) float64 {
+	return d.Rates[name]
+}
+
+/**
+ * Diff two stats of the same stream (same Ssrc, or same entity for transports
+ * and data entities) taken in order. They must both be *ProducerStat,
+ * *ConsumerStat, *TransportStat, *DataProducerStat or *DataConsumerStat
+ * (or values of them).
+ */
+func DiffStats(prev, curr interface{}) (diff StatsDiff, err error) {
+	prevValue, currValue := reflect.Indirect(reflect.ValueOf(prev)), reflect.Indirect(reflect.ValueOf(curr))
+
+	if !prevValue.IsValid() || !currValue.IsValid() {
+		err = NewTypeError("missing stats")
+		return
+	}
+	if prevValue.Type() != currValue.Type() {
+		err = NewTypeError("stats of different types: %s and %s", prevValue.Type(), currValue.Type())
+		return
+	}
+
+	fields, ok := statsCounterFields[currValue.Type()]
+	if !ok {
+		err = NewTypeError("unsupported stats type: %s", currValue.Type())
+		return
+	}
+
+	prevTimestamp := prevValue.FieldByName("Timestamp").Int()
+	currTimestamp := currValue.FieldByName("Timestamp").Int()
+
+	// Stats timestamps are in milliseconds.
+	diff.Interval = time.Duration(currTimestamp-prevTimestamp) * time.Millisecond
+
+	if diff.Interval <= 0 {
+		err = NewTypeError("stats timestamps not increasing: %d then %d", prevTimestamp, currTimestamp)
+		return
+	}
+
+	diff.Deltas = make(map[string]int64, len(fields))
+	diff.Rates = make(map[string]float64, len(fields))
+
+	for _, name := range fields {
+		field, _ := currValue.Type().FieldByName(name)
+		key := statsFieldKey(field)
+
+		prevCount, currCount := counterValue(prevValue.FieldByName(name)), counterValue(currValue.FieldByName(name))
+		delta := currCount - prevCount
+
+		if delta < 0 {
+			delta = currCount
+		}
+		diff.Deltas[key] = delta
+		diff.Rates[key] = float64(delta) / diff.Interval.Seconds()
+	}
+
+	return
+}
+
+func counterValue(value reflect.Value) int64 {
+	switch value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint())
+	default:
+		return value.Int()
+	}
+}
+
+// statsFieldKey returns the JSON name of the field.
+func statsFieldKey(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; len(tag) > 0 {
+		return tag
+	}
+	return strings.ToLower(field.Name[:1]) + field.Name[1:]
+}
+
+/**
+ * StatsWindow keeps the last N stats of a stream to compute its rates over
+ * them.
+ */
+type StatsWindow struct {
+	locker  sync.Mutex
+	size    int
+	samples []interface{}
+}
+
+/**
+ * Create a StatsWindow keeping the given number (at least 2) of samples.
+ */
+func NewStatsWindow(size int) *StatsWindow {
+	if size < 2 {
+		size = 2
+	}
+
+	return &StatsWindow{size: size}
+}
+
+/**
+ * Add a sample, dropping the oldest one if the window is full. It must have
+ * the type of the previous samples.
+ */
+func (w *StatsWindow) Add(stat interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(stat))
+
+	if !value.IsValid() {
+		return NewTypeError("missing stats")
+	}
+	if _, ok := statsCounterFields[value.Type()]; !ok {
+		return NewTypeError("unsupported stats type: %s", value.Type())
+	}
+
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	if len(w.samples) > 0 {
+		if last := reflect.Indirect(reflect.ValueOf(w.samples[len(w.samples)-1])); last.Type() != value.Type() {
+			return NewTypeError("stats of different types: %s and %s", last.Type(), value.Type())
+		}
+	}
+
+	w.samples = append(w.samples, stat)
+
+	if len(w.samples) > w.size {
+		w.samples = append(w.samples[:0], w.samples[len(w.samples)-w.size:]...)
+	}
+
+	return nil
+}
+
+/**
+ * Number of samples in the window.
+ */
+func (w *StatsWindow) Len() int {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	return len(w.samples)
+}
+
+/**
+ * Diff the oldest and the newest samples, i.e. the rates over the window.
+ */
+func (w *StatsWindow) Diff() (diff StatsDiff, err error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	if len(w.samples) < 2 {
+		err = NewInvalidStateError("%d samples, at least 2 needed", len(w.samples))
+		return
+	}
+
+	return DiffStats(w.samples[0], w.samples[len(w.samples)-1])
+}
@@ -0,0 +1,58 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStats(t *testing.T) {
+	prev := &ProducerStat{Timestamp: 1000, ByteCount: 1000, PacketCount: 10, NackCount: 5, Score: 10}
+	curr := &ConsumerStat{Timestamp: 3000, ByteCount: 5000, PacketCount: 30, NackCount: 2, Score: 9}
+
+	diff, err := DiffStats(prev, curr)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2*time.Second, diff.Interval)
+	assert.EqualValues(t, 4000, diff.Deltas["byteCount"])
+	assert.EqualValues(t, 2000, diff.Rate("byteCount"))
+	assert.EqualValues(t, 10, diff.Rate("packetCount"))
+	// reset counter
+	assert.EqualValues(t, 2, diff.Deltas["nackCount"])
+	assert.NotContains(t, diff.Deltas, "score")
+
+	dataDiff, err := DiffStats(DataProducerStat{Timestamp: 0, MessagesReceived: 1}, DataProducerStat{Timestamp: 500, MessagesReceived: 11})
+	require.NoError(t, err)
+	assert.EqualValues(t, 20, dataDiff.Rate("messagesReceived"))
+
+	_, err = DiffStats(curr, prev)
+	assert.IsType(t, TypeError{}, err)
+
+	_, err = DiffStats(prev, &TransportStat{Timestamp: 2000})
+	assert.IsType(t, TypeError{}, err)
+
+	_, err = DiffStats(1, 2)
+	assert.IsType(t, TypeError{}, err)
+}
+
+func TestStatsWindow(t *testing.T) {
+	window := NewStatsWindow(3)
+
+	_, err := window.Diff()
+	assert.Error(t, err)
+
+	for i := int64(1); i <= 5; i++ {
+		require.NoError(t, window.Add(&TransportStat{Timestamp: i * 1000, BytesSent: i * i * 100}))
+	}
+	assert.Equal(t, 3, window.Len())
+
+	diff, err := window.Diff()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, diff.Interval)
+	assert.EqualValues(t, 2500-900, diff.Deltas["bytesSent"])
+
+	assert.Error(t, window.Add(&DataConsumerStat{Timestamp: 6000}))
+	assert.Error(t, window.Add("stats"))
+}
@@ -0,0 +1,284 @@
+package mediasoup
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Stress tests run against a MockWorker, to be run with -race.
+
+const stressRounds = 50
+
+func newStressWorker(t *testing.T) (mock *MockWorker, router *Router) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	mock.Respond("router.createWebRtcTransport", H{"iceRole": "controlled", "iceState": "new", "dtlsState": "new"})
+	mock.Respond("transport.produce", H{"type": "simple"})
+	mock.Respond("transport.consume", H{"paused": false, "producerPaused": false})
+	mock.Handle("consumer.setPriority", func(request MockRequest) (interface{}, error) {
+		var data struct{ Priority uint32 }
+		request.Unmarshal(&data)
+
+		return data, nil
+	})
+	mock.Handle("consumer.setPreferredLayers", func(request MockRequest) (interface{}, error) {
+		var layers ConsumerLayers
+		request.Unmarshal(&layers)
+
+		return layers, nil
+	})
+
+	router, err = mock.CreateRouter(stressRouterOptions)
+	require.NoError(t, err)
+
+	return
+}
+
+var stressRouterOptions = RouterOptions{MediaCodecs: []*RtpCodecCapability{
+	{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+}}
+
+func stressProduce(transport ITransport) (*Producer, error) {
+	return transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+}
+
+// parallel runs the functions concurrently stressRounds times each.
+func parallel(fns ...func(i int)) {
+	var wg sync.WaitGroup
+
+	for _, fn := range fns {
+		wg.Add(1)
+
+		go func(fn func(i int)) {
+			defer wg.Done()
+
+			for i := 0; i < stressRounds; i++ {
+				fn(i)
+			}
+		}(fn)
+	}
+
+	wg.Wait()
+}
+
+func TestStress_ProducerConsumerState(t *testing.T) {
+	mock, router := newStressWorker(t)
+	defer mock.Close()
+
+	transport, err := router.CreateWebRtcTransport(WebRtcTransportOptions{
+		ListenIps: []TransportListenIp{{Ip: "127.0.0.1"}},
+	})
+	require.NoError(t, err)
+
+	producer, err := stressProduce(transport)
+	require.NoError(t, err)
+
+	consumer, err := transport.Consume(ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: router.RtpCapabilities(),
+	})
+	require.NoError(t, err)
+
+	parallel(
+		func(i int) {
+			if i%2 == 0 {
+				assert.NoError(t, producer.Pause())
+			} else {
+				assert.NoError(t, producer.Resume())
+			}
+		},
+		func(i int) {
+			if i%2 == 0 {
+				assert.NoError(t, consumer.Pause())
+			} else {
+				assert.NoError(t, consumer.Resume())
+			}
+		},
+		func(i int) {
+			assert.NoError(t, consumer.SetPriority(uint32(i)))
+			assert.NoError(t, consumer.SetPreferredLayers(ConsumerLayers{SpatialLayer: uint8(i % 3)}))
+		},
+		func(i int) {
+			mock.Notify(producer.Id(), "score", []ProducerScore{{Ssrc: 1234, Score: uint32(i % 10)}})
+			mock.Notify(consumer.Id(), "score", ConsumerScore{Score: uint16(i % 10)})
+			mock.Notify(consumer.Id(), "layerschange", ConsumerLayers{SpatialLayer: uint8(i % 3)})
+			mock.Notify(consumer.Id(), []string{"producerpause", "producerresume"}[i%2], nil)
+		},
+		func(i int) {
+			mock.Notify(transport.Id(), "icestatechange", H{"iceState": "connected"})
+			mock.Notify(transport.Id(), "iceselectedtuplechange", H{"iceSelectedTuple": H{"localPort": i}})
+			mock.Notify(transport.Id(), "dtlsstatechange", H{"dtlsState": "connected", "dtlsRemoteCert": "cert"})
+			mock.Notify(transport.Id(), "sctpstatechange", H{"sctpState": "connected"})
+		},
+		func(int) {
+			producer.Paused()
+			producer.Score()
+			consumer.Paused()
+			consumer.ProducerPaused()
+			consumer.Priority()
+			consumer.Score()
+			consumer.PreferredLayers()
+			consumer.CurrentLayers()
+			transport.IceState()
+			transport.IceSelectedTuple()
+			transport.DtlsParameters()
+			transport.DtlsState()
+			transport.DtlsRemoteCert()
+			transport.SctpState()
+		},
+	)
+
+	assert.Equal(t, uint32(stressRounds-1), consumer.Priority())
+	assert.False(t, producer.Paused())
+	assert.False(t, consumer.Paused())
+}
+
+func TestStress_ConsumerPauseDuringProducerPause(t *testing.T) {
+	SetConfig(Config{EmitterDispatchMode: EmitterDispatchMode_Sync})
+	defer SetConfig(DefaultConfig())
+
+	mock, router := newStressWorker(t)
+	defer mock.Close()
+
+	transport, err := router.CreateWebRtcTransport(WebRtcTransportOptions{
+		ListenIps: []TransportListenIp{{Ip: "127.0.0.1"}},
+	})
+	require.NoError(t, err)
+
+	producer, err := stressProduce(transport)
+	require.NoError(t, err)
+
+	consumer, err := transport.Consume(ConsumerOptions{
+		ProducerId:      producer.Id(),
+		RtpCapabilities: router.RtpCapabilities(),
+	})
+	require.NoError(t, err)
+
+	// The Producer is paused while the Consumer pause request is pending.
+	mock.Handle("consumer.pause", func(MockRequest) (interface{}, error) {
+		return nil, mock.Notify(consumer.Id(), "producerpause", nil)
+	})
+
+	var pauses int32
+
+	consumer.Observer().On("pause", func() {
+		atomic.AddInt32(&pauses, 1)
+	})
+
+	require.NoError(t, consumer.Pause())
+	assert.True(t, consumer.Paused())
+	// The notification may be dispatched after the response.
+	assert.Eventually(t, consumer.ProducerPaused, time.Second, time.Millisecond)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&pauses))
+}
+
+func TestStress_CreateClose(t *testing.T) {
+	mock, router := newStressWorker(t)
+	defer mock.Close()
+
+	var fns []func(i int)
+
+	for n := 0; n < 4; n++ {
+		fns = append(fns, func(i int) {
+			transport, err := router.CreateDirectTransport()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			producer, err := stressProduce(transport)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			consumer, err := transport.Consume(ConsumerOptions{
+				ProducerId:      producer.Id(),
+				RtpCapabilities: router.RtpCapabilities(),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// Close concurrently in various orders.
+			var wg sync.WaitGroup
+			wg.Add(3)
+
+			go func() { defer wg.Done(); consumer.Close() }()
+			go func() { defer wg.Done(); producer.Close() }()
+			go func() {
+				defer wg.Done()
+
+				if i%2 == 0 {
+					transport.Close()
+				}
+			}()
+
+			wg.Wait()
+			transport.Close()
+
+			assert.True(t, consumer.Closed())
+			assert.True(t, producer.Closed())
+		})
+	}
+
+	fns = append(fns, func(i int) {
+		router, err := mock.CreateRouter(stressRouterOptions)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if i%2 == 0 {
+			router.Close()
+		}
+	})
+
+	parallel(fns...)
+
+	assert.Empty(t, router.Producers())
+	assert.Len(t, mock.Routers(), 1+stressRounds/2)
+}
+
+func TestStress_CloseWorker(t *testing.T) {
+	for n := 0; n < 10; n++ {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			mock, router := newStressWorker(t)
+
+			transports := make(chan *WebRtcTransport, stressRounds)
+
+			parallel(
+				func(int) {
+					transport, err := router.CreateWebRtcTransport(WebRtcTransportOptions{
+						ListenIps: []TransportListenIp{{Ip: "127.0.0.1"}},
+					})
+					if err == nil {
+						transports <- transport
+					}
+				},
+				func(i int) {
+					if i == stressRounds/2 {
+						mock.Close()
+					}
+				},
+			)
+
+			close(transports)
+
+			for transport := range transports {
+				assert.Eventually(t, transport.Closed, time.Second, time.Millisecond)
+			}
+			assert.True(t, router.Closed())
+			assert.Empty(t, mock.Routers())
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package mediasoup
 
 import (
-	"github.com/jiyeyuran/mediasoup-go/h264"
+	"sync"
 )
 
 var supportedRtpCapabilities = RtpCapabilities{
@@ -21,9 +21,9 @@ var supportedRtpCapabilities = RtpCapabilities{
 			ClockRate: 48000,
 			Channels:  4,
 			Parameters: RtpCodecSpecificParameters{
-				ChannelMapping: "0,1,2,3",
-				NumStreams:     2,
-				CoupledStreams: 2,
+				{Key: "channel_mapping", Value: "0,1,2,3"},
+				{Key: "num_streams", Value: 2},
+				{Key: "coupled_streams", Value: 2},
 			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "transport-cc"},
@@ -35,9 +35,9 @@ var supportedRtpCapabilities = RtpCapabilities{
 			ClockRate: 48000,
 			Channels:  6,
 			Parameters: RtpCodecSpecificParameters{
-				ChannelMapping: "0,4,1,2,3,5",
-				NumStreams:     4,
-				CoupledStreams: 2,
+				{Key: "channel_mapping", Value: "0,4,1,2,3,5"},
+				{Key: "num_streams", Value: 4},
+				{Key: "coupled_streams", Value: 2},
 			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "transport-cc"},
@@ -49,9 +49,9 @@ var supportedRtpCapabilities = RtpCapabilities{
 			ClockRate: 48000,
 			Channels:  8,
 			Parameters: RtpCodecSpecificParameters{
-				ChannelMapping: "0,6,1,2,3,4,5,7",
-				NumStreams:     5,
-				CoupledStreams: 3,
+				{Key: "channel_mapping", Value: "0,6,1,2,3,4,5,7"},
+				{Key: "num_streams", Value: 5},
+				{Key: "coupled_streams", Value: 3},
 			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "transport-cc"},
@@ -158,6 +158,12 @@ var supportedRtpCapabilities = RtpCapabilities{
 			PreferredPayloadType: 13,
 			ClockRate:            8000,
 		},
+		{
+			Kind:      "audio",
+			MimeType:  "audio/red",
+			ClockRate: 48000,
+			Channels:  2,
+		},
 		{
 			Kind:      "audio",
 			MimeType:  "audio/telephone-event",
@@ -208,10 +214,8 @@ var supportedRtpCapabilities = RtpCapabilities{
 			MimeType:  "video/H264",
 			ClockRate: 90000,
 			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					PacketizationMode:     1,
-					LevelAsymmetryAllowed: 1,
-				},
+				{Key: "packetization-mode", Value: 1},
+				{Key: "level-asymmetry-allowed", Value: 1},
 			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "nack"},
@@ -226,10 +230,8 @@ var supportedRtpCapabilities = RtpCapabilities{
 			MimeType:  "video/H264",
 			ClockRate: 90000,
 			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					PacketizationMode:     0,
-					LevelAsymmetryAllowed: 1,
-				},
+				{Key: "packetization-mode", Value: 0},
+				{Key: "level-asymmetry-allowed", Value: 1},
 			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "nack"},
@@ -243,12 +245,6 @@ var supportedRtpCapabilities = RtpCapabilities{
 			Kind:      "video",
 			MimeType:  "video/H265",
 			ClockRate: 90000,
-			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					PacketizationMode:     1,
-					LevelAsymmetryAllowed: 1,
-				},
-			},
 			RtcpFeedback: []RtcpFeedback{
 				{Type: "nack"},
 				{Type: "nack", Parameter: "pli"},
@@ -259,21 +255,13 @@ var supportedRtpCapabilities = RtpCapabilities{
 		},
 		{
 			Kind:      "video",
-			MimeType:  "video/H265",
+			MimeType:  "video/red",
+			ClockRate: 90000,
+		},
+		{
+			Kind:      "video",
+			MimeType:  "video/ulpfec",
 			ClockRate: 90000,
-			Parameters: RtpCodecSpecificParameters{
-				RtpParameter: h264.RtpParameter{
-					PacketizationMode:     0,
-					LevelAsymmetryAllowed: 1,
-				},
-			},
-			RtcpFeedback: []RtcpFeedback{
-				{Type: "nack"},
-				{Type: "nack", Parameter: "pli"},
-				{Type: "ccm", Parameter: "fir"},
-				{Type: "goog-remb"},
-				{Type: "transport-cc"},
-			},
 		},
 	},
 	HeaderExtensions: []*RtpHeaderExtension{
@@ -370,9 +358,32 @@ var supportedRtpCapabilities = RtpCapabilities{
 			PreferredEncrypt: false,
 			Direction:        Direction_Sendrecv,
 		},
+		{
+			Kind:             "audio",
+			Uri:              "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			PreferredId:      13,
+			PreferredEncrypt: false,
+			Direction:        Direction_Sendrecv,
+		},
+		{
+			Kind:             "video",
+			Uri:              "http://www.webrtc.org/experiments/rtp-hdrext/abs-capture-time",
+			PreferredId:      13,
+			PreferredEncrypt: false,
+			Direction:        Direction_Sendrecv,
+		},
+		{
+			Kind:             "video",
+			Uri:              "http://www.webrtc.org/experiments/rtp-hdrext/playout-delay",
+			PreferredId:      14,
+			PreferredEncrypt: false,
+			Direction:        Direction_Sendrecv,
+		},
 	},
 }
 
+var supportedRtpCapabilitiesMu sync.RWMutex
+
 func init() {
 	if err := validateRtpCapabilities(&supportedRtpCapabilities); err != nil {
 		panic(err)
@@ -380,7 +391,113 @@ func init() {
 }
 
 func GetSupportedRtpCapabilities() (rtpCapabilities RtpCapabilities) {
+	supportedRtpCapabilitiesMu.RLock()
+	defer supportedRtpCapabilitiesMu.RUnlock()
+
 	clone(supportedRtpCapabilities, &rtpCapabilities)
 
 	return
 }
+
+/**
+ * Register a custom RTP header extension so it is included in the RTP
+ * capabilities of Routers created afterwards. The extension must be supported
+ * by the mediasoup-worker in use, otherwise it will be ignored by it.
+ *
+ * If kind is empty the extension is registered for both audio and video. The
+ * preferredId must not be used by another extension of the same kind.
+ */
+func RegisterHeaderExtension(ext RtpHeaderExtension) (err error) {
+	if err = validateRtpHeaderExtension(&ext); err != nil {
+		return
+	}
+
+	kinds := []MediaKind{ext.Kind}
+
+	if len(ext.Kind) == 0 {
+		kinds = []MediaKind{MediaKind_Audio, MediaKind_Video}
+	}
+
+	supportedRtpCapabilitiesMu.Lock()
+	defer supportedRtpCapabilitiesMu.Unlock()
+
+	for _, kind := range kinds {
+		for _, supportedExt := range supportedRtpCapabilities.HeaderExtensions {
+			if supportedExt.Kind != kind {
+				continue
+			}
+			if supportedExt.Uri == ext.Uri {
+				return NewTypeError("header extension already registered [kind:%s, uri:%s]", kind, ext.Uri)
+			}
+			if supportedExt.PreferredId == ext.PreferredId {
+				return NewTypeError("header extension preferredId %d already in use [kind:%s, uri:%s]",
+					ext.PreferredId, kind, supportedExt.Uri)
+			}
+		}
+	}
+
+	for _, kind := range kinds {
+		registeredExt := ext
+		registeredExt.Kind = kind
+
+		supportedRtpCapabilities.HeaderExtensions = append(supportedRtpCapabilities.HeaderExtensions, &registeredExt)
+	}
+
+	return
+}
+
+/**
+ * Register a custom codec so it can be used in the mediaCodecs of Routers
+ * created afterwards. It is intended to be called at program start for
+ * experimental or proprietary codecs the mediasoup-worker can forward.
+ *
+ * mimeType and clockRate are mandatory. If rtcpFeedback is nil, the default
+ * feedback of the codec kind is used (transport-cc for audio, and nack,
+ * nack pli, ccm fir, goog-remb and transport-cc for video). RTX codecs cannot
+ * be registered since they are generated for every video codec.
+ */
+func RegisterSupportedCodec(codec RtpCodecCapability) (err error) {
+	if err = validateRtpCodecCapability(&codec); err != nil {
+		return
+	}
+
+	if codec.isRtxCodec() {
+		return NewTypeError("cannot register a RTX codec")
+	}
+
+	if codec.RtcpFeedback == nil {
+		if codec.Kind == MediaKind_Audio {
+			codec.RtcpFeedback = []RtcpFeedback{
+				{Type: "transport-cc"},
+			}
+		} else {
+			codec.RtcpFeedback = []RtcpFeedback{
+				{Type: "nack"},
+				{Type: "nack", Parameter: "pli"},
+				{Type: "ccm", Parameter: "fir"},
+				{Type: "goog-remb"},
+				{Type: "transport-cc"},
+			}
+		}
+	}
+
+	supportedRtpCapabilitiesMu.Lock()
+	defer supportedRtpCapabilitiesMu.Unlock()
+
+	if _, matched := findMatchedCodec(&codec, supportedRtpCapabilities.Codecs, matchOptions{strict: true}); matched {
+		return NewTypeError("codec already registered [mimeType:%s]", codec.MimeType)
+	}
+
+	if codec.PreferredPayloadType > 0 {
+		for _, supportedCodec := range supportedRtpCapabilities.Codecs {
+			if supportedCodec.PreferredPayloadType == codec.PreferredPayloadType {
+				return NewTypeError("codec preferredPayloadType %d already in use [mimeType:%s]",
+					codec.PreferredPayloadType, supportedCodec.MimeType)
+			}
+		}
+	}
+
+	supportedRtpCapabilities.Codecs = append(supportedRtpCapabilities.Codecs, &codec)
+
+	return
+}
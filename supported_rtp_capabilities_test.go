@@ -0,0 +1,93 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterHeaderExtension(t *testing.T) {
+	headerExtensions := supportedRtpCapabilities.HeaderExtensions
+	defer func() {
+		supportedRtpCapabilities.HeaderExtensions = headerExtensions
+	}()
+
+	uri := "urn:example:params:rtp-hdrext:custom"
+
+	err := RegisterHeaderExtension(RtpHeaderExtension{Uri: uri, PreferredId: 15})
+	require.NoError(t, err)
+
+	var registered []*RtpHeaderExtension
+
+	for _, ext := range GetSupportedRtpCapabilities().HeaderExtensions {
+		if ext.Uri == uri {
+			registered = append(registered, ext)
+		}
+	}
+	require.Len(t, registered, 2)
+	assert.Equal(t, MediaKind_Audio, registered[0].Kind)
+	assert.Equal(t, MediaKind_Video, registered[1].Kind)
+	assert.Equal(t, Direction_Sendrecv, registered[1].Direction)
+
+	// Already registered.
+	err = RegisterHeaderExtension(RtpHeaderExtension{Kind: "video", Uri: uri, PreferredId: 16})
+	assert.IsType(t, TypeError{}, err)
+
+	// preferredId used by abs-capture-time.
+	err = RegisterHeaderExtension(RtpHeaderExtension{Kind: "audio", Uri: "urn:example:other", PreferredId: 13})
+	assert.IsType(t, TypeError{}, err)
+
+	// Invalid extension.
+	err = RegisterHeaderExtension(RtpHeaderExtension{Kind: "audio", Uri: "urn:example:other"})
+	assert.IsType(t, TypeError{}, err)
+}
+
+func TestRegisterSupportedCodec(t *testing.T) {
+	codecs := supportedRtpCapabilities.Codecs
+	defer func() {
+		supportedRtpCapabilities.Codecs = codecs
+	}()
+
+	err := RegisterSupportedCodec(RtpCodecCapability{
+		MimeType:  "video/x-custom",
+		ClockRate: 90000,
+	})
+	require.NoError(t, err)
+
+	caps, err := generateRouterRtpCapabilities([]*RtpCodecCapability{
+		{Kind: "video", MimeType: "video/X-CUSTOM", ClockRate: 90000},
+	})
+	require.NoError(t, err)
+	require.Len(t, caps.Codecs, 2)
+	assert.Equal(t, MediaKind_Video, caps.Codecs[0].Kind)
+	assert.Len(t, caps.Codecs[0].RtcpFeedback, 5)
+	assert.Equal(t, "video/rtx", caps.Codecs[1].MimeType)
+
+	err = RegisterSupportedCodec(RtpCodecCapability{
+		MimeType:     "audio/x-custom",
+		ClockRate:    16000,
+		RtcpFeedback: []RtcpFeedback{},
+	})
+	require.NoError(t, err)
+
+	audioCodec := supportedRtpCapabilities.Codecs[len(supportedRtpCapabilities.Codecs)-1]
+	assert.EqualValues(t, 1, audioCodec.Channels)
+	assert.Empty(t, audioCodec.RtcpFeedback)
+
+	// Already registered.
+	err = RegisterSupportedCodec(RtpCodecCapability{MimeType: "video/x-custom", ClockRate: 90000})
+	assert.IsType(t, TypeError{}, err)
+
+	// preferredPayloadType used by PCMA.
+	err = RegisterSupportedCodec(RtpCodecCapability{MimeType: "audio/x-other", ClockRate: 8000, PreferredPayloadType: 8})
+	assert.IsType(t, TypeError{}, err)
+
+	// RTX codec.
+	err = RegisterSupportedCodec(RtpCodecCapability{MimeType: "video/rtx", ClockRate: 90000})
+	assert.IsType(t, TypeError{}, err)
+
+	// Missing clockRate.
+	err = RegisterSupportedCodec(RtpCodecCapability{MimeType: "video/x-other"})
+	assert.IsType(t, TypeError{}, err)
+}
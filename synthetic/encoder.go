@@ -0,0 +1,371 @@
+package synthetic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+/**
+ * AudioEncoder encodes PCM into the RTP payloads of an audio codec.
+ */
+type AudioEncoder interface {
+	/**
+	 * Codec of the payloads, with its default payload type.
+	 */
+	Codec() mediasoup.RtpCodecParameters
+
+	/**
+	 * Sample rate of the encoded PCM.
+	 */
+	SampleRate() int
+
+	/**
+	 * Number of channels of the encoded PCM.
+	 */
+	Channels() int
+
+	/**
+	 * Encode pcm (interleaved 16-bit samples) into data, returning the size of
+	 * the payload.
+	 */
+	Encode(pcm []int16, data []byte) (n int, err error)
+}
+
+/**
+ * NewPCMUEncoder creates the G.711 mu-law encoder, encoding 8000 Hz mono PCM.
+ */
+func NewPCMUEncoder() AudioEncoder {
+	return pcmuEncoder{}
+}
+
+type pcmuEncoder struct{}
+
+func (pcmuEncoder) Codec() mediasoup.RtpCodecParameters {
+	return mediasoup.RtpCodecParameters{
+		MimeType:    "audio/PCMU",
+		PayloadType: 0,
+		ClockRate:   8000,
+		Channels:    1,
+	}
+}
+
+func (pcmuEncoder) SampleRate() int {
+	return 8000
+}
+
+func (pcmuEncoder) Channels() int {
+	return 1
+}
+
+func (pcmuEncoder) Encode(pcm []int16, data []byte) (n int, err error) {
+	if len(data) < len(pcm) {
+		return 0, mediasoup.NewTypeError("buffer too small")
+	}
+	for i, sample := range pcm {
+		data[i] = linearToUlaw(sample)
+	}
+	return len(pcm), nil
+}
+
+// linearToUlaw encodes a G.711 mu-law sample.
+func linearToUlaw(sample int16) byte {
+	const (
+		bias = 0x84
+		clip = 32635
+	)
+
+	s := int(sample)
+	sign := 0
+
+	if s < 0 {
+		s, sign = -s, 0x80
+	}
+	if s > clip {
+		s = clip
+	}
+	s += bias
+
+	exponent := 7
+
+	for mask := 0x4000; s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (s >> uint(exponent+3)) & 0x0f
+
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+/**
+ * EncodedFrame is a frame given by a VideoEncoder.
+ */
+type EncodedFrame struct {
+	Data []byte
+
+	/**
+	 * Presentation time of the frame, relative to the first frame.
+	 */
+	Time time.Duration
+}
+
+/**
+ * VideoEncoder encodes raw frames into the frames of a video codec, possibly
+ * asynchronously.
+ */
+type VideoEncoder interface {
+	/**
+	 * Codec of the frames, with its default payload type.
+	 */
+	Codec() mediasoup.RtpCodecParameters
+
+	/**
+	 * Encode the frame, a 4:2:0 image of the size of the video.
+	 */
+	Encode(frame *image.YCbCr) error
+
+	/**
+	 * Encoded frames, in order. The channel is closed once the encoder is
+	 * closed.
+	 */
+	Frames() <-chan EncodedFrame
+
+	Close() error
+}
+
+type VP8EncoderOptions struct {
+	Width  int
+	Height int
+
+	/**
+	 * Frames per second. Default 30.
+	 */
+	FrameRate int
+
+	/**
+	 * Target bitrate, in bps. Default 1000000.
+	 */
+	Bitrate int
+
+	/**
+	 * Maximum interval between two keyframes. Default 1 second.
+	 */
+	KeyframeInterval time.Duration
+
+	/**
+	 * Path of the FFmpeg binary encoding the frames. Default "ffmpeg".
+	 */
+	FFmpegPath string
+
+	/**
+	 * Logger of the encoder. Default mediasoup.NewLogger("VP8Encoder").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * NewVP8Encoder creates a VP8 encoder running FFmpeg (libvpx) in realtime
+ * mode, the raw frames being written to its stdin and the encoded frames read
+ * from its stdout.
+ */
+func NewVP8Encoder(options VP8EncoderOptions) (encoder VideoEncoder, err error) {
+	if options.Width <= 0 || options.Height <= 0 || options.Width%2 != 0 || options.Height%2 != 0 {
+		return nil, mediasoup.NewTypeError("invalid size %dx%d", options.Width, options.Height)
+	}
+	if options.FrameRate <= 0 {
+		options.FrameRate = 30
+	}
+	if options.Bitrate <= 0 {
+		options.Bitrate = 1000000
+	}
+	if options.KeyframeInterval <= 0 {
+		options.KeyframeInterval = time.Second
+	}
+	if len(options.FFmpegPath) == 0 {
+		options.FFmpegPath = "ffmpeg"
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("VP8Encoder")
+	}
+
+	e := &vp8Encoder{
+		logger:  options.Logger,
+		options: options,
+		frames:  make(chan EncodedFrame, 8),
+		raw:     make([]byte, options.Width*options.Height*3/2),
+	}
+
+	e.cmd = exec.Command(options.FFmpegPath, encodeArgs(options)...)
+	e.cmd.Stderr = &e.stderr
+
+	if e.stdin, err = e.cmd.StdinPipe(); err != nil {
+		return
+	}
+
+	stdout, err := e.cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+
+	if err = e.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting ffmpeg failed: %v", err)
+	}
+
+	go e.readFrames(stdout)
+
+	return e, nil
+}
+
+// encodeArgs returns the arguments of FFmpeg encoding raw frames from stdin
+// into an IVF stream written to stdout.
+func encodeArgs(options VP8EncoderOptions) []string {
+	gop := int(options.KeyframeInterval.Seconds() * float64(options.FrameRate))
+
+	if gop < 1 {
+		gop = 1
+	}
+
+	return []string{
+		"-hide_banner", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "yuv420p",
+		"-video_size", fmt.Sprintf("%dx%d", options.Width, options.Height),
+		"-framerate", strconv.Itoa(options.FrameRate),
+		"-i", "pipe:0",
+		"-c:v", "libvpx", "-deadline", "realtime", "-cpu-used", "8",
+		"-lag-in-frames", "0", "-auto-alt-ref", "0", "-error-resilient", "1",
+		"-b:v", strconv.Itoa(options.Bitrate), "-g", strconv.Itoa(gop),
+		"-f", "ivf", "-flush_packets", "1", "pipe:1",
+	}
+}
+
+type vp8Encoder struct {
+	logger    mediasoup.Logger
+	options   VP8EncoderOptions
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stderr    bytes.Buffer
+	frames    chan EncodedFrame
+	raw       []byte
+	closeOnce sync.Once
+}
+
+func (e *vp8Encoder) Codec() mediasoup.RtpCodecParameters {
+	return mediasoup.RtpCodecParameters{
+		MimeType:    "video/VP8",
+		PayloadType: 101,
+		ClockRate:   90000,
+	}
+}
+
+func (e *vp8Encoder) Encode(frame *image.YCbCr) (err error) {
+	width, height := e.options.Width, e.options.Height
+
+	if frame.Rect.Dx() != width || frame.Rect.Dy() != height {
+		return mediasoup.NewTypeError("frame size %dx%d is not %dx%d", frame.Rect.Dx(), frame.Rect.Dy(), width, height)
+	}
+
+	// Planes of a yuv420p frame.
+	raw := e.raw[:0]
+
+	for y := 0; y < height; y++ {
+		offset := frame.YOffset(frame.Rect.Min.X, frame.Rect.Min.Y+y)
+		raw = append(raw, frame.Y[offset:offset+width]...)
+	}
+	for _, plane := range [][]byte{frame.Cb, frame.Cr} {
+		for y := 0; y < height; y += 2 {
+			offset := frame.COffset(frame.Rect.Min.X, frame.Rect.Min.Y+y)
+			raw = append(raw, plane[offset:offset+width/2]...)
+		}
+	}
+
+	if _, err = e.stdin.Write(raw); err != nil {
+		return fmt.Errorf("writing frame to ffmpeg failed: %v", err)
+	}
+
+	return
+}
+
+func (e *vp8Encoder) Frames() <-chan EncodedFrame {
+	return e.frames
+}
+
+// readFrames reads the frames of the IVF stream until ffmpeg exits.
+func (e *vp8Encoder) readFrames(stdout io.Reader) {
+	defer close(e.frames)
+
+	err := readIvf(stdout, func(frame EncodedFrame) {
+		e.frames <- frame
+	})
+
+	if waitErr := e.cmd.Wait(); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	if err != nil && err != io.EOF {
+		e.logger.Error("encoding failed: %v: %s", err, strings.TrimSpace(e.stderr.String()))
+	}
+}
+
+// readIvf reads the frames of an IVF stream, their time being given by the
+// time base of the stream.
+func readIvf(r io.Reader, onFrame func(frame EncodedFrame)) (err error) {
+	header := make([]byte, 32)
+
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	if string(header[:4]) != "DKIF" {
+		return fmt.Errorf("invalid IVF signature %q", header[:4])
+	}
+
+	headerSize := int(binary.LittleEndian.Uint16(header[6:]))
+	den := int64(binary.LittleEndian.Uint32(header[16:]))
+	num := int64(binary.LittleEndian.Uint32(header[20:]))
+
+	if den == 0 {
+		return fmt.Errorf("invalid IVF time base")
+	}
+	if headerSize > len(header) {
+		if _, err = io.CopyN(ioutil.Discard, r, int64(headerSize-len(header))); err != nil {
+			return
+		}
+	}
+
+	frameHeader := make([]byte, 12)
+
+	for {
+		if _, err = io.ReadFull(r, frameHeader); err != nil {
+			return
+		}
+
+		data := make([]byte, binary.LittleEndian.Uint32(frameHeader))
+		pts := int64(binary.LittleEndian.Uint64(frameHeader[4:]))
+
+		if _, err = io.ReadFull(r, data); err != nil {
+			return
+		}
+
+		onFrame(EncodedFrame{
+			Data: data,
+			Time: time.Duration(pts * num * int64(time.Second) / den),
+		})
+	}
+}
+
+func (e *vp8Encoder) Close() error {
+	e.closeOnce.Do(func() {
+		e.logger.Debug("Close()")
+
+		e.stdin.Close()
+	})
+
+	return nil
+}
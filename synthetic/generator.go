@@ -0,0 +1,471 @@
+package synthetic
+
+import (
+	"encoding/binary"
+	"image"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+type AudioOptions struct {
+	/**
+	 * DirectTransport of the Producer.
+	 */
+	Transport *mediasoup.DirectTransport
+
+	/**
+	 * Encoder of the tone. Default NewPCMUEncoder().
+	 */
+	Encoder AudioEncoder
+
+	/**
+	 * Frequency of the tone, in Hz. Default 440.
+	 */
+	Frequency float64
+
+	/**
+	 * Amplitude of the tone, from 0 to 1. Default 0.5.
+	 */
+	Amplitude float64
+
+	/**
+	 * Duration of the audio carried by a packet. Default 20 ms.
+	 */
+	PacketDuration time.Duration
+
+	/**
+	 * Payload type of the RTP packets. Default the one of the encoder codec.
+	 */
+	PayloadType byte
+
+	/**
+	 * SSRC of the RTP packets. Default random.
+	 */
+	Ssrc uint32
+
+	/**
+	 * AppData of the Producer.
+	 */
+	AppData interface{}
+
+	/**
+	 * Logger of the generator. Default mediasoup.NewLogger("AudioGenerator").
+	 */
+	Logger mediasoup.Logger
+}
+
+type VideoOptions struct {
+	/**
+	 * DirectTransport of the Producer.
+	 */
+	Transport *mediasoup.DirectTransport
+
+	/**
+	 * Pattern of the video. Default ColorBars.
+	 */
+	Pattern Pattern
+
+	/**
+	 * Size of the video. Default 640x360.
+	 */
+	Width  int
+	Height int
+
+	/**
+	 * Frames per second. Default 30.
+	 */
+	FrameRate int
+
+	/**
+	 * Encoder of the video, of the given size and frame rate. Default a VP8
+	 * encoder running FFmpeg, created with NewVP8Encoder().
+	 */
+	Encoder VideoEncoder
+
+	/**
+	 * Maximum size of the RTP payloads. Default 1200 bytes.
+	 */
+	MaxPayloadSize int
+
+	/**
+	 * Payload type of the RTP packets. Default the one of the encoder codec.
+	 */
+	PayloadType byte
+
+	/**
+	 * SSRC of the RTP packets. Default random.
+	 */
+	Ssrc uint32
+
+	/**
+	 * AppData of the Producer.
+	 */
+	AppData interface{}
+
+	/**
+	 * Logger of the generator. Default mediasoup.NewLogger("VideoGenerator").
+	 */
+	Logger mediasoup.Logger
+}
+
+/**
+ * Generator sends the RTP of a test signal through a Producer of a
+ * DirectTransport. It is closed when the Producer is closed.
+ * @emits error - (err error)
+ * @emits close
+ */
+type Generator struct {
+	mediasoup.IEventEmitter
+	logger    mediasoup.Logger
+	producer  *mediasoup.Producer
+	header    rtpHeader
+	packets   int64
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	onClose   func()
+}
+
+// rtpHeader numbers the packets of a stream.
+type rtpHeader struct {
+	payloadType    byte
+	ssrc           uint32
+	sequenceNumber uint16
+}
+
+// packet returns a packet of the stream carrying the payload.
+func (h *rtpHeader) packet(timestamp uint32, marker bool, payload []byte) []byte {
+	packet := make([]byte, 12+len(payload))
+	packet[0] = 0x80
+	packet[1] = h.payloadType & 0x7f
+
+	if marker {
+		packet[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(packet[2:], h.sequenceNumber)
+	binary.BigEndian.PutUint32(packet[4:], timestamp)
+	binary.BigEndian.PutUint32(packet[8:], h.ssrc)
+	copy(packet[12:], payload)
+
+	h.sequenceNumber++
+
+	return packet
+}
+
+func newGenerator(
+	transport *mediasoup.DirectTransport,
+	kind mediasoup.MediaKind,
+	codec mediasoup.RtpCodecParameters,
+	payloadType byte,
+	ssrc uint32,
+	appData interface{},
+	logger mediasoup.Logger,
+) (g *Generator, err error) {
+	if payloadType != 0 {
+		codec.PayloadType = payloadType
+	}
+	if ssrc == 0 {
+		ssrc = rand.Uint32()
+	}
+
+	producer, err := transport.Produce(mediasoup.ProducerOptions{
+		Kind: kind,
+		RtpParameters: mediasoup.RtpParameters{
+			Codecs:    []*mediasoup.RtpCodecParameters{&codec},
+			Encodings: []mediasoup.RtpEncodingParameters{{Ssrc: ssrc}},
+		},
+		AppData: appData,
+	})
+	if err != nil {
+		return
+	}
+
+	g = &Generator{
+		IEventEmitter: mediasoup.NewEventEmitter(),
+		logger:        logger,
+		producer:      producer,
+		header: rtpHeader{
+			payloadType:    codec.PayloadType,
+			ssrc:           ssrc,
+			sequenceNumber: uint16(rand.Uint32()),
+		},
+		closeCh: make(chan struct{}),
+	}
+
+	producer.Observer().Once("close", func() { g.Close() })
+
+	return
+}
+
+/**
+ * Create a Producer sending a sine tone.
+ */
+func NewAudioGenerator(options AudioOptions) (g *Generator, err error) {
+	if options.Transport == nil {
+		return nil, mediasoup.NewTypeError("missing Transport")
+	}
+	if options.Encoder == nil {
+		options.Encoder = NewPCMUEncoder()
+	}
+	if options.Frequency <= 0 {
+		options.Frequency = 440
+	}
+	if options.Amplitude <= 0 {
+		options.Amplitude = 0.5
+	}
+	if options.PacketDuration <= 0 {
+		options.PacketDuration = 20 * time.Millisecond
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("AudioGenerator")
+	}
+
+	encoder := options.Encoder
+	codec := encoder.Codec()
+
+	g, err = newGenerator(options.Transport, mediasoup.MediaKind_Audio, codec,
+		options.PayloadType, options.Ssrc, options.AppData, options.Logger)
+	if err != nil {
+		return
+	}
+
+	tone := NewSineTone(options.Frequency, options.Amplitude, encoder.SampleRate(), encoder.Channels())
+	samples := int(int64(encoder.SampleRate()) * int64(options.PacketDuration) / int64(time.Second))
+	step := uint32(int64(codec.ClockRate) * int64(options.PacketDuration) / int64(time.Second))
+
+	go g.runAudio(tone, encoder, samples, step, options.PacketDuration)
+
+	return
+}
+
+func (g *Generator) runAudio(tone *SineTone, encoder AudioEncoder, samples int, step uint32, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pcm := make([]int16, samples*encoder.Channels())
+	data := make([]byte, 4000)
+	timestamp := rand.Uint32()
+
+	for {
+		select {
+		case <-ticker.C:
+			tone.Read(pcm)
+
+			n, err := encoder.Encode(pcm, data)
+			if err != nil {
+				g.fail(err)
+				return
+			}
+
+			g.send(g.header.packet(timestamp, false, data[:n]))
+			timestamp += step
+
+		case <-g.closeCh:
+			return
+		}
+	}
+}
+
+/**
+ * Create a Producer sending a video pattern.
+ */
+func NewVideoGenerator(options VideoOptions) (g *Generator, err error) {
+	if options.Transport == nil {
+		return nil, mediasoup.NewTypeError("missing Transport")
+	}
+	if len(options.Pattern) == 0 {
+		options.Pattern = ColorBars
+	}
+	if options.Width <= 0 || options.Height <= 0 {
+		options.Width, options.Height = 640, 360
+	}
+	if options.FrameRate <= 0 {
+		options.FrameRate = 30
+	}
+	if options.MaxPayloadSize <= 0 {
+		options.MaxPayloadSize = 1200
+	}
+	if options.Logger == nil {
+		options.Logger = mediasoup.NewLogger("VideoGenerator")
+	}
+
+	encoder := options.Encoder
+
+	if encoder == nil {
+		encoder, err = NewVP8Encoder(VP8EncoderOptions{
+			Width:     options.Width,
+			Height:    options.Height,
+			FrameRate: options.FrameRate,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	codec := encoder.Codec()
+
+	if strings.ToLower(codec.MimeType) != "video/vp8" {
+		encoder.Close()
+		return nil, mediasoup.NewUnsupportedError("unsupported codec %s", codec.MimeType)
+	}
+
+	g, err = newGenerator(options.Transport, mediasoup.MediaKind_Video, codec,
+		options.PayloadType, options.Ssrc, options.AppData, options.Logger)
+	if err != nil {
+		encoder.Close()
+		return
+	}
+	g.onClose = func() { encoder.Close() }
+
+	go g.runVideo(encoder, options)
+	go g.sendVideo(encoder, options.MaxPayloadSize)
+
+	return
+}
+
+// runVideo draws the frames of the pattern, given to the encoder.
+func (g *Generator) runVideo(encoder VideoEncoder, options VideoOptions) {
+	ticker := time.NewTicker(time.Second / time.Duration(options.FrameRate))
+	defer ticker.Stop()
+
+	frame := image.NewYCbCr(image.Rect(0, 0, options.Width, options.Height), image.YCbCrSubsampleRatio420)
+
+	for n := 0; ; n++ {
+		DrawPattern(frame, options.Pattern, n)
+
+		if err := encoder.Encode(frame); err != nil {
+			g.fail(err)
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-g.closeCh:
+			return
+		}
+	}
+}
+
+// sendVideo packetizes the encoded frames until the encoder is closed.
+func (g *Generator) sendVideo(encoder VideoEncoder, maxPayloadSize int) {
+	base := rand.Uint32()
+	pictureId := uint16(rand.Uint32())
+
+	for frame := range encoder.Frames() {
+		timestamp := base + uint32(int64(frame.Time)*90000/int64(time.Second))
+		payloads := vp8Payloads(frame.Data, pictureId, maxPayloadSize)
+
+		for i, payload := range payloads {
+			g.send(g.header.packet(timestamp, i == len(payloads)-1, payload))
+		}
+
+		pictureId = (pictureId + 1) & 0x7fff
+	}
+}
+
+// vp8Payloads splits the frame into RTP payloads (RFC 7741), each starting with
+// a payload descriptor carrying a 15-bit picture ID.
+func vp8Payloads(frame []byte, pictureId uint16, maxPayloadSize int) (payloads [][]byte) {
+	const descriptorSize = 4
+
+	chunkSize := maxPayloadSize - descriptorSize
+
+	for offset := 0; offset < len(frame) || offset == 0; offset += chunkSize {
+		end := offset + chunkSize
+
+		if end > len(frame) {
+			end = len(frame)
+		}
+
+		payload := make([]byte, descriptorSize, descriptorSize+end-offset)
+		payload[0] = 0x80 // X
+
+		if offset == 0 {
+			payload[0] |= 0x10 // S, partition 0
+		}
+		payload[1] = 0x80 // I
+		binary.BigEndian.PutUint16(payload[2:], 0x8000|pictureId&0x7fff)
+
+		payloads = append(payloads, append(payload, frame[offset:end]...))
+
+		if end == len(frame) {
+			break
+		}
+	}
+
+	return
+}
+
+func (g *Generator) send(packet []byte) {
+	if err := g.producer.Send(packet); err != nil {
+		if !g.Closed() && !g.producer.Closed() {
+			g.logger.Warn("sending packet failed: %v", err)
+		}
+		return
+	}
+	atomic.AddInt64(&g.packets, 1)
+}
+
+func (g *Generator) fail(err error) {
+	if g.Closed() {
+		return
+	}
+	g.logger.Error("generating media failed: %v", err)
+	g.SafeEmit("error", err)
+	g.Close()
+}
+
+/**
+ * Producer sending the signal.
+ */
+func (g *Generator) Producer() *mediasoup.Producer {
+	return g.producer
+}
+
+/**
+ * Number of RTP packets sent so far.
+ */
+func (g *Generator) Packets() int64 {
+	return atomic.LoadInt64(&g.packets)
+}
+
+/**
+ * Whether the generator is closed.
+ */
+func (g *Generator) Closed() bool {
+	select {
+	case <-g.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+/**
+ * Stop generating and close the Producer.
+ */
+func (g *Generator) Close() (err error) {
+	err = mediasoup.ErrAlreadyClosed
+
+	g.closeOnce.Do(func() {
+		g.logger.Debug("Close()")
+
+		close(g.closeCh)
+
+		if g.onClose != nil {
+			g.onClose()
+		}
+		if !g.producer.Closed() {
+			g.producer.Close()
+		}
+		g.SafeEmit("close")
+
+		err = nil
+	})
+
+	return
+}
@@ -0,0 +1,61 @@
+// Package opus provides the Opus encoder of the synthetic package, using
+// libopus through cgo. It is a separate module so that the mediasoup-go module
+// does not require cgo nor libopus.
+package opus
+
+import (
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/synthetic"
+	opus "gopkg.in/hraban/opus.v2"
+)
+
+/**
+ * NewEncoder creates the synthetic.AudioEncoder encoding 48000 Hz PCM of the
+ * given number of channels (1 or 2) in Opus at the given bitrate (bps), 0
+ * letting libopus choose it.
+ */
+func NewEncoder(channels, bitrate int) (synthetic.AudioEncoder, error) {
+	encoder, err := opus.NewEncoder(48000, channels, opus.AppAudio)
+	if err != nil {
+		return nil, err
+	}
+	if bitrate > 0 {
+		if err = encoder.SetBitrate(bitrate); err != nil {
+			return nil, err
+		}
+	}
+
+	return &opusEncoder{encoder: encoder, channels: channels}, nil
+}
+
+type opusEncoder struct {
+	encoder  *opus.Encoder
+	channels int
+}
+
+func (e *opusEncoder) Codec() mediasoup.RtpCodecParameters {
+	codec := mediasoup.RtpCodecParameters{
+		MimeType:    "audio/opus",
+		PayloadType: 100,
+		ClockRate:   48000,
+		Channels:    2,
+	}
+
+	if e.channels == 2 {
+		codec.Parameters.Set("sprop-stereo", 1)
+	}
+
+	return codec
+}
+
+func (e *opusEncoder) SampleRate() int {
+	return 48000
+}
+
+func (e *opusEncoder) Channels() int {
+	return e.channels
+}
+
+func (e *opusEncoder) Encode(pcm []int16, data []byte) (int, error) {
+	return e.encoder.Encode(pcm, data)
+}
@@ -0,0 +1,166 @@
+// Package synthetic generates the RTP of test signals, a sine tone or a video
+// pattern, sent through a Producer of a DirectTransport, so that integration
+// tests and demos can run media end-to-end without real clients:
+//
+//	transport, _ := router.CreateDirectTransport()
+//	tone, err := synthetic.NewAudioGenerator(synthetic.AudioOptions{
+//		Transport: transport,
+//		Encoder:   opusEncoder, // from the synthetic/opus module
+//	})
+//	bars, err := synthetic.NewVideoGenerator(synthetic.VideoOptions{
+//		Transport: transport,
+//		Pattern:   synthetic.ColorBars,
+//	})
+//	// Consume tone.Producer() and bars.Producer() as any other Producer.
+//
+// Audio is encoded in PCMU by default, in Opus with the encoder of the
+// synthetic/opus module (libopus through cgo). Video is encoded in VP8 with
+// FFmpeg (libvpx) by default, no VP8 encoder being available in Go.
+package synthetic
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+/**
+ * SineTone generates the PCM of a sine wave.
+ */
+type SineTone struct {
+	frequency  float64
+	amplitude  float64
+	sampleRate int
+	channels   int
+	phase      float64
+}
+
+/**
+ * Create a sine tone of the given frequency (Hz) and amplitude (0 to 1, full
+ * scale being 1), every channel carrying the same wave.
+ */
+func NewSineTone(frequency, amplitude float64, sampleRate, channels int) *SineTone {
+	return &SineTone{
+		frequency:  frequency,
+		amplitude:  math.Max(0, math.Min(amplitude, 1)),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+}
+
+/**
+ * Fill pcm with the next samples (interleaved 16-bit), returning the number of
+ * samples per channel.
+ */
+func (s *SineTone) Read(pcm []int16) (samples int) {
+	step := 2 * math.Pi * s.frequency / float64(s.sampleRate)
+
+	for ; (samples+1)*s.channels <= len(pcm); samples++ {
+		value := int16(s.amplitude * math.MaxInt16 * math.Sin(s.phase))
+
+		for c := 0; c < s.channels; c++ {
+			pcm[samples*s.channels+c] = value
+		}
+
+		s.phase += step
+
+		if s.phase >= 2*math.Pi {
+			s.phase -= 2 * math.Pi
+		}
+	}
+
+	return
+}
+
+type Pattern string
+
+const (
+	/**
+	 * Vertical color bars above a strip crossed by a moving marker.
+	 */
+	ColorBars Pattern = "colorbars"
+
+	/**
+	 * White box bouncing over a gray background.
+	 */
+	MovingBox Pattern = "movingbox"
+)
+
+// 75% color bars: white, yellow, cyan, green, magenta, red and blue.
+var colorBars = []color.RGBA{
+	{191, 191, 191, 255},
+	{191, 191, 0, 255},
+	{0, 191, 191, 255},
+	{0, 191, 0, 255},
+	{191, 0, 191, 255},
+	{191, 0, 0, 255},
+	{0, 0, 191, 255},
+}
+
+/**
+ * Draw the given frame (counted from 0) of the pattern into img, a 4:2:0
+ * image.
+ */
+func DrawPattern(img *image.YCbCr, pattern Pattern, frame int) {
+	bounds := img.Rect
+	width, height := bounds.Dx(), bounds.Dy()
+
+	switch pattern {
+	case MovingBox:
+		fillRect(img, bounds, color.RGBA{64, 64, 64, 255})
+
+		size := height / 4
+		x := bounce(frame*4, width-size)
+		y := bounce(frame*3, height-size)
+
+		fillRect(img, image.Rect(x, y, x+size, y+size).Add(bounds.Min), color.RGBA{255, 255, 255, 255})
+
+	default:
+		barsHeight := height * 3 / 4
+
+		for i, c := range colorBars {
+			x0, x1 := width*i/len(colorBars), width*(i+1)/len(colorBars)
+			fillRect(img, image.Rect(x0, 0, x1, barsHeight).Add(bounds.Min), c)
+		}
+
+		fillRect(img, image.Rect(0, barsHeight, width, height).Add(bounds.Min), color.RGBA{16, 16, 16, 255})
+
+		markerWidth := width / 16
+		x := bounce(frame*4, width-markerWidth)
+
+		fillRect(img, image.Rect(x, barsHeight, x+markerWidth, height).Add(bounds.Min), color.RGBA{255, 255, 255, 255})
+	}
+}
+
+// bounce returns the position, from 0 to max, of an object moving back and
+// forth which has travelled the given distance.
+func bounce(distance, max int) int {
+	if max <= 0 {
+		return 0
+	}
+
+	distance %= 2 * max
+
+	if distance > max {
+		return 2*max - distance
+	}
+	return distance
+}
+
+// fillRect fills the rectangle of a 4:2:0 image with the color.
+func fillRect(img *image.YCbCr, rect image.Rectangle, c color.RGBA) {
+	rect = rect.Intersect(img.Rect)
+	y, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			img.Y[img.YOffset(px, py)] = y
+
+			if px%2 == 0 && py%2 == 0 {
+				offset := img.COffset(px, py)
+				img.Cb[offset] = cb
+				img.Cr[offset] = cr
+			}
+		}
+	}
+}
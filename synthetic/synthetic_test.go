@@ -0,0 +1,189 @@
+package synthetic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/mediasoup-go"
+	"github.com/jiyeyuran/mediasoup-go/audiobridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGenerator_InvalidOptions(t *testing.T) {
+	_, err := NewAudioGenerator(AudioOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewVideoGenerator(VideoOptions{})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+
+	_, err = NewVP8Encoder(VP8EncoderOptions{Width: 641, Height: 360})
+	assert.IsType(t, mediasoup.TypeError{}, err)
+}
+
+func TestSineTone(t *testing.T) {
+	tone := NewSineTone(1000, 0.5, 8000, 2)
+	pcm := make([]int16, 8000*2)
+
+	assert.Equal(t, 8000, tone.Read(pcm))
+
+	// 1000 periods, thus 2000 zero crossings.
+	crossings := 0
+	peak := int16(0)
+
+	for i := 2; i < len(pcm); i += 2 {
+		assert.Equal(t, pcm[i], pcm[i+1])
+
+		if (pcm[i-2] < 0) != (pcm[i] < 0) {
+			crossings++
+		}
+		if pcm[i] > peak {
+			peak = pcm[i]
+		}
+	}
+
+	assert.InDelta(t, 2000, crossings, 2)
+	assert.InDelta(t, 16383, peak, 2)
+}
+
+func TestPCMUEncoder(t *testing.T) {
+	encoder := NewPCMUEncoder()
+	decoder, err := audiobridge.NewG711Decoder(&mediasoup.RtpCodecParameters{MimeType: "audio/PCMU"})
+	require.NoError(t, err)
+
+	pcm := []int16{0, 100, -100, 1000, -1000, 10000, -10000, 32767, -32768}
+	data := make([]byte, len(pcm))
+
+	n, err := encoder.Encode(pcm, data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(pcm), n)
+
+	decoded := make([]int16, len(pcm))
+	_, err = decoder.Decode(data, decoded)
+	assert.NoError(t, err)
+
+	for i, sample := range pcm {
+		// Quantization error of mu-law: less than 1/16 of the magnitude.
+		tolerance := math.Abs(float64(sample))/16 + 8
+		assert.InDelta(t, sample, decoded[i], tolerance, "sample %d", sample)
+	}
+}
+
+func TestDrawPattern(t *testing.T) {
+	img := image.NewYCbCr(image.Rect(0, 0, 140, 80), image.YCbCrSubsampleRatio420)
+
+	DrawPattern(img, ColorBars, 0)
+
+	// Yellow bar, then the blue one.
+	y, cb, cr := color.RGBToYCbCr(191, 191, 0)
+	assert.Equal(t, color.YCbCr{Y: y, Cb: cb, Cr: cr}, img.YCbCrAt(30, 10))
+
+	y, cb, cr = color.RGBToYCbCr(0, 0, 191)
+	assert.Equal(t, color.YCbCr{Y: y, Cb: cb, Cr: cr}, img.YCbCrAt(130, 10))
+
+	// The marker moves.
+	assert.EqualValues(t, 255, img.YCbCrAt(0, 70).Y)
+	DrawPattern(img, ColorBars, 10)
+	assert.NotEqual(t, uint8(255), img.YCbCrAt(0, 70).Y)
+	assert.EqualValues(t, 255, img.YCbCrAt(40, 70).Y)
+
+	DrawPattern(img, MovingBox, 0)
+	assert.EqualValues(t, 255, img.YCbCrAt(0, 0).Y)
+	assert.NotEqual(t, uint8(255), img.YCbCrAt(100, 60).Y)
+}
+
+func TestBounce(t *testing.T) {
+	assert.Equal(t, 0, bounce(0, 10))
+	assert.Equal(t, 7, bounce(7, 10))
+	assert.Equal(t, 8, bounce(12, 10))
+	assert.Equal(t, 0, bounce(20, 10))
+	assert.Equal(t, 3, bounce(23, 10))
+	assert.Equal(t, 0, bounce(5, 0))
+}
+
+func TestVp8Payloads(t *testing.T) {
+	frame := make([]byte, 2500)
+	payloads := vp8Payloads(frame, 0x1234, 1000)
+
+	assert.Len(t, payloads, 3)
+	assert.Equal(t, []byte{0x90, 0x80, 0x92, 0x34}, payloads[0][:4])
+	assert.Equal(t, []byte{0x80, 0x80, 0x92, 0x34}, payloads[1][:4])
+	assert.Len(t, payloads[0], 1000)
+	assert.Len(t, payloads[2], 4+2500-2*996)
+
+	assert.Len(t, vp8Payloads(nil, 0, 1000), 1)
+}
+
+func TestRtpHeader(t *testing.T) {
+	header := rtpHeader{payloadType: 101, ssrc: 1234, sequenceNumber: 0xffff}
+
+	packet := header.packet(5000, true, []byte{1, 2, 3})
+	assert.Equal(t, []byte{0x80, 0x80 | 101, 0xff, 0xff}, packet[:4])
+	assert.EqualValues(t, 5000, binary.BigEndian.Uint32(packet[4:]))
+	assert.EqualValues(t, 1234, binary.BigEndian.Uint32(packet[8:]))
+	assert.Equal(t, []byte{1, 2, 3}, packet[12:])
+
+	packet = header.packet(5000, false, nil)
+	assert.Equal(t, []byte{0x80, 101, 0, 0}, packet[:4])
+}
+
+func TestReadIvf(t *testing.T) {
+	var buf bytes.Buffer
+
+	header := make([]byte, 32)
+	copy(header, "DKIF")
+	binary.LittleEndian.PutUint16(header[6:], 32)
+	copy(header[8:], "VP80")
+	binary.LittleEndian.PutUint32(header[16:], 30)
+	binary.LittleEndian.PutUint32(header[20:], 1)
+	buf.Write(header)
+
+	for pts, size := range []int{10, 5} {
+		frameHeader := make([]byte, 12)
+		binary.LittleEndian.PutUint32(frameHeader, uint32(size))
+		binary.LittleEndian.PutUint64(frameHeader[4:], uint64(pts*3))
+		buf.Write(frameHeader)
+		buf.Write(make([]byte, size))
+	}
+
+	var frames []EncodedFrame
+
+	err := readIvf(&buf, func(frame EncodedFrame) {
+		frames = append(frames, frame)
+	})
+
+	assert.Error(t, err)
+	assert.Len(t, frames, 2)
+	assert.Len(t, frames[0].Data, 10)
+	assert.Equal(t, 100*time.Millisecond, frames[1].Time)
+}
+
+func TestEncodeArgs(t *testing.T) {
+	args := encodeArgs(VP8EncoderOptions{
+		Width:            640,
+		Height:           360,
+		FrameRate:        30,
+		Bitrate:          500000,
+		KeyframeInterval: 2 * time.Second,
+	})
+
+	assert.Contains(t, args, "640x360")
+	assert.Contains(t, args, "libvpx")
+	assert.Equal(t, "60", args[indexOf(args, "-g")+1])
+	assert.Equal(t, "500000", args[indexOf(args, "-b:v")+1])
+	assert.Equal(t, "pipe:1", args[len(args)-1])
+}
+
+func indexOf(args []string, arg string) int {
+	for i, a := range args {
+		if a == arg {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,369 @@
+package mediasoup
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TraceAggregatorOptions struct {
+	/**
+	 * Aggregation window. Default 10 seconds.
+	 */
+	Window time.Duration
+
+	/**
+	 * Fraction (0, 1] of the "rtp" trace events which are processed, each one
+	 * counted as 1/SampleRate events. Other trace events are always processed.
+	 * Default 1.
+	 */
+	SampleRate float64
+
+	/**
+	 * Trace event types enabled in every Producer and Consumer of the Router.
+	 * Default keyframe, nack, pli and fir.
+	 */
+	ProducerTraceEventTypes []ProducerTraceEventType
+	ConsumerTraceEventTypes []ConsumerTraceEventType
+
+	/**
+	 * Trace event types enabled in every Transport of the Router. Default bwe.
+	 */
+	TransportTraceEventTypes []TransportTraceEventType
+
+	/**
+	 * Function called with the summary of every entity with trace events at
+	 * the end of each window.
+	 */
+	OnSummary func(summary TraceSummary)
+}
+
+/**
+ * Trace events of an entity aggregated over a window.
+ */
+type TraceSummary struct {
+	EntityType  StatsEntityType `json:"entityType"`
+	EntityId    string          `json:"entityId"`
+	WindowStart time.Time       `json:"windowStart"`
+	Window      time.Duration   `json:"window"`
+
+	RtpPackets int `json:"rtpPackets,omitempty"`
+	Keyframes  int `json:"keyframes,omitempty"`
+	Nacks      int `json:"nacks,omitempty"`
+	Plis       int `json:"plis,omitempty"`
+	Firs       int `json:"firs,omitempty"`
+
+	/**
+	 * Mean interval between keyframes, 0 if less than two keyframes.
+	 */
+	KeyframeInterval time.Duration `json:"keyframeInterval,omitempty"`
+
+	/**
+	 * Last available bitrate given by a "bwe" trace event of a transport.
+	 */
+	AvailableBitrate int64 `json:"availableBitrate,omitempty"`
+}
+
+// Number of PLIs per second.
+func (s TraceSummary) PliRate() float64 {
+	return float64(s.Plis) / s.Window.Seconds()
+}
+
+// Number of NACKs per second.
+func (s TraceSummary) NackRate() float64 {
+	return float64(s.Nacks) / s.Window.Seconds()
+}
+
+type traceCounters struct {
+	locker           sync.Mutex
+	entityType       StatsEntityType
+	rtpPackets       float64
+	keyframes        int
+	firstKeyframe    time.Time
+	lastKeyframe     time.Time
+	nacks            int
+	plis             int
+	firs             int
+	availableBitrate int64
+	hasEvents        bool
+}
+
+func (c *traceCounters) reset() {
+	c.rtpPackets = 0
+	c.keyframes = 0
+	c.firstKeyframe = time.Time{}
+	c.lastKeyframe = time.Time{}
+	c.nacks = 0
+	c.plis = 0
+	c.firs = 0
+	c.availableBitrate = 0
+	c.hasEvents = false
+}
+
+/**
+ * TraceAggregator subscribes to the trace events of every Transport, Producer
+ * and Consumer of a Router and periodically emits compact summaries of them.
+ */
+type TraceAggregator struct {
+	logger      Logger
+	options     TraceAggregatorOptions
+	counters    sync.Map
+	windowStart time.Time
+	closed      uint32
+	closeCh     chan struct{}
+}
+
+/**
+ * Create a TraceAggregator for the given Router. It is closed with the Router.
+ */
+func NewTraceAggregator(router *Router, options TraceAggregatorOptions) *TraceAggregator {
+	logger := NewLogger("TraceAggregator")
+
+	logger.Debug("constructor()")
+
+	if options.Window <= 0 {
+		options.Window = 10 * time.Second
+	}
+	if options.SampleRate <= 0 || options.SampleRate > 1 {
+		options.SampleRate = 1
+	}
+	if options.ProducerTraceEventTypes == nil {
+		options.ProducerTraceEventTypes = []ProducerTraceEventType{
+			ProducerTraceEventType_Keyframe,
+			ProducerTraceEventType_Nack,
+			ProducerTraceEventType_Pli,
+			ProducerTraceEventType_Fir,
+		}
+	}
+	if options.ConsumerTraceEventTypes == nil {
+		options.ConsumerTraceEventTypes = []ConsumerTraceEventType{
+			ConsumerTraceEventType_Keyframe,
+			ConsumerTraceEventType_Nack,
+			ConsumerTraceEventType_Pli,
+			ConsumerTraceEventType_Fir,
+		}
+	}
+	if options.TransportTraceEventTypes == nil {
+		options.TransportTraceEventTypes = []TransportTraceEventType{TransportTraceEventType_Bwe}
+	}
+
+	aggregator := &TraceAggregator{
+		logger:      logger,
+		options:     options,
+		windowStart: time.Now(),
+		closeCh:     make(chan struct{}),
+	}
+
+	router.Observer().On("newtransport", aggregator.addTransport)
+	router.Observer().On("close", aggregator.Close)
+
+	router.transports.Range(func(key, value interface{}) bool {
+		aggregator.addTransport(value.(ITransport))
+		return true
+	})
+
+	go aggregator.run()
+
+	return aggregator
+}
+
+/**
+ * Stop aggregating. The summaries of the current window are not emitted.
+ */
+func (a *TraceAggregator) Close() {
+	if atomic.CompareAndSwapUint32(&a.closed, 0, 1) {
+		a.logger.Debug("close()")
+
+		close(a.closeCh)
+	}
+}
+
+func (a *TraceAggregator) Closed() bool {
+	return atomic.LoadUint32(&a.closed) > 0
+}
+
+func (a *TraceAggregator) addTransport(transport ITransport) {
+	if a.Closed() {
+		return
+	}
+
+	id := transport.Id()
+	counters := a.track(StatsEntityType_Transport, id, transport.Observer())
+
+	if counters == nil {
+		return
+	}
+
+	transport.Observer().On("trace", func(trace TransportTraceEventData) {
+		if trace.Type != TransportTraceEventType_Bwe {
+			return
+		}
+		info, _ := trace.Info.(map[string]interface{})
+		bitrate, _ := info["availableBitrate"].(float64)
+
+		counters.locker.Lock()
+		counters.availableBitrate = int64(bitrate)
+		counters.hasEvents = true
+		counters.locker.Unlock()
+	})
+	transport.Observer().On("newproducer", a.addProducer)
+	transport.Observer().On("newconsumer", a.addConsumer)
+
+	if err := transport.EnableTraceEvent(a.options.TransportTraceEventTypes...); err != nil {
+		a.logger.Warn("enabling trace events of transport %s failed: %s", id, err)
+	}
+
+	transport.base().producers.Range(func(key, value interface{}) bool {
+		a.addProducer(value.(*Producer))
+		return true
+	})
+	transport.base().consumers.Range(func(key, value interface{}) bool {
+		a.addConsumer(value.(*Consumer))
+		return true
+	})
+}
+
+func (a *TraceAggregator) addProducer(producer *Producer) {
+	if a.Closed() {
+		return
+	}
+
+	counters := a.track(StatsEntityType_Producer, producer.Id(), producer.Observer())
+
+	if counters == nil {
+		return
+	}
+
+	producer.Observer().On("trace", func(trace ProducerTraceEventData) {
+		a.count(counters, string(trace.Type))
+	})
+
+	if err := producer.EnableTraceEvent(a.options.ProducerTraceEventTypes...); err != nil {
+		a.logger.Warn("enabling trace events of producer %s failed: %s", producer.Id(), err)
+	}
+}
+
+func (a *TraceAggregator) addConsumer(consumer *Consumer) {
+	if a.Closed() {
+		return
+	}
+
+	counters := a.track(StatsEntityType_Consumer, consumer.Id(), consumer.Observer())
+
+	if counters == nil {
+		return
+	}
+
+	consumer.Observer().On("trace", func(trace ConsumerTraceEventData) {
+		a.count(counters, string(trace.Type))
+	})
+
+	if err := consumer.EnableTraceEvent(a.options.ConsumerTraceEventTypes...); err != nil {
+		a.logger.Warn("enabling trace events of consumer %s failed: %s", consumer.Id(), err)
+	}
+}
+
+// track starts aggregating the trace events of the entity, returning nil if
+// already done.
+func (a *TraceAggregator) track(entityType StatsEntityType, id string, observer IEventEmitter) *traceCounters {
+	counters := &traceCounters{entityType: entityType}
+
+	if _, loaded := a.counters.LoadOrStore(id, counters); loaded {
+		return nil
+	}
+
+	observer.Once("close", func() {
+		a.counters.Delete(id)
+	})
+
+	return counters
+}
+
+// count processes a producer or consumer trace event of the given type.
+func (a *TraceAggregator) count(counters *traceCounters, typ string) {
+	if typ == "rtp" && a.options.SampleRate < 1 && rand.Float64() >= a.options.SampleRate {
+		return
+	}
+
+	counters.locker.Lock()
+	defer counters.locker.Unlock()
+
+	counters.hasEvents = true
+
+	switch typ {
+	case "rtp":
+		counters.rtpPackets += 1 / a.options.SampleRate
+	case "keyframe":
+		now := time.Now()
+
+		if counters.keyframes == 0 {
+			counters.firstKeyframe = now
+		}
+		counters.lastKeyframe = now
+		counters.keyframes++
+	case "nack":
+		counters.nacks++
+	case "pli":
+		counters.plis++
+	case "fir":
+		counters.firs++
+	}
+}
+
+// flush emits the summaries of the window and resets the counters.
+func (a *TraceAggregator) flush(now time.Time) {
+	windowStart := a.windowStart
+	a.windowStart = now
+
+	a.counters.Range(func(key, value interface{}) bool {
+		counters := value.(*traceCounters)
+
+		counters.locker.Lock()
+
+		if !counters.hasEvents {
+			counters.locker.Unlock()
+			return true
+		}
+
+		summary := TraceSummary{
+			EntityType:       counters.entityType,
+			EntityId:         key.(string),
+			WindowStart:      windowStart,
+			Window:           now.Sub(windowStart),
+			RtpPackets:       int(counters.rtpPackets + 0.5),
+			Keyframes:        counters.keyframes,
+			Nacks:            counters.nacks,
+			Plis:             counters.plis,
+			Firs:             counters.firs,
+			AvailableBitrate: counters.availableBitrate,
+		}
+		if counters.keyframes > 1 {
+			summary.KeyframeInterval = counters.lastKeyframe.Sub(counters.firstKeyframe) /
+				time.Duration(counters.keyframes-1)
+		}
+
+		counters.reset()
+		counters.locker.Unlock()
+
+		if a.options.OnSummary != nil {
+			a.options.OnSummary(summary)
+		}
+
+		return true
+	})
+}
+
+func (a *TraceAggregator) run() {
+	ticker := time.NewTicker(a.options.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			a.flush(now)
+		case <-a.closeCh:
+			return
+		}
+	}
+}
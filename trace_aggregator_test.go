@@ -0,0 +1,53 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceAggregatorFlush(t *testing.T) {
+	var summaries []TraceSummary
+
+	start := time.Now()
+	aggregator := &TraceAggregator{
+		options: TraceAggregatorOptions{
+			SampleRate: 1,
+			OnSummary:  func(summary TraceSummary) { summaries = append(summaries, summary) },
+		},
+		windowStart: start,
+	}
+
+	observer := NewEventEmitter()
+	counters := aggregator.track(StatsEntityType_Producer, "p1", observer)
+	require.NotNil(t, counters)
+	assert.Nil(t, aggregator.track(StatsEntityType_Producer, "p1", observer))
+	aggregator.track(StatsEntityType_Consumer, "c1", NewEventEmitter())
+
+	for _, typ := range []string{"keyframe", "pli", "pli", "nack", "rtp", "keyframe"} {
+		aggregator.count(counters, typ)
+	}
+	aggregator.flush(start.Add(2 * time.Second))
+
+	require.Len(t, summaries, 1)
+
+	summary := summaries[0]
+	assert.Equal(t, "p1", summary.EntityId)
+	assert.Equal(t, StatsEntityType_Producer, summary.EntityType)
+	assert.Equal(t, 2*time.Second, summary.Window)
+	assert.Equal(t, 2, summary.Keyframes)
+	assert.Equal(t, 1, summary.RtpPackets)
+	assert.Equal(t, 1.0, summary.PliRate())
+	assert.Equal(t, 0.5, summary.NackRate())
+
+	summaries = nil
+	aggregator.flush(start.Add(4 * time.Second))
+	assert.Empty(t, summaries)
+
+	observer.Emit("close")
+	aggregator.count(counters, "pli")
+	aggregator.flush(start.Add(6 * time.Second))
+	assert.Empty(t, summaries)
+}
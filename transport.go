@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -15,17 +16,36 @@ type ITransport interface {
 	Closed() bool
 	AppData() interface{}
 	Observer() IEventEmitter
-	Close()
+	Producers() []*Producer
+	AllProducers() func(yield func(*Producer) bool)
+	Consumers() []*Consumer
+	AllConsumers() func(yield func(*Consumer) bool)
+	DataProducers() []*DataProducer
+	AllDataProducers() func(yield func(*DataProducer) bool)
+	DataConsumers() []*DataConsumer
+	AllDataConsumers() func(yield func(*DataConsumer) bool)
+	Close() error
 	routerClosed()
+	base() *Transport
 	Dump() (*TransportDump, error)
+	DumpContext(ctx context.Context) (*TransportDump, error)
 	GetStats() ([]*TransportStat, error)
+	GetStatsContext(ctx context.Context) ([]*TransportStat, error)
 	Connect(TransportConnectOptions) error
+	ConnectContext(ctx context.Context, options TransportConnectOptions) error
 	SetMaxIncomingBitrate(bitrate int) error
-	Produce(ProducerOptions) (*Producer, error)
-	Consume(ConsumerOptions) (*Consumer, error)
-	ProduceData(DataProducerOptions) (*DataProducer, error)
-	ConsumeData(DataConsumerOptions) (*DataConsumer, error)
+	SetMaxIncomingBitrateContext(ctx context.Context, bitrate int) error
+	SetCodecPolicy(policy CodecPolicy)
+	Produce(ProducerOptions, ...ProducerOption) (*Producer, error)
+	ProduceContext(ctx context.Context, options ProducerOptions, opts ...ProducerOption) (*Producer, error)
+	Consume(ConsumerOptions, ...ConsumerOption) (*Consumer, error)
+	ConsumeContext(ctx context.Context, options ConsumerOptions, opts ...ConsumerOption) (*Consumer, error)
+	ProduceData(DataProducerOptions, ...DataProducerOption) (*DataProducer, error)
+	ProduceDataContext(ctx context.Context, options DataProducerOptions, opts ...DataProducerOption) (*DataProducer, error)
+	ConsumeData(DataConsumerOptions, ...DataConsumerOption) (*DataConsumer, error)
+	ConsumeDataContext(ctx context.Context, options DataConsumerOptions, opts ...DataConsumerOption) (*DataConsumer, error)
 	EnableTraceEvent(types ...TransportTraceEventType) error
+	EnableTraceEventContext(ctx context.Context, types ...TransportTraceEventType) error
 }
 
 type TransportListenIp struct {
@@ -39,6 +59,13 @@ type TransportListenIp struct {
 	 * private IP).
 	 */
 	AnnouncedIp string `json:"announcedIp,omitempty"`
+
+	/**
+	 * Whether AnnouncedIp, if empty, is the public IP of the host of the family
+	 * of Ip, discovered with DiscoverPublicIp() when a transport is created and
+	 * reused for 5 minutes.
+	 */
+	DiscoverAnnouncedIp bool `json:"-"`
 }
 
 /**
@@ -167,6 +194,8 @@ type transportParams struct {
 	getProducerById          func(string) *Producer
 	getDataProducerById      func(string) *DataProducer
 	logger                   Logger
+	loggerContext            loggerContext
+	idGenerator              IdGenerator
 }
 
 /**
@@ -219,6 +248,12 @@ type Transport struct {
 	observer IEventEmitter
 	// locker instance
 	locker sync.Mutex
+	// Codec policy for Consumers.
+	codecPolicy CodecPolicy
+	// Context of the loggers of the Producers and Consumers.
+	loggerContext loggerContext
+	// Generator of the entity ids.
+	idGenerator IdGenerator
 }
 
 func newTransport(params transportParams) ITransport {
@@ -235,12 +270,19 @@ func newTransport(params transportParams) ITransport {
 		getRouterRtpCapabilities: params.getRouterRtpCapabilities,
 		getProducerById:          params.getProducerById,
 		getDataProducerById:      params.getDataProducerById,
-		observer:                 NewEventEmitter(),
+		observer:                 params.loggerContext.newObserver("transport", params.internal.TransportId),
+		loggerContext:            params.loggerContext,
+		idGenerator:              params.idGenerator,
 	}
 
 	return transport
 }
 
+// base returns the Transport embedded in the transport subclasses.
+func (transport *Transport) base() *Transport {
+	return transport
+}
+
 // Transport id
 func (transport *Transport) Id() string {
 	return transport.internal.TransportId
@@ -269,54 +311,138 @@ func (transport *Transport) Observer() IEventEmitter {
 	return transport.observer
 }
 
-// Close the Transport.
-func (transport *Transport) Close() {
-	if atomic.CompareAndSwapUint32(&transport.closed, 0, 1) {
-		transport.logger.Debug("close()")
-
-		// Remove notification subscriptions.
-		transport.channel.RemoveAllListeners(transport.Id())
-		transport.payloadChannel.RemoveAllListeners(transport.Id())
-
-		transport.channel.Request("transport.close", transport.internal)
+// Producers returns the producers of the Transport.
+func (transport *Transport) Producers() []*Producer {
+	producers := make([]*Producer, 0)
+	transport.producers.Range(func(key, value interface{}) bool {
+		producers = append(producers, value.(*Producer))
+		return true
+	})
+	return producers
+}
 
+// AllProducers iterates over the producers of the Transport, like Worker.AllRouters().
+func (transport *Transport) AllProducers() func(yield func(*Producer) bool) {
+	return func(yield func(*Producer) bool) {
 		transport.producers.Range(func(key, value interface{}) bool {
-			producer := value.(*Producer)
-
-			producer.transportClosed()
-			transport.Emit("@producerclose", producer)
-
-			return true
+			return yield(value.(*Producer))
 		})
+	}
+}
 
-		transport.consumers.Range(func(key, value interface{}) bool {
-			value.(*Consumer).transportClosed()
+// Consumers returns the consumers of the Transport.
+func (transport *Transport) Consumers() []*Consumer {
+	consumers := make([]*Consumer, 0)
+	transport.consumers.Range(func(key, value interface{}) bool {
+		consumers = append(consumers, value.(*Consumer))
+		return true
+	})
+	return consumers
+}
 
-			return true
+// AllConsumers iterates over the consumers of the Transport, like Worker.AllRouters().
+func (transport *Transport) AllConsumers() func(yield func(*Consumer) bool) {
+	return func(yield func(*Consumer) bool) {
+		transport.consumers.Range(func(key, value interface{}) bool {
+			return yield(value.(*Consumer))
 		})
+	}
+}
 
+// DataProducers returns the data producers of the Transport.
+func (transport *Transport) DataProducers() []*DataProducer {
+	dataProducers := make([]*DataProducer, 0)
+	transport.dataProducers.Range(func(key, value interface{}) bool {
+		dataProducers = append(dataProducers, value.(*DataProducer))
+		return true
+	})
+	return dataProducers
+}
+
+// AllDataProducers iterates over the data producers of the Transport, like Worker.AllRouters().
+func (transport *Transport) AllDataProducers() func(yield func(*DataProducer) bool) {
+	return func(yield func(*DataProducer) bool) {
 		transport.dataProducers.Range(func(key, value interface{}) bool {
-			producer := value.(*DataProducer)
+			return yield(value.(*DataProducer))
+		})
+	}
+}
 
-			producer.transportClosed()
-			transport.Emit("@dataproducerclose", producer)
+// DataConsumers returns the data consumers of the Transport.
+func (transport *Transport) DataConsumers() []*DataConsumer {
+	dataConsumers := make([]*DataConsumer, 0)
+	transport.dataConsumers.Range(func(key, value interface{}) bool {
+		dataConsumers = append(dataConsumers, value.(*DataConsumer))
+		return true
+	})
+	return dataConsumers
+}
 
-			return true
+// AllDataConsumers iterates over the data consumers of the Transport, like Worker.AllRouters().
+func (transport *Transport) AllDataConsumers() func(yield func(*DataConsumer) bool) {
+	return func(yield func(*DataConsumer) bool) {
+		transport.dataConsumers.Range(func(key, value interface{}) bool {
+			return yield(value.(*DataConsumer))
 		})
+	}
+}
 
-		transport.dataConsumers.Range(func(key, value interface{}) bool {
-			value.(*DataConsumer).transportClosed()
+// Close the Transport.
+func (transport *Transport) Close() (err error) {
+	if !atomic.CompareAndSwapUint32(&transport.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
 
-			return true
-		})
+	transport.logger.Debug("close()")
 
-		transport.Emit("@close")
-		transport.RemoveAllListeners()
+	// Remove notification subscriptions.
+	transport.channel.RemoveAllListeners(transport.Id())
+	transport.payloadChannel.RemoveAllListeners(transport.Id())
 
-		// Emit observer event.
-		transport.observer.SafeEmit("close")
-		transport.observer.RemoveAllListeners()
+	response := transport.channel.Request("transport.close", transport.internal)
+
+	if err = response.Err(); err != nil {
+		transport.logger.Error("transport close error: %s", err)
 	}
+
+	transport.producers.Range(func(key, value interface{}) bool {
+		producer := value.(*Producer)
+
+		producer.transportClosed()
+		transport.Emit("@producerclose", producer)
+
+		return true
+	})
+
+	transport.consumers.Range(func(key, value interface{}) bool {
+		value.(*Consumer).transportClosed()
+
+		return true
+	})
+
+	transport.dataProducers.Range(func(key, value interface{}) bool {
+		producer := value.(*DataProducer)
+
+		producer.transportClosed()
+		transport.Emit("@dataproducerclose", producer)
+
+		return true
+	})
+
+	transport.dataConsumers.Range(func(key, value interface{}) bool {
+		value.(*DataConsumer).transportClosed()
+
+		return true
+	})
+
+	transport.Emit("@close")
+	transport.RemoveAllListeners()
+
+	// Emit observer event.
+	transport.observer.SafeEmit("close")
+	transport.observer.RemoveAllListeners()
+
+	return
 }
 
 /**
@@ -373,9 +499,16 @@ func (transport *Transport) routerClosed() {
 
 // Dump Transport.
 func (transport *Transport) Dump() (data *TransportDump, err error) {
+	return transport.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (transport *Transport) DumpContext(ctx context.Context) (data *TransportDump, err error) {
 	transport.logger.Debug("dump()")
 
-	resp := transport.channel.Request("transport.dump", transport.internal)
+	resp := transport.channel.RequestContext(ctx, "transport.dump", transport.internal)
 	err = resp.Unmarshal(&data)
 
 	return
@@ -383,9 +516,16 @@ func (transport *Transport) Dump() (data *TransportDump, err error) {
 
 // Get Transport stats.
 func (transport *Transport) GetStats() (stat []*TransportStat, err error) {
+	return transport.GetStatsContext(context.Background())
+}
+
+/**
+ * GetStatsContext is GetStats with a context cancelling the worker request.
+ */
+func (transport *Transport) GetStatsContext(ctx context.Context) (stat []*TransportStat, err error) {
 	transport.logger.Debug("getStats()")
 
-	resp := transport.channel.Request("transport.getStats", transport.internal)
+	resp := transport.channel.RequestContext(ctx, "transport.getStats", transport.internal)
 	err = resp.Unmarshal(&stat)
 
 	return
@@ -398,24 +538,61 @@ func (transport *Transport) Connect(TransportConnectOptions) error {
 	return errors.New("method not implemented in the subclass")
 }
 
+/**
+ * ConnectContext is Connect with a context cancelling the worker request.
+ */
+func (transport *Transport) ConnectContext(context.Context, TransportConnectOptions) error {
+	return errors.New("method not implemented in the subclass")
+}
+
 /**
  * Set maximum incoming bitrate for receiving media.
  */
 func (transport *Transport) SetMaxIncomingBitrate(bitrate int) error {
+	return transport.SetMaxIncomingBitrateContext(context.Background(), bitrate)
+}
+
+/**
+ * SetMaxIncomingBitrateContext is SetMaxIncomingBitrate with a context cancelling the worker request.
+ */
+func (transport *Transport) SetMaxIncomingBitrateContext(ctx context.Context, bitrate int) error {
 	transport.logger.Debug("SetMaxIncomingBitrate() [bitrate:%d]", bitrate)
 
-	resp := transport.channel.Request(
-		"transport.setMaxIncomingBitrate", transport.internal, H{"bitrate": bitrate})
+	resp := transport.channel.RequestContext(ctx, "transport.setMaxIncomingBitrate", transport.internal, H{"bitrate": bitrate})
 
 	return resp.Err()
 }
 
+/**
+ * Set the codec policy applied when creating Consumers in this Transport,
+ * unless ConsumerOptions has its own one. nil removes it.
+ */
+func (transport *Transport) SetCodecPolicy(policy CodecPolicy) {
+	transport.logger.Debug("setCodecPolicy()")
+
+	transport.locker.Lock()
+	defer transport.locker.Unlock()
+
+	transport.codecPolicy = policy
+}
+
 /**
  * Create a Producer.
  */
-func (transport *Transport) Produce(options ProducerOptions) (producer *Producer, err error) {
+func (transport *Transport) Produce(options ProducerOptions, opts ...ProducerOption) (producer *Producer, err error) {
+	return transport.ProduceContext(context.Background(), options, opts...)
+}
+
+/**
+ * ProduceContext is Produce with a context cancelling the worker request.
+ */
+func (transport *Transport) ProduceContext(ctx context.Context, options ProducerOptions, opts ...ProducerOption) (producer *Producer, err error) {
 	transport.logger.Debug("produce()")
 
+	for _, opt := range opts {
+		opt.applyProducer(&options)
+	}
+
 	id := options.Id
 	kind := options.Kind
 	rtpParameters := options.RtpParameters
@@ -429,7 +606,7 @@ func (transport *Transport) Produce(options ProducerOptions) (producer *Producer
 			return
 		}
 	} else {
-		id = uuid.NewV4().String()
+		id = transport.idGenerator.newId("producer")
 	}
 
 	// This may throw.
@@ -482,7 +659,7 @@ func (transport *Transport) Produce(options ProducerOptions) (producer *Producer
 		"keyFrameRequestDelay": keyFrameRequestDelay,
 		"paused":               paused,
 	}
-	resp := transport.channel.Request("transport.produce", internal, reqData)
+	resp := transport.channel.requestCreation(ctx, "transport.produce", "producer.close", internal, reqData)
 
 	var status struct {
 		Type ProducerType
@@ -496,6 +673,7 @@ func (transport *Transport) Produce(options ProducerOptions) (producer *Producer
 		RtpParameters:           rtpParameters,
 		Type:                    status.Type,
 		ConsumableRtpParameters: consumableRtpParameters,
+		RtpMapping:              rtpMapping,
 	}
 
 	producer = newProducer(producerParams{
@@ -505,8 +683,17 @@ func (transport *Transport) Produce(options ProducerOptions) (producer *Producer
 		payloadChannel: transport.payloadChannel,
 		appData:        appData,
 		paused:         paused,
+		loggerContext:  transport.loggerContext.with(options.Logger, "producerId", internal.ProducerId),
 	})
 
+	if len(options.TraceEventTypes) > 0 {
+		if err = producer.EnableTraceEvent(options.TraceEventTypes...); err != nil {
+			producer.Close()
+			producer = nil
+			return
+		}
+	}
+
 	transport.producers.Store(producer.Id(), producer)
 
 	producer.On("@close", func() {
@@ -525,9 +712,20 @@ func (transport *Transport) Produce(options ProducerOptions) (producer *Producer
 /**
  * Create a Consumer.
  */
-func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer, err error) {
+func (transport *Transport) Consume(options ConsumerOptions, opts ...ConsumerOption) (consumer *Consumer, err error) {
+	return transport.ConsumeContext(context.Background(), options, opts...)
+}
+
+/**
+ * ConsumeContext is Consume with a context cancelling the worker request.
+ */
+func (transport *Transport) ConsumeContext(ctx context.Context, options ConsumerOptions, opts ...ConsumerOption) (consumer *Consumer, err error) {
 	transport.logger.Debug("consume()")
 
+	for _, opt := range opts {
+		opt.applyConsumer(&options)
+	}
+
 	producerId := options.ProducerId
 	rtpCapabilities := options.RtpCapabilities
 	paused := options.Paused
@@ -541,7 +739,16 @@ func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer
 		return
 	}
 
-	rtpParameters, err := getConsumerRtpParameters(producer.ConsumableRtpParameters(), rtpCapabilities, options.Pipe)
+	codecPolicy := options.CodecPolicy
+
+	if codecPolicy == nil {
+		transport.locker.Lock()
+		codecPolicy = transport.codecPolicy
+		transport.locker.Unlock()
+	}
+
+	rtpParameters, err := getConsumerRtpParameters(
+		producer.ConsumableRtpParameters(), rtpCapabilities, options.Pipe, codecPolicy)
 	if err != nil {
 		return
 	}
@@ -567,7 +774,7 @@ func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer
 	}
 
 	internal := transport.internal
-	internal.ConsumerId = uuid.NewV4().String()
+	internal.ConsumerId = transport.idGenerator.newId("consumer")
 	internal.ProducerId = producerId
 
 	typ := producer.Type()
@@ -584,7 +791,7 @@ func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer
 		"paused":                 paused,
 		"preferredLayers":        preferredLayers,
 	}
-	resp := transport.channel.Request("transport.consume", internal, reqData)
+	resp := transport.channel.requestCreation(ctx, "transport.consume", "consumer.close", internal, reqData)
 
 	var status struct {
 		Paused         bool
@@ -610,8 +817,18 @@ func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer
 		producerPaused:  status.ProducerPaused,
 		score:           status.Score,
 		preferredLayers: preferredLayers,
+		loggerContext: transport.loggerContext.with(options.Logger,
+			"consumerId", internal.ConsumerId, "producerId", internal.ProducerId),
 	})
 
+	if len(options.TraceEventTypes) > 0 {
+		if err = consumer.EnableTraceEvent(options.TraceEventTypes...); err != nil {
+			consumer.Close()
+			consumer = nil
+			return
+		}
+	}
+
 	transport.consumers.Store(consumer.Id(), consumer)
 	consumer.On("@close", func() {
 		transport.consumers.Delete(consumer.Id())
@@ -629,9 +846,20 @@ func (transport *Transport) Consume(options ConsumerOptions) (consumer *Consumer
 /**
  * Create a DataProducer.
  */
-func (transport *Transport) ProduceData(options DataProducerOptions) (dataProducer *DataProducer, err error) {
+func (transport *Transport) ProduceData(options DataProducerOptions, opts ...DataProducerOption) (dataProducer *DataProducer, err error) {
+	return transport.ProduceDataContext(context.Background(), options, opts...)
+}
+
+/**
+ * ProduceDataContext is ProduceData with a context cancelling the worker request.
+ */
+func (transport *Transport) ProduceDataContext(ctx context.Context, options DataProducerOptions, opts ...DataProducerOption) (dataProducer *DataProducer, err error) {
 	transport.logger.Debug("produceData()")
 
+	for _, opt := range opts {
+		opt.applyDataProducer(&options)
+	}
+
 	id := options.Id
 	sctpStreamParameters := options.SctpStreamParameters
 	label := options.Label
@@ -644,7 +872,7 @@ func (transport *Transport) ProduceData(options DataProducerOptions) (dataProduc
 			return
 		}
 	} else {
-		id = uuid.NewV4().String()
+		id = transport.idGenerator.newId("dataProducer")
 	}
 
 	var typ DataProducerType
@@ -675,7 +903,7 @@ func (transport *Transport) ProduceData(options DataProducerOptions) (dataProduc
 	if sctpStreamParameters != nil {
 		reqData["sctpStreamParameters"] = sctpStreamParameters
 	}
-	resp := transport.channel.Request("transport.produceData", internal, reqData)
+	resp := transport.channel.requestCreation(ctx, "transport.produceData", "dataProducer.close", internal, reqData)
 
 	var data dataProducerData
 	if err = resp.Unmarshal(&data); err != nil {
@@ -688,6 +916,7 @@ func (transport *Transport) ProduceData(options DataProducerOptions) (dataProduc
 		channel:        transport.channel,
 		payloadChannel: transport.payloadChannel,
 		appData:        appData,
+		loggerContext:  transport.loggerContext.with(options.Logger, "dataProducerId", internal.DataProducerId),
 	})
 
 	transport.dataProducers.Store(dataProducer.Id(), dataProducer)
@@ -707,9 +936,20 @@ func (transport *Transport) ProduceData(options DataProducerOptions) (dataProduc
 /**
  * Create a DataConsumer.
  */
-func (transport *Transport) ConsumeData(options DataConsumerOptions) (dataConsumer *DataConsumer, err error) {
+func (transport *Transport) ConsumeData(options DataConsumerOptions, opts ...DataConsumerOption) (dataConsumer *DataConsumer, err error) {
+	return transport.ConsumeDataContext(context.Background(), options, opts...)
+}
+
+/**
+ * ConsumeDataContext is ConsumeData with a context cancelling the worker request.
+ */
+func (transport *Transport) ConsumeDataContext(ctx context.Context, options DataConsumerOptions, opts ...DataConsumerOption) (dataConsumer *DataConsumer, err error) {
 	transport.logger.Debug("consumeData()")
 
+	for _, opt := range opts {
+		opt.applyDataConsumer(&options)
+	}
+
 	dataProducerId := options.DataProducerId
 	ordered := options.Ordered
 	maxPacketLifeTime := options.MaxPacketLifeTime
@@ -761,7 +1001,7 @@ func (transport *Transport) ConsumeData(options DataConsumerOptions) (dataConsum
 	}
 
 	internal := transport.internal
-	internal.DataConsumerId = uuid.NewV4().String()
+	internal.DataConsumerId = transport.idGenerator.newId("dataConsumer")
 	internal.DataProducerId = dataProducerId
 
 	reqData := H{
@@ -770,7 +1010,7 @@ func (transport *Transport) ConsumeData(options DataConsumerOptions) (dataConsum
 		"label":                dataProducer.Label(),
 		"protocol":             dataProducer.Protocol(),
 	}
-	resp := transport.channel.Request("transport.consumeData", internal, reqData)
+	resp := transport.channel.requestCreation(ctx, "transport.consumeData", "dataConsumer.close", internal, reqData)
 
 	var data dataConsumerData
 	if err = resp.Unmarshal(&data); err != nil {
@@ -783,6 +1023,8 @@ func (transport *Transport) ConsumeData(options DataConsumerOptions) (dataConsum
 		channel:        transport.channel,
 		payloadChannel: transport.payloadChannel,
 		appData:        appData,
+		loggerContext: transport.loggerContext.with(options.Logger,
+			"dataConsumerId", internal.DataConsumerId, "dataProducerId", internal.DataProducerId),
 	})
 
 	transport.dataConsumers.Store(dataConsumer.Id(), dataConsumer)
@@ -815,13 +1057,20 @@ func (transport *Transport) ConsumeData(options DataConsumerOptions) (dataConsum
  * Enable 'trace' event.
  */
 func (transport *Transport) EnableTraceEvent(types ...TransportTraceEventType) error {
+	return transport.EnableTraceEventContext(context.Background(), types...)
+}
+
+/**
+ * EnableTraceEventContext is EnableTraceEvent with a context cancelling the worker request.
+ */
+func (transport *Transport) EnableTraceEventContext(ctx context.Context, types ...TransportTraceEventType) error {
 	transport.logger.Debug("pause()")
 
 	if types == nil {
 		types = []TransportTraceEventType{}
 	}
 
-	resp := transport.channel.Request("transport.enableTraceEvent", transport.internal, H{"types": types})
+	resp := transport.channel.RequestContext(ctx, "transport.enableTraceEvent", transport.internal, H{"types": types})
 
 	return resp.Err()
 }
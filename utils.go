@@ -1,9 +1,12 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"math/rand"
 	"reflect"
+	"runtime/pprof"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,6 +19,15 @@ func init() {
 
 type H map[string]interface{}
 
+// goWithWorkerLabels runs fn in a new goroutine with pprof labels identifying
+// the worker and the role of the goroutine, so CPU and goroutine profiles
+// attribute its cost to the worker. Goroutines started by fn inherit them.
+func goWithWorkerLabels(pid int, role string, fn func()) {
+	labels := pprof.Labels("mediasoup.worker.pid", strconv.Itoa(pid), "mediasoup.role", role)
+
+	go pprof.Do(context.Background(), labels, func(context.Context) { fn() })
+}
+
 type ptrTransformers struct{}
 
 // overwrites pointer type
@@ -43,6 +55,19 @@ func generateRandomNumber() uint32 {
 	return uint32(rand.Int63n(900000000)) + 100000000
 }
 
+// unmarshalJSON decodes data into v with the generated decoders of json_gen.go
+// or its UnmarshalJSON method if any, sparing the validation and reflection of
+// json.Unmarshal on the hot paths.
+func unmarshalJSON(data []byte, v interface{}) error {
+	if ok, err := decodeGeneratedJSON(data, v); ok {
+		return err
+	}
+	if u, ok := v.(json.Unmarshaler); ok {
+		return u.UnmarshalJSON(data)
+	}
+	return json.Unmarshal(data, v)
+}
+
 func clone(from, to interface{}) (err error) {
 	data, err := json.Marshal(from)
 	if err != nil {
@@ -66,3 +91,32 @@ func syncMapLen(m *sync.Map) (len int) {
 	})
 	return
 }
+
+// clearSyncMap deletes every entry of m. Unlike assigning a new sync.Map, it
+// is safe while m is used concurrently.
+func clearSyncMap(m *sync.Map) {
+	m.Range(func(key, value interface{}) bool {
+		m.Delete(key)
+		return true
+	})
+}
+
+// waitTimeout waits at most timeout for wg, reporting whether it is done.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
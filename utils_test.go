@@ -0,0 +1,26 @@
+package mediasoup
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoWithWorkerLabels(t *testing.T) {
+	started, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+
+	goWithWorkerLabels(1234, "watchdog", func() {
+		close(started)
+		<-done
+	})
+	<-started
+
+	buf := bytes.NewBuffer(nil)
+	pprof.Lookup("goroutine").WriteTo(buf, 1)
+
+	assert.Contains(t, buf.String(), `"mediasoup.role":"watchdog"`)
+	assert.Contains(t, buf.String(), `"mediasoup.worker.pid":"1234"`)
+}
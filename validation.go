@@ -0,0 +1,242 @@
+package mediasoup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Code of a rule violated by a RtpParameters object.
+ */
+type ValidationErrorCode string
+
+const (
+	// A mandatory field is not present.
+	ValidationErrorCode_Missing ValidationErrorCode = "missing"
+	// A field has a value out of its allowed range or format.
+	ValidationErrorCode_Invalid ValidationErrorCode = "invalid"
+	// A field value must be unique but it is repeated.
+	ValidationErrorCode_Duplicated ValidationErrorCode = "duplicated"
+	// A field references a payload type that is not present in codecs.
+	ValidationErrorCode_UnknownPayloadType ValidationErrorCode = "unknown-payload-type"
+)
+
+/**
+ * ValidationError describes a single rule violated by a RtpParameters object.
+ * Path is a JSON-path-like location (e.g. "encodings[1].rtx.ssrc").
+ */
+type ValidationError struct {
+	Path    string              `json:"path"`
+	Code    ValidationErrorCode `json:"code"`
+	Message string              `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Path, e.Message)
+}
+
+/**
+ * ValidationErrors is the list of every rule violated by a RtpParameters object.
+ */
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+type validationErrorsBuilder struct {
+	errs ValidationErrors
+}
+
+func (b *validationErrorsBuilder) add(code ValidationErrorCode, path string, format string, args ...interface{}) {
+	b.errs = append(b.errs, ValidationError{
+		Path:    path,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/**
+ * Check the given RtpParameters and return every rule violated, or nil if they
+ * are valid. Unlike ValidateRtpParameters, the given parameters are not
+ * modified and the check does not stop at the first error, so signaling
+ * servers can report all the mistakes of a client at once.
+ */
+func CheckRtpParameters(params RtpParameters) ValidationErrors {
+	b := &validationErrorsBuilder{}
+
+	if len(params.Codecs) == 0 {
+		b.add(ValidationErrorCode_Missing, "codecs", "missing")
+	}
+
+	payloadTypes := map[byte]bool{}
+
+	for _, codec := range params.Codecs {
+		if codec != nil {
+			payloadTypes[codec.PayloadType] = true
+		}
+	}
+
+	seenPayloadTypes := map[byte]bool{}
+
+	for i, codec := range params.Codecs {
+		path := fmt.Sprintf("codecs[%d]", i)
+
+		if codec == nil {
+			b.add(ValidationErrorCode_Missing, path, "missing")
+			continue
+		}
+
+		if seenPayloadTypes[codec.PayloadType] {
+			b.add(ValidationErrorCode_Duplicated, path+".payloadType", "%d duplicated", codec.PayloadType)
+		}
+		seenPayloadTypes[codec.PayloadType] = true
+
+		checkRtpCodecParameters(b, path, codec, payloadTypes)
+	}
+
+	seenIds := map[int]bool{}
+
+	for i, ext := range params.HeaderExtensions {
+		path := fmt.Sprintf("headerExtensions[%d]", i)
+
+		if len(ext.Uri) == 0 {
+			b.add(ValidationErrorCode_Missing, path+".uri", "missing")
+		}
+
+		switch {
+		case ext.Id == 0:
+			b.add(ValidationErrorCode_Missing, path+".id", "missing")
+		case ext.Id < 0 || ext.Id > 255:
+			b.add(ValidationErrorCode_Invalid, path+".id", "%d out of range [1, 255]", ext.Id)
+		case seenIds[ext.Id]:
+			b.add(ValidationErrorCode_Duplicated, path+".id", "%d duplicated", ext.Id)
+		}
+		seenIds[ext.Id] = true
+	}
+
+	seenSsrcs := map[uint32]bool{}
+	seenRids := map[string]bool{}
+
+	for i, encoding := range params.Encodings {
+		path := fmt.Sprintf("encodings[%d]", i)
+
+		if encoding.Ssrc > 0 {
+			if seenSsrcs[encoding.Ssrc] {
+				b.add(ValidationErrorCode_Duplicated, path+".ssrc", "%d duplicated", encoding.Ssrc)
+			}
+			seenSsrcs[encoding.Ssrc] = true
+		}
+
+		if len(encoding.Rid) > 0 {
+			if seenRids[encoding.Rid] {
+				b.add(ValidationErrorCode_Duplicated, path+".rid", "%q duplicated", encoding.Rid)
+			}
+			seenRids[encoding.Rid] = true
+		}
+
+		if encoding.CodecPayloadType > 0 && !payloadTypes[encoding.CodecPayloadType] {
+			b.add(ValidationErrorCode_UnknownPayloadType, path+".codecPayloadType",
+				"%d not found in codecs", encoding.CodecPayloadType)
+		}
+
+		if encoding.Rtx != nil {
+			if encoding.Rtx.Ssrc == 0 {
+				b.add(ValidationErrorCode_Missing, path+".rtx.ssrc", "missing")
+			} else {
+				if seenSsrcs[encoding.Rtx.Ssrc] {
+					b.add(ValidationErrorCode_Duplicated, path+".rtx.ssrc", "%d duplicated", encoding.Rtx.Ssrc)
+				}
+				seenSsrcs[encoding.Rtx.Ssrc] = true
+			}
+		}
+
+		if len(encoding.ScalabilityMode) > 0 && !scalabilityModeRegex.MatchString(encoding.ScalabilityMode) {
+			b.add(ValidationErrorCode_Invalid, path+".scalabilityMode", "%q invalid", encoding.ScalabilityMode)
+		}
+	}
+
+	return b.errs
+}
+
+func checkRtpCodecParameters(b *validationErrorsBuilder, path string, codec *RtpCodecParameters, payloadTypes map[byte]bool) {
+	mimeType := strings.ToLower(codec.MimeType)
+
+	if len(mimeType) == 0 {
+		b.add(ValidationErrorCode_Missing, path+".mimeType", "missing")
+	} else if !strings.HasPrefix(mimeType, "audio/") && !strings.HasPrefix(mimeType, "video/") {
+		b.add(ValidationErrorCode_Invalid, path+".mimeType", "%q invalid", codec.MimeType)
+	}
+
+	if codec.ClockRate == 0 {
+		b.add(ValidationErrorCode_Missing, path+".clockRate", "missing")
+	}
+
+	if codec.isRtxCodec() {
+		if apt := codec.Parameters.GetInt("apt"); apt == 0 {
+			b.add(ValidationErrorCode_Missing, path+".parameters.apt", "missing")
+		} else if apt < 0 || apt > 0xff || !payloadTypes[byte(apt)] {
+			b.add(ValidationErrorCode_UnknownPayloadType, path+".parameters.apt",
+				"%d not found in codecs", apt)
+		}
+	}
+
+	if mimeType == "audio/multiopus" {
+		checkMultiopusParameters(b, path, codec.Channels, codec.Parameters)
+	}
+
+	for j, fb := range codec.RtcpFeedback {
+		if len(fb.Type) == 0 {
+			b.add(ValidationErrorCode_Missing, fmt.Sprintf("%s.rtcpFeedback[%d].type", path, j), "missing")
+		}
+	}
+}
+
+func checkMultiopusParameters(b *validationErrorsBuilder, path string, channels int, params RtpCodecSpecificParameters) {
+	if channels <= 2 {
+		b.add(ValidationErrorCode_Invalid, path+".channels", "%d must be greater than 2", channels)
+	}
+
+	numStreams := params.GetInt("num_streams")
+	coupledStreams := params.GetInt("coupled_streams")
+	channelMapping := params.GetString("channel_mapping")
+
+	if numStreams == 0 {
+		b.add(ValidationErrorCode_Missing, path+".parameters.num_streams", "missing")
+	}
+
+	if coupledStreams > numStreams {
+		b.add(ValidationErrorCode_Invalid, path+".parameters.coupled_streams",
+			"%d greater than num_streams", coupledStreams)
+	}
+
+	if len(channelMapping) == 0 {
+		return
+	}
+
+	mapping := strings.Split(channelMapping, ",")
+
+	if len(mapping) != channels {
+		b.add(ValidationErrorCode_Invalid, path+".parameters.channel_mapping",
+			"has %d entries, expected %d", len(mapping), channels)
+		return
+	}
+
+	totalStreams := numStreams + coupledStreams
+
+	for j, entry := range mapping {
+		value, err := strconv.Atoi(strings.TrimSpace(entry))
+
+		if err != nil || value < 0 || (value >= totalStreams && value != 255) {
+			b.add(ValidationErrorCode_Invalid, fmt.Sprintf("%s.parameters.channel_mapping[%d]", path, j),
+				"%q invalid", entry)
+		}
+	}
+}
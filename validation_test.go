@@ -0,0 +1,72 @@
+package mediasoup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRtpParameters(t *testing.T) {
+	params := RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{MimeType: "video/VP8", PayloadType: 101, ClockRate: 90000},
+			{MimeType: "video/rtx", PayloadType: 102, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 101}}},
+		},
+		HeaderExtensions: []RtpHeaderExtensionParameters{
+			{Uri: "urn:ietf:params:rtp-hdrext:sdes:mid", Id: 1},
+		},
+		Encodings: []RtpEncodingParameters{
+			{Ssrc: 1111, Rtx: &RtpEncodingRtx{Ssrc: 2222}, ScalabilityMode: "L1T3"},
+			{Ssrc: 3333, Rtx: &RtpEncodingRtx{Ssrc: 4444}},
+		},
+	}
+
+	assert.Nil(t, CheckRtpParameters(params))
+
+	params.Codecs = append(params.Codecs,
+		&RtpCodecParameters{MimeType: "foo", PayloadType: 101},
+		&RtpCodecParameters{MimeType: "video/rtx", PayloadType: 103, ClockRate: 90000, Parameters: RtpCodecSpecificParameters{{Key: "apt", Value: 99}}},
+	)
+	params.HeaderExtensions = append(params.HeaderExtensions, RtpHeaderExtensionParameters{Id: 1})
+	params.Encodings[1].Rtx.Ssrc = 0
+	params.Encodings = append(params.Encodings, RtpEncodingParameters{Ssrc: 1111, CodecPayloadType: 120, ScalabilityMode: "X1"})
+
+	errs := CheckRtpParameters(params)
+
+	assert.Equal(t, ValidationErrors{
+		{Path: "codecs[2].payloadType", Code: ValidationErrorCode_Duplicated, Message: "101 duplicated"},
+		{Path: "codecs[2].mimeType", Code: ValidationErrorCode_Invalid, Message: `"foo" invalid`},
+		{Path: "codecs[2].clockRate", Code: ValidationErrorCode_Missing, Message: "missing"},
+		{Path: "codecs[3].parameters.apt", Code: ValidationErrorCode_UnknownPayloadType, Message: "99 not found in codecs"},
+		{Path: "headerExtensions[1].uri", Code: ValidationErrorCode_Missing, Message: "missing"},
+		{Path: "headerExtensions[1].id", Code: ValidationErrorCode_Duplicated, Message: "1 duplicated"},
+		{Path: "encodings[1].rtx.ssrc", Code: ValidationErrorCode_Missing, Message: "missing"},
+		{Path: "encodings[2].ssrc", Code: ValidationErrorCode_Duplicated, Message: "1111 duplicated"},
+		{Path: "encodings[2].codecPayloadType", Code: ValidationErrorCode_UnknownPayloadType, Message: "120 not found in codecs"},
+		{Path: "encodings[2].scalabilityMode", Code: ValidationErrorCode_Invalid, Message: `"X1" invalid`},
+	}, errs)
+	assert.Contains(t, errs.Error(), "encodings[1].rtx.ssrc missing")
+}
+
+func TestCheckRtpParameters_Multiopus(t *testing.T) {
+	errs := CheckRtpParameters(RtpParameters{
+		Codecs: []*RtpCodecParameters{
+			{
+				MimeType:    "audio/multiopus",
+				PayloadType: 100,
+				ClockRate:   48000,
+				Channels:    4,
+				Parameters: RtpCodecSpecificParameters{
+					{Key: "num_streams", Value: 2},
+					{Key: "coupled_streams", Value: 2},
+					{Key: "channel_mapping", Value: "0,1,4,255"},
+				},
+			},
+		},
+	})
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "codecs[0].parameters.channel_mapping[2]", errs[0].Path)
+	assert.Equal(t, ValidationErrorCode_Invalid, errs[0].Code)
+}
@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 )
@@ -59,6 +60,12 @@ type WebRtcTransportOptions struct {
 	 */
 	SctpSendBufferSize int `json:"sctpSendBufferSize,omitempty"`
 
+	/**
+	 * Base logger for the transport and its Producers and Consumers. Default
+	 * the one of the Router.
+	 */
+	Logger Logger `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -221,7 +228,7 @@ func newWebRtcTransport(params transportParams) ITransport {
 		sctpState:      data.SctpState,
 		transportType:  TransportType_Webrtc,
 	}
-	params.logger = NewLogger("WebRtcTransport")
+	params.logger = params.loggerContext.newLogger("WebRtcTransport")
 
 	transport := &WebRtcTransport{
 		ITransport:     newTransport(params),
@@ -248,6 +255,9 @@ func (t WebRtcTransport) IceRole() string {
  * ICE parameters.
  */
 func (t WebRtcTransport) IceParameters() IceParameters {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.IceParameters
 }
 
@@ -262,6 +272,9 @@ func (t WebRtcTransport) IceCandidates() []IceCandidate {
  * ICE state.
  */
 func (t WebRtcTransport) IceState() IceState {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.IceState
 }
 
@@ -269,6 +282,9 @@ func (t WebRtcTransport) IceState() IceState {
  * ICE selected tuple.
  */
 func (t WebRtcTransport) IceSelectedTuple() *TransportTuple {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.IceSelectedTuple
 }
 
@@ -276,6 +292,9 @@ func (t WebRtcTransport) IceSelectedTuple() *TransportTuple {
  * DTLS parameters.
  */
 func (t WebRtcTransport) DtlsParameters() DtlsParameters {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.DtlsParameters
 }
 
@@ -283,6 +302,9 @@ func (t WebRtcTransport) DtlsParameters() DtlsParameters {
  * DTLS state.
  */
 func (t WebRtcTransport) DtlsState() DtlsState {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.DtlsState
 }
 
@@ -290,6 +312,9 @@ func (t WebRtcTransport) DtlsState() DtlsState {
  * Remote certificate in PEM format.
  */
 func (t WebRtcTransport) DtlsRemoteCert() string {
+	t.data.locker.Lock()
+	defer t.data.locker.Unlock()
+
 	return t.data.DtlsRemoteCert
 }
 
@@ -304,7 +329,7 @@ func (t WebRtcTransport) SctpParameters() SctpParameters {
  * SRTP parameters.
  */
 func (t WebRtcTransport) SctpState() SctpState {
-	return t.data.SctpState
+	return t.data.GetSctpState()
 }
 
 /**
@@ -331,9 +356,9 @@ func (transport *WebRtcTransport) Observer() IEventEmitter {
  *
  * @override
  */
-func (transport *WebRtcTransport) Close() {
+func (transport *WebRtcTransport) Close() error {
 	if transport.Closed() {
-		return
+		return ErrAlreadyClosed
 	}
 
 	transport.data.SetIceSelectedTuple(nil)
@@ -344,7 +369,7 @@ func (transport *WebRtcTransport) Close() {
 		transport.data.SetSctpState(SctpState_Closed)
 	}
 
-	transport.ITransport.Close()
+	return transport.ITransport.Close()
 }
 
 /**
@@ -374,10 +399,17 @@ func (transport *WebRtcTransport) routerClosed() {
  * @override
  */
 func (transport *WebRtcTransport) Connect(options TransportConnectOptions) (err error) {
+	return transport.ConnectContext(context.Background(), options)
+}
+
+/**
+ * ConnectContext is Connect with a context cancelling the worker request.
+ */
+func (transport *WebRtcTransport) ConnectContext(ctx context.Context, options TransportConnectOptions) (err error) {
 	transport.logger.Debug("connect()")
 
 	reqData := TransportConnectOptions{DtlsParameters: options.DtlsParameters}
-	resp := transport.channel.Request("transport.connect", transport.internal, reqData)
+	resp := transport.channel.RequestContext(ctx, "transport.connect", transport.internal, reqData)
 
 	var data struct {
 		DtlsLocalRole DtlsRole
@@ -396,9 +428,16 @@ func (transport *WebRtcTransport) Connect(options TransportConnectOptions) (err
  * Restart ICE.
  */
 func (transport *WebRtcTransport) RestartIce() (iceParameters IceParameters, err error) {
+	return transport.RestartIceContext(context.Background())
+}
+
+/**
+ * RestartIceContext is RestartIce with a context cancelling the worker request.
+ */
+func (transport *WebRtcTransport) RestartIceContext(ctx context.Context) (iceParameters IceParameters, err error) {
 	transport.logger.Debug("restartIce()")
 
-	resp := transport.channel.Request("transport.restartIce", transport.internal)
+	resp := transport.channel.RequestContext(ctx, "transport.restartIce", transport.internal)
 
 	var data struct {
 		IceParameters IceParameters
@@ -470,7 +509,7 @@ func (transport *WebRtcTransport) handleWorkerNotifications() {
 
 		case "trace":
 			var result TransportTraceEventData
-			json.Unmarshal(data, &result)
+			unmarshalJSON(data, &result)
 
 			transport.SafeEmit("trace", result)
 
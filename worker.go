@@ -2,7 +2,9 @@ package mediasoup
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -13,12 +15,15 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
-
-	uuid "github.com/satori/go.uuid"
+	"time"
 )
 
 const VERSION = "3.7.17"
 
+// Interval at which CloseGracefully() checks whether the Producers and
+// Consumers are closed.
+const workerDrainInterval = 100 * time.Millisecond
+
 type WorkerLogLevel string
 
 const (
@@ -134,6 +139,9 @@ type WorkerResourceUsage struct {
 	RU_Nivcsw int64 `json:"ru_nivcsw"`
 }
 
+/**
+ * Default worker binary, overridden per Worker with WithWorkerBin().
+ */
 var WorkerBin string = os.Getenv("MEDIASOUP_WORKER_BIN")
 
 func init() {
@@ -163,7 +171,11 @@ type Option func(w *WorkerSettings)
 
 /**
  * Worker
- * @emits died - (error: Error)
+ * @emits died - (error: Error), Forensics() returning what was recorded until then
+ * @emits unresponsive - (error: Error, failures: int), see StartLivenessCheck()
+ * @emits responsive
+ * @emits limited - (event: WorkerLimitEvent), see WorkerResourceLimits
+ * @emits signal - (event: WorkerSignalEvent)
  * @emits @success
  * @emits @failure - (error: Error)
  */
@@ -171,8 +183,15 @@ type Worker struct {
 	IEventEmitter
 	// Worker logger.
 	logger Logger
+	// Logger context for the entities created in the Worker.
+	loggerContext loggerContext
 	// Worker process PID.
 	pid int
+	// Worker process, nil without process.
+	process *os.Process
+	// Limit counters of the cgroup of the worker process, if any.
+	limits       *cgroupLimits
+	limitsLocker sync.Mutex
 	// Channel instance.
 	channel *Channel
 	// PayloadChannel instance.
@@ -185,62 +204,112 @@ type Worker struct {
 	routers sync.Map
 	// Observer instance.
 	observer IEventEmitter
-
-	// spawnDone indices child is started
+	// Generator of the entity ids.
+	idGenerator IdGenerator
+	// Recorder of the forensics.
+	forensics *forensicsRecorder
+	// Worker process version, if known.
+	version string
+	// Whether the Worker is closing gracefully, refusing new Routers and
+	// Transports.
+	draining uint32
+	// Receives the error of the death of the worker process.
+	diedCh   chan error
+	diedOnce sync.Once
+	// Closed on close.
+	doneCh chan struct{}
+	// Closed once the worker process exited, nil without process.
+	exitCh chan struct{}
+	// Time Close() waits for the worker process to exit after SIGTERM, and
+	// then after SIGKILL.
+	closeTimeout time.Duration
+
+	// Output line of the worker process telling the RTC port range is
+	// unavailable, written while spawning.
+	portRangeFailure atomic.Value
+
+	// Set once the spawn is over, with "running", its failure or its timeout,
+	// whichever comes first. Like closed, only accessed atomically as the
+	// watchdog, the Channel and the user goroutines race for it.
 	spawnDone uint32
 }
 
+/**
+ * Spawn a Worker, waiting for its worker process to report it is running, at
+ * most WorkerSettings.SpawnTimeout (ErrSpawnTimeout being returned then), and
+ * checking its version (ErrIncompatibleWorker being returned if unsupported).
+ * On failure the spawn error is returned and the Worker is nil.
+ */
 func NewWorker(options ...Option) (worker *Worker, err error) {
-	logger := NewLogger("Worker")
-	settings := &WorkerSettings{
-		LogLevel:   WorkerLogLevel_Error,
-		RtcMinPort: 10000,
-		RtcMaxPort: 59999,
-		AppData:    H{},
-	}
+	settings := newWorkerSettings(options...)
 
-	for _, option := range options {
-		option(settings)
-	}
+	loggerContext := loggerContext{}.with(settings.Logger).withLabels(settings.Labels)
+	logger := loggerContext.newLogger("Worker")
 
 	logger.Debug("constructor()")
 
-	producerPair, err := createSocketPair()
-	if err != nil {
-		return
-	}
-	consumerPair, err := createSocketPair()
-	if err != nil {
-		return
-	}
-	payloadProducerPair, err := createSocketPair()
-	if err != nil {
-		return
-	}
-	payloadConsumerPair, err := createSocketPair()
-	if err != nil {
+	if err = settings.validate(); err != nil {
 		return
 	}
 
-	producerSocket, err := fileToConn(producerPair[0])
+	// Channel protocol of the worker version, the JSON one if unknown.
+	codec, err := newChannelCodec(ProtocolForWorkerVersion(settings.WorkerVersion))
 	if err != nil {
 		return
 	}
-	consumerSocket, err := fileToConn(consumerPair[0])
-	if err != nil {
-		return
+
+	var (
+		// Closed on failure: the files until the worker process inherited
+		// them, the sockets until the Worker owns them.
+		files     []io.Closer
+		conns     []net.Conn
+		dtlsPipes *dtlsPipes
+	)
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, file := range files {
+			file.Close()
+		}
+		for _, conn := range conns {
+			conn.Close()
+		}
+		if dtlsPipes != nil {
+			dtlsPipes.close()
+		}
+	}()
+
+	var pairs [4][2]*os.File
+
+	for i := range pairs {
+		if pairs[i], err = createSocketPair(); err != nil {
+			return
+		}
+		files = append(files, pairs[i][0], pairs[i][1])
 	}
-	payloadProducerSocket, err := fileToConn(payloadProducerPair[0])
-	if err != nil {
-		return
+
+	for _, pair := range pairs {
+		var conn net.Conn
+
+		if conn, err = fileToConn(pair[0]); err != nil {
+			return
+		}
+		conns = append(conns, conn)
 	}
-	payloadConsumerSocket, err := fileToConn(payloadConsumerPair[0])
-	if err != nil {
+	producerSocket, consumerSocket := conns[0], conns[1]
+	payloadProducerSocket, payloadConsumerSocket := conns[2], conns[3]
+
+	// Inherited after the sockets of the Channel and the PayloadChannel.
+	if dtlsPipes, err = settings.openDtlsPipes(3 + 4); err != nil {
 		return
 	}
 
-	bin := strings.TrimSpace(WorkerBin)
-	args := settings.Args()
+	bin := strings.TrimSpace(settings.WorkerBin)
+	if len(bin) == 0 {
+		bin = strings.TrimSpace(WorkerBin)
+	}
+	args := dtlsPipes.args(*settings)
 
 	if binArgs := strings.Fields(bin); len(binArgs) > 1 {
 		bin = binArgs[0]
@@ -250,95 +319,203 @@ func NewWorker(options ...Option) (worker *Worker, err error) {
 	logger.Debug("spawning worker process: %s %s", bin, strings.Join(args, " "))
 
 	child := exec.Command(bin, args...)
-	child.ExtraFiles = []*os.File{producerPair[1], consumerPair[1], payloadProducerPair[1], payloadConsumerPair[1]}
-	child.Env = []string{"MEDIASOUP_VERSION=" + VERSION}
+	child.ExtraFiles = []*os.File{pairs[0][1], pairs[1][1], pairs[2][1], pairs[3][1]}
+	child.ExtraFiles = append(child.ExtraFiles, dtlsPipes.readers...)
+	child.Env = settings.Environ()
 
 	stderr, err := child.StderrPipe()
 	if err != nil {
 		return
 	}
+	files = append(files, stderr)
+
 	stdout, err := child.StdoutPipe()
 	if err != nil {
 		return
 	}
+	files = append(files, stdout)
+
 	if err = child.Start(); err != nil {
 		return
 	}
 
+	// Inherited by the worker process.
+	for _, pair := range pairs {
+		pair[1].Close()
+	}
+	files = nil
+	dtlsPipes.start(logger)
+	dtlsPipes = nil
+
 	pid := child.Process.Pid
-	channel := newChannel(producerSocket, consumerSocket, pid)
-	payloadChannel := newPayloadChannel(payloadProducerSocket, payloadConsumerSocket)
-	workerLogger := NewLogger(fmt.Sprintf("worker[pid:%d]", pid))
 
-	go func() {
-		r := bufio.NewReader(stderr)
-		for {
-			line, _, err := r.ReadLine()
-			if err != nil {
-				break
-			}
-			workerLogger.Error("(stderr) %s", line)
+	if err = settings.applyScheduling(pid); err == nil {
+		err = settings.applyResourceLimits(pid)
+	}
+	if err != nil {
+		logger.Error("worker process scheduling failed [pid:%d]: %v", pid, err)
+		child.Process.Kill()
+		child.Wait()
+		return
+	}
+	loggerContext = loggerContext.with(nil, "workerPid", pid)
+	logger = loggerContext.newLogger("Worker")
+	channel := newChannel(producerSocket, consumerSocket, pid, codec, loggerContext)
+	payloadChannel := newPayloadChannel(payloadProducerSocket, payloadConsumerSocket, pid, codec, loggerContext)
+	workerLogger := loggerContext.newLogger(fmt.Sprintf("worker[pid:%d]", pid))
+
+	channel.logHandler = settings.LogHandler
+
+	w := newWorker(pid, channel, payloadChannel, settings, loggerContext)
+	conns = nil
+	w.exitCh = make(chan struct{})
+	w.process = child.Process
+	w.forensics.setCommand(bin, args)
+
+	if limits := settings.ResourceLimits; len(limits.Cgroup) > 0 {
+		w.limits = newCgroupLimits(limits.Cgroup)
+
+		if limits.CheckInterval > 0 {
+			goWithWorkerLabels(pid, "limits-watcher", func() { w.watchLimits(limits.CheckInterval) })
 		}
-	}()
+	}
+
+	var logsWg sync.WaitGroup
 
-	go func() {
-		r := bufio.NewReader(stdout)
+	readLogs := func(reader io.Reader, stream string, level WorkerLogLevel, writer io.Writer) {
+		defer logsWg.Done()
+
+		logger := workerLogger.With("stream", stream)
+		r := bufio.NewReader(reader)
 		for {
 			line, _, err := r.ReadLine()
 			if err != nil {
 				break
 			}
-			workerLogger.Debug("(stdout) %s", line)
-		}
-	}()
+			log := parseWorkerLog(pid, level, string(line))
 
-	worker = &Worker{
-		IEventEmitter:  NewEventEmitter(),
-		logger:         logger,
-		pid:            pid,
-		channel:        channel,
-		payloadChannel: payloadChannel,
-		appData:        settings.AppData,
-		observer:       NewEventEmitter(),
+			if writer != nil {
+				if _, err := fmt.Fprintf(writer, "%s\n", line); err != nil {
+					logger.Warn("writing worker output failed: %v", err)
+				}
+			} else {
+				log.writeTo(logger)
+			}
+			w.forensics.recordLog(stream, log)
+			w.recordSpawnOutput(string(line))
+
+			if settings.LogHandler != nil {
+				settings.LogHandler(log)
+			}
+		}
 	}
 
-	doneCh := make(chan error)
+	logsWg.Add(2)
+	goWithWorkerLabels(pid, "stderr-pump", func() { readLogs(stderr, "stderr", WorkerLogLevel_Error, settings.Stderr) })
+	goWithWorkerLabels(pid, "stdout-pump", func() { readLogs(stdout, "stdout", WorkerLogLevel_Debug, settings.Stdout) })
+
+	doneCh := make(chan error, 1)
 
 	channel.Once(strconv.Itoa(pid), func(event string) {
-		if atomic.CompareAndSwapUint32(&worker.spawnDone, 0, 1) && event == "running" {
+		if event == "running" && atomic.CompareAndSwapUint32(&w.spawnDone, 0, 1) {
 			logger.Debug("worker process running [pid:%d]", pid)
-			worker.Emit("@success")
+			w.Emit("@success")
 			close(doneCh)
 		}
 	})
-	worker.Once("@failure", func(err error) { doneCh <- err })
+	w.Once("@failure", func(err error) { doneCh <- err })
 
-	go worker.wait(child)
+	goWithWorkerLabels(pid, "watchdog", func() { w.wait(child, logsWg.Wait) })
+	goWithWorkerLabels(pid, "signal-watcher", w.watchSignals)
 
 	// start to handle channel data
 	channel.Start()
 
-	err = <-doneCh
+	timer := time.NewTimer(settings.SpawnTimeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-doneCh:
+	case <-timer.C:
+		if atomic.CompareAndSwapUint32(&w.spawnDone, 0, 1) {
+			logger.Error("worker process did not report running within %s [pid:%d]", settings.SpawnTimeout, pid)
+			err = fmt.Errorf("%w [pid:%d, timeout:%s]", ErrSpawnTimeout, pid, settings.SpawnTimeout)
+		} else {
+			// "running" or the failure raced with the timeout.
+			err = <-doneCh
+		}
+	}
+
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), settings.SpawnTimeout)
+		err = w.negotiateVersion(ctx, settings.WorkerVersion)
+		cancel()
+	}
+
+	if err != nil {
+		w.Close()
+		return
+	}
+	worker = w
 
 	return
 }
 
-func (w *Worker) wait(child *exec.Cmd) {
-	if w.Closed() {
-		return
+func newWorkerSettings(options ...Option) *WorkerSettings {
+	settings := &WorkerSettings{
+		LogLevel:     WorkerLogLevel_Error,
+		RtcMinPort:   10000,
+		RtcMaxPort:   59999,
+		SpawnTimeout: 10 * time.Second,
+		CloseTimeout: 5 * time.Second,
+		AppData:      H{},
 	}
 
-	// clean up unix descriptors
-	defer func() {
-		w.channel.Close()
-		w.payloadChannel.Close()
-		for _, extraFile := range child.ExtraFiles {
-			extraFile.Close()
-		}
-	}()
+	for _, option := range options {
+		option(settings)
+	}
+
+	return settings
+}
+
+func newWorker(pid int, channel *Channel, payloadChannel *PayloadChannel, settings *WorkerSettings, loggerContext loggerContext) *Worker {
+	worker := &Worker{
+		IEventEmitter:  NewEventEmitter(),
+		logger:         loggerContext.newLogger("Worker"),
+		loggerContext:  loggerContext,
+		pid:            pid,
+		channel:        channel,
+		payloadChannel: payloadChannel,
+		appData:        settings.AppData,
+		observer:       loggerContext.newObserver("worker", strconv.Itoa(pid)),
+		idGenerator:    settings.IdGenerator,
+		forensics:      newForensicsRecorder(settings),
+		diedCh:         make(chan error, 1),
+		doneCh:         make(chan struct{}),
+		closeTimeout:   settings.CloseTimeout,
+	}
+
+	channel.recorder = worker.forensics
+	channel.policies = settings.RequestPolicies
+	channel.On("@request", func(info ChannelRequestInfo) {
+		worker.observer.SafeEmit("request", info)
+	})
+
+	if interval := settings.Forensics.ResourceUsageInterval; interval > 0 {
+		goWithWorkerLabels(pid, "resource-usage-poller", func() { worker.pollResourceUsage(interval) })
+	}
 
+	return worker
+}
+
+// wait waits for the exit of the worker process, once its logs are read to keep
+// the last ones in the forensics.
+func (w *Worker) wait(child *exec.Cmd, waitLogs func()) {
 	var code int
 	var signal = os.Interrupt
+	var signaled string
+
+	waitLogs()
 
 	if exiterr, ok := child.Wait().(*exec.ExitError); ok {
 		// The worker has exited with an exit code != 0
@@ -347,14 +524,47 @@ func (w *Worker) wait(child *exec.Cmd) {
 
 			if status.Signaled() {
 				signal = status.Signal()
+				signaled = signal.String()
 			} else {
 				signal = status.StopSignal()
 			}
 		}
 	}
 
+	closed := w.Closed()
+	died := fmt.Errorf("%w [pid:%d, code:%d, signal:%s]", ErrWorkerDied, w.pid, code, signal)
+
+	// Fail the pending requests with the death of the worker process.
+	if !closed && atomic.LoadUint32(&w.spawnDone) > 0 {
+		w.channel.workerExited(died)
+		w.payloadChannel.workerExited(died)
+	} else {
+		w.channel.workerExited(nil)
+		w.payloadChannel.workerExited(nil)
+	}
+
+	// clean up unix descriptors
+	w.channel.Close()
+	w.payloadChannel.Close()
+	for _, extraFile := range child.ExtraFiles {
+		extraFile.Close()
+	}
+	close(w.exitCh)
+
+	// Terminated by Close().
+	if closed {
+		return
+	}
+
 	if atomic.CompareAndSwapUint32(&w.spawnDone, 0, 1) {
-		if code == 42 {
+		if line, ok := w.portRangeFailure.Load().(string); ok {
+			settings := w.forensics.settings
+			err := fmt.Errorf("%w [pid:%d, rtcMinPort:%d, rtcMaxPort:%d]: %s",
+				ErrPortRangeUnavailable, w.pid, settings.RtcMinPort, settings.RtcMaxPort, line)
+
+			w.logger.Error("worker process failed: %v", err)
+			w.Emit("@failure", err)
+		} else if code == 42 {
 			w.logger.Error("worker process failed due to wrong settings [pid:%d]", w.pid)
 			w.Emit("@failure", NewTypeError("wrong settings"))
 		} else {
@@ -363,8 +573,19 @@ func (w *Worker) wait(child *exec.Cmd) {
 			w.Emit("@failure", fmt.Errorf(`[pid:%d, code:%d, signal:%s]`, w.pid, code, signal))
 		}
 	} else {
+		err := died
+
 		w.logger.Error("worker process died unexpectedly [pid:%d, code:%d, signal:%s]", w.pid, code, signal)
-		w.SafeEmit("died", fmt.Errorf("[pid:%d, code:%d, signal:%s]", w.pid, code, signal))
+		if len(signaled) > 0 {
+			w.emitSignal(WorkerSignalEvent{Type: WorkerSignalType_Terminated, Signal: signal.(syscall.Signal)})
+		}
+		// Killed by the OOM killer of its cgroup?
+		if w.limits != nil && signal == syscall.SIGKILL {
+			w.emitLimits(true)
+		}
+		w.captureForensics(err, code, signaled)
+		w.died(err)
+		w.SafeEmit("died", err)
 	}
 
 	w.Close()
@@ -378,12 +599,41 @@ func (w *Worker) Pid() int {
 }
 
 /**
- * Whether the Worker is closed.
+ * Whether the Worker is closed. Safe to call concurrently with Close() and the
+ * death of the worker process.
  */
 func (w *Worker) Closed() bool {
 	return atomic.LoadUint32(&w.closed) > 0
 }
 
+/**
+ * Channel receiving the error of the death of the worker process, then closed.
+ * It is closed without error (receiving nil) if the Worker is closed with
+ * Close() instead.
+ */
+func (w *Worker) Died() <-chan error {
+	return w.diedCh
+}
+
+/**
+ * Channel closed once the Worker is closed, either with Close() or because its
+ * worker process died.
+ */
+func (w *Worker) Done() <-chan struct{} {
+	return w.doneCh
+}
+
+// died delivers the error of the death of the worker process to Died(), nil
+// if the Worker was closed with Close().
+func (w *Worker) died(err error) {
+	w.diedOnce.Do(func() {
+		if err != nil {
+			w.diedCh <- err
+		}
+		close(w.diedCh)
+	})
+}
+
 /**
  * App custom data.
  */
@@ -391,27 +641,68 @@ func (w *Worker) AppData() interface{} {
 	return w.appData
 }
 
-// Observer
+/**
+ * Labels of the Worker, see WorkerSettings.Labels. Must not be modified.
+ */
+func (w *Worker) Labels() map[string]string {
+	return w.loggerContext.labels
+}
+
+/**
+ * Observer.
+ *
+ * @emits close
+ * @emits newrouter - (router: *Router)
+ * @emits request - (info: ChannelRequestInfo)
+ * @emits resourceusage - (stats: WorkerResourceUsageStats), see StartResourceMonitor()
+ */
 func (w *Worker) Observer() IEventEmitter {
 	return w.observer
 }
 
+// Routers returns the routers of the Worker.
+func (w *Worker) Routers() []*Router {
+	routers := make([]*Router, 0)
+	w.routers.Range(func(key, value interface{}) bool {
+		routers = append(routers, value.(*Router))
+		return true
+	})
+	return routers
+}
+
+/**
+ * AllRouters iterates over the routers of the Worker, stopping when yield
+ * returns false. With Go 1.23 or later:
+ *
+ *	for router := range worker.AllRouters() {
+ *		...
+ *	}
+ */
+func (w *Worker) AllRouters() func(yield func(*Router) bool) {
+	return func(yield func(*Router) bool) {
+		w.routers.Range(func(key, value interface{}) bool {
+			return yield(value.(*Router))
+		})
+	}
+}
+
 /**
- * Close the Worker.
+ * Close the Worker, terminating its worker process: SIGKILL is sent if it does
+ * not exit within WorkerSettings.CloseTimeout after SIGTERM. It returns once
+ * the process exited and the channel goroutines returned (each wait being
+ * bounded by CloseTimeout). ErrAlreadyClosed is returned if it was already
+ * closed.
  */
-func (w *Worker) Close() {
+func (w *Worker) Close() (err error) {
 	if !atomic.CompareAndSwapUint32(&w.closed, 0, 1) {
-		return
+		return ErrAlreadyClosed
 	}
 
 	w.logger.Debug("close()")
 
-	// Kill the worker process.
-	if pid := w.Pid(); pid > 0 {
-		if process, err := os.FindProcess(pid); err == nil {
-			process.Signal(syscall.SIGTERM)
-			process.Signal(os.Kill)
-		}
+	// Terminate the worker process.
+	if w.exitCh != nil {
+		err = w.terminate()
 	}
 
 	// Close the Channel instance.
@@ -420,25 +711,147 @@ func (w *Worker) Close() {
 	// Close the PayloadChannel instance.
 	w.payloadChannel.Close()
 
+	// Closed from an event handler run by a channel goroutine, which cannot
+	// return before Close() does: wait for the goroutines in the background.
+	if w.channel.isDispatching() || w.payloadChannel.isDispatching() {
+		go w.waitChannels()
+	} else {
+		w.waitChannels()
+	}
+
 	// Close every Router.
 	w.routers.Range(func(key, value interface{}) bool {
 		router := value.(*Router)
 		router.workerClosed()
 		return true
 	})
-	w.routers = sync.Map{}
+	clearSyncMap(&w.routers)
 	w.RemoveAllListeners()
 
+	w.died(nil)
+	close(w.doneCh)
+
 	// Emit observer event.
 	w.observer.SafeEmit("close")
 	w.observer.RemoveAllListeners()
+
+	return
+}
+
+// terminate sends SIGTERM to the worker process and waits for its exit, at
+// most closeTimeout before sending SIGKILL.
+func (w *Worker) terminate() (err error) {
+	select {
+	case <-w.exitCh:
+		return
+	default:
+	}
+
+	process, err := os.FindProcess(w.pid)
+	if err != nil {
+		return
+	}
+	if err = process.Signal(syscall.SIGTERM); err != nil {
+		return
+	}
+
+	timer := time.NewTimer(w.closeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.exitCh:
+		return
+	case <-timer.C:
+	}
+
+	w.logger.Warn("worker process did not exit within %s after SIGTERM, killing it [pid:%d]", w.closeTimeout, w.pid)
+
+	if err = process.Kill(); err != nil {
+		return
+	}
+
+	timer.Reset(w.closeTimeout)
+
+	select {
+	case <-w.exitCh:
+	case <-timer.C:
+		err = fmt.Errorf("worker process did not exit after SIGKILL [pid:%d]", w.pid)
+	}
+
+	return
+}
+
+// waitChannels waits for the goroutines of the closed channels to return.
+func (w *Worker) waitChannels() {
+	if !w.channel.wait(w.closeTimeout) || !w.payloadChannel.wait(w.closeTimeout) {
+		w.logger.Warn("channel goroutines did not return within %s", w.closeTimeout)
+	}
+}
+
+/**
+ * Close the Worker once its Producers and Consumers are closed: new Routers and
+ * Transports are refused from now on, and the worker process is terminated
+ * when the last Producer or Consumer is closed or when the context is done,
+ * whose error is returned then. ErrAlreadyClosed is returned if the Worker was
+ * already closed.
+ */
+func (w *Worker) CloseGracefully(ctx context.Context) (err error) {
+	if w.Closed() {
+		return ErrAlreadyClosed
+	}
+
+	w.logger.Debug("closeGracefully()")
+
+	atomic.StoreUint32(&w.draining, 1)
+
+	ticker := time.NewTicker(workerDrainInterval)
+	defer ticker.Stop()
+
+	for w.mediaCount() > 0 && err == nil && !w.Closed() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			err = ctx.Err()
+			w.logger.Warn("closing worker with %d producers and consumers left: %v", w.mediaCount(), err)
+		}
+	}
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+
+	return
+}
+
+/**
+ * Whether the Worker is closing gracefully.
+ */
+func (w *Worker) Draining() bool {
+	return atomic.LoadUint32(&w.draining) > 0
+}
+
+// mediaCount returns the number of Producers and Consumers of the Worker.
+func (w *Worker) mediaCount() (count int) {
+	for _, router := range w.Routers() {
+		for _, transport := range router.Transports() {
+			count += len(transport.Producers()) + len(transport.Consumers())
+		}
+	}
+	return
 }
 
 // Dump Worker.
 func (w *Worker) Dump() (dump WorkerDump, err error) {
+	return w.DumpContext(context.Background())
+}
+
+/**
+ * DumpContext is Dump with a context cancelling the worker request.
+ */
+func (w *Worker) DumpContext(ctx context.Context) (dump WorkerDump, err error) {
 	w.logger.Debug("dump()")
 
-	err = w.channel.Request("worker.dump", nil).Unmarshal(&dump)
+	err = w.channel.RequestContext(ctx, "worker.dump", nil).Unmarshal(&dump)
 
 	return
 }
@@ -447,43 +860,89 @@ func (w *Worker) Dump() (dump WorkerDump, err error) {
  * Get mediasoup-worker process resource usage.
  */
 func (w *Worker) GetResourceUsage() (usage WorkerResourceUsage, err error) {
+	return w.GetResourceUsageContext(context.Background())
+}
+
+/**
+ * GetResourceUsageContext is GetResourceUsage with a context cancelling the worker request.
+ */
+func (w *Worker) GetResourceUsageContext(ctx context.Context) (usage WorkerResourceUsage, err error) {
 	w.logger.Debug("getResourceUsage()")
 
-	resp := w.channel.Request("worker.getResourceUsage", nil)
+	resp := w.channel.RequestContext(ctx, "worker.getResourceUsage", nil)
 	err = resp.Unmarshal(&usage)
 
 	return
 }
 
+/**
+ * State of the communication with the worker process (pending requests,
+ * queued messages, dispatch latency), e.g. to publish it with expvar.
+ */
+func (w *Worker) IPCStats() WorkerIPCStats {
+	return WorkerIPCStats{
+		Channel:        w.channel.Stats(),
+		PayloadChannel: w.payloadChannel.Stats(),
+	}
+}
+
 // UpdateSettings Update settings.
 func (w *Worker) UpdateSettings(settings WorkerUpdateableSettings) error {
+	return w.UpdateSettingsContext(context.Background(), settings)
+}
+
+/**
+ * UpdateSettingsContext is UpdateSettings with a context cancelling the worker request.
+ */
+func (w *Worker) UpdateSettingsContext(ctx context.Context, settings WorkerUpdateableSettings) error {
 	w.logger.Debug("updateSettings()")
 
-	return w.channel.Request("worker.updateSettings", nil, settings).Err()
+	return w.channel.RequestContext(ctx, "worker.updateSettings", nil, settings).Err()
 }
 
 // CreateRouter creates a router.
-func (w *Worker) CreateRouter(options RouterOptions) (router *Router, err error) {
-	w.logger.Debug("createRouter()")
+func (w *Worker) CreateRouter(options RouterOptions, opts ...RouterOption) (router *Router, err error) {
+	return w.CreateRouterContext(context.Background(), options, opts...)
+}
 
-	internal := internalData{RouterId: uuid.NewV4().String()}
+/**
+ * CreateRouterContext is CreateRouter with a context cancelling the worker request.
+ */
+func (w *Worker) CreateRouterContext(ctx context.Context, options RouterOptions, opts ...RouterOption) (router *Router, err error) {
+	w.logger.Debug("createRouter()")
 
-	rsp := w.channel.Request("worker.createRouter", internal, nil)
-	if err = rsp.Err(); err != nil {
+	if w.Draining() {
+		err = NewInvalidStateError("worker draining")
 		return
 	}
 
+	for _, opt := range opts {
+		opt.applyRouter(&options)
+	}
+
+	// Generated first not to create in the worker a Router with invalid codecs.
 	rtpCapabilities, err := generateRouterRtpCapabilities(options.MediaCodecs)
 	if err != nil {
 		return
 	}
+
+	internal := internalData{RouterId: w.idGenerator.newId("router")}
+
+	rsp := w.channel.requestCreation(ctx, "worker.createRouter", "router.close", internal)
+	if err = rsp.Err(); err != nil {
+		return
+	}
+
 	data := routerData{RtpCapabilities: rtpCapabilities}
 	router = newRouter(routerParams{
 		internal:       internal,
+		loggerContext:  w.loggerContext.with(options.Logger, "routerId", internal.RouterId),
 		data:           data,
 		channel:        w.channel,
 		payloadChannel: w.payloadChannel,
 		appData:        options.AppData,
+		idGenerator:    w.idGenerator,
+		draining:       w.Draining,
 	})
 
 	w.routers.Store(internal.RouterId, router)
@@ -0,0 +1,127 @@
+package mediasoup
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+/**
+ * PEM encode a DTLS certificate chain (DER) and its private key, to be given
+ * to WithDtlsCertPEM(). The key must be exportable (RSA, ECDSA or Ed25519);
+ * e.g. for a tls.Certificate:
+ *
+ *	certPEM, keyPEM, err := mediasoup.EncodeDtlsKeyPair(cert.Certificate, cert.PrivateKey.(crypto.Signer))
+ */
+func EncodeDtlsKeyPair(certificate [][]byte, key crypto.Signer) (certPEM, keyPEM []byte, err error) {
+	if len(certificate) == 0 {
+		err = NewTypeError("missing DTLS certificate")
+		return
+	}
+	for _, der := range certificate {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		err = NewTypeError("unexportable DTLS private key: %s", err)
+		return
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	return
+}
+
+// validateDtlsPEM checks the in-memory DTLS certificate and private key.
+func (w WorkerSettings) validateDtlsPEM() error {
+	if len(w.DtlsCertificate) == 0 && len(w.DtlsPrivateKey) == 0 {
+		return nil
+	}
+	if len(w.DtlsCertificate) == 0 || len(w.DtlsPrivateKey) == 0 {
+		return NewTypeError("both dtlsCertificate and dtlsPrivateKey must be set")
+	}
+	if len(w.DtlsCertificateFile) > 0 || len(w.DtlsPrivateKeyFile) > 0 {
+		return NewTypeError("dtlsCertificate/dtlsPrivateKey and dtlsCertificateFile/dtlsPrivateKeyFile are exclusive")
+	}
+	if block, _ := pem.Decode(w.DtlsCertificate); block == nil {
+		return NewTypeError("dtlsCertificate is not PEM encoded")
+	}
+	if block, _ := pem.Decode(w.DtlsPrivateKey); block == nil {
+		return NewTypeError("dtlsPrivateKey is not PEM encoded")
+	}
+	return nil
+}
+
+// dtlsPipes gives the in-memory DTLS certificate and private key to the
+// worker process through pipes it inherits, never writing them into a file.
+type dtlsPipes struct {
+	// Ends inherited by the worker process.
+	readers []*os.File
+	// Ends written by us.
+	writers []*os.File
+	data    [][]byte
+	// Paths the worker process opens the read ends by.
+	paths []string
+}
+
+// openDtlsPipes creates the pipes of the in-memory DTLS certificate and
+// private key, if any, given the fd number their read ends get in the worker
+// process.
+func (w WorkerSettings) openDtlsPipes(firstFd int) (pipes *dtlsPipes, err error) {
+	pipes = &dtlsPipes{}
+
+	if len(w.DtlsCertificate) == 0 {
+		return
+	}
+
+	for i, data := range [][]byte{w.DtlsCertificate, w.DtlsPrivateKey} {
+		r, wr, err := os.Pipe()
+		if err != nil {
+			pipes.close()
+			return nil, fmt.Errorf("creating DTLS pipe: %w", err)
+		}
+		pipes.readers = append(pipes.readers, r)
+		pipes.writers = append(pipes.writers, wr)
+		pipes.data = append(pipes.data, data)
+		pipes.paths = append(pipes.paths, fmt.Sprintf("/dev/fd/%d", firstFd+i))
+	}
+
+	return
+}
+
+// args returns the worker arguments of the given settings, the DTLS files
+// being the pipes if any. The given settings are left unchanged.
+func (p *dtlsPipes) args(settings WorkerSettings) []string {
+	if len(p.paths) > 0 {
+		settings.DtlsCertificateFile = p.paths[0]
+		settings.DtlsPrivateKeyFile = p.paths[1]
+	}
+	return settings.Args()
+}
+
+// start writes the certificate and the private key once the worker process
+// inherited the read ends. A write fails once the worker process exited
+// without reading them, as no read end is left open.
+func (p *dtlsPipes) start(logger Logger) {
+	for _, r := range p.readers {
+		r.Close()
+	}
+	for i, w := range p.writers {
+		go func(w *os.File, data []byte) {
+			defer w.Close()
+
+			if _, err := w.Write(data); err != nil {
+				logger.Error("writing DTLS pipe failed: %v", err)
+			}
+		}(w, p.data[i])
+	}
+}
+
+// close closes the pipes of a worker process which did not start.
+func (p *dtlsPipes) close() {
+	for _, f := range append(p.readers, p.writers...) {
+		f.Close()
+	}
+}
@@ -0,0 +1,438 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Maximum length of the data of a recorded Channel message.
+const forensicsDataMaxLen = 256
+
+/**
+ * ForensicsOptions configure what a Worker records to diagnose the death of
+ * its worker process.
+ */
+type ForensicsOptions struct {
+	/**
+	 * Number of the last Channel requests, responses and notifications kept.
+	 * Default 100, negative to record none.
+	 */
+	MaxMessages int
+
+	/**
+	 * Number of the last log lines of the worker process kept. Default 100,
+	 * negative to record none.
+	 */
+	MaxLogs int
+
+	/**
+	 * Interval between the resource usage samples of the worker process. No
+	 * sample is taken if 0.
+	 */
+	ResourceUsageInterval time.Duration
+
+	/**
+	 * Number of the last resource usage samples kept. Default 60.
+	 */
+	MaxResourceUsageSamples int
+
+	/**
+	 * Directory the forensics are written to as JSON when the worker process
+	 * dies, if set.
+	 */
+	Dir string
+}
+
+/**
+ * Message exchanged with the worker process through the Channel.
+ */
+type ForensicsMessage struct {
+	Time time.Time `json:"time"`
+
+	/**
+	 * "request", "response" or "notification".
+	 */
+	Type string `json:"type"`
+
+	/**
+	 * Id of the request, 0 for a notification.
+	 */
+	Id int64 `json:"id,omitempty"`
+
+	/**
+	 * Method of the request or event of the notification.
+	 */
+	Method string `json:"method"`
+
+	/**
+	 * Id of the entity which emitted the notification.
+	 */
+	TargetId string `json:"targetId,omitempty"`
+
+	/**
+	 * Request (with its internal ids) or notification data as JSON, truncated.
+	 */
+	Data string `json:"data,omitempty"`
+
+	/**
+	 * Time taken by the worker to answer the request.
+	 */
+	Duration time.Duration `json:"duration,omitempty"`
+
+	/**
+	 * Error of the request, if any.
+	 */
+	Error string `json:"error,omitempty"`
+}
+
+/**
+ * Request sent to the worker process and still waiting for its response.
+ */
+type ForensicsRequest struct {
+	Id      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+/**
+ * Log line written by the worker process.
+ */
+type ForensicsLog struct {
+	Time time.Time `json:"time"`
+
+	/**
+	 * "stderr", "stdout" or "channel".
+	 */
+	Stream  string            `json:"stream"`
+	Level   WorkerLogLevel    `json:"level"`
+	Source  string            `json:"source,omitempty"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+/**
+ * Resource usage of the worker process at a given time.
+ */
+type ResourceUsageSample struct {
+	Time  time.Time           `json:"time"`
+	Usage WorkerResourceUsage `json:"usage"`
+}
+
+/**
+ * What was recorded of a Worker until the death of its worker process (or
+ * until now if it is still running), to diagnose it after the fact.
+ */
+type WorkerForensics struct {
+	Pid int `json:"pid"`
+
+	/**
+	 * Time of the capture, i.e. of the death of the worker process.
+	 */
+	Time      time.Time `json:"time"`
+	StartedAt time.Time `json:"startedAt"`
+
+	/**
+	 * Error emitted with "died", if any.
+	 */
+	Error string `json:"error,omitempty"`
+
+	/**
+	 * Exit code of the worker process and signal which killed it, if any.
+	 */
+	ExitCode int    `json:"exitCode,omitempty"`
+	Signal   string `json:"signal,omitempty"`
+
+	/**
+	 * Worker binary, arguments and settings it was spawned with. AppData is
+	 * left out of the settings.
+	 */
+	Bin      string         `json:"bin,omitempty"`
+	Args     []string       `json:"args"`
+	Settings WorkerSettings `json:"settings"`
+
+	/**
+	 * Last messages exchanged through the Channel, oldest first.
+	 */
+	Messages []ForensicsMessage `json:"messages"`
+
+	/**
+	 * Requests still waiting for their response, e.g. the one which crashed
+	 * the worker process.
+	 */
+	PendingRequests []ForensicsRequest `json:"pendingRequests"`
+
+	/**
+	 * Last log lines of the worker process, oldest first.
+	 */
+	Logs []ForensicsLog `json:"logs"`
+
+	/**
+	 * Last resource usage samples, oldest first.
+	 */
+	ResourceUsage []ResourceUsageSample `json:"resourceUsage"`
+
+	IPCStats WorkerIPCStats `json:"ipcStats"`
+
+	/**
+	 * File the forensics were written to, if any.
+	 */
+	Path string `json:"-"`
+}
+
+// ring keeps the last items added to it.
+type ring struct {
+	items []interface{}
+	size  int
+	next  int
+}
+
+func (r *ring) add(item interface{}) {
+	if r.size <= 0 {
+		return
+	}
+	if len(r.items) < r.size {
+		r.items = append(r.items, item)
+		return
+	}
+	r.items[r.next] = item
+	r.next = (r.next + 1) % r.size
+}
+
+// each calls fn with the items, oldest first.
+func (r *ring) each(fn func(item interface{})) {
+	for i := range r.items {
+		fn(r.items[(r.next+i)%len(r.items)])
+	}
+}
+
+// forensicsRecorder records the state of a Worker for its forensics.
+type forensicsRecorder struct {
+	options   ForensicsOptions
+	startedAt time.Time
+	locker    sync.Mutex
+	bin       string
+	args      []string
+	settings  WorkerSettings
+	messages  ring
+	logs      ring
+	usages    ring
+	captured  *WorkerForensics
+}
+
+func newForensicsRecorder(settings *WorkerSettings) *forensicsRecorder {
+	options := settings.Forensics
+
+	if options.MaxMessages == 0 {
+		options.MaxMessages = 100
+	}
+	if options.MaxLogs == 0 {
+		options.MaxLogs = 100
+	}
+	if options.MaxResourceUsageSamples == 0 {
+		options.MaxResourceUsageSamples = 60
+	}
+
+	recorder := &forensicsRecorder{
+		options:   options,
+		startedAt: time.Now(),
+		args:      settings.Args(),
+		settings:  *settings,
+		messages:  ring{size: options.MaxMessages},
+		logs:      ring{size: options.MaxLogs},
+		usages:    ring{size: options.MaxResourceUsageSamples},
+	}
+	recorder.settings.AppData = nil
+	recorder.settings.DtlsCertificate = nil
+	recorder.settings.DtlsPrivateKey = nil
+
+	return recorder
+}
+
+func (r *forensicsRecorder) setCommand(bin string, args []string) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.bin, r.args = bin, args
+}
+
+func (r *forensicsRecorder) recordMessage(message ForensicsMessage, data []byte) {
+	// The Channel may be used without Worker.
+	if r == nil {
+		return
+	}
+	// Redacted before truncating, which could leave a secret unmatched.
+	text := redactSecrets(string(data))
+	if len(text) > forensicsDataMaxLen {
+		text = text[:forensicsDataMaxLen]
+	}
+	message.Time = time.Now()
+	message.Data = text
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.messages.add(message)
+}
+
+func (r *forensicsRecorder) recordLog(stream string, log WorkerLog) {
+	if r == nil {
+		return
+	}
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.logs.add(ForensicsLog{
+		Time:    time.Now(),
+		Stream:  stream,
+		Level:   log.Level,
+		Source:  log.Source,
+		Message: log.Message,
+		Fields:  log.Fields,
+	})
+}
+
+func (r *forensicsRecorder) recordResourceUsage(usage WorkerResourceUsage) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	r.usages.add(ResourceUsageSample{Time: time.Now(), Usage: usage})
+}
+
+// snapshot returns what was recorded so far.
+func (r *forensicsRecorder) snapshot() (forensics *WorkerForensics) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	forensics = &WorkerForensics{
+		Time:            time.Now(),
+		StartedAt:       r.startedAt,
+		Bin:             r.bin,
+		Args:            r.args,
+		Settings:        r.settings,
+		Messages:        []ForensicsMessage{},
+		PendingRequests: []ForensicsRequest{},
+		Logs:            []ForensicsLog{},
+		ResourceUsage:   []ResourceUsageSample{},
+	}
+	r.messages.each(func(item interface{}) {
+		forensics.Messages = append(forensics.Messages, item.(ForensicsMessage))
+	})
+	r.logs.each(func(item interface{}) {
+		forensics.Logs = append(forensics.Logs, item.(ForensicsLog))
+	})
+	r.usages.each(func(item interface{}) {
+		forensics.ResourceUsage = append(forensics.ResourceUsage, item.(ResourceUsageSample))
+	})
+
+	return
+}
+
+/**
+ * Forensics of the Worker: what was recorded until the death of its worker
+ * process if it died, else until now.
+ */
+func (w *Worker) Forensics() *WorkerForensics {
+	w.forensics.locker.Lock()
+	captured := w.forensics.captured
+	w.forensics.locker.Unlock()
+
+	if captured != nil {
+		return captured
+	}
+
+	return w.snapshotForensics()
+}
+
+func (w *Worker) snapshotForensics() *WorkerForensics {
+	forensics := w.forensics.snapshot()
+	forensics.Pid = w.pid
+	forensics.PendingRequests = append(forensics.PendingRequests, w.channel.pendingRequests(forensics.Time)...)
+	forensics.IPCStats = w.IPCStats()
+
+	return forensics
+}
+
+// captureForensics captures the forensics of the death of the worker process,
+// writing them to the forensics directory if any. Called before emitting
+// "died", so that its listeners get them with Forensics().
+func (w *Worker) captureForensics(err error, code int, signal string) {
+	forensics := w.snapshotForensics()
+	forensics.ExitCode = code
+	forensics.Signal = signal
+
+	if err != nil {
+		forensics.Error = err.Error()
+	}
+
+	if dir := w.forensics.options.Dir; len(dir) > 0 {
+		if path, err := forensics.writeTo(dir); err != nil {
+			w.logger.Error("writing worker forensics failed: %s", err)
+		} else {
+			forensics.Path = path
+			w.logger.Warn("worker forensics written to %s", path)
+		}
+	}
+
+	w.forensics.locker.Lock()
+	w.forensics.captured = forensics
+	w.forensics.locker.Unlock()
+}
+
+func (f *WorkerForensics) writeTo(dir string) (path string, err error) {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	path = filepath.Join(dir, fmt.Sprintf("mediasoup-worker-%d-%s.json", f.Pid, f.Time.Format("20060102T150405.000")))
+	err = ioutil.WriteFile(path, data, 0600)
+
+	return
+}
+
+// pollResourceUsage samples the resource usage of the worker process until
+// the Channel is closed.
+func (w *Worker) pollResourceUsage(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var usage WorkerResourceUsage
+
+			if err := w.channel.Request("worker.getResourceUsage", nil).Unmarshal(&usage); err == nil {
+				w.forensics.recordResourceUsage(usage)
+			}
+		case <-w.channel.closeCh:
+			return
+		}
+	}
+}
+
+// pendingRequests returns the requests waiting for their response, by id.
+func (c *Channel) pendingRequests(now time.Time) (requests []ForensicsRequest) {
+	c.sents.Range(func(key, value interface{}) bool {
+		sent := value.(sentInfo)
+		requests = append(requests, ForensicsRequest{
+			Id:      sent.id,
+			Method:  sent.method,
+			Elapsed: now.Sub(sent.start),
+		})
+		return true
+	})
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Id < requests[j].Id
+	})
+
+	return
+}
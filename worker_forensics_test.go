@@ -0,0 +1,149 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerForensics(t *testing.T) {
+	mock, err := NewMockWorker(WithLogLevel(WorkerLogLevel_Warn), WithForensics(ForensicsOptions{MaxMessages: 3}))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.Respond("worker.dump", H{"pid": 1})
+	mock.HandleDefault(func(MockRequest) (interface{}, error) {
+		return nil, NewInvalidStateError("not now")
+	})
+
+	mock.Dump()
+	mock.UpdateSettings(WorkerUpdateableSettings{LogLevel: WorkerLogLevel_Debug})
+
+	notified := make(chan struct{})
+	mock.channel.On("producer1", func(event string, data []byte) { close(notified) })
+	require.NoError(t, mock.Notify("producer1", "score", []ProducerScore{{Score: 3}}))
+	<-notified
+
+	require.NoError(t, mock.write(mock.channelConn, []byte("ERTC::DtlsTransport::ProcessHandshake() | handshake failed")))
+
+	diedForensics := make(chan *WorkerForensics, 1)
+	mock.On("died", func(err error) { diedForensics <- mock.Forensics() })
+
+	assert.Eventually(t, func() bool { return len(mock.Forensics().Logs) == 1 }, time.Second, 10*time.Millisecond)
+
+	mock.Die(errors.New("crash"))
+
+	forensics := <-diedForensics
+
+	assert.Equal(t, mock.Pid(), forensics.Pid)
+	assert.Equal(t, "crash", forensics.Error)
+	assert.Contains(t, forensics.Args, "--logLevel=warn")
+	assert.Equal(t, WorkerLogLevel_Warn, forensics.Settings.LogLevel)
+
+	// The oldest messages are dropped.
+	require.Len(t, forensics.Messages, 3)
+	assert.Equal(t, "request", forensics.Messages[0].Type)
+	assert.Equal(t, "worker.updateSettings", forensics.Messages[0].Method)
+	assert.Contains(t, forensics.Messages[0].Data, `"data":{"logLevel":"debug"}`)
+	assert.Equal(t, "response", forensics.Messages[1].Type)
+	assert.Equal(t, forensics.Messages[0].Id, forensics.Messages[1].Id)
+	assert.Contains(t, forensics.Messages[1].Error, "not now")
+	assert.Equal(t, ForensicsMessage{
+		Time:     forensics.Messages[2].Time,
+		Type:     "notification",
+		Method:   "score",
+		TargetId: "producer1",
+		Data:     forensics.Messages[2].Data,
+	}, forensics.Messages[2])
+
+	require.Len(t, forensics.Logs, 1)
+	assert.Equal(t, "channel", forensics.Logs[0].Stream)
+	assert.Equal(t, WorkerLogLevel_Error, forensics.Logs[0].Level)
+	assert.Equal(t, "handshake failed", forensics.Logs[0].Message)
+
+	// The forensics of the death are kept.
+	assert.Same(t, forensics, mock.Forensics())
+}
+
+func TestWorkerForensics_PendingRequests(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	mock.Handle("worker.dump", func(MockRequest) (interface{}, error) {
+		<-block
+		return nil, nil
+	})
+
+	go mock.Dump()
+
+	assert.Eventually(t, func() bool {
+		requests := mock.Forensics().PendingRequests
+		return len(requests) == 1 && requests[0].Method == "worker.dump"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWorkerForensics_RedactSecrets(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.Respond("transport.dump", H{"iceParameters": H{"usernameFragment": "u", "password": "p4ss"}})
+
+	mock.channel.Request("transport.dump", nil)
+
+	messages := mock.Forensics().Messages
+	require.Len(t, messages, 2)
+	assert.NotContains(t, messages[1].Data, "p4ss")
+}
+
+func TestWorkerForensics_ResourceUsage(t *testing.T) {
+	mock, err := NewMockWorker(WithForensics(ForensicsOptions{
+		ResourceUsageInterval:   10 * time.Millisecond,
+		MaxResourceUsageSamples: 2,
+	}))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.Respond("worker.getResourceUsage", H{"ru_maxrss": 1024})
+
+	assert.Eventually(t, func() bool {
+		return len(mock.Forensics().ResourceUsage) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.EqualValues(t, 1024, mock.Forensics().ResourceUsage[1].Usage.RU_Maxrss)
+}
+
+func TestWorkerForensics_Dir(t *testing.T) {
+	dir := t.TempDir()
+
+	mock, err := NewMockWorker(WithForensics(ForensicsOptions{Dir: dir}))
+	require.NoError(t, err)
+
+	mock.Dump()
+	mock.Die(errors.New("crash"))
+
+	forensics := mock.Forensics()
+	require.NotEmpty(t, forensics.Path)
+
+	info, err := os.Stat(forensics.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := ioutil.ReadFile(forensics.Path)
+	require.NoError(t, err)
+
+	var written WorkerForensics
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, "crash", written.Error)
+	assert.Equal(t, forensics.Messages[0].Method, written.Messages[0].Method)
+}
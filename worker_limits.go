@@ -0,0 +1,187 @@
+package mediasoup
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+ * Resource limits of the worker subprocess. Linux only.
+ */
+type WorkerResourceLimits struct {
+	/**
+	 * Maximum size of the virtual memory (RLIMIT_AS), in bytes. Unchanged if 0.
+	 */
+	MemoryBytes uint64
+
+	/**
+	 * Maximum number of open files (RLIMIT_NOFILE). Unchanged if 0.
+	 */
+	OpenFiles uint64
+
+	/**
+	 * Directory of a cgroup v2 (e.g. "/sys/fs/cgroup/mediasoup") the worker
+	 * subprocess is moved into, its memory.max, memory.high and cpu.max being
+	 * set by the operator.
+	 */
+	Cgroup string
+
+	/**
+	 * Interval at which the memory.events and cpu.stat of the cgroup are read
+	 * to emit "limited" when the worker subprocess is throttled. Not read if 0,
+	 * "limited" being only emitted when it is OOM-killed.
+	 */
+	CheckInterval time.Duration
+}
+
+func (l WorkerResourceLimits) isZero() bool {
+	return l.MemoryBytes == 0 && l.OpenFiles == 0 && len(l.Cgroup) == 0
+}
+
+type WorkerLimitReason string
+
+const (
+	// Killed by the OOM killer of the cgroup (memory.max exceeded).
+	WorkerLimitReason_OOMKill WorkerLimitReason = "oom_kill"
+	// Memory allocations failed as memory.max was reached.
+	WorkerLimitReason_MemoryMax WorkerLimitReason = "memory_max"
+	// Throttled as memory.high was exceeded.
+	WorkerLimitReason_MemoryHigh WorkerLimitReason = "memory_high"
+	// Throttled as the cpu.max quota was exceeded.
+	WorkerLimitReason_CPUThrottled WorkerLimitReason = "cpu_throttled"
+)
+
+/**
+ * Event emitted with "limited" by the Worker when its worker subprocess hit a
+ * limit of its cgroup.
+ */
+type WorkerLimitEvent struct {
+	Reason WorkerLimitReason `json:"reason"`
+
+	/**
+	 * Number of times the limit was hit since the previous check.
+	 */
+	Count uint64 `json:"count"`
+}
+
+// Counters of the cgroup files giving each WorkerLimitReason.
+var cgroupLimitCounters = []struct {
+	file    string
+	key     string
+	reason  WorkerLimitReason
+	onDeath bool
+}{
+	{"memory.events", "oom_kill", WorkerLimitReason_OOMKill, true},
+	{"memory.events", "max", WorkerLimitReason_MemoryMax, false},
+	{"memory.events", "high", WorkerLimitReason_MemoryHigh, false},
+	{"cpu.stat", "nr_throttled", WorkerLimitReason_CPUThrottled, false},
+}
+
+// cgroupLimits reads the limit counters of a cgroup, giving the events of the
+// ones increased since the previous read.
+type cgroupLimits struct {
+	dir    string
+	values map[WorkerLimitReason]uint64
+}
+
+func newCgroupLimits(dir string) *cgroupLimits {
+	limits := &cgroupLimits{dir: dir}
+	limits.read(false)
+
+	return limits
+}
+
+// read returns the events of the counters increased since the previous read,
+// only the ones telling the worker subprocess was killed if onDeath.
+func (l *cgroupLimits) read(onDeath bool) (events []WorkerLimitEvent) {
+	values := make(map[WorkerLimitReason]uint64)
+	files := make(map[string]map[string]uint64)
+
+	for _, counter := range cgroupLimitCounters {
+		if _, ok := files[counter.file]; !ok {
+			files[counter.file] = readCgroupKeyedFile(filepath.Join(l.dir, counter.file))
+		}
+		value, ok := files[counter.file][counter.key]
+		if !ok {
+			continue
+		}
+		values[counter.reason] = value
+
+		if l.values == nil || (onDeath && !counter.onDeath) {
+			continue
+		}
+		if last := l.values[counter.reason]; value > last {
+			events = append(events, WorkerLimitEvent{Reason: counter.reason, Count: value - last})
+		}
+	}
+	l.values = values
+
+	return
+}
+
+// readCgroupKeyedFile parses a flat keyed cgroup file ("key value" lines),
+// empty if unreadable.
+func readCgroupKeyedFile(path string) map[string]uint64 {
+	values := make(map[string]uint64)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+
+	return values
+}
+
+/**
+ * The process of the worker subprocess, for external supervision tooling, nil
+ * for a MockWorker. Signaling or waiting for it bypasses the Worker, use
+ * Close() to stop it.
+ */
+func (w *Worker) Process() *os.Process {
+	return w.process
+}
+
+// watchLimits checks the cgroup limits of the worker subprocess at the given
+// interval, until it exited.
+func (w *Worker) watchLimits(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.emitLimits(false)
+		case <-w.exitCh:
+			return
+		}
+	}
+}
+
+// emitLimits emits "limited" for the cgroup limits hit since the previous
+// check, only the OOM kill once the worker subprocess was killed.
+func (w *Worker) emitLimits(onDeath bool) {
+	w.limitsLocker.Lock()
+	events := w.limits.read(onDeath)
+	w.limitsLocker.Unlock()
+
+	for _, event := range events {
+		w.logger.Warn("worker process limited [pid:%d, reason:%s, count:%d]", w.pid, event.Reason, event.Count)
+		w.SafeEmit("limited", event)
+	}
+}
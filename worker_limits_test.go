@@ -0,0 +1,50 @@
+package mediasoup
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupLimits(t *testing.T) {
+	dir := t.TempDir()
+	write := func(file, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+
+	write("memory.events", "low 0\nhigh 2\nmax 0\noom 0\noom_kill 0\n")
+	write("cpu.stat", "usage_usec 1000\nnr_periods 10\nnr_throttled 1\nthrottled_usec 50\n")
+
+	limits := newCgroupLimits(dir)
+	assert.Empty(t, limits.read(false))
+
+	write("memory.events", "low 0\nhigh 5\nmax 0\noom 1\noom_kill 1\n")
+	write("cpu.stat", "usage_usec 2000\nnr_periods 20\nnr_throttled 4\nthrottled_usec 80\n")
+
+	assert.ElementsMatch(t, []WorkerLimitEvent{
+		{Reason: WorkerLimitReason_OOMKill, Count: 1},
+		{Reason: WorkerLimitReason_MemoryHigh, Count: 3},
+		{Reason: WorkerLimitReason_CPUThrottled, Count: 3},
+	}, limits.read(false))
+
+	write("memory.events", "low 0\nhigh 6\nmax 0\noom 2\noom_kill 2\n")
+
+	assert.Equal(t, []WorkerLimitEvent{{Reason: WorkerLimitReason_OOMKill, Count: 1}}, limits.read(true))
+}
+
+func TestWorker_Process(t *testing.T) {
+	worker := CreateTestWorker()
+	defer worker.Close()
+
+	require.NotNil(t, worker.Process())
+	assert.Equal(t, worker.Pid(), worker.Process().Pid)
+
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	assert.Nil(t, mock.Process())
+}
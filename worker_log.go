@@ -0,0 +1,154 @@
+package mediasoup
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/**
+ * A log line written by a worker subprocess.
+ */
+type WorkerLog struct {
+	/**
+	 * Worker process identifier (PID).
+	 */
+	Pid int
+
+	/**
+	 * Level of the line: "debug", "warn" or "error".
+	 */
+	Level WorkerLogLevel
+
+	/**
+	 * Tag of the worker component which wrote the line, guessed from Source.
+	 * Empty if unknown.
+	 */
+	Tag WorkerLogTag
+
+	/**
+	 * C++ method which wrote the line (e.g.
+	 * "RTC::DtlsTransport::ProcessHandshake()"), if any.
+	 */
+	Source string
+
+	/**
+	 * Log message.
+	 */
+	Message string
+
+	/**
+	 * Fields of the message, given by the worker as "[key:value, ...]" (e.g.
+	 * "producerId" in "new producer [producerId:xxx]"). Nil if none.
+	 */
+	Fields map[string]string
+}
+
+// Matches the "[key:value, ...]" groups of a worker log message.
+var workerLogFieldsRegexp = regexp.MustCompile(`\[([A-Za-z][\w.]*:[^\[\],]*(?:, ?[A-Za-z][\w.]*:[^\[\],]*)*)\]`)
+
+// Worker classes (prefixes) mapped to the tag of their logs.
+var workerLogTagsBySource = []struct {
+	prefix string
+	tag    WorkerLogTag
+}{
+	{"RTC::IceServer", WorkerLogTag_ICE},
+	{"RTC::IceCandidate", WorkerLogTag_ICE},
+	{"RTC::StunPacket", WorkerLogTag_ICE},
+	{"RTC::DtlsTransport", WorkerLogTag_DTLS},
+	{"RTC::SrtpSession", WorkerLogTag_SRTP},
+	{"RTC::RTCP::", WorkerLogTag_RTCP},
+	{"RTC::RtpStream", WorkerLogTag_RTP},
+	{"RTC::RtpPacket", WorkerLogTag_RTP},
+	{"RTC::NackGenerator", WorkerLogTag_RTX},
+	{"RTC::TransportCongestionControl", WorkerLogTag_BWE},
+	{"RTC::SenderBandwidthEstimator", WorkerLogTag_BWE},
+	{"RTC::SimulcastConsumer", WorkerLogTag_Simulcast},
+	{"RTC::SvcConsumer", WorkerLogTag_SVC},
+	{"RTC::SctpAssociation", WorkerLogTag_SCTP},
+	{"RTC::DataConsumer", WorkerLogTag_Message},
+	{"RTC::DataProducer", WorkerLogTag_Message},
+}
+
+/**
+ * Parse a log line of the worker with the given level. Lines are formatted as
+ * "Class::Method() | message", "(ABORT) " lines are errors.
+ */
+func parseWorkerLog(pid int, level WorkerLogLevel, line string) (log WorkerLog) {
+	log.Pid = pid
+	log.Level = level
+
+	if strings.HasPrefix(line, "(ABORT) ") {
+		log.Level = WorkerLogLevel_Error
+		line = line[len("(ABORT) "):]
+	}
+
+	if idx := strings.Index(line, " | "); idx > 0 && strings.HasSuffix(line[:idx], ")") {
+		log.Source = line[:idx]
+		line = line[idx+len(" | "):]
+	}
+	log.Message = line
+	log.Fields = parseWorkerLogFields(line)
+
+	for _, item := range workerLogTagsBySource {
+		if strings.HasPrefix(log.Source, item.prefix) {
+			log.Tag = item.tag
+			break
+		}
+	}
+
+	return
+}
+
+// parseWorkerLogFields returns the fields of the "[key:value, ...]" groups of
+// the message, nil if none.
+func parseWorkerLogFields(message string) (fields map[string]string) {
+	for _, match := range workerLogFieldsRegexp.FindAllStringSubmatch(message, -1) {
+		for _, pair := range strings.Split(match[1], ",") {
+			kv := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[kv[0]] = strings.TrimSpace(kv[1])
+		}
+	}
+	return
+}
+
+/**
+ * Write the log line into the logger with the same level, its source, tag and
+ * fields as key/value pairs.
+ */
+func (log WorkerLog) writeTo(logger Logger) {
+	if len(log.Source) > 0 {
+		logger = logger.With("source", log.Source)
+	}
+	if len(log.Tag) > 0 {
+		logger = logger.With("tag", log.Tag)
+	}
+	if len(log.Fields) > 0 {
+		keys := make([]string, 0, len(log.Fields))
+
+		for key := range log.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		keyvals := make([]interface{}, 0, 2*len(keys))
+
+		for _, key := range keys {
+			keyvals = append(keyvals, key, log.Fields[key])
+		}
+		logger = logger.With(keyvals...)
+	}
+
+	switch log.Level {
+	case WorkerLogLevel_Error:
+		logger.Error("%s", log.Message)
+	case WorkerLogLevel_Warn:
+		logger.Warn("%s", log.Message)
+	default:
+		logger.Debug("%s", log.Message)
+	}
+}
@@ -0,0 +1,59 @@
+package mediasoup
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkerLog(t *testing.T) {
+	log := parseWorkerLog(10, WorkerLogLevel_Warn,
+		"RTC::DtlsTransport::ProcessHandshake() | OpenSSL SSL_do_handshake() failed")
+
+	assert.Equal(t, WorkerLog{
+		Pid:     10,
+		Level:   WorkerLogLevel_Warn,
+		Tag:     WorkerLogTag_DTLS,
+		Source:  "RTC::DtlsTransport::ProcessHandshake()",
+		Message: "OpenSSL SSL_do_handshake() failed",
+	}, log)
+
+	log = parseWorkerLog(10, WorkerLogLevel_Debug, "(ABORT) Worker::Worker() | failed | 1")
+
+	assert.Equal(t, WorkerLogLevel_Error, log.Level)
+	assert.Equal(t, "Worker::Worker()", log.Source)
+	assert.Equal(t, "failed | 1", log.Message)
+	assert.Empty(t, log.Tag)
+
+	log = parseWorkerLog(10, WorkerLogLevel_Error, "a | b")
+
+	assert.Empty(t, log.Source)
+	assert.Equal(t, "a | b", log.Message)
+	assert.Nil(t, log.Fields)
+
+	log = parseWorkerLog(10, WorkerLogLevel_Debug,
+		"RTC::Router::HandleRequest() | Producer created [producerId:p1, kind:audio] [ssrc:1234] [not a field]")
+
+	assert.Equal(t, "Producer created [producerId:p1, kind:audio] [ssrc:1234] [not a field]", log.Message)
+	assert.Equal(t, map[string]string{"producerId": "p1", "kind": "audio", "ssrc": "1234"}, log.Fields)
+}
+
+func TestWorkerLogWriteTo(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	parseWorkerLog(10, WorkerLogLevel_Warn, "RTC::IceServer::ProcessStunPacket() | unknown user [username:abc]").
+		writeTo(NewZerologLogger(zerolog.New(buf)))
+
+	var line map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "warn", line["level"])
+	assert.Equal(t, "ice", line["tag"])
+	assert.Equal(t, "RTC::IceServer::ProcessStunPacket()", line["source"])
+	assert.Equal(t, "unknown user [username:abc]", line["message"])
+	assert.Equal(t, "abc", line["username"])
+}
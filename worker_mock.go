@@ -0,0 +1,360 @@
+package mediasoup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jiyeyuran/mediasoup-go/netstring"
+)
+
+/**
+ * Request received by a MockWorker, through the Channel or the PayloadChannel.
+ */
+type MockRequest struct {
+	// Request method (e.g. "router.createWebRtcTransport") or event of a
+	// PayloadChannel notification (e.g. "producer.send").
+	Method string
+	// Ids of the targeted entities (e.g. "routerId", "transportId").
+	Internal H
+	Data     json.RawMessage
+	// Payload of a PayloadChannel request or notification.
+	Payload []byte
+	// Whether the request is a PayloadChannel notification, not answered.
+	Notification bool
+}
+
+/**
+ * Unmarshal the data of the request.
+ */
+func (r MockRequest) Unmarshal(v interface{}) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+/**
+ * MockHandler answers a request with the data of the response (marshaled to
+ * JSON), or with an error. The errors created with NewTypeError,
+ * NewUnsupportedError and NewInvalidStateError are returned as such to the
+ * caller, the ones whose message contains "not found" as NotFoundError.
+ */
+type MockHandler func(request MockRequest) (data interface{}, err error)
+
+// Pids of the mock workers, negative as they have no process.
+var mockWorkerPid int64
+
+/**
+ * MockWorker is a Worker without mediasoup-worker process, the requests of its
+ * Channel and PayloadChannel being answered by scripted handlers, so that
+ * applications can unit-test their media logic:
+ *
+ *	mock, _ := mediasoup.NewMockWorker()
+ *	mock.Handle("transport.produce", func(mediasoup.MockRequest) (interface{}, error) {
+ *		return mediasoup.H{"type": "simple"}, nil
+ *	})
+ *	app := NewApp(mock.Worker)
+ *	...
+ *	mock.Notify(producerId, "score", []mediasoup.ProducerScore{{Score: 3}})
+ *
+ * The requests without handler are accepted with an empty response.
+ */
+type MockWorker struct {
+	*Worker
+	locker         sync.Mutex
+	handlers       map[string]MockHandler
+	defaultHandler MockHandler
+	requests       []MockRequest
+	// Mock ends of the sockets written to the Worker.
+	channelConn        net.Conn
+	payloadChannelConn net.Conn
+	writeLocker        sync.Mutex
+}
+
+/**
+ * Create a MockWorker, with the options of NewWorker.
+ */
+func NewMockWorker(options ...Option) (mock *MockWorker, err error) {
+	settings := newWorkerSettings(options...)
+	pid := int(atomic.AddInt64(&mockWorkerPid, -1))
+	loggerContext := loggerContext{}.with(settings.Logger).withLabels(settings.Labels).with(nil, "workerPid", pid)
+
+	// Socket ends of the Worker and of the mock.
+	producerSocket, mockConsumerSocket := net.Pipe()
+	mockProducerSocket, consumerSocket := net.Pipe()
+	payloadProducerSocket, mockPayloadConsumerSocket := net.Pipe()
+	mockPayloadProducerSocket, payloadConsumerSocket := net.Pipe()
+
+	channel := newChannel(producerSocket, consumerSocket, pid, jsonChannelCodec{}, loggerContext)
+	payloadChannel := newPayloadChannel(payloadProducerSocket, payloadConsumerSocket, pid, jsonChannelCodec{}, loggerContext)
+
+	mock = &MockWorker{
+		Worker:             newWorker(pid, channel, payloadChannel, settings, loggerContext),
+		handlers:           make(map[string]MockHandler),
+		channelConn:        mockProducerSocket,
+		payloadChannelConn: mockPayloadProducerSocket,
+	}
+	atomic.StoreUint32(&mock.spawnDone, 1)
+
+	go mock.serve(mockConsumerSocket, mockProducerSocket, false)
+	go mock.serve(mockPayloadConsumerSocket, mockPayloadProducerSocket, true)
+
+	channel.Start()
+
+	mock.logger.Debug("mock worker running [pid:%d]", pid)
+
+	return
+}
+
+/**
+ * Answer the requests of the method with the handler.
+ */
+func (m *MockWorker) Handle(method string, handler MockHandler) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	m.handlers[method] = handler
+}
+
+/**
+ * Answer the requests of the method with the given data.
+ */
+func (m *MockWorker) Respond(method string, data interface{}) {
+	m.Handle(method, func(MockRequest) (interface{}, error) {
+		return data, nil
+	})
+}
+
+/**
+ * Answer the requests without handler with the given one, e.g. to reject
+ * them. The requests without handler are accepted by default.
+ */
+func (m *MockWorker) HandleDefault(handler MockHandler) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	m.defaultHandler = handler
+}
+
+/**
+ * Requests received so far, in order, only the ones of the given methods if
+ * any.
+ */
+func (m *MockWorker) Requests(methods ...string) (requests []MockRequest) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	for _, request := range m.requests {
+		if len(methods) == 0 || stringsContain(methods, request.Method) {
+			requests = append(requests, request)
+		}
+	}
+
+	return
+}
+
+func stringsContain(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Send a notification of the worker through the Channel, as emitted by the
+ * entity of the given id (e.g. a "score" of a Producer).
+ */
+func (m *MockWorker) Notify(targetId, event string, data interface{}) error {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message, _ := json.Marshal(H{
+		"targetId": targetId,
+		"event":    event,
+		"data":     json.RawMessage(rawData),
+	})
+
+	return m.write(m.channelConn, message)
+}
+
+/**
+ * Send a notification of the worker with a payload through the
+ * PayloadChannel (e.g. a "message" of a DataConsumer).
+ */
+func (m *MockWorker) NotifyPayload(targetId, event string, data interface{}, payload []byte) error {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message, _ := json.Marshal(H{
+		"targetId": targetId,
+		"event":    event,
+		"data":     json.RawMessage(rawData),
+	})
+
+	return m.write(m.payloadChannelConn, message, payload)
+}
+
+/**
+ * Simulate the death of the worker process: the forensics are captured, "died"
+ * is emitted with the error and the Worker is closed.
+ */
+func (m *MockWorker) Die(err error) {
+	if m.Closed() {
+		return
+	}
+
+	m.logger.Error("worker process died unexpectedly [pid:%d]: %v", m.pid, err)
+	m.channel.workerExited(fmt.Errorf("%w: %s", ErrWorkerDied, err))
+	m.payloadChannel.workerExited(fmt.Errorf("%w: %s", ErrWorkerDied, err))
+	m.captureForensics(err, 0, "")
+	m.died(err)
+	m.SafeEmit("died", err)
+	m.Close()
+}
+
+func (m *MockWorker) write(conn net.Conn, messages ...[]byte) (err error) {
+	m.writeLocker.Lock()
+	defer m.writeLocker.Unlock()
+
+	for _, message := range messages {
+		if _, err = conn.Write(netstring.Encode(message)); err != nil {
+			return NewInvalidStateError("Channel closed")
+		}
+	}
+
+	return
+}
+
+// serve answers the requests read from the socket until it is closed, a
+// request or notification of the PayloadChannel being followed by its payload.
+func (m *MockWorker) serve(reader, writer net.Conn, withPayload bool) {
+	decoder := netstring.NewDecoder()
+
+	go func() {
+		buf := make([]byte, 65536)
+
+		for {
+			n, err := reader.Read(buf)
+			if err != nil {
+				reader.Close()
+				writer.Close()
+				return
+			}
+			decoder.Feed(buf[:n])
+		}
+	}()
+
+	for {
+		var message, payload []byte
+
+		select {
+		case message = <-decoder.Result():
+		case <-m.channelClosed(withPayload):
+			return
+		}
+
+		if withPayload {
+			select {
+			case payload = <-decoder.Result():
+			case <-m.channelClosed(withPayload):
+				return
+			}
+		}
+
+		m.handle(writer, message, payload)
+	}
+}
+
+func (m *MockWorker) channelClosed(withPayload bool) <-chan struct{} {
+	if withPayload {
+		return m.payloadChannel.closeCh
+	}
+	return m.channel.closeCh
+}
+
+func (m *MockWorker) handle(writer net.Conn, message, payload []byte) {
+	var msg struct {
+		Id       int64           `json:"id"`
+		Method   string          `json:"method"`
+		Event    string          `json:"event"`
+		Internal H               `json:"internal"`
+		Data     json.RawMessage `json:"data"`
+	}
+	json.Unmarshal(message, &msg)
+
+	request := MockRequest{
+		Method:       msg.Method,
+		Internal:     msg.Internal,
+		Data:         msg.Data,
+		Payload:      payload,
+		Notification: msg.Id == 0,
+	}
+
+	if request.Notification {
+		request.Method = msg.Event
+	}
+
+	m.locker.Lock()
+	m.requests = append(m.requests, request)
+	handler, ok := m.handlers[request.Method]
+	if !ok {
+		handler = m.defaultHandler
+	}
+	m.locker.Unlock()
+
+	if request.Notification {
+		if handler != nil {
+			handler(request)
+		}
+		return
+	}
+
+	var data interface{}
+	var err error
+
+	if handler != nil {
+		data, err = handler(request)
+	}
+
+	response := H{"id": msg.Id}
+
+	if err != nil {
+		response["error"], response["reason"] = mockError(err)
+	} else {
+		response["accepted"] = true
+
+		if data != nil {
+			response["data"] = data
+		}
+	}
+
+	rawResponse, err := json.Marshal(response)
+	if err != nil {
+		rawResponse, _ = json.Marshal(H{"id": msg.Id, "error": "Error", "reason": err.Error()})
+	}
+
+	m.write(writer, rawResponse)
+}
+
+// mockError returns the name and reason of the error of a worker response.
+func mockError(err error) (name, reason string) {
+	switch e := err.(type) {
+	case TypeError:
+		return "TypeError", e.Error()
+	case UnsupportedError:
+		return e.name, e.message
+	case InvalidStateError:
+		return e.name, e.message
+	case NotFoundError:
+		return "Error", e.message
+	default:
+		return "Error", err.Error()
+	}
+}
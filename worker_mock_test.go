@@ -0,0 +1,383 @@
+package mediasoup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockWorker(t *testing.T) {
+	mock, err := NewMockWorker(func(settings *WorkerSettings) {
+		settings.AppData = H{"foo": "bar"}
+	})
+	require.NoError(t, err)
+	defer mock.Close()
+
+	assert.Less(t, mock.Pid(), 0)
+	assert.Equal(t, H{"foo": "bar"}, mock.AppData())
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+	assert.Len(t, mock.Routers(), 1)
+
+	requests := mock.Requests("worker.createRouter")
+	require.Len(t, requests, 1)
+	assert.Equal(t, router.Id(), requests[0].Internal["routerId"])
+
+	mock.Respond("worker.getResourceUsage", H{"ru_utime": 42})
+
+	usage, err := mock.GetResourceUsage()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42, usage.RU_Utime)
+
+	mock.Handle("worker.updateSettings", func(request MockRequest) (interface{}, error) {
+		var settings WorkerUpdateableSettings
+		request.Unmarshal(&settings)
+
+		return nil, NewTypeError("invalid log level %q", settings.LogLevel)
+	})
+
+	err = mock.UpdateSettings(WorkerUpdateableSettings{LogLevel: "foo"})
+	assert.IsType(t, TypeError{}, err)
+	assert.Contains(t, err.Error(), `invalid log level "foo"`)
+
+	mock.HandleDefault(func(MockRequest) (interface{}, error) {
+		return nil, NewInvalidStateError("not now")
+	})
+
+	_, err = mock.Dump()
+	assert.IsType(t, InvalidStateError{}, err)
+}
+
+func TestMockWorker_Notify(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	transport, err := router.CreateDirectTransport()
+	require.NoError(t, err)
+
+	mock.Respond("transport.produce", H{"type": "simple"})
+
+	producer, err := transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ProducerType_Simple, producer.Type())
+
+	scores := make(chan []ProducerScore, 1)
+	producer.On("score", func(score []ProducerScore) { scores <- score })
+
+	assert.NoError(t, mock.Notify(producer.Id(), "score", []ProducerScore{{Ssrc: 1234, Score: 7}}))
+
+	select {
+	case score := <-scores:
+		assert.Equal(t, []ProducerScore{{Ssrc: 1234, Score: 7}}, score)
+	case <-time.After(time.Second):
+		t.Fatal("score not emitted")
+	}
+
+	// PayloadChannel notification.
+	assert.NoError(t, producer.Send([]byte{1, 2, 3}))
+
+	assert.Eventually(t, func() bool {
+		return len(mock.Requests("producer.send")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	request := mock.Requests("producer.send")[0]
+	assert.True(t, request.Notification)
+	assert.Equal(t, []byte{1, 2, 3}, request.Payload)
+	assert.Equal(t, producer.Id(), request.Internal["producerId"])
+}
+
+func TestMockWorker_Die(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	died := make(chan error, 1)
+	mock.On("died", func(err error) { died <- err })
+
+	mock.Die(errors.New("crash"))
+
+	select {
+	case err := <-died:
+		assert.EqualError(t, err, "crash")
+	case <-time.After(time.Second):
+		t.Fatal("died not emitted")
+	}
+
+	assert.True(t, mock.Closed())
+
+	select {
+	case err := <-mock.Died():
+		assert.EqualError(t, err, "crash")
+	default:
+		t.Fatal("death not delivered")
+	}
+	<-mock.Done()
+
+	_, err = mock.CreateRouter(poolRouterOptions)
+	assert.Error(t, err)
+}
+
+func TestMockWorker_Die_PendingRequests(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	block := make(chan struct{})
+	defer close(block)
+
+	mock.Handle("worker.dump", func(MockRequest) (interface{}, error) {
+		<-block
+		return H{}, nil
+	})
+
+	dumped := make(chan error, 1)
+	go func() {
+		_, err := mock.Dump()
+		dumped <- err
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(mock.Requests("worker.dump")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mock.Die(errors.New("crash"))
+
+	select {
+	case err := <-dumped:
+		assert.True(t, errors.Is(err, ErrWorkerDied))
+		assert.Contains(t, err.Error(), "crash")
+	case <-time.After(time.Second):
+		t.Fatal("pending request not failed")
+	}
+}
+
+func TestMockWorker_Done(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	select {
+	case <-mock.Done():
+		t.Fatal("done before close")
+	default:
+	}
+
+	mock.Close()
+
+	select {
+	case <-mock.Done():
+	default:
+		t.Fatal("not done after close")
+	}
+
+	select {
+	case err, ok := <-mock.Died():
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	default:
+		t.Fatal("Died() not closed after close")
+	}
+}
+
+func TestMockWorker_Context(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	block := make(chan struct{})
+
+	mock.Handle("worker.createRouter", func(MockRequest) (interface{}, error) {
+		<-block
+		return nil, nil
+	})
+
+	routerOptions := RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}}
+
+	// Invalid codecs are rejected before creating the router in the worker.
+	_, err = mock.CreateRouterContext(context.Background(), RouterOptions{})
+	assert.IsType(t, TypeError{}, err)
+	assert.Empty(t, mock.Requests("worker.createRouter"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = mock.CreateRouterContext(ctx, routerOptions)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Empty(t, mock.Routers())
+
+	close(block)
+
+	// The router the worker may have created anyway is closed.
+	assert.Eventually(t, func() bool {
+		return len(mock.Requests("router.close")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mock.Handle("worker.createRouter", nil)
+
+	router, err := mock.CreateRouterContext(context.Background(), routerOptions)
+	require.NoError(t, err)
+
+	transport, err := router.CreateDirectTransportContext(context.Background())
+	require.NoError(t, err)
+
+	mock.Respond("transport.produce", H{"type": "simple"})
+
+	producer, err := transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, producer.PauseContext(ctx))
+	assert.False(t, producer.Paused())
+	assert.Empty(t, mock.Requests("producer.pause"))
+
+	assert.NoError(t, producer.PauseContext(context.Background()))
+	assert.True(t, producer.Paused())
+}
+
+func TestMockWorker_CloseGracefully(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	transport, err := router.CreateDirectTransport()
+	require.NoError(t, err)
+
+	producer, err := transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+
+	closed := make(chan error, 1)
+	go func() { closed <- mock.CloseGracefully(context.Background()) }()
+
+	assert.Eventually(t, mock.Draining, time.Second, 10*time.Millisecond)
+
+	_, err = mock.CreateRouter(RouterOptions{})
+	assert.IsType(t, InvalidStateError{}, err)
+	_, err = router.CreateDirectTransport()
+	assert.IsType(t, InvalidStateError{}, err)
+
+	select {
+	case <-closed:
+		t.Fatal("closed with a producer left")
+	case <-time.After(2 * workerDrainInterval):
+	}
+	assert.False(t, mock.Closed())
+
+	producer.Close()
+
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("not closed")
+	}
+	assert.True(t, mock.Closed())
+	assert.Equal(t, ErrAlreadyClosed, mock.CloseGracefully(context.Background()))
+}
+
+func TestMockWorker_CloseGracefully_Timeout(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	transport, err := router.CreateDirectTransport()
+	require.NoError(t, err)
+
+	_, err = transport.Produce(ProducerOptions{
+		Kind: MediaKind_Audio,
+		RtpParameters: RtpParameters{
+			Codecs:    []*RtpCodecParameters{{MimeType: "audio/opus", PayloadType: 100, ClockRate: 48000, Channels: 2}},
+			Encodings: []RtpEncodingParameters{{Ssrc: 1234}},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, mock.CloseGracefully(ctx))
+	assert.True(t, mock.Closed())
+}
+
+func TestMockWorker_ConcurrentClose(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		mock, err := NewMockWorker()
+		require.NoError(t, err)
+
+		_, err = mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+			{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+		}})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var closeErrs uint32
+
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			mock.Die(errors.New("crash"))
+		}()
+		go func() {
+			defer wg.Done()
+			if mock.Close() == nil {
+				atomic.AddUint32(&closeErrs, 1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for !mock.Closed() {
+				mock.Routers()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			mock.CloseGracefully(context.Background())
+		}()
+		wg.Wait()
+
+		assert.True(t, mock.Closed())
+		assert.Empty(t, mock.Routers())
+		<-mock.Done()
+	}
+}
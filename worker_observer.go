@@ -0,0 +1,44 @@
+package mediasoup
+
+/**
+ * WorkerObserver registers typed listeners on the observer of a Worker,
+ * instead of listeners of string events taking interface{} arguments.
+ */
+type WorkerObserver struct {
+	observer IEventEmitter
+}
+
+/**
+ * Create the WorkerObserver of the Worker.
+ */
+func NewWorkerObserver(worker *Worker) WorkerObserver {
+	return WorkerObserver{observer: worker.Observer()}
+}
+
+/**
+ * Call fn once the Worker is closed.
+ */
+func (o WorkerObserver) OnClose(fn func()) {
+	o.observer.Once("close", fn)
+}
+
+/**
+ * Call fn with every Router created in the Worker.
+ */
+func (o WorkerObserver) OnNewRouter(fn func(router *Router)) {
+	o.observer.On("newrouter", fn)
+}
+
+/**
+ * Call fn with every request sent to the worker process.
+ */
+func (o WorkerObserver) OnRequest(fn func(info ChannelRequestInfo)) {
+	o.observer.On("request", fn)
+}
+
+/**
+ * Call fn with the resource usage samples of StartResourceMonitor().
+ */
+func (o WorkerObserver) OnResourceUsage(fn func(stats WorkerResourceUsageStats)) {
+	o.observer.On("resourceusage", fn)
+}
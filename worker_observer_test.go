@@ -0,0 +1,51 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerObserver(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	observer := NewWorkerObserver(mock.Worker)
+
+	routers := make(chan *Router, 1)
+	requests := make(chan ChannelRequestInfo, 10)
+	closed := make(chan struct{})
+
+	observer.OnNewRouter(func(router *Router) { routers <- router })
+	observer.OnRequest(func(info ChannelRequestInfo) { requests <- info })
+	observer.OnClose(func() { close(closed) })
+
+	router, err := mock.CreateRouter(RouterOptions{MediaCodecs: []*RtpCodecCapability{
+		{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+	}})
+	require.NoError(t, err)
+
+	select {
+	case r := <-routers:
+		assert.Equal(t, router, r)
+	case <-time.After(time.Second):
+		t.Fatal("newrouter not emitted")
+	}
+
+	select {
+	case info := <-requests:
+		assert.Equal(t, "worker.createRouter", info.Method)
+	case <-time.After(time.Second):
+		t.Fatal("request not emitted")
+	}
+
+	mock.Close()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("close not emitted")
+	}
+}
@@ -0,0 +1,355 @@
+package mediasoup
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * How a WorkerPool chooses the Worker of a new Router.
+ */
+type WorkerPlacement string
+
+const (
+	/**
+	 * The Worker with the fewest Routers.
+	 */
+	WorkerPlacement_RouterCount WorkerPlacement = "routerCount"
+
+	/**
+	 * The Worker whose process used the least CPU time during the last
+	 * LoadInterval, the one with the fewest Routers among equals.
+	 */
+	WorkerPlacement_ResourceUsage WorkerPlacement = "resourceUsage"
+)
+
+/**
+ * WorkerPool options.
+ */
+type WorkerPoolOptions struct {
+	/**
+	 * Number of Workers. Default runtime.NumCPU().
+	 */
+	Size int
+
+	/**
+	 * Options of the Workers.
+	 */
+	WorkerOptions []Option
+
+	/**
+	 * Placement of the Routers. Default WorkerPlacement_RouterCount.
+	 */
+	Placement WorkerPlacement
+
+	/**
+	 * Interval between the resource usage samples of the Workers, with
+	 * WorkerPlacement_ResourceUsage. Default 5 seconds.
+	 */
+	LoadInterval time.Duration
+
+	/**
+	 * Whether to spawn a new Worker when one dies, with the given supervisor
+	 * options. Else the dead Workers are removed from the pool.
+	 */
+	Respawn           bool
+	SupervisorOptions WorkerSupervisorOptions
+}
+
+/**
+ * WorkerPool spawns a set of Workers and creates the Routers in the least
+ * loaded one.
+ *
+ * @emits workerdied - (error: Error, worker: *Worker)
+ * @emits workerrestarted - (pid: int, worker: *Worker)
+ */
+type WorkerPool struct {
+	IEventEmitter
+	logger  Logger
+	options WorkerPoolOptions
+	locker  sync.Mutex
+	slots   []*workerSlot
+	closed  uint32
+	closeCh chan struct{}
+}
+
+// workerSlot is a Worker of the pool with its load.
+type workerSlot struct {
+	worker     *Worker
+	supervisor *WorkerSupervisor
+	// Routers being created in the Worker.
+	pending int
+	// CPU time used by the worker process per second of the last interval.
+	load      float64
+	lastUsage *WorkerResourceUsage
+	lastTime  time.Time
+}
+
+/**
+ * Spawn the Workers of a WorkerPool. If one of them fails to spawn, the other
+ * ones are closed and the error is returned.
+ */
+func NewWorkerPool(options WorkerPoolOptions) (*WorkerPool, error) {
+	return newWorkerPool(options, func() (*Worker, error) {
+		return NewWorker(options.WorkerOptions...)
+	})
+}
+
+func newWorkerPool(options WorkerPoolOptions, spawn func() (*Worker, error)) (pool *WorkerPool, err error) {
+	logger := NewLogger("WorkerPool")
+
+	logger.Debug("constructor()")
+
+	if options.Size <= 0 {
+		options.Size = runtime.NumCPU()
+	}
+	if len(options.Placement) == 0 {
+		options.Placement = WorkerPlacement_RouterCount
+	}
+	if options.LoadInterval <= 0 {
+		options.LoadInterval = 5 * time.Second
+	}
+
+	pool = &WorkerPool{
+		IEventEmitter: NewEventEmitter(),
+		logger:        logger,
+		options:       options,
+		closeCh:       make(chan struct{}),
+	}
+
+	for i := 0; i < options.Size; i++ {
+		slot := &workerSlot{}
+
+		if options.Respawn {
+			slot.supervisor, err = newWorkerSupervisor(options.SupervisorOptions, spawn)
+			if err == nil {
+				slot.worker = slot.supervisor.Worker()
+			}
+		} else {
+			slot.worker, err = spawn()
+		}
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		pool.slots = append(pool.slots, slot)
+		pool.watch(slot)
+	}
+
+	if options.Placement == WorkerPlacement_ResourceUsage {
+		go pool.pollLoad()
+	}
+
+	return
+}
+
+/**
+ * Workers of the pool, alive or being respawned.
+ */
+func (p *WorkerPool) Workers() []*Worker {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	workers := make([]*Worker, len(p.slots))
+
+	for i, slot := range p.slots {
+		workers[i] = slot.worker
+	}
+	return workers
+}
+
+/**
+ * Whether the WorkerPool is closed.
+ */
+func (p *WorkerPool) Closed() bool {
+	return atomic.LoadUint32(&p.closed) > 0
+}
+
+/**
+ * Close every Worker. ErrAlreadyClosed is returned if the pool was already
+ * closed.
+ */
+func (p *WorkerPool) Close() error {
+	if !atomic.CompareAndSwapUint32(&p.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
+
+	p.logger.Debug("close()")
+
+	close(p.closeCh)
+
+	p.locker.Lock()
+	slots := p.slots
+	p.slots = nil
+	p.locker.Unlock()
+
+	for _, slot := range slots {
+		if slot.supervisor != nil {
+			slot.supervisor.Close()
+		} else {
+			slot.worker.Close()
+		}
+	}
+	p.RemoveAllListeners()
+
+	return nil
+}
+
+/**
+ * Create a Router in the least loaded Worker.
+ */
+func (p *WorkerPool) CreateRouter(options RouterOptions, opts ...RouterOption) (*Router, error) {
+	return p.CreateRouterContext(context.Background(), options, opts...)
+}
+
+/**
+ * CreateRouterContext is CreateRouter with a context cancelling the worker request.
+ */
+func (p *WorkerPool) CreateRouterContext(ctx context.Context, options RouterOptions, opts ...RouterOption) (router *Router, err error) {
+	p.logger.Debug("createRouter()")
+
+	slot, worker := p.leastLoaded()
+	if slot == nil {
+		err = NewInvalidStateError("no worker available")
+		return
+	}
+
+	defer func() {
+		p.locker.Lock()
+		slot.pending--
+		p.locker.Unlock()
+	}()
+
+	return worker.CreateRouterContext(ctx, options, opts...)
+}
+
+// leastLoaded returns the slot of the least loaded alive Worker and the
+// Worker, counting the Router about to be created in it.
+func (p *WorkerPool) leastLoaded() (best *workerSlot, worker *Worker) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	var bestLoad float64
+	var bestRouters int
+
+	for _, slot := range p.slots {
+		if slot.worker.Closed() {
+			continue
+		}
+
+		routers := slot.pending
+		slot.worker.routers.Range(func(key, value interface{}) bool {
+			routers++
+			return true
+		})
+
+		var load float64
+
+		if p.options.Placement == WorkerPlacement_ResourceUsage {
+			load = slot.load
+		}
+
+		if best == nil || load < bestLoad || load == bestLoad && routers < bestRouters {
+			best, bestLoad, bestRouters = slot, load, routers
+		}
+	}
+
+	if best != nil {
+		best.pending++
+		worker = best.worker
+	}
+
+	return
+}
+
+func (p *WorkerPool) watch(slot *workerSlot) {
+	if slot.supervisor != nil {
+		slot.supervisor.On("died", func(err error, worker *Worker) {
+			p.SafeEmit("workerdied", err, worker)
+		})
+		slot.supervisor.On("restarted", func(pid int, worker *Worker) {
+			p.locker.Lock()
+			slot.worker = worker
+			slot.load, slot.lastUsage = 0, nil
+			p.locker.Unlock()
+
+			p.SafeEmit("workerrestarted", pid, worker)
+		})
+		return
+	}
+
+	worker := slot.worker
+
+	worker.On("died", func(err error) {
+		p.logger.Warn("worker died, removing it from the pool [pid:%d]: %v", worker.Pid(), err)
+
+		p.locker.Lock()
+		for i, s := range p.slots {
+			if s == slot {
+				p.slots = append(p.slots[:i:i], p.slots[i+1:]...)
+				break
+			}
+		}
+		p.locker.Unlock()
+
+		p.SafeEmit("workerdied", err, worker)
+	})
+}
+
+// pollLoad samples the resource usage of the Workers until the pool is
+// closed.
+func (p *WorkerPool) pollLoad() {
+	ticker := time.NewTicker(p.options.LoadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.sampleLoad()
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+func (p *WorkerPool) sampleLoad() {
+	p.locker.Lock()
+	slots := append([]*workerSlot(nil), p.slots...)
+	p.locker.Unlock()
+
+	for _, slot := range slots {
+		p.locker.Lock()
+		worker := slot.worker
+		p.locker.Unlock()
+
+		if worker.Closed() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.options.LoadInterval)
+		usage, err := worker.GetResourceUsageContext(ctx)
+		cancel()
+
+		if err != nil {
+			p.logger.Warn("getting resource usage failed [pid:%d]: %v", worker.Pid(), err)
+			continue
+		}
+
+		now := time.Now()
+
+		p.locker.Lock()
+		// The Worker may have been respawned meanwhile.
+		if slot.worker == worker {
+			if last := slot.lastUsage; last != nil {
+				cpu := cpuTime(usage) - cpuTime(*last)
+				slot.load = float64(cpu) / now.Sub(slot.lastTime).Seconds()
+			}
+			slot.lastUsage, slot.lastTime = &usage, now
+		}
+		p.locker.Unlock()
+	}
+}
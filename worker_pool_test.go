@@ -0,0 +1,142 @@
+package mediasoup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var poolRouterOptions = RouterOptions{MediaCodecs: []*RtpCodecCapability{
+	{Kind: MediaKind_Audio, MimeType: "audio/opus", ClockRate: 48000, Channels: 2},
+}}
+
+func TestWorkerPool_RouterCount(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	pool, err := newWorkerPool(WorkerPoolOptions{Size: 3}, spawner.spawn)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Len(t, pool.Workers(), 3)
+
+	for i := 0; i < 4; i++ {
+		_, err := pool.CreateRouter(poolRouterOptions)
+		require.NoError(t, err)
+	}
+
+	var counts []int
+	for _, worker := range pool.Workers() {
+		counts = append(counts, len(worker.Routers()))
+	}
+	assert.Equal(t, []int{2, 1, 1}, counts)
+}
+
+func TestWorkerPool_SpawnFailure(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	pool, err := newWorkerPool(WorkerPoolOptions{Size: 2}, func() (*Worker, error) {
+		if len(spawner.mocks) == 1 {
+			return nil, errors.New("spawn failed")
+		}
+		return spawner.spawn()
+	})
+	assert.Nil(t, pool)
+	assert.EqualError(t, err, "spawn failed")
+	assert.True(t, spawner.mocks[0].Closed())
+}
+
+func TestWorkerPool_WorkerDied(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	pool, err := newWorkerPool(WorkerPoolOptions{Size: 2}, spawner.spawn)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	died := make(chan *Worker, 1)
+	pool.On("workerdied", func(err error, worker *Worker) { died <- worker })
+
+	spawner.mocks[0].Die(errors.New("crash"))
+
+	select {
+	case worker := <-died:
+		assert.Equal(t, spawner.mocks[0].Worker, worker)
+	case <-time.After(time.Second):
+		t.Fatal("workerdied not emitted")
+	}
+
+	assert.Equal(t, []*Worker{spawner.mocks[1].Worker}, pool.Workers())
+
+	router, err := pool.CreateRouter(poolRouterOptions)
+	require.NoError(t, err)
+	assert.Equal(t, []*Router{router}, spawner.mocks[1].Routers())
+
+	spawner.mocks[1].Die(errors.New("crash"))
+	<-died
+
+	_, err = pool.CreateRouter(poolRouterOptions)
+	assert.IsType(t, InvalidStateError{}, err)
+}
+
+func TestWorkerPool_Respawn(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	pool, err := newWorkerPool(WorkerPoolOptions{
+		Size:              2,
+		Respawn:           true,
+		SupervisorOptions: WorkerSupervisorOptions{RestartDelay: 10 * time.Millisecond},
+	}, spawner.spawn)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	restarted := make(chan *Worker, 1)
+	pool.On("workerrestarted", func(pid int, worker *Worker) { restarted <- worker })
+
+	spawner.mocks[0].Die(errors.New("crash"))
+
+	select {
+	case worker := <-restarted:
+		assert.Equal(t, []*Worker{worker, spawner.mocks[1].Worker}, pool.Workers())
+	case <-time.After(time.Second):
+		t.Fatal("workerrestarted not emitted")
+	}
+}
+
+func TestWorkerPool_ResourceUsage(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	pool, err := newWorkerPool(WorkerPoolOptions{
+		Size:         2,
+		Placement:    WorkerPlacement_ResourceUsage,
+		LoadInterval: time.Hour,
+	}, spawner.spawn)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// The first worker uses 100ms of CPU time per sample, the second one 10ms.
+	for i, mock := range spawner.mocks {
+		step := int64(100)
+		if i == 1 {
+			step = 10
+		}
+		var utime int64
+
+		mock.Handle("worker.getResourceUsage", func(MockRequest) (interface{}, error) {
+			utime += step
+			return H{"ru_utime": utime}, nil
+		})
+	}
+
+	pool.sampleLoad()
+	pool.sampleLoad()
+
+	for i := 0; i < 2; i++ {
+		_, err := pool.CreateRouter(poolRouterOptions)
+		require.NoError(t, err)
+	}
+
+	assert.Empty(t, spawner.mocks[0].Routers())
+	assert.Len(t, spawner.mocks[1].Routers(), 2)
+}
@@ -0,0 +1,36 @@
+package mediasoup
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Lowercase fragments of the worker output telling the RTC port range is
+// unavailable.
+var portRangeFailurePatterns = []string{
+	"no more available ports",
+	"address already in use",
+	"eaddrinuse",
+	"rtcminport",
+	"rtcmaxport",
+	"port range",
+}
+
+func isPortRangeFailure(line string) bool {
+	line = strings.ToLower(line)
+
+	for _, pattern := range portRangeFailurePatterns {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordSpawnOutput keeps the line of the output of the worker process telling
+// the RTC port range is unavailable, if written while spawning.
+func (w *Worker) recordSpawnOutput(line string) {
+	if atomic.LoadUint32(&w.spawnDone) == 0 && w.portRangeFailure.Load() == nil && isPortRangeFailure(line) {
+		w.portRangeFailure.Store(line)
+	}
+}
@@ -0,0 +1,74 @@
+package mediasoup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+/**
+ * Spawn n Workers in parallel (e.g. at startup, so the first Router does not
+ * wait for a worker process to spawn), each one being verified by a dump
+ * round-trip. If one of them fails to spawn or to answer, the other ones are
+ * closed and the first error is returned.
+ */
+func PrewarmWorkers(n int, options ...Option) ([]*Worker, error) {
+	return prewarmWorkers(n, func() (*Worker, error) {
+		return NewWorker(options...)
+	})
+}
+
+func prewarmWorkers(n int, spawn func() (*Worker, error)) (workers []*Worker, err error) {
+	if n <= 0 {
+		return nil, NewTypeError("invalid number of workers %d", n)
+	}
+
+	logger := NewLogger("Worker")
+
+	logger.Debug("prewarmWorkers() [n:%d]", n)
+
+	workers = make([]*Worker, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			worker, err := spawn()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			workers[i] = worker
+
+			if _, err := worker.DumpContext(context.Background()); err != nil {
+				errs[i] = fmt.Errorf("worker not ready [pid:%d]: %w", worker.Pid(), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, e := range errs {
+		if e != nil {
+			err = e
+			break
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	logger.Error("prewarming workers failed: %v", err)
+
+	for _, worker := range workers {
+		if worker != nil {
+			worker.Close()
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,68 @@
+package mediasoup
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrewarmWorkers(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	workers, err := prewarmWorkers(3, spawner.spawn)
+	require.NoError(t, err)
+	require.Len(t, workers, 3)
+
+	for i, mock := range spawner.mocks {
+		assert.Len(t, mock.Requests("worker.dump"), 1)
+		assert.False(t, workers[i].Closed())
+		workers[i].Close()
+	}
+}
+
+func TestPrewarmWorkers_NotReady(t *testing.T) {
+	var (
+		spawned int32
+		locker  sync.Mutex
+		mocks   []*MockWorker
+	)
+
+	_, err := prewarmWorkers(2, func() (*Worker, error) {
+		mock, err := NewMockWorker()
+		if err != nil {
+			return nil, err
+		}
+		if atomic.AddInt32(&spawned, 1) == 2 {
+			mock.Handle("worker.dump", func(MockRequest) (interface{}, error) {
+				return nil, NewInvalidStateError("not ready")
+			})
+		}
+		locker.Lock()
+		mocks = append(mocks, mock)
+		locker.Unlock()
+
+		return mock.Worker, nil
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not ready")
+
+	require.Len(t, mocks, 2)
+	for _, mock := range mocks {
+		assert.True(t, mock.Closed())
+	}
+}
+
+func TestPrewarmWorkers_SpawnFailure(t *testing.T) {
+	spawner := &mockSpawner{failures: 1}
+
+	workers, err := prewarmWorkers(2, spawner.spawn)
+	assert.Nil(t, workers)
+	assert.EqualError(t, err, "spawn failed")
+
+	for _, mock := range spawner.mocks {
+		assert.True(t, mock.Closed())
+	}
+}
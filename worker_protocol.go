@@ -0,0 +1,61 @@
+package mediasoup
+
+import "fmt"
+
+/**
+ * Protocol of the Channel with the worker process.
+ */
+type WorkerProtocol string
+
+const (
+	/**
+	 * JSON messages in netstrings, with a separate PayloadChannel (mediasoup <
+	 * 3.13). Spoken by this library, with the messages of the 3.7 workers.
+	 */
+	WorkerProtocol_JSON WorkerProtocol = "json"
+
+	/**
+	 * Flatbuffers messages, the PayloadChannel being merged into the Channel
+	 * (mediasoup >= 3.13). Not supported: it has no channel codec yet.
+	 */
+	WorkerProtocol_Flatbuffers WorkerProtocol = "flatbuffers"
+)
+
+// Version of the worker moving the Channel to flatbuffers.
+const flatbuffersWorkerVersion = "3.13.0"
+
+/**
+ * Protocol of the Channel of the given worker version, WorkerProtocol_JSON if
+ * it is unknown.
+ */
+func ProtocolForWorkerVersion(version string) WorkerProtocol {
+	v, ok := parseVersion(version)
+	if !ok {
+		return WorkerProtocol_JSON
+	}
+	fbs, _ := parseVersion(flatbuffersWorkerVersion)
+
+	if compareVersions(v, fbs) >= 0 {
+		return WorkerProtocol_Flatbuffers
+	}
+	return WorkerProtocol_JSON
+}
+
+/**
+ * Protocol of the Channel with the worker process.
+ */
+func (w *Worker) Protocol() WorkerProtocol {
+	return w.channel.codec.protocol()
+}
+
+// checkProtocol fails for a declared worker version whose Channel protocol has
+// no codec, before spawning a worker process which would not understand any
+// request.
+func checkProtocol(version string) error {
+	protocol := ProtocolForWorkerVersion(version)
+
+	if _, err := newChannelCodec(protocol); err != nil {
+		return fmt.Errorf("%w [version:%s, protocol:%s]", ErrIncompatibleWorker, version, protocol)
+	}
+	return nil
+}
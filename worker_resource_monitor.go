@@ -0,0 +1,99 @@
+package mediasoup
+
+import (
+	"context"
+	"time"
+)
+
+/**
+ * Resource usage of the worker process over a resource monitor interval,
+ * emitted with "resourceusage" on the Worker observer.
+ */
+type WorkerResourceUsageStats struct {
+	Time time.Time `json:"time"`
+
+	/**
+	 * Resource usage at Time.
+	 */
+	Usage WorkerResourceUsage `json:"usage"`
+
+	/**
+	 * Time elapsed since the previous sample.
+	 */
+	Interval time.Duration `json:"interval"`
+
+	/**
+	 * CPU time (user and system) used during the interval, in percent of one
+	 * core.
+	 */
+	CPUPercent float64 `json:"cpuPercent"`
+
+	/**
+	 * Growth of the maximum resident set size during the interval.
+	 */
+	RSSGrowth int64 `json:"rssGrowth"`
+}
+
+/**
+ * Get the resource usage of the worker process at the given interval, emitting
+ * "resourceusage" on the observer from the second sample on. It stops when the
+ * returned function is called or when the Worker is closed.
+ */
+func (w *Worker) StartResourceMonitor(interval time.Duration) (stop func()) {
+	w.logger.Debug("startResourceMonitor() [interval:%s]", interval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	goWithWorkerLabels(w.pid, "resource-monitor", func() {
+		defer cancel()
+
+		w.monitorResourceUsage(ctx, interval)
+	})
+
+	return cancel
+}
+
+func (w *Worker) monitorResourceUsage(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *WorkerResourceUsageStats
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-w.Done():
+			return
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, interval)
+		usage, err := w.GetResourceUsageContext(reqCtx)
+		cancel()
+
+		if err != nil {
+			if !w.Closed() && ctx.Err() == nil {
+				w.logger.Warn("getting resource usage failed: %v", err)
+			}
+			continue
+		}
+
+		stats := &WorkerResourceUsageStats{Time: time.Now(), Usage: usage}
+
+		if last != nil {
+			stats.Interval = stats.Time.Sub(last.Time)
+			stats.CPUPercent = float64(cpuTime(usage)-cpuTime(last.Usage)) /
+				float64(stats.Interval.Milliseconds()) * 100
+			stats.RSSGrowth = usage.RU_Maxrss - last.Usage.RU_Maxrss
+
+			w.observer.SafeEmit("resourceusage", *stats)
+		}
+		last = stats
+	}
+}
+
+// cpuTime returns the user and system CPU time of the usage, in ms.
+func cpuTime(usage WorkerResourceUsage) int64 {
+	return usage.RU_Utime + usage.RU_Stime
+}
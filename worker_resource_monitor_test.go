@@ -0,0 +1,45 @@
+package mediasoup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_StartResourceMonitor(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+
+	var utime, maxrss int64
+
+	mock.Handle("worker.getResourceUsage", func(MockRequest) (interface{}, error) {
+		utime += 10
+		maxrss += 1024
+		return H{"ru_utime": utime, "ru_maxrss": maxrss}, nil
+	})
+
+	statsCh := make(chan WorkerResourceUsageStats, 10)
+	mock.Observer().On("resourceusage", func(stats WorkerResourceUsageStats) { statsCh <- stats })
+
+	stop := mock.StartResourceMonitor(20 * time.Millisecond)
+	defer stop()
+
+	select {
+	case stats := <-statsCh:
+		assert.EqualValues(t, 20, stats.Usage.RU_Utime)
+		assert.EqualValues(t, 1024, stats.RSSGrowth)
+		assert.Greater(t, stats.CPUPercent, 0.0)
+		assert.Greater(t, int64(stats.Interval), int64(0))
+	case <-time.After(time.Second):
+		t.Fatal("resourceusage not emitted")
+	}
+
+	mock.Close()
+
+	// The monitor stops on close.
+	requests := len(mock.Requests("worker.getResourceUsage"))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, requests, len(mock.Requests("worker.getResourceUsage")))
+}
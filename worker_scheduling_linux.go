@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package mediasoup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// applyScheduling sets the CPU affinity, nice level and OOM score adjustment
+// of the worker process.
+func (w WorkerSettings) applyScheduling(pid int) (err error) {
+	if len(w.CPUAffinity) > 0 {
+		var mask [16]uint64 // 1024 CPUs, like cpu_set_t.
+
+		for _, cpu := range w.CPUAffinity {
+			if cpu >= len(mask)*64 {
+				return NewTypeError("invalid cpu %d", cpu)
+			}
+			mask[cpu/64] |= 1 << (uint(cpu) % 64)
+		}
+
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY,
+			uintptr(pid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask[0])))
+		if errno != 0 {
+			return fmt.Errorf("setting cpu affinity: %w", errno)
+		}
+	}
+
+	if w.Nice != 0 {
+		if err = syscall.Setpriority(syscall.PRIO_PROCESS, pid, w.Nice); err != nil {
+			return fmt.Errorf("setting nice level: %w", err)
+		}
+	}
+
+	if w.OOMScoreAdj != 0 {
+		path := fmt.Sprintf("/proc/%d/oom_score_adj", pid)
+
+		if err = ioutil.WriteFile(path, []byte(strconv.Itoa(w.OOMScoreAdj)), 0644); err != nil {
+			return fmt.Errorf("setting oom score adjustment: %w", err)
+		}
+	}
+
+	return
+}
+
+// applyResourceLimits sets the rlimits of the worker process and moves it
+// into its cgroup.
+func (w WorkerSettings) applyResourceLimits(pid int) (err error) {
+	limits := w.ResourceLimits
+
+	for _, limit := range []struct {
+		name     string
+		resource int
+		value    uint64
+	}{
+		{"memory", syscall.RLIMIT_AS, limits.MemoryBytes},
+		{"open files", syscall.RLIMIT_NOFILE, limits.OpenFiles},
+	} {
+		if limit.value == 0 {
+			continue
+		}
+		rlimit := syscall.Rlimit{Cur: limit.value, Max: limit.value}
+
+		_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64,
+			uintptr(pid), uintptr(limit.resource), uintptr(unsafe.Pointer(&rlimit)), 0, 0, 0)
+		if errno != 0 {
+			return fmt.Errorf("setting %s limit: %w", limit.name, errno)
+		}
+	}
+
+	if len(limits.Cgroup) > 0 {
+		path := filepath.Join(limits.Cgroup, "cgroup.procs")
+
+		if err = ioutil.WriteFile(path, []byte(strconv.Itoa(pid)), 0644); err != nil {
+			return fmt.Errorf("moving into cgroup: %w", err)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+//go:build linux
+// +build linux
+
+package mediasoup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerSettings_ApplyScheduling(t *testing.T) {
+	child := exec.Command("/bin/sleep", "10")
+	require.NoError(t, child.Start())
+	defer func() {
+		child.Process.Kill()
+		child.Wait()
+	}()
+
+	pid := child.Process.Pid
+	settings := newWorkerSettings(WithCPUAffinity(0), WithNice(5), WithOOMScoreAdj(500))
+
+	require.NoError(t, settings.validate())
+	require.NoError(t, settings.applyScheduling(pid))
+
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	require.NoError(t, err)
+	assert.Contains(t, string(status), "Cpus_allowed_list:\t0\n")
+
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, pid)
+	require.NoError(t, err)
+	assert.Equal(t, 20-5, prio) // The raw syscall returns 20 - nice.
+
+	adj, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/oom_score_adj", pid))
+	require.NoError(t, err)
+	assert.Equal(t, "500", strings.TrimSpace(string(adj)))
+
+	assert.IsType(t, TypeError{}, newWorkerSettings(WithNice(20)).validate())
+	assert.IsType(t, TypeError{}, newWorkerSettings(WithCPUAffinity(-1)).validate())
+}
+
+func TestWorkerSettings_ApplyResourceLimits(t *testing.T) {
+	child := exec.Command("/bin/sleep", "10")
+	require.NoError(t, child.Start())
+	defer func() {
+		child.Process.Kill()
+		child.Wait()
+	}()
+
+	pid := child.Process.Pid
+	settings := newWorkerSettings(WithResourceLimits(WorkerResourceLimits{
+		MemoryBytes: 1 << 30,
+		OpenFiles:   256,
+	}))
+
+	require.NoError(t, settings.validate())
+	require.NoError(t, settings.applyResourceLimits(pid))
+
+	limits, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	require.NoError(t, err)
+	assert.Regexp(t, `Max address space\s+1073741824\s+1073741824`, string(limits))
+	assert.Regexp(t, `Max open files\s+256\s+256`, string(limits))
+
+	settings = newWorkerSettings(WithResourceLimits(WorkerResourceLimits{Cgroup: t.TempDir() + "/missing"}))
+	assert.Error(t, settings.applyResourceLimits(pid))
+
+	settings = newWorkerSettings(WithResourceLimits(WorkerResourceLimits{CheckInterval: time.Second}))
+	assert.IsType(t, TypeError{}, settings.validate())
+}
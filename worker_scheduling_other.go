@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package mediasoup
+
+// applyScheduling fails if CPU affinity, nice level or OOM score adjustment
+// are set, as they are only supported on Linux.
+func (w WorkerSettings) applyScheduling(pid int) error {
+	if len(w.CPUAffinity) > 0 || w.Nice != 0 || w.OOMScoreAdj != 0 {
+		return NewUnsupportedError("worker scheduling settings are only supported on linux")
+	}
+	return nil
+}
+
+// applyResourceLimits fails if resource limits are set, as they are only
+// supported on Linux.
+func (w WorkerSettings) applyResourceLimits(pid int) error {
+	if !w.ResourceLimits.isZero() {
+		return NewUnsupportedError("worker resource limits are only supported on linux")
+	}
+	return nil
+}
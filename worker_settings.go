@@ -2,6 +2,11 @@ package mediasoup
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 )
 
 type WorkerSettings struct {
@@ -40,6 +45,119 @@ type WorkerSettings struct {
 	 */
 	DtlsPrivateKeyFile string `json:"dtlsPrivateKeyFile,omitempty"`
 
+	/**
+	 * PEM encoded DTLS certificate and private key, instead of the files
+	 * (e.g. given by a secret manager). They are given to the worker
+	 * subprocess through inherited pipes, never written into a file.
+	 */
+	DtlsCertificate []byte `json:"-"`
+	DtlsPrivateKey  []byte `json:"-"`
+
+	/**
+	 * Path to the worker binary, optionally followed by arguments. Default
+	 * the package level WorkerBin.
+	 */
+	WorkerBin string `json:"-"`
+
+	/**
+	 * Environment variables of the worker subprocess, overriding the ones
+	 * inherited from the Go process. MEDIASOUP_VERSION can not be overridden.
+	 */
+	Env map[string]string `json:"-"`
+
+	/**
+	 * Arguments appended to the ones given by the settings.
+	 */
+	ExtraArgs []string `json:"-"`
+
+	/**
+	 * CPUs the worker subprocess is pinned to, like taskset. Linux only.
+	 */
+	CPUAffinity []int `json:"-"`
+
+	/**
+	 * Nice level of the worker subprocess, from -20 (highest priority) to 19.
+	 * Unchanged if 0. Linux only.
+	 */
+	Nice int `json:"-"`
+
+	/**
+	 * OOM score adjustment of the worker subprocess, from -1000 (never killed
+	 * by the OOM killer) to 1000. Unchanged if 0. Linux only.
+	 */
+	OOMScoreAdj int `json:"-"`
+
+	/**
+	 * Resource limits (rlimits and cgroup) of the worker subprocess. Linux
+	 * only.
+	 */
+	ResourceLimits WorkerResourceLimits `json:"-"`
+
+	/**
+	 * Version of the worker binary, checked against the supported range
+	 * before spawning the worker, and after it when the worker does not
+	 * report its own version.
+	 */
+	WorkerVersion string `json:"-"`
+
+	/**
+	 * Maximum time NewWorker() waits for the worker subprocess to report it is
+	 * running. Default 10 seconds.
+	 */
+	SpawnTimeout time.Duration `json:"-"`
+
+	/**
+	 * Maximum time Close() waits for the worker subprocess to exit after
+	 * SIGTERM before sending SIGKILL, and then after SIGKILL. Default 5
+	 * seconds.
+	 */
+	CloseTimeout time.Duration `json:"-"`
+
+	/**
+	 * Base logger for the Worker and every entity created in it. Default the
+	 * one created by NewLogger().
+	 */
+	Logger Logger `json:"-"`
+
+	/**
+	 * Writers the stdout and stderr lines of the worker subprocess are written
+	 * to instead of being logged with Logger, if set. They may be written
+	 * concurrently if they are the same.
+	 */
+	Stdout io.Writer `json:"-"`
+	Stderr io.Writer `json:"-"`
+
+	/**
+	 * Function called with every log line written by the worker subprocess
+	 * (e.g. to capture DTLS errors), in addition to logging it.
+	 */
+	LogHandler func(log WorkerLog) `json:"-"`
+
+	/**
+	 * Generator of the ids of the entities created in the Worker. Default
+	 * DefaultIdGenerator.
+	 */
+	IdGenerator IdGenerator `json:"-"`
+
+	/**
+	 * What is recorded to diagnose the death of the worker subprocess, see
+	 * Worker.Forensics().
+	 */
+	Forensics ForensicsOptions `json:"-"`
+
+	/**
+	 * Labels (e.g. "tenant") added to every log line and observer event of the
+	 * Worker and of the entities created in it, see ObserverEvent.Labels.
+	 */
+	Labels map[string]string `json:"-"`
+
+	/**
+	 * Timeout and retries of the Channel requests, by method (e.g.
+	 * "transport.getStats"), by action of any entity (e.g. "*.dump") or for all
+	 * of them ("*"). See WithRequestPolicy().
+	 */
+	RequestPolicies map[string]RequestPolicy `json:"-"`
+
 	/**
 	 * Custom application data.
 	 */
@@ -72,7 +190,128 @@ func (w WorkerSettings) Args() []string {
 		args = append(args, fmt.Sprintf("--%s=%v", key, value))
 	}
 
-	return args
+	return append(args, w.ExtraArgs...)
+}
+
+// Log tags known by the worker.
+var validWorkerLogTags = map[WorkerLogTag]bool{
+	WorkerLogTag_INFO:      true,
+	WorkerLogTag_ICE:       true,
+	WorkerLogTag_DTLS:      true,
+	WorkerLogTag_RTP:       true,
+	WorkerLogTag_SRTP:      true,
+	WorkerLogTag_RTCP:      true,
+	WorkerLogTag_RTX:       true,
+	WorkerLogTag_BWE:       true,
+	WorkerLogTag_Score:     true,
+	WorkerLogTag_Simulcast: true,
+	WorkerLogTag_SVC:       true,
+	WorkerLogTag_SCTP:      true,
+	WorkerLogTag_Message:   true,
+}
+
+// validate checks the settings before spawning, to return a descriptive error
+// instead of the worker exiting with code 42, and that the declared worker
+// version (if any) supports the flags they require.
+func (w WorkerSettings) validate() error {
+	switch w.LogLevel {
+	case WorkerLogLevel_Debug, WorkerLogLevel_Warn, WorkerLogLevel_Error, WorkerLogLevel_None:
+	default:
+		return NewTypeError("invalid logLevel %q", w.LogLevel)
+	}
+	for _, logTag := range w.LogTags {
+		if !validWorkerLogTags[logTag] {
+			return NewTypeError("invalid logTag %q", logTag)
+		}
+	}
+	if w.RtcMinPort > w.RtcMaxPort {
+		return NewTypeError("rtcMinPort %d greater than rtcMaxPort %d", w.RtcMinPort, w.RtcMaxPort)
+	}
+	if (len(w.DtlsCertificateFile) > 0) != (len(w.DtlsPrivateKeyFile) > 0) {
+		return NewTypeError("both dtlsCertificateFile and dtlsPrivateKeyFile must be set")
+	}
+	if err := w.validateDtlsPEM(); err != nil {
+		return err
+	}
+	for _, file := range []string{w.DtlsCertificateFile, w.DtlsPrivateKeyFile} {
+		if len(file) == 0 {
+			continue
+		}
+		if f, err := os.Open(file); err != nil {
+			return NewTypeError("unreadable DTLS file: %s", err)
+		} else {
+			f.Close()
+		}
+	}
+	if w.SpawnTimeout <= 0 || w.CloseTimeout <= 0 {
+		return NewTypeError("spawnTimeout and closeTimeout must be positive")
+	}
+	for key := range w.CustomOptions {
+		if len(key) == 0 {
+			return NewTypeError("empty custom option name")
+		}
+	}
+	for _, cpu := range w.CPUAffinity {
+		if cpu < 0 {
+			return NewTypeError("invalid cpu %d", cpu)
+		}
+	}
+	if w.Nice < -20 || w.Nice > 19 {
+		return NewTypeError("nice level %d out of [-20, 19]", w.Nice)
+	}
+	if w.OOMScoreAdj < -1000 || w.OOMScoreAdj > 1000 {
+		return NewTypeError("oom score adjustment %d out of [-1000, 1000]", w.OOMScoreAdj)
+	}
+	if w.ResourceLimits.CheckInterval < 0 {
+		return NewTypeError("negative resource limits checkInterval")
+	}
+	if w.ResourceLimits.CheckInterval > 0 && len(w.ResourceLimits.Cgroup) == 0 {
+		return NewTypeError("resource limits checkInterval requires a cgroup")
+	}
+	for key, policy := range w.RequestPolicies {
+		if err := policy.validate(key); err != nil {
+			return err
+		}
+	}
+
+	if err := checkProtocol(w.WorkerVersion); err != nil {
+		return err
+	}
+	if err := checkWorkerVersion(w.WorkerVersion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/**
+ * Environment of the worker subprocess: the one of the Go process, overridden
+ * by Env (sorted by name), then MEDIASOUP_VERSION which can not be overridden.
+ */
+func (w WorkerSettings) Environ() []string {
+	inherited := os.Environ()
+	env := make([]string, 0, len(inherited)+len(w.Env)+1)
+
+	for _, kv := range inherited {
+		key := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			key = kv[:i]
+		}
+		if _, ok := w.Env[key]; !ok && key != "MEDIASOUP_VERSION" {
+			env = append(env, kv)
+		}
+	}
+
+	overrides := make([]string, 0, len(w.Env))
+
+	for key, value := range w.Env {
+		if key != "MEDIASOUP_VERSION" {
+			overrides = append(overrides, key+"="+value)
+		}
+	}
+	sort.Strings(overrides)
+
+	return append(append(env, overrides...), "MEDIASOUP_VERSION="+VERSION)
 }
 
 func (w WorkerSettings) Option() Option {
@@ -86,6 +325,12 @@ func (w WorkerSettings) Option() Option {
 		if w.RtcMaxPort == 0 {
 			w.RtcMaxPort = 59999
 		}
+		if w.SpawnTimeout == 0 {
+			w.SpawnTimeout = 10 * time.Second
+		}
+		if w.CloseTimeout == 0 {
+			w.CloseTimeout = 5 * time.Second
+		}
 		*p = w
 	}
 }
@@ -129,6 +374,13 @@ func WithRtcMaxPort(rtcMaxPort uint16) Option {
 	}
 }
 
+func WithRtcPortRange(rtcMinPort, rtcMaxPort uint16) Option {
+	return func(o *WorkerSettings) {
+		o.RtcMinPort = rtcMinPort
+		o.RtcMaxPort = rtcMaxPort
+	}
+}
+
 func WithDtlsCert(dtlsCertificateFile, dtlsPrivateKeyFile string) Option {
 	return func(o *WorkerSettings) {
 		o.DtlsCertificateFile = dtlsCertificateFile
@@ -136,6 +388,151 @@ func WithDtlsCert(dtlsCertificateFile, dtlsPrivateKeyFile string) Option {
 	}
 }
 
+func WithDtlsCertPEM(certificate, privateKey []byte) Option {
+	return func(o *WorkerSettings) {
+		o.DtlsCertificate = certificate
+		o.DtlsPrivateKey = privateKey
+	}
+}
+
+func WithCPUAffinity(cpus ...int) Option {
+	return func(o *WorkerSettings) {
+		o.CPUAffinity = cpus
+	}
+}
+
+func WithNice(nice int) Option {
+	return func(o *WorkerSettings) {
+		o.Nice = nice
+	}
+}
+
+func WithOOMScoreAdj(adj int) Option {
+	return func(o *WorkerSettings) {
+		o.OOMScoreAdj = adj
+	}
+}
+
+func WithResourceLimits(limits WorkerResourceLimits) Option {
+	return func(o *WorkerSettings) {
+		o.ResourceLimits = limits
+	}
+}
+
+func WithWorkerBin(path string) Option {
+	return func(o *WorkerSettings) {
+		o.WorkerBin = path
+	}
+}
+
+func WithEnv(env map[string]string) Option {
+	return func(o *WorkerSettings) {
+		if o.Env == nil {
+			o.Env = make(map[string]string)
+		}
+		for key, value := range env {
+			o.Env[key] = value
+		}
+	}
+}
+
+func WithExtraArgs(args ...string) Option {
+	return func(o *WorkerSettings) {
+		o.ExtraArgs = append(o.ExtraArgs, args...)
+	}
+}
+
+func WithWorkerVersion(version string) Option {
+	return func(o *WorkerSettings) {
+		o.WorkerVersion = version
+	}
+}
+
+func WithSpawnTimeout(timeout time.Duration) Option {
+	return func(o *WorkerSettings) {
+		o.SpawnTimeout = timeout
+	}
+}
+
+func WithCloseTimeout(timeout time.Duration) Option {
+	return func(o *WorkerSettings) {
+		o.CloseTimeout = timeout
+	}
+}
+
+func WithLogger(logger Logger) Option {
+	return func(o *WorkerSettings) {
+		o.Logger = logger
+	}
+}
+
+func WithStdout(w io.Writer) Option {
+	return func(o *WorkerSettings) {
+		o.Stdout = w
+	}
+}
+
+func WithStderr(w io.Writer) Option {
+	return func(o *WorkerSettings) {
+		o.Stderr = w
+	}
+}
+
+func WithLogHandler(handler func(log WorkerLog)) Option {
+	return func(o *WorkerSettings) {
+		o.LogHandler = handler
+	}
+}
+
+func WithIdGenerator(generator IdGenerator) Option {
+	return func(o *WorkerSettings) {
+		o.IdGenerator = generator
+	}
+}
+
+func WithForensics(options ForensicsOptions) Option {
+	return func(o *WorkerSettings) {
+		o.Forensics = options
+	}
+}
+
+func WithLabels(labels map[string]string) Option {
+	return func(o *WorkerSettings) {
+		if o.Labels == nil {
+			o.Labels = make(map[string]string)
+		}
+		for key, value := range labels {
+			o.Labels[key] = value
+		}
+	}
+}
+
+/**
+ * Set the timeout and retries of the Channel requests with the method (e.g.
+ * "router.dump"), the action of any entity (e.g. "*.getStats") or of all of
+ * them ("*"), e.g. to retry the stats polls timed out:
+ *
+ *	mediasoup.WithRequestPolicy("*.getStats", mediasoup.RequestPolicy{Timeout: 2 * time.Second, Retries: 2})
+ */
+func WithRequestPolicy(method string, policy RequestPolicy) Option {
+	return func(o *WorkerSettings) {
+		if o.RequestPolicies == nil {
+			o.RequestPolicies = make(map[string]RequestPolicy)
+		}
+		o.RequestPolicies[method] = policy
+	}
+}
+
+/**
+ * Set the custom application data of the Worker. Named WithWorkerAppData as
+ * WithAppData() sets the one of the entities created in the Worker.
+ */
+func WithWorkerAppData(appData interface{}) Option {
+	return func(o *WorkerSettings) {
+		o.AppData = appData
+	}
+}
+
 func WithCustomOption(key string, value interface{}) Option {
 	return func(o *WorkerSettings) {
 		if o.CustomOptions == nil {
@@ -0,0 +1,34 @@
+package mediasoup
+
+import (
+	"syscall"
+)
+
+type WorkerSignalType string
+
+const (
+	// The worker process was stopped (e.g. by SIGSTOP or SIGTSTP).
+	WorkerSignalType_Stopped WorkerSignalType = "stopped"
+	// The stopped worker process was continued by SIGCONT.
+	WorkerSignalType_Continued WorkerSignalType = "continued"
+	// The worker process was terminated by a signal not sent by Close().
+	WorkerSignalType_Terminated WorkerSignalType = "terminated"
+)
+
+/**
+ * Event emitted with "signal" by the Worker when its worker process is
+ * stopped, continued or terminated by a signal.
+ */
+type WorkerSignalEvent struct {
+	Type   WorkerSignalType `json:"type"`
+	Signal syscall.Signal   `json:"signal"`
+}
+
+func (w *Worker) emitSignal(event WorkerSignalEvent) {
+	if event.Type == WorkerSignalType_Continued {
+		w.logger.Info("worker process continued [pid:%d]", w.pid)
+	} else {
+		w.logger.Warn("worker process %s [pid:%d, signal:%s]", event.Type, w.pid, event.Signal)
+	}
+	w.SafeEmit("signal", event)
+}
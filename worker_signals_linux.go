@@ -0,0 +1,54 @@
+//go:build linux
+// +build linux
+
+package mediasoup
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	_P_PID         = 1
+	_WSTOPPED      = 0x2
+	_WCONTINUED    = 0x8
+	_CLD_STOPPED   = 5
+	_CLD_CONTINUED = 6
+)
+
+// siginfo_t, up to si_status, of a SIGCHLD.
+type childSiginfo struct {
+	Signo  int32
+	Errno  int32
+	Code   int32
+	_      int32
+	Pid    int32
+	Uid    uint32
+	Status int32
+	_      [100]byte
+}
+
+// watchSignals emits "signal" every time the worker process is stopped or
+// continued, until it is reaped by wait().
+func (w *Worker) watchSignals() {
+	for {
+		var info childSiginfo
+
+		_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, _P_PID, uintptr(w.pid),
+			uintptr(unsafe.Pointer(&info)), _WSTOPPED|_WCONTINUED, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			// ECHILD once reaped.
+			return
+		}
+
+		switch info.Code {
+		case _CLD_STOPPED:
+			w.emitSignal(WorkerSignalEvent{Type: WorkerSignalType_Stopped, Signal: syscall.Signal(info.Status)})
+		case _CLD_CONTINUED:
+			w.emitSignal(WorkerSignalEvent{Type: WorkerSignalType_Continued, Signal: syscall.SIGCONT})
+		}
+	}
+}
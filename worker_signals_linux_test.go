@@ -0,0 +1,46 @@
+//go:build linux
+// +build linux
+
+package mediasoup
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_SignalEvents(t *testing.T) {
+	worker := CreateTestWorker()
+	defer worker.Close()
+
+	events := make(chan WorkerSignalEvent, 3)
+	worker.On("signal", func(event WorkerSignalEvent) { events <- event })
+
+	nextEvent := func() WorkerSignalEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			require.FailNow(t, "signal not emitted")
+			return WorkerSignalEvent{}
+		}
+	}
+
+	require.NoError(t, syscall.Kill(worker.Pid(), syscall.SIGSTOP))
+	assert.Equal(t, WorkerSignalEvent{Type: WorkerSignalType_Stopped, Signal: syscall.SIGSTOP}, nextEvent())
+
+	require.NoError(t, syscall.Kill(worker.Pid(), syscall.SIGCONT))
+	assert.Equal(t, WorkerSignalEvent{Type: WorkerSignalType_Continued, Signal: syscall.SIGCONT}, nextEvent())
+
+	require.NoError(t, syscall.Kill(worker.Pid(), syscall.SIGKILL))
+	assert.Equal(t, WorkerSignalEvent{Type: WorkerSignalType_Terminated, Signal: syscall.SIGKILL}, nextEvent())
+
+	select {
+	case <-worker.Died():
+	case <-time.After(time.Second):
+		t.Fatal("died not emitted")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package mediasoup
+
+// watchSignals does nothing, the stops and continuations of the worker
+// process being only watched on Linux.
+func (w *Worker) watchSignals() {}
@@ -0,0 +1,264 @@
+package mediasoup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFakeWorkerBin runs fn with WorkerBin set to a shell script.
+func withFakeWorkerBin(t *testing.T, script string, fn func()) {
+	bin := filepath.Join(t.TempDir(), "mediasoup-worker")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+
+	workerBin := WorkerBin
+	WorkerBin = bin
+	defer func() { WorkerBin = workerBin }()
+
+	fn()
+}
+
+func TestNewWorker_SpawnTimeout(t *testing.T) {
+	withFakeWorkerBin(t, "exec /bin/sleep 10", func() {
+		start := time.Now()
+
+		worker, err := NewWorker(WithSpawnTimeout(100 * time.Millisecond))
+
+		assert.Nil(t, worker)
+		assert.True(t, errors.Is(err, ErrSpawnTimeout))
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+}
+
+func TestNewWorker_SpawnFailure(t *testing.T) {
+	withFakeWorkerBin(t, "exit 42", func() {
+		worker, err := NewWorker()
+
+		assert.Nil(t, worker)
+		assert.IsType(t, TypeError{}, err)
+	})
+
+	withFakeWorkerBin(t, "exit 1", func() {
+		worker, err := NewWorker()
+
+		assert.Nil(t, worker)
+		assert.Contains(t, err.Error(), "code:1")
+	})
+}
+
+func TestNewWorker_StartFailureClosesFiles(t *testing.T) {
+	openFiles := func() int {
+		entries, err := ioutil.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skip("no /proc/self/fd")
+		}
+		return len(entries)
+	}
+	cert, err := ioutil.ReadFile("testdata/dtls-cert.pem")
+	require.NoError(t, err)
+	key, err := ioutil.ReadFile("testdata/dtls-key.pem")
+	require.NoError(t, err)
+
+	before := openFiles()
+
+	worker, err := NewWorker(
+		WithWorkerBin(filepath.Join(t.TempDir(), "missing-worker")),
+		WithDtlsCertPEM(cert, key),
+	)
+	assert.Nil(t, worker)
+	assert.Error(t, err)
+
+	// The sockets of the Channels and the DTLS pipes are closed.
+	assert.Equal(t, before, openFiles())
+}
+
+func TestNewWorker_WorkerBin(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "mediasoup-worker-canary")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+	withFakeWorkerBin(t, "exit 42", func() {
+		worker, err := NewWorker(WithWorkerBin(bin))
+
+		assert.Nil(t, worker)
+		assert.Contains(t, err.Error(), "code:1")
+	})
+}
+
+func TestWorkerSettings_Environ(t *testing.T) {
+	os.Setenv("MEDIASOUP_TEST_INHERITED", "1")
+	os.Setenv("MEDIASOUP_TEST_OVERRIDDEN", "1")
+	defer os.Unsetenv("MEDIASOUP_TEST_INHERITED")
+	defer os.Unsetenv("MEDIASOUP_TEST_OVERRIDDEN")
+
+	env := newWorkerSettings(WithEnv(map[string]string{
+		"MEDIASOUP_TEST_OVERRIDDEN": "2",
+		"MEDIASOUP_VERSION":         "0.0.0",
+	})).Environ()
+
+	assert.Contains(t, env, "MEDIASOUP_TEST_INHERITED=1")
+	assert.Contains(t, env, "MEDIASOUP_TEST_OVERRIDDEN=2")
+	assert.NotContains(t, env, "MEDIASOUP_TEST_OVERRIDDEN=1")
+	assert.NotContains(t, env, "MEDIASOUP_VERSION=0.0.0")
+	assert.Equal(t, "MEDIASOUP_VERSION="+VERSION, env[len(env)-1])
+}
+
+func TestNewWorker_EnvAndExtraArgs(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	withFakeWorkerBin(t, `echo "$MALLOC_ARENA_MAX $MEDIASOUP_VERSION $@" > `+out+`; exit 1`, func() {
+		_, err := NewWorker(
+			WithEnv(map[string]string{"MALLOC_ARENA_MAX": "2", "MEDIASOUP_VERSION": "0.0.0"}),
+			WithExtraArgs("--foo", "--bar=1"),
+		)
+		require.Error(t, err)
+	})
+
+	data, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	assert.Regexp(t, `^2 `+VERSION+` --logLevel=error .*--foo --bar=1\n$`, string(data))
+}
+
+func TestNewWorker_IncompleteDtlsCert(t *testing.T) {
+	worker, err := NewWorker(WithDtlsCert("cert.pem", ""))
+	assert.Nil(t, worker)
+	assert.IsType(t, TypeError{}, err)
+}
+
+func TestNewWorker_StdoutStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	withFakeWorkerBin(t, `echo "out line"; echo "err line" >&2; exit 1`, func() {
+		_, err := NewWorker(WithStdout(&stdout), WithStderr(&stderr))
+		require.Error(t, err)
+	})
+
+	assert.Equal(t, "out line\n", stdout.String())
+	assert.Equal(t, "err line\n", stderr.String())
+}
+
+func TestWorker_TerminateEscalatesToSIGKILL(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "stubborn")
+	require.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\ntrap '' TERM\necho ready\nwhile :; do /bin/sleep 0.05; done\n"), 0755))
+
+	child := exec.Command(bin)
+	stdout, err := child.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, child.Start())
+
+	// Wait for the trap to be installed.
+	_, err = bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+
+	w := &Worker{
+		logger:       NewLogger("Worker"),
+		pid:          child.Process.Pid,
+		exitCh:       make(chan struct{}),
+		closeTimeout: 100 * time.Millisecond,
+	}
+	go func() {
+		child.Wait()
+		close(w.exitCh)
+	}()
+
+	start := time.Now()
+
+	assert.NoError(t, w.terminate())
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(100*time.Millisecond))
+
+	status := child.ProcessState.Sys().(syscall.WaitStatus)
+	assert.Equal(t, syscall.SIGKILL, status.Signal())
+
+	// The process already exited.
+	assert.NoError(t, w.terminate())
+}
+
+func TestWorker_CloseFromEventHandler(t *testing.T) {
+	mock, err := NewMockWorker(WithCloseTimeout(5 * time.Second))
+	require.NoError(t, err)
+
+	closed := make(chan error, 1)
+
+	// Run by the goroutine processing the Channel messages.
+	mock.channel.On("target", func(event string, data []byte) {
+		closed <- mock.Close()
+	})
+	require.NoError(t, mock.Notify("target", "event", nil))
+
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Close() from an event handler blocked")
+	}
+	assert.True(t, mock.Closed())
+	assert.True(t, mock.channel.wait(time.Second))
+	assert.True(t, mock.payloadChannel.wait(time.Second))
+}
+
+func TestNewWorker_SpawnTimeoutKillsStubbornProcess(t *testing.T) {
+	// Ignored signals stay ignored across exec.
+	withFakeWorkerBin(t, "trap '' TERM; exec /bin/sleep 10", func() {
+		start := time.Now()
+
+		worker, err := NewWorker(WithSpawnTimeout(100*time.Millisecond), WithCloseTimeout(100*time.Millisecond))
+
+		assert.Nil(t, worker)
+		assert.True(t, errors.Is(err, ErrSpawnTimeout))
+		assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	})
+}
+
+func TestWorkerSettings_Validate(t *testing.T) {
+	appData := H{"region": "eu"}
+	settings := newWorkerSettings(
+		WithRtcPortRange(40000, 40100),
+		WithLogTags([]WorkerLogTag{WorkerLogTag_ICE, WorkerLogTag_DTLS}),
+		WithWorkerAppData(appData),
+	)
+	assert.NoError(t, settings.validate())
+	assert.EqualValues(t, 40000, settings.RtcMinPort)
+	assert.EqualValues(t, 40100, settings.RtcMaxPort)
+	assert.Equal(t, appData, settings.AppData)
+
+	for _, testCase := range []struct {
+		option Option
+		err    string
+	}{
+		{WithLogLevel("verbose"), `invalid logLevel "verbose"`},
+		{WithLogTags([]WorkerLogTag{"foo"}), `invalid logTag "foo"`},
+		{WithRtcPortRange(50000, 40000), "rtcMinPort 50000 greater than rtcMaxPort 40000"},
+		{WithRtcMaxPort(0), "rtcMinPort 10000 greater than rtcMaxPort 0"},
+		{WithDtlsCert("/nonexistent/cert.pem", "/nonexistent/key.pem"), "unreadable DTLS file"},
+		{WithSpawnTimeout(-time.Second), "spawnTimeout and closeTimeout must be positive"},
+		{WithCustomOption("", 1), "empty custom option name"},
+	} {
+		err := newWorkerSettings(testCase.option).validate()
+		assert.IsType(t, TypeError{}, err)
+		assert.Contains(t, err.Error(), testCase.err)
+	}
+}
+
+func TestNewWorker_PortRangeUnavailable(t *testing.T) {
+	withFakeWorkerBin(t, `echo "RTC::PortManager::Bind() | no more available ports [protocol:udp, ip:'0.0.0.0']" >&2; exit 1`, func() {
+		worker, err := NewWorker(WithRtcPortRange(40000, 40010))
+		assert.Nil(t, worker)
+		assert.True(t, errors.Is(err, ErrPortRangeUnavailable))
+		assert.Contains(t, err.Error(), "rtcMinPort:40000, rtcMaxPort:40010")
+		assert.Contains(t, err.Error(), "no more available ports")
+	})
+
+	withFakeWorkerBin(t, `echo "unrelated failure" >&2; exit 1`, func() {
+		_, err := NewWorker()
+		assert.False(t, errors.Is(err, ErrPortRangeUnavailable))
+	})
+}
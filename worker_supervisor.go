@@ -0,0 +1,187 @@
+package mediasoup
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * WorkerSupervisor options.
+ */
+type WorkerSupervisorOptions struct {
+	/**
+	 * Delay before spawning a new worker process once the previous one died,
+	 * doubled after each failed spawn. Default 1 second.
+	 */
+	RestartDelay time.Duration
+
+	/**
+	 * Maximum delay between two spawns. Default 30 seconds.
+	 */
+	MaxRestartDelay time.Duration
+}
+
+/**
+ * WorkerSupervisor keeps a Worker running: when its worker process dies, a new
+ * Worker is spawned with the same settings, until it succeeds or the
+ * supervisor is closed. The Routers of the dead Worker are lost, so the
+ * application recreates them on "restarted".
+ *
+ * @emits died - (error: Error, worker: *Worker)
+ * @emits restarted - (pid: int, worker: *Worker)
+ * @emits restartfailed - (error: Error)
+ */
+type WorkerSupervisor struct {
+	IEventEmitter
+	logger   Logger
+	options  WorkerSupervisorOptions
+	spawn    func() (*Worker, error)
+	locker   sync.Mutex
+	worker   *Worker
+	restarts int
+	closed   uint32
+	closeCh  chan struct{}
+}
+
+/**
+ * Spawn a Worker with the given options and supervise it.
+ */
+func NewWorkerSupervisor(options WorkerSupervisorOptions, workerOptions ...Option) (*WorkerSupervisor, error) {
+	return newWorkerSupervisor(options, func() (*Worker, error) {
+		return NewWorker(workerOptions...)
+	})
+}
+
+func newWorkerSupervisor(options WorkerSupervisorOptions, spawn func() (*Worker, error)) (supervisor *WorkerSupervisor, err error) {
+	logger := NewLogger("WorkerSupervisor")
+
+	logger.Debug("constructor()")
+
+	if options.RestartDelay <= 0 {
+		options.RestartDelay = time.Second
+	}
+	if options.MaxRestartDelay <= 0 {
+		options.MaxRestartDelay = 30 * time.Second
+	}
+	if options.MaxRestartDelay < options.RestartDelay {
+		options.MaxRestartDelay = options.RestartDelay
+	}
+
+	worker, err := spawn()
+	if err != nil {
+		return
+	}
+
+	supervisor = &WorkerSupervisor{
+		IEventEmitter: NewEventEmitter(),
+		logger:        logger,
+		options:       options,
+		spawn:         spawn,
+		worker:        worker,
+		closeCh:       make(chan struct{}),
+	}
+	supervisor.watch(worker)
+
+	return
+}
+
+/**
+ * Current Worker, the dead one until a new one is spawned.
+ */
+func (s *WorkerSupervisor) Worker() *Worker {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.worker
+}
+
+/**
+ * Number of Workers spawned after the death of the previous one.
+ */
+func (s *WorkerSupervisor) Restarts() int {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.restarts
+}
+
+/**
+ * Whether the WorkerSupervisor is closed.
+ */
+func (s *WorkerSupervisor) Closed() bool {
+	return atomic.LoadUint32(&s.closed) > 0
+}
+
+/**
+ * Stop supervising and close the current Worker. ErrAlreadyClosed is returned
+ * if it was already closed.
+ */
+func (s *WorkerSupervisor) Close() error {
+	if !atomic.CompareAndSwapUint32(&s.closed, 0, 1) {
+		return ErrAlreadyClosed
+	}
+
+	s.logger.Debug("close()")
+
+	close(s.closeCh)
+
+	s.Worker().Close()
+	s.RemoveAllListeners()
+
+	return nil
+}
+
+func (s *WorkerSupervisor) watch(worker *Worker) {
+	worker.On("died", func(err error) {
+		s.logger.Warn("worker died [pid:%d]: %v", worker.Pid(), err)
+		s.SafeEmit("died", err, worker)
+
+		go s.restart()
+	})
+}
+
+// restart spawns a new Worker, retrying with a growing delay until it succeeds
+// or the supervisor is closed.
+func (s *WorkerSupervisor) restart() {
+	delay := s.options.RestartDelay
+
+	for {
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-s.closeCh:
+			timer.Stop()
+			return
+		}
+
+		worker, err := s.spawn()
+		if err != nil {
+			s.logger.Error("spawning worker failed, retrying in %s: %v", delay, err)
+			s.SafeEmit("restartfailed", err)
+
+			if delay *= 2; delay > s.options.MaxRestartDelay {
+				delay = s.options.MaxRestartDelay
+			}
+			continue
+		}
+
+		s.locker.Lock()
+		if s.Closed() {
+			s.locker.Unlock()
+			worker.Close()
+			return
+		}
+		s.worker = worker
+		s.restarts++
+		s.locker.Unlock()
+
+		s.watch(worker)
+
+		s.logger.Info("worker restarted [pid:%d]", worker.Pid())
+		s.SafeEmit("restarted", worker.Pid(), worker)
+
+		return
+	}
+}
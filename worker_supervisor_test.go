@@ -0,0 +1,109 @@
+package mediasoup
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSpawner spawns MockWorkers, failing while failures is positive.
+type mockSpawner struct {
+	locker   sync.Mutex
+	mocks    []*MockWorker
+	failures int
+}
+
+func (m *mockSpawner) spawn() (*Worker, error) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("spawn failed")
+	}
+
+	mock, err := NewMockWorker()
+	if err != nil {
+		return nil, err
+	}
+	m.mocks = append(m.mocks, mock)
+
+	return mock.Worker, nil
+}
+
+func (m *mockSpawner) last() *MockWorker {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	return m.mocks[len(m.mocks)-1]
+}
+
+func TestWorkerSupervisor_Restart(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	supervisor, err := newWorkerSupervisor(WorkerSupervisorOptions{
+		RestartDelay: 10 * time.Millisecond,
+	}, spawner.spawn)
+	require.NoError(t, err)
+	defer supervisor.Close()
+
+	first := supervisor.Worker()
+
+	died := make(chan error, 1)
+	supervisor.On("died", func(err error, worker *Worker) {
+		assert.Equal(t, first, worker)
+		died <- err
+	})
+
+	restarted := make(chan int, 1)
+	failures := make(chan error, 2)
+	supervisor.On("restarted", func(pid int) { restarted <- pid })
+	supervisor.On("restartfailed", func(err error) { failures <- err })
+
+	spawner.failures = 2
+	spawner.last().Die(errors.New("crash"))
+
+	select {
+	case err := <-died:
+		assert.EqualError(t, err, "crash")
+	case <-time.After(time.Second):
+		t.Fatal("died not emitted")
+	}
+
+	select {
+	case pid := <-restarted:
+		assert.Equal(t, spawner.last().Pid(), pid)
+		assert.NotEqual(t, first.Pid(), pid)
+	case <-time.After(time.Second):
+		t.Fatal("restarted not emitted")
+	}
+
+	assert.Len(t, failures, 2)
+	assert.Equal(t, 1, supervisor.Restarts())
+	assert.Equal(t, spawner.last().Worker, supervisor.Worker())
+	assert.False(t, supervisor.Worker().Closed())
+}
+
+func TestWorkerSupervisor_Close(t *testing.T) {
+	spawner := &mockSpawner{}
+
+	supervisor, err := newWorkerSupervisor(WorkerSupervisorOptions{
+		RestartDelay: 50 * time.Millisecond,
+	}, spawner.spawn)
+	require.NoError(t, err)
+
+	spawner.last().Die(errors.New("crash"))
+
+	// Closed before the restart delay elapses.
+	assert.NoError(t, supervisor.Close())
+	assert.Equal(t, ErrAlreadyClosed, supervisor.Close())
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Len(t, spawner.mocks, 1)
+	assert.Zero(t, supervisor.Restarts())
+}
@@ -1,6 +1,7 @@
 package mediasoup
 
 import (
+	"io/ioutil"
 	"os"
 	"runtime"
 	"syscall"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var worker *Worker
@@ -182,3 +184,34 @@ func TestWorkerProcessIgnoreSignals(t *testing.T) {
 	assert.False(t, worker.Closed())
 	worker.Close()
 }
+
+func TestCreateWorker_DtlsCertPEM(t *testing.T) {
+	cert, err := ioutil.ReadFile("testdata/dtls-cert.pem")
+	require.NoError(t, err)
+	key, err := ioutil.ReadFile("testdata/dtls-key.pem")
+	require.NoError(t, err)
+
+	worker := CreateTestWorker(WithDtlsCertPEM(cert, key))
+	assert.NotZero(t, worker.Pid())
+
+	// Read by the worker process from the pipes it inherited, the settings
+	// being left unchanged.
+	assert.Subset(t, worker.forensics.args, []string{
+		"--dtlsCertificateFile=/dev/fd/7",
+		"--dtlsPrivateKeyFile=/dev/fd/8",
+	})
+	assert.Empty(t, worker.forensics.settings.DtlsCertificateFile)
+	assert.Empty(t, worker.forensics.settings.DtlsPrivateKeyFile)
+
+	worker.Close()
+
+	_, err = NewWorker(WithDtlsCertPEM(cert, []byte("not a key")))
+	assert.IsType(t, TypeError{}, err)
+
+	_, err = NewWorker(
+		WithDtlsCertPEM(cert, key),
+		WithDtlsCert("testdata/dtls-cert.pem", "testdata/dtls-key.pem"),
+	)
+	assert.IsType(t, TypeError{}, err)
+	assert.Contains(t, err.Error(), "exclusive")
+}
@@ -0,0 +1,121 @@
+package mediasoup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	/**
+	 * Oldest worker version this library speaks the protocol of.
+	 */
+	MinWorkerVersion = "3.7.0"
+
+	/**
+	 * First worker version whose protocol is not supported: the requests and
+	 * notifications of this library are the ones of the 3.7 workers, later
+	 * ones changing them (and mediasoup 3.13 moving the Channel to
+	 * flatbuffers).
+	 */
+	MaxWorkerVersion = "3.8.0"
+)
+
+/**
+ * Worker process version, reported by "worker.dump" or declared with
+ * WithWorkerVersion(). Empty if unknown.
+ */
+func (w *Worker) Version() string {
+	return w.version
+}
+
+// negotiateVersion queries the version of the worker process and checks it is
+// supported. The version is taken from "worker.dump" (workers which report it)
+// or else from the declared one. The 3.7 workers do not report it, so without
+// a declared version the worker is assumed to be the one of VERSION and
+// accepted: only WithWorkerVersion() makes an incompatible worker fail here
+// instead of on its first request.
+func (w *Worker) negotiateVersion(ctx context.Context, declared string) (err error) {
+	var dump struct {
+		Version string `json:"version,omitempty"`
+	}
+
+	if err = w.channel.RequestContext(ctx, "worker.dump", nil).Unmarshal(&dump); err != nil {
+		return
+	}
+
+	version := dump.Version
+	if len(version) == 0 {
+		version = declared
+	}
+	if len(version) == 0 {
+		w.logger.Debug("worker version unknown, assuming it is supported [pid:%d]", w.pid)
+		return
+	}
+
+	if !workerVersionSupported(version) {
+		w.logger.Error("unsupported worker version [pid:%d, version:%s]", w.pid, version)
+
+		return fmt.Errorf("%w [pid:%d, version:%s, supported:>=%s <%s]",
+			ErrIncompatibleWorker, w.pid, version, MinWorkerVersion, MaxWorkerVersion)
+	}
+	w.version = version
+
+	return
+}
+
+// checkWorkerVersion fails for a declared worker version out of the supported
+// range, before spawning a worker process which would be rejected once
+// running.
+func checkWorkerVersion(version string) error {
+	if len(version) > 0 && !workerVersionSupported(version) {
+		return fmt.Errorf("%w [version:%s, supported:>=%s <%s]",
+			ErrIncompatibleWorker, version, MinWorkerVersion, MaxWorkerVersion)
+	}
+	return nil
+}
+
+func workerVersionSupported(version string) bool {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	min, _ := parseVersion(MinWorkerVersion)
+	max, _ := parseVersion(MaxWorkerVersion)
+
+	return compareVersions(v, min) >= 0 && compareVersions(v, max) < 0
+}
+
+// parseVersion parses "major.minor.patch", ignoring a leading "v" and a
+// pre-release or build suffix.
+func parseVersion(version string) (v [3]int, ok bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return
+		}
+		v[i] = n
+	}
+
+	return v, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
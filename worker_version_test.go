@@ -0,0 +1,117 @@
+package mediasoup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerVersionSupported(t *testing.T) {
+	for version, supported := range map[string]bool{
+		"3.7.0":       true,
+		"3.7.17":      true,
+		"v3.7.2":      true,
+		"3.7.0-beta1": true,
+		"3.8.0":       false,
+		"3.12.16":     false,
+		"3.6.99":      false,
+		"3.13.0":      false,
+		"4.0.0":       false,
+		"3.7":         false,
+		"latest":      false,
+	} {
+		assert.Equal(t, supported, workerVersionSupported(version), version)
+	}
+}
+
+func TestWorkerSettings_ValidateWorkerVersion(t *testing.T) {
+	assert.NoError(t, newWorkerSettings().validate())
+	assert.NoError(t, newWorkerSettings(WithWorkerVersion("3.7.17")).validate())
+
+	for _, version := range []string{"3.6.0", "3.8.0", "3.12.16", "latest"} {
+		err := newWorkerSettings(WithWorkerVersion(version)).validate()
+		assert.True(t, errors.Is(err, ErrIncompatibleWorker), version)
+		assert.Contains(t, err.Error(), "version:"+version)
+	}
+}
+
+func TestNewWorker_UnsupportedWorkerVersion(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	withFakeWorkerBin(t, "touch "+out+"; exit 1", func() {
+		worker, err := NewWorker(WithWorkerVersion("3.10.0"))
+		assert.Nil(t, worker)
+		assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+	})
+
+	// Rejected without spawning the worker process.
+	assert.NoFileExists(t, out)
+}
+
+func TestWorker_NegotiateVersion(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// Unknown version.
+	require.NoError(t, mock.negotiateVersion(context.Background(), ""))
+	assert.Empty(t, mock.Version())
+
+	// Declared version.
+	require.NoError(t, mock.negotiateVersion(context.Background(), "3.7.9"))
+	assert.Equal(t, "3.7.9", mock.Version())
+
+	err = mock.negotiateVersion(context.Background(), "3.6.0")
+	assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+
+	err = mock.negotiateVersion(context.Background(), "3.12.16")
+	assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+
+	// The reported version takes precedence.
+	mock.Respond("worker.dump", H{"pid": mock.Pid(), "version": "3.14.1"})
+
+	err = mock.negotiateVersion(context.Background(), "3.7.9")
+	assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+	assert.Contains(t, err.Error(), "version:3.14.1")
+}
+
+func TestWorker_NegotiateVersion_Dump37(t *testing.T) {
+	mock, err := NewMockWorker()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// Dump of a mediasoup 3.7 worker, without version.
+	mock.HandleDefault(func(request MockRequest) (interface{}, error) {
+		if request.Method == "worker.dump" {
+			return json.RawMessage(`{"pid":4242,"routerIds":["c4c3ebd9-5c6f-4c4e-bc3f-2ea2b1b6d6a8"]}`), nil
+		}
+		return nil, nil
+	})
+
+	require.NoError(t, mock.negotiateVersion(context.Background(), ""))
+	assert.Empty(t, mock.Version())
+
+	require.NoError(t, mock.negotiateVersion(context.Background(), "3.7.17"))
+	assert.Equal(t, "3.7.17", mock.Version())
+
+	err = mock.negotiateVersion(context.Background(), "3.10.0")
+	assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+	assert.Contains(t, err.Error(), "version:3.10.0")
+}
+
+func TestProtocolForWorkerVersion(t *testing.T) {
+	assert.Equal(t, WorkerProtocol_JSON, ProtocolForWorkerVersion("3.7.17"))
+	assert.Equal(t, WorkerProtocol_JSON, ProtocolForWorkerVersion("3.12.16"))
+	assert.Equal(t, WorkerProtocol_JSON, ProtocolForWorkerVersion(""))
+	assert.Equal(t, WorkerProtocol_Flatbuffers, ProtocolForWorkerVersion("3.13.0"))
+	assert.Equal(t, WorkerProtocol_Flatbuffers, ProtocolForWorkerVersion("v3.14.1"))
+
+	err := newWorkerSettings(WithWorkerVersion("3.14.1")).validate()
+	assert.True(t, errors.Is(err, ErrIncompatibleWorker))
+	assert.Contains(t, err.Error(), "flatbuffers")
+}
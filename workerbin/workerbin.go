@@ -0,0 +1,385 @@
+// Package workerbin provides the mediasoup-worker binary without installing
+// the mediasoup Node package: the release archive of the worker binary is
+// downloaded into a cache directory, its SHA-256 checksum being verified,
+//
+//	bin, err := workerbin.Download(ctx, workerbin.Options{
+//		BaseURL:  "https://downloads.example.com/mediasoup",
+//		Checksum: "<sha256 of mediasoup-worker-3.7.17-linux-x64.tgz>",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	worker, err := mediasoup.NewWorker(mediasoup.WithWorkerBin(bin))
+//
+// or extracted from a release archive embedded in the application:
+//
+//	//go:embed mediasoup-worker-3.7.17-linux-x64.tgz
+//	var workerArchive []byte
+//
+//	bin, err := workerbin.Extract(bytes.NewReader(workerArchive), workerbin.Options{})
+//
+// The archives of the worker versions spoken by this package (3.7) are not
+// published by mediasoup, whose prebuilt binaries start with 3.10: they are
+// built and hosted by the application, Download() requiring their BaseURL.
+//
+// The binary is only fetched once per version and platform, later calls
+// return the cached one. The checksums of the archive and of the binary are
+// recorded next to it and verified on every call, a cached binary not
+// matching them being fetched again.
+package workerbin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jiyeyuran/mediasoup-go"
+)
+
+var (
+	// ErrNotFound is returned when the release archive has no worker binary.
+	ErrNotFound = errors.New("workerbin: mediasoup-worker not found in archive")
+
+	// ErrChecksumMismatch is returned when the SHA-256 checksum of the release
+	// archive is not the expected one.
+	ErrChecksumMismatch = errors.New("workerbin: archive checksum mismatch")
+
+	// ErrUnsupportedVersion is returned for a version out of the range spoken
+	// by mediasoup-go, see mediasoup.MinWorkerVersion and
+	// mediasoup.MaxWorkerVersion.
+	ErrUnsupportedVersion = errors.New("workerbin: unsupported mediasoup-worker version")
+)
+
+// Options of the worker binary.
+type Options struct {
+	// Version of mediasoup, in [mediasoup.MinWorkerVersion,
+	// mediasoup.MaxWorkerVersion). Default mediasoup.VERSION.
+	Version string
+
+	// Hex encoded SHA-256 checksum of the release archive of the version and
+	// platform, verified before extracting it. Required by Download().
+	Checksum string
+
+	// Directory the binaries are cached in. Default "mediasoup-go" in
+	// os.UserCacheDir().
+	CacheDir string
+
+	// URL the release archives are downloaded from, followed by
+	// "/<version>/<archive name>". Required by Download().
+	BaseURL string
+
+	// Platform and architecture of the binary, as named in the release
+	// archives (e.g. "linux-x64", "darwin-arm64", "win32-x64"). Default the
+	// ones of the running program.
+	Platform string
+
+	// Client downloading the archive. Default http.DefaultClient.
+	Client *http.Client
+}
+
+func (o *Options) setDefaults() (err error) {
+	if len(o.Version) == 0 {
+		o.Version = mediasoup.VERSION
+	}
+	if !supportedVersion(o.Version) {
+		return fmt.Errorf("%w: %s, supported >=%s <%s",
+			ErrUnsupportedVersion, o.Version, mediasoup.MinWorkerVersion, mediasoup.MaxWorkerVersion)
+	}
+	if len(o.CacheDir) == 0 {
+		if o.CacheDir, err = os.UserCacheDir(); err != nil {
+			return
+		}
+		o.CacheDir = filepath.Join(o.CacheDir, "mediasoup-go")
+	}
+	if len(o.Platform) == 0 {
+		o.Platform = Platform()
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	return
+}
+
+// Platform returns the platform and architecture of the running program as
+// named in the mediasoup release archives.
+func Platform() string {
+	goos, arch := runtime.GOOS, runtime.GOARCH
+
+	if goos == "windows" {
+		goos = "win32"
+	}
+	if arch == "amd64" {
+		arch = "x64"
+	}
+	return goos + "-" + arch
+}
+
+// ArchiveName returns the name of the release archive of the given version
+// and platform.
+func ArchiveName(version, platform string) string {
+	return fmt.Sprintf("mediasoup-worker-%s-%s.tgz", version, platform)
+}
+
+// Path returns the path of the cached binary, which may not exist yet.
+func Path(options Options) (string, error) {
+	if err := options.setDefaults(); err != nil {
+		return "", err
+	}
+	return binPath(options), nil
+}
+
+func binPath(options Options) string {
+	return filepath.Join(options.CacheDir, options.Version, options.Platform, binName(options.Platform))
+}
+
+// checksumsPath returns the path of the checksums of the archive and of the
+// binary, recorded next to it in the sha256sum format.
+func checksumsPath(bin string) string {
+	return bin + ".sha256"
+}
+
+func binName(platform string) string {
+	if strings.HasPrefix(platform, "win32") {
+		return "mediasoup-worker.exe"
+	}
+	return "mediasoup-worker"
+}
+
+// Download returns the path of the cached binary, downloading the release
+// archive first if it is not cached yet.
+func Download(ctx context.Context, options Options) (bin string, err error) {
+	if err = options.setDefaults(); err != nil {
+		return
+	}
+	if bin = binPath(options); cached(bin, options) {
+		return
+	}
+	if len(options.Checksum) == 0 {
+		err = fmt.Errorf("workerbin: checksum of mediasoup-worker %s %s required", options.Version, options.Platform)
+		return
+	}
+	if len(options.BaseURL) == 0 {
+		err = fmt.Errorf("workerbin: base URL of mediasoup-worker %s required", options.Version)
+		return
+	}
+
+	url := options.BaseURL + "/" + options.Version + "/" + ArchiveName(options.Version, options.Platform)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	resp, err := options.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("workerbin: downloading %s: %s", url, resp.Status)
+		return
+	}
+
+	return extract(resp.Body, options)
+}
+
+// Extract returns the path of the cached binary, extracting it from the given
+// release archive (a gzipped tarball) first if it is not cached yet. Its
+// checksum is verified if set.
+func Extract(archive io.Reader, options Options) (bin string, err error) {
+	if err = options.setDefaults(); err != nil {
+		return
+	}
+	if bin = binPath(options); cached(bin, options) {
+		return
+	}
+	return extract(archive, options)
+}
+
+// extract writes the binary of the archive to a temporary file renamed to the
+// cache path, so that a partially written binary is never used. The checksums
+// are recorded first, a binary left without them being fetched again.
+func extract(archive io.Reader, options Options) (bin string, err error) {
+	bin = binPath(options)
+
+	// Read first to verify the checksum before extracting anything.
+	data, err := ioutil.ReadAll(archive)
+	if err != nil {
+		return
+	}
+	if len(options.Checksum) > 0 {
+		if err = verifyChecksum(data, options.Checksum); err != nil {
+			return
+		}
+	}
+	archiveSum := sha256.Sum256(data)
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		var header *tar.Header
+
+		if header, err = tr.Next(); err == io.EOF {
+			err = ErrNotFound
+			return
+		} else if err != nil {
+			return
+		}
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != binName(options.Platform) {
+			continue
+		}
+
+		dir := filepath.Dir(bin)
+
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return
+		}
+
+		var tmp *os.File
+
+		if tmp, err = ioutil.TempFile(dir, ".mediasoup-worker-*"); err != nil {
+			return
+		}
+		defer os.Remove(tmp.Name())
+
+		binHash := sha256.New()
+
+		if _, err = io.Copy(io.MultiWriter(tmp, binHash), tr); err != nil {
+			tmp.Close()
+			return
+		}
+		if err = tmp.Close(); err != nil {
+			return
+		}
+		if err = os.Chmod(tmp.Name(), 0755); err != nil {
+			return
+		}
+
+		checksums := fmt.Sprintf("%s  %s\n%s  %s\n",
+			hex.EncodeToString(archiveSum[:]), ArchiveName(options.Version, options.Platform),
+			hex.EncodeToString(binHash.Sum(nil)), binName(options.Platform))
+
+		if err = writeFile(checksumsPath(bin), []byte(checksums)); err != nil {
+			return
+		}
+		err = os.Rename(tmp.Name(), bin)
+
+		return
+	}
+}
+
+// writeFile writes the file through a temporary file renamed to its path.
+func writeFile(name string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".mediasoup-worker-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// cached reports whether the binary is cached and matches the checksums
+// recorded next to it, the one of the archive being the expected one if set.
+func cached(bin string, options Options) bool {
+	data, err := ioutil.ReadFile(checksumsPath(bin))
+	if err != nil || !exists(bin) {
+		return false
+	}
+	checksums := make(map[string]string)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			checksums[fields[1]] = fields[0]
+		}
+	}
+
+	archiveSum, binSum := checksums[ArchiveName(options.Version, options.Platform)], checksums[binName(options.Platform)]
+
+	if len(archiveSum) == 0 || len(binSum) == 0 {
+		return false
+	}
+	if len(options.Checksum) > 0 && !strings.EqualFold(archiveSum, options.Checksum) {
+		return false
+	}
+
+	f, err := os.Open(bin)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+
+	if _, err = io.Copy(hash, f); err != nil {
+		return false
+	}
+	return strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), binSum)
+}
+
+func verifyChecksum(data []byte, checksum string) error {
+	sum := sha256.Sum256(data)
+
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, checksum, actual)
+	}
+	return nil
+}
+
+// supportedVersion reports whether the version is in [MinWorkerVersion,
+// MaxWorkerVersion) of mediasoup.
+func supportedVersion(version string) bool {
+	v, ok := parseVersion(version)
+	min, _ := parseVersion(mediasoup.MinWorkerVersion)
+	max, _ := parseVersion(mediasoup.MaxWorkerVersion)
+
+	return ok && compareVersions(v, min) >= 0 && compareVersions(v, max) < 0
+}
+
+// parseVersion parses "major.minor.patch", ignoring a leading "v".
+func parseVersion(version string) (v [3]int, ok bool) {
+	n, err := fmt.Sscanf(strings.TrimPrefix(version, "v"), "%d.%d.%d", &v[0], &v[1], &v[2])
+
+	return v, err == nil && n == 3
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+func exists(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && info.Mode().IsRegular()
+}
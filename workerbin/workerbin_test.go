@@ -0,0 +1,154 @@
+package workerbin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func TestDownload(t *testing.T) {
+	archive := newArchive(t, map[string]string{
+		"README.md":        "readme",
+		"mediasoup-worker": "worker",
+	})
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/3.7.17/mediasoup-worker-3.7.17-linux-x64.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	options := Options{
+		Version:  "3.7.17",
+		CacheDir: t.TempDir(),
+		BaseURL:  server.URL,
+		Platform: "linux-x64",
+	}
+
+	_, err := Download(context.Background(), options)
+	assert.Contains(t, err.Error(), "checksum")
+	assert.Equal(t, 0, requests)
+
+	options.Checksum = strings.Repeat("0", 64)
+	_, err = Download(context.Background(), options)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	sum := sha256.Sum256(archive)
+	options.Checksum = hex.EncodeToString(sum[:])
+
+	bin, err := Download(context.Background(), options)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(options.CacheDir, "3.7.17", "linux-x64", "mediasoup-worker"), bin)
+
+	data, err := ioutil.ReadFile(bin)
+	require.NoError(t, err)
+	assert.Equal(t, "worker", string(data))
+
+	// The cached binary is returned.
+	bin2, err := Download(context.Background(), options)
+	require.NoError(t, err)
+	assert.Equal(t, bin, bin2)
+	assert.Equal(t, 2, requests)
+
+	// A cached binary not matching its recorded checksum is downloaded again.
+	require.NoError(t, ioutil.WriteFile(bin, []byte("tampered"), 0755))
+
+	_, err = Download(context.Background(), options)
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+
+	data, err = ioutil.ReadFile(bin)
+	require.NoError(t, err)
+	assert.Equal(t, "worker", string(data))
+
+	// As is a cached binary without recorded checksums.
+	require.NoError(t, os.Remove(bin+".sha256"))
+
+	_, err = Download(context.Background(), options)
+	require.NoError(t, err)
+	assert.Equal(t, 4, requests)
+
+	// Or whose archive checksum is not the expected one.
+	options.Checksum = strings.Repeat("0", 64)
+	_, err = Download(context.Background(), options)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+	assert.Equal(t, 5, requests)
+
+	options.Checksum = hex.EncodeToString(sum[:])
+	options.Version = "3.7.0"
+	_, err = Download(context.Background(), options)
+	assert.Contains(t, err.Error(), "404")
+
+	// The versions out of the supported range are rejected.
+	options.Version = "3.12.16"
+	_, err = Download(context.Background(), options)
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+	assert.Equal(t, 6, requests)
+
+	// No public release hosts the archives of the default version.
+	_, err = Download(context.Background(), Options{CacheDir: t.TempDir(), Checksum: options.Checksum})
+	assert.Contains(t, err.Error(), "base URL of mediasoup-worker 3.7.17 required")
+}
+
+func TestExtract(t *testing.T) {
+	options := Options{CacheDir: t.TempDir(), Platform: "win32-x64"}
+
+	_, err := Extract(bytes.NewReader(newArchive(t, map[string]string{"README.md": "readme"})), options)
+	assert.Equal(t, ErrNotFound, err)
+
+	archive := newArchive(t, map[string]string{"bin/mediasoup-worker.exe": "worker"})
+
+	options.Checksum = strings.Repeat("0", 64)
+	_, err = Extract(bytes.NewReader(archive), options)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	options.Checksum = ""
+	bin, err := Extract(bytes.NewReader(archive), options)
+	require.NoError(t, err)
+	assert.Equal(t, "mediasoup-worker.exe", filepath.Base(bin))
+
+	path, err := Path(options)
+	require.NoError(t, err)
+	assert.Equal(t, bin, path)
+}
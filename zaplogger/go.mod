@@ -0,0 +1,13 @@
+module github.com/jiyeyuran/mediasoup-go/zaplogger
+
+go 1.15
+
+require (
+	github.com/jiyeyuran/mediasoup-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.4
+	go.uber.org/zap v1.19.0
+)
+
+// The APIs used by this module are not in a tagged mediasoup-go version yet:
+// build against the root module of this repository until they are.
+replace github.com/jiyeyuran/mediasoup-go => ../
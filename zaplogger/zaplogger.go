@@ -0,0 +1,45 @@
+// Package zaplogger writes mediasoup logs to a zap Logger:
+//
+//	mediasoup.NewLogger = func(scope string) mediasoup.Logger {
+//	  return zaplogger.New(logger).With("scope", scope)
+//	}
+//
+// It is a separate module so that the mediasoup-go module does not depend on
+// zap.
+package zaplogger
+
+import (
+	"github.com/jiyeyuran/mediasoup-go"
+	"go.uber.org/zap"
+)
+
+type zapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+/**
+ * Create a mediasoup Logger writing to the given zap Logger.
+ */
+func New(logger *zap.Logger) mediasoup.Logger {
+	return zapLogger{logger: logger.Sugar()}
+}
+
+func (l zapLogger) Debug(format string, v ...interface{}) {
+	l.logger.Debugf(format, v...)
+}
+
+func (l zapLogger) Info(format string, v ...interface{}) {
+	l.logger.Infof(format, v...)
+}
+
+func (l zapLogger) Warn(format string, v ...interface{}) {
+	l.logger.Warnf(format, v...)
+}
+
+func (l zapLogger) Error(format string, v ...interface{}) {
+	l.logger.Errorf(format, v...)
+}
+
+func (l zapLogger) With(keyvals ...interface{}) mediasoup.Logger {
+	return zapLogger{logger: l.logger.With(keyvals...)}
+}
@@ -0,0 +1,27 @@
+package zaplogger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := New(zap.New(core)).With("routerId", "r1")
+
+	logger.Debug("discarded")
+	logger.Warn("hello %d", 1)
+
+	require.Equal(t, 1, logs.Len())
+
+	entry := logs.All()[0]
+
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	assert.Equal(t, "hello 1", entry.Message)
+	assert.Equal(t, map[string]interface{}{"routerId": "r1"}, entry.ContextMap())
+}